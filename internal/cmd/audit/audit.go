@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"strings"
+	"time"
+)
+
+// NewCmdAudit returns a cobra command for viewing the audit log of abctl.
+func NewCmdAudit() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "View the audit log of the state-changing commands of abctl",
+		Long: `View the audit log of the state-changing commands of abctl.
+
+Every execution of a command changing the installation, such as 'abctl local install' or 'abctl local uninstall', is
+appended to ` + paths.Audit + ` with its time, user, arguments (any sensitive values redacted), and outcome.`,
+	}
+
+	cmd.AddCommand(newCmdList())
+
+	return cmd
+}
+
+func newCmdList() *cobra.Command {
+	var (
+		flagSince time.Duration
+		flagUser  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the recorded commands, oldest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			recorded, err := Load(paths.Audit)
+			if err != nil {
+				pterm.Error.Println("Unable to load the audit log")
+				return err
+			}
+
+			var since time.Time
+			if flagSince > 0 {
+				since = time.Now().Add(-flagSince)
+			}
+
+			filtered := Filter(recorded, since, flagUser)
+			if len(filtered) == 0 {
+				pterm.Info.Println("No audit records found")
+				return nil
+			}
+
+			data := pterm.TableData{{"TIME", "USER", "COMMAND", "ARGS", "DURATION", "OUTCOME", "ERROR"}}
+			for _, r := range filtered {
+				outcome := r.Outcome
+				if r.ErrorCode != "" {
+					outcome += " (" + r.ErrorCode + ")"
+				}
+				data = append(data, []string{
+					r.Time.Local().Format(time.RFC3339), r.User, r.Command, strings.Join(r.Args, " "), r.Duration.String(), outcome, r.Error,
+				})
+			}
+
+			return pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+		},
+	}
+
+	cmd.Flags().DurationVar(&flagSince, "since", 0, "only list commands executed within this duration, e.g. 24h (defaults to every recorded command)")
+	cmd.Flags().StringVar(&flagUser, "user", "", "only list commands executed by this user")
+
+	return cmd
+}
@@ -0,0 +1,188 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/redact"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Annotation is the cobra annotation marking a command as state-changing, every execution of which is recorded in
+// the audit log.
+const Annotation = "abctl.audit"
+
+const (
+	// OutcomeSuccess is the outcome of a command which completed without an error.
+	OutcomeSuccess = "success"
+	// OutcomeFailure is the outcome of a command which returned an error.
+	OutcomeFailure = "failure"
+)
+
+// Record is an execution of a state-changing command.
+type Record struct {
+	Time time.Time `json:"time"`
+	// User is the user who executed the command, including the user who invoked sudo, if any.
+	User    string `json:"user"`
+	Command string `json:"command"`
+	// Args are the positional arguments and the changed flags, with any sensitive value replaced by redact.Redacted.
+	Args     []string      `json:"args,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Outcome  string        `json:"outcome"`
+	Error    string        `json:"error,omitempty"`
+	// ErrorCode is the code of the localerr.LocalError of the error, if any.
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+// Audited returns true if the command is annotated with Annotation.
+func Audited(cmd *cobra.Command) bool {
+	_, ok := cmd.Annotations[Annotation]
+	return ok
+}
+
+// Annotations returns the cobra annotations marking a command as audited.
+func Annotations() map[string]string {
+	return map[string]string{Annotation: "true"}
+}
+
+// New returns the Record of the executed command, which started at start and returned err.
+func New(cmd *cobra.Command, start time.Time, err error, errorCode string) Record {
+	r := Record{
+		Time:     start.UTC(),
+		User:     currentUser(),
+		Command:  cmd.CommandPath(),
+		Args:     Args(cmd),
+		Duration: time.Since(start).Round(time.Millisecond),
+		Outcome:  OutcomeSuccess,
+	}
+	if err != nil {
+		r.Outcome = OutcomeFailure
+		r.Error = redact.String(err.Error())
+		r.ErrorCode = errorCode
+	}
+	return r
+}
+
+// Args returns the positional arguments and the changed flags of the cmd, as --flag=value, with the value of every
+// flag with a sensitive name redacted. A positional argument following a sensitive one is redacted as well, as it is
+// its value, e.g. the value of 'abctl config set local.install.password <value>'.
+func Args(cmd *cobra.Command) []string {
+	var args []string
+	sensitive := false
+	for _, arg := range cmd.Flags().Args() {
+		if sensitive {
+			arg = redact.Redacted
+		}
+		sensitive = redact.Key(arg)
+		args = append(args, redact.String(arg))
+	}
+
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		value := f.Value.String()
+		if redact.Key(f.Name) {
+			value = redact.Redacted
+		}
+		args = append(args, fmt.Sprintf("--%s=%s", f.Name, redact.String(value)))
+	})
+
+	return args
+}
+
+// currentUser returns the name of the user executing abctl, and the user who invoked sudo, if any.
+func currentUser() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if sudo := os.Getenv("SUDO_USER"); sudo != "" && sudo != name {
+		return fmt.Sprintf("%s (sudo by %s)", name, sudo)
+	}
+	return name
+}
+
+// Load returns the records of the audit log located at path, oldest first.
+// If no audit log exists, no records and no error are returned.
+func Load(path string) ([]Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not read audit log %s: %w", path, err)
+	}
+
+	var records []Record
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var r Record
+		// skip any line which cannot be decoded, e.g. one which was only partially written
+		if err := json.Unmarshal(line, &r); err != nil {
+			continue
+		}
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read audit log %s: %w", path, err)
+	}
+
+	return records, nil
+}
+
+// Append adds the record to the audit log located at path, creating it if it does not exist.
+// The audit log is only ever appended to, no record is modified or removed.
+func Append(path string, r Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("could not encode audit record: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("could not create directories for %s: %w", path, err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("could not open audit log %s: %w", path, err)
+	}
+	// the record is written by a single write, so concurrent commands never interleave their records
+	_, err = f.Write(append(data, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("could not write audit log %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Filter returns the records which occurred at or after since, of the user name.
+// A zero since, or an empty name, does not filter by that attribute.
+// The user matches both the user who executed the command and the user who invoked sudo.
+func Filter(records []Record, since time.Time, name string) []Record {
+	var filtered []Record
+	for _, r := range records {
+		if !since.IsZero() && r.Time.Before(since) {
+			continue
+		}
+		if name != "" && r.User != name && !strings.HasPrefix(r.User, name+" ") && !strings.HasSuffix(r.User, "sudo by "+name+")") {
+			continue
+		}
+		filtered = append(filtered, r)
+	}
+	return filtered
+}
@@ -0,0 +1,161 @@
+package audit
+
+import (
+	"errors"
+	"github.com/airbytehq/abctl/internal/redact"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "audit.jsonl")
+
+	// a missing audit log has no records
+	records, err := Load(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if len(records) != 0 {
+		t.Error("expected no records", records)
+	}
+
+	exp := []Record{
+		{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), User: "alice", Command: "abctl local install", Args: []string{"--port=9000"}, Duration: time.Minute, Outcome: OutcomeSuccess},
+		{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), User: "bob", Command: "abctl local uninstall", Duration: time.Second, Outcome: OutcomeFailure, Error: "could not uninstall", ErrorCode: "ABCTL-001"},
+	}
+	for _, r := range exp {
+		if err := Append(path, r); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+
+	records, err = Load(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(exp, records); d != "" {
+		t.Error("records mismatch (-want +got):", d)
+	}
+}
+
+func TestLoad_PartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	data := `{"time":"2024-01-01T00:00:00Z","user":"alice","command":"abctl local install","duration":0,"outcome":"success"}
+{"time":"2024-01-01T00:00:01Z","us`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal("could not write audit log", err)
+	}
+
+	records, err := Load(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	exp := []Record{{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), User: "alice", Command: "abctl local install", Outcome: OutcomeSuccess}}
+	if d := cmp.Diff(exp, records); d != "" {
+		t.Error("records mismatch (-want +got):", d)
+	}
+}
+
+func TestNew(t *testing.T) {
+	var (
+		flagPassword string
+		flagUsername string
+		flagValues   string
+		flagPort     int
+	)
+
+	root := &cobra.Command{Use: "abctl"}
+	cmd := &cobra.Command{
+		Use:         "install",
+		Annotations: Annotations(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&flagPassword, "password", "p", "", "")
+	cmd.Flags().StringVarP(&flagUsername, "username", "u", "", "")
+	cmd.Flags().StringVar(&flagValues, "values", "", "")
+	cmd.Flags().IntVar(&flagPort, "port", 8000, "")
+	root.AddCommand(cmd)
+
+	root.SetArgs([]string{"install", "--username", "admin", "-p", "s3cr3t", "--values", "postgres://user:pass@db:5432/airbyte"})
+	executed, err := root.ExecuteC()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if !Audited(executed) {
+		t.Error("expected the command to be audited")
+	}
+	if Audited(root) {
+		t.Error("expected the root command not to be audited")
+	}
+
+	r := New(executed, time.Now(), errors.New("could not connect to postgres://user:pass@db:5432/airbyte"), "ABCTL-001")
+	exp := Record{
+		Command: "abctl install",
+		// flags are visited in lexicographical order, unchanged flags are omitted
+		Args:      []string{"--password=" + redact.Redacted, "--username=admin", "--values=postgres://user:" + redact.Redacted + "@db:5432/airbyte"},
+		Outcome:   OutcomeFailure,
+		Error:     "could not connect to postgres://user:" + redact.Redacted + "@db:5432/airbyte",
+		ErrorCode: "ABCTL-001",
+	}
+	if d := cmp.Diff(exp, r, cmp.FilterPath(func(p cmp.Path) bool {
+		switch p.String() {
+		case "Time", "User", "Duration":
+			return true
+		}
+		return false
+	}, cmp.Ignore())); d != "" {
+		t.Error("record mismatch (-want +got):", d)
+	}
+	if r.User == "" {
+		t.Error("expected the user to be recorded")
+	}
+}
+
+func TestArgs_SensitivePositional(t *testing.T) {
+	cmd := &cobra.Command{Use: "set <key> <value>", Run: func(cmd *cobra.Command, args []string) {}}
+	if err := cmd.ParseFlags([]string{"local.install.password", "s3cr3t"}); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := []string{"local.install.password", redact.Redacted}
+	if d := cmp.Diff(exp, Args(cmd)); d != "" {
+		t.Error("args mismatch (-want +got):", d)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []Record{
+		{Time: start, User: "alice"},
+		{Time: start.Add(time.Hour), User: "root (sudo by bob)"},
+		{Time: start.Add(2 * time.Hour), User: "bob"},
+	}
+
+	tests := []struct {
+		name  string
+		since time.Time
+		user  string
+		exp   []Record
+	}{
+		{name: "all", exp: records},
+		{name: "since", since: start.Add(time.Hour), exp: records[1:]},
+		{name: "user", user: "alice", exp: records[:1]},
+		{name: "sudo user", user: "bob", exp: records[1:]},
+		{name: "sudo target", user: "root", exp: records[1:2]},
+		{name: "none", user: "carol"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.exp, Filter(records, tt.since, tt.user)); d != "" {
+				t.Error("records mismatch (-want +got):", d)
+			}
+		})
+	}
+}
@@ -2,97 +2,215 @@ package cmd
 
 import (
 	"context"
-	"errors"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/config"
+	"github.com/airbytehq/abctl/internal/cmd/gc"
+	"github.com/airbytehq/abctl/internal/cmd/images"
 	"github.com/airbytehq/abctl/internal/cmd/local"
 	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
 	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/report"
 	"github.com/airbytehq/abctl/internal/cmd/version"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/airbytehq/abctl/internal/shutdown"
+	"github.com/airbytehq/abctl/internal/trace"
+	"github.com/airbytehq/abctl/internal/update"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 	"os"
-)
-
-// Help messages to display for specific error situations.
-const (
-	// helpDocker is displayed if ErrDocker is ever returned
-	helpDocker = `An error occurred while communicating with the Docker daemon.
-Ensure that Docker is running and is accessible.  You may need to upgrade to a newer version of Docker.
-For additional help please visit https://docs.docker.com/get-docker/`
-
-	// helpKubernetes is displayed if ErrKubernetes is ever returned
-	helpKubernetes = `An error occurred while communicating with the Kubernetes cluster.
-If this error persists, you may need to run the uninstall command before attempting to run
-the install command again.`
-
-	// helpIngress is displayed if ErrIngress is ever returned
-	helpIngress = `An error occurred while configuring ingress.
-This could be in indication that the ingress port is already in use by a different application.
-The ingress port can be changed by passing the flag --port.`
-
-	// helpPort is displayed if ErrPort is ever returned
-	helpPort = `An error occurred while verifying if the request port is available.
-This could be in indication that the ingress port is already in use by a different application.
-The ingress port can be changed by passing the flag --port.`
+	"path/filepath"
+	"runtime/debug"
+	"time"
 )
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute(ctx context.Context, cmd *cobra.Command) {
-	if err := cmd.ExecuteContext(ctx); err != nil {
+	defer reportPanic(cmd)
+
+	start := time.Now()
+	ctx, span := trace.NewSpan(ctx, "abctl")
+	executed, err := cmd.ExecuteContextC(ctx)
+	// if the command was interrupted, give any cleanup handlers the opportunity to complete before exiting
+	shutdown.Wait()
+
+	if executed != nil {
+		span.SetAttr("command", executed.CommandPath())
+	}
+	span.RecordError(err)
+	le := localerr.Find(err)
+	var code string
+	if le != nil {
+		code = le.Code()
+		span.SetAttr("error_code", code)
+	}
+	span.End()
+
+	if executed != nil && audit.Audited(executed) {
+		if errAudit := audit.Append(paths.Audit, audit.New(executed, start, err, code)); errAudit != nil {
+			pterm.Warning.Printfln("Unable to write the audit log: %s", errAudit)
+		}
+	}
+
+	if traceFile, _ := cmd.PersistentFlags().GetString("trace-file"); traceFile != "" {
+		if errTrace := trace.WriteFile(traceFile, span); errTrace != nil {
+			pterm.Warning.Printfln("Unable to write trace file: %s", errTrace)
+		} else {
+			pterm.Info.Printfln("Trace written to %s", traceFile)
+		}
+	}
+
+	if err != nil {
 		pterm.Error.Println(err)
 
-		if errors.Is(err, localerr.ErrDocker) {
-			pterm.Println()
-			pterm.Info.Println(helpDocker)
-		} else if errors.Is(err, localerr.ErrKubernetes) {
-			pterm.Println()
-			pterm.Info.Println(helpKubernetes)
-		} else if errors.Is(err, localerr.ErrIngress) {
+		if le != nil {
 			pterm.Println()
-			pterm.Info.Println(helpIngress)
-		} else if errors.Is(err, localerr.ErrPort) {
-			pterm.Println()
-			pterm.Info.Printfln(helpPort)
+			pterm.Info.Println(le.Help())
+			pterm.Info.Printfln("Error code %s, see %s", le.Code(), le.DocsURL())
 		}
+	}
+
+	// the report includes the last lines of the log file, which already contain the error printed above
+	if err != nil && executed != nil && report.Reported(executed) {
+		logFile, _ := cmd.PersistentFlags().GetString("log-file")
+		if path, errReport := report.Write(paths.Crashes, report.New(executed, err, code, nil, nil, logFile)); errReport != nil {
+			pterm.Warning.Printfln("Unable to write the failure report: %s", errReport)
+		} else {
+			pterm.Info.Printfln("A report of the failure was written to %s, run 'abctl report send' to share it", path)
+		}
+	}
 
+	if errLog := logging.Close(); errLog != nil {
+		pterm.Warning.Printfln("Unable to close log file: %s", errLog)
+	}
+	if err != nil {
 		os.Exit(1)
 	}
 }
 
+// reportPanic writes a report of a panic of abctl before crashing, as its stack is otherwise lost once the terminal
+// is closed.
+func reportPanic(cmd *cobra.Command) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	stack := debug.Stack()
+
+	executed, _, _ := cmd.Find(os.Args[1:])
+	logFile, _ := cmd.PersistentFlags().GetString("log-file")
+	if path, err := report.Write(paths.Crashes, report.New(executed, nil, "", recovered, stack, logFile)); err == nil {
+		pterm.Error.Printfln("abctl crashed, a report was written to %s, run 'abctl report send' to share it", path)
+	}
+	panic(recovered)
+}
+
+// isConfigCmd returns true if cmd is the config command or one of its subcommands, which load the config file
+// themselves.
+func isConfigCmd(cmd *cobra.Command) bool {
+	for ; cmd.HasParent(); cmd = cmd.Parent() {
+		if cmd.Name() == "config" && !cmd.Parent().HasParent() {
+			return true
+		}
+	}
+	return false
+}
+
 // NewCmd returns the abctl root cobra command.
-func NewCmd() *cobra.Command {
+// The checkUpdate func, if not nil, is called with the release channel once the flags are resolved, to check for a
+// newer release of abctl while the command runs.
+func NewCmd(checkUpdate func(channel string)) *cobra.Command {
 	cobra.EnableTraverseRunHooks = true
 
 	var (
-		flagDNT     bool
-		flagVerbose bool
+		flagDNT       bool
+		flagVerbose   int
+		flagLogFormat string
+		flagLogFile   string
+		flagQuiet     bool
+		flagNoColor   bool
+		flagChannel   string
+		flagNoCheck   bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "abctl",
 		Short: pterm.LightBlue("Airbyte") + "'s command line tool",
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			if flagVerbose {
-				pterm.EnableDebugMessages()
+			// the environment and configured defaults must be applied before any flag is read, by this or any other
+			// command, with the precedence: flag > environment > config
+			if err := config.ApplyEnv(cmd, os.LookupEnv); err != nil {
+				return err
+			}
+			// an unreadable config file must not prevent any command from running, and is not applied to the config
+			// commands, which read it themselves
+			var errCfg error
+			if !isConfigCmd(cmd) {
+				var cfg config.Config
+				if cfg, errCfg = config.Load(paths.Config); errCfg == nil {
+					if err := config.Apply(cmd, cfg); err != nil {
+						return err
+					}
+				}
+			}
+
+			// NO_COLOR is honored by most command line tools, see https://no-color.org
+			if _, ok := os.LookupEnv("NO_COLOR"); ok {
+				flagNoColor = true
+			}
+			// the default log file is optional, e.g. if ~/.airbyte is owned by root after running abctl with sudo
+			logOpts := logging.Options{
+				Verbosity:    flagVerbose,
+				Format:       flagLogFormat,
+				File:         flagLogFile,
+				FileOptional: !cmd.Flags().Changed("log-file"),
+				Quiet:        flagQuiet,
+				NoColor:      flagNoColor,
+			}
+			if err := logging.Setup(logOpts); err != nil {
+				return err
+			}
+			logging.Tracef("Running %s", cmd.CommandPath())
+			if errCfg != nil {
+				pterm.Warning.Printfln("Ignoring the configured defaults of flags: %s", errCfg)
 			}
 
 			if flagDNT {
 				pterm.Info.Println("Telemetry collection disabled (--dnt)")
 			}
 
+			if err := update.ValidateChannel(flagChannel); err != nil {
+				return err
+			}
+			if checkUpdate != nil && !flagNoCheck {
+				checkUpdate(flagChannel)
+			}
+
 			return nil
 		},
 	}
 
 	cmd.SilenceUsage = true
 	cmd.SilenceErrors = true
-	cmd.CompletionOptions.DisableDefaultCmd = true
 
 	cmd.PersistentFlags().BoolVar(&flagDNT, "dnt", false, "opt out of telemetry data collection")
-	cmd.PersistentFlags().BoolVarP(&flagVerbose, "verbose", "v", false, "enable verbose output")
+	cmd.PersistentFlags().CountVarP(&flagVerbose, "verbose", "v", "enable verbose output, -v for debug and -vv for trace output")
+	cmd.PersistentFlags().StringVar(&flagLogFormat, "log-format", logging.FormatText, "format of the log records, text or json")
+	cmd.PersistentFlags().StringVar(&flagLogFile, "log-file", filepath.Join(paths.Logs, "abctl.log"), "file capturing all output of the command, rotated once it reaches 10MiB, or empty to disable it")
+	cmd.PersistentFlags().BoolVarP(&flagQuiet, "quiet", "q", false, "only print errors and the output of the command, omitting the progress and informational messages")
+	cmd.PersistentFlags().BoolVar(&flagNoColor, "no-color", false, "print plain text without colors or spinners, which is the default if the output is not a terminal (also NO_COLOR)")
+	cmd.PersistentFlags().StringVar(&flagChannel, "channel", update.ChannelStable, "the release channel checked for a newer abctl, stable, rc, or nightly for pre-releases")
+	cmd.PersistentFlags().BoolVar(&flagNoCheck, "no-update-check", false, "do not check for a newer release of abctl, which is skipped in CI or if the output is not a terminal regardless")
+	cmd.PersistentFlags().String("trace-file", "", "write a trace of the command's execution to this file, for attaching to support requests")
 
 	cmd.AddCommand(version.NewCmdVersion())
 	cmd.AddCommand(local.NewCmdLocal(k8s.DefaultProvider))
+	cmd.AddCommand(images.NewCmdImages())
+	cmd.AddCommand(config.NewCmdConfig())
+	cmd.AddCommand(audit.NewCmdAudit())
+	cmd.AddCommand(report.NewCmdReport())
+	cmd.AddCommand(gc.NewCmdGC(k8s.DefaultProvider))
 
 	return cmd
 }
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"strings"
+)
+
+// EnvPrefix is the prefix of the environment variables which set the values of flags.
+const EnvPrefix = "ABCTL"
+
+// Apply sets every flag of the command which was not provided on the command line to its value in the Config.
+// The value configured for the command itself takes precedence over the value configured for any of its parents,
+// e.g. local.install.port over local.port.
+func Apply(cmd *cobra.Command, c Config) error {
+	return apply(cmd, func(path []string, flag string) (string, []string, bool) {
+		for i := len(path); i >= 0; i-- {
+			key := strings.Join(append(path[:i:i], flag), ".")
+			if value, ok := c.Get(key); ok {
+				return "config value for " + key, flagValues(value), true
+			}
+		}
+		return "", nil, false
+	})
+}
+
+// ApplyEnv sets every flag of the command which was not provided on the command line to the value of its
+// environment variable, see EnvVar.
+// The variable of the command itself takes precedence over the variable of any of its parents,
+// e.g. ABCTL_LOCAL_INSTALL_PORT over ABCTL_LOCAL_PORT.
+// It must be applied before the Config, so the environment takes precedence over the Config.
+func ApplyEnv(cmd *cobra.Command, lookupEnv func(string) (string, bool)) error {
+	return apply(cmd, func(path []string, flag string) (string, []string, bool) {
+		for i := len(path); i >= 0; i-- {
+			name := EnvVar(path[:i], flag)
+			if value, ok := lookupEnv(name); ok {
+				return "environment variable " + name, []string{value}, true
+			}
+		}
+		return "", nil, false
+	})
+}
+
+// EnvVar returns the name of the environment variable which sets the flag of the command path (excluding abctl),
+// e.g. ABCTL_LOCAL_INSTALL_CHART_VERSION for the chart-version flag of local install.
+func EnvVar(path []string, flag string) string {
+	name := strings.Join(append(append([]string{EnvPrefix}, path...), flag), "_")
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// apply sets every flag of the command which was not provided on the command line to its values, if found.
+func apply(cmd *cobra.Command, lookup func(path []string, flag string) (source string, values []string, ok bool)) error {
+	path := commandPath(cmd)
+
+	var errs []error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			return
+		}
+		source, values, ok := lookup(path, f.Name)
+		if !ok {
+			return
+		}
+		for _, v := range values {
+			if err := cmd.Flags().Set(f.Name, v); err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s: %w", source, err))
+			}
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// commandPath returns the names of the command and its parents, excluding the root command.
+func commandPath(cmd *cobra.Command) []string {
+	var path []string
+	for ; cmd.HasParent(); cmd = cmd.Parent() {
+		path = append([]string{cmd.Name()}, path...)
+	}
+	return path
+}
+
+// flagFor returns the flag the key configures, which must be a flag of an existing command.
+func flagFor(root *cobra.Command, key string) (*pflag.Flag, error) {
+	parts := strings.Split(key, ".")
+	name := parts[len(parts)-1]
+
+	cmd, rest, err := root.Find(parts[:len(parts)-1])
+	if err != nil || len(rest) > 0 {
+		return nil, fmt.Errorf("invalid key %s, %s is not a command", key, strings.Join(parts[:len(parts)-1], " "))
+	}
+
+	f := cmd.Flags().Lookup(name)
+	if f == nil {
+		f = cmd.PersistentFlags().Lookup(name)
+	}
+	if f == nil {
+		f = cmd.InheritedFlags().Lookup(name)
+	}
+	if f == nil || name == "help" {
+		return nil, fmt.Errorf("invalid key %s, %s is not a flag of '%s'", key, name, cmd.CommandPath())
+	}
+	return f, nil
+}
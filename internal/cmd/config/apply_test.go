@@ -0,0 +1,193 @@
+package config
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+	"testing"
+)
+
+type flags struct {
+	dnt     bool
+	port    int
+	hosts   []string
+	context string
+}
+
+func testCommand() (*cobra.Command, *cobra.Command, *flags) {
+	var f flags
+
+	root := &cobra.Command{Use: "abctl", SilenceUsage: true, SilenceErrors: true}
+	root.PersistentFlags().BoolVar(&f.dnt, "dnt", false, "")
+
+	local := &cobra.Command{Use: "local"}
+	local.PersistentFlags().StringVar(&f.context, "context", "", "")
+
+	install := &cobra.Command{Use: "install", Run: func(cmd *cobra.Command, args []string) {}}
+	install.Flags().IntVar(&f.port, "port", 8000, "")
+	install.Flags().StringSliceVar(&f.hosts, "host", nil, "")
+
+	root.AddCommand(local)
+	local.AddCommand(install)
+
+	return root, install, &f
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		args   []string
+		exp    flags
+		err    bool
+	}{
+		{
+			name: "no config",
+			exp:  flags{port: 8000},
+		},
+		{
+			name: "config",
+			config: Config{
+				"dnt": true,
+				"local": map[string]interface{}{
+					"context": "kind",
+					"install": map[string]interface{}{
+						"port": 9000,
+						"host": []interface{}{"localhost", "airbyte.lan"},
+					},
+				},
+			},
+			exp: flags{dnt: true, port: 9000, hosts: []string{"localhost", "airbyte.lan"}, context: "kind"},
+		},
+		{
+			name: "command takes precedence over parent",
+			config: Config{
+				"context": "root",
+				"local": map[string]interface{}{
+					"context": "local",
+					"install": map[string]interface{}{"context": "install"},
+				},
+			},
+			exp: flags{port: 8000, context: "install"},
+		},
+		{
+			name:   "flag takes precedence over config",
+			config: Config{"local": map[string]interface{}{"install": map[string]interface{}{"port": 9000, "host": "airbyte.lan"}}},
+			args:   []string{"--port", "7000"},
+			exp:    flags{port: 7000, hosts: []string{"airbyte.lan"}},
+		},
+		{
+			name:   "invalid value",
+			config: Config{"local": map[string]interface{}{"install": map[string]interface{}{"port": "http"}}},
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, install, f := testCommand()
+			root.SetArgs(append([]string{"local", "install"}, tt.args...))
+			root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+				if cmd != install {
+					t.Error("unexpected command", cmd.CommandPath())
+				}
+				return Apply(cmd, tt.config)
+			}
+
+			err := root.Execute()
+			if tt.err != (err != nil) {
+				t.Fatal("unexpected error result", err)
+			}
+			if tt.err {
+				return
+			}
+			if d := cmp.Diff(tt.exp, *f, cmp.AllowUnexported(flags{})); d != "" {
+				t.Error("flags mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestFlagFor(t *testing.T) {
+	root, _, _ := testCommand()
+
+	for _, key := range []string{"dnt", "local.dnt", "local.context", "local.install.port", "local.install.context"} {
+		if _, err := flagFor(root, key); err != nil {
+			t.Errorf("unexpected error for %s: %s", key, err)
+		}
+	}
+	for _, key := range []string{"port", "local.install.unknown", "local.unknown.port", "local.install.help"} {
+		if _, err := flagFor(root, key); err == nil {
+			t.Errorf("expected an error for %s", key)
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		config Config
+		args   []string
+		exp    flags
+	}{
+		{
+			name: "env",
+			env: map[string]string{
+				"ABCTL_DNT":                "true",
+				"ABCTL_LOCAL_CONTEXT":      "kind",
+				"ABCTL_LOCAL_INSTALL_PORT": "9000",
+				"ABCTL_LOCAL_INSTALL_HOST": "localhost,airbyte.lan",
+			},
+			exp: flags{dnt: true, port: 9000, hosts: []string{"localhost", "airbyte.lan"}, context: "kind"},
+		},
+		{
+			name: "command takes precedence over parent",
+			env: map[string]string{
+				"ABCTL_CONTEXT":               "root",
+				"ABCTL_LOCAL_CONTEXT":         "local",
+				"ABCTL_LOCAL_INSTALL_CONTEXT": "install",
+			},
+			exp: flags{port: 8000, context: "install"},
+		},
+		{
+			name:   "flag > env > config",
+			env:    map[string]string{"ABCTL_LOCAL_INSTALL_PORT": "9000", "ABCTL_LOCAL_CONTEXT": "env"},
+			config: Config{"dnt": true, "local": map[string]interface{}{"context": "config", "install": map[string]interface{}{"port": 6000}}},
+			args:   []string{"--port", "7000"},
+			exp:    flags{dnt: true, port: 7000, context: "env"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, _, f := testCommand()
+			root.SetArgs(append([]string{"local", "install"}, tt.args...))
+			root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+				lookupEnv := func(name string) (string, bool) {
+					value, ok := tt.env[name]
+					return value, ok
+				}
+				if err := ApplyEnv(cmd, lookupEnv); err != nil {
+					return err
+				}
+				return Apply(cmd, tt.config)
+			}
+
+			if err := root.Execute(); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, *f, cmp.AllowUnexported(flags{})); d != "" {
+				t.Error("flags mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestEnvVar(t *testing.T) {
+	if d := cmp.Diff("ABCTL_LOCAL_INSTALL_CHART_VERSION", EnvVar([]string{"local", "install"}, "chart-version")); d != "" {
+		t.Error("env var mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff("ABCTL_DNT", EnvVar(nil, "dnt")); d != "" {
+		t.Error("env var mismatch (-want +got):", d)
+	}
+}
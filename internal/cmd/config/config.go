@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+// NewCmdConfig returns a cobra command for managing the default values of flags, stored in paths.Config.
+func NewCmdConfig() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manages the default values of flags",
+		Long: `Manages the default values of flags, stored in ` + paths.Config + `.
+
+Keys are the command path (excluding abctl) followed by the flag name, e.g. local.install.port.
+A flag of a parent command applies to all of its subcommands, e.g. local.kubeconfig, or dnt for every command.
+Every flag can also be set by an environment variable, named ABCTL_ followed by the key in upper case with
+underscores, e.g. ABCTL_LOCAL_INSTALL_PORT or ABCTL_DNT.
+
+A flag provided on the command line takes precedence over its environment variable, which takes precedence over
+its configured value.`,
+		Example: `  abctl config set local.install.port 9000
+  abctl config set local.install.chart-version 0.63.0
+  abctl config set dnt true`,
+	}
+
+	cmd.AddCommand(newCmdSet(), newCmdGet(), newCmdUnset(), newCmdList())
+
+	return cmd
+}
+
+func newCmdSet() *cobra.Command {
+	return &cobra.Command{
+		Use:         "set <key> <value>",
+		Annotations: audit.Annotations(),
+		Short:       "Set the default value of a flag",
+		Args:        cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, value := args[0], args[1]
+
+			f, err := flagFor(cmd.Root(), key)
+			if err != nil {
+				return err
+			}
+			// the value is only parsed to verify it, the flag's command is never executed
+			if err := f.Value.Set(value); err != nil {
+				return fmt.Errorf("invalid value %s for %s: %w", value, key, err)
+			}
+
+			c, err := Load(paths.Config)
+			if err != nil {
+				return err
+			}
+			if err := c.Set(key, value); err != nil {
+				return err
+			}
+			if err := Save(paths.Config, c); err != nil {
+				return err
+			}
+
+			pterm.Success.Printfln("Set %s to %s", key, value)
+			return nil
+		},
+	}
+}
+
+func newCmdGet() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the default value of a flag",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := Load(paths.Config)
+			if err != nil {
+				return err
+			}
+
+			value, ok := c.Get(args[0])
+			if !ok {
+				return fmt.Errorf("%s is not set", args[0])
+			}
+			pterm.Println(strings.Join(flagValues(value), ","))
+			return nil
+		},
+	}
+}
+
+func newCmdUnset() *cobra.Command {
+	return &cobra.Command{
+		Use:         "unset <key>",
+		Annotations: audit.Annotations(),
+		Short:       "Remove the default value of a flag",
+		Args:        cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := Load(paths.Config)
+			if err != nil {
+				return err
+			}
+
+			if !c.Unset(args[0]) {
+				pterm.Info.Printfln("%s is not set", args[0])
+				return nil
+			}
+			if err := Save(paths.Config, c); err != nil {
+				return err
+			}
+
+			pterm.Success.Printfln("Unset %s", args[0])
+			return nil
+		},
+	}
+}
+
+func newCmdList() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Print the default values of all flags",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := Load(paths.Config)
+			if err != nil {
+				return err
+			}
+
+			for _, e := range c.List() {
+				pterm.Printfln("%s=%s", e.Key, e.Value)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Config contains the default values of flags, nested by the command path (excluding abctl) and keyed by the flag
+// name, e.g. {"local": {"install": {"port": 9000}}}.
+// A flag of a parent command applies to all of its subcommands, e.g. {"local": {"kubeconfig": "..."}}.
+type Config map[string]interface{}
+
+// Entry is a single value of the Config.
+type Entry struct {
+	// Key is the full dotted path of the value, e.g. local.install.port
+	Key   string
+	Value string
+}
+
+// Load returns the Config stored in the file located at path.
+// If no file exists, an empty Config is returned.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Config{}, nil
+		}
+		return Config{}, fmt.Errorf("could not read config file %s: %w", path, err)
+	}
+
+	// unmarshalled as a plain map, as the nested tables would otherwise also be unmarshalled as a Config
+	c := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return Config{}, fmt.Errorf("could not unmarshal config file %s: %w", path, err)
+	}
+	if c == nil {
+		c = map[string]interface{}{}
+	}
+
+	return c, nil
+}
+
+// Save writes the Config to the file located at path, creating any missing directories.
+// The file is only readable by the user, as the values may include credentials.
+func Save(path string, c Config) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("could not marshal config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("could not create directories for %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("could not write config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Get returns the value of the key, which is only found if it is a value and not a table of further values.
+func (c Config) Get(key string) (interface{}, bool) {
+	parts := strings.Split(key, ".")
+	table := map[string]interface{}(c)
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := table[part].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		table = next
+	}
+
+	value, ok := table[parts[len(parts)-1]]
+	if !ok {
+		return nil, false
+	}
+	if _, isTable := value.(map[string]interface{}); isTable {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set sets the value of the key, replacing any existing value.
+func (c Config) Set(key string, value interface{}) error {
+	parts := strings.Split(key, ".")
+	if slices.ContainsFunc(parts, func(part string) bool { return strings.TrimSpace(part) == "" }) {
+		return fmt.Errorf("invalid key %s", key)
+	}
+
+	table := map[string]interface{}(c)
+	for i, part := range parts[:len(parts)-1] {
+		next, ok := table[part].(map[string]interface{})
+		if !ok {
+			if _, exists := table[part]; exists {
+				return fmt.Errorf("invalid key %s, %s is a value", key, strings.Join(parts[:i+1], "."))
+			}
+			next = map[string]interface{}{}
+			table[part] = next
+		}
+		table = next
+	}
+
+	table[parts[len(parts)-1]] = value
+	return nil
+}
+
+// Unset removes the value of the key, along with any tables left empty.
+// It returns false if the key has no value.
+func (c Config) Unset(key string) bool {
+	return unset(c, strings.Split(key, "."))
+}
+
+func unset(table map[string]interface{}, parts []string) bool {
+	if len(parts) == 1 {
+		value, ok := table[parts[0]]
+		if !ok {
+			return false
+		}
+		if _, isTable := value.(map[string]interface{}); isTable {
+			return false
+		}
+		delete(table, parts[0])
+		return true
+	}
+
+	next, ok := table[parts[0]].(map[string]interface{})
+	if !ok || !unset(next, parts[1:]) {
+		return false
+	}
+	if len(next) == 0 {
+		delete(table, parts[0])
+	}
+	return true
+}
+
+// List returns every value of the Config, sorted by key.
+func (c Config) List() []Entry {
+	var entries []Entry
+	list(c, "", &entries)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+func list(table map[string]interface{}, prefix string, entries *[]Entry) {
+	for k, v := range table {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if next, ok := v.(map[string]interface{}); ok {
+			list(next, key, entries)
+			continue
+		}
+		*entries = append(*entries, Entry{Key: key, Value: strings.Join(flagValues(v), ",")})
+	}
+}
+
+// flagValues converts a value into the values to set its flag to.
+// A list sets the flag once for each of its values, as repeating the flag on the command line would.
+func flagValues(value interface{}) []string {
+	list, ok := value.([]interface{})
+	if !ok {
+		return []string{fmt.Sprint(value)}
+	}
+
+	values := make([]string, len(list))
+	for i, v := range list {
+		values[i] = fmt.Sprint(v)
+	}
+	return values
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "abctl", "config.yaml")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff([]Entry(nil), c.List()); d != "" {
+		t.Error("expected an empty config (-want +got):", d)
+	}
+
+	for key, value := range map[string]string{
+		"dnt":                         "true",
+		"local.install.port":          "9000",
+		"local.install.chart-version": "0.63.0",
+		"local.kubeconfig":            "/tmp/kubeconfig",
+	} {
+		if err := c.Set(key, value); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+	if err := Save(path, c); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if c, err = Load(path); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	exp := []Entry{
+		{Key: "dnt", Value: "true"},
+		{Key: "local.install.chart-version", Value: "0.63.0"},
+		{Key: "local.install.port", Value: "9000"},
+		{Key: "local.kubeconfig", Value: "/tmp/kubeconfig"},
+	}
+	if d := cmp.Diff(exp, c.List()); d != "" {
+		t.Error("entries mismatch (-want +got):", d)
+	}
+
+	if _, ok := c.Get("local.install"); ok {
+		t.Error("expected a table to not be a value")
+	}
+	if err := c.Set("local.install.port.http", "1"); err == nil {
+		t.Error("expected an error setting a key within a value")
+	}
+	if err := c.Set("local..port", "1"); err == nil {
+		t.Error("expected an error setting an invalid key")
+	}
+
+	if c.Unset("local.install") {
+		t.Error("expected a table to not be unset")
+	}
+	if !c.Unset("local.install.port") || !c.Unset("local.install.chart-version") {
+		t.Error("expected the values to be unset")
+	}
+	if c.Unset("local.install.port") {
+		t.Error("expected an unset value to not be unset again")
+	}
+	if d := cmp.Diff(Config{"dnt": "true", "local": map[string]interface{}{"kubeconfig": "/tmp/kubeconfig"}}, c); d != "" {
+		t.Error("config mismatch (-want +got):", d)
+	}
+}
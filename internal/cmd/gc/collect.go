@@ -0,0 +1,518 @@
+package gc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/docker"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/logging"
+	"io/fs"
+	corev1 "k8s.io/api/core/v1"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Kinds of the artifacts collected.
+const (
+	KindContainer  = "container"
+	KindNetwork    = "network"
+	KindImageCache = "image cache"
+	KindChart      = "chart"
+	KindLog        = "log"
+	KindData       = "persisted data"
+)
+
+const (
+	// kindClusterLabel is the docker label kind sets to the cluster name on the container of every node.
+	kindClusterLabel = "io.x-k8s.kind.cluster"
+	kindNetwork      = "kind"
+)
+
+// now can be overwritten for testing purposes.
+var now = time.Now
+
+// Candidate is an artifact of abctl which is no longer used.
+type Candidate struct {
+	Kind   string
+	Name   string
+	Reason string
+	// Size is the size of the files of the artifact in bytes, zero for docker artifacts.
+	Size int64
+	// Persisted is true for persisted data, which is only removed if requested explicitly, as it cannot be recovered.
+	Persisted bool
+
+	remove func(ctx context.Context) error
+}
+
+// Remove removes the artifact.
+func (c Candidate) Remove(ctx context.Context) error {
+	return c.remove(ctx)
+}
+
+// CommandRunner runs the command and returns its combined output.
+type CommandRunner func(ctx context.Context, name string, args ...string) (string, error)
+
+func runCommand(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	return string(out), err
+}
+
+// VolumeClient lists the persistent volumes of the cluster, which reference the persisted data.
+type VolumeClient interface {
+	PersistentVolumeList(ctx context.Context) (*corev1.PersistentVolumeList, error)
+}
+
+// dirs are the directories of the files collected.
+type dirs struct {
+	cache  string
+	charts string
+	logs   string
+	data   string
+}
+
+// Collector finds the artifacts of abctl which are no longer used.
+type Collector struct {
+	run         CommandRunner
+	maxAge      time.Duration
+	clusterName string
+	volumes     func() (VolumeClient, error)
+	dirs        dirs
+}
+
+// Option for configuring the Collector.
+type Option func(*Collector)
+
+// WithCommandRunner defines the runner of the docker commands, primarily for testing purposes.
+func WithCommandRunner(run CommandRunner) Option {
+	return func(c *Collector) {
+		c.run = run
+	}
+}
+
+// WithMaxAge defines the age after which the caches and the rotated log files are stale.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(c *Collector) {
+		c.maxAge = maxAge
+	}
+}
+
+// WithVolumeClient defines the client of the cluster, primarily for testing purposes.
+func WithVolumeClient(client VolumeClient) Option {
+	return func(c *Collector) {
+		c.volumes = func() (VolumeClient, error) {
+			return client, nil
+		}
+	}
+}
+
+// withDirs defines the directories of the files collected, for testing purposes.
+func withDirs(d dirs) Option {
+	return func(c *Collector) {
+		c.dirs = d
+	}
+}
+
+// New returns a Collector of the artifacts of the cluster of the provider.
+func New(provider k8s.Provider, opts ...Option) *Collector {
+	c := &Collector{
+		run:         runCommand,
+		maxAge:      30 * 24 * time.Hour,
+		clusterName: provider.ClusterName,
+		volumes: func() (VolumeClient, error) {
+			restCfg, err := k8s.RestConfig(provider.KubeconfigPath(paths.UserHome), provider.Context)
+			if err != nil {
+				return nil, err
+			}
+			return k8s.NewClient(restCfg)
+		},
+		dirs: dirs{
+			cache:  paths.Cache,
+			charts: filepath.Join(paths.AbCtl, "charts"),
+			logs:   paths.Logs,
+			data:   paths.Data,
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Collect returns the artifacts which are no longer used, in the order they should be removed.
+// The docker artifacts are skipped if docker cannot be reached, as is the image cache, whose container cannot be
+// checked then.
+func (c *Collector) Collect(ctx context.Context) ([]Candidate, error) {
+	var candidates []Candidate
+
+	nodes, errDocker := c.nodes(ctx)
+	if errDocker != nil {
+		logging.Debugf("skipping the docker artifacts, could not list the kind containers: %s", errDocker)
+	} else {
+		orphans, live := orphanedNodes(nodes, c.clusterName)
+		for _, n := range orphans {
+			candidates = append(candidates, c.containerCandidate(n))
+		}
+		if network, ok := c.network(ctx, nodes, live); ok {
+			candidates = append(candidates, network)
+		}
+		cache, err := c.imageCache(ctx)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, cache...)
+	}
+
+	charts, err := staleFiles(c.dirs.charts, KindChart, c.maxAge, nil)
+	if err != nil {
+		return nil, err
+	}
+	candidates = append(candidates, charts...)
+
+	logs, err := staleFiles(c.dirs.logs, KindLog, c.maxAge, rotatedLog)
+	if err != nil {
+		return nil, err
+	}
+	candidates = append(candidates, logs...)
+
+	// without docker it is unknown whether a cluster references the persisted data
+	if errDocker == nil {
+		data, err := c.danglingData(ctx, nodes)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, data...)
+	}
+
+	return candidates, nil
+}
+
+// node is the container of a node of a kind cluster.
+type node struct {
+	Name    string
+	Cluster string
+	State   string
+}
+
+// nodes returns the containers of the nodes of every kind cluster, including those of clusters not created by abctl.
+func (c *Collector) nodes(ctx context.Context) ([]node, error) {
+	out, err := c.run(ctx, "docker", "ps", "-a", "--filter", "label="+kindClusterLabel,
+		"--format", `{{.Names}}\t{{.Label "`+kindClusterLabel+`"}}\t{{.State}}`)
+	if err != nil {
+		return nil, fmt.Errorf("could not list the kind containers: %w: %s", err, strings.TrimSpace(out))
+	}
+	return parseNodes(out), nil
+}
+
+// parseNodes parses the containers listed by docker ps, one tab separated name, cluster, and state per line.
+func parseNodes(out string) []node {
+	var nodes []node
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		nodes = append(nodes, node{Name: fields[0], Cluster: fields[1], State: fields[2]})
+	}
+	return nodes
+}
+
+// orphanedNodes splits the nodes of the abctl cluster into the orphaned ones, left behind by a failed or interrupted
+// installation, and returns the number of the other nodes, of any cluster, which are still in use.
+// The nodes of a cluster are orphaned if its control-plane is missing or was never started. A stopped cluster is
+// still in use, as it is started again with docker.
+func orphanedNodes(nodes []node, clusterName string) ([]node, int) {
+	controlPlane := clusterName + "-control-plane"
+	orphaned := true
+	for _, n := range nodes {
+		if n.Cluster == clusterName && n.Name == controlPlane {
+			orphaned = n.State == "created" || n.State == "dead"
+		}
+	}
+
+	var orphans []node
+	live := 0
+	for _, n := range nodes {
+		if n.Cluster == clusterName && orphaned {
+			orphans = append(orphans, n)
+		} else {
+			live++
+		}
+	}
+	return orphans, live
+}
+
+func (c *Collector) containerCandidate(n node) Candidate {
+	reason := fmt.Sprintf("node of cluster '%s', whose control-plane is missing", n.Cluster)
+	if n.Name == n.Cluster+"-control-plane" {
+		reason = fmt.Sprintf("control-plane of cluster '%s', which was never started", n.Cluster)
+	}
+	return Candidate{
+		Kind:   KindContainer,
+		Name:   n.Name,
+		Reason: reason,
+		remove: func(ctx context.Context) error {
+			// -v removes the anonymous volumes kind creates for the node along with it
+			if out, err := c.run(ctx, "docker", "rm", "-f", "-v", n.Name); err != nil {
+				return fmt.Errorf("could not remove container %s: %w: %s", n.Name, err, strings.TrimSpace(out))
+			}
+			return nil
+		},
+	}
+}
+
+// network returns the kind network as a candidate if no container but the orphaned nodes is attached to it, and no
+// other kind cluster remains which would use it.
+func (c *Collector) network(ctx context.Context, nodes []node, live int) (Candidate, bool) {
+	if live > 0 {
+		return Candidate{}, false
+	}
+	out, err := c.run(ctx, "docker", "network", "inspect", kindNetwork, "--format", "{{range .Containers}}{{.Name}} {{end}}")
+	if err != nil {
+		// most likely the network does not exist
+		logging.Debugf("could not inspect the %s network: %s: %s", kindNetwork, err, strings.TrimSpace(out))
+		return Candidate{}, false
+	}
+
+	names := map[string]bool{}
+	for _, n := range nodes {
+		names[n.Name] = true
+	}
+	for _, attached := range strings.Fields(out) {
+		if !names[attached] {
+			logging.Debugf("keeping the %s network, container %s is attached to it", kindNetwork, attached)
+			return Candidate{}, false
+		}
+	}
+
+	return Candidate{
+		Kind:   KindNetwork,
+		Name:   kindNetwork,
+		Reason: "no kind cluster uses it",
+		remove: func(ctx context.Context) error {
+			if out, err := c.run(ctx, "docker", "network", "rm", kindNetwork); err != nil {
+				return fmt.Errorf("could not remove network %s: %w: %s", kindNetwork, err, strings.TrimSpace(out))
+			}
+			return nil
+		},
+	}, true
+}
+
+// imageCache returns the image cache as a candidate if none of its images was pulled within the max age and its
+// container is not running, which would otherwise serve the images to a cluster.
+func (c *Collector) imageCache(ctx context.Context) ([]Candidate, error) {
+	modified, size, err := walk(c.dirs.cache)
+	if err != nil {
+		return nil, err
+	}
+	if modified.IsZero() || now().Sub(modified) < c.maxAge {
+		return nil, nil
+	}
+
+	out, err := c.run(ctx, "docker", "ps", "-a", "--filter", "name=^"+docker.CacheContainer+"$", "--format", "{{.State}}")
+	if err != nil {
+		logging.Debugf("keeping the image cache, could not determine the state of container %s: %s: %s", docker.CacheContainer, err, strings.TrimSpace(out))
+		return nil, nil
+	}
+	state := strings.TrimSpace(out)
+	if state == "running" {
+		return nil, nil
+	}
+
+	return []Candidate{{
+		Kind:   KindImageCache,
+		Name:   c.dirs.cache,
+		Reason: "last used " + age(modified),
+		Size:   size,
+		remove: func(ctx context.Context) error {
+			if state != "" {
+				if out, err := c.run(ctx, "docker", "rm", "-f", docker.CacheContainer); err != nil {
+					return fmt.Errorf("could not remove container %s: %w: %s", docker.CacheContainer, err, strings.TrimSpace(out))
+				}
+			}
+			return removeAll(c.dirs.cache)
+		},
+	}}, nil
+}
+
+// rotatedLogPattern matches the log files rotated by the logging package, e.g. abctl.log.1.
+var rotatedLogPattern = regexp.MustCompile(`\.log\.\d+$`)
+
+// rotatedLog returns true if the file is a rotated log file, the log file currently written to is never collected.
+func rotatedLog(name string) bool {
+	return rotatedLogPattern.MatchString(name)
+}
+
+// staleFiles returns the files of the dir which were not modified within the max age, and whose name matches if
+// match is not nil.
+func staleFiles(dir, kind string, maxAge time.Duration, match func(name string) bool) ([]Candidate, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", dir, err)
+	}
+
+	var candidates []Candidate
+	for _, e := range entries {
+		if e.IsDir() || (match != nil && !match(e.Name())) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, fmt.Errorf("could not stat %s: %w", filepath.Join(dir, e.Name()), err)
+		}
+		if now().Sub(info.ModTime()) < maxAge {
+			continue
+		}
+
+		path := filepath.Join(dir, e.Name())
+		candidates = append(candidates, Candidate{
+			Kind:   kind,
+			Name:   path,
+			Reason: "last modified " + age(info.ModTime()),
+			Size:   info.Size(),
+			remove: func(context.Context) error {
+				if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					return fmt.Errorf("could not remove %s: %w", path, err)
+				}
+				return nil
+			},
+		})
+	}
+	return candidates, nil
+}
+
+// danglingData returns the directories of the persisted data which no persistent volume of the abctl cluster
+// references by its path, whether created by abctl or provisioned by the local-path-provisioner. Every directory is
+// dangling if the cluster does not exist, and none if it is stopped or cannot be reached, as its persistent volumes
+// are unknown then.
+func (c *Collector) danglingData(ctx context.Context, nodes []node) ([]Candidate, error) {
+	entries, err := os.ReadDir(c.dirs.data)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read persisted data %s: %w", c.dirs.data, err)
+	}
+
+	var referenced map[string]bool
+	reason := fmt.Sprintf("cluster '%s' does not exist", c.clusterName)
+	if orphans, _ := orphanedNodes(nodes, c.clusterName); len(orphans) == 0 && clusterExists(nodes, c.clusterName) {
+		for _, n := range nodes {
+			if n.Name == c.clusterName+"-control-plane" && n.State != "running" {
+				logging.Debugf("keeping the persisted data, cluster '%s' is not running", c.clusterName)
+				return nil, nil
+			}
+		}
+		volumes, err := c.volumes()
+		if err != nil {
+			logging.Debugf("keeping the persisted data, could not connect to cluster '%s': %s", c.clusterName, err)
+			return nil, nil
+		}
+		pvs, err := volumes.PersistentVolumeList(ctx)
+		if err != nil {
+			logging.Debugf("keeping the persisted data, could not list the persistent volumes of cluster '%s': %s", c.clusterName, err)
+			return nil, nil
+		}
+		referenced = map[string]bool{}
+		for _, pv := range pvs.Items {
+			if dir, ok := k8s.PersistedDataDir(pv); ok {
+				referenced[dir] = true
+			}
+		}
+		reason = "no persistent volume references it"
+	}
+
+	var candidates []Candidate
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if referenced[e.Name()] {
+			continue
+		}
+
+		path := filepath.Join(c.dirs.data, e.Name())
+		_, size, err := walk(path)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, Candidate{
+			Kind:      KindData,
+			Name:      path,
+			Reason:    reason,
+			Size:      size,
+			Persisted: true,
+			remove: func(context.Context) error {
+				return removeAll(path)
+			},
+		})
+	}
+	return candidates, nil
+}
+
+// clusterExists returns true if any node belongs to the cluster.
+func clusterExists(nodes []node, clusterName string) bool {
+	for _, n := range nodes {
+		if n.Cluster == clusterName {
+			return true
+		}
+	}
+	return false
+}
+
+// walk returns the latest modification time and the total size of the files of dir, zero if dir does not exist or
+// contains no files.
+func walk(dir string) (time.Time, int64, error) {
+	var (
+		modified time.Time
+		size     int64
+	)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+		size += info.Size()
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return time.Time{}, 0, nil
+	}
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("could not read %s: %w", dir, err)
+	}
+	return modified, size, nil
+}
+
+func removeAll(dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("could not remove %s: %w", dir, err)
+	}
+	return nil
+}
+
+// age returns how long ago t was, in days.
+func age(t time.Time) string {
+	days := int(now().Sub(t).Hours() / 24)
+	if days == 1 {
+		return "1 day ago"
+	}
+	return fmt.Sprintf("%d days ago", days)
+}
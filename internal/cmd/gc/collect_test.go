@@ -0,0 +1,260 @@
+package gc
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockVolumeClient lists a hostPath persistent volume of each of its paths.
+type mockVolumeClient struct {
+	paths []string
+}
+
+func (m mockVolumeClient) PersistentVolumeList(_ context.Context) (*corev1.PersistentVolumeList, error) {
+	pvs := &corev1.PersistentVolumeList{}
+	for _, p := range m.paths {
+		pvs.Items = append(pvs.Items, corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: p}},
+		}})
+	}
+	return pvs, nil
+}
+
+// mockDocker returns a CommandRunner answering the docker commands of the Collector, recording the removals.
+func mockDocker(ps, network, cache string, removed *[]string) CommandRunner {
+	return func(ctx context.Context, name string, args ...string) (string, error) {
+		cmd := strings.Join(args, " ")
+		switch {
+		case strings.HasPrefix(cmd, "ps -a --filter label="):
+			return ps, nil
+		case strings.HasPrefix(cmd, "ps -a --filter name="):
+			return cache, nil
+		case strings.HasPrefix(cmd, "network inspect"):
+			if network == "" {
+				return "Error response from daemon: network kind not found", fmt.Errorf("exit status 1")
+			}
+			return network, nil
+		case strings.HasPrefix(cmd, "rm") || strings.HasPrefix(cmd, "network rm"):
+			*removed = append(*removed, cmd)
+			return "", nil
+		}
+		return "", fmt.Errorf("unexpected command %s", cmd)
+	}
+}
+
+func TestOrphanedNodes(t *testing.T) {
+	tests := []struct {
+		name       string
+		ps         string
+		expOrphans []string
+		expLive    int
+	}{
+		{
+			name:    "running",
+			ps:      "airbyte-abctl-control-plane\tairbyte-abctl\trunning\n",
+			expLive: 1,
+		},
+		{
+			name:    "stopped",
+			ps:      "airbyte-abctl-control-plane\tairbyte-abctl\texited\n",
+			expLive: 1,
+		},
+		{
+			name:       "never started",
+			ps:         "airbyte-abctl-control-plane\tairbyte-abctl\tcreated\nkind-control-plane\tkind\trunning\n",
+			expOrphans: []string{"airbyte-abctl-control-plane"},
+			expLive:    1,
+		},
+		{
+			name:       "control-plane missing",
+			ps:         "airbyte-abctl-worker\tairbyte-abctl\texited\n",
+			expOrphans: []string{"airbyte-abctl-worker"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orphans, live := orphanedNodes(parseNodes(tt.ps), "airbyte-abctl")
+			var names []string
+			for _, n := range orphans {
+				names = append(names, n.Name)
+			}
+			if d := cmp.Diff(tt.expOrphans, names); d != "" {
+				t.Error("orphans mismatch (-want +got):", d)
+			}
+			if d := cmp.Diff(tt.expLive, live); d != "" {
+				t.Error("live mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestCollect(t *testing.T) {
+	n := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return n }
+	t.Cleanup(func() { now = time.Now })
+
+	old := n.Add(-60 * 24 * time.Hour)
+	recent := n.Add(-time.Hour)
+
+	tests := []struct {
+		name       string
+		ps         string
+		network    string
+		cache      string
+		volumes    []string
+		exp        []string
+		expRemoved []string
+	}{
+		{
+			name:    "failed install",
+			ps:      "airbyte-abctl-control-plane\tairbyte-abctl\tcreated\n",
+			network: "airbyte-abctl-control-plane \n",
+			cache:   "exited\n",
+			exp: []string{
+				"container airbyte-abctl-control-plane",
+				"network kind",
+				"image cache cache",
+				"chart charts/airbyte-1.0.0.tgz",
+				"log logs/abctl.log.1",
+				"persisted data data/airbyte-minio-pv",
+				"persisted data data/airbyte-volume-db",
+			},
+			expRemoved: []string{
+				"rm -f -v airbyte-abctl-control-plane",
+				"network rm kind",
+				"rm -f airbyte-abctl-cache",
+			},
+		},
+		{
+			name:    "running install",
+			ps:      "airbyte-abctl-control-plane\tairbyte-abctl\trunning\n",
+			network: "airbyte-abctl-control-plane airbyte-abctl-cache \n",
+			cache:   "running\n",
+			volumes: []string{
+				"/var/local-path-provisioner/airbyte-minio-pv",
+				"/var/local-path-provisioner/airbyte-volume-db",
+				// a volume of e.g. loki, provisioned by the local-path-provisioner
+				"/var/local-path-provisioner/pvc-0b1c2d3e-4f5a-6b7c-8d9e-0f1a2b3c4d5e_airbyte-abctl_storage-airbyte-loki-0",
+			},
+			exp: []string{
+				"chart charts/airbyte-1.0.0.tgz",
+				"log logs/abctl.log.1",
+				"persisted data data/other-airbyte-minio-pv",
+			},
+		},
+		{
+			name:    "stopped install",
+			ps:      "airbyte-abctl-control-plane\tairbyte-abctl\texited\n",
+			network: "\n",
+			exp: []string{
+				"image cache cache",
+				"chart charts/airbyte-1.0.0.tgz",
+				"log logs/abctl.log.1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			write := func(path string, modified time.Time) {
+				path = filepath.Join(dir, path)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chtimes(path, modified, modified); err != nil {
+					t.Fatal(err)
+				}
+			}
+			write("cache/docker/registry/v2/blob", old)
+			write("charts/airbyte-1.0.0.tgz", old)
+			write("charts/airbyte-index.yaml", recent)
+			write("logs/abctl.log", old)
+			write("logs/abctl.log.1", old)
+			write("logs/abctl.log.2", recent)
+			write("data/airbyte-minio-pv/file", recent)
+			write("data/airbyte-volume-db/file", recent)
+			if tt.volumes != nil {
+				write("data/pvc-0b1c2d3e-4f5a-6b7c-8d9e-0f1a2b3c4d5e_airbyte-abctl_storage-airbyte-loki-0/file", recent)
+				write("data/other-airbyte-minio-pv/file", recent)
+			}
+
+			var removed []string
+			c := New(
+				k8s.DefaultProvider,
+				WithCommandRunner(mockDocker(tt.ps, tt.network, tt.cache, &removed)),
+				WithVolumeClient(mockVolumeClient{paths: tt.volumes}),
+				withDirs(dirs{
+					cache:  filepath.Join(dir, "cache"),
+					charts: filepath.Join(dir, "charts"),
+					logs:   filepath.Join(dir, "logs"),
+					data:   filepath.Join(dir, "data"),
+				}),
+			)
+			candidates, err := c.Collect(context.Background())
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			var names []string
+			for _, c := range candidates {
+				name := strings.TrimPrefix(c.Name, dir+string(filepath.Separator))
+				names = append(names, c.Kind+" "+filepath.ToSlash(name))
+				if c.Persisted != (c.Kind == KindData) {
+					t.Errorf("%s %s persisted mismatch", c.Kind, c.Name)
+				}
+			}
+			if d := cmp.Diff(tt.exp, names); d != "" {
+				t.Error("candidates mismatch (-want +got):", d)
+			}
+
+			for _, c := range candidates {
+				if err := c.Remove(context.Background()); err != nil {
+					t.Error("unexpected error", err)
+				}
+				if filepath.IsAbs(c.Name) {
+					if _, err := os.Stat(c.Name); !os.IsNotExist(err) {
+						t.Errorf("%s was not removed", c.Name)
+					}
+				}
+			}
+			if d := cmp.Diff(tt.expRemoved, removed); d != "" {
+				t.Error("removed mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestCollect_NoDocker(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "data", "airbyte-minio-pv"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	c := New(
+		k8s.TestProvider,
+		WithCommandRunner(func(ctx context.Context, name string, args ...string) (string, error) {
+			return "", fmt.Errorf("executable file not found in $PATH")
+		}),
+		withDirs(dirs{data: filepath.Join(dir, "data")}),
+	)
+	candidates, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if len(candidates) != 0 {
+		t.Error("expected no candidates without docker, got", candidates)
+	}
+}
@@ -0,0 +1,109 @@
+package gc
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+// NewCmdGC returns a cobra command for removing the artifacts of abctl which are no longer used.
+func NewCmdGC(provider k8s.Provider) *cobra.Command {
+	var (
+		flagDryRun    bool
+		flagMaxAge    time.Duration
+		flagPersisted bool
+	)
+
+	cmd := &cobra.Command{
+		Use:         "gc",
+		Annotations: audit.Annotations(),
+		Short:       "Remove the artifacts of abctl which are no longer used",
+		Long: `Remove the artifacts of abctl which are no longer used:
+
+  - the docker containers of a kind cluster left behind by a failed or interrupted installation, whose control-plane
+    is missing or was never started, along with their volumes
+  - the kind network, if no kind cluster uses it
+  - the image cache and the downloaded charts, if not used within --max-age
+  - the rotated log files, if older than --max-age
+  - the persisted data no persistent volume of the cluster references, which is only removed with --persisted, as it
+    cannot be recovered
+
+Nothing is removed with --dry-run, which lists what would be removed instead.`,
+		Example: `  abctl gc --dry-run
+  abctl gc --max-age 168h
+  abctl gc --persisted`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ := pterm.DefaultSpinner.Start("Collecting the artifacts which are no longer used")
+
+			if !flagDryRun {
+				unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+				if err != nil {
+					return err
+				}
+				defer unlock()
+			}
+
+			candidates, err := New(provider, WithMaxAge(flagMaxAge)).Collect(cmd.Context())
+			if err != nil {
+				spinner.Fail("Unable to collect the artifacts")
+				return err
+			}
+			if len(candidates) == 0 {
+				spinner.Success("Nothing to remove")
+				return nil
+			}
+			spinner.Success(fmt.Sprintf("Found %d artifact(s) which are no longer used", len(candidates)))
+
+			var (
+				removed int
+				size    int64
+				kept    []Candidate
+			)
+			for _, c := range candidates {
+				if c.Persisted && !flagPersisted {
+					kept = append(kept, c)
+					continue
+				}
+				if flagDryRun {
+					pterm.Info.Printfln("Would remove %s '%s', %s", c.Kind, c.Name, c.Reason)
+					size += c.Size
+					continue
+				}
+				if err := c.Remove(cmd.Context()); err != nil {
+					pterm.Error.Printfln("Unable to remove %s '%s'", c.Kind, c.Name)
+					return err
+				}
+				pterm.Success.Printfln("Removed %s '%s', %s", c.Kind, c.Name, c.Reason)
+				removed++
+				size += c.Size
+			}
+
+			for _, c := range kept {
+				pterm.Info.Printfln("Keeping %s '%s', %s", c.Kind, c.Name, c.Reason)
+			}
+			if len(kept) > 0 {
+				pterm.Info.Println("Run 'abctl gc --persisted' to remove the persisted data, which cannot be recovered")
+			}
+
+			mb := float64(size) / (1024 * 1024)
+			if flagDryRun {
+				pterm.Info.Printfln("Dry run, nothing was removed, %.1f MB would be reclaimed", mb)
+				return nil
+			}
+			pterm.Success.Printfln("Removed %d artifact(s), %.1f MB reclaimed", removed, mb)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flagDryRun, "dry-run", false, "list the artifacts which would be removed, without removing them")
+	cmd.Flags().DurationVar(&flagMaxAge, "max-age", 30*24*time.Hour, "the age after which the image cache, the charts, and the rotated log files are removed")
+	cmd.Flags().BoolVar(&flagPersisted, "persisted", false, "also remove the persisted data no installation references, which cannot be recovered")
+
+	return cmd
+}
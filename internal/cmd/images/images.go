@@ -0,0 +1,102 @@
+package images
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/docker"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// NewCmdImages returns a cobra command for managing the images used by abctl.
+func NewCmdImages() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "images",
+		Short: "Manages the images used by abctl",
+	}
+
+	cmd.AddCommand(newCmdCache(), newCmdScan(), newCmdManifest())
+
+	return cmd
+}
+
+func newCmdCache() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manages the image cache",
+		Long: `Manages the image cache, created by 'abctl local install --image-cache'.
+
+The image cache stores the images pulled by the kind cluster in ` + paths.Cache + `, so they are not downloaded
+again when the cluster is recreated.`,
+	}
+
+	cmd.AddCommand(newCmdCachePrune())
+
+	return cmd
+}
+
+func newCmdCachePrune() *cobra.Command {
+	var flagDockerHost string
+
+	cmd := &cobra.Command{
+		Use:         "prune",
+		Annotations: audit.Annotations(),
+		Short:       "Remove the image cache and every cached image",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dockerClient, err := docker.New(cmd.Context(), docker.WithHost(flagDockerHost))
+			if err != nil {
+				pterm.Error.Println("Could not connect to Docker daemon")
+				return fmt.Errorf("could not connect to docker: %w", err)
+			}
+
+			if err := dockerClient.RemoveCache(cmd.Context()); err != nil {
+				pterm.Error.Printfln("Unable to remove the image cache container '%s'", docker.CacheContainer)
+				return err
+			}
+
+			size, err := dirSize(paths.Cache)
+			if err != nil {
+				logging.Debugf("could not determine size of %s: %s", paths.Cache, err)
+			}
+			if err := os.RemoveAll(paths.Cache); err != nil {
+				pterm.Error.Printfln("Unable to remove the cached images '%s'", paths.Cache)
+				return fmt.Errorf("could not remove %s: %w", paths.Cache, err)
+			}
+
+			pterm.Success.Printfln("Image cache pruned, %.1f MB reclaimed", float64(size)/(1024*1024))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flagDockerHost, "docker-host", "", "the docker host to connect to (e.g. unix:///var/run/docker.sock), defaults to DOCKER_HOST or the first detected docker socket")
+
+	return cmd
+}
+
+// dirSize returns the total size of the files in dir, zero if dir does not exist.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	return size, err
+}
@@ -0,0 +1,41 @@
+package images
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirSize(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "docker", "registry"), 0755); err != nil {
+		t.Fatal("could not create directories", err)
+	}
+	for path, contents := range map[string]string{
+		"data":                        "12345",
+		"docker/registry/blob":        "1234567890",
+		"docker/registry/blob-digest": "123",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(contents), 0644); err != nil {
+			t.Fatal("could not write file", err)
+		}
+	}
+
+	size, err := dirSize(dir)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(int64(18), size); d != "" {
+		t.Error("size mismatch (-want +got):", d)
+	}
+
+	// a missing directory has no size
+	size, err = dirSize(filepath.Join(dir, "missing"))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(int64(0), size); d != "" {
+		t.Error("size mismatch (-want +got):", d)
+	}
+}
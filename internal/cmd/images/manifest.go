@@ -0,0 +1,261 @@
+package images
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+const outputSkopeo = "skopeo"
+
+// manifestConcurrency is the number of images resolved concurrently.
+const manifestConcurrency = 8
+
+// entry is an image of the chart resolved for an architecture.
+type entry struct {
+	Image string `json:"image"`
+	// Digest is the digest of the image, which is the digest of the index of a multi-arch image.
+	Digest string `json:"digest,omitempty"`
+	// PlatformDigest is the digest of the image of the architecture, empty if the image lacks the architecture.
+	PlatformDigest string   `json:"platform_digest,omitempty"`
+	Platforms      []string `json:"platforms,omitempty"`
+	// Missing is true if the image lacks the architecture, and would run emulated, if at all.
+	Missing bool   `json:"missing,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func newCmdManifest() *cobra.Command {
+	var (
+		flagChartVersion  string
+		flagChart         string
+		flagChartUsername string
+		flagChartPassword string
+		flagValues        string
+		flagArch          string
+		flagOutput        string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "manifest",
+		Short: "List the images of the Airbyte helm chart, with their digests for an architecture",
+		Long: `List the images of the Airbyte helm chart, with their digests for an architecture.
+
+The chart is rendered locally to determine the images it installs, whose manifests are fetched from their registries
+to resolve the digest of the image of the architecture, which defaults to the architecture of this machine. An image
+lacking the architecture (e.g. arm64 on Apple Silicon) runs emulated, slowly or not at all, and is flagged.
+
+The skopeo output is a 'skopeo sync --src yaml' source, for mirroring the images into another registry.`,
+		Example: `  abctl images manifest
+  abctl images manifest --arch arm64 --chart-version 0.64.0
+  abctl images manifest --output skopeo > airbyte.yaml && skopeo sync --all --src yaml --dest docker airbyte.yaml my.registry.example.com`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			switch flagOutput {
+			case outputTable, outputJSON, outputSkopeo:
+			default:
+				return fmt.Errorf("output must be one of %s, %s, or %s, received %s", outputTable, outputJSON, outputSkopeo, flagOutput)
+			}
+			switch flagArch {
+			case "amd64", "arm64":
+				return nil
+			default:
+				return fmt.Errorf("arch must be amd64 or arm64, received %s", flagArch)
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var valuesYAML string
+			if flagValues != "" {
+				raw, err := os.ReadFile(flagValues)
+				if err != nil {
+					pterm.Error.Printfln("Unable to read the values file '%s'", flagValues)
+					return fmt.Errorf("could not read values file %s: %w", flagValues, err)
+				}
+				valuesYAML = string(raw)
+			}
+
+			spinner, _ := pterm.DefaultSpinner.Start("Fetching Airbyte Helm Chart")
+
+			helm, err := local.NewHelmChartClient()
+			if err != nil {
+				spinner.Fail("Unable to create Helm client")
+				return err
+			}
+
+			version := flagChartVersion
+			if version == "latest" {
+				version = ""
+			}
+			loc, err := local.ParseChartLoc(flagChart)
+			if err != nil {
+				spinner.Fail(fmt.Sprintf("Invalid --chart '%s'", flagChart))
+				return err
+			}
+			chart, err := local.LoadAirbyteChart(helm, loc, version, local.RegistryAuth{Username: flagChartUsername, Password: flagChartPassword})
+			if err != nil {
+				spinner.Fail("Unable to fetch Airbyte Helm Chart")
+				return err
+			}
+
+			images, err := local.FindImagesFromChart(chart, valuesYAML)
+			if err != nil {
+				spinner.Fail("Unable to determine the images of the Airbyte Helm Chart")
+				return err
+			}
+
+			spinner.UpdateText(fmt.Sprintf("Resolving %d images of the Airbyte Helm Chart (version: %s) for %s", len(images), chart.Metadata.Version, flagArch))
+			entries := resolveImages(cmd.Context(), newRegistryClient(http.DefaultClient), images, flagArch)
+
+			var missing, failed []string
+			for _, e := range entries {
+				switch {
+				case e.Error != "":
+					failed = append(failed, e.Image)
+				case e.Missing:
+					missing = append(missing, e.Image)
+				}
+			}
+			spinner.Success(fmt.Sprintf("Resolved %d images of the Airbyte Helm Chart (version: %s) for %s", len(images)-len(failed), chart.Metadata.Version, flagArch))
+
+			if len(failed) > 0 {
+				pterm.Warning.Printfln("Unable to resolve %d image(s), which may require authentication: %s", len(failed), strings.Join(failed, ", "))
+			}
+			if len(missing) > 0 {
+				pterm.Warning.Printfln("%d image(s) lack a linux/%s variant and run emulated, slowly or not at all: %s", len(missing), flagArch, strings.Join(missing, ", "))
+			}
+
+			return printEntries(cmd.OutOrStdout(), flagOutput, entries)
+		},
+	}
+
+	cmd.Flags().StringVar(&flagChartVersion, "chart-version", "latest", "the version of the Airbyte helm chart")
+	cmd.Flags().StringVar(&flagChart, "chart", "", "the Airbyte helm chart of a local chart directory or archive, an http(s) url, or an oci:// reference, instead of the Airbyte helm repository")
+	cmd.Flags().StringVar(&flagChartUsername, "chart-username", "", "the username of the oci registry of --chart, defaults to the credentials of 'helm registry login' or 'docker login'")
+	cmd.Flags().StringVar(&flagChartPassword, "chart-password", "", "the password of the oci registry of --chart (also ABCTL_IMAGES_MANIFEST_CHART_PASSWORD)")
+	cmd.Flags().StringVar(&flagValues, "values", "", "the Airbyte helm values file, as provided to 'abctl local install', which may change the installed images")
+	cmd.Flags().StringVar(&flagArch, "arch", runtime.GOARCH, "the architecture the images are resolved for, amd64 or arm64")
+	cmd.Flags().StringVarP(&flagOutput, "output", "o", outputTable, "the output format, either table, json, or skopeo")
+
+	return cmd
+}
+
+// resolveImages resolves the images for the linux platform of the arch. An image which cannot be resolved is returned
+// with its error.
+func resolveImages(ctx context.Context, client *registryClient, images []string, arch string) []entry {
+	entries := make([]entry, len(images))
+
+	var g errgroup.Group
+	g.SetLimit(manifestConcurrency)
+	for i, image := range images {
+		g.Go(func() error {
+			entries[i] = resolveImage(ctx, client, image, arch)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	return entries
+}
+
+func resolveImage(ctx context.Context, client *registryClient, image, arch string) entry {
+	e := entry{Image: image}
+
+	ref, err := parseImage(image)
+	if err != nil {
+		e.Error = err.Error()
+		return e
+	}
+	r, err := client.resolve(ctx, ref)
+	if err != nil {
+		e.Error = err.Error()
+		return e
+	}
+
+	e.Digest = r.Digest
+	for _, p := range r.Platforms {
+		e.Platforms = append(e.Platforms, p.String())
+		if p.OS == "linux" && p.Architecture == arch && e.PlatformDigest == "" {
+			e.PlatformDigest = r.Digests[p.String()]
+		}
+	}
+	e.Missing = e.PlatformDigest == ""
+	return e
+}
+
+// printEntries writes the entries to w in the output format.
+func printEntries(w io.Writer, output string, entries []entry) error {
+	switch output {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case outputSkopeo:
+		return writeSkopeoSync(w, entries)
+	}
+
+	data := pterm.TableData{{"IMAGE", "DIGEST", "PLATFORMS"}}
+	for _, e := range entries {
+		digest := e.PlatformDigest
+		switch {
+		case e.Error != "":
+			digest = "error: " + e.Error
+		case e.Missing:
+			digest = "missing"
+		}
+		data = append(data, []string{e.Image, digest, strings.Join(e.Platforms, ", ")})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithWriter(w).WithData(data).Render()
+}
+
+// skopeoRegistry is a registry of a 'skopeo sync --src yaml' source.
+type skopeoRegistry struct {
+	Images map[string][]string `yaml:"images"`
+}
+
+// writeSkopeoSync writes the images as a 'skopeo sync --src yaml' source, listing the tags, or the digests of pinned
+// images, of the repositories of each registry.
+func writeSkopeoSync(w io.Writer, entries []entry) error {
+	registries := map[string]skopeoRegistry{}
+	for _, e := range entries {
+		ref, err := parseImage(e.Image)
+		if err != nil {
+			return err
+		}
+		reg, ok := registries[ref.Registry]
+		if !ok {
+			reg = skopeoRegistry{Images: map[string][]string{}}
+			registries[ref.Registry] = reg
+		}
+		repository := strings.TrimPrefix(ref.Repository, "library/")
+		if !contains(reg.Images[repository], ref.Reference) {
+			reg.Images[repository] = append(reg.Images[repository], ref.Reference)
+			sort.Strings(reg.Images[repository])
+		}
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(registries); err != nil {
+		return fmt.Errorf("could not encode skopeo sync source: %w", err)
+	}
+	return enc.Close()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseImage(t *testing.T) {
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		image  string
+		exp    imageRef
+		expErr bool
+	}{
+		{image: "busybox", exp: imageRef{Registry: "docker.io", Repository: "library/busybox", Reference: "latest"}},
+		{image: "airbyte/server:0.63.0", exp: imageRef{Registry: "docker.io", Repository: "airbyte/server", Reference: "0.63.0"}},
+		{image: "registry.k8s.io/ingress-nginx/controller:v1.10.0@" + digest, exp: imageRef{Registry: "registry.k8s.io", Repository: "ingress-nginx/controller", Reference: digest}},
+		{image: "localhost:5000/airbyte/server", exp: imageRef{Registry: "localhost:5000", Repository: "airbyte/server", Reference: "latest"}},
+		{image: "Airbyte/Server", expErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			ref, err := parseImage(tt.image)
+			if tt.expErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, ref); d != "" {
+				t.Error("reference mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	exp := map[string]string{"realm": "https://auth.docker.io/token", "service": "registry.docker.io", "scope": "repository:airbyte/server:pull"}
+	got := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:airbyte/server:pull"`)
+	if d := cmp.Diff(exp, got); d != "" {
+		t.Error("params mismatch (-want +got):", d)
+	}
+	if parseChallenge(`Basic realm="registry"`) != nil {
+		t.Error("expected no params of a basic challenge")
+	}
+}
+
+// testRegistry serves a multi-arch image airbyte/server:1.0.0, an amd64 only image airbyte/worker:1.0.0, and
+// requires a bearer token issued anonymously.
+func testRegistry(t *testing.T) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if scope := r.URL.Query().Get("scope"); !strings.HasPrefix(scope, "repository:airbyte/") || !strings.HasSuffix(scope, ":pull") {
+				t.Error("unexpected scope", scope)
+			}
+			_, _ = w.Write([]byte(`{"token":"t0ken"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer t0ken" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/airbyte/server/manifests/1.0.0":
+			w.Header().Set("Docker-Content-Digest", "sha256:index")
+			_, _ = w.Write([]byte(`{"mediaType":"` + mediaTypeOCIIndex + `","manifests":[
+{"digest":"sha256:amd","platform":{"os":"linux","architecture":"amd64"}},
+{"digest":"sha256:arm","platform":{"os":"linux","architecture":"arm64","variant":"v8"}},
+{"digest":"sha256:att","platform":{"os":"unknown","architecture":"unknown"}}]}`))
+		case "/v2/airbyte/worker/manifests/1.0.0":
+			w.Header().Set("Docker-Content-Digest", "sha256:worker")
+			w.Header().Set("Content-Type", mediaTypeDockerManifest)
+			_, _ = w.Write([]byte(`{"schemaVersion":2,"config":{"digest":"sha256:config"}}`))
+		case "/v2/airbyte/worker/blobs/sha256:config":
+			_, _ = w.Write([]byte(`{"os":"linux","architecture":"amd64"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestResolveImages(t *testing.T) {
+	srv := testRegistry(t)
+	client := newRegistryClient(srv.Client())
+	client.baseURL = func(host string) string {
+		if host != "docker.io" {
+			t.Error("unexpected registry", host)
+		}
+		return srv.URL
+	}
+
+	images := []string{"airbyte/server:1.0.0", "airbyte/worker:1.0.0", "airbyte/missing:1.0.0"}
+
+	entries := resolveImages(context.Background(), client, images, "arm64")
+	exp := []entry{
+		{Image: "airbyte/server:1.0.0", Digest: "sha256:index", PlatformDigest: "sha256:arm", Platforms: []string{"linux/amd64", "linux/arm64/v8"}},
+		{Image: "airbyte/worker:1.0.0", Digest: "sha256:worker", Platforms: []string{"linux/amd64"}, Missing: true},
+		{Image: "airbyte/missing:1.0.0", Error: "could not fetch manifest of airbyte/missing:1.0.0: airbyte/missing:1.0.0 not found"},
+	}
+	if d := cmp.Diff(exp, entries); d != "" {
+		t.Error("arm64 entries mismatch (-want +got):", d)
+	}
+
+	entries = resolveImages(context.Background(), client, images[:2], "amd64")
+	if d := cmp.Diff([]string{"sha256:amd", "sha256:worker"}, []string{entries[0].PlatformDigest, entries[1].PlatformDigest}); d != "" {
+		t.Error("amd64 digests mismatch (-want +got):", d)
+	}
+}
+
+func TestWriteSkopeoSync(t *testing.T) {
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	entries := []entry{
+		{Image: "airbyte/server:1.0.0"},
+		{Image: "airbyte/server:0.9.0"},
+		{Image: "busybox:1.35"},
+		{Image: "busybox:1.35"},
+		{Image: "registry.k8s.io/ingress-nginx/controller:v1.10.0@" + digest},
+	}
+
+	var buf bytes.Buffer
+	if err := writeSkopeoSync(&buf, entries); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := `docker.io:
+  images:
+    airbyte/server:
+      - 0.9.0
+      - 1.0.0
+    busybox:
+      - "1.35"
+registry.k8s.io:
+  images:
+    ingress-nginx/controller:
+      - sha256:` + digest[len("sha256:"):] + `
+`
+	if d := cmp.Diff(exp, buf.String()); d != "" {
+		t.Error("skopeo sync mismatch (-want +got):", d)
+	}
+}
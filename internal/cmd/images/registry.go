@@ -0,0 +1,312 @@
+package images
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// the media types of the manifests, of both docker and oci images
+const (
+	mediaTypeDockerList     = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeOCIIndex       = "application/vnd.oci.image.index.v1+json"
+	mediaTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+)
+
+var errUnauthorized = errors.New("the registry requires authentication")
+
+// imageRef is a parsed image reference, e.g. airbyte/server:0.63.0 is the repository airbyte/server of docker hub.
+type imageRef struct {
+	Registry   string
+	Repository string
+	// Reference is the digest of the image if pinned, or else its tag.
+	Reference string
+}
+
+// parseImage parses the image reference, normalized like docker does: an image without a registry is an image of
+// docker hub, an official image of docker hub is an image of its library, and an image without a tag is latest.
+func parseImage(image string) (imageRef, error) {
+	ref := imageRef{Registry: "docker.io", Repository: image, Reference: "latest"}
+
+	if i := strings.Index(ref.Repository, "@"); i >= 0 {
+		ref.Repository, ref.Reference = ref.Repository[:i], ref.Repository[i+1:]
+		// a tag is ignored by docker if the image is pinned to a digest
+		if j := strings.LastIndex(ref.Repository, ":"); j > strings.LastIndex(ref.Repository, "/") {
+			ref.Repository = ref.Repository[:j]
+		}
+	} else if j := strings.LastIndex(ref.Repository, ":"); j > strings.LastIndex(ref.Repository, "/") {
+		ref.Repository, ref.Reference = ref.Repository[:j], ref.Repository[j+1:]
+	}
+
+	if i := strings.Index(ref.Repository, "/"); i >= 0 {
+		first := ref.Repository[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			ref.Registry, ref.Repository = first, ref.Repository[i+1:]
+		}
+	}
+	if ref.Registry == "docker.io" && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+
+	if ref.Repository == "" || ref.Reference == "" || strings.ToLower(ref.Repository) != ref.Repository {
+		return imageRef{}, fmt.Errorf("invalid image reference %s", image)
+	}
+	return ref, nil
+}
+
+// Name returns the reference of the image within its registry, e.g. airbyte/server:0.63.0.
+func (r imageRef) Name() string {
+	if strings.Contains(r.Reference, ":") {
+		return r.Repository + "@" + r.Reference
+	}
+	return r.Repository + ":" + r.Reference
+}
+
+// platform is the platform of an image, e.g. linux/arm64.
+type platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+func (p platform) String() string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// manifest is the manifest of a single platform image, or an index of the manifests of a multi-arch image.
+type manifest struct {
+	MediaType string `json:"mediaType"`
+	Config    struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+	Manifests []struct {
+		Digest   string   `json:"digest"`
+		Platform platform `json:"platform"`
+	} `json:"manifests"`
+}
+
+// resolved is an image resolved by its registry.
+type resolved struct {
+	// Digest is the digest of the image, which is the digest of the index of a multi-arch image.
+	Digest string
+	// Platforms are the platforms the image is available for, excluding unknown platforms, e.g. of attestations.
+	Platforms []platform
+	// Digests are the digests of the image of each platform, by the string of the platform.
+	Digests map[string]string
+}
+
+type doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// registryClient fetches the manifests of images anonymously from their registries.
+type registryClient struct {
+	doer doer
+	// baseURL returns the url of the registry api of the host, e.g. https://registry-1.docker.io for docker.io.
+	baseURL func(host string) string
+
+	// tokens are the bearer tokens of the repositories
+	tokens sync.Map
+}
+
+func newRegistryClient(doer doer) *registryClient {
+	return &registryClient{doer: doer, baseURL: func(host string) string {
+		if host == "docker.io" {
+			host = "registry-1.docker.io"
+		}
+		return "https://" + host
+	}}
+}
+
+// resolve returns the digests and platforms of the image.
+// The config of a single platform image is fetched to determine its platform.
+func (c *registryClient) resolve(ctx context.Context, ref imageRef) (resolved, error) {
+	m, digest, err := c.manifest(ctx, ref, ref.Reference)
+	if err != nil {
+		return resolved{}, err
+	}
+
+	r := resolved{Digest: digest, Digests: map[string]string{}}
+	switch m.MediaType {
+	case mediaTypeDockerList, mediaTypeOCIIndex:
+		for _, pm := range m.Manifests {
+			// attestations are listed with the unknown/unknown platform
+			if pm.Platform.OS == "unknown" || pm.Platform.OS == "" {
+				continue
+			}
+			r.Platforms = append(r.Platforms, pm.Platform)
+			r.Digests[pm.Platform.String()] = pm.Digest
+		}
+	default:
+		var p platform
+		if err := c.get(ctx, ref, "/blobs/"+m.Config.Digest, "", &p, nil); err != nil {
+			return resolved{}, fmt.Errorf("could not fetch config of %s: %w", ref.Name(), err)
+		}
+		r.Platforms = []platform{p}
+		r.Digests[p.String()] = digest
+	}
+	return r, nil
+}
+
+// manifest fetches the manifest of the reference of the repository, returning the manifest and its digest.
+func (c *registryClient) manifest(ctx context.Context, ref imageRef, reference string) (manifest, string, error) {
+	var (
+		m      manifest
+		digest string
+	)
+	accept := strings.Join([]string{mediaTypeOCIIndex, mediaTypeDockerList, mediaTypeOCIManifest, mediaTypeDockerManifest}, ", ")
+	err := c.get(ctx, ref, "/manifests/"+reference, accept, &m, func(res *http.Response) {
+		digest = res.Header.Get("Docker-Content-Digest")
+		if m.MediaType == "" {
+			m.MediaType = res.Header.Get("Content-Type")
+		}
+	})
+	if err != nil {
+		return manifest{}, "", fmt.Errorf("could not fetch manifest of %s: %w", ref.Name(), err)
+	}
+	if m.MediaType == "" {
+		m.MediaType = mediaTypeDockerManifest
+	}
+	if digest == "" && strings.Contains(reference, ":") {
+		digest = reference
+	}
+	return m, digest, nil
+}
+
+// get decodes the response of the path of the repository api into v, authenticating with an anonymous bearer token
+// if the registry requires one. The response is passed to inspect, if not nil, once decoded.
+func (c *registryClient) get(ctx context.Context, ref imageRef, path, accept string, v interface{}, inspect func(*http.Response)) error {
+	u := fmt.Sprintf("%s/v2/%s%s", c.baseURL(ref.Registry), ref.Repository, path)
+
+	res, err := c.do(ctx, ref, u, accept)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode == http.StatusUnauthorized {
+		challenge := res.Header.Get("WWW-Authenticate")
+		res.Body.Close()
+		if err := c.authenticate(ctx, ref, challenge); err != nil {
+			return err
+		}
+		if res, err = c.do(ctx, ref, u, accept); err != nil {
+			return err
+		}
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errUnauthorized
+	case http.StatusNotFound:
+		return fmt.Errorf("%s not found", ref.Name())
+	default:
+		return fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("could not decode response: %w", err)
+	}
+	if inspect != nil {
+		inspect(res)
+	}
+	return nil
+}
+
+func (c *registryClient) do(ctx context.Context, ref imageRef, u, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if token, ok := c.tokens.Load(ref.Registry + "/" + ref.Repository); ok {
+		req.Header.Set("Authorization", "Bearer "+token.(string))
+	}
+
+	res, err := c.doer.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch %s: %w", u, err)
+	}
+	return res, nil
+}
+
+// authenticate fetches an anonymous bearer token for pulling the repository, as requested by the challenge of the
+// WWW-Authenticate header, e.g. Bearer realm="https://auth.docker.io/token",service="registry.docker.io".
+func (c *registryClient) authenticate(ctx context.Context, ref imageRef, challenge string) error {
+	params := parseChallenge(challenge)
+	if params == nil || params["realm"] == "" {
+		return errUnauthorized
+	}
+
+	q := url.Values{}
+	if params["service"] != "" {
+		q.Set("service", params["service"])
+	}
+	q.Set("scope", fmt.Sprintf("repository:%s:pull", ref.Repository))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, params["realm"]+"?"+q.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+
+	res, err := c.doer.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not fetch token of %s: %w", ref.Registry, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return errUnauthorized
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&token); err != nil {
+		return fmt.Errorf("could not decode token of %s: %w", ref.Registry, err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	c.tokens.Store(ref.Registry+"/"+ref.Repository, token.Token)
+	return nil
+}
+
+// parseChallenge returns the parameters of a Bearer challenge, or nil if the challenge is not a Bearer challenge.
+func parseChallenge(challenge string) map[string]string {
+	rest, ok := strings.CutPrefix(challenge, "Bearer ")
+	if !ok {
+		return nil
+	}
+
+	params := map[string]string{}
+	for rest != "" {
+		key, value, ok := strings.Cut(strings.TrimLeft(rest, ", "), "=")
+		if !ok {
+			break
+		}
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				break
+			}
+			params[key], rest = value[1:end+1], value[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(value, ",")
+			params[key] = value
+		}
+	}
+	return params
+}
@@ -0,0 +1,205 @@
+package images
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/docker"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/progress"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// summary is the number of vulnerabilities of each severity found within a single image.
+type summary struct {
+	Image    string `json:"image"`
+	Critical int    `json:"critical"`
+	High     int    `json:"high"`
+	Medium   int    `json:"medium"`
+	Low      int    `json:"low"`
+	Unknown  int    `json:"unknown"`
+}
+
+func newCmdScan() *cobra.Command {
+	var (
+		flagChartVersion  string
+		flagChart         string
+		flagChartUsername string
+		flagChartPassword string
+		flagValues        string
+		flagOutput        string
+		flagDockerHost    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "scan",
+		Short: "Scan the images of the Airbyte helm chart for vulnerabilities",
+		Long: `Scan the images of the Airbyte helm chart for vulnerabilities.
+
+The chart is rendered locally to determine the images it installs, which are then scanned by trivy (` + docker.ScanImage + `),
+run within a Docker container. The number of vulnerabilities of each severity is reported per image.`,
+		Example: `  abctl images scan
+  abctl images scan --chart-version 0.64.0 --values values.yaml --output json
+  abctl images scan --chart ./charts/airbyte`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			switch flagOutput {
+			case outputTable, outputJSON:
+				return nil
+			default:
+				return fmt.Errorf("output must be one of %s or %s, received %s", outputTable, outputJSON, flagOutput)
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var valuesYAML string
+			if flagValues != "" {
+				raw, err := os.ReadFile(flagValues)
+				if err != nil {
+					pterm.Error.Printfln("Unable to read the values file '%s'", flagValues)
+					return fmt.Errorf("could not read values file %s: %w", flagValues, err)
+				}
+				valuesYAML = string(raw)
+			}
+
+			spinner, _ := pterm.DefaultSpinner.Start("Fetching Airbyte Helm Chart")
+
+			helm, err := local.NewHelmChartClient()
+			if err != nil {
+				spinner.Fail("Unable to create Helm client")
+				return err
+			}
+
+			version := flagChartVersion
+			if version == "latest" {
+				version = ""
+			}
+			loc, err := local.ParseChartLoc(flagChart)
+			if err != nil {
+				spinner.Fail(fmt.Sprintf("Invalid --chart '%s'", flagChart))
+				return err
+			}
+			chart, err := local.LoadAirbyteChart(helm, loc, version, local.RegistryAuth{Username: flagChartUsername, Password: flagChartPassword})
+			if err != nil {
+				spinner.Fail("Unable to fetch Airbyte Helm Chart")
+				return err
+			}
+
+			images, err := local.FindImagesFromChart(chart, valuesYAML)
+			if err != nil {
+				spinner.Fail("Unable to determine the images of the Airbyte Helm Chart")
+				return err
+			}
+
+			dockerClient, err := docker.New(cmd.Context(), docker.WithHost(flagDockerHost))
+			if err != nil {
+				spinner.Fail("Could not connect to Docker daemon")
+				return fmt.Errorf("could not connect to docker: %w", err)
+			}
+
+			dir, err := os.MkdirTemp("", "abctl-scan-")
+			if err != nil {
+				spinner.Fail("Unable to create a temporary directory")
+				return fmt.Errorf("could not create temporary directory: %w", err)
+			}
+			defer os.RemoveAll(dir)
+
+			scanning := fmt.Sprintf("Scanning %d images of the Airbyte Helm Chart (version: %s)", len(images), chart.Metadata.Version)
+			spinner.UpdateText(scanning)
+			reports, err := dockerClient.Scan(cmd.Context(), dir, images, progress.Spinner(spinner, scanning))
+			if err != nil {
+				spinner.Fail("Unable to scan the images")
+				return err
+			}
+
+			summaries := make([]summary, len(images))
+			for i, img := range images {
+				raw, err := os.ReadFile(reports[i])
+				if err != nil {
+					spinner.Fail("Unable to read the scan reports")
+					return fmt.Errorf("could not read scan report of %s: %w", img, err)
+				}
+				if summaries[i], err = summarize(img, raw); err != nil {
+					spinner.Fail("Unable to read the scan reports")
+					return err
+				}
+			}
+			spinner.Success(fmt.Sprintf("Scanned %d images of the Airbyte Helm Chart (version: %s)", len(images), chart.Metadata.Version))
+
+			return printSummaries(cmd.OutOrStdout(), flagOutput, summaries)
+		},
+	}
+
+	cmd.Flags().StringVar(&flagChartVersion, "chart-version", "latest", "the version of the Airbyte helm chart to scan")
+	cmd.Flags().StringVar(&flagChart, "chart", "", "scan the Airbyte helm chart of a local chart directory or archive, an http(s) url, or an oci:// reference, instead of the Airbyte helm repository")
+	cmd.Flags().StringVar(&flagChartUsername, "chart-username", "", "the username of the oci registry of --chart, defaults to the credentials of 'helm registry login' or 'docker login'")
+	cmd.Flags().StringVar(&flagChartPassword, "chart-password", "", "the password of the oci registry of --chart (also ABCTL_IMAGES_SCAN_CHART_PASSWORD)")
+	cmd.Flags().StringVar(&flagValues, "values", "", "the Airbyte helm values file, as provided to 'abctl local install', which may change the installed images")
+	cmd.Flags().StringVarP(&flagOutput, "output", "o", outputTable, "the output format, either table or json")
+	cmd.Flags().StringVar(&flagDockerHost, "docker-host", "", "the docker host to connect to (e.g. unix:///var/run/docker.sock), defaults to DOCKER_HOST or the first detected docker socket")
+
+	return cmd
+}
+
+// summarize counts the vulnerabilities of each severity within the trivy json report of the image.
+func summarize(image string, report []byte) (summary, error) {
+	var parsed struct {
+		Results []struct {
+			Vulnerabilities []struct {
+				Severity string
+			}
+		}
+	}
+	if err := json.Unmarshal(report, &parsed); err != nil {
+		return summary{}, fmt.Errorf("could not decode scan report of %s: %w", image, err)
+	}
+
+	s := summary{Image: image}
+	for _, result := range parsed.Results {
+		for _, vuln := range result.Vulnerabilities {
+			switch strings.ToUpper(vuln.Severity) {
+			case "CRITICAL":
+				s.Critical++
+			case "HIGH":
+				s.High++
+			case "MEDIUM":
+				s.Medium++
+			case "LOW":
+				s.Low++
+			default:
+				s.Unknown++
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// printSummaries writes the summaries to w in the output format.
+func printSummaries(w io.Writer, output string, summaries []summary) error {
+	if output == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summaries)
+	}
+
+	data := pterm.TableData{{"IMAGE", "CRITICAL", "HIGH", "MEDIUM", "LOW", "UNKNOWN"}}
+	for _, s := range summaries {
+		data = append(data, []string{
+			s.Image,
+			fmt.Sprintf("%d", s.Critical),
+			fmt.Sprintf("%d", s.High),
+			fmt.Sprintf("%d", s.Medium),
+			fmt.Sprintf("%d", s.Low),
+			fmt.Sprintf("%d", s.Unknown),
+		})
+	}
+
+	return pterm.DefaultTable.WithHasHeader().WithWriter(w).WithData(data).Render()
+}
@@ -0,0 +1,70 @@
+package images
+
+import (
+	"bytes"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	report := `{
+  "ArtifactName": "airbyte/server:0.60.0",
+  "Results": [
+    {
+      "Target": "airbyte/server:0.60.0 (debian 12.5)",
+      "Vulnerabilities": [
+        {"VulnerabilityID": "CVE-2024-0001", "Severity": "CRITICAL"},
+        {"VulnerabilityID": "CVE-2024-0002", "Severity": "HIGH"},
+        {"VulnerabilityID": "CVE-2024-0003", "Severity": "HIGH"},
+        {"VulnerabilityID": "CVE-2024-0004", "Severity": "LOW"}
+      ]
+    },
+    {
+      "Target": "Java",
+      "Vulnerabilities": [
+        {"VulnerabilityID": "CVE-2024-0005", "Severity": "MEDIUM"},
+        {"VulnerabilityID": "CVE-2024-0006", "Severity": "UNKNOWN"}
+      ]
+    },
+    {
+      "Target": "Node.js"
+    }
+  ]
+}`
+
+	s, err := summarize("airbyte/server:0.60.0", []byte(report))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	exp := summary{Image: "airbyte/server:0.60.0", Critical: 1, High: 2, Medium: 1, Low: 1, Unknown: 1}
+	if d := cmp.Diff(exp, s); d != "" {
+		t.Error("summary mismatch (-want +got):", d)
+	}
+
+	if _, err := summarize("airbyte/server:0.60.0", []byte("not json")); err == nil {
+		t.Error("expected error for an invalid report")
+	}
+}
+
+func TestPrintSummaries_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	summaries := []summary{{Image: "busybox:1.35", High: 3}}
+	if err := printSummaries(&buf, outputJSON, summaries); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := `[
+  {
+    "image": "busybox:1.35",
+    "critical": 0,
+    "high": 3,
+    "medium": 0,
+    "low": 0,
+    "unknown": 0
+  }
+]
+`
+	if d := cmp.Diff(exp, buf.String()); d != "" {
+		t.Error("output mismatch (-want +got):", d)
+	}
+}
@@ -0,0 +1,78 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// summaryName is the name of the file, within the bundle, which summarizes the collection of every item.
+const summaryName = "summary.txt"
+
+// Write writes the results as a gzipped tar archive to w.
+// Every result with data is written as its own file, named after the result, under the root directory.
+// A summary of all the results, including any errors, is also written.
+func Write(w io.Writer, root string, results []Result) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	now := time.Now()
+
+	writeFile := func(name string, data []byte) error {
+		hdr := &tar.Header{
+			Name:    path.Join(root, name),
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("could not write header for %s: %w", name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("could not write %s: %w", name, err)
+		}
+		return nil
+	}
+
+	for _, r := range results {
+		if r.Data == nil {
+			continue
+		}
+		if err := writeFile(r.Name, r.Data); err != nil {
+			return err
+		}
+	}
+
+	if err := writeFile(summaryName, []byte(summary(results))); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("could not close tar writer: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("could not close gzip writer: %w", err)
+	}
+
+	return nil
+}
+
+// summary returns a line for every result containing its name, size, duration and status.
+func summary(results []Result) string {
+	var sb strings.Builder
+	for _, r := range results {
+		status := "ok"
+		switch {
+		case r.Err != nil:
+			status = "error: " + r.Err.Error()
+		case r.Truncated:
+			status = "truncated"
+		}
+		sb.WriteString(fmt.Sprintf("%s\t%d bytes\t%s\t%s\n", r.Name, len(r.Data), r.Duration.Round(time.Millisecond), status))
+	}
+	return sb.String()
+}
@@ -0,0 +1,64 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	results := []Result{
+		{Name: "a/data.txt", Data: []byte("data")},
+		{Name: "b/failed.txt", Err: errors.New("test failure")},
+		{Name: "c/big.txt", Data: []byte("big" + truncated), Truncated: true},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "bundle", results); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal("could not create gzip reader", err)
+	}
+	tr := tar.NewReader(gz)
+
+	files := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal("could not read tar", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal("could not read file", err)
+		}
+		files[hdr.Name] = string(data)
+	}
+
+	if d := cmp.Diff("data", files["bundle/a/data.txt"]); d != "" {
+		t.Error("data mismatch (-want +got):", d)
+	}
+	if _, ok := files["bundle/b/failed.txt"]; ok {
+		t.Error("expected failed item to not be written")
+	}
+	if d := cmp.Diff("big"+truncated, files["bundle/c/big.txt"]); d != "" {
+		t.Error("data mismatch (-want +got):", d)
+	}
+
+	summary := files["bundle/summary.txt"]
+	for _, exp := range []string{"a/data.txt\t4 bytes", "b/failed.txt\t0 bytes\t0s\terror: test failure", "truncated"} {
+		if !strings.Contains(summary, exp) {
+			t.Errorf("expected summary to contain %q, received:\n%s", exp, summary)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/redact"
+	"sync"
+	"time"
+)
+
+// ErrSizeLimit is returned for any item that was skipped because the total size limit of the bundle was reached.
+var ErrSizeLimit = errors.New("bundle size limit reached")
+
+// truncated is appended to the data of any item that was truncated due to a size limit.
+const truncated = "\n... truncated by abctl ..."
+
+// Item is a single piece of diagnostic data to collect.
+type Item struct {
+	// Name of the item, used as the file path within the bundle.
+	Name string
+	// Collect returns the data for this item.
+	// The provided context will be cancelled once the per-item timeout has been reached.
+	Collect func(ctx context.Context) ([]byte, error)
+}
+
+// Result is the outcome of collecting a single Item.
+type Result struct {
+	// Name of the Item
+	Name string
+	// Data that was collected, may be partial if Truncated is true.
+	Data []byte
+	// Err is set if the item could not be collected.
+	Err error
+	// Truncated is true if the Data was truncated due to a size limit.
+	Truncated bool
+	// Duration is how long the collection took.
+	Duration time.Duration
+}
+
+// Collector collects Items concurrently using a bounded worker pool.
+type Collector struct {
+	// Workers is the maximum number of items collected concurrently.
+	Workers int
+	// ItemTimeout is the maximum amount of time any single item is given to be collected.
+	ItemTimeout time.Duration
+	// MaxItemBytes is the maximum size of any single item, larger items are truncated.
+	MaxItemBytes int
+	// MaxTotalBytes is the maximum size of all items combined.
+	// Once reached, any remaining items are skipped and return ErrSizeLimit.
+	MaxTotalBytes int
+}
+
+// DefaultCollector is the Collector used when generating a support bundle.
+var DefaultCollector = Collector{
+	Workers:       8,
+	ItemTimeout:   15 * time.Second,
+	MaxItemBytes:  5 * 1024 * 1024,
+	MaxTotalBytes: 100 * 1024 * 1024,
+}
+
+// Collect collects all the items, returning a Result for each item in the same order as the provided items.
+// Any known secrets are redacted from the collected data.
+// A failure to collect an individual item does not stop the collection of the other items.
+func (c Collector) Collect(ctx context.Context, items []Item) []Result {
+	results := make([]Result, len(items))
+
+	workers := c.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		lock      sync.Mutex
+		remaining = c.MaxTotalBytes
+		wg        sync.WaitGroup
+		queue     = make(chan int)
+	)
+
+	// reserve returns how many bytes of data can be included in the bundle, accounting for the total size limit.
+	reserve := func(size int) int {
+		if c.MaxTotalBytes <= 0 {
+			return size
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		if size > remaining {
+			size = remaining
+		}
+		remaining -= size
+		return size
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range queue {
+				results[idx] = c.collect(ctx, items[idx], reserve)
+			}
+		}()
+	}
+
+	for idx := range items {
+		queue <- idx
+	}
+	close(queue)
+	wg.Wait()
+
+	return results
+}
+
+// collect collects a single item, applying the per-item timeout and size limits.
+func (c Collector) collect(ctx context.Context, item Item, reserve func(int) int) Result {
+	res := Result{Name: item.Name}
+	start := time.Now()
+	defer func() { res.Duration = time.Since(start) }()
+
+	if err := ctx.Err(); err != nil {
+		res.Err = err
+		return res
+	}
+
+	if c.ItemTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.ItemTimeout)
+		defer cancel()
+	}
+
+	data, err := collectWithContext(ctx, item)
+	if err != nil {
+		res.Err = fmt.Errorf("could not collect %s: %w", item.Name, err)
+		// still include any partial data that was returned
+	}
+
+	if data != nil {
+		data = redact.Bytes(data)
+	}
+
+	if c.MaxItemBytes > 0 && len(data) > c.MaxItemBytes {
+		data = data[:c.MaxItemBytes]
+		res.Truncated = true
+	}
+
+	allowed := reserve(len(data))
+	if allowed == 0 && len(data) > 0 {
+		res.Err = ErrSizeLimit
+		return res
+	}
+	if allowed < len(data) {
+		data = data[:allowed]
+		res.Truncated = true
+	}
+
+	if res.Truncated {
+		data = append(data, truncated...)
+	}
+	res.Data = data
+
+	return res
+}
+
+// collectWithContext calls the item's Collect function, returning early if the ctx is done.
+// This ensures an item that does not respect its context cannot block the collection beyond its timeout.
+func collectWithContext(ctx context.Context, item Item) ([]byte, error) {
+	type result struct {
+		data []byte
+		err  error
+	}
+
+	ch := make(chan result, 1)
+	go func() {
+		data, err := item.Collect(ctx)
+		ch <- result{data: data, err: err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.data, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,163 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func staticItem(name, data string) Item {
+	return Item{
+		Name: name,
+		Collect: func(context.Context) ([]byte, error) {
+			return []byte(data), nil
+		},
+	}
+}
+
+func TestCollector_Collect(t *testing.T) {
+	errTest := errors.New("test")
+
+	items := []Item{
+		staticItem("a", "aaa"),
+		{
+			Name: "b",
+			Collect: func(context.Context) ([]byte, error) {
+				return nil, errTest
+			},
+		},
+		staticItem("c", "ccc"),
+	}
+
+	results := Collector{Workers: 2}.Collect(context.Background(), items)
+
+	if d := cmp.Diff(3, len(results)); d != "" {
+		t.Fatal("results count mismatch (-want +got):", d)
+	}
+
+	for i, name := range []string{"a", "b", "c"} {
+		if d := cmp.Diff(name, results[i].Name); d != "" {
+			t.Error("name mismatch (-want +got):", d)
+		}
+	}
+	if d := cmp.Diff("aaa", string(results[0].Data)); d != "" {
+		t.Error("data mismatch (-want +got):", d)
+	}
+	if !errors.Is(results[1].Err, errTest) {
+		t.Error("expected errTest, received", results[1].Err)
+	}
+	if d := cmp.Diff("ccc", string(results[2].Data)); d != "" {
+		t.Error("data mismatch (-want +got):", d)
+	}
+}
+
+func TestCollector_Collect_Workers(t *testing.T) {
+	var active, max int32
+
+	items := make([]Item, 20)
+	for i := range items {
+		items[i] = Item{
+			Name: fmt.Sprintf("item-%d", i),
+			Collect: func(context.Context) ([]byte, error) {
+				cur := atomic.AddInt32(&active, 1)
+				defer atomic.AddInt32(&active, -1)
+				for {
+					prev := atomic.LoadInt32(&max)
+					if cur <= prev || atomic.CompareAndSwapInt32(&max, prev, cur) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				return nil, nil
+			},
+		}
+	}
+
+	Collector{Workers: 3}.Collect(context.Background(), items)
+
+	if max > 3 {
+		t.Errorf("expected at most 3 concurrent items, received %d", max)
+	}
+}
+
+func TestCollector_Collect_ItemTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	items := []Item{
+		{
+			Name: "ignores-ctx",
+			Collect: func(context.Context) ([]byte, error) {
+				<-block
+				return nil, nil
+			},
+		},
+		staticItem("fast", "data"),
+	}
+
+	start := time.Now()
+	results := Collector{Workers: 1, ItemTimeout: 10 * time.Millisecond}.Collect(context.Background(), items)
+	if time.Since(start) > time.Second {
+		t.Error("expected collection to stop waiting on the blocked item")
+	}
+
+	if !errors.Is(results[0].Err, context.DeadlineExceeded) {
+		t.Error("expected context.DeadlineExceeded, received", results[0].Err)
+	}
+	if d := cmp.Diff("data", string(results[1].Data)); d != "" {
+		t.Error("data mismatch (-want +got):", d)
+	}
+}
+
+func TestCollector_Collect_SizeLimits(t *testing.T) {
+	items := []Item{
+		staticItem("a", strings.Repeat("a", 10)),
+		staticItem("b", strings.Repeat("b", 4)),
+		staticItem("c", strings.Repeat("c", 4)),
+	}
+
+	results := Collector{Workers: 1, MaxItemBytes: 6, MaxTotalBytes: 8}.Collect(context.Background(), items)
+
+	if d := cmp.Diff("aaaaaa"+truncated, string(results[0].Data)); d != "" {
+		t.Error("data mismatch (-want +got):", d)
+	}
+	if !results[0].Truncated {
+		t.Error("expected item a to be truncated")
+	}
+
+	if d := cmp.Diff("bb"+truncated, string(results[1].Data)); d != "" {
+		t.Error("data mismatch (-want +got):", d)
+	}
+	if !results[1].Truncated {
+		t.Error("expected item b to be truncated")
+	}
+
+	if !errors.Is(results[2].Err, ErrSizeLimit) {
+		t.Error("expected ErrSizeLimit, received", results[2].Err)
+	}
+	if results[2].Data != nil {
+		t.Error("expected no data for item c")
+	}
+}
+
+func TestCollector_Collect_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := Collector{}.Collect(ctx, []Item{staticItem("a", "aaa")})
+	if !errors.Is(results[0].Err, context.Canceled) {
+		t.Error("expected context.Canceled, received", results[0].Err)
+	}
+}
+
+func TestCollector_Collect_Redacted(t *testing.T) {
+	results := Collector{}.Collect(context.Background(), []Item{staticItem("a", "DATABASE_PASSWORD=hunter2")})
+	if d := cmp.Diff("DATABASE_PASSWORD=REDACTED", string(results[0].Data)); d != "" {
+		t.Error("data mismatch (-want +got):", d)
+	}
+}
@@ -0,0 +1,163 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// K8sClient is the subset of the k8s.Client methods used for collecting kubernetes diagnostics.
+type K8sClient interface {
+	EventsList(ctx context.Context, namespace string) (*eventsv1.EventList, error)
+	LogsGet(ctx context.Context, namespace string, name string) (string, error)
+	PodList(ctx context.Context, namespace string) (*corev1.PodList, error)
+}
+
+var _ K8sClient = (k8s.Client)(nil)
+
+// K8sItems returns the Items for collecting the diagnostics of the given namespace.
+// This includes the events of the namespace and, for every pod, its logs and a description of its state.
+func K8sItems(ctx context.Context, client K8sClient, namespace string) ([]Item, error) {
+	pods, err := client.PodList(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("could not list pods in namespace %s: %w", namespace, err)
+	}
+
+	items := []Item{{
+		Name: path.Join(namespace, "events.txt"),
+		Collect: func(ctx context.Context) ([]byte, error) {
+			events, err := client.EventsList(ctx, namespace)
+			if err != nil {
+				return nil, err
+			}
+			return []byte(formatEvents(events.Items)), nil
+		},
+	}}
+
+	for _, pod := range pods.Items {
+		pod := pod
+		items = append(items,
+			Item{
+				Name: path.Join(namespace, "pods", pod.Name, "describe.yaml"),
+				Collect: func(context.Context) ([]byte, error) {
+					return describePod(pod)
+				},
+			},
+			Item{
+				Name: path.Join(namespace, "pods", pod.Name, "logs.txt"),
+				Collect: func(ctx context.Context) ([]byte, error) {
+					logs, err := client.LogsGet(ctx, namespace, pod.Name)
+					if err != nil {
+						return nil, err
+					}
+					return []byte(logs), nil
+				},
+			},
+		)
+	}
+
+	return items, nil
+}
+
+// formatEvents returns the events as text, one line per event, sorted by when the event occurred.
+func formatEvents(events []eventsv1.Event) string {
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+
+	var sb strings.Builder
+	for _, e := range events {
+		sb.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s/%s\t%s\n",
+			eventTime(e).UTC().Format(time.RFC3339), e.Type, e.Reason, e.Regarding.Kind, e.Regarding.Name, e.Note))
+	}
+
+	return sb.String()
+}
+
+// eventTime returns the most relevant time for when the event occurred.
+func eventTime(e eventsv1.Event) time.Time {
+	if !e.DeprecatedLastTimestamp.IsZero() {
+		return e.DeprecatedLastTimestamp.Time
+	}
+	return e.EventTime.Time
+}
+
+// podDescription is a condensed view of a pod, similar to what `kubectl describe pod` returns.
+type podDescription struct {
+	Name       string                 `yaml:"name"`
+	Namespace  string                 `yaml:"namespace"`
+	Node       string                 `yaml:"node,omitempty"`
+	Phase      corev1.PodPhase        `yaml:"phase"`
+	Reason     string                 `yaml:"reason,omitempty"`
+	Message    string                 `yaml:"message,omitempty"`
+	StartTime  string                 `yaml:"startTime,omitempty"`
+	Conditions []podCondition         `yaml:"conditions,omitempty"`
+	Containers []containerDescription `yaml:"containers,omitempty"`
+}
+
+type podCondition struct {
+	Type    corev1.PodConditionType `yaml:"type"`
+	Status  corev1.ConditionStatus  `yaml:"status"`
+	Reason  string                  `yaml:"reason,omitempty"`
+	Message string                  `yaml:"message,omitempty"`
+}
+
+type containerDescription struct {
+	Name         string `yaml:"name"`
+	Image        string `yaml:"image"`
+	Ready        bool   `yaml:"ready"`
+	RestartCount int32  `yaml:"restartCount"`
+	State        string `yaml:"state"`
+	LastState    string `yaml:"lastState,omitempty"`
+}
+
+// describePod returns the yaml description of the pod.
+func describePod(pod corev1.Pod) ([]byte, error) {
+	desc := podDescription{
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+		Node:      pod.Spec.NodeName,
+		Phase:     pod.Status.Phase,
+		Reason:    pod.Status.Reason,
+		Message:   pod.Status.Message,
+	}
+	if pod.Status.StartTime != nil {
+		desc.StartTime = pod.Status.StartTime.UTC().Format(time.RFC3339)
+	}
+	for _, c := range pod.Status.Conditions {
+		desc.Conditions = append(desc.Conditions, podCondition{Type: c.Type, Status: c.Status, Reason: c.Reason, Message: c.Message})
+	}
+	for _, c := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		desc.Containers = append(desc.Containers, containerDescription{
+			Name:         c.Name,
+			Image:        c.Image,
+			Ready:        c.Ready,
+			RestartCount: c.RestartCount,
+			State:        containerState(c.State),
+			LastState:    containerState(c.LastTerminationState),
+		})
+	}
+
+	return yaml.Marshal(desc)
+}
+
+// containerState returns a human-readable description of the container state.
+func containerState(s corev1.ContainerState) string {
+	switch {
+	case s.Running != nil:
+		return "running"
+	case s.Waiting != nil:
+		return strings.TrimSpace(fmt.Sprintf("waiting: %s %s", s.Waiting.Reason, s.Waiting.Message))
+	case s.Terminated != nil:
+		return strings.TrimSpace(fmt.Sprintf("terminated (exit code %d): %s %s", s.Terminated.ExitCode, s.Terminated.Reason, s.Terminated.Message))
+	default:
+		return ""
+	}
+}
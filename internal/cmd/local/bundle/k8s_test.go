@@ -0,0 +1,115 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockK8sClient struct {
+	events *eventsv1.EventList
+	logs   map[string]string
+	pods   *corev1.PodList
+}
+
+func (m mockK8sClient) EventsList(context.Context, string) (*eventsv1.EventList, error) {
+	return m.events, nil
+}
+
+func (m mockK8sClient) LogsGet(_ context.Context, _ string, name string) (string, error) {
+	logs, ok := m.logs[name]
+	if !ok {
+		return "", errors.New("no logs")
+	}
+	return logs, nil
+}
+
+func (m mockK8sClient) PodList(context.Context, string) (*corev1.PodList, error) {
+	return m.pods, nil
+}
+
+func TestK8sItems(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	client := mockK8sClient{
+		events: &eventsv1.EventList{Items: []eventsv1.Event{
+			{
+				DeprecatedLastTimestamp: metav1.NewTime(now.Add(time.Minute)),
+				Type:                    "Warning",
+				Reason:                  "BackOff",
+				Regarding:               corev1.ObjectReference{Kind: "Pod", Name: "server"},
+				Note:                    "Back-off restarting failed container",
+			},
+			{
+				EventTime: metav1.NewMicroTime(now),
+				Type:      "Normal",
+				Reason:    "Scheduled",
+				Regarding: corev1.ObjectReference{Kind: "Pod", Name: "server"},
+				Note:      "Successfully assigned",
+			},
+		}},
+		logs: map[string]string{"server": "server logs"},
+		pods: &corev1.PodList{Items: []corev1.Pod{{
+			ObjectMeta: metav1.ObjectMeta{Name: "server", Namespace: "airbyte-abctl"},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:         "server",
+					Image:        "airbyte/server",
+					RestartCount: 2,
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason: "CrashLoopBackOff",
+					}},
+				}},
+			},
+		}}},
+	}
+
+	items, err := K8sItems(context.Background(), client, "airbyte-abctl")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	var names []string
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	expNames := []string{
+		"airbyte-abctl/events.txt",
+		"airbyte-abctl/pods/server/describe.yaml",
+		"airbyte-abctl/pods/server/logs.txt",
+	}
+	if d := cmp.Diff(expNames, names); d != "" {
+		t.Fatal("names mismatch (-want +got):", d)
+	}
+
+	results := Collector{Workers: 2}.Collect(context.Background(), items)
+	for _, r := range results {
+		if r.Err != nil {
+			t.Error("unexpected error", r.Err)
+		}
+	}
+
+	expEvents := "2024-01-01T00:00:00Z\tNormal\tScheduled\tPod/server\tSuccessfully assigned\n" +
+		"2024-01-01T00:01:00Z\tWarning\tBackOff\tPod/server\tBack-off restarting failed container\n"
+	if d := cmp.Diff(expEvents, string(results[0].Data)); d != "" {
+		t.Error("events mismatch (-want +got):", d)
+	}
+
+	describe := string(results[1].Data)
+	for _, exp := range []string{"phase: Running", "restartCount: 2", "state: 'waiting: CrashLoopBackOff'"} {
+		if !strings.Contains(describe, exp) {
+			t.Errorf("expected describe to contain %q, received:\n%s", exp, describe)
+		}
+	}
+
+	if d := cmp.Diff("server logs", string(results[2].Data)); d != "" {
+		t.Error("logs mismatch (-want +got):", d)
+	}
+}
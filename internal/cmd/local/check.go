@@ -4,10 +4,15 @@ import (
 	"context"
 	"fmt"
 	"github.com/airbytehq/abctl/internal/cmd/local/docker"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
 	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/cmd/local/readiness"
+	"github.com/airbytehq/abctl/internal/logging"
 	"github.com/pterm/pterm"
 	"net"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -17,13 +22,45 @@ import (
 // If this value is nil, the default docker-client (as returned from defaultDocker) will be utilized.
 var dockerClient *docker.Docker
 
+// dockerHost is an explicit docker host to connect to, as defined by the --docker-host flag.
+var dockerHost string
+
+// newDockerClient returns a docker client connected to the dockerHost, or to the first detected docker host
+// if no dockerHost was defined.
+//
+// As kind communicates with docker via the docker cli, the DOCKER_HOST environment variable is set to the
+// connected host (if not already defined) to ensure kind communicates with the same docker host.
+func newDockerClient(ctx context.Context) (*docker.Docker, error) {
+	cli, err := docker.New(ctx, docker.WithHost(dockerHost))
+	if err != nil {
+		return nil, err
+	}
+
+	if dockerHost != "" || os.Getenv("DOCKER_HOST") == "" {
+		if err := os.Setenv("DOCKER_HOST", cli.Host); err != nil {
+			logging.Debugf("Unable to set DOCKER_HOST: %s", err)
+		}
+	}
+
+	return cli, nil
+}
+
+// remoteDockerHost returns the hostname of the remote ssh docker host of the dockerClient, or an empty string if
+// the dockerClient is not connected to a remote host (or was never created).
+func remoteDockerHost() string {
+	if dockerClient == nil {
+		return ""
+	}
+	return dockerClient.RemoteHost()
+}
+
 // dockerInstalled checks if docker is installed on the host machine.
 // Returns a nil error if docker was successfully detected, otherwise an error will be returned.  Any error returned
 // is guaranteed to include the ErrDocker error in the error chain.
 func dockerInstalled(ctx context.Context) (docker.Version, error) {
 	var err error
 	if dockerClient == nil {
-		if dockerClient, err = docker.New(ctx); err != nil {
+		if dockerClient, err = newDockerClient(ctx); err != nil {
 			pterm.Error.Println("Could not create Docker client")
 			return docker.Version{}, fmt.Errorf("%w: could not create client: %w", localerr.ErrDocker, err)
 		}
@@ -52,7 +89,8 @@ var httpClient doer = &http.Client{Timeout: 3 * time.Second}
 // This function works by attempting to establish a tcp listener on a port.
 // If we can establish a tcp listener on the port, an additional check is made to see if Airbyte may already be
 // bound to that port. If something behinds Airbyte is using it, then treat this as a inaccessible port.
-func portAvailable(ctx context.Context, port int) error {
+// The port is checked on the bindAddress it will be bound to, or on localhost if it is empty.
+func portAvailable(ctx context.Context, bindAddress string, port int) error {
 	if port < 1024 {
 		pterm.Warning.Printfln(
 			"Availability of port %d cannot be determined, as this is a privileged port (less than 1024).\n"+
@@ -61,10 +99,16 @@ func portAvailable(ctx context.Context, port int) error {
 		return nil
 	}
 
-	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+	// the port is checked on the address it is bound to, or on localhost if it is bound to all addresses
+	host := bindAddress
+	if host == "" {
+		host = "localhost"
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
 	if err != nil {
 		// check if an existing airbyte installation is already listening on this port
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://localhost:%d", port), nil)
+		url := fmt.Sprintf("http://%s", net.JoinHostPort(k8s.DialAddress(bindAddress), strconv.Itoa(port)))
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 		if err != nil {
 			pterm.Error.Printfln("Port %d request could not be created", port)
 			return fmt.Errorf("%w: could not create request: %w", localerr.ErrPort, err)
@@ -89,3 +133,76 @@ func portAvailable(ctx context.Context, port int) error {
 	pterm.Success.Printfln("Port %d appears to be available", port)
 	return nil
 }
+
+// remotePortAvailable returns a nil error if the port on the remote host is available, or already in use by Airbyte,
+// otherwise returns an error.
+//
+// As a listener cannot be established on a remote host, the port is considered available if no connection can be
+// established to it. If a connection can be established, an additional check is made to see if Airbyte may already
+// be bound to that port.
+func remotePortAvailable(ctx context.Context, host string, port int) error {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	conn, err := (&net.Dialer{Timeout: 3 * time.Second}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		logging.Debugf("could not connect to %s: %s", addr, err)
+		pterm.Success.Printfln("Port %d on %s appears to be available", port, host)
+		return nil
+	}
+	_ = conn.Close()
+
+	// check if an existing airbyte installation is already listening on this port
+	if err := readiness.Ingress(httpClient, fmt.Sprintf("http://%s", addr)).Ready(ctx); err == nil {
+		pterm.Success.Printfln("Port %d on %s appears to be running a previous Airbyte installation", port, host)
+		return nil
+	}
+
+	pterm.Error.Printfln("Port %d on %s appears to already be in use", port, host)
+	return fmt.Errorf("%w: port %d on %s is already in use", localerr.ErrPort, port, host)
+}
+
+// apiPortAvailable returns a nil error if the kubernetes api server port is available, otherwise returns an error.
+// Unlike the http port, a previous Airbyte installation is never expected to be bound to the api server port, as
+// the port of an existing cluster is never checked.
+func apiPortAvailable(port int) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+	if err != nil {
+		pterm.Error.Printfln("Kubernetes API server port %d appears to already be in use", port)
+		return fmt.Errorf("%w: api server port %d is already in use: %w", localerr.ErrPort, port, err)
+	}
+	_ = listener.Close()
+
+	pterm.Success.Printfln("Kubernetes API server port %d appears to be available", port)
+	return nil
+}
+
+// ipv6Only returns true if none of the addrs are non-loopback ipv4 addresses, but at least one is a non-loopback
+// ipv6 address. Link-local addresses are ignored, as they are always present.
+func ipv6Only(addrs []net.Addr) bool {
+	var v4, v6 bool
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			v4 = true
+		} else {
+			v6 = true
+		}
+	}
+	return v6 && !v4
+}
+
+// detectIPFamily returns the ip family the cluster should use on this host, which is ipv6 for an ipv6-only host,
+// otherwise an empty string (indicating the kind default).
+func detectIPFamily() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		logging.Debugf("Unable to determine the interface addresses: %s", err)
+		return ""
+	}
+	if ipv6Only(addrs) {
+		return k8s.IPFamilyIPv6
+	}
+	return ""
+}
@@ -3,18 +3,22 @@ package local
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/airbytehq/abctl/internal/cmd/local/docker"
 	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
 	"github.com/airbytehq/abctl/internal/telemetry"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/system"
 	"github.com/docker/docker/api/types/volume"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/uuid"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"io"
 	"net"
+	"net/http"
 	"strconv"
 	"strings"
 	"testing"
@@ -83,7 +87,7 @@ func TestPortAvailable_Available(t *testing.T) {
 		t.Fatal("could not close listener", err)
 	}
 
-	err = portAvailable(context.Background(), p)
+	err = portAvailable(context.Background(), "", p)
 	if err != nil {
 		t.Error("portAvailable returned unexpected error", err)
 	}
@@ -98,7 +102,7 @@ func TestPortAvailable_Unavailable(t *testing.T) {
 	defer listener.Close()
 	p := port(listener.Addr().String())
 
-	err = portAvailable(context.Background(), p)
+	err = portAvailable(context.Background(), "", p)
 	// expecting an error
 	if err == nil {
 		t.Error("portAvailable should have returned an error")
@@ -108,6 +112,71 @@ func TestPortAvailable_Unavailable(t *testing.T) {
 	}
 }
 
+func TestRemotePortAvailable(t *testing.T) {
+	origHTTPClient := httpClient
+	t.Cleanup(func() {
+		httpClient = origHTTPClient
+	})
+
+	// nothing listening on the port
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("could not create listener", err)
+	}
+	p := port(listener.Addr().String())
+	if err := listener.Close(); err != nil {
+		t.Fatal("could not close listener", err)
+	}
+	if err := remotePortAvailable(context.Background(), "127.0.0.1", p); err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	// something listening on the port
+	listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("could not create listener", err)
+	}
+	defer listener.Close()
+	p = port(listener.Addr().String())
+
+	tests := []struct {
+		name   string
+		status int
+		header http.Header
+		expErr bool
+	}{
+		{name: "other service", status: http.StatusNotFound, expErr: true},
+		{name: "previous airbyte installation", status: http.StatusUnauthorized, header: http.Header{"Www-Authenticate": []string{`Basic realm="abctl"`}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient = mockDoer{do: func(req *http.Request) (*http.Response, error) {
+				if d := cmp.Diff(fmt.Sprintf("http://127.0.0.1:%d", p), req.URL.String()); d != "" {
+					t.Error("url mismatch (-want +got):", d)
+				}
+				return &http.Response{StatusCode: tt.status, Header: tt.header, Body: http.NoBody}, nil
+			}}
+
+			err := remotePortAvailable(context.Background(), "127.0.0.1", p)
+			if tt.expErr && !errors.Is(err, localerr.ErrPort) {
+				t.Error("expected ErrPort, received", err)
+			}
+			if !tt.expErr && err != nil {
+				t.Error("unexpected error", err)
+			}
+		})
+	}
+}
+
+type mockDoer struct {
+	do func(req *http.Request) (*http.Response, error)
+}
+
+func (m mockDoer) Do(req *http.Request) (*http.Response, error) {
+	return m.do(req)
+}
+
 // port returns the port from a string value in the format of "ipv4:port" or "ip::v6:port"
 func port(s string) int {
 	vals := strings.Split(s, ":")
@@ -131,6 +200,11 @@ type mockDockerClient struct {
 	containerExecInspect func(ctx context.Context, execID string) (types.ContainerExecInspect, error)
 	containerExecStart   func(ctx context.Context, execID string, config types.ExecStartCheck) error
 
+	imageInspectWithRaw func(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
+	imagePull           func(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error)
+	imageSave           func(ctx context.Context, imageIDs []string) (io.ReadCloser, error)
+
+	info          func(ctx context.Context) (system.Info, error)
 	serverVersion func(ctx context.Context) (types.Version, error)
 	volumeInspect func(ctx context.Context, volumeID string) (volume.Volume, error)
 }
@@ -171,6 +245,22 @@ func (m mockDockerClient) ContainerExecStart(ctx context.Context, execID string,
 	return m.containerExecStart(ctx, execID, config)
 }
 
+func (m mockDockerClient) ImagePull(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error) {
+	return m.imagePull(ctx, refStr, options)
+}
+
+func (m mockDockerClient) ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+	return m.imageInspectWithRaw(ctx, imageID)
+}
+
+func (m mockDockerClient) ImageSave(ctx context.Context, imageIDs []string) (io.ReadCloser, error) {
+	return m.imageSave(ctx, imageIDs)
+}
+
+func (m mockDockerClient) Info(ctx context.Context) (system.Info, error) {
+	return m.info(ctx)
+}
+
 func (m mockDockerClient) ServerVersion(ctx context.Context) (types.Version, error) {
 	return m.serverVersion(ctx)
 }
@@ -208,3 +298,54 @@ func (m *mockTelemetryClient) Attr(key, val string) {
 func (m *mockTelemetryClient) User() uuid.UUID {
 	return m.user()
 }
+
+func TestAPIPortAvailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal("could not create listener", err)
+	}
+	p := port(listener.Addr().String())
+
+	err = apiPortAvailable(p)
+	if !errors.Is(err, localerr.ErrPort) {
+		t.Error("expected ErrPort for a port in use, received", err)
+	}
+
+	if err := listener.Close(); err != nil {
+		t.Fatal("could not close listener", err)
+	}
+	if err := apiPortAvailable(p); err != nil {
+		t.Error("unexpected error", err)
+	}
+}
+
+func TestIPv6Only(t *testing.T) {
+	ipNet := func(s string) net.Addr {
+		ip, n, err := net.ParseCIDR(s)
+		if err != nil {
+			t.Fatal("could not parse cidr", err)
+		}
+		n.IP = ip
+		return n
+	}
+
+	tests := []struct {
+		name  string
+		addrs []net.Addr
+		exp   bool
+	}{
+		{name: "none"},
+		{name: "ipv4", addrs: []net.Addr{ipNet("127.0.0.1/8"), ipNet("192.168.1.10/24")}},
+		{name: "dual", addrs: []net.Addr{ipNet("192.168.1.10/24"), ipNet("2001:db8::10/64")}},
+		{name: "ipv6", addrs: []net.Addr{ipNet("127.0.0.1/8"), ipNet("::1/128"), ipNet("2001:db8::10/64")}, exp: true},
+		{name: "link-local only", addrs: []net.Addr{ipNet("::1/128"), ipNet("fe80::1/64")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.exp, ipv6Only(tt.addrs)); d != "" {
+				t.Error("ipv6Only mismatch (-want +got):", d)
+			}
+		})
+	}
+}
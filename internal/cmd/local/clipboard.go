@@ -0,0 +1,45 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommands returns the commands copying their stdin to the clipboard of the operating system, in order of
+// preference.
+func clipboardCommands(goos string) [][]string {
+	switch goos {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip.exe"}}
+	default:
+		return [][]string{{"wl-copy"}, {"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+	}
+}
+
+// copyToClipboard copies the text to the clipboard by the first clipboard command which is installed.
+// It can be overwritten for testing purposes.
+var copyToClipboard = func(ctx context.Context, text string) error {
+	commands := clipboardCommands(runtime.GOOS)
+	for _, command := range commands {
+		if _, err := exec.LookPath(command[0]); err != nil {
+			continue
+		}
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("could not copy to the clipboard with %s: %w: %s", command[0], err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+
+	names := make([]string, len(commands))
+	for i, command := range commands {
+		names[i] = command[0]
+	}
+	return fmt.Errorf("no clipboard command found, install any of %s", strings.Join(names, ", "))
+}
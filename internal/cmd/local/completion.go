@@ -0,0 +1,103 @@
+package local
+
+import (
+	"context"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/tools/clientcmd"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// completionTimeout limits how long any dynamic completion may take, as the shell is blocked until it completes.
+const completionTimeout = 5 * time.Second
+
+// registerCompletions registers the dynamic completion of the --namespace, --chart-version, and --context flags of
+// the command and all of its subcommands.
+// Completions must never print anything, as everything printed is a completion.
+func registerCompletions(cmd *cobra.Command, provider k8s.Provider) {
+	completions := map[string]func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective){
+		"namespace":     completeNamespaces(provider),
+		"chart-version": completeChartVersions,
+		"context":       completeContexts,
+	}
+
+	for _, flags := range []*pflag.FlagSet{cmd.Flags(), cmd.PersistentFlags()} {
+		flags.VisitAll(func(f *pflag.Flag) {
+			if complete, ok := completions[f.Name]; ok {
+				_ = cmd.RegisterFlagCompletionFunc(f.Name, complete)
+			}
+		})
+	}
+
+	for _, sub := range cmd.Commands() {
+		registerCompletions(sub, provider)
+	}
+}
+
+// completeNamespaces completes the namespaces of the cluster, which is the existing cluster if either the
+// --kubeconfig or --context flag is provided.
+func completeNamespaces(provider k8s.Provider) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		p, err := flagsProvider(cmd, provider)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+		defer cancel()
+		namespaces, err := local.Namespaces(ctx, p)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return filterPrefix(namespaces, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeChartVersions completes the versions of the Airbyte helm chart, newest first.
+func completeChartVersions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	versions, err := local.ChartVersions(ctx, &http.Client{}, filepath.Join(paths.AbCtl, "charts"))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return filterPrefix(versions, toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeContexts completes the contexts of the kubeconfig, which is ~/.kube/config unless --kubeconfig is provided.
+func completeContexts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+	if kubeconfig == "" {
+		kubeconfig = clientcmd.RecommendedHomeFile
+	}
+
+	cfg, err := clientcmd.LoadFromFile(kubeconfig)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var contexts []string
+	for name := range cfg.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+	return filterPrefix(contexts, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// filterPrefix returns the values which start with the prefix.
+func filterPrefix(values []string, prefix string) []string {
+	var filtered []string
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,43 @@
+package local
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompleteContexts(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+contexts:
+- name: kind-airbyte-abctl
+  context: {cluster: kind-airbyte-abctl}
+- name: k3s
+  context: {cluster: k3s}
+- name: kind-other
+  context: {cluster: kind-other}
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("kubeconfig", kubeconfig, "")
+
+	contexts, directive := completeContexts(cmd, nil, "kind-")
+	if d := cmp.Diff([]string{"kind-airbyte-abctl", "kind-other"}, contexts); d != "" {
+		t.Error("contexts mismatch (-want +got):", d)
+	}
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Error("unexpected directive", directive)
+	}
+
+	if err := cmd.Flags().Set("kubeconfig", filepath.Join(t.TempDir(), "dne")); err != nil {
+		t.Fatal(err)
+	}
+	if _, directive := completeContexts(cmd, nil, ""); directive != cobra.ShellCompDirectiveError {
+		t.Error("expected an error directive for a missing kubeconfig, got", directive)
+	}
+}
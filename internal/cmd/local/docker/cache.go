@@ -0,0 +1,91 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/progress"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+	"os"
+	"runtime"
+)
+
+const (
+	// CacheContainer is the name of the container running the image cache.
+	CacheContainer = "airbyte-abctl-cache"
+	// cacheImage is the registry image, which is run as a pull-through cache of docker hub.
+	cacheImage = "registry:2"
+	cachePort  = 5000
+	// kindNetwork is the docker network of the kind nodes, the cache is connected to it so the nodes can access it.
+	kindNetwork = "kind"
+)
+
+// CacheMirror is the url of the image cache, as accessed from the kind nodes.
+var CacheMirror = fmt.Sprintf("http://%s:%d", CacheContainer, cachePort)
+
+// StartCache starts the image cache, storing the cached image layers in dir so they outlive the cluster.
+// The cache is a pull-through cache of docker hub, connected to the kind network, and is created if it does not
+// already exist. As it must be connected to the kind network, the kind cluster must be created first.
+// The progress of pulling the image of the cache is reported to report, if not nil.
+func (d *Docker) StartCache(ctx context.Context, dir string, report progress.Reporter) error {
+	ci, err := d.Client.ContainerInspect(ctx, CacheContainer)
+	switch {
+	case err == nil:
+		if ci.ContainerJSONBase != nil && ci.State != nil && ci.State.Running {
+			return nil
+		}
+		return d.Start(ctx, CacheContainer)
+	case !client.IsErrNotFound(err):
+		return fmt.Errorf("could not inspect container %s: %w", CacheContainer, err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("could not create image cache directory %s: %w", dir, err)
+	}
+
+	if err := d.pull(ctx, cacheImage, report); err != nil {
+		return err
+	}
+
+	cfg := &container.Config{
+		Image: cacheImage,
+		Env:   []string{"REGISTRY_PROXY_REMOTEURL=https://registry-1.docker.io"},
+	}
+	// run as the current user on linux, otherwise the cached layers would be owned by root and could not be pruned
+	if runtime.GOOS == "linux" {
+		cfg.User = fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+	}
+
+	con, err := d.Client.ContainerCreate(
+		ctx,
+		cfg,
+		&container.HostConfig{
+			NetworkMode:   kindNetwork,
+			RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyUnlessStopped},
+			Mounts: []mount.Mount{{
+				Type:   mount.TypeBind,
+				Source: dir,
+				Target: "/var/lib/registry",
+			}},
+		},
+		nil,
+		nil,
+		CacheContainer,
+	)
+	if err != nil {
+		return fmt.Errorf("could not create container %s: %w", CacheContainer, err)
+	}
+
+	return d.Start(ctx, con.ID)
+}
+
+// RemoveCache stops and removes the image cache container, if it exists.
+// The cached image layers are not removed.
+func (d *Docker) RemoveCache(ctx context.Context) error {
+	err := d.Client.ContainerRemove(ctx, CacheContainer, container.RemoveOptions{Force: true})
+	if err != nil && !client.IsErrNotFound(err) {
+		return fmt.Errorf("could not remove container %s: %w", CacheContainer, err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/google/go-cmp/cmp"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStartCache(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "cache")
+
+	var calls []string
+	p := mockPinger{
+		containerInspect: func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+			return types.ContainerJSON{}, errdefs.NotFound(errors.New("no such container"))
+		},
+		imagePull: func(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error) {
+			calls = append(calls, "pull "+refStr)
+			return io.NopCloser(strings.NewReader("progress")), nil
+		},
+		containerCreate: func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *ocispec.Platform, containerName string) (container.CreateResponse, error) {
+			calls = append(calls, "create "+containerName)
+			if d := cmp.Diff(kindNetwork, string(hostConfig.NetworkMode)); d != "" {
+				t.Error("network mismatch (-want +got):", d)
+			}
+			if d := cmp.Diff(dir, hostConfig.Mounts[0].Source); d != "" {
+				t.Error("mount mismatch (-want +got):", d)
+			}
+			return container.CreateResponse{ID: "cache-id"}, nil
+		},
+		containerStart: func(ctx context.Context, container string, options container.StartOptions) error {
+			calls = append(calls, "start "+container)
+			return nil
+		},
+	}
+
+	f := func(opts ...client.Opt) (pinger, error) { return p, nil }
+	cli, err := newWithOptions(ctx, f, "darwin")
+	if err != nil {
+		t.Fatal("failed creating client", err)
+	}
+
+	if err := cli.StartCache(ctx, dir, nil); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := []string{"pull " + cacheImage, "create " + CacheContainer, "start cache-id"}
+	if d := cmp.Diff(exp, calls); d != "" {
+		t.Error("calls mismatch (-want +got):", d)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Error("expected the cache directory to be created", err)
+	}
+}
+
+func TestStartCache_Existing(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		running bool
+		exp     []string
+	}{
+		{name: "running", running: true},
+		{name: "stopped", exp: []string{"start " + CacheContainer}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			p := mockPinger{
+				containerInspect: func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+					state := &types.ContainerState{Running: tt.running}
+					return types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{State: state}}, nil
+				},
+				containerStart: func(ctx context.Context, container string, options container.StartOptions) error {
+					calls = append(calls, "start "+container)
+					return nil
+				},
+			}
+
+			f := func(opts ...client.Opt) (pinger, error) { return p, nil }
+			cli, err := newWithOptions(ctx, f, "darwin")
+			if err != nil {
+				t.Fatal("failed creating client", err)
+			}
+
+			if err := cli.StartCache(ctx, t.TempDir(), nil); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, calls); d != "" {
+				t.Error("calls mismatch (-want +got):", d)
+			}
+		})
+	}
+}
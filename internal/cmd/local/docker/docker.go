@@ -6,20 +6,26 @@ import (
 	"fmt"
 	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
 	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/airbytehq/abctl/internal/shutdown"
+	"github.com/docker/cli/cli/connhelper"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/system"
 	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/go-connections/nat"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
-	"github.com/pterm/pterm"
 	"io"
+	"net/url"
 	"os"
-	"path"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"time"
 )
@@ -47,6 +53,11 @@ type Client interface {
 	ContainerExecInspect(ctx context.Context, execID string) (types.ContainerExecInspect, error)
 	ContainerExecStart(ctx context.Context, execID string, config types.ExecStartCheck) error
 
+	ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
+	ImagePull(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error)
+	ImageSave(ctx context.Context, imageIDs []string) (io.ReadCloser, error)
+
+	Info(ctx context.Context) (system.Info, error)
 	ServerVersion(ctx context.Context) (types.Version, error)
 	VolumeInspect(ctx context.Context, volumeID string) (volume.Volume, error)
 }
@@ -57,10 +68,44 @@ var _ Client = (*client.Client)(nil)
 // Can be created with default settings by calling New or with a custom Client by manually instantiating this type.
 type Docker struct {
 	Client Client
+	// Host is the docker host the Client is connected to, only set if created via New.
+	Host string
+}
+
+// Option for configuring the docker client creation.
+type Option func(*options)
+
+type options struct {
+	// host is an explicit docker host, if defined no other hosts will be attempted
+	host string
+	// getenv returns the value of the environment variable, defined for testing purposes
+	getenv func(string) string
+	// exists returns true if the file exists, defined for testing purposes
+	exists func(string) bool
+}
+
+// WithHost defines an explicit docker host (e.g. unix:///var/run/docker.sock) to connect to.
+// If the host is empty, the default hosts will be attempted.
+func WithHost(host string) Option {
+	return func(o *options) {
+		o.host = host
+	}
 }
 
 // New returns a new Docker type with a default Client implementation.
-func New(ctx context.Context) (*Docker, error) {
+//
+// The docker hosts are attempted in the following order, the first host that responds successfully is used:
+//  1. the host provided via WithHost, if defined no other hosts are attempted
+//  2. the DOCKER_HOST environment variable
+//  3. the platform default (/var/run/docker.sock or the npipe on windows)
+//  4. Docker Desktop (~/.docker/run/docker.sock, ~/.docker/desktop/docker.sock)
+//  5. Colima (~/.colima/default/docker.sock, ~/.colima/docker.sock)
+//  6. Rancher Desktop (~/.rd/docker.sock)
+//  7. Lima (~/.lima/docker/sock/docker.sock)
+//  8. rootless docker ($XDG_RUNTIME_DIR/docker.sock)
+//
+// The well-known sockets of 4-8 are only attempted if the socket file exists.
+func New(ctx context.Context, opts ...Option) (*Docker, error) {
 	// convert the client.NewClientWithOpts to a newPing function
 	f := func(opts ...client.Opt) (pinger, error) {
 		var p pinger
@@ -72,7 +117,7 @@ func New(ctx context.Context) (*Docker, error) {
 		return p, nil
 	}
 
-	return newWithOptions(ctx, f, runtime.GOOS)
+	return newWithOptions(ctx, f, runtime.GOOS, opts...)
 }
 
 // newPing exists for testing purposes.
@@ -89,44 +134,94 @@ type pinger interface {
 var _ pinger = (*client.Client)(nil)
 
 // newWithOptions allows for the docker client to be injected for testing purposes.
-func newWithOptions(ctx context.Context, newPing newPing, goos string) (*Docker, error) {
-	var (
-		dockerCli Client
-		err       error
-	)
+func newWithOptions(ctx context.Context, newPing newPing, goos string, opts ...Option) (*Docker, error) {
+	o := options{
+		getenv: os.Getenv,
+		exists: func(path string) bool {
+			_, err := os.Stat(path)
+			return err == nil
+		},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
 
 	dockerOpts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
 
-	switch goos {
-	case "darwin":
-		// on mac, sometimes the docker host isn't set correctly, if it fails check the home directory
-		dockerCli, err = createAndPing(ctx, newPing, "unix:///var/run/docker.sock", dockerOpts)
+	var errs []error
+	for _, host := range hosts(goos, o) {
+		dockerCli, err := createAndPing(ctx, newPing, host, dockerOpts)
 		if err != nil {
-			var err2 error
-			dockerCli, err2 = createAndPing(ctx, newPing, fmt.Sprintf("unix://%s/.docker/run/docker.sock", paths.UserHome), dockerOpts)
-			if err2 != nil {
-				return nil, fmt.Errorf("%w: could not create docker client: (%w, %w)", localerr.ErrDocker, err, err2)
+			logging.Debugf("Unable to connect to docker host %s: %s", host, err)
+			errs = append(errs, fmt.Errorf("%s: %w", host, err))
+			continue
+		}
+		logging.Debugf("Connected to docker host %s", host)
+		return &Docker{Client: dockerCli, Host: host}, nil
+	}
+
+	return nil, fmt.Errorf("%w: could not create docker client: %w", localerr.ErrDocker, errors.Join(errs...))
+}
+
+// hosts returns the docker hosts to attempt, in order.
+func hosts(goos string, o options) []string {
+	if o.host != "" {
+		return []string{o.host}
+	}
+
+	var hosts []string
+	add := func(host string) {
+		for _, h := range hosts {
+			if h == host {
+				return
 			}
-			// if we made it here, clear out the original error,
-			// as we were able to successfully connect on the second attempt
-			err = nil
 		}
-	case "windows":
-		dockerCli, err = createAndPing(ctx, newPing, "npipe:////./pipe/docker_engine", dockerOpts)
-	default:
-		dockerCli, err = createAndPing(ctx, newPing, "unix:///var/run/docker.sock", dockerOpts)
+		hosts = append(hosts, host)
 	}
 
-	if err != nil {
-		return nil, fmt.Errorf("%w: could not create docker client: %w", localerr.ErrDocker, err)
+	if env := o.getenv("DOCKER_HOST"); env != "" {
+		add(env)
+	}
+
+	if goos == "windows" {
+		add("npipe:////./pipe/docker_engine")
+		return hosts
+	}
+
+	add("unix:///var/run/docker.sock")
+	if goos == "darwin" {
+		// on mac, sometimes the docker host isn't set correctly, always check the home directory
+		add(fmt.Sprintf("unix://%s/.docker/run/docker.sock", paths.UserHome))
 	}
 
-	return &Docker{Client: dockerCli}, nil
+	sockets := []string{
+		filepath.Join(paths.UserHome, ".docker", "run", "docker.sock"),
+		filepath.Join(paths.UserHome, ".docker", "desktop", "docker.sock"),
+		filepath.Join(paths.UserHome, ".colima", "default", "docker.sock"),
+		filepath.Join(paths.UserHome, ".colima", "docker.sock"),
+		filepath.Join(paths.UserHome, ".rd", "docker.sock"),
+		filepath.Join(paths.UserHome, ".lima", "docker", "sock", "docker.sock"),
+	}
+	if runtimeDir := o.getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		sockets = append(sockets, filepath.Join(runtimeDir, "docker.sock"))
+	}
+	for _, socket := range sockets {
+		if o.exists(socket) {
+			add("unix://" + socket)
+		}
+	}
+
+	return hosts
 }
 
 // createAndPing attempts to create a docker client and ping it to ensure we can communicate
 func createAndPing(ctx context.Context, newPing newPing, host string, opts []client.Opt) (Client, error) {
-	cli, err := newPing(append(opts, client.WithHost(host))...)
+	hostOpts, err := hostOptions(host)
+	if err != nil {
+		return nil, fmt.Errorf("could not configure docker host: %w", err)
+	}
+
+	cli, err := newPing(append(opts, hostOpts...)...)
 	if err != nil {
 		return nil, fmt.Errorf("could not create docker client: %w", err)
 	}
@@ -138,6 +233,40 @@ func createAndPing(ctx context.Context, newPing newPing, host string, opts []cli
 	return cli, nil
 }
 
+// hostOptions returns the client options for connecting to the host.
+// An ssh host (ssh://user@host) is connected to by running `docker system dial-stdio` on the remote host over ssh.
+func hostOptions(host string) ([]client.Opt, error) {
+	helper, err := connhelper.GetConnectionHelper(host)
+	if err != nil {
+		return nil, err
+	}
+	if helper == nil {
+		return []client.Opt{client.WithHost(host)}, nil
+	}
+
+	return []client.Opt{
+		client.WithHost(helper.Host),
+		client.WithDialContext(helper.Dialer),
+	}, nil
+}
+
+// RemoteHost returns the hostname of the docker host if it is a remote ssh host (e.g. ssh://user@host),
+// otherwise an empty string is returned.
+func RemoteHost(host string) string {
+	u, err := url.Parse(host)
+	if err != nil || u.Scheme != "ssh" {
+		return ""
+	}
+
+	return u.Hostname()
+}
+
+// RemoteHost returns the hostname of the remote ssh docker host this Docker is connected to, or an empty string
+// if the docker host is not a remote ssh host.
+func (d *Docker) RemoteHost() string {
+	return RemoteHost(d.Host)
+}
+
 // Version returns the version information from the underlying docker process.
 func (d *Docker) Version(ctx context.Context) (Version, error) {
 	ver, err := d.Client.ServerVersion(ctx)
@@ -153,14 +282,48 @@ func (d *Docker) Version(ctx context.Context) (Version, error) {
 
 }
 
+// Resources are the cpus and memory available to the containers of the docker host.
+type Resources struct {
+	CPUs int
+	// Memory is the memory in bytes.
+	Memory int64
+}
+
+// Resources returns the cpus and memory available to the containers of the docker host, which is the VM of Docker
+// Desktop, Colima, etc. rather than this machine, if docker runs within one.
+func (d *Docker) Resources(ctx context.Context) (Resources, error) {
+	info, err := d.Client.Info(ctx)
+	if err != nil {
+		return Resources{}, fmt.Errorf("could not determine docker info: %w", err)
+	}
+
+	return Resources{CPUs: info.NCPU, Memory: info.MemTotal}, nil
+}
+
 // Port returns the host-port the underlying docker process is currently bound to, for the given container.
-// It determines this by walking through all the ports on the container and finding the one that is bound to ip 0.0.0.0.
+// It determines this by finding the host-port bound to the container's http port (80/tcp), which may be bound to a
+// specific listen address. Otherwise, it walks through all the ports on the container and finds the one that is bound
+// to ip 0.0.0.0.
+// If the container is not running, an error containing localerr.ErrClusterStopped is returned, as a stopped
+// container is not bound to any ports.
 func (d *Docker) Port(ctx context.Context, container string) (int, error) {
 	ci, err := d.Client.ContainerInspect(ctx, container)
 	if err != nil {
 		return 0, fmt.Errorf("could not inspect container: %w", err)
 	}
 
+	if ci.ContainerJSONBase != nil && ci.State != nil && !ci.State.Running {
+		return 0, fmt.Errorf("%w: container %s is %s", localerr.ErrClusterStopped, container, ci.State.Status)
+	}
+
+	for _, ipPort := range ci.NetworkSettings.Ports["80/tcp"] {
+		port, err := strconv.Atoi(ipPort.HostPort)
+		if err != nil {
+			return 0, fmt.Errorf("could not convert host port %s to integer: %w", ipPort.HostPort, err)
+		}
+		return port, nil
+	}
+
 	for _, bindings := range ci.NetworkSettings.Ports {
 		for _, ipPort := range bindings {
 			if ipPort.HostIP == "0.0.0.0" {
@@ -176,6 +339,75 @@ func (d *Docker) Port(ctx context.Context, container string) (int, error) {
 	return 0, errors.New("could not determine port for container")
 }
 
+// PortMapped returns true if the container port of the container is mapped to a host port.
+func (d *Docker) PortMapped(ctx context.Context, container string, port int) (bool, error) {
+	ci, err := d.Client.ContainerInspect(ctx, container)
+	if err != nil {
+		return false, fmt.Errorf("could not inspect container: %w", err)
+	}
+	if ci.NetworkSettings == nil {
+		return false, nil
+	}
+
+	return len(ci.NetworkSettings.Ports[nat.Port(fmt.Sprintf("%d/tcp", port))]) > 0, nil
+}
+
+// Gateway returns the gateway ip of the network of the container, which is the docker host as seen from the
+// container. The network named network is preferred, if the container is connected to multiple networks.
+func (d *Docker) Gateway(ctx context.Context, container, network string) (string, error) {
+	ci, err := d.Client.ContainerInspect(ctx, container)
+	if err != nil {
+		return "", fmt.Errorf("could not inspect container: %w", err)
+	}
+	if ci.NetworkSettings == nil || len(ci.NetworkSettings.Networks) == 0 {
+		return "", fmt.Errorf("container %s is not connected to any network", container)
+	}
+
+	gateway := func(name string) string {
+		n := ci.NetworkSettings.Networks[name]
+		if n == nil {
+			return ""
+		}
+		if n.Gateway != "" {
+			return n.Gateway
+		}
+		return n.IPv6Gateway
+	}
+	if gw := gateway(network); gw != "" {
+		return gw, nil
+	}
+	// the networks are a map, so they are sorted to always return the same gateway
+	names := make([]string, 0, len(ci.NetworkSettings.Networks))
+	for name := range ci.NetworkSettings.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if gw := gateway(name); gw != "" {
+			return gw, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not determine the gateway of container %s", container)
+}
+
+// Start starts the stopped container.
+func (d *Docker) Start(ctx context.Context, name string) error {
+	if err := d.Client.ContainerStart(ctx, name, container.StartOptions{}); err != nil {
+		return fmt.Errorf("could not start container %s: %w", name, err)
+	}
+	return nil
+}
+
+// Stop stops the running container, allowing it up to timeout to stop gracefully before it is killed.
+func (d *Docker) Stop(ctx context.Context, name string, timeout time.Duration) error {
+	secs := int(timeout.Seconds())
+	if err := d.Client.ContainerStop(ctx, name, container.StopOptions{Timeout: &secs}); err != nil {
+		return fmt.Errorf("could not stop container %s: %w", name, err)
+	}
+	return nil
+}
+
 const migratePGDATA = "/var/lib/postgresql/data"
 
 // MigrateComposeDB handles migrating the existing docker compose database into the abctl managed k8s cluster.
@@ -206,7 +438,8 @@ func (d *Docker) MigrateComposeDB(ctx context.Context, volume string) error {
 	if err != nil {
 		return fmt.Errorf("could not create initial docker migration container: %w", err)
 	}
-	pterm.Debug.Println(fmt.Sprintf("Created initial migration container '%s'", conCopy.ID))
+	logging.Debugf("Created initial migration container '%s'", conCopy.ID)
+	doneCopy := d.trackContainer(conCopy.ID)
 
 	// docker cp [conCopy.ID]]:/$migratePGDATA/. ~/.airbyte/abctl/data/airbyte-volume-db/pgdata
 	dst := filepath.Join(paths.Data, "airbyte-volume-db", "pgdata")
@@ -214,9 +447,10 @@ func (d *Docker) MigrateComposeDB(ctx context.Context, volume string) error {
 	if err := d.copyFromContainer(ctx, conCopy.ID, migratePGDATA+"/.", dst); err != nil {
 		return fmt.Errorf("could not copy airbyte db data from container %s: %w", conCopy.ID, err)
 	}
-	pterm.Debug.Println(fmt.Sprintf("Copied airbyte db data from container '%s' to '%s'", conCopy.ID, dst))
+	logging.Debugf("Copied airbyte db data from container '%s' to '%s'", conCopy.ID, dst)
 
 	d.stopAndRemoveContainer(ctx, conCopy.ID)
+	doneCopy()
 
 	// Create a container for adding the correct db user and renaming the database.
 	// We have inconsistencies between our docker and helm default database credentials and even our database name.
@@ -248,13 +482,17 @@ func (d *Docker) MigrateComposeDB(ctx context.Context, volume string) error {
 	if err != nil {
 		return fmt.Errorf("could not create docker container: %w", err)
 	}
-	pterm.Debug.Println(fmt.Sprintf("Created secondary migration container '%s'", conTransform.ID))
-	pterm.Debug.Println(fmt.Sprintf("Container was created with the following warnings: %s", conTransform.Warnings))
+	logging.Debugf("Created secondary migration container '%s'", conTransform.ID)
+	logging.Debugf("Container was created with the following warnings: %s", conTransform.Warnings)
 	if err := d.Client.ContainerStart(ctx, conTransform.ID, container.StartOptions{}); err != nil {
 		return fmt.Errorf("could not start container %s: %w", conTransform.ID, err)
 	}
 	// cleanup and remove container when we're done
-	defer func() { d.stopAndRemoveContainer(ctx, conTransform.ID) }()
+	doneTransform := d.trackContainer(conTransform.ID)
+	defer func() {
+		d.stopAndRemoveContainer(ctx, conTransform.ID)
+		doneTransform()
+	}()
 
 	// TODO figure out a better way to determine when the container has successfully started
 	time.Sleep(10 * time.Second)
@@ -267,21 +505,21 @@ func (d *Docker) MigrateComposeDB(ctx context.Context, volume string) error {
 	)
 	// add a new database user to match the default helm user
 	now := time.Now()
-	pterm.Debug.Println("Adding Airbyte postgres user")
+	logging.Debugf("Adding Airbyte postgres user")
 	if err := d.exec(ctx, conTransform.ID, cmdPsqlUser); err != nil {
-		pterm.Debug.Println("Failed to add postgres user")
+		logging.Debugf("Failed to add postgres user")
 		return fmt.Errorf("could not update postgres user: %w", err)
 	}
-	pterm.Debug.Println(fmt.Sprintf("Adding Airbyte postgres user completed in %s", time.Since(now)))
+	logging.Debugf("Adding Airbyte postgres user completed in %s", time.Since(now))
 
 	// rename the database to match the default helm database name
-	pterm.Debug.Println("Renaming database")
+	logging.Debugf("Renaming database")
 	now = time.Now()
 	if err := d.exec(ctx, conTransform.ID, cmdPsqlRename); err != nil {
-		pterm.Debug.Println("Failed to rename database")
+		logging.Debugf("Failed to rename database")
 		return fmt.Errorf("could not rename postgres database: %w", err)
 	}
-	pterm.Debug.Println(fmt.Sprintf("Renaming database completed in %s", time.Since(now)))
+	logging.Debugf("Renaming database completed in %s", time.Since(now))
 
 	return nil
 }
@@ -289,7 +527,7 @@ func (d *Docker) MigrateComposeDB(ctx context.Context, volume string) error {
 // volumeExists returns the MountPoint of the volumeID (if the volume exists), an empty string otherwise.
 func (d *Docker) volumeExists(ctx context.Context, volumeID string) string {
 	if v, err := d.Client.VolumeInspect(ctx, volumeID); err != nil {
-		pterm.Debug.Println(fmt.Sprintf("Volume %s cannot be accessed: %s", volumeID, err))
+		logging.Debugf("Volume %s cannot be accessed: %s", volumeID, err)
 		return ""
 	} else {
 		return v.Mountpoint
@@ -342,7 +580,7 @@ func (d *Docker) exec(ctx context.Context, container string, cmd []string) error
 // The dst will be treated as a directory.
 func (d *Docker) copyFromContainer(ctx context.Context, container, src, dst string) error {
 	// ensure dst directory exists
-	if err := os.MkdirAll(path.Dir(dst), 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
 		return fmt.Errorf("could not create directory '%s': %w", dst, err)
 	}
 
@@ -365,14 +603,27 @@ func (d *Docker) copyFromContainer(ctx context.Context, container, src, dst stri
 	return nil
 }
 
+// trackContainer registers the containerID as an in-flight operation, ensuring it will be removed if abctl is interrupted.
+// Returns a function that must be called once the container has been removed.
+func (d *Docker) trackContainer(containerID string) func() {
+	return shutdown.Track(
+		fmt.Sprintf("Running migration container '%s'", containerID),
+		"The migration container will be removed.",
+		func(ctx context.Context) error {
+			d.stopAndRemoveContainer(ctx, containerID)
+			return nil
+		},
+	)
+}
+
 // stopAndRemoveContainer will stop and ultimately remove the containerID
 func (d *Docker) stopAndRemoveContainer(ctx context.Context, containerID string) {
-	pterm.Debug.Println(fmt.Sprintf("Stopping container '%s'", containerID))
+	logging.Debugf("Stopping container '%s'", containerID)
 	if err := d.Client.ContainerStop(ctx, containerID, container.StopOptions{}); err != nil {
-		pterm.Debug.Println(fmt.Sprintf("Could not stop docker container %s: %s", containerID, err))
+		logging.Debugf("Could not stop docker container %s: %s", containerID, err)
 	}
-	pterm.Debug.Println(fmt.Sprintf("Removing container '%s'", containerID))
+	logging.Debugf("Removing container '%s'", containerID)
 	if err := d.Client.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: true}); err != nil {
-		pterm.Debug.Println(fmt.Sprintf("Could not remove docker container %s: %s", containerID, err))
+		logging.Debugf("Could not remove docker container %s: %s", containerID, err)
 	}
 }
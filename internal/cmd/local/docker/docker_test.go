@@ -3,17 +3,23 @@ package docker
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/system"
 	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
 	"github.com/google/go-cmp/cmp"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"io"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 var expVersion = Version{
@@ -216,6 +222,157 @@ func TestNewWithOptions_DarwinPingErrFirstAttemptOnly(t *testing.T) {
 	}
 }
 
+func TestNewWithOptions_WithHost(t *testing.T) {
+	var attempts int
+
+	f := func(opts ...client.Opt) (pinger, error) {
+		attempts++
+		return nil, errors.New("test error")
+	}
+
+	_, err := newWithOptions(context.Background(), f, "darwin", WithHost("tcp://127.0.0.1:2375"))
+	if !errors.Is(err, localerr.ErrDocker) {
+		t.Error("unexpected error, should be ErrDocker", err)
+	}
+	// an explicit host should never fall back to any other hosts
+	if d := cmp.Diff(1, attempts); d != "" {
+		t.Error("unexpected attempts", d)
+	}
+}
+
+func TestHostOptions(t *testing.T) {
+	tests := []struct {
+		host string
+		exp  string
+	}{
+		{host: "unix:///var/run/docker.sock", exp: "unix:///var/run/docker.sock"},
+		{host: "tcp://127.0.0.1:2375", exp: "tcp://127.0.0.1:2375"},
+		// ssh hosts are dialed via the connection helper, which uses a placeholder http host
+		{host: "ssh://airbyte@remote.example.com", exp: "http://docker.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			opts, err := hostOptions(tt.host)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			cli, err := client.NewClientWithOpts(opts...)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, cli.DaemonHost()); d != "" {
+				t.Error("host mismatch (-want +got):", d)
+			}
+		})
+	}
+
+	if _, err := hostOptions("ssh://"); err == nil {
+		t.Error("expected an error for an ssh host without a hostname")
+	}
+}
+
+func TestRemoteHost(t *testing.T) {
+	tests := map[string]string{
+		"ssh://airbyte@remote.example.com":    "remote.example.com",
+		"ssh://airbyte@remote.example.com:22": "remote.example.com",
+		"ssh://10.0.0.5":                      "10.0.0.5",
+		"unix:///var/run/docker.sock":         "",
+		"tcp://127.0.0.1:2375":                "",
+		"":                                    "",
+	}
+
+	for host, exp := range tests {
+		if d := cmp.Diff(exp, (&Docker{Host: host}).RemoteHost()); d != "" {
+			t.Errorf("remote host mismatch for %s (-want +got): %s", host, d)
+		}
+	}
+}
+
+func TestHosts(t *testing.T) {
+	home := paths.UserHome
+
+	tests := []struct {
+		name    string
+		goos    string
+		env     map[string]string
+		sockets []string
+		host    string
+		exp     []string
+	}{
+		{
+			name: "explicit host",
+			goos: "linux",
+			env:  map[string]string{"DOCKER_HOST": "unix:///env.sock"},
+			host: "ssh://user@remote",
+			exp:  []string{"ssh://user@remote"},
+		},
+		{
+			name: "linux defaults",
+			goos: "linux",
+			exp:  []string{"unix:///var/run/docker.sock"},
+		},
+		{
+			name: "docker host env",
+			goos: "linux",
+			env:  map[string]string{"DOCKER_HOST": "unix:///env.sock"},
+			exp:  []string{"unix:///env.sock", "unix:///var/run/docker.sock"},
+		},
+		{
+			name: "darwin colima and rancher desktop",
+			goos: "darwin",
+			sockets: []string{
+				filepath.Join(home, ".colima", "default", "docker.sock"),
+				filepath.Join(home, ".rd", "docker.sock"),
+			},
+			exp: []string{
+				"unix:///var/run/docker.sock",
+				"unix://" + filepath.Join(home, ".docker", "run", "docker.sock"),
+				"unix://" + filepath.Join(home, ".colima", "default", "docker.sock"),
+				"unix://" + filepath.Join(home, ".rd", "docker.sock"),
+			},
+		},
+		{
+			name:    "linux rootless and lima",
+			goos:    "linux",
+			env:     map[string]string{"XDG_RUNTIME_DIR": "/run/user/1000"},
+			sockets: []string{"/run/user/1000/docker.sock", filepath.Join(home, ".lima", "docker", "sock", "docker.sock")},
+			exp: []string{
+				"unix:///var/run/docker.sock",
+				"unix://" + filepath.Join(home, ".lima", "docker", "sock", "docker.sock"),
+				"unix:///run/user/1000/docker.sock",
+			},
+		},
+		{
+			name: "windows",
+			goos: "windows",
+			env:  map[string]string{"DOCKER_HOST": "tcp://127.0.0.1:2375"},
+			exp:  []string{"tcp://127.0.0.1:2375", "npipe:////./pipe/docker_engine"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := options{
+				host:   tt.host,
+				getenv: func(key string) string { return tt.env[key] },
+				exists: func(path string) bool {
+					for _, s := range tt.sockets {
+						if s == path {
+							return true
+						}
+					}
+					return false
+				},
+			}
+
+			if d := cmp.Diff(tt.exp, hosts(tt.goos, o)); d != "" {
+				t.Error("hosts mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
 func TestVersion_Err(t *testing.T) {
 	ctx := context.Background()
 	p := mockPinger{
@@ -237,6 +394,168 @@ func TestVersion_Err(t *testing.T) {
 	}
 }
 
+func TestPort(t *testing.T) {
+	ctx := context.Background()
+	p := mockPinger{
+		containerInspect: func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+			return types.ContainerJSON{
+				NetworkSettings: &types.NetworkSettings{
+					NetworkSettingsBase: types.NetworkSettingsBase{
+						Ports: map[nat.Port][]nat.PortBinding{
+							"6443/tcp": {{HostIP: "127.0.0.1", HostPort: "41234"}},
+							// the http port may be bound to a specific listen address
+							"80/tcp": {{HostIP: "127.0.0.1", HostPort: "8000"}},
+						},
+					},
+				},
+			}, nil
+		},
+	}
+
+	f := func(opts ...client.Opt) (pinger, error) { return p, nil }
+
+	cli, err := newWithOptions(ctx, f, "darwin")
+	if err != nil {
+		t.Fatal("failed creating client", err)
+	}
+
+	port, err := cli.Port(ctx, "container")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(8000, port); d != "" {
+		t.Error("port mismatch (-want +got):", d)
+	}
+}
+
+func TestPortMapped(t *testing.T) {
+	ctx := context.Background()
+	p := mockPinger{
+		containerInspect: func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+			return types.ContainerJSON{
+				NetworkSettings: &types.NetworkSettings{
+					NetworkSettingsBase: types.NetworkSettingsBase{
+						Ports: map[nat.Port][]nat.PortBinding{
+							"80/tcp":   {{HostIP: "0.0.0.0", HostPort: "8000"}},
+							"8001/tcp": {{HostIP: "0.0.0.0", HostPort: "8001"}},
+						},
+					},
+				},
+			}, nil
+		},
+	}
+
+	f := func(opts ...client.Opt) (pinger, error) { return p, nil }
+
+	cli, err := newWithOptions(ctx, f, "darwin")
+	if err != nil {
+		t.Fatal("failed creating client", err)
+	}
+
+	for port, exp := range map[int]bool{8001: true, 8002: false} {
+		mapped, err := cli.PortMapped(ctx, "container", port)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if d := cmp.Diff(exp, mapped); d != "" {
+			t.Errorf("port %d mapped mismatch (-want +got): %s", port, d)
+		}
+	}
+}
+
+func TestGateway(t *testing.T) {
+	ctx := context.Background()
+	networks := map[string]*network.EndpointSettings{
+		"bridge": {Gateway: "172.17.0.1"},
+		"kind":   {Gateway: "172.18.0.1"},
+		"v6":     {IPv6Gateway: "fc00:f853:ccd:e793::1"},
+	}
+	p := mockPinger{
+		containerInspect: func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+			return types.ContainerJSON{NetworkSettings: &types.NetworkSettings{Networks: networks}}, nil
+		},
+	}
+
+	f := func(opts ...client.Opt) (pinger, error) { return p, nil }
+
+	cli, err := newWithOptions(ctx, f, "linux")
+	if err != nil {
+		t.Fatal("failed creating client", err)
+	}
+
+	tests := map[string]string{
+		// the requested network is preferred
+		"kind": "172.18.0.1",
+		"v6":   "fc00:f853:ccd:e793::1",
+		// otherwise the first network, by name, with a gateway
+		"missing": "172.17.0.1",
+	}
+	for name, exp := range tests {
+		gw, err := cli.Gateway(ctx, "container", name)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if d := cmp.Diff(exp, gw); d != "" {
+			t.Errorf("network %s gateway mismatch (-want +got): %s", name, d)
+		}
+	}
+
+	networks = map[string]*network.EndpointSettings{}
+	if _, err := cli.Gateway(ctx, "container", "kind"); err == nil {
+		t.Error("expected an error without any networks")
+	}
+}
+
+func TestResources(t *testing.T) {
+	ctx := context.Background()
+	p := mockPinger{
+		info: func(ctx context.Context) (system.Info, error) {
+			return system.Info{NCPU: 4, MemTotal: 8 << 30}, nil
+		},
+	}
+
+	f := func(opts ...client.Opt) (pinger, error) { return p, nil }
+
+	cli, err := newWithOptions(ctx, f, "darwin")
+	if err != nil {
+		t.Fatal("failed creating client", err)
+	}
+
+	res, err := cli.Resources(ctx)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(Resources{CPUs: 4, Memory: 8 << 30}, res); d != "" {
+		t.Error("resources mismatch (-want +got):", d)
+	}
+}
+
+func TestPort_Stopped(t *testing.T) {
+	ctx := context.Background()
+	p := mockPinger{
+		containerInspect: func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+			return types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{
+					State: &types.ContainerState{Running: false, Status: "exited"},
+				},
+				NetworkSettings: &types.NetworkSettings{},
+			}, nil
+		},
+	}
+
+	f := func(opts ...client.Opt) (pinger, error) { return p, nil }
+
+	cli, err := newWithOptions(ctx, f, "darwin")
+	if err != nil {
+		t.Fatal("failed creating client", err)
+	}
+
+	_, err = cli.Port(ctx, "container")
+	if !errors.Is(err, localerr.ErrClusterStopped) {
+		t.Error("expected ErrClusterStopped, received", err)
+	}
+}
+
 func TestPort_Missing(t *testing.T) {
 	ctx := context.Background()
 	p := mockPinger{
@@ -317,6 +636,41 @@ func TestPort_Err(t *testing.T) {
 	}
 }
 
+func TestStartStop(t *testing.T) {
+	ctx := context.Background()
+
+	var calls []string
+	p := mockPinger{
+		containerStart: func(ctx context.Context, container string, options container.StartOptions) error {
+			calls = append(calls, "start "+container)
+			return nil
+		},
+		containerStop: func(ctx context.Context, container string, options container.StopOptions) error {
+			calls = append(calls, fmt.Sprintf("stop %s %d", container, *options.Timeout))
+			return nil
+		},
+	}
+
+	f := func(opts ...client.Opt) (pinger, error) { return p, nil }
+
+	cli, err := newWithOptions(ctx, f, "darwin")
+	if err != nil {
+		t.Fatal("failed creating client", err)
+	}
+
+	if err := cli.Stop(ctx, "airbyte-abctl-control-plane", time.Minute); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if err := cli.Start(ctx, "airbyte-abctl-control-plane"); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := []string{"stop airbyte-abctl-control-plane 60", "start airbyte-abctl-control-plane"}
+	if d := cmp.Diff(exp, calls); d != "" {
+		t.Error("calls mismatch (-want +got):", d)
+	}
+}
+
 // -- mocks
 var _ pinger = (*mockPinger)(nil)
 
@@ -332,6 +686,11 @@ type mockPinger struct {
 	containerExecInspect func(ctx context.Context, execID string) (types.ContainerExecInspect, error)
 	containerExecStart   func(ctx context.Context, execID string, config types.ExecStartCheck) error
 
+	imageInspectWithRaw func(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
+	imagePull           func(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error)
+	imageSave           func(ctx context.Context, imageIDs []string) (io.ReadCloser, error)
+
+	info          func(ctx context.Context) (system.Info, error)
 	serverVersion func(ctx context.Context) (types.Version, error)
 	volumeInspect func(ctx context.Context, volumeID string) (volume.Volume, error)
 
@@ -393,6 +752,22 @@ func (m mockPinger) ContainerInspect(ctx context.Context, containerID string) (t
 	return m.containerInspect(ctx, containerID)
 }
 
+func (m mockPinger) ImagePull(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error) {
+	return m.imagePull(ctx, refStr, options)
+}
+
+func (m mockPinger) ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+	return m.imageInspectWithRaw(ctx, imageID)
+}
+
+func (m mockPinger) ImageSave(ctx context.Context, imageIDs []string) (io.ReadCloser, error) {
+	return m.imageSave(ctx, imageIDs)
+}
+
+func (m mockPinger) Info(ctx context.Context) (system.Info, error) {
+	return m.info(ctx)
+}
+
 func (m mockPinger) ServerVersion(ctx context.Context) (types.Version, error) {
 	if m.serverVersion == nil {
 		return types.Version{
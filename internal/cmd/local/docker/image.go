@@ -0,0 +1,25 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"github.com/docker/docker/client"
+	"io"
+)
+
+// SaveImage returns the archive of the image, as `docker save` would write it, which must be closed by the caller.
+// The image must exist locally, e.g. built by `docker build`, it is not pulled.
+func (d *Docker) SaveImage(ctx context.Context, image string) (io.ReadCloser, error) {
+	if _, _, err := d.Client.ImageInspectWithRaw(ctx, image); err != nil {
+		if client.IsErrNotFound(err) {
+			return nil, fmt.Errorf("image %s does not exist locally, it must be built or pulled first", image)
+		}
+		return nil, fmt.Errorf("could not inspect image %s: %w", image, err)
+	}
+
+	archive, err := d.Client.ImageSave(ctx, []string{image})
+	if err != nil {
+		return nil, fmt.Errorf("could not save image %s: %w", image, err)
+	}
+	return archive, nil
+}
@@ -0,0 +1,74 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSaveImage(t *testing.T) {
+	ctx := context.Background()
+
+	p := mockPinger{
+		imageInspectWithRaw: func(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+			return types.ImageInspect{ID: imageID}, nil, nil
+		},
+		imageSave: func(ctx context.Context, imageIDs []string) (io.ReadCloser, error) {
+			if d := cmp.Diff([]string{"airbyte/source-test:dev"}, imageIDs); d != "" {
+				t.Error("images mismatch (-want +got):", d)
+			}
+			return io.NopCloser(strings.NewReader("archive")), nil
+		},
+	}
+
+	f := func(opts ...client.Opt) (pinger, error) { return p, nil }
+	cli, err := newWithOptions(ctx, f, "darwin")
+	if err != nil {
+		t.Fatal("failed creating client", err)
+	}
+
+	archive, err := cli.SaveImage(ctx, "airbyte/source-test:dev")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	defer archive.Close()
+
+	data, err := io.ReadAll(archive)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff("archive", string(data)); d != "" {
+		t.Error("archive mismatch (-want +got):", d)
+	}
+}
+
+func TestSaveImage_NotFound(t *testing.T) {
+	ctx := context.Background()
+
+	p := mockPinger{
+		imageInspectWithRaw: func(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+			return types.ImageInspect{}, nil, errdefs.NotFound(errors.New("no such image"))
+		},
+		imageSave: func(ctx context.Context, imageIDs []string) (io.ReadCloser, error) {
+			t.Error("unexpected save")
+			return nil, nil
+		},
+	}
+
+	f := func(opts ...client.Opt) (pinger, error) { return p, nil }
+	cli, err := newWithOptions(ctx, f, "darwin")
+	if err != nil {
+		t.Fatal("failed creating client", err)
+	}
+
+	_, err = cli.SaveImage(ctx, "airbyte/source-test:dev")
+	if err == nil || !strings.Contains(err.Error(), "does not exist locally") {
+		t.Error("expected not found error, got", err)
+	}
+}
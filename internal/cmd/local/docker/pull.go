@@ -0,0 +1,92 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/progress"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"io"
+	"time"
+)
+
+// pullReportInterval limits how often the progress of an image pull is reported, as its layers report their progress
+// many times per second, defined here for testing purposes.
+var pullReportInterval = time.Second
+
+// pull pulls the image, reporting the bytes pulled by its layers and the estimated remaining time, if report is not
+// nil. The progress is reported with an empty status once the pull completes.
+func (d *Docker) pull(ctx context.Context, ref string, report progress.Reporter) error {
+	logging.Debugf("Pulling image '%s'", ref)
+	out, err := d.Client.ImagePull(ctx, ref, image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("could not pull image %s: %w", ref, err)
+	}
+	defer out.Close()
+
+	if report != nil {
+		defer report("")
+	}
+
+	// the pull only completes once its progress has been read
+	if err := readPullProgress(out, report); err != nil {
+		return fmt.Errorf("could not pull image %s: %w", ref, err)
+	}
+	return nil
+}
+
+// layerProgress is the progress of the download of a single layer.
+type layerProgress struct {
+	current, total int64
+}
+
+// readPullProgress reads the progress of an image pull until it completes, reporting the bytes downloaded by all of
+// its layers. Progress which cannot be decoded is discarded.
+func readPullProgress(r io.Reader, report progress.Reporter) error {
+	tracker := progress.NewTracker(progress.Bytes)
+	layers := map[string]layerProgress{}
+	var reported time.Time
+
+	dec := json.NewDecoder(r)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			// not the expected progress, read the remainder so the pull still completes
+			_, err := io.Copy(io.Discard, io.MultiReader(dec.Buffered(), r))
+			return err
+		}
+		if report == nil || msg.ID == "" {
+			continue
+		}
+
+		switch msg.Status {
+		case "Downloading":
+			if msg.Progress != nil && msg.Progress.Total > 0 {
+				layers[msg.ID] = layerProgress{current: msg.Progress.Current, total: msg.Progress.Total}
+			}
+		case "Download complete":
+			if l, ok := layers[msg.ID]; ok {
+				layers[msg.ID] = layerProgress{current: l.total, total: l.total}
+			}
+		default:
+			continue
+		}
+
+		var done, total int64
+		for _, l := range layers {
+			done += l.current
+			total += l.total
+		}
+		tracker.Update(done, total)
+		if time.Since(reported) >= pullReportInterval {
+			report(tracker.String())
+			reported = time.Now()
+		}
+	}
+}
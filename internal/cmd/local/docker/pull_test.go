@@ -0,0 +1,50 @@
+package docker
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+func TestReadPullProgress(t *testing.T) {
+	stream := `{"status":"Pulling from library/registry","id":"2"}
+{"status":"Pulling fs layer","id":"a"}
+{"status":"Pulling fs layer","id":"b"}
+{"status":"Downloading","progressDetail":{"current":524288,"total":1048576},"id":"a"}
+{"status":"Downloading","progressDetail":{"current":1048576,"total":3145728},"id":"b"}
+{"status":"Download complete","id":"a"}
+{"status":"Extracting","progressDetail":{"current":1048576,"total":1048576},"id":"a"}
+{"status":"Pull complete","id":"a"}
+{"status":"Digest: sha256:abc"}
+`
+
+	origInterval := pullReportInterval
+	pullReportInterval = 0
+	t.Cleanup(func() { pullReportInterval = origInterval })
+
+	var reports []string
+	report := func(status string) {
+		reports = append(reports, status)
+	}
+	if err := readPullProgress(strings.NewReader(stream), report); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := []string{"0.5/1.0 MB pulled", "1.5/4.0 MB pulled", "2.0/4.0 MB pulled"}
+	if d := cmp.Diff(exp, reports); d != "" {
+		t.Error("reports mismatch (-want +got):", d)
+	}
+}
+
+func TestReadPullProgress_Invalid(t *testing.T) {
+	var reports []string
+	report := func(status string) {
+		reports = append(reports, status)
+	}
+	if err := readPullProgress(strings.NewReader("progress"), report); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if len(reports) > 0 {
+		t.Error("unexpected reports", reports)
+	}
+}
@@ -0,0 +1,82 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/progress"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"path/filepath"
+)
+
+const (
+	// ScanImage is the trivy image which scans the images for vulnerabilities.
+	ScanImage = "aquasec/trivy:0.54.1"
+	// scanCacheVolume stores the trivy vulnerability database, so it is not downloaded again by every scan.
+	scanCacheVolume = "airbyte-abctl-scan-cache"
+	scanReports     = "/reports"
+)
+
+// Scan scans every image for vulnerabilities, writing the json report of images[i] to dir/<i>.json.
+// The images are scanned by trivy, run within a container, which pulls the images from their registries itself.
+// Returns the paths of the reports, in the same order as the images.
+// The progress of pulling the image of trivy is reported to report, if not nil.
+func (d *Docker) Scan(ctx context.Context, dir string, images []string, report progress.Reporter) ([]string, error) {
+	if err := d.pull(ctx, ScanImage, report); err != nil {
+		return nil, err
+	}
+
+	// docker run -d -v airbyte-abctl-scan-cache:/root/.cache/trivy --entrypoint tail aquasec/trivy -f /dev/null
+	con, err := d.Client.ContainerCreate(
+		ctx,
+		&container.Config{
+			Image:      ScanImage,
+			Entrypoint: []string{"tail", "-f", "/dev/null"},
+		},
+		&container.HostConfig{
+			Mounts: []mount.Mount{{
+				Type:   mount.TypeVolume,
+				Source: scanCacheVolume,
+				Target: "/root/.cache/trivy",
+			}},
+		},
+		nil,
+		nil,
+		"")
+	if err != nil {
+		return nil, fmt.Errorf("could not create scan container: %w", err)
+	}
+	logging.Debugf("Created scan container '%s'", con.ID)
+	done := d.trackContainer(con.ID)
+	defer func() {
+		d.stopAndRemoveContainer(ctx, con.ID)
+		done()
+	}()
+
+	if err := d.Client.ContainerStart(ctx, con.ID, container.StartOptions{}); err != nil {
+		return nil, fmt.Errorf("could not start container %s: %w", con.ID, err)
+	}
+
+	if err := d.exec(ctx, con.ID, []string{"mkdir", "-p", scanReports}); err != nil {
+		return nil, fmt.Errorf("could not create reports directory: %w", err)
+	}
+
+	reports := make([]string, len(images))
+	for i, img := range images {
+		logging.Debugf("Scanning image '%s'", img)
+		report := fmt.Sprintf("%d.json", i)
+		cmd := []string{"trivy", "image", "--quiet", "--format", "json", "--output", scanReports + "/" + report, img}
+		if err := d.exec(ctx, con.ID, cmd); err != nil {
+			return nil, fmt.Errorf("could not scan image %s: %w", img, err)
+		}
+		reports[i] = filepath.Join(dir, report)
+	}
+
+	// note the src must end with a `.`, due to how docker cp works with directories
+	if err := d.copyFromContainer(ctx, con.ID, scanReports+"/.", dir); err != nil {
+		return nil, fmt.Errorf("could not copy scan reports from container %s: %w", con.ID, err)
+	}
+
+	return reports, nil
+}
@@ -0,0 +1,156 @@
+// Package events persists the interesting kubernetes events of an Airbyte installation, so they are available
+// after the command which observed them has completed.
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MaxEvents is the number of most recent events retained by the event log.
+const MaxEvents = 1000
+
+const (
+	// SeverityNormal is the severity of kubernetes events of type Normal.
+	SeverityNormal = "normal"
+	// SeverityWarning is the severity of kubernetes events of type Warning.
+	SeverityWarning = "warning"
+)
+
+// Event is a persisted kubernetes event.
+type Event struct {
+	Time     time.Time `json:"time"`
+	Severity string    `json:"severity"`
+	Reason   string    `json:"reason"`
+	// Namespace is the namespace of the object the event is regarding, empty for events recorded by earlier versions.
+	Namespace string `json:"namespace,omitempty"`
+	// Object is the kind and name of the object the event is regarding, e.g. Pod/airbyte-abctl-server-0.
+	Object  string `json:"object"`
+	Message string `json:"message"`
+	Count   int32  `json:"count,omitempty"`
+}
+
+// interestingReasons are the reasons of normal events which are worth persisting.
+var interestingReasons = map[string]struct{}{
+	"backoff":    {},
+	"killing":    {},
+	"pulled":     {},
+	"pulling":    {},
+	"unhealthy":  {},
+	"oomkilling": {},
+}
+
+// Interesting returns true if the event should be persisted.
+// Every warning is interesting, as are normal events regarding image pulls, backoffs, and killed containers.
+func Interesting(e Event) bool {
+	if e.Severity == SeverityWarning {
+		return true
+	}
+	_, ok := interestingReasons[strings.ToLower(e.Reason)]
+	return ok
+}
+
+// Severity returns the severity of the kubernetes event type.
+func Severity(eventType string) string {
+	if strings.EqualFold(eventType, "warning") {
+		return SeverityWarning
+	}
+	return SeverityNormal
+}
+
+// Load returns the events stored in the event log located at path, oldest first.
+// If no event log exists, no events and no error are returned.
+func Load(path string) ([]Event, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not read event log %s: %w", path, err)
+	}
+
+	var events []Event
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e Event
+		// skip any line which cannot be decoded, e.g. one which was only partially written
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read event log %s: %w", path, err)
+	}
+
+	return events, nil
+}
+
+// Append adds the events to the event log located at path, creating it if it does not exist.
+// Only the MaxEvents most recent events are retained.
+func Append(path string, events ...Event) error {
+	existing, err := Load(path)
+	if err != nil {
+		return err
+	}
+	all := append(existing, events...)
+	if len(all) > MaxEvents {
+		all = all[len(all)-MaxEvents:]
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range all {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("could not encode event: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("could not create directories for %s: %w", path, err)
+	}
+
+	// write to a temporary file first, so a concurrent Load never reads a partially written event log
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("could not write event log %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("could not replace event log %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Filter returns the events which occurred at or after since, with the severity, regarding an object of the namespace.
+// A zero since, or an empty severity or namespace, does not filter by that attribute. Events without a namespace are
+// never filtered by the namespace.
+func Filter(events []Event, since time.Time, severity, namespace string) []Event {
+	var filtered []Event
+	for _, e := range events {
+		if !since.IsZero() && e.Time.Before(since) {
+			continue
+		}
+		if severity != "" && e.Severity != severity {
+			continue
+		}
+		if namespace != "" && e.Namespace != "" && e.Namespace != namespace {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+	return filtered
+}
@@ -0,0 +1,109 @@
+package events
+
+import (
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "events.jsonl")
+
+	// a missing event log has no events
+	events, err := Load(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if len(events) != 0 {
+		t.Error("expected no events", events)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var exp []Event
+	for i := 0; i < MaxEvents+5; i++ {
+		e := Event{Time: start.Add(time.Duration(i) * time.Second), Severity: SeverityWarning, Reason: "BackOff", Object: fmt.Sprintf("Pod/server-%d", i)}
+		exp = append(exp, e)
+	}
+	// append across multiple calls, the first of which fills the event log
+	if err := Append(path, exp[:MaxEvents]...); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if err := Append(path, exp[MaxEvents:]...); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	events, err = Load(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	// only the most recent events are retained
+	if d := cmp.Diff(exp[5:], events); d != "" {
+		t.Error("events mismatch (-want +got):", d)
+	}
+}
+
+func TestLoad_PartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	data := `{"time":"2024-01-01T00:00:00Z","severity":"warning","reason":"BackOff","object":"Pod/server","message":"back-off"}
+{"time":"2024-01-01T00:00:01Z","sev`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal("could not write event log", err)
+	}
+
+	events, err := Load(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	exp := []Event{{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Severity: SeverityWarning, Reason: "BackOff", Object: "Pod/server", Message: "back-off"}}
+	if d := cmp.Diff(exp, events); d != "" {
+		t.Error("events mismatch (-want +got):", d)
+	}
+}
+
+func TestInteresting(t *testing.T) {
+	tests := []struct {
+		event Event
+		exp   bool
+	}{
+		{event: Event{Severity: SeverityWarning, Reason: "FailedMount"}, exp: true},
+		{event: Event{Severity: SeverityNormal, Reason: "Pulling"}, exp: true},
+		{event: Event{Severity: SeverityNormal, Reason: "BackOff"}, exp: true},
+		{event: Event{Severity: SeverityNormal, Reason: "Scheduled"}, exp: false},
+		{event: Event{Severity: SeverityNormal, Reason: "Created"}, exp: false},
+	}
+
+	for _, tt := range tests {
+		if d := cmp.Diff(tt.exp, Interesting(tt.event)); d != "" {
+			t.Errorf("%s %s mismatch (-want +got): %s", tt.event.Severity, tt.event.Reason, d)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Time: start, Severity: SeverityWarning, Reason: "BackOff"},
+		{Time: start.Add(time.Hour), Severity: SeverityNormal, Reason: "Pulled"},
+		{Time: start.Add(2 * time.Hour), Severity: SeverityWarning, Reason: "Unhealthy", Namespace: "airbyte-abctl"},
+		{Time: start.Add(3 * time.Hour), Severity: SeverityNormal, Reason: "Pulled", Namespace: "team-a"},
+	}
+
+	if d := cmp.Diff(events, Filter(events, time.Time{}, "", "")); d != "" {
+		t.Error("unfiltered mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff(events[1:], Filter(events, start.Add(time.Hour), "", "")); d != "" {
+		t.Error("since mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff([]Event{events[0], events[2]}, Filter(events, time.Time{}, SeverityWarning, "")); d != "" {
+		t.Error("severity mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff([]Event{events[2]}, Filter(events, start.Add(time.Hour), SeverityWarning, "")); d != "" {
+		t.Error("since and severity mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff(events[:3], Filter(events, time.Time{}, "", "airbyte-abctl")); d != "" {
+		t.Error("namespace mismatch (-want +got):", d)
+	}
+}
@@ -0,0 +1,132 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/pterm/pterm"
+	"net"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// commandRunner runs the command and returns its combined output.
+// Defined for testing purposes.
+type commandRunner func(ctx context.Context, name string, args ...string) (string, error)
+
+// runCommand is the default commandRunner, it can be overwritten for testing purposes.
+var runCommand commandRunner = func(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	return string(out), err
+}
+
+// firewall is a host firewall which is known to block LAN access to the ingress port.
+type firewall struct {
+	// name of the firewall
+	name string
+	// allow returns the commands necessary for allowing access to the port.
+	allow func(port int) []string
+}
+
+var (
+	firewallUFW = firewall{
+		name: "ufw",
+		allow: func(port int) []string {
+			return []string{fmt.Sprintf("sudo ufw allow %d/tcp", port)}
+		},
+	}
+	firewallFirewalld = firewall{
+		name: "firewalld",
+		allow: func(port int) []string {
+			return []string{
+				fmt.Sprintf("sudo firewall-cmd --permanent --add-port=%d/tcp", port),
+				"sudo firewall-cmd --reload",
+			}
+		},
+	}
+	firewallWindows = firewall{
+		name: "Windows Defender Firewall",
+		allow: func(port int) []string {
+			return []string{fmt.Sprintf(
+				`New-NetFirewallRule -DisplayName "Airbyte (abctl)" -Direction Inbound -Protocol TCP -LocalPort %d -Action Allow`, port,
+			)}
+		},
+	}
+	firewallMacOS = firewall{
+		name: "macOS Application Firewall",
+		allow: func(int) []string {
+			return []string{
+				"sudo /usr/libexec/ApplicationFirewall/socketfilterfw --add /Applications/Docker.app",
+				"sudo /usr/libexec/ApplicationFirewall/socketfilterfw --unblockapp /Applications/Docker.app",
+			}
+		},
+	}
+)
+
+// detectFirewalls returns the host firewalls which are currently active.
+// Any firewall whose state cannot be determined (e.g. it isn't installed) is assumed to be inactive.
+func detectFirewalls(ctx context.Context, goos string, run commandRunner) []firewall {
+	active := func(name string, args []string, contains string) bool {
+		out, err := run(ctx, name, args...)
+		if err != nil {
+			logging.Debugf("Unable to determine firewall state via %s: %s", name, err)
+			return false
+		}
+		return strings.Contains(strings.ToLower(out), contains)
+	}
+
+	var firewalls []firewall
+	switch goos {
+	case "linux":
+		if active("ufw", []string{"status"}, "status: active") {
+			firewalls = append(firewalls, firewallUFW)
+		}
+		if active("firewall-cmd", []string{"--state"}, "running") {
+			firewalls = append(firewalls, firewallFirewalld)
+		}
+	case "windows":
+		if active("netsh", []string{"advfirewall", "show", "currentprofile", "state"}, "on") {
+			firewalls = append(firewalls, firewallWindows)
+		}
+	case "darwin":
+		if active("/usr/libexec/ApplicationFirewall/socketfilterfw", []string{"--getglobalstate"}, "enabled") {
+			firewalls = append(firewalls, firewallMacOS)
+		}
+	}
+
+	return firewalls
+}
+
+// isLocalHost returns true if the host is only reachable from this machine.
+func isLocalHost(host string) bool {
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback()
+	}
+	return false
+}
+
+// firewallGuidance warns if any of the hosts are expected to be reachable from other machines but an active
+// host firewall may block access to the port, printing the commands necessary for allowing access.
+func firewallGuidance(ctx context.Context, hosts []string, port int) {
+	var lan []string
+	for _, host := range hosts {
+		if !isLocalHost(host) {
+			lan = append(lan, host)
+		}
+	}
+	if len(lan) == 0 {
+		return
+	}
+
+	for _, fw := range detectFirewalls(ctx, runtime.GOOS, runCommand) {
+		pterm.Warning.Printfln(
+			"The %s is active and may block access to port %d from other machines (host: %s).\n"+
+				"If Airbyte is not reachable from another machine, allow access to the port by running:\n  %s",
+			fw.name, port, strings.Join(lan, ", "), strings.Join(fw.allow(port), "\n  "),
+		)
+	}
+}
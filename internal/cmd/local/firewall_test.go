@@ -0,0 +1,98 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestDetectFirewalls(t *testing.T) {
+	tests := []struct {
+		name    string
+		goos    string
+		outputs map[string]string
+		exp     []string
+	}{
+		{
+			name:    "linux ufw active",
+			goos:    "linux",
+			outputs: map[string]string{"ufw": "Status: active\n\nTo Action From"},
+			exp:     []string{"ufw"},
+		},
+		{
+			name:    "linux ufw inactive",
+			goos:    "linux",
+			outputs: map[string]string{"ufw": "Status: inactive"},
+		},
+		{
+			name:    "linux ufw and firewalld",
+			goos:    "linux",
+			outputs: map[string]string{"ufw": "Status: active", "firewall-cmd": "running"},
+			exp:     []string{"ufw", "firewalld"},
+		},
+		{
+			name:    "linux none installed",
+			goos:    "linux",
+			outputs: map[string]string{},
+		},
+		{
+			name:    "windows",
+			goos:    "windows",
+			outputs: map[string]string{"netsh": "Private Profile Settings:\nState                                 ON"},
+			exp:     []string{"Windows Defender Firewall"},
+		},
+		{
+			name:    "darwin",
+			goos:    "darwin",
+			outputs: map[string]string{"/usr/libexec/ApplicationFirewall/socketfilterfw": "Firewall is enabled. (State = 1)"},
+			exp:     []string{"macOS Application Firewall"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := func(_ context.Context, name string, _ ...string) (string, error) {
+				out, ok := tt.outputs[name]
+				if !ok {
+					return "", errors.New("command not found")
+				}
+				return out, nil
+			}
+
+			var names []string
+			for _, fw := range detectFirewalls(context.Background(), tt.goos, run) {
+				names = append(names, fw.name)
+			}
+			if d := cmp.Diff(tt.exp, names); d != "" {
+				t.Error("firewalls mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestIsLocalHost(t *testing.T) {
+	tests := map[string]bool{
+		"localhost":         true,
+		"LOCALHOST":         true,
+		"airbyte.localhost": true,
+		"127.0.0.1":         true,
+		"::1":               true,
+		"airbyte.example":   false,
+		"192.168.1.10":      false,
+		"0.0.0.0":           false,
+	}
+
+	for host, exp := range tests {
+		if d := cmp.Diff(exp, isLocalHost(host)); d != "" {
+			t.Errorf("isLocalHost(%s) mismatch (-want +got):\n%s", host, d)
+		}
+	}
+}
+
+func TestFirewallAllow(t *testing.T) {
+	exp := []string{"sudo firewall-cmd --permanent --add-port=8000/tcp", "sudo firewall-cmd --reload"}
+	if d := cmp.Diff(exp, firewallFirewalld.allow(8000)); d != "" {
+		t.Error("allow mismatch (-want +got):", d)
+	}
+}
@@ -1,49 +1,72 @@
 package k8s
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"net/http"
 	"path"
 	"strings"
+	"time"
 )
 
 // DefaultPersistentVolumeSize is the size of the disks created by the persistent-volumes and requested by
 // the persistent-volume-claims.
 var DefaultPersistentVolumeSize = resource.MustParse("500Mi")
 
+// StandardStorageClass is the storage class kind provides, which is used unless another storage class is provided.
+const StandardStorageClass = "standard"
+
 // Client primarily for testing purposes
 type Client interface {
 	// IngressCreate creates an ingress in the given namespace
 	IngressCreate(ctx context.Context, namespace string, ingress *networkingv1.Ingress) error
+	// IngressGet returns the ingress in the given namespace
+	IngressGet(ctx context.Context, namespace string, ingress string) (*networkingv1.Ingress, error)
 	// IngressExists returns true if the ingress exists in the namespace, false otherwise.
 	IngressExists(ctx context.Context, namespace string, ingress string) bool
 	// IngressUpdate updates an existing ingress in the given namespace
 	IngressUpdate(ctx context.Context, namespace string, ingress *networkingv1.Ingress) error
+	// IngressClassList returns the ingress classes of the cluster
+	IngressClassList(ctx context.Context) (*networkingv1.IngressClassList, error)
 
 	// NamespaceCreate creates a namespace
 	NamespaceCreate(ctx context.Context, namespace string) error
 	// NamespaceExists returns true if the namespace exists, false otherwise
 	NamespaceExists(ctx context.Context, namespace string) bool
+	// NamespaceList returns the names of all the namespaces
+	NamespaceList(ctx context.Context) ([]string, error)
 	// NamespaceDelete deletes the existing namespace
 	NamespaceDelete(ctx context.Context, namespace string) error
 
-	// PersistentVolumeCreate creates a persistent volume
-	PersistentVolumeCreate(ctx context.Context, namespace, name string) error
+	// PersistentVolumeCreate creates a hostPath persistent volume of the storage class
+	PersistentVolumeCreate(ctx context.Context, namespace, name, storageClass string) error
 	// PersistentVolumeExists returns true if the persistent volume exists, false otherwise
 	PersistentVolumeExists(ctx context.Context, namespace, name string) bool
 	// PersistentVolumeDelete deletes the existing persistent volume
 	PersistentVolumeDelete(ctx context.Context, namespace, name string) error
+	// PersistentVolumeList returns all the persistent volumes of the cluster
+	PersistentVolumeList(ctx context.Context) (*corev1.PersistentVolumeList, error)
 
-	// PersistentVolumeClaimCreate creates a persistent volume claim
-	PersistentVolumeClaimCreate(ctx context.Context, namespace, name, volumeName string) error
+	// PersistentVolumeClaimCreate creates a persistent volume claim of the storage class, bound to the volumeName.
+	// If the volumeName is empty, the volume is dynamically provisioned by the storage class.
+	// If the storageClass is empty, the default storage class of the cluster is used.
+	PersistentVolumeClaimCreate(ctx context.Context, namespace, name, volumeName, storageClass string) error
 	// PersistentVolumeClaimExists returns true if the persistent volume claim exists, false otherwise
 	PersistentVolumeClaimExists(ctx context.Context, namespace, name, volumeName string) bool
 	// PersistentVolumeClaimDelete deletes the existing persistent volume claim
@@ -51,23 +74,74 @@ type Client interface {
 
 	// SecretCreateOrUpdate will update or create the secret name with the payload of data in the specified namespace
 	SecretCreateOrUpdate(ctx context.Context, namespace, name string, data map[string][]byte) error
+	// SecretGet returns the secret in the given namespace
+	SecretGet(ctx context.Context, namespace, name string) (*corev1.Secret, error)
+	// SecretList returns the secrets in the given namespace matching the label selector, every secret if it is empty
+	SecretList(ctx context.Context, namespace, selector string) (*corev1.SecretList, error)
+	// SecretDelete deletes the secret in the given namespace
+	SecretDelete(ctx context.Context, namespace, name string) error
 
 	// ServiceGet returns a the service for the given namespace and name
 	ServiceGet(ctx context.Context, namespace, name string) (*corev1.Service, error)
+	// ServiceProxyGet sends a GET request for the path, with the query params, to the port of the service via
+	// the kubernetes api server proxy, returning the response body.
+	ServiceProxyGet(ctx context.Context, namespace, name, port, path string, params map[string]string) ([]byte, error)
 
 	// ServerVersionGet returns the kubernetes version.
 	ServerVersionGet() (string, error)
+	// PermissionsDenied returns the permissions the user of the kubeconfig is not allowed within the namespace.
+	PermissionsDenied(ctx context.Context, namespace string, permissions []Permission) ([]Permission, error)
+
+	// ManifestApply creates or updates the object with server-side apply, within the namespace unless the object has
+	// a namespace of its own or is cluster-scoped. The namespace of obj is set to the namespace it was applied within.
+	ManifestApply(ctx context.Context, namespace string, obj *unstructured.Unstructured) error
+	// ManifestDelete deletes the object, identified by its apiVersion, kind, namespace, and name
+	ManifestDelete(ctx context.Context, obj *unstructured.Unstructured) error
 
 	EventsWatch(ctx context.Context, namespace string) (watch.Interface, error)
+	// EventsList returns all the events in the given namespace
+	EventsList(ctx context.Context, namespace string) (*eventsv1.EventList, error)
 
 	LogsGet(ctx context.Context, namespace string, name string) (string, error)
+	// LogsFollow streams the logs of the pod, until the pod terminates or the ctx is done
+	LogsFollow(ctx context.Context, namespace string, name string) (io.ReadCloser, error)
+
+	// PodList returns all the pods in the given namespace
+	PodList(ctx context.Context, namespace string) (*corev1.PodList, error)
+	// PodExec executes the command within the first container of the pod, writing its output to stdout
+	PodExec(ctx context.Context, namespace, name string, cmd []string, stdout io.Writer) error
+	// PodExecStreams executes the command within the first container of the pod, connected to the streams
+	PodExecStreams(ctx context.Context, namespace, name string, cmd []string, streams ExecStreams) error
+
+	// DeploymentList returns all the deployments in the given namespace
+	DeploymentList(ctx context.Context, namespace string) (*appsv1.DeploymentList, error)
+	// DeploymentScale sets the replicas of the deployment
+	DeploymentScale(ctx context.Context, namespace, name string, replicas int32) error
+	// DeploymentRestart triggers a rollout restart of the deployment, the same as kubectl rollout restart
+	DeploymentRestart(ctx context.Context, namespace, name string) error
+	// StatefulSetList returns all the stateful sets in the given namespace
+	StatefulSetList(ctx context.Context, namespace string) (*appsv1.StatefulSetList, error)
+	// StatefulSetScale sets the replicas of the stateful set
+	StatefulSetScale(ctx context.Context, namespace, name string, replicas int32) error
 }
 
 var _ Client = (*DefaultK8sClient)(nil)
 
+// ExecStreams are the streams a command executed by PodExecStreams is connected to.
+type ExecStreams struct {
+	// Stdin, if not nil, is read by the command until it is exhausted.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// TTY allocates a terminal for the command, in which case its stderr is written to Stdout.
+	TTY bool
+}
+
 // DefaultK8sClient converts the official kubernetes client to our more manageable (and testable) interface
 type DefaultK8sClient struct {
 	ClientSet *kubernetes.Clientset
+	// RestConfig is required by PodExec, which streams from the pod outside the ClientSet
+	RestConfig *rest.Config
 }
 
 func (d *DefaultK8sClient) IngressCreate(ctx context.Context, namespace string, ingress *networkingv1.Ingress) error {
@@ -75,6 +149,10 @@ func (d *DefaultK8sClient) IngressCreate(ctx context.Context, namespace string,
 	return err
 }
 
+func (d *DefaultK8sClient) IngressGet(ctx context.Context, namespace string, ingress string) (*networkingv1.Ingress, error) {
+	return d.ClientSet.NetworkingV1().Ingresses(namespace).Get(ctx, ingress, metav1.GetOptions{})
+}
+
 func (d *DefaultK8sClient) IngressExists(ctx context.Context, namespace string, ingress string) bool {
 	_, err := d.ClientSet.NetworkingV1().Ingresses(namespace).Get(ctx, ingress, metav1.GetOptions{})
 	if err == nil {
@@ -89,6 +167,10 @@ func (d *DefaultK8sClient) IngressUpdate(ctx context.Context, namespace string,
 	return err
 }
 
+func (d *DefaultK8sClient) IngressClassList(ctx context.Context) (*networkingv1.IngressClassList, error) {
+	return d.ClientSet.NetworkingV1().IngressClasses().List(ctx, metav1.ListOptions{})
+}
+
 func (d *DefaultK8sClient) NamespaceCreate(ctx context.Context, namespace string) error {
 	_, err := d.ClientSet.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}, metav1.CreateOptions{})
 	return err
@@ -103,11 +185,24 @@ func (d *DefaultK8sClient) NamespaceExists(ctx context.Context, namespace string
 	return !k8serrors.IsNotFound(err)
 }
 
+func (d *DefaultK8sClient) NamespaceList(ctx context.Context) ([]string, error) {
+	list, err := d.ClientSet.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(list.Items))
+	for i, ns := range list.Items {
+		names[i] = ns.Name
+	}
+	return names, nil
+}
+
 func (d *DefaultK8sClient) NamespaceDelete(ctx context.Context, namespace string) error {
 	return d.ClientSet.CoreV1().Namespaces().Delete(ctx, namespace, metav1.DeleteOptions{})
 }
 
-func (d *DefaultK8sClient) PersistentVolumeCreate(ctx context.Context, namespace, name string) error {
+func (d *DefaultK8sClient) PersistentVolumeCreate(ctx context.Context, namespace, name, storageClass string) error {
 	hostPathType := corev1.HostPathDirectoryOrCreate
 
 	pv := &corev1.PersistentVolume{
@@ -116,7 +211,7 @@ func (d *DefaultK8sClient) PersistentVolumeCreate(ctx context.Context, namespace
 			Capacity: corev1.ResourceList{corev1.ResourceStorage: DefaultPersistentVolumeSize},
 			PersistentVolumeSource: corev1.PersistentVolumeSource{
 				HostPath: &corev1.HostPathVolumeSource{
-					Path: path.Join("/var/local-path-provisioner", name),
+					Path: path.Join(localPathProvisioner, name),
 					Type: &hostPathType,
 				},
 			},
@@ -124,7 +219,7 @@ func (d *DefaultK8sClient) PersistentVolumeCreate(ctx context.Context, namespace
 				corev1.ReadWriteOnce,
 			},
 			PersistentVolumeReclaimPolicy: "Retain",
-			StorageClassName:              "standard",
+			StorageClassName:              storageClass,
 		},
 	}
 
@@ -144,19 +239,24 @@ func (d *DefaultK8sClient) PersistentVolumeDelete(ctx context.Context, _, name s
 	return d.ClientSet.CoreV1().PersistentVolumes().Delete(ctx, name, metav1.DeleteOptions{})
 }
 
-func (d *DefaultK8sClient) PersistentVolumeClaimCreate(ctx context.Context, namespace, name, volumeName string) error {
-	storageClass := "standard"
+func (d *DefaultK8sClient) PersistentVolumeList(ctx context.Context) (*corev1.PersistentVolumeList, error) {
+	return d.ClientSet.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+}
 
+func (d *DefaultK8sClient) PersistentVolumeClaimCreate(ctx context.Context, namespace, name, volumeName, storageClass string) error {
 	pvc := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{Name: name},
 		Spec: corev1.PersistentVolumeClaimSpec{
-			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-			Resources:        corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: DefaultPersistentVolumeSize}},
-			VolumeName:       volumeName,
-			StorageClassName: &storageClass,
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources:   corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: DefaultPersistentVolumeSize}},
+			VolumeName:  volumeName,
 		},
 		Status: corev1.PersistentVolumeClaimStatus{},
 	}
+	// a nil storage class name results in the default storage class being used
+	if storageClass != "" {
+		pvc.Spec.StorageClassName = &storageClass
+	}
 
 	_, err := d.ClientSet.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, pvc, metav1.CreateOptions{})
 	return err
@@ -175,6 +275,10 @@ func (d *DefaultK8sClient) PersistentVolumeClaimDelete(ctx context.Context, name
 	return d.ClientSet.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 }
 
+func (d *DefaultK8sClient) SecretGet(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	return d.ClientSet.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
 func (d *DefaultK8sClient) SecretCreateOrUpdate(ctx context.Context, namespace, name string, data map[string][]byte) error {
 	secret := &corev1.Secret{
 		TypeMeta: metav1.TypeMeta{},
@@ -205,6 +309,14 @@ func (d *DefaultK8sClient) SecretCreateOrUpdate(ctx context.Context, namespace,
 	return fmt.Errorf("unexpected error while handling the secret %s: %w", name, err)
 }
 
+func (d *DefaultK8sClient) SecretList(ctx context.Context, namespace, selector string) (*corev1.SecretList, error) {
+	return d.ClientSet.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+}
+
+func (d *DefaultK8sClient) SecretDelete(ctx context.Context, namespace, name string) error {
+	return d.ClientSet.CoreV1().Secrets(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
 func (d *DefaultK8sClient) ServerVersionGet() (string, error) {
 	ver, err := d.ClientSet.DiscoveryClient.ServerVersion()
 	if err != nil {
@@ -218,10 +330,113 @@ func (d *DefaultK8sClient) ServiceGet(ctx context.Context, namespace string, nam
 	return d.ClientSet.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
+func (d *DefaultK8sClient) ServiceProxyGet(ctx context.Context, namespace, name, port, path string, params map[string]string) ([]byte, error) {
+	return d.ClientSet.CoreV1().Services(namespace).ProxyGet("http", name, port, path, params).DoRaw(ctx)
+}
+
 func (d *DefaultK8sClient) EventsWatch(ctx context.Context, namespace string) (watch.Interface, error) {
 	return d.ClientSet.EventsV1().Events(namespace).Watch(ctx, metav1.ListOptions{})
 }
 
+func (d *DefaultK8sClient) EventsList(ctx context.Context, namespace string) (*eventsv1.EventList, error) {
+	return d.ClientSet.EventsV1().Events(namespace).List(ctx, metav1.ListOptions{})
+}
+
+func (d *DefaultK8sClient) PodList(ctx context.Context, namespace string) (*corev1.PodList, error) {
+	return d.ClientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+}
+
+func (d *DefaultK8sClient) PodExec(ctx context.Context, namespace, name string, cmd []string, stdout io.Writer) error {
+	req := d.ClientSet.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{Command: cmd, Stdout: true, Stderr: true}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(d.RestConfig, http.MethodPost, req.URL())
+	if err != nil {
+		return fmt.Errorf("could not create executor for pod %s: %w", name, err)
+	}
+
+	var stderr bytes.Buffer
+	if err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: stdout, Stderr: &stderr}); err != nil {
+		return fmt.Errorf("could not execute %s in pod %s: %w: %s", cmd[0], name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (d *DefaultK8sClient) PodExecStreams(ctx context.Context, namespace, name string, cmd []string, streams ExecStreams) error {
+	opts := &corev1.PodExecOptions{
+		Command: cmd,
+		Stdin:   streams.Stdin != nil,
+		Stdout:  streams.Stdout != nil,
+		Stderr:  streams.Stderr != nil && !streams.TTY,
+		TTY:     streams.TTY,
+	}
+	req := d.ClientSet.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(name).
+		SubResource("exec").
+		VersionedParams(opts, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(d.RestConfig, http.MethodPost, req.URL())
+	if err != nil {
+		return fmt.Errorf("could not create executor for pod %s: %w", name, err)
+	}
+
+	streamOpts := remotecommand.StreamOptions{Stdin: streams.Stdin, Stdout: streams.Stdout, Tty: streams.TTY}
+	if opts.Stderr {
+		streamOpts.Stderr = streams.Stderr
+	}
+	if err := executor.StreamWithContext(ctx, streamOpts); err != nil {
+		return fmt.Errorf("could not execute %s in pod %s: %w", cmd[0], name, err)
+	}
+	return nil
+}
+
+func (d *DefaultK8sClient) DeploymentList(ctx context.Context, namespace string) (*appsv1.DeploymentList, error) {
+	return d.ClientSet.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+}
+
+func (d *DefaultK8sClient) DeploymentScale(ctx context.Context, namespace, name string, replicas int32) error {
+	scale, err := d.ClientSet.AppsV1().Deployments(namespace).GetScale(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get scale of deployment %s: %w", name, err)
+	}
+	scale.Spec.Replicas = replicas
+	if _, err := d.ClientSet.AppsV1().Deployments(namespace).UpdateScale(ctx, name, scale, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("could not scale deployment %s: %w", name, err)
+	}
+	return nil
+}
+
+func (d *DefaultK8sClient) DeploymentRestart(ctx context.Context, namespace, name string) error {
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":"%s"}}}}}`,
+		time.Now().Format(time.RFC3339))
+	if _, err := d.ClientSet.AppsV1().Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("could not restart deployment %s: %w", name, err)
+	}
+	return nil
+}
+
+func (d *DefaultK8sClient) StatefulSetList(ctx context.Context, namespace string) (*appsv1.StatefulSetList, error) {
+	return d.ClientSet.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+}
+
+func (d *DefaultK8sClient) StatefulSetScale(ctx context.Context, namespace, name string, replicas int32) error {
+	scale, err := d.ClientSet.AppsV1().StatefulSets(namespace).GetScale(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get scale of stateful set %s: %w", name, err)
+	}
+	scale.Spec.Replicas = replicas
+	if _, err := d.ClientSet.AppsV1().StatefulSets(namespace).UpdateScale(ctx, name, scale, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("could not scale stateful set %s: %w", name, err)
+	}
+	return nil
+}
+
 func (d *DefaultK8sClient) LogsGet(ctx context.Context, namespace string, name string) (string, error) {
 	req := d.ClientSet.CoreV1().Pods(namespace).GetLogs(name, &corev1.PodLogOptions{})
 	reader, err := req.Stream(ctx)
@@ -235,3 +450,12 @@ func (d *DefaultK8sClient) LogsGet(ctx context.Context, namespace string, name s
 	}
 	return buf.String(), nil
 }
+
+func (d *DefaultK8sClient) LogsFollow(ctx context.Context, namespace string, name string) (io.ReadCloser, error) {
+	req := d.ClientSet.CoreV1().Pods(namespace).GetLogs(name, &corev1.PodLogOptions{Follow: true})
+	reader, err := req.Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not follow logs for pod %s: %w", name, err)
+	}
+	return reader, nil
+}
@@ -3,18 +3,32 @@ package k8s
 import (
 	"fmt"
 	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"k8s.io/client-go/tools/clientcmd"
+	"net"
+	"os"
+	"runtime"
 	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // Cluster is an interface representing all the actions taken at the cluster level.
 type Cluster interface {
 	// Create a cluster with the provided name.
-	Create(portHTTP int) error
+	Create(portHTTP int, opts CreateOpts) error
 	// Delete a cluster with the provided name.
 	Delete() error
 	// Exists returns true if the cluster exists, false otherwise.
 	Exists() bool
+	// Nodes returns the names of the docker containers of every node of the cluster.
+	Nodes() ([]string, error)
+	// ExportKubeconfig writes the context of the cluster to its kubeconfig again.
+	ExportKubeconfig() error
+	// LoadImage loads the image archive file (as written by `docker save`) onto every node of the cluster.
+	LoadImage(archive string) error
 }
 
 // interface sanity check
@@ -27,15 +41,179 @@ type kindCluster struct {
 	// kubeconfig is the full path to the kubeconfig file kind is using
 	kubeconfig  string
 	clusterName string
+	// remoteHost is the hostname of the remote docker host (if docker is accessed via ssh), empty otherwise
+	remoteHost string
+	// log records the messages of the kind provider, nil if they are not recorded
+	log *kindLogger
+}
+
+// CreateOpts are the optional configuration options used when creating a cluster.
+type CreateOpts struct {
+	// NodeImage is the kind node image, defaults to kindest/node of the supported kubernetes version.
+	NodeImage string
+	// ExtraMounts are additional directories of the docker host to mount into the kind node.
+	ExtraMounts []Mount
+	// KindConfig is a custom kind config, merged with the kind config generated by abctl.
+	KindConfig []byte
+	// APIServerPort is the host port of the kubernetes api server, zero lets kind choose a random port.
+	APIServerPort int
+	// BindAddress is the host address the http port (and the api port) is bound to, defaults to all addresses.
+	// See ValidBindAddress.
+	BindAddress string
+	// APIPort, if not zero, is the host port of the Airbyte api, which is mapped to the same port of the node.
+	APIPort int
+	// IPFamily is the ip family of the cluster (IPFamilyIPv4, IPFamilyIPv6, or IPFamilyDual), defaults to ipv4.
+	IPFamily string
+	// RegistryMirrors are the urls of mirrors of docker hub, e.g. an image cache or a private registry, which the
+	// kind node pulls images from in order, falling back to docker hub if none of them are available.
+	RegistryMirrors []string
+	// CACert, if not empty, is the path of a PEM bundle of CA certificates on the docker host, which the kind node
+	// trusts in addition to its own, e.g. the CA of a TLS-intercepting proxy the images are pulled through.
+	CACert string
+}
+
+// caCertNodePath is where the CACert is mounted within the kind node. Every certificate of the /etc/ssl/certs
+// directory is trusted by containerd, without updating the certificate bundle of the node.
+const caCertNodePath = "/etc/ssl/certs/abctl-ca.pem"
+
+// The supported ip families of a cluster.
+const (
+	IPFamilyIPv4 = "ipv4"
+	IPFamilyIPv6 = "ipv6"
+	IPFamilyDual = "dual"
+)
+
+// ValidIPFamily returns an error if the family is not a supported ip family.
+func ValidIPFamily(family string) error {
+	switch family {
+	case IPFamilyIPv4, IPFamilyIPv6, IPFamilyDual:
+		return nil
+	default:
+		return fmt.Errorf("unsupported ip family %s, expected one of %s, %s, or %s", family, IPFamilyIPv4, IPFamilyIPv6, IPFamilyDual)
+	}
+}
+
+// ValidBindAddress returns an error if the address is not an ip address the ports of a cluster can be bound to.
+func ValidBindAddress(address string) error {
+	if net.ParseIP(address) == nil {
+		return fmt.Errorf("invalid bind address %s, expected an ipv4 or ipv6 address", address)
+	}
+	return nil
+}
+
+// DialAddress returns the host the ports of a cluster bound to the address are reachable on from this machine.
+// The ports are bound to all addresses if the address is empty or unspecified (e.g. 0.0.0.0 or ::), in which case
+// they are reachable on localhost.
+func DialAddress(address string) string {
+	if ip := net.ParseIP(address); ip == nil || ip.IsUnspecified() {
+		return "localhost"
+	}
+	return address
 }
 
 const k8sVersion = "v1.29.1"
 
-func (k *kindCluster) Create(port int) error {
+// defaultNodeImage is the kind node image used when no NodeImage is provided.
+const defaultNodeImage = "kindest/node:" + k8sVersion
+
+func (k *kindCluster) Create(port int, createOpts CreateOpts) error {
+	// the data directory is only mounted for a local docker host, as the directory would need to exist on the remote host
+	var dataDir string
+	if k.remoteHost == "" {
+		if err := prepareDataDir(paths.Data); err != nil {
+			return fmt.Errorf("unable to prepare data directory: %w", err)
+		}
+		dataDir = hostPath(paths.Data, runtime.GOOS)
+	}
+
+	mounts := make([]Mount, len(createOpts.ExtraMounts))
+	for i, m := range createOpts.ExtraMounts {
+		m.HostPath = hostPath(m.HostPath, runtime.GOOS)
+		mounts[i] = m
+	}
+	createOpts.ExtraMounts = mounts
+	if createOpts.CACert != "" {
+		createOpts.CACert = hostPath(createOpts.CACert, runtime.GOOS)
+	}
+
+	rawCfg := kindConfig(port, dataDir, k.remoteHost, createOpts)
+
+	opts := []cluster.CreateOption{
+		cluster.CreateWithWaitForReady(120 * time.Second),
+		cluster.CreateWithKubeconfigPath(k.kubeconfig),
+	}
+
+	// the node image overrides the image of every node, a custom kind config may specify its own node images
+	switch {
+	case createOpts.NodeImage != "":
+		opts = append(opts, cluster.CreateWithNodeImage(createOpts.NodeImage))
+	case len(createOpts.KindConfig) == 0:
+		opts = append(opts, cluster.CreateWithNodeImage(defaultNodeImage))
+	}
+
+	if len(createOpts.KindConfig) > 0 {
+		var err error
+		if rawCfg, err = mergeKindConfig(rawCfg, createOpts.KindConfig, port); err != nil {
+			return fmt.Errorf("unable to merge kind config: %w", err)
+		}
+	}
+	opts = append(opts, cluster.CreateWithRawConfig([]byte(rawCfg)))
+
+	if err := k.p.Create(k.clusterName, opts...); err != nil {
+		return newCreateError(err, k.log)
+	}
+
+	if k.remoteHost != "" {
+		if err := setServerHost(k.kubeconfig, kindContext(k.clusterName), k.remoteHost); err != nil {
+			return fmt.Errorf("unable to update kubeconfig for remote host %s: %w", k.remoteHost, err)
+		}
+	}
+
+	return nil
+}
+
+// kindConfig returns the kind cluster configuration.
+//
+// If dataDir is not empty, it is mounted into the node for persisting data.
+// If remoteHost is not empty, the api server is exposed on all interfaces of the remote host and its certificate
+// is valid for the remoteHost, allowing the cluster to be accessed from this machine.
+// The opts ExtraMounts and CACert are mounted into the node in addition to the dataDir, and the opts networking options are
+// applied to the api server and http port mapping. The opts NodeImage and KindConfig are not part of this config.
+func kindConfig(port int, dataDir, remoteHost string, opts CreateOpts) string {
 	// see https://kind.sigs.k8s.io/docs/user/ingress/#create-cluster
-	rawCfg := fmt.Sprintf(`kind: Cluster
+	cfg := `kind: Cluster
 apiVersion: kind.x-k8s.io/v1alpha4
-nodes:
+`
+	if remoteHost != "" || opts.APIServerPort != 0 || opts.IPFamily != "" {
+		cfg += `networking:
+`
+	}
+	if opts.IPFamily != "" {
+		cfg += fmt.Sprintf(`  ipFamily: %s
+`, opts.IPFamily)
+	}
+	if remoteHost != "" {
+		cfg += `  apiServerAddress: "0.0.0.0"
+`
+	}
+	if opts.APIServerPort != 0 {
+		cfg += fmt.Sprintf(`  apiServerPort: %d
+`, opts.APIServerPort)
+	}
+
+	if len(opts.RegistryMirrors) > 0 {
+		endpoints := make([]string, len(opts.RegistryMirrors))
+		for i, mirror := range opts.RegistryMirrors {
+			endpoints[i] = strconv.Quote(mirror)
+		}
+		cfg += fmt.Sprintf(`containerdConfigPatches:
+  - |-
+    [plugins."io.containerd.grpc.v1.cri".registry.mirrors."docker.io"]
+      endpoint = [%s]
+`, strings.Join(endpoints, ", "))
+	}
+
+	cfg += `nodes:
   - role: control-plane
     kubeadmConfigPatches:
     - |
@@ -43,28 +221,61 @@ nodes:
       nodeRegistration:
         kubeletExtraArgs:
           node-labels: "ingress-ready=true"
-    extraMounts:
-      - hostPath: %s
-        containerPath: /var/local-path-provisioner
-    extraPortMappings:
-      - containerPort: 80
-        hostPort: %d
-        protocol: TCP`,
-		paths.Data,
-		port)
+`
+	if remoteHost != "" {
+		cfg += fmt.Sprintf(`    - |
+      kind: ClusterConfiguration
+      apiServer:
+        certSANs:
+        - %q
+`, remoteHost)
+	}
 
-	opts := []cluster.CreateOption{
-		cluster.CreateWithWaitForReady(120 * time.Second),
-		cluster.CreateWithKubeconfigPath(k.kubeconfig),
-		cluster.CreateWithNodeImage("kindest/node:" + k8sVersion),
-		cluster.CreateWithRawConfig([]byte(rawCfg)),
+	if dataDir != "" || len(opts.ExtraMounts) > 0 || opts.CACert != "" {
+		cfg += `    extraMounts:
+`
+	}
+	if dataDir != "" {
+		cfg += fmt.Sprintf(`      - hostPath: %q
+        containerPath: %s
+`, dataDir, localPathProvisioner)
+	}
+	for _, m := range opts.ExtraMounts {
+		cfg += fmt.Sprintf(`      - hostPath: %q
+        containerPath: %q
+`, m.HostPath, m.ContainerPath)
+		if m.ReadOnly {
+			cfg += `        readOnly: true
+`
+		}
+	}
+	if opts.CACert != "" {
+		cfg += fmt.Sprintf(`      - hostPath: %q
+        containerPath: %s
+        readOnly: true
+`, opts.CACert, caCertNodePath)
 	}
 
-	if err := k.p.Create(k.clusterName, opts...); err != nil {
-		return fmt.Errorf("unable to create kind cluster: %w", err)
+	cfg += `    extraPortMappings:`
+	cfg += portMapping(80, port, opts.BindAddress)
+	if opts.APIPort != 0 {
+		cfg += portMapping(opts.APIPort, opts.APIPort, opts.BindAddress)
 	}
 
-	return nil
+	return cfg
+}
+
+// portMapping returns the extraPortMappings entry mapping the containerPort of the node to the hostPort.
+func portMapping(containerPort, hostPort int, bindAddress string) string {
+	mapping := fmt.Sprintf(`
+      - containerPort: %d
+        hostPort: %d
+        protocol: TCP`, containerPort, hostPort)
+	if bindAddress != "" {
+		mapping += fmt.Sprintf(`
+        listenAddress: %q`, bindAddress)
+	}
+	return mapping
 }
 
 func (k *kindCluster) Delete() error {
@@ -85,3 +296,102 @@ func (k *kindCluster) Exists() bool {
 
 	return false
 }
+
+func (k *kindCluster) Nodes() ([]string, error) {
+	nodes, err := k.p.ListNodes(k.clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list nodes of kind cluster: %w", err)
+	}
+
+	names := make([]string, len(nodes))
+	for i, n := range nodes {
+		names[i] = n.String()
+	}
+	return names, nil
+}
+
+// LoadImage loads the image archive onto every node, as `kind load image-archive` would.
+func (k *kindCluster) LoadImage(archive string) error {
+	nodes, err := k.p.ListNodes(k.clusterName)
+	if err != nil {
+		return fmt.Errorf("unable to list nodes of kind cluster: %w", err)
+	}
+
+	for _, n := range nodes {
+		if err := loadImageArchive(n, archive); err != nil {
+			return fmt.Errorf("unable to load image onto node %s: %w", n, err)
+		}
+	}
+	return nil
+}
+
+func loadImageArchive(n nodes.Node, archive string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return nodeutils.LoadImageArchive(n, f)
+}
+
+func (k *kindCluster) ExportKubeconfig() error {
+	if err := k.p.ExportKubeConfig(k.clusterName, k.kubeconfig, false); err != nil {
+		return fmt.Errorf("unable to export kubeconfig of kind cluster: %w", err)
+	}
+
+	if k.remoteHost != "" {
+		if err := setServerHost(k.kubeconfig, kindContext(k.clusterName), k.remoteHost); err != nil {
+			return fmt.Errorf("unable to update kubeconfig for remote host %s: %w", k.remoteHost, err)
+		}
+	}
+
+	return nil
+}
+
+// interface sanity check
+var _ Cluster = (*existingCluster)(nil)
+
+// existingCluster is a Cluster implementation for an existing cluster, which abctl does not manage.
+type existingCluster struct {
+	kubeconfig string
+	context    string
+}
+
+func (e *existingCluster) Create(int, CreateOpts) error {
+	return fmt.Errorf("context %s does not exist in kubeconfig %s, existing clusters cannot be created", e.context, e.kubeconfig)
+}
+
+// Delete is a noop, as abctl does not manage the lifecycle of an existing cluster.
+func (e *existingCluster) Delete() error {
+	return nil
+}
+
+func (e *existingCluster) Exists() bool {
+	return ContextExists(e.kubeconfig, e.context)
+}
+
+// Nodes returns an error, as the nodes of an existing cluster are not docker containers managed by abctl.
+func (e *existingCluster) Nodes() ([]string, error) {
+	return nil, fmt.Errorf("the nodes of the existing cluster %s are not managed by abctl", e.context)
+}
+
+// ExportKubeconfig returns an error, as the kubeconfig of the existing cluster is not managed by abctl.
+func (e *existingCluster) ExportKubeconfig() error {
+	return fmt.Errorf("the kubeconfig %s of the existing cluster %s is not managed by abctl", e.kubeconfig, e.context)
+}
+
+// LoadImage returns an error, as the nodes of an existing cluster are not managed by abctl, the image must be pushed to
+// a registry the cluster pulls from instead.
+func (e *existingCluster) LoadImage(string) error {
+	return fmt.Errorf("images cannot be loaded onto the existing cluster %s, push the image to a registry it pulls from instead", e.context)
+}
+
+// ContextExists returns true if the context exists in the kubeconfig, false otherwise.
+func ContextExists(kubeconfig, context string) bool {
+	cfg, err := clientcmd.LoadFromFile(kubeconfig)
+	if err != nil {
+		return false
+	}
+	_, ok := cfg.Contexts[context]
+	return ok
+}
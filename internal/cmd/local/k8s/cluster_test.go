@@ -0,0 +1,194 @@
+package k8s
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestKindConfig(t *testing.T) {
+	local := kindConfig(8000, "/home/airbyte/.airbyte/abctl/data", "", CreateOpts{})
+	exp := `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+  - role: control-plane
+    kubeadmConfigPatches:
+    - |
+      kind: InitConfiguration
+      nodeRegistration:
+        kubeletExtraArgs:
+          node-labels: "ingress-ready=true"
+    extraMounts:
+      - hostPath: "/home/airbyte/.airbyte/abctl/data"
+        containerPath: /var/local-path-provisioner
+    extraPortMappings:
+      - containerPort: 80
+        hostPort: 8000
+        protocol: TCP`
+	if d := cmp.Diff(exp, local); d != "" {
+		t.Error("local config mismatch (-want +got):", d)
+	}
+
+	remote := kindConfig(8000, "", "remote.example.com", CreateOpts{})
+	exp = `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+networking:
+  apiServerAddress: "0.0.0.0"
+nodes:
+  - role: control-plane
+    kubeadmConfigPatches:
+    - |
+      kind: InitConfiguration
+      nodeRegistration:
+        kubeletExtraArgs:
+          node-labels: "ingress-ready=true"
+    - |
+      kind: ClusterConfiguration
+      apiServer:
+        certSANs:
+        - "remote.example.com"
+    extraPortMappings:
+      - containerPort: 80
+        hostPort: 8000
+        protocol: TCP`
+	if d := cmp.Diff(exp, remote); d != "" {
+		t.Error("remote config mismatch (-want +got):", d)
+	}
+
+	mounts := kindConfig(8000, "/home/airbyte/.airbyte/abctl/data", "", CreateOpts{ExtraMounts: []Mount{
+		{HostPath: "/data/datasets", ContainerPath: "/datasets", ReadOnly: true},
+		{HostPath: "/data/models", ContainerPath: "/models"},
+	}, CACert: "/certs/ca.pem"})
+	exp = `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+  - role: control-plane
+    kubeadmConfigPatches:
+    - |
+      kind: InitConfiguration
+      nodeRegistration:
+        kubeletExtraArgs:
+          node-labels: "ingress-ready=true"
+    extraMounts:
+      - hostPath: "/home/airbyte/.airbyte/abctl/data"
+        containerPath: /var/local-path-provisioner
+      - hostPath: "/data/datasets"
+        containerPath: "/datasets"
+        readOnly: true
+      - hostPath: "/data/models"
+        containerPath: "/models"
+      - hostPath: "/certs/ca.pem"
+        containerPath: /etc/ssl/certs/abctl-ca.pem
+        readOnly: true
+    extraPortMappings:
+      - containerPort: 80
+        hostPort: 8000
+        protocol: TCP`
+	if d := cmp.Diff(exp, mounts); d != "" {
+		t.Error("mounts config mismatch (-want +got):", d)
+	}
+
+	networking := kindConfig(8000, "/home/airbyte/.airbyte/abctl/data", "", CreateOpts{
+		APIServerPort: 6443,
+		BindAddress:   "127.0.0.1",
+		IPFamily:      IPFamilyDual,
+		APIPort:       8001,
+	})
+	exp = `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+networking:
+  ipFamily: dual
+  apiServerPort: 6443
+nodes:
+  - role: control-plane
+    kubeadmConfigPatches:
+    - |
+      kind: InitConfiguration
+      nodeRegistration:
+        kubeletExtraArgs:
+          node-labels: "ingress-ready=true"
+    extraMounts:
+      - hostPath: "/home/airbyte/.airbyte/abctl/data"
+        containerPath: /var/local-path-provisioner
+    extraPortMappings:
+      - containerPort: 80
+        hostPort: 8000
+        protocol: TCP
+        listenAddress: "127.0.0.1"
+      - containerPort: 8001
+        hostPort: 8001
+        protocol: TCP
+        listenAddress: "127.0.0.1"`
+	if d := cmp.Diff(exp, networking); d != "" {
+		t.Error("networking config mismatch (-want +got):", d)
+	}
+
+	mirror := kindConfig(8000, "/home/airbyte/.airbyte/abctl/data", "", CreateOpts{RegistryMirrors: []string{"http://airbyte-abctl-cache:5000", "https://mirror.example.com"}})
+	exp = `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+containerdConfigPatches:
+  - |-
+    [plugins."io.containerd.grpc.v1.cri".registry.mirrors."docker.io"]
+      endpoint = ["http://airbyte-abctl-cache:5000", "https://mirror.example.com"]
+nodes:
+  - role: control-plane
+    kubeadmConfigPatches:
+    - |
+      kind: InitConfiguration
+      nodeRegistration:
+        kubeletExtraArgs:
+          node-labels: "ingress-ready=true"
+    extraMounts:
+      - hostPath: "/home/airbyte/.airbyte/abctl/data"
+        containerPath: /var/local-path-provisioner
+    extraPortMappings:
+      - containerPort: 80
+        hostPort: 8000
+        protocol: TCP`
+	if d := cmp.Diff(exp, mirror); d != "" {
+		t.Error("mirror config mismatch (-want +got):", d)
+	}
+	// the generated config must be valid
+	if _, err := decodeKindConfig([]byte(mirror)); err != nil {
+		t.Error("unexpected error decoding mirror config", err)
+	}
+}
+
+func TestValidIPFamily(t *testing.T) {
+	for _, family := range []string{IPFamilyIPv4, IPFamilyIPv6, IPFamilyDual} {
+		if err := ValidIPFamily(family); err != nil {
+			t.Errorf("unexpected error for %s: %s", family, err)
+		}
+	}
+	if err := ValidIPFamily("ipv5"); err == nil {
+		t.Error("expected an error for ipv5")
+	}
+}
+
+func TestValidBindAddress(t *testing.T) {
+	for _, address := range []string{"0.0.0.0", "127.0.0.1", "192.168.1.10", "::", "::1", "fd00::10"} {
+		if err := ValidBindAddress(address); err != nil {
+			t.Errorf("unexpected error for %s: %s", address, err)
+		}
+	}
+	for _, address := range []string{"", "localhost", "[::1]", "192.168.1.10:8000"} {
+		if err := ValidBindAddress(address); err == nil {
+			t.Errorf("expected an error for %s", address)
+		}
+	}
+}
+
+func TestDialAddress(t *testing.T) {
+	tests := map[string]string{
+		"":             "localhost",
+		"0.0.0.0":      "localhost",
+		"::":           "localhost",
+		"127.0.0.1":    "127.0.0.1",
+		"192.168.1.10": "192.168.1.10",
+		"fd00::10":     "fd00::10",
+	}
+	for address, exp := range tests {
+		if d := cmp.Diff(exp, DialAddress(address)); d != "" {
+			t.Errorf("dial address of %s mismatch (-want +got): %s", address, d)
+		}
+	}
+}
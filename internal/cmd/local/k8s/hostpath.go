@@ -0,0 +1,56 @@
+package k8s
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"os"
+	"path"
+	"strings"
+)
+
+// localPathProvisioner is the path, within the kind node, where the persisted data directory is mounted.
+// As this is a path within a linux container, it must always use forward slashes regardless of the host os.
+const localPathProvisioner = "/var/local-path-provisioner"
+
+// hostPath returns the dir formatted as a kind extraMounts hostPath for the given goos.
+//
+// On Windows, Docker Desktop expects drive-letter paths using forward slashes (e.g. C:/Users/airbyte),
+// as backslashes are not reliably handled when the kind config is parsed.
+func hostPath(dir, goos string) string {
+	if goos != "windows" {
+		return dir
+	}
+	return strings.ReplaceAll(dir, `\`, "/")
+}
+
+// prepareDataDir ensures the dir exists prior to the cluster being created, as docker would otherwise create it
+// owned by root. The permissions of an existing dir are left as they are, the dir must not be writable by other users
+// as it contains the database and storage of airbyte.
+func prepareDataDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("could not create directory %s: %w", dir, err)
+	}
+	return nil
+}
+
+// PersistedDataDir returns the name of the directory, within the persisted data directory, the persistent volume
+// stores its data in. This is the name of the volume for the volumes abctl creates, and pvc-<uid>_<namespace>_<claim>
+// for the volumes the local-path-provisioner provisions dynamically. False is returned if the volume does not store
+// its data within the persisted data directory.
+func PersistedDataDir(pv corev1.PersistentVolume) (string, bool) {
+	var p string
+	switch {
+	case pv.Spec.HostPath != nil:
+		p = pv.Spec.HostPath.Path
+	case pv.Spec.Local != nil:
+		p = pv.Spec.Local.Path
+	default:
+		return "", false
+	}
+
+	rel, ok := strings.CutPrefix(path.Clean(p), localPathProvisioner+"/")
+	if !ok {
+		return "", false
+	}
+	return strings.SplitN(rel, "/", 2)[0], true
+}
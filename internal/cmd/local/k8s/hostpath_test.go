@@ -0,0 +1,136 @@
+package k8s
+
+import (
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestHostPath(t *testing.T) {
+	tests := []struct {
+		name string
+		dir  string
+		goos string
+		exp  string
+	}{
+		{
+			name: "linux",
+			dir:  "/home/airbyte/.airbyte/abctl/data",
+			goos: "linux",
+			exp:  "/home/airbyte/.airbyte/abctl/data",
+		},
+		{
+			name: "darwin",
+			dir:  "/Users/airbyte/.airbyte/abctl/data",
+			goos: "darwin",
+			exp:  "/Users/airbyte/.airbyte/abctl/data",
+		},
+		{
+			name: "windows",
+			dir:  `C:\Users\airbyte\.airbyte\abctl\data`,
+			goos: "windows",
+			exp:  "C:/Users/airbyte/.airbyte/abctl/data",
+		},
+		{
+			name: "windows other drive",
+			dir:  `D:\airbyte\data`,
+			goos: "windows",
+			exp:  "D:/airbyte/data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.exp, hostPath(tt.dir, tt.goos)); d != "" {
+				t.Error("host path mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestPrepareDataDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not supported on windows")
+	}
+
+	dir := filepath.Join(t.TempDir(), "abctl", "data")
+	if err := prepareDataDir(dir); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	stat, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal("could not stat dir", err)
+	}
+	if !stat.IsDir() {
+		t.Error("expected a directory")
+	}
+	if perm := stat.Mode().Perm(); perm&0022 != 0 {
+		t.Errorf("expected the dir to not be writable by others, got %s", perm)
+	}
+
+	// an existing dir is left as it is
+	if err := os.Chmod(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := prepareDataDir(dir); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if stat, err = os.Stat(dir); err != nil {
+		t.Fatal("could not stat dir", err)
+	}
+	if d := cmp.Diff(os.FileMode(0700), stat.Mode().Perm()); d != "" {
+		t.Error("permissions mismatch (-want +got):", d)
+	}
+}
+
+func TestPersistedDataDir(t *testing.T) {
+	tests := []struct {
+		name   string
+		source corev1.PersistentVolumeSource
+		exp    string
+		expOK  bool
+	}{
+		{
+			name:   "abctl volume",
+			source: corev1.PersistentVolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/local-path-provisioner/airbyte-minio-pv"}},
+			exp:    "airbyte-minio-pv",
+			expOK:  true,
+		},
+		{
+			name:   "provisioned volume",
+			source: corev1.PersistentVolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/local-path-provisioner/pvc-0b1c_airbyte-abctl_storage-airbyte-loki-0"}},
+			exp:    "pvc-0b1c_airbyte-abctl_storage-airbyte-loki-0",
+			expOK:  true,
+		},
+		{
+			name:   "local volume subdirectory",
+			source: corev1.PersistentVolumeSource{Local: &corev1.LocalVolumeSource{Path: "/var/local-path-provisioner/pvc-0b1c_airbyte-abctl_data/sub"}},
+			exp:    "pvc-0b1c_airbyte-abctl_data",
+			expOK:  true,
+		},
+		{
+			name:   "other path",
+			source: corev1.PersistentVolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/lib/data"}},
+		},
+		{
+			name:   "other source",
+			source: corev1.PersistentVolumeSource{NFS: &corev1.NFSVolumeSource{Server: "nfs", Path: "/var/local-path-provisioner/data"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, ok := PersistedDataDir(corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{PersistentVolumeSource: tt.source}})
+			if d := cmp.Diff(tt.exp, dir); d != "" {
+				t.Error("dir mismatch (-want +got):", d)
+			}
+			if ok != tt.expOK {
+				t.Errorf("expected ok %t, got %t", tt.expOK, ok)
+			}
+		})
+	}
+}
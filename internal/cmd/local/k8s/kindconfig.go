@@ -0,0 +1,180 @@
+package k8s
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+	"strings"
+)
+
+const (
+	kindConfigKind       = "Cluster"
+	kindConfigAPIVersion = "kind.x-k8s.io/v1alpha4"
+)
+
+// ReadKindConfig reads and validates the custom kind config located at path.
+func ReadKindConfig(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read kind config %s: %w", path, err)
+	}
+
+	if err := ValidateKindConfig(data); err != nil {
+		return nil, fmt.Errorf("invalid kind config %s: %w", path, err)
+	}
+
+	return data, nil
+}
+
+// SaveKindConfig stores the custom kind config at path, creating any missing directories.
+func SaveKindConfig(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("could not create directory %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("could not write kind config %s: %w", path, err)
+	}
+	return nil
+}
+
+// ValidateKindConfig validates that the custom kind config can be merged with the config generated by abctl.
+func ValidateKindConfig(data []byte) error {
+	cfg, err := decodeKindConfig(data)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Kind != kindConfigKind || cfg.APIVersion != kindConfigAPIVersion {
+		return fmt.Errorf("unsupported kind %s (apiVersion: %s), expected %s (apiVersion: %s)",
+			cfg.Kind, cfg.APIVersion, kindConfigKind, kindConfigAPIVersion)
+	}
+
+	for _, node := range cfg.Nodes {
+		for _, m := range node.ExtraMounts {
+			if !strings.HasPrefix(m.ContainerPath, "/") {
+				return fmt.Errorf("the extraMounts containerPath %s must be absolute", m.ContainerPath)
+			}
+			if m.ContainerPath == localPathProvisioner {
+				return fmt.Errorf("the extraMounts containerPath %s is reserved for the persisted data", localPathProvisioner)
+			}
+		}
+		for _, pm := range node.ExtraPortMappings {
+			if pm.ContainerPort == 80 && nodeRole(node) == v1alpha4.ControlPlaneRole {
+				return errors.New("the extraPortMappings containerPort 80 of the control-plane is reserved for the ingress")
+			}
+		}
+	}
+
+	return nil
+}
+
+// decodeKindConfig decodes the kind config, unknown fields are considered an error.
+func decodeKindConfig(data []byte) (v1alpha4.Cluster, error) {
+	var cfg v1alpha4.Cluster
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return v1alpha4.Cluster{}, fmt.Errorf("could not decode kind config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// nodeRole returns the role of the node, which defaults to the control-plane.
+func nodeRole(node v1alpha4.Node) v1alpha4.NodeRole {
+	if node.Role == "" {
+		return v1alpha4.ControlPlaneRole
+	}
+	return node.Role
+}
+
+// mergeKindConfig merges the custom kind config into the generated kind config.
+//
+// The cluster level fields of the custom config are used as-is, except for the networking apiServerAddress which is
+// required for a remote docker host, and the networking apiServerPort and ipFamily if they were generated. The generated control-plane node is merged with the first control-plane node of
+// the custom config, any other nodes (e.g. workers) of the custom config are added to the cluster.
+// Nodes which do not specify an image use the default node image.
+// The ports the ingress and the Airbyte api are exposed on must not be mapped by the custom config.
+func mergeKindConfig(generated string, custom []byte, port int) (string, error) {
+	gen, err := decodeKindConfig([]byte(generated))
+	if err != nil {
+		return "", fmt.Errorf("could not decode generated kind config: %w", err)
+	}
+	if err := ValidateKindConfig(custom); err != nil {
+		return "", err
+	}
+	cfg, err := decodeKindConfig(custom)
+	if err != nil {
+		return "", err
+	}
+
+	if gen.Networking.APIServerAddress != "" {
+		if cfg.Networking.APIServerAddress != "" && cfg.Networking.APIServerAddress != gen.Networking.APIServerAddress {
+			return "", fmt.Errorf("the networking apiServerAddress must be %s when using a remote docker host", gen.Networking.APIServerAddress)
+		}
+		cfg.Networking.APIServerAddress = gen.Networking.APIServerAddress
+	}
+	// the networking flags take precedence over the custom config
+	if gen.Networking.APIServerPort != 0 {
+		cfg.Networking.APIServerPort = gen.Networking.APIServerPort
+	}
+	if gen.Networking.IPFamily != "" {
+		cfg.Networking.IPFamily = gen.Networking.IPFamily
+	}
+
+	cfg.ContainerdConfigPatches = append(gen.ContainerdConfigPatches, cfg.ContainerdConfigPatches...)
+
+	// the generated config maps the ingress port, and the api port if requested
+	reserved := map[int]bool{port: true}
+	for _, pm := range gen.Nodes[0].ExtraPortMappings {
+		reserved[int(pm.HostPort)] = true
+	}
+	for _, node := range cfg.Nodes {
+		for _, pm := range node.ExtraPortMappings {
+			if reserved[int(pm.HostPort)] {
+				return "", fmt.Errorf("the extraPortMappings hostPort %d is reserved for the ingress", pm.HostPort)
+			}
+		}
+	}
+
+	controlPlane := gen.Nodes[0]
+	nodes := []v1alpha4.Node{controlPlane}
+	merged := false
+	for _, node := range cfg.Nodes {
+		if merged || nodeRole(node) != v1alpha4.ControlPlaneRole {
+			nodes = append(nodes, node)
+			continue
+		}
+
+		nodes[0] = v1alpha4.Node{
+			Role:                         v1alpha4.ControlPlaneRole,
+			Image:                        node.Image,
+			Labels:                       node.Labels,
+			ExtraMounts:                  append(controlPlane.ExtraMounts, node.ExtraMounts...),
+			ExtraPortMappings:            append(controlPlane.ExtraPortMappings, node.ExtraPortMappings...),
+			KubeadmConfigPatches:         append(controlPlane.KubeadmConfigPatches, node.KubeadmConfigPatches...),
+			KubeadmConfigPatchesJSON6902: node.KubeadmConfigPatchesJSON6902,
+		}
+		merged = true
+	}
+	// nodes without an image use the same image as a cluster created without a custom config
+	for i := range nodes {
+		if nodes[i].Image == "" {
+			nodes[i].Image = defaultNodeImage
+		}
+	}
+	cfg.Nodes = nodes
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("could not encode kind config: %w", err)
+	}
+
+	return string(data), nil
+}
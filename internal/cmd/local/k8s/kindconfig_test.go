@@ -0,0 +1,241 @@
+package k8s
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeKindConfig(t *testing.T) {
+	generated := kindConfig(8000, "/home/airbyte/.airbyte/abctl/data", "", CreateOpts{})
+	custom := `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+networking:
+  podSubnet: 10.244.0.0/16
+featureGates:
+  InPlacePodVerticalScaling: true
+nodes:
+  - role: control-plane
+    labels:
+      gpu: "true"
+    extraMounts:
+      - hostPath: /data/datasets
+        containerPath: /datasets
+    extraPortMappings:
+      - containerPort: 5432
+        hostPort: 5432
+  - role: worker
+    image: kindest/node:v1.29.2
+`
+
+	merged, err := mergeKindConfig(generated, []byte(custom), 8000)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+    - role: control-plane
+      image: kindest/node:v1.29.1
+      labels:
+        gpu: "true"
+      extraMounts:
+        - containerPath: /var/local-path-provisioner
+          hostPath: /home/airbyte/.airbyte/abctl/data
+        - containerPath: /datasets
+          hostPath: /data/datasets
+      extraPortMappings:
+        - containerPort: 80
+          hostPort: 8000
+          protocol: TCP
+        - containerPort: 5432
+          hostPort: 5432
+      kubeadmConfigPatches:
+        - |
+          kind: InitConfiguration
+          nodeRegistration:
+            kubeletExtraArgs:
+              node-labels: "ingress-ready=true"
+    - role: worker
+      image: kindest/node:v1.29.2
+networking:
+    podSubnet: 10.244.0.0/16
+featureGates:
+    InPlacePodVerticalScaling: true
+`
+	if d := cmp.Diff(exp, merged); d != "" {
+		t.Error("merged config mismatch (-want +got):", d)
+	}
+}
+
+func TestMergeKindConfig_WorkersOnly(t *testing.T) {
+	generated := kindConfig(8000, "", "remote.example.com", CreateOpts{})
+	custom := `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+  - role: worker
+`
+
+	merged, err := mergeKindConfig(generated, []byte(custom), 8000)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cfg, err := decodeKindConfig([]byte(merged))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	var roles []string
+	for _, node := range cfg.Nodes {
+		roles = append(roles, string(node.Role))
+	}
+	if d := cmp.Diff([]string{"control-plane", "worker"}, roles); d != "" {
+		t.Error("roles mismatch (-want +got):", d)
+	}
+	// the remote host networking must be retained
+	if d := cmp.Diff("0.0.0.0", cfg.Networking.APIServerAddress); d != "" {
+		t.Error("apiServerAddress mismatch (-want +got):", d)
+	}
+}
+
+func TestMergeKindConfig_Networking(t *testing.T) {
+	generated := kindConfig(8000, "", "", CreateOpts{APIServerPort: 6443, IPFamily: IPFamilyIPv6})
+	custom := `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+networking:
+  apiServerPort: 7443
+  podSubnet: fd00:10:244::/56
+`
+
+	merged, err := mergeKindConfig(generated, []byte(custom), 8000)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	cfg, err := decodeKindConfig([]byte(merged))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// the generated networking takes precedence, any other custom networking is retained
+	if d := cmp.Diff(int32(6443), cfg.Networking.APIServerPort); d != "" {
+		t.Error("apiServerPort mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff(IPFamilyIPv6, string(cfg.Networking.IPFamily)); d != "" {
+		t.Error("ipFamily mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff("fd00:10:244::/56", cfg.Networking.PodSubnet); d != "" {
+		t.Error("podSubnet mismatch (-want +got):", d)
+	}
+}
+
+func TestMergeKindConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteHost string
+		custom     string
+		errMsg     string
+	}{
+		{
+			name:   "host port",
+			custom: "nodes:\n  - role: worker\n    extraPortMappings:\n      - containerPort: 8080\n        hostPort: 8000\n",
+			errMsg: "hostPort 8000 is reserved",
+		},
+		{
+			name:       "remote api server address",
+			remoteHost: "remote.example.com",
+			custom:     "networking:\n  apiServerAddress: 127.0.0.1\n",
+			errMsg:     "apiServerAddress must be 0.0.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataDir := "/data"
+			if tt.remoteHost != "" {
+				dataDir = ""
+			}
+			generated := kindConfig(8000, dataDir, tt.remoteHost, CreateOpts{})
+			custom := "kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\n" + tt.custom
+
+			_, err := mergeKindConfig(generated, []byte(custom), 8000)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error to contain '%s', received '%s'", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestValidateKindConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		custom string
+		errMsg string
+	}{
+		{
+			name:   "kind",
+			custom: "kind: Pod\napiVersion: v1\n",
+			errMsg: "unsupported kind Pod",
+		},
+		{
+			name:   "unknown field",
+			custom: "kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\nnode: []\n",
+			errMsg: "could not decode kind config",
+		},
+		{
+			name:   "reserved mount",
+			custom: "kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\nnodes:\n  - extraMounts:\n      - hostPath: /data\n        containerPath: /var/local-path-provisioner\n",
+			errMsg: "reserved for the persisted data",
+		},
+		{
+			name:   "relative mount",
+			custom: "kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\nnodes:\n  - extraMounts:\n      - hostPath: /data\n        containerPath: data\n",
+			errMsg: "must be absolute",
+		},
+		{
+			name:   "ingress port",
+			custom: "kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\nnodes:\n  - extraPortMappings:\n      - containerPort: 80\n        hostPort: 9000\n",
+			errMsg: "reserved for the ingress",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateKindConfig([]byte(tt.custom))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error to contain '%s', received '%s'", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestReadSaveKindConfig(t *testing.T) {
+	custom := []byte("kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\n")
+	path := filepath.Join(t.TempDir(), "abctl", "kind.yaml")
+
+	if err := SaveKindConfig(path, custom); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	data, err := ReadKindConfig(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(custom, data); d != "" {
+		t.Error("kind config mismatch (-want +got):", d)
+	}
+
+	if err := os.WriteFile(path, []byte("kind: Pod\n"), 0644); err != nil {
+		t.Fatal("could not write kind config", err)
+	}
+	if _, err := ReadKindConfig(path); err == nil {
+		t.Error("expected an error reading an invalid kind config")
+	}
+}
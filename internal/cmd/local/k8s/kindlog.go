@@ -0,0 +1,194 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/logging"
+	"regexp"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+	"strings"
+	"sync"
+)
+
+// maxKindLogLines is the number of the most recent messages kept by the kindLogger.
+const maxKindLogLines = 500
+
+// interface sanity check
+var _ log.Logger = (*kindLogger)(nil)
+
+// kindLogger is a kind logger recording the messages kind logs while creating a cluster, which are otherwise
+// discarded, so the cause of a failure can be shown. Every message is logged at trace level as well.
+type kindLogger struct {
+	lock  sync.Mutex
+	lines []string
+}
+
+func (l *kindLogger) record(msg string) {
+	logging.Tracef("kind: %s", msg)
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.lines = append(l.lines, strings.Split(strings.TrimRight(msg, "\n"), "\n")...)
+	if len(l.lines) > maxKindLogLines {
+		l.lines = l.lines[len(l.lines)-maxKindLogLines:]
+	}
+}
+
+func (l *kindLogger) Warn(message string) {
+	l.record("WARNING: " + message)
+}
+
+func (l *kindLogger) Warnf(format string, args ...interface{}) {
+	l.Warn(fmt.Sprintf(format, args...))
+}
+
+func (l *kindLogger) Error(message string) {
+	l.record("ERROR: " + message)
+}
+
+func (l *kindLogger) Errorf(format string, args ...interface{}) {
+	l.Error(fmt.Sprintf(format, args...))
+}
+
+func (l *kindLogger) V(log.Level) log.InfoLogger {
+	return kindInfoLogger{l}
+}
+
+// String returns the recorded messages, one per line.
+func (l *kindLogger) String() string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return strings.Join(l.lines, "\n")
+}
+
+// kindInfoLogger records the info messages of every verbosity level, as the detailed messages are the ones which
+// explain a failure.
+type kindInfoLogger struct {
+	l *kindLogger
+}
+
+func (i kindInfoLogger) Info(message string) {
+	i.l.record(message)
+}
+
+func (i kindInfoLogger) Infof(format string, args ...interface{}) {
+	i.l.record(fmt.Sprintf(format, args...))
+}
+
+func (i kindInfoLogger) Enabled() bool {
+	return true
+}
+
+// CreateError is returned by Cluster.Create if kind failed to create the cluster.
+type CreateError struct {
+	err error
+	// Log is the output kind logged while creating the cluster, followed by the output of the command which failed,
+	// if any.
+	Log string
+}
+
+func (e *CreateError) Error() string {
+	return e.err.Error()
+}
+
+func (e *CreateError) Unwrap() error {
+	return e.err
+}
+
+// newCreateError returns the CreateError of the err returned by kind, with the messages recorded by the logger.
+func newCreateError(err error, logger *kindLogger) *CreateError {
+	var output []string
+	if logger != nil {
+		if s := logger.String(); s != "" {
+			output = append(output, s)
+		}
+	}
+	// kind only includes the command which failed in the error, not its output
+	var runErr *exec.RunError
+	if errors.As(err, &runErr) && len(runErr.Output) > 0 {
+		output = append(output, strings.TrimRight(string(runErr.Output), "\n"))
+	}
+	return &CreateError{err: fmt.Errorf("unable to create kind cluster: %w", err), Log: strings.Join(output, "\n")}
+}
+
+// Diagnosis is the likely cause of a failure to create a cluster, identified from its log.
+type Diagnosis struct {
+	// Lines are the last lines of the log relevant to the failure.
+	Lines []string
+	// Hints describe how to resolve the identified causes.
+	Hints []string
+}
+
+// createFailure is a known cause of a failure to create a cluster, identified by a line of the log.
+type createFailure struct {
+	re   *regexp.Regexp
+	hint string
+}
+
+var createFailures = []createFailure{
+	{
+		re:   regexp.MustCompile(`(?i)port is already allocated|address already in use|bind for .* failed|cannot assign requested address`),
+		hint: "A port of the cluster is already in use by another application or cluster. Stop it, or choose other ports with --port (and --kind-api-port, if set).",
+	},
+	{
+		re:   regexp.MustCompile(`(?i)cgroup`),
+		hint: "The kind node could not set up its cgroups. Ensure Docker uses cgroup v2 (or cgroupns=private on cgroup v1 hosts), and on WSL2 or rootless Docker follow https://kind.sigs.k8s.io/docs/user/known-issues/.",
+	},
+	{
+		re:   regexp.MustCompile(`(?i)too many open files|inotify|fs\.inotify`),
+		hint: "The host ran out of inotify watches. Increase them, e.g. sysctl fs.inotify.max_user_watches=524288 and sysctl fs.inotify.max_user_instances=512.",
+	},
+	{
+		re:   regexp.MustCompile(`(?i)no space left on device`),
+		hint: "The Docker host ran out of disk space. Free some, e.g. with docker system prune.",
+	},
+	{
+		re:   regexp.MustCompile(`(?i)cannot connect to the docker daemon|docker daemon is not running|permission denied while trying to connect to the docker`),
+		hint: "Docker is not running, or is not accessible by this user. Start Docker, or add the user to the docker group.",
+	},
+	{
+		re:   regexp.MustCompile(`(?i)pull access denied|failed to pull image|error pulling image|tls handshake timeout|x509: certificate|i/o timeout`),
+		hint: "The kind node image could not be pulled. Check the network and proxy settings of Docker, or pull through a mirror with --registry-mirror, trusting its CA with --ca-cert.",
+	},
+	{
+		re:   regexp.MustCompile(`(?i)could not find a log line that matches|timed out waiting for|context deadline exceeded`),
+		hint: "The kind node did not start in time, which usually means Docker lacks CPU or memory. Allocate more resources to Docker, or see the lines above for the cause.",
+	},
+}
+
+// maxDiagnosisLines is the most lines of the log included in a Diagnosis.
+const maxDiagnosisLines = 10
+
+// DiagnoseCreate returns the Diagnosis of the log of a CreateError. The lines of the log matching a known cause
+// are returned with the hints of their causes, or, if there are none, the last lines of the log without any hints.
+func DiagnoseCreate(log string) Diagnosis {
+	var d Diagnosis
+	var lines []string
+	matched := make([]bool, len(createFailures))
+	for _, line := range strings.Split(log, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+		for i, f := range createFailures {
+			if f.re.MatchString(line) {
+				if len(d.Lines) == 0 || d.Lines[len(d.Lines)-1] != line {
+					d.Lines = append(d.Lines, line)
+				}
+				if !matched[i] {
+					matched[i] = true
+					d.Hints = append(d.Hints, f.hint)
+				}
+			}
+		}
+	}
+
+	if len(d.Lines) == 0 {
+		d.Lines = lines
+	}
+	if len(d.Lines) > maxDiagnosisLines {
+		d.Lines = d.Lines[len(d.Lines)-maxDiagnosisLines:]
+	}
+	return d
+}
@@ -0,0 +1,93 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kind/pkg/exec"
+	"strings"
+	"testing"
+)
+
+func TestKindLogger(t *testing.T) {
+	l := &kindLogger{}
+	l.V(0).Info("Creating cluster \"airbyte-abctl\" ...")
+	l.Warnf("port %d is in use", 8000)
+	l.Error("multi\nline")
+	for i := 0; i < maxKindLogLines; i++ {
+		l.V(3).Infof("line %d", i)
+	}
+
+	lines := strings.Split(l.String(), "\n")
+	if d := cmp.Diff(maxKindLogLines, len(lines)); d != "" {
+		t.Error("lines mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff(fmt.Sprintf("line %d", maxKindLogLines-1), lines[len(lines)-1]); d != "" {
+		t.Error("last line mismatch (-want +got):", d)
+	}
+}
+
+func TestNewCreateError(t *testing.T) {
+	l := &kindLogger{}
+	l.V(0).Info("Ensuring node image (kindest/node:v1.29.1)")
+
+	runErr := &exec.RunError{
+		Command: []string{"docker", "run"},
+		Output:  []byte("docker: Error response from daemon: Bind for 0.0.0.0:8000 failed: port is already allocated.\n"),
+		Inner:   errors.New("exit status 125"),
+	}
+	err := newCreateError(fmt.Errorf("failed to create cluster: %w", runErr), l)
+
+	if !errors.Is(err, runErr) {
+		t.Error("expected the error to wrap the kind error")
+	}
+	exp := "Ensuring node image (kindest/node:v1.29.1)\ndocker: Error response from daemon: Bind for 0.0.0.0:8000 failed: port is already allocated."
+	if d := cmp.Diff(exp, err.Log); d != "" {
+		t.Error("log mismatch (-want +got):", d)
+	}
+}
+
+func TestDiagnoseCreate(t *testing.T) {
+	tests := []struct {
+		name      string
+		log       string
+		expLines  []string
+		expHints  int
+		expPrefix string
+	}{
+		{
+			name:      "port",
+			log:       "Creating cluster\nERROR: docker: Bind for 0.0.0.0:8000 failed: port is already allocated.\n",
+			expLines:  []string{"ERROR: docker: Bind for 0.0.0.0:8000 failed: port is already allocated."},
+			expHints:  1,
+			expPrefix: "A port of the cluster",
+		},
+		{
+			name:      "cgroup",
+			log:       "Starting control-plane\nfailed to create cgroup: permission denied\ncould not find a log line that matches \"Reached target .*Multi-User System.*|detected cgroup v1\"",
+			expLines:  []string{"failed to create cgroup: permission denied", "could not find a log line that matches \"Reached target .*Multi-User System.*|detected cgroup v1\""},
+			expHints:  2,
+			expPrefix: "The kind node could not set up its cgroups",
+		},
+		{
+			name:     "unknown",
+			log:      "one\n\ntwo\nthree",
+			expLines: []string{"one", "two", "three"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := DiagnoseCreate(tt.log)
+			if diff := cmp.Diff(tt.expLines, d.Lines); diff != "" {
+				t.Error("lines mismatch (-want +got):", diff)
+			}
+			if diff := cmp.Diff(tt.expHints, len(d.Hints)); diff != "" {
+				t.Fatal("hints mismatch (-want +got):", diff)
+			}
+			if tt.expPrefix != "" && !strings.HasPrefix(d.Hints[0], tt.expPrefix) {
+				t.Errorf("expected hint with prefix %q, got %q", tt.expPrefix, d.Hints[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// FieldManager is the field manager of the objects abctl applies with server-side apply.
+const FieldManager = "abctl"
+
+// resource returns the dynamic resource of the obj, within its namespace if the resource is namespaced.
+// The namespace of a namespaced obj defaults to the namespace, the namespace of a cluster-scoped obj is removed.
+// The api group resources are discovered on every call, so a resource whose custom resource definition was only just
+// applied is found.
+func (d *DefaultK8sClient) resource(namespace string, obj *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	groupResources, err := restmapper.GetAPIGroupResources(d.ClientSet.Discovery())
+	if err != nil {
+		return nil, fmt.Errorf("could not discover api resources: %w", err)
+	}
+
+	gvk := obj.GroupVersionKind()
+	mapping, err := restmapper.NewDiscoveryRESTMapper(groupResources).RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("could not find the resource of %s: %w", gvk, err)
+	}
+
+	client, err := dynamic.NewForConfig(d.RestConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		obj.SetNamespace("")
+		return client.Resource(mapping.Resource), nil
+	}
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(namespace)
+	}
+	return client.Resource(mapping.Resource).Namespace(obj.GetNamespace()), nil
+}
+
+func (d *DefaultK8sClient) ManifestApply(ctx context.Context, namespace string, obj *unstructured.Unstructured) error {
+	res, err := d.resource(namespace, obj)
+	if err != nil {
+		return err
+	}
+
+	_, err = res.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{FieldManager: FieldManager, Force: true})
+	return err
+}
+
+func (d *DefaultK8sClient) ManifestDelete(ctx context.Context, obj *unstructured.Unstructured) error {
+	res, err := d.resource("", obj)
+	if err != nil {
+		return err
+	}
+
+	return res.Delete(ctx, obj.GetName(), metav1.DeleteOptions{})
+}
@@ -0,0 +1,152 @@
+package k8s
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Mount is an additional directory on the docker host which is mounted into the kind node.
+type Mount struct {
+	// HostPath is the directory on the docker host.
+	HostPath string
+	// ContainerPath is the path within the kind node where the HostPath is mounted.
+	ContainerPath string
+	// ReadOnly, if true, mounts the HostPath as read-only.
+	ReadOnly bool
+}
+
+// ParseMount parses a mount in the format host-path:container-path[:ro|rw], e.g. /data/datasets:/datasets:ro.
+//
+// A relative host-path is resolved against the current working directory. The container-path must be absolute, as
+// it is a path within the linux kind node. As a Windows host-path contains a colon (e.g. C:\data), the mount is
+// split on its last colon.
+func ParseMount(s string) (Mount, error) {
+	var m Mount
+
+	spec := s
+	switch {
+	case strings.HasSuffix(spec, ":ro"):
+		m.ReadOnly = true
+		spec = strings.TrimSuffix(spec, ":ro")
+	case strings.HasSuffix(spec, ":rw"):
+		spec = strings.TrimSuffix(spec, ":rw")
+	}
+
+	i := strings.LastIndex(spec, ":")
+	if i <= 0 || i == len(spec)-1 {
+		return Mount{}, fmt.Errorf("invalid mount %s, expected the format host-path:container-path[:ro|rw]", s)
+	}
+
+	m.ContainerPath = spec[i+1:]
+	if !strings.HasPrefix(m.ContainerPath, "/") {
+		return Mount{}, fmt.Errorf("invalid mount %s, the container path %s must be absolute", s, m.ContainerPath)
+	}
+
+	hostPath, err := filepath.Abs(spec[:i])
+	if err != nil {
+		return Mount{}, fmt.Errorf("invalid mount %s, could not determine the absolute host path: %w", s, err)
+	}
+	m.HostPath = hostPath
+
+	return m, nil
+}
+
+// ParseMounts parses every mount, see ParseMount.
+func ParseMounts(mounts []string) ([]Mount, error) {
+	parsed := make([]Mount, len(mounts))
+	for i, s := range mounts {
+		m, err := ParseMount(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = m
+	}
+	return parsed, nil
+}
+
+// Volume types of a VolumeMount.
+const (
+	VolumeHostPath  = "hostpath"
+	VolumeSecret    = "secret"
+	VolumeConfigMap = "configmap"
+	VolumeEmptyDir  = "emptydir"
+)
+
+// VolumeMount is a volume mounted into the containers of a component of Airbyte, e.g. a Secret containing a custom
+// CA bundle mounted into the worker.
+type VolumeMount struct {
+	// Component is the component of Airbyte the volume is mounted into, e.g. worker.
+	Component string
+	// Type is the type of the volume, one of VolumeHostPath, VolumeSecret, VolumeConfigMap, or VolumeEmptyDir.
+	Type string
+	// Source is the name of the existing Secret or ConfigMap, or the path within the kind node of a hostpath volume
+	// (e.g. the container-path of a Mount). It is empty for an emptydir volume.
+	Source string
+	// MountPath is the path within the containers where the volume is mounted.
+	MountPath string
+	// ReadOnly, if true, mounts the volume as read-only.
+	ReadOnly bool
+}
+
+// ParseVolumeMount parses a volume mount in the format component:type[:source]:mount-path[:ro|rw], e.g.
+// worker:secret:ca-bundle:/etc/ssl/custom:ro, or server:emptydir:/scratch, as an emptydir volume has no source.
+func ParseVolumeMount(s string) (VolumeMount, error) {
+	var m VolumeMount
+
+	spec := s
+	switch {
+	case strings.HasSuffix(spec, ":ro"):
+		m.ReadOnly = true
+		spec = strings.TrimSuffix(spec, ":ro")
+	case strings.HasSuffix(spec, ":rw"):
+		spec = strings.TrimSuffix(spec, ":rw")
+	}
+
+	parts := strings.Split(spec, ":")
+	if len(parts) < 3 {
+		return VolumeMount{}, fmt.Errorf("invalid volume mount %s, expected the format component:type[:source]:mount-path[:ro|rw]", s)
+	}
+	m.Component, m.Type = parts[0], strings.ToLower(parts[1])
+
+	switch m.Type {
+	case VolumeEmptyDir:
+		if len(parts) != 3 {
+			return VolumeMount{}, fmt.Errorf("invalid volume mount %s, an %s volume has no source", s, VolumeEmptyDir)
+		}
+		m.MountPath = parts[2]
+	case VolumeHostPath, VolumeSecret, VolumeConfigMap:
+		if len(parts) != 4 || parts[2] == "" {
+			return VolumeMount{}, fmt.Errorf("invalid volume mount %s, a %s volume requires a source", s, m.Type)
+		}
+		m.Source, m.MountPath = parts[2], parts[3]
+	default:
+		return VolumeMount{}, fmt.Errorf("invalid volume mount %s, the type %s must be one of %s, %s, %s, or %s",
+			s, parts[1], VolumeHostPath, VolumeSecret, VolumeConfigMap, VolumeEmptyDir)
+	}
+
+	if m.Component == "" {
+		return VolumeMount{}, fmt.Errorf("invalid volume mount %s, the component is required", s)
+	}
+	if m.Type == VolumeHostPath && !strings.HasPrefix(m.Source, "/") {
+		return VolumeMount{}, fmt.Errorf("invalid volume mount %s, the host path %s must be absolute", s, m.Source)
+	}
+	if !strings.HasPrefix(m.MountPath, "/") {
+		return VolumeMount{}, fmt.Errorf("invalid volume mount %s, the mount path %s must be absolute", s, m.MountPath)
+	}
+
+	return m, nil
+}
+
+// ParseVolumeMounts parses every volume mount, see ParseVolumeMount.
+func ParseVolumeMounts(mounts []string) ([]VolumeMount, error) {
+	parsed := make([]VolumeMount, len(mounts))
+	for i, s := range mounts {
+		m, err := ParseVolumeMount(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = m
+	}
+	return parsed, nil
+}
@@ -0,0 +1,94 @@
+package k8s
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseMount(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("could not determine working directory", err)
+	}
+
+	tests := []struct {
+		input string
+		exp   Mount
+	}{
+		{input: "/data:/datasets", exp: Mount{HostPath: "/data", ContainerPath: "/datasets"}},
+		{input: "/data:/datasets:ro", exp: Mount{HostPath: "/data", ContainerPath: "/datasets", ReadOnly: true}},
+		{input: "/data:/datasets:rw", exp: Mount{HostPath: "/data", ContainerPath: "/datasets"}},
+		{input: "data:/datasets", exp: Mount{HostPath: filepath.Join(wd, "data"), ContainerPath: "/datasets"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			m, err := ParseMount(tt.input)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, m); d != "" {
+				t.Error("mount mismatch (-want +got):", d)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "/data", "/data:", ":/datasets", "/data:datasets", "/data:ro"} {
+		if _, err := ParseMount(input); err == nil {
+			t.Errorf("expected an error for '%s'", input)
+		}
+	}
+}
+
+func TestParseVolumeMount(t *testing.T) {
+	tests := []struct {
+		input string
+		exp   VolumeMount
+	}{
+		{
+			input: "worker:secret:ca-bundle:/etc/ssl/custom:ro",
+			exp:   VolumeMount{Component: "worker", Type: VolumeSecret, Source: "ca-bundle", MountPath: "/etc/ssl/custom", ReadOnly: true},
+		},
+		{
+			input: "server:ConfigMap:settings:/settings",
+			exp:   VolumeMount{Component: "server", Type: VolumeConfigMap, Source: "settings", MountPath: "/settings"},
+		},
+		{
+			input: "worker:emptydir:/scratch:rw",
+			exp:   VolumeMount{Component: "worker", Type: VolumeEmptyDir, MountPath: "/scratch"},
+		},
+		{
+			input: "workload-launcher:hostpath:/datasets:/datasets:ro",
+			exp:   VolumeMount{Component: "workload-launcher", Type: VolumeHostPath, Source: "/datasets", MountPath: "/datasets", ReadOnly: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			m, err := ParseVolumeMount(tt.input)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, m); d != "" {
+				t.Error("volume mount mismatch (-want +got):", d)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"",
+		"worker:secret",
+		"worker:secret:/certs",
+		"worker:emptydir:scratch:/scratch",
+		"worker:hostpath:datasets:/datasets",
+		"worker:nfs:server:/data",
+		"worker:configmap:settings:settings",
+		":secret:ca-bundle:/certs",
+	} {
+		if _, err := ParseVolumeMount(input); err == nil {
+			t.Errorf("expected an error for '%s'", input)
+		}
+	}
+}
@@ -2,14 +2,17 @@ package k8s
 
 import (
 	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/docker"
+	"k8s.io/client-go/tools/clientcmd"
 	"os"
 	"path/filepath"
 	"sigs.k8s.io/kind/pkg/cluster"
 )
 
 const (
-	Kind = "kind"
-	Test = "test"
+	Existing = "existing"
+	Kind     = "kind"
+	Test     = "test"
 )
 
 // Provider represents a k8s provider.
@@ -24,13 +27,27 @@ type Provider struct {
 	Kubeconfig string
 	// HelmNginx additional helm values to pass to the nginx chart
 	HelmNginx []string
+	// HelmTraefik additional helm values to pass to the traefik chart
+	HelmTraefik []string
+	// DefaultStorageClass, if true, relies on the default StorageClass of the cluster to provision the persistent
+	// volumes instead of creating hostPath persistent volumes.
+	DefaultStorageClass bool
+}
+
+// KubeconfigPath returns the full path of the Kubeconfig.
+// The Kubeconfig is relative to the userHome, unless it is an absolute path.
+func (p Provider) KubeconfigPath(userHome string) string {
+	if filepath.IsAbs(p.Kubeconfig) {
+		return p.Kubeconfig
+	}
+	return filepath.Join(userHome, p.Kubeconfig)
 }
 
 // mkDirs creates the directories for this providers kubeconfig.
 // The kubeconfigs are always scoped to the user's home directory.
 func (p Provider) mkDirs(userHome string) error {
 	const permissions = 0700
-	kubeconfig := filepath.Join(userHome, p.Kubeconfig)
+	kubeconfig := p.KubeconfigPath(userHome)
 	if err := os.MkdirAll(filepath.Dir(kubeconfig), permissions); err != nil {
 		return fmt.Errorf("could not create directory %s: %v", kubeconfig, err)
 	}
@@ -45,14 +62,54 @@ func (p Provider) Cluster() (Cluster, error) {
 		return nil, fmt.Errorf("could not determine user home directory: %w", err)
 	}
 
+	if p.Name == Existing {
+		return &existingCluster{kubeconfig: p.KubeconfigPath(home), context: p.Context}, nil
+	}
+
 	if err := p.mkDirs(home); err != nil {
 		return nil, fmt.Errorf("could not create directory %s: %w", home, err)
 	}
 
+	kindLog := &kindLogger{}
 	return &kindCluster{
-		p:           cluster.NewProvider(),
-		kubeconfig:  filepath.Join(home, p.Kubeconfig),
+		p:           cluster.NewProvider(cluster.ProviderWithLogger(kindLog)),
+		kubeconfig:  p.KubeconfigPath(home),
 		clusterName: p.ClusterName,
+		// kind communicates with docker via the docker cli, which uses the DOCKER_HOST
+		remoteHost: docker.RemoteHost(os.Getenv("DOCKER_HOST")),
+		log:        kindLog,
+	}, nil
+}
+
+// ExistingProvider returns a Provider for an existing cluster (e.g. k3s, minikube), accessed via the kubeconfig
+// and context. If the context is empty, the current context of the kubeconfig is used.
+//
+// The cluster is never created nor deleted by abctl, and its default StorageClass is used for persistent volumes.
+func ExistingProvider(kubeconfig, context string) (Provider, error) {
+	kubeconfig, err := filepath.Abs(kubeconfig)
+	if err != nil {
+		return Provider{}, fmt.Errorf("could not determine path of kubeconfig %s: %w", kubeconfig, err)
+	}
+
+	if context == "" {
+		cfg, err := clientcmd.LoadFromFile(kubeconfig)
+		if err != nil {
+			return Provider{}, fmt.Errorf("could not load kubeconfig %s: %w", kubeconfig, err)
+		}
+		if cfg.CurrentContext == "" {
+			return Provider{}, fmt.Errorf("kubeconfig %s has no current context, a context must be provided", kubeconfig)
+		}
+		context = cfg.CurrentContext
+	}
+
+	return Provider{
+		Name:                Existing,
+		ClusterName:         context,
+		Context:             context,
+		Kubeconfig:          kubeconfig,
+		HelmNginx:           []string{},
+		HelmTraefik:         []string{},
+		DefaultStorageClass: true,
 	}, nil
 }
 
@@ -68,6 +125,10 @@ var (
 			"controller.service.httpsPort.enable=false",
 			"controller.service.type=NodePort",
 		},
+		HelmTraefik: []string{
+			"ports.web.hostPort=80",
+			"service.type=NodePort",
+		},
 	}
 
 	// TestProvider represents a test provider, for testing purposes
@@ -77,5 +138,6 @@ var (
 		Context:     "test-abctl",
 		Kubeconfig:  filepath.Join(os.TempDir(), "abctl.kubeconfig"),
 		HelmNginx:   []string{},
+		HelmTraefik: []string{},
 	}
 )
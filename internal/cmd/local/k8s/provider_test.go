@@ -0,0 +1,108 @@
+package k8s
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"path/filepath"
+	"testing"
+)
+
+func TestProvider_KubeconfigPath(t *testing.T) {
+	if d := cmp.Diff(filepath.Join("/home/airbyte", ".airbyte", "abctl", "abctl.kubeconfig"), DefaultProvider.KubeconfigPath("/home/airbyte")); d != "" {
+		t.Error("relative kubeconfig mismatch (-want +got):", d)
+	}
+
+	abs := Provider{Kubeconfig: "/etc/rancher/k3s/k3s.yaml"}
+	if d := cmp.Diff("/etc/rancher/k3s/k3s.yaml", abs.KubeconfigPath("/home/airbyte")); d != "" {
+		t.Error("absolute kubeconfig mismatch (-want +got):", d)
+	}
+}
+
+// writeKubeconfig writes a kubeconfig containing the contexts, with the current context, returning its path.
+func writeKubeconfig(t *testing.T, current string, contexts ...string) string {
+	cfg := clientcmdapi.NewConfig()
+	cfg.CurrentContext = current
+	for _, c := range contexts {
+		cfg.Clusters[c] = &clientcmdapi.Cluster{Server: "https://127.0.0.1:6443"}
+		cfg.Contexts[c] = &clientcmdapi.Context{Cluster: c}
+	}
+
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := clientcmd.WriteToFile(*cfg, path); err != nil {
+		t.Fatal("could not write kubeconfig", err)
+	}
+	return path
+}
+
+func TestExistingProvider(t *testing.T) {
+	kubeconfig := writeKubeconfig(t, "k3s", "k3s", "minikube")
+
+	tests := []struct {
+		name    string
+		context string
+		exp     string
+	}{
+		{name: "current context", exp: "k3s"},
+		{name: "explicit context", context: "minikube", exp: "minikube"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ExistingProvider(kubeconfig, tt.context)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			exp := Provider{
+				Name:                Existing,
+				ClusterName:         tt.exp,
+				Context:             tt.exp,
+				Kubeconfig:          kubeconfig,
+				HelmNginx:           []string{},
+				HelmTraefik:         []string{},
+				DefaultStorageClass: true,
+			}
+			if d := cmp.Diff(exp, p); d != "" {
+				t.Error("provider mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestExistingProvider_Errors(t *testing.T) {
+	if _, err := ExistingProvider(filepath.Join(t.TempDir(), "dne"), ""); err == nil {
+		t.Error("expected an error for a missing kubeconfig")
+	}
+	if _, err := ExistingProvider(writeKubeconfig(t, "", "k3s"), ""); err == nil {
+		t.Error("expected an error for a kubeconfig without a current context")
+	}
+}
+
+func TestExistingCluster(t *testing.T) {
+	kubeconfig := writeKubeconfig(t, "k3s", "k3s")
+
+	p, err := ExistingProvider(kubeconfig, "k3s")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	cluster, err := p.Cluster()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if !cluster.Exists() {
+		t.Error("expected cluster to exist")
+	}
+	// an existing cluster must never be created or deleted
+	if err := cluster.Create(8000, CreateOpts{}); err == nil {
+		t.Error("expected an error creating an existing cluster")
+	}
+	if err := cluster.Delete(); err != nil {
+		t.Error("unexpected error deleting an existing cluster", err)
+	}
+
+	missing := existingCluster{kubeconfig: kubeconfig, context: "dne"}
+	if missing.Exists() {
+		t.Error("expected cluster to not exist")
+	}
+}
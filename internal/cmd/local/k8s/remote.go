@@ -0,0 +1,50 @@
+package k8s
+
+import (
+	"fmt"
+	"k8s.io/client-go/tools/clientcmd"
+	"net"
+	"net/url"
+)
+
+// kindContext returns the kubeconfig context kind creates for the clusterName.
+func kindContext(clusterName string) string {
+	return "kind-" + clusterName
+}
+
+// setServerHost replaces the host of the server address of the kubeconfig context with host, keeping the port.
+//
+// kind always writes the server address of the api server as seen by the docker host, which is not reachable
+// when the docker host is a remote machine.
+func setServerHost(kubeconfig, context, host string) error {
+	cfg, err := clientcmd.LoadFromFile(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("could not load kubeconfig %s: %w", kubeconfig, err)
+	}
+
+	kubeCtx, ok := cfg.Contexts[context]
+	if !ok {
+		return fmt.Errorf("context %s not found in kubeconfig %s", context, kubeconfig)
+	}
+	cluster, ok := cfg.Clusters[kubeCtx.Cluster]
+	if !ok {
+		return fmt.Errorf("cluster %s not found in kubeconfig %s", kubeCtx.Cluster, kubeconfig)
+	}
+
+	server, err := url.Parse(cluster.Server)
+	if err != nil {
+		return fmt.Errorf("could not parse server address %s: %w", cluster.Server, err)
+	}
+	if port := server.Port(); port != "" {
+		server.Host = net.JoinHostPort(host, port)
+	} else {
+		server.Host = host
+	}
+	cluster.Server = server.String()
+
+	if err := clientcmd.WriteToFile(*cfg, kubeconfig); err != nil {
+		return fmt.Errorf("could not write kubeconfig %s: %w", kubeconfig, err)
+	}
+
+	return nil
+}
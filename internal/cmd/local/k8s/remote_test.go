@@ -0,0 +1,41 @@
+package k8s
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetServerHost(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "abctl.kubeconfig")
+
+	cfg := clientcmdapi.NewConfig()
+	cfg.Clusters["kind-airbyte-abctl"] = &clientcmdapi.Cluster{Server: "https://0.0.0.0:43567"}
+	cfg.Clusters["other"] = &clientcmdapi.Cluster{Server: "https://127.0.0.1:6443"}
+	cfg.Contexts["kind-airbyte-abctl"] = &clientcmdapi.Context{Cluster: "kind-airbyte-abctl"}
+	if err := clientcmd.WriteToFile(*cfg, kubeconfig); err != nil {
+		t.Fatal("could not write kubeconfig", err)
+	}
+
+	if err := setServerHost(kubeconfig, kindContext("airbyte-abctl"), "remote.example.com"); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	updated, err := clientcmd.LoadFromFile(kubeconfig)
+	if err != nil {
+		t.Fatal("could not load kubeconfig", err)
+	}
+	if d := cmp.Diff("https://remote.example.com:43567", updated.Clusters["kind-airbyte-abctl"].Server); d != "" {
+		t.Error("server mismatch (-want +got):", d)
+	}
+	// other clusters must not be modified
+	if d := cmp.Diff("https://127.0.0.1:6443", updated.Clusters["other"].Server); d != "" {
+		t.Error("server mismatch (-want +got):", d)
+	}
+
+	if err := setServerHost(kubeconfig, "dne", "remote.example.com"); err == nil {
+		t.Error("expected an error for a missing context")
+	}
+}
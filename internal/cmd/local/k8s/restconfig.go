@@ -0,0 +1,95 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/logging"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// RestConfig returns the rest config of the context of the kubeconfig, which the kubernetes and helm clients share.
+// If the context is empty, the current context of the kubeconfig is used.
+// The error names the kubeconfig if it cannot be loaded, or if it does not contain the context.
+func RestConfig(kubeconfig, context string) (*rest.Config, error) {
+	cfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+		&clientcmd.ConfigOverrides{CurrentContext: context},
+	)
+
+	raw, err := cfg.RawConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not load kubeconfig %s: %w", kubeconfig, err)
+	}
+	if context == "" {
+		context = raw.CurrentContext
+	}
+	if context == "" {
+		return nil, fmt.Errorf("kubeconfig %s has no current context", kubeconfig)
+	}
+	if _, ok := raw.Contexts[context]; !ok {
+		return nil, fmt.Errorf("context %s does not exist in kubeconfig %s", context, kubeconfig)
+	}
+
+	restCfg, err := cfg.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not create rest config for context %s of kubeconfig %s: %w", context, kubeconfig, err)
+	}
+	logging.Debugf("using context %s of kubeconfig %s", context, kubeconfig)
+
+	return restCfg, nil
+}
+
+// NewClient returns the DefaultK8sClient of the rest config.
+func NewClient(restCfg *rest.Config) (*DefaultK8sClient, error) {
+	clientSet, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return nil, fmt.Errorf("could not create clientset: %w", err)
+	}
+
+	return &DefaultK8sClient{ClientSet: clientSet, RestConfig: restCfg}, nil
+}
+
+// Permission is a verb on a resource, which the user of the kubeconfig may or may not be allowed.
+type Permission struct {
+	Verb string
+	// Group is the api group of the resource, empty for the core group.
+	Group    string
+	Resource string
+}
+
+// String returns the permission as kubectl auth can-i expects it, e.g. create deployments.apps.
+func (p Permission) String() string {
+	if p.Group == "" {
+		return p.Verb + " " + p.Resource
+	}
+	return p.Verb + " " + p.Resource + "." + p.Group
+}
+
+func (d *DefaultK8sClient) PermissionsDenied(ctx context.Context, namespace string, permissions []Permission) ([]Permission, error) {
+	var denied []Permission
+	for _, p := range permissions {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: namespace,
+					Verb:      p.Verb,
+					Group:     p.Group,
+					Resource:  p.Resource,
+				},
+			},
+		}
+		result, err := d.ClientSet.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("could not review permission to %s: %w", p, err)
+		}
+		if !result.Status.Allowed {
+			denied = append(denied, p)
+		}
+	}
+
+	return denied, nil
+}
@@ -0,0 +1,54 @@
+package k8s
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRestConfig(t *testing.T) {
+	kubeconfig := writeKubeconfig(t, "k3s", "k3s", "minikube")
+	noCurrent := writeKubeconfig(t, "", "k3s")
+	missing := filepath.Join(t.TempDir(), "dne")
+
+	tests := []struct {
+		name       string
+		kubeconfig string
+		context    string
+		expErr     string
+	}{
+		{name: "context", kubeconfig: kubeconfig, context: "minikube"},
+		{name: "current context", kubeconfig: kubeconfig},
+		{name: "missing context", kubeconfig: kubeconfig, context: "kind", expErr: "context kind does not exist in kubeconfig " + kubeconfig},
+		{name: "no current context", kubeconfig: noCurrent, expErr: "kubeconfig " + noCurrent + " has no current context"},
+		{name: "missing kubeconfig", kubeconfig: missing, context: "k3s", expErr: "could not load kubeconfig " + missing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restCfg, err := RestConfig(tt.kubeconfig, tt.context)
+			if tt.expErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expErr) {
+					t.Errorf("expected error containing %q, got %v", tt.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff("https://127.0.0.1:6443", restCfg.Host); d != "" {
+				t.Error("host mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestPermission_String(t *testing.T) {
+	if d := cmp.Diff("create secrets", Permission{Verb: "create", Resource: "secrets"}.String()); d != "" {
+		t.Error("core permission mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff("create deployments.apps", Permission{Verb: "create", Group: "apps", Resource: "deployments"}.String()); d != "" {
+		t.Error("group permission mismatch (-want +got):", d)
+	}
+}
@@ -2,15 +2,24 @@ package local
 
 import (
 	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/airbytehq/abctl/internal/logging"
 	"github.com/airbytehq/abctl/internal/telemetry"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
 	"os"
 	"path/filepath"
+	"regexp"
 )
 
 var telClient telemetry.Client
 
+// flagNamespace is the namespace Airbyte is installed into, shared by every local command.
+var flagNamespace = local.DefaultNamespace
+
 // NewCmdLocal represents the local command.
 func NewCmdLocal(provider k8s.Provider) *cobra.Command {
 	cmd := &cobra.Command{
@@ -27,6 +36,19 @@ func NewCmdLocal(provider k8s.Provider) *cobra.Command {
 
 				telClient = telemetry.Get(telOpts...)
 			}
+			// ignore the error as it will default to an empty string if an error returns
+			dockerHost, _ = cmd.Flags().GetString("docker-host")
+
+			var err error
+			if provider, err = flagsProvider(cmd, provider); err != nil {
+				pterm.Error.Println("Unable to use the kubeconfig")
+				return err
+			}
+
+			if err := namespaceFlag(flagNamespace); err != nil {
+				return err
+			}
+
 			printProviderDetails(provider)
 
 			return nil
@@ -34,13 +56,92 @@ func NewCmdLocal(provider k8s.Provider) *cobra.Command {
 		Short: "Manages local Airbyte installations",
 	}
 
-	cmd.AddCommand(NewCmdInstall(provider), NewCmdUninstall(provider), NewCmdStatus(provider))
+	cmd.PersistentFlags().String("docker-host", "", "the docker host to connect to (e.g. unix:///var/run/docker.sock), defaults to DOCKER_HOST or the first detected docker socket")
+
+	cmd.PersistentFlags().String("kubeconfig", "", "use the existing cluster of this kubeconfig instead of creating a kind cluster (defaults to ~/.kube/config if --context is provided)")
+	cmd.PersistentFlags().String("context", "", "use the existing cluster of this kubeconfig context instead of creating a kind cluster (defaults to the current context)")
+	cmd.PersistentFlags().StringVar(&flagNamespace, "namespace", local.DefaultNamespace, "the namespace Airbyte is installed into, a different namespace (and host) allows multiple installations within the same cluster")
+
+	cmd.AddCommand(NewCmdInstall(&provider), NewCmdUninstall(&provider), NewCmdStatus(&provider), NewCmdSupportBundle(&provider), NewCmdValues(), NewCmdWait(&provider), NewCmdPause(&provider), NewCmdResume(&provider), NewCmdWatchdog(&provider), NewCmdMonitoring(&provider), NewCmdLogs(&provider), NewCmdEvents(), NewCmdHosts(&provider), NewCmdSSO(&provider), NewCmdEdition(&provider), NewCmdApply(&provider), NewCmdRepair(&provider), NewCmdDB(&provider), NewCmdStorage(&provider), NewCmdConnector(&provider), NewCmdWorkspace(&provider), NewCmdHistory(&provider), NewCmdRestart(&provider), NewCmdSecrets(&provider), NewCmdCredentials(&provider), NewCmdDoctor(&provider))
+
+	registerCompletions(cmd, provider)
 
 	return cmd
 }
 
 func printProviderDetails(p k8s.Provider) {
 	userHome, _ := os.UserHomeDir()
-	configPath := filepath.Join(userHome, p.Kubeconfig)
+	configPath := p.KubeconfigPath(userHome)
 	pterm.Info.Printfln("Using Kubernetes provider:\n  Provider: %s\n  Kubeconfig: %s\n  Context: %s", p.Name, configPath, p.Context)
 }
+
+// flagsProvider returns the provider of the existing cluster if either the --kubeconfig or --context flag is
+// provided, otherwise the provider is returned unchanged.
+func flagsProvider(cmd *cobra.Command, provider k8s.Provider) (k8s.Provider, error) {
+	// ignore the errors as they will default to an empty string if an error returns
+	kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+	kubeContext, _ := cmd.Flags().GetString("context")
+	if kubeconfig == "" && kubeContext == "" {
+		return provider, nil
+	}
+
+	if kubeconfig == "" {
+		kubeconfig = clientcmd.RecommendedHomeFile
+	}
+	existing, err := k8s.ExistingProvider(kubeconfig, kubeContext)
+	if err != nil {
+		return provider, err
+	}
+	return existing, nil
+}
+
+// namespaceFlag verifies the --namespace flag is a valid namespace.
+func namespaceFlag(namespace string) error {
+	if err := local.ValidateNamespace(namespace); err != nil {
+		pterm.Error.Printfln("Invalid --namespace '%s'", namespace)
+		return err
+	}
+	return nil
+}
+
+// stateFileChars are the characters of a kubeconfig context which are replaced within the name of its state files.
+var stateFileChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// statePath returns the path of the state file of the installation into the namespace of the provider's cluster.
+// The installation into the default namespace of the kind cluster keeps the state file of earlier versions.
+func statePath(provider k8s.Provider, namespace string) string {
+	if provider.Name == k8s.Kind && namespace == local.DefaultNamespace {
+		return paths.State
+	}
+	return filepath.Join(paths.States, stateFileChars.ReplaceAllString(provider.Context, "-")+"_"+namespace+".yaml")
+}
+
+// removeClusterStates removes the state files of every installation within the provider's cluster.
+func removeClusterStates(provider k8s.Provider) {
+	files, _ := filepath.Glob(filepath.Join(paths.States, stateFileChars.ReplaceAllString(provider.Context, "-")+"_*.yaml"))
+	if provider.Name == k8s.Kind {
+		files = append(files, paths.State)
+	}
+	for _, f := range files {
+		if err := state.Remove(f); err != nil {
+			logging.Debugf("could not remove state: %s", err)
+		}
+	}
+}
+
+// installationOption returns the local.Option defining the ingress controller, api port, and bind address recorded
+// for the installation into the namespace of the provider's cluster. If they cannot be determined, nginx without an
+// api port bound to all addresses is assumed, as it was the only ingress controller installed by earlier versions.
+func installationOption(provider k8s.Provider, namespace string) local.Option {
+	st, err := state.Load(statePath(provider, namespace))
+	if err != nil {
+		logging.Debugf("could not load state: %s", err)
+	}
+	return func(c *local.Command) {
+		local.WithIngressController(st.IngressController)(c)
+		local.WithIngressClass(st.IngressClass)(c)
+		local.WithAPIPort(st.APIPort)(c)
+		local.WithBindAddress(st.BindAddress)(c)
+		local.WithCACert(st.CACert)(c)
+	}
+}
@@ -0,0 +1,408 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/redact"
+	"github.com/pterm/pterm"
+	"gopkg.in/yaml.v3"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ApplySpec is the declarative spec of a local installation, which Apply reconciles the installation with.
+// Anything the spec does not set is left unchanged.
+type ApplySpec struct {
+	// Namespace is the namespace Airbyte was installed into, defaults to the namespace provided to LoadApplySpec.
+	Namespace string `yaml:"namespace"`
+	// Port is the ingress http port, which is only checked, as changing it requires reinstalling.
+	Port int `yaml:"port"`
+	// ChartVersion is the version of the Airbyte helm chart.
+	ChartVersion string `yaml:"chartVersion"`
+	// ValuesFile is a helm chart values file, relative to the spec.
+	ValuesFile string `yaml:"valuesFile"`
+	// Values are helm chart values, which take precedence over the values of the ValuesFile.
+	Values map[string]interface{} `yaml:"values"`
+	// Hosts are the hosts Airbyte is accessible from.
+	Hosts []string `yaml:"hosts"`
+	// Secrets are the secrets of the namespace, whose values are referenced rather than contained by the spec.
+	Secrets []SecretSpec `yaml:"secrets"`
+}
+
+// SecretSpec references the values of the keys of a secret.
+// Keys of the existing secret which are not referenced are left unchanged.
+type SecretSpec struct {
+	Name string `yaml:"name"`
+	// FromEnv maps the keys of the secret to the environment variables containing their values.
+	FromEnv map[string]string `yaml:"fromEnv"`
+	// FromFile maps the keys of the secret to the files containing their values, relative to the spec.
+	FromFile map[string]string `yaml:"fromFile"`
+}
+
+// Change is a difference between an ApplySpec and the installation.
+type Change struct {
+	// Field is the changed field of the spec, e.g. hosts, values.global.edition, or secrets.name.key.
+	Field   string
+	Current string
+	Desired string
+}
+
+// LoadApplySpec reads the spec at the path, resolving the files it references relative to it.
+// The namespace is used if the spec does not set one.
+func LoadApplySpec(path, namespace string) (ApplySpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ApplySpec{}, fmt.Errorf("could not read spec %s: %w", path, err)
+	}
+
+	var spec ApplySpec
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&spec); err != nil {
+		return ApplySpec{}, fmt.Errorf("could not parse spec %s: %w", path, err)
+	}
+
+	if spec.Namespace == "" {
+		spec.Namespace = namespace
+	}
+	if err := ValidateNamespace(spec.Namespace); err != nil {
+		return ApplySpec{}, err
+	}
+	if spec.Port < 0 || spec.Port > 65535 {
+		return ApplySpec{}, fmt.Errorf("invalid port %d", spec.Port)
+	}
+
+	dir := filepath.Dir(path)
+	if spec.ValuesFile != "" && !filepath.IsAbs(spec.ValuesFile) {
+		spec.ValuesFile = filepath.Join(dir, spec.ValuesFile)
+	}
+	for i, s := range spec.Secrets {
+		if s.Name == "" {
+			return ApplySpec{}, fmt.Errorf("secret %d has no name", i)
+		}
+		for key, file := range s.FromFile {
+			if !filepath.IsAbs(file) {
+				s.FromFile[key] = filepath.Join(dir, file)
+			}
+		}
+	}
+
+	return spec, nil
+}
+
+// values returns the values of the ValuesFile merged with the Values.
+func (s ApplySpec) values() (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if s.ValuesFile != "" {
+		data, err := os.ReadFile(s.ValuesFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read values file %s: %w", s.ValuesFile, err)
+		}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("could not parse values file %s: %w", s.ValuesFile, err)
+		}
+	}
+	mergeValues(values, s.Values)
+	return values, nil
+}
+
+// mergeValues merges src into dst, the values of src taking precedence.
+func mergeValues(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcOK := asMap(v)
+		dstMap, dstOK := asMap(dst[k])
+		if srcOK && dstOK {
+			mergeValues(dstMap, srcMap)
+			dst[k] = dstMap
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// data returns the values of the referenced keys.
+func (s SecretSpec) data(lookupEnv func(string) (string, bool)) (map[string][]byte, error) {
+	data := map[string][]byte{}
+	for key, env := range s.FromEnv {
+		value, ok := lookupEnv(env)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s of secret %s key %s is not set", env, s.Name, key)
+		}
+		data[key] = []byte(value)
+	}
+	for key, file := range s.FromFile {
+		value, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not read file of secret %s key %s: %w", s.Name, key, err)
+		}
+		data[key] = value
+	}
+	return data, nil
+}
+
+// Drift returns the differences between the spec and the installation, which Apply would reconcile.
+func (c *Command) Drift(ctx context.Context, spec ApplySpec) ([]Change, error) {
+	changes, _, err := c.drift(ctx, spec)
+	return changes, err
+}
+
+// drift returns the differences between the spec and the installation, along with the helm values of the spec,
+// whose secret references are resolved so they are compared to (and upgraded with) the values of the secrets.
+func (c *Command) drift(ctx context.Context, spec ApplySpec) ([]Change, map[string]interface{}, error) {
+	c.spinner.UpdateText("Checking for the Airbyte Helm Chart")
+	rel, err := c.helm.GetRelease(airbyteChartRelease)
+	if err != nil {
+		pterm.Error.Println("Airbyte is not installed, run 'abctl local install' to install it")
+		return nil, nil, fmt.Errorf("could not get airbyte release: %w", err)
+	}
+
+	var changes []Change
+
+	// the port of an existing cluster is not known, only the port of a kind cluster can be determined
+	if spec.Port != 0 && c.provider.Name == k8s.Kind && spec.Port != c.portHTTP {
+		changes = append(changes, Change{Field: "port", Current: fmt.Sprint(c.portHTTP), Desired: fmt.Sprint(spec.Port)})
+	}
+
+	if spec.ChartVersion != "" && spec.ChartVersion != "latest" && spec.ChartVersion != rel.Chart.Metadata.Version {
+		changes = append(changes, Change{Field: "chartVersion", Current: rel.Chart.Metadata.Version, Desired: spec.ChartVersion})
+	}
+
+	values, err := c.specValues(ctx, spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	changes = append(changes, valueChanges("values", values, rel.Config)...)
+
+	if len(spec.Hosts) > 0 {
+		c.spinner.UpdateText("Checking the hosts of the Ingress")
+		hosts, err := c.Hosts(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !sameHosts(hosts, spec.Hosts) {
+			changes = append(changes, Change{Field: "hosts", Current: strings.Join(hosts, ","), Desired: strings.Join(spec.Hosts, ",")})
+		}
+	}
+
+	for _, s := range spec.Secrets {
+		c.spinner.UpdateText(fmt.Sprintf("Checking the secret '%s'", s.Name))
+		desired, err := s.data(os.LookupEnv)
+		if err != nil {
+			return nil, nil, err
+		}
+		current, err := c.secretData(ctx, s.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, key := range sortedKeys(desired) {
+			value, ok := current[key]
+			switch {
+			case !ok:
+				changes = append(changes, Change{Field: fmt.Sprintf("secrets.%s.%s", s.Name, key), Desired: redact.Redacted})
+			case !bytes.Equal(value, desired[key]):
+				changes = append(changes, Change{Field: fmt.Sprintf("secrets.%s.%s", s.Name, key), Current: redact.Redacted, Desired: redact.Redacted})
+			}
+		}
+	}
+
+	return changes, values, nil
+}
+
+// Apply reconciles the installation with the spec, changing only what differs, and returns the changes.
+// The helm values of the installation which the spec does not set are left unchanged.
+func (c *Command) Apply(ctx context.Context, spec ApplySpec, download *DownloadOpts) ([]Change, error) {
+	changes, values, err := c.drift(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var upgrade, hosts, secrets bool
+	for _, change := range changes {
+		switch {
+		case change.Field == "port":
+			pterm.Error.Printfln("The port %s cannot be changed to %s without reinstalling", change.Current, change.Desired)
+			return changes, fmt.Errorf("port %s differs from the spec port %s", change.Current, change.Desired)
+		case change.Field == "chartVersion" || strings.HasPrefix(change.Field, "values."):
+			upgrade = true
+		case change.Field == "hosts":
+			hosts = true
+		case strings.HasPrefix(change.Field, "secrets."):
+			secrets = true
+		}
+	}
+
+	if secrets {
+		if err := c.applySecrets(ctx, spec.Secrets); err != nil {
+			return changes, err
+		}
+	}
+
+	if upgrade {
+		rel, err := c.helm.GetRelease(airbyteChartRelease)
+		if err != nil {
+			return changes, fmt.Errorf("could not get airbyte release: %w", err)
+		}
+		version := spec.ChartVersion
+		if version == "" || version == "latest" {
+			version = rel.Chart.Metadata.Version
+		}
+
+		valuesYAML, err := yaml.Marshal(values)
+		if err != nil {
+			return changes, fmt.Errorf("could not marshal values: %w", err)
+		}
+
+		if err := c.handleChart(ctx, chartRequest{
+			name:         "airbyte",
+			repoName:     airbyteRepoName,
+			repoURL:      airbyteRepoURL,
+			chartName:    airbyteChartName,
+			chartRelease: airbyteChartRelease,
+			chartVersion: version,
+			namespace:    c.namespace,
+			valuesYAML:   string(valuesYAML),
+			download:     download,
+			provenance:   true,
+			reuseValues:  true,
+		}); err != nil {
+			return changes, fmt.Errorf("could not upgrade airbyte chart: %w", err)
+		}
+	}
+
+	// the upgrade already restarted any deployment whose values changed, but not those only using a changed secret
+	if secrets && !upgrade {
+		if err := c.restartDeployments(ctx); err != nil {
+			return changes, err
+		}
+	}
+
+	if hosts {
+		if err := c.updateHosts(ctx, spec.Hosts); err != nil {
+			return changes, err
+		}
+	}
+
+	return changes, nil
+}
+
+// specValues returns the helm values of the spec, with the references to the secrets of external secret stores
+// resolved, as Install does for its values file.
+func (c *Command) specValues(ctx context.Context, spec ApplySpec) (map[string]interface{}, error) {
+	values, err := spec.values()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal values: %w", err)
+	}
+
+	resolved, err := c.secrets.ResolveYAML(ctx, string(raw))
+	if err != nil {
+		pterm.Error.Println("Unable to resolve the secrets of the values of the spec")
+		return nil, fmt.Errorf("could not resolve secrets of values: %w", err)
+	}
+	values = map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(resolved), &values); err != nil {
+		return nil, fmt.Errorf("could not parse resolved values: %w", err)
+	}
+	return values, nil
+}
+
+// secretData returns the data of the secret, which is empty if the secret does not exist.
+func (c *Command) secretData(ctx context.Context, name string) (map[string][]byte, error) {
+	secret, err := c.k8s.SecretGet(ctx, c.namespace, name)
+	if k8serrors.IsNotFound(err) {
+		return map[string][]byte{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get secret %s: %w", name, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return map[string][]byte{}, nil
+	}
+	return secret.Data, nil
+}
+
+// applySecrets sets the referenced keys of every secret, keeping the other keys of the existing secret.
+func (c *Command) applySecrets(ctx context.Context, secrets []SecretSpec) error {
+	for _, s := range secrets {
+		c.spinner.UpdateText(fmt.Sprintf("Updating the secret '%s'", s.Name))
+		desired, err := s.data(os.LookupEnv)
+		if err != nil {
+			return err
+		}
+		data, err := c.secretData(ctx, s.Name)
+		if err != nil {
+			return err
+		}
+		for key, value := range desired {
+			data[key] = value
+		}
+
+		if err := c.k8s.SecretCreateOrUpdate(ctx, c.namespace, s.Name, data); err != nil {
+			pterm.Error.Printfln("Unable to update the secret '%s'", s.Name)
+			return fmt.Errorf("could not update secret %s: %w", s.Name, err)
+		}
+		pterm.Success.Printfln("Updated the secret '%s'", s.Name)
+	}
+	return nil
+}
+
+// valueChanges returns the values of desired which differ from current, redacted.
+// Values of current which desired does not contain are not changes, as they are reused.
+func valueChanges(prefix string, desired, current map[string]interface{}) []Change {
+	keys := make([]string, 0, len(desired))
+	for k := range desired {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var changes []Change
+	for _, key := range keys {
+		field := prefix + "." + key
+		desiredMap, desiredOK := asMap(desired[key])
+		currentMap, currentOK := asMap(current[key])
+		if desiredOK && currentOK {
+			changes = append(changes, valueChanges(field, desiredMap, currentMap)...)
+			continue
+		}
+
+		// the release values are decoded from json, so every value is compared as json
+		desiredJSON, _ := json.Marshal(desired[key])
+		var currentJSON []byte
+		if v, ok := current[key]; ok {
+			currentJSON, _ = json.Marshal(v)
+		}
+		if !bytes.Equal(desiredJSON, currentJSON) {
+			changes = append(changes, redactChange(Change{Field: field, Current: string(currentJSON), Desired: string(desiredJSON)}))
+		}
+	}
+	return changes
+}
+
+// sameHosts returns true if both contain the same hosts, regardless of their order.
+func sameHosts(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	sort.Strings(a)
+	sort.Strings(b)
+	return slices.Equal(a, b)
+}
+
+// sortedKeys returns the keys of the map in order.
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,319 @@
+package local
+
+import (
+	"context"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/redact"
+	"github.com/google/go-cmp/cmp"
+	helmclient "github.com/mittwald/go-helm-client"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/repo"
+	appsv1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadApplySpec(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "abctl.yaml")
+	if err := os.WriteFile(path, []byte(`port: 9000
+chartVersion: 1.2.3
+valuesFile: values.yaml
+values:
+  worker:
+    replicas: 2
+hosts: [localhost, airbyte.lan]
+secrets:
+  - name: custom
+    fromEnv:
+      token: CUSTOM_TOKEN
+    fromFile:
+      key: secrets/key
+      abs: /etc/key
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	spec, err := LoadApplySpec(path, DefaultNamespace)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := ApplySpec{
+		Namespace:    DefaultNamespace,
+		Port:         9000,
+		ChartVersion: "1.2.3",
+		ValuesFile:   filepath.Join(dir, "values.yaml"),
+		Values:       map[string]interface{}{"worker": map[string]interface{}{"replicas": 2}},
+		Hosts:        []string{"localhost", "airbyte.lan"},
+		Secrets: []SecretSpec{{
+			Name:     "custom",
+			FromEnv:  map[string]string{"token": "CUSTOM_TOKEN"},
+			FromFile: map[string]string{"key": filepath.Join(dir, "secrets", "key"), "abs": "/etc/key"},
+		}},
+	}
+	if d := cmp.Diff(exp, spec); d != "" {
+		t.Error("spec mismatch (-want +got):", d)
+	}
+
+	for _, invalid := range []string{"prot: 9000", "namespace: Airbyte", "secrets: [{fromEnv: {token: TOKEN}}]"} {
+		if err := os.WriteFile(path, []byte(invalid), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadApplySpec(path, DefaultNamespace); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+// applyTest returns a Command for an installation of chart version 1.2.3 accessible from localhost, which records
+// what was changed.
+func applyTest(t *testing.T, provider k8s.Provider, port int, opts ...Option) (*Command, *applyRecord) {
+	var rec applyRecord
+
+	helm := mockHelmClient{
+		addOrUpdateChartRepo: func(entry repo.Entry) error {
+			return nil
+		},
+		getRelease: func(name string) (*release.Release, error) {
+			return &release.Release{
+				Name:  name,
+				Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "1.2.3"}},
+				// the release values are decoded from json
+				Config: map[string]interface{}{
+					"global": map[string]interface{}{"edition": "community", "env_vars": map[string]interface{}{"AIRBYTE_INSTALLATION_ID": "id"}},
+					"worker": map[string]interface{}{"replicas": float64(1)},
+				},
+			}, nil
+		},
+		getChart: func(name string, opts *action.ChartPathOptions) (*chart.Chart, string, error) {
+			return &chart.Chart{Metadata: &chart.Metadata{Version: opts.Version}}, "", nil
+		},
+		installOrUpgradeChart: func(ctx context.Context, s *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+			rec.upgrade = s
+			return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: s.Version}}, Name: s.ReleaseName}, nil
+		},
+	}
+
+	k8sClient := mockK8sClient{
+		serverVersionGet: func() (string, error) {
+			return "test", nil
+		},
+		ingressGet: func(ctx context.Context, namespace string, name string) (*networkingv1.Ingress, error) {
+			return ingress(namespace, []string{"localhost"}, IngressNginx, ""), nil
+		},
+		ingressExists: func(ctx context.Context, namespace string, name string) bool {
+			return name == airbyteIngress
+		},
+		ingressUpdate: func(ctx context.Context, namespace string, ing *networkingv1.Ingress) error {
+			for _, rule := range ing.Spec.Rules {
+				rec.hosts = append(rec.hosts, rule.Host)
+			}
+			return nil
+		},
+		secretGet: func(ctx context.Context, namespace, name string) (*coreV1.Secret, error) {
+			if name == "existing" {
+				return &coreV1.Secret{Data: map[string][]byte{"token": []byte("old"), "other": []byte("kept")}}, nil
+			}
+			return nil, k8serrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+		},
+		secretCreateOrUpdate: func(ctx context.Context, namespace, name string, data map[string][]byte) error {
+			if rec.secrets == nil {
+				rec.secrets = map[string]map[string][]byte{}
+			}
+			rec.secrets[name] = data
+			return nil
+		},
+		deploymentList: func(ctx context.Context, namespace string) (*appsv1.DeploymentList, error) {
+			return &appsv1.DeploymentList{Items: []appsv1.Deployment{
+				{ObjectMeta: metav1.ObjectMeta{Name: "airbyte-abctl-server"}},
+			}}, nil
+		},
+		deploymentRestart: func(ctx context.Context, namespace, name string) error {
+			rec.restarted = append(rec.restarted, name)
+			return nil
+		},
+	}
+
+	c, err := New(
+		provider,
+		append([]Option{
+			WithHelmClient(&helm),
+			WithK8sClient(&k8sClient),
+			WithTelemetryClient(&mockTelemetryClient{}),
+			WithPortHTTP(port),
+		}, opts...)...,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, &rec
+}
+
+type applyRecord struct {
+	upgrade   *helmclient.ChartSpec
+	hosts     []string
+	secrets   map[string]map[string][]byte
+	restarted []string
+}
+
+func TestCommand_Apply(t *testing.T) {
+	t.Setenv("ABCTL_TEST_TOKEN", "new")
+
+	c, rec := applyTest(t, k8s.TestProvider, 8000)
+	spec := ApplySpec{
+		Namespace: DefaultNamespace,
+		Port:      9000,
+		Values:    map[string]interface{}{"global": map[string]interface{}{"edition": "community"}, "worker": map[string]interface{}{"replicas": 1}},
+		Hosts:     []string{"localhost", "airbyte.lan"},
+		Secrets: []SecretSpec{
+			{Name: "existing", FromEnv: map[string]string{"token": "ABCTL_TEST_TOKEN"}},
+			{Name: "created", FromEnv: map[string]string{"token": "ABCTL_TEST_TOKEN"}},
+		},
+	}
+
+	changes, err := c.Apply(context.Background(), spec, nil)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// the port of an existing cluster is unknown, and the unchanged values are not changes
+	expChanges := []Change{
+		{Field: "hosts", Current: "localhost", Desired: "localhost,airbyte.lan"},
+		{Field: "secrets.existing.token", Current: redact.Redacted, Desired: redact.Redacted},
+		{Field: "secrets.created.token", Desired: redact.Redacted},
+	}
+	if d := cmp.Diff(expChanges, changes); d != "" {
+		t.Error("changes mismatch (-want +got):", d)
+	}
+
+	if rec.upgrade != nil {
+		t.Error("expected the chart to not be upgraded")
+	}
+	if d := cmp.Diff([]string{"localhost", "airbyte.lan"}, rec.hosts); d != "" {
+		t.Error("hosts mismatch (-want +got):", d)
+	}
+	expSecrets := map[string]map[string][]byte{
+		"existing": {"token": []byte("new"), "other": []byte("kept")},
+		"created":  {"token": []byte("new")},
+	}
+	if d := cmp.Diff(expSecrets, rec.secrets); d != "" {
+		t.Error("secrets mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff([]string{"airbyte-abctl-server"}, rec.restarted); d != "" {
+		t.Error("restarted mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_Apply_Values(t *testing.T) {
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(valuesFile, []byte("worker:\n  replicas: 3\nwebapp:\n  enabled: true\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, rec := applyTest(t, k8s.TestProvider, 8000)
+	spec := ApplySpec{
+		Namespace:    DefaultNamespace,
+		ChartVersion: "1.3.0",
+		ValuesFile:   valuesFile,
+		Values: map[string]interface{}{
+			"worker": map[string]interface{}{"replicas": 2},
+			"global": map[string]interface{}{"auth": map[string]interface{}{"password": "hunter2"}},
+		},
+	}
+
+	changes, err := c.Apply(context.Background(), spec, nil)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expChanges := []Change{
+		{Field: "chartVersion", Current: "1.2.3", Desired: "1.3.0"},
+		{Field: "values.global.auth", Desired: redact.Redacted},
+		{Field: "values.webapp", Desired: `{"enabled":true}`},
+		{Field: "values.worker.replicas", Current: "1", Desired: "2"},
+	}
+	if d := cmp.Diff(expChanges, changes); d != "" {
+		t.Error("changes mismatch (-want +got):", d)
+	}
+
+	if rec.upgrade == nil {
+		t.Fatal("chart was not upgraded")
+	}
+	if rec.upgrade.Version != "1.3.0" || !rec.upgrade.ReuseValues {
+		t.Errorf("unexpected chart spec: version %s, reuse values %t", rec.upgrade.Version, rec.upgrade.ReuseValues)
+	}
+	if !strings.Contains(rec.upgrade.ValuesYaml, "replicas: 2") {
+		t.Error("expected the spec values to take precedence, got", rec.upgrade.ValuesYaml)
+	}
+	if len(rec.restarted) > 0 {
+		t.Error("expected no deployments to be restarted, got", rec.restarted)
+	}
+}
+
+func TestCommand_Apply_SecretValues(t *testing.T) {
+	resolver := mockSecretResolver{secrets: map[string]string{
+		"vault://kv/airbyte#edition": "community",
+		"aws-sm://airbyte/db#host":   "db.internal",
+	}}
+	c, rec := applyTest(t, k8s.TestProvider, 8000, WithSecretResolver(&resolver))
+	spec := ApplySpec{
+		Namespace: DefaultNamespace,
+		Values: map[string]interface{}{
+			"global": map[string]interface{}{"edition": "vault://kv/airbyte#edition"},
+			"worker": map[string]interface{}{"dbHost": "aws-sm://airbyte/db#host"},
+		},
+	}
+
+	changes, err := c.Apply(context.Background(), spec, nil)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// the resolved edition is the current edition, so it is not a change
+	expChanges := []Change{
+		{Field: "values.worker.dbHost", Desired: `"db.internal"`},
+	}
+	if d := cmp.Diff(expChanges, changes); d != "" {
+		t.Error("changes mismatch (-want +got):", d)
+	}
+
+	if rec.upgrade == nil {
+		t.Fatal("chart was not upgraded")
+	}
+	if !strings.Contains(rec.upgrade.ValuesYaml, "dbHost: db.internal") || strings.Contains(rec.upgrade.ValuesYaml, "://") {
+		t.Error("expected the chart to be upgraded with the resolved values, got", rec.upgrade.ValuesYaml)
+	}
+}
+
+func TestCommand_Apply_Port(t *testing.T) {
+	provider := k8s.TestProvider
+	provider.Name = k8s.Kind
+
+	c, rec := applyTest(t, provider, 8000)
+	spec := ApplySpec{Namespace: DefaultNamespace, Port: 9000, Hosts: []string{"airbyte.lan"}}
+
+	if _, err := c.Apply(context.Background(), spec, nil); err == nil {
+		t.Fatal("expected an error changing the port")
+	}
+	if rec.hosts != nil {
+		t.Error("expected nothing to change, got hosts", rec.hosts)
+	}
+
+	changes, err := c.Drift(context.Background(), ApplySpec{Namespace: DefaultNamespace, Port: 8000, Hosts: []string{"localhost"}})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if len(changes) > 0 {
+		t.Error("expected no drift, got", changes)
+	}
+}
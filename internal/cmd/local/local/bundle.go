@@ -0,0 +1,86 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/bundle"
+	"github.com/airbytehq/abctl/internal/redact"
+	"gopkg.in/yaml.v3"
+	"path"
+	"time"
+)
+
+// releaseStatus is the information about a helm release included in a support bundle.
+type releaseStatus struct {
+	Name         string                 `yaml:"name"`
+	Namespace    string                 `yaml:"namespace"`
+	Revision     int                    `yaml:"revision"`
+	Status       string                 `yaml:"status"`
+	Description  string                 `yaml:"description,omitempty"`
+	LastDeployed string                 `yaml:"lastDeployed,omitempty"`
+	ChartVersion string                 `yaml:"chartVersion"`
+	AppVersion   string                 `yaml:"appVersion"`
+	Values       map[string]interface{} `yaml:"values,omitempty"`
+}
+
+// BundleItems returns the bundle.Items for collecting the diagnostics of the airbyte and ingress controller
+// installations.
+// This includes the helm release status (with any sensitive values redacted) as well as the
+// logs, descriptions, and events of every pod.
+func (c *Command) BundleItems(ctx context.Context) ([]bundle.Item, error) {
+	var items []bundle.Item
+
+	releases, namespaces := []string{airbyteChartRelease}, []string{c.namespace}
+	if release, namespace := ingressControllerRelease(c.ingressController); release != "" {
+		releases = append(releases, release)
+		namespaces = append(namespaces, namespace)
+	}
+
+	for _, name := range releases {
+		name := name
+		items = append(items, bundle.Item{
+			Name: path.Join("helm", name+".yaml"),
+			Collect: func(context.Context) ([]byte, error) {
+				return c.releaseStatus(name)
+			},
+		})
+	}
+
+	for _, namespace := range namespaces {
+		k8sItems, err := bundle.K8sItems(ctx, c.k8s, namespace)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, k8sItems...)
+	}
+
+	return items, nil
+}
+
+// releaseStatus returns the yaml status of the helm release.
+func (c *Command) releaseStatus(name string) ([]byte, error) {
+	rel, err := c.releaseHelm(name).GetRelease(name)
+	if err != nil {
+		return nil, fmt.Errorf("could not get helm release %s: %w", name, err)
+	}
+
+	status := releaseStatus{
+		Name:      rel.Name,
+		Namespace: rel.Namespace,
+		Revision:  rel.Version,
+		Values:    redact.Values(rel.Config),
+	}
+	if rel.Info != nil {
+		status.Status = rel.Info.Status.String()
+		status.Description = rel.Info.Description
+		if !rel.Info.LastDeployed.IsZero() {
+			status.LastDeployed = rel.Info.LastDeployed.UTC().Format(time.RFC3339)
+		}
+	}
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		status.ChartVersion = rel.Chart.Metadata.Version
+		status.AppVersion = rel.Chart.Metadata.AppVersion
+	}
+
+	return yaml.Marshal(status)
+}
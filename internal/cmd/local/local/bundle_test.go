@@ -0,0 +1,95 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"github.com/airbytehq/abctl/internal/cmd/local/bundle"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/redact"
+	"github.com/google/go-cmp/cmp"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+	"testing"
+)
+
+func TestCommand_BundleItems(t *testing.T) {
+	helm := mockHelmClient{
+		getRelease: func(name string) (*release.Release, error) {
+			if name != airbyteChartRelease {
+				return nil, errors.New("release not found")
+			}
+			return &release.Release{
+				Name:      name,
+				Namespace: airbyteNamespace,
+				Version:   3,
+				Info:      &release.Info{Status: release.StatusDeployed},
+				Chart:     &chart.Chart{Metadata: &chart.Metadata{Version: "0.50.0", AppVersion: "0.60.0"}},
+				Config: map[string]interface{}{
+					"global": map[string]interface{}{
+						"auth": map[string]interface{}{"password": "hunter2"},
+					},
+				},
+			}, nil
+		},
+	}
+
+	k8sClient := mockK8sClient{
+		podList: func(_ context.Context, namespace string) (*coreV1.PodList, error) {
+			if namespace != airbyteNamespace {
+				return &coreV1.PodList{}, nil
+			}
+			return &coreV1.PodList{Items: []coreV1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "server"}}}}, nil
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&helm),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithHTTPClient(&mockHTTP{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := c.BundleItems(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	var names []string
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	expNames := []string{
+		"helm/airbyte-abctl.yaml",
+		"helm/ingress-nginx.yaml",
+		"airbyte-abctl/events.txt",
+		"airbyte-abctl/pods/server/describe.yaml",
+		"airbyte-abctl/pods/server/logs.txt",
+		"ingress-nginx/events.txt",
+	}
+	if d := cmp.Diff(expNames, names); d != "" {
+		t.Fatal("names mismatch (-want +got):", d)
+	}
+
+	results := bundle.Collector{Workers: 1}.Collect(context.Background(), items[:2])
+
+	status := string(results[0].Data)
+	for _, exp := range []string{"status: deployed", "chartVersion: 0.50.0", "password: " + redact.Redacted} {
+		if !strings.Contains(status, exp) {
+			t.Errorf("expected release status to contain %q, received:\n%s", exp, status)
+		}
+	}
+	if strings.Contains(status, "hunter2") {
+		t.Error("expected password to be redacted")
+	}
+
+	if results[1].Err == nil {
+		t.Error("expected an error for the missing release")
+	}
+}
@@ -0,0 +1,157 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/pterm/pterm"
+	"net/http"
+	"os"
+	"unicode/utf16"
+)
+
+const (
+	// caCertSecret contains the CA certificates the components of Airbyte trust, as a PEM bundle and a java
+	// truststore, which is mounted into every component at caCertDir.
+	caCertSecret        = "abctl-ca-cert"
+	caCertDir           = "/etc/abctl/ca"
+	caCertBundleKey     = "ca.crt"
+	caCertTrustStoreKey = "truststore.jks"
+	// caCertTrustStorePass is the password of the truststore, which only protects its integrity.
+	caCertTrustStorePass = "changeit"
+)
+
+// ReadCACert returns the PEM bundle of CA certificates at path.
+// An error is returned if the bundle contains no certificates, or any invalid certificate.
+func ReadCACert(path string) ([]byte, error) {
+	bundle, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read ca certificate %s: %w", path, err)
+	}
+	if _, err := parseCACert(bundle); err != nil {
+		return nil, fmt.Errorf("invalid ca certificate %s: %w", path, err)
+	}
+	return bundle, nil
+}
+
+func parseCACert(bundle []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for rest := bundle; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("certificate %d is invalid: %w", len(certs)+1, err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM encoded certificates found")
+	}
+	return certs, nil
+}
+
+// caCertTransport returns an http transport trusting the CA certificates of the bundle, in addition to the
+// certificates of the system.
+func caCertTransport(bundle []byte) (*http.Transport, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("no PEM encoded certificates found")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return transport, nil
+}
+
+// trustStore returns a java truststore (in the JKS format, as written by keytool) of the certs, with the password, as
+// java does not trust a PEM bundle.
+func trustStore(certs []*x509.Certificate, password string) []byte {
+	var b bytes.Buffer
+	writeUTF := func(s string) {
+		_ = binary.Write(&b, binary.BigEndian, uint16(len(s)))
+		b.WriteString(s)
+	}
+
+	// magic, version 2, and the number of entries
+	_ = binary.Write(&b, binary.BigEndian, []uint32{0xFEEDFEED, 2, uint32(len(certs))})
+	for i, cert := range certs {
+		// a trusted certificate entry, its alias and creation time
+		_ = binary.Write(&b, binary.BigEndian, uint32(2))
+		writeUTF(fmt.Sprintf("abctl-ca-%d", i))
+		_ = binary.Write(&b, binary.BigEndian, int64(0))
+		writeUTF("X.509")
+		_ = binary.Write(&b, binary.BigEndian, uint32(len(cert.Raw)))
+		b.Write(cert.Raw)
+	}
+
+	// the keyed digest protecting the integrity of the truststore
+	digest := sha1.New()
+	for _, c := range utf16.Encode([]rune(password)) {
+		_ = binary.Write(digest, binary.BigEndian, c)
+	}
+	digest.Write([]byte("Mighty Aphrodite"))
+	digest.Write(b.Bytes())
+	b.Write(digest.Sum(nil))
+
+	return b.Bytes()
+}
+
+// caCertValues returns the airbyte chart values which have the components of Airbyte trust the CA certificates
+// mounted by caCertMounts, for java (via a truststore), python, node, and openssl based processes.
+func caCertValues() []string {
+	bundle := caCertDir + "/" + caCertBundleKey
+	return []string{
+		fmt.Sprintf("global.env_vars.SSL_CERT_FILE=%s", bundle),
+		fmt.Sprintf("global.env_vars.REQUESTS_CA_BUNDLE=%s", bundle),
+		fmt.Sprintf("global.env_vars.NODE_EXTRA_CA_CERTS=%s", bundle),
+		fmt.Sprintf("global.env_vars.JAVA_TOOL_OPTIONS=-Djavax.net.ssl.trustStore=%s/%s -Djavax.net.ssl.trustStoreType=JKS -Djavax.net.ssl.trustStorePassword=%s",
+			caCertDir, caCertTrustStoreKey, caCertTrustStorePass),
+	}
+}
+
+// caCertMounts returns the volume mounts of the caCertSecret into every component of Airbyte supporting them, as the
+// caCertValues apply to every component.
+func caCertMounts() []k8s.VolumeMount {
+	mounts := make([]k8s.VolumeMount, len(volumeComponents))
+	for i, component := range volumeComponents {
+		mounts[i] = k8s.VolumeMount{Component: component, Type: k8s.VolumeSecret, Source: caCertSecret, MountPath: caCertDir, ReadOnly: true}
+	}
+	return mounts
+}
+
+// handleCACert creates or updates the caCertSecret containing the CA certificates of the bundle at path.
+func (c *Command) handleCACert(ctx context.Context, path string) error {
+	bundle, err := ReadCACert(path)
+	if err != nil {
+		return err
+	}
+	certs, _ := parseCACert(bundle)
+
+	c.spinner.UpdateText("Configuring the CA certificates")
+	data := map[string][]byte{
+		caCertBundleKey:     bundle,
+		caCertTrustStoreKey: trustStore(certs, caCertTrustStorePass),
+	}
+	if err := c.k8s.SecretCreateOrUpdate(ctx, c.namespace, caCertSecret, data); err != nil {
+		pterm.Error.Println("Unable to configure the CA certificates")
+		return fmt.Errorf("could not create or update secret %s: %w", caCertSecret, err)
+	}
+	pterm.Info.Printfln("CA certificates of '%s' configured", path)
+
+	return nil
+}
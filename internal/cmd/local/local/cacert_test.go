@@ -0,0 +1,182 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pterm/pterm"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// testCACert returns the PEM bundle of the certificate of the tls server.
+func testCACert(srv *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+}
+
+func TestReadCACert(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(valid, append([]byte("# corporate ca\n"), testCACert(srv)...), 0644); err != nil {
+		t.Fatal("could not write ca certificate", err)
+	}
+	bundle, err := ReadCACert(valid)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if !bytes.Contains(bundle, testCACert(srv)) {
+		t.Error("expected the bundle to be returned")
+	}
+
+	invalid := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "empty", content: "", err: "no PEM encoded certificates found"},
+		{name: "invalid", content: "-----BEGIN CERTIFICATE-----\naW52YWxpZA==\n-----END CERTIFICATE-----\n", err: "certificate 1 is invalid"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".pem")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal("could not write ca certificate", err)
+			}
+			if _, err := ReadCACert(path); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+
+	if _, err := ReadCACert(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Error("expected an error for a missing ca certificate")
+	}
+}
+
+func TestCACertTransport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	// the certificate of the server is not trusted by default
+	if _, err := (&http.Client{}).Get(srv.URL); err == nil {
+		t.Fatal("expected the certificate of the server not to be trusted")
+	}
+
+	transport, err := caCertTransport(testCACert(srv))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	res, err := (&http.Client{Transport: transport}).Get(srv.URL)
+	if err != nil {
+		t.Fatal("expected the certificate of the server to be trusted", err)
+	}
+	res.Body.Close()
+}
+
+func TestTrustStore(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	cert := srv.Certificate()
+
+	store := trustStore([]*x509.Certificate{cert}, "changeit")
+	r := bytes.NewReader(store)
+
+	var header [4]uint32
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		t.Fatal("could not read header", err)
+	}
+	// magic, version, number of entries, and the trusted certificate tag of the first entry
+	if d := cmp.Diff([4]uint32{0xFEEDFEED, 2, 1, 2}, header); d != "" {
+		t.Error("header mismatch (-want +got):", d)
+	}
+
+	// the certificate is followed by the keyed digest of everything before it
+	data, sum := store[:len(store)-sha1.Size], store[len(store)-sha1.Size:]
+	if !bytes.HasSuffix(data, cert.Raw) {
+		t.Error("expected the certificate to be the last entry")
+	}
+	digest := sha1.New()
+	for _, c := range utf16.Encode([]rune("changeit")) {
+		_ = binary.Write(digest, binary.BigEndian, c)
+	}
+	digest.Write([]byte("Mighty Aphrodite"))
+	digest.Write(data)
+	if !bytes.Equal(digest.Sum(nil), sum) {
+		t.Error("digest mismatch")
+	}
+}
+
+func TestCommand_handleCACert(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, testCACert(srv), 0644); err != nil {
+		t.Fatal("could not write ca certificate", err)
+	}
+
+	var secret map[string][]byte
+	k8sClient := mockK8sClient{
+		secretCreateOrUpdate: func(ctx context.Context, namespace, name string, data map[string][]byte) error {
+			if namespace != airbyteNamespace || name != caCertSecret {
+				t.Errorf("unexpected secret %s/%s", namespace, name)
+			}
+			secret = data
+			return nil
+		},
+	}
+
+	spinner, _ := pterm.DefaultSpinner.Start()
+	c := &Command{k8s: &k8sClient, namespace: airbyteNamespace, spinner: spinner}
+	if err := c.handleCACert(context.Background(), path); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if d := cmp.Diff(testCACert(srv), secret[caCertBundleKey]); d != "" {
+		t.Error("bundle mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff(trustStore([]*x509.Certificate{srv.Certificate()}, caCertTrustStorePass), secret[caCertTrustStoreKey]); d != "" {
+		t.Error("truststore mismatch (-want +got):", d)
+	}
+}
+
+func TestCACertValues(t *testing.T) {
+	values, err := mergedValues("", append(caCertValues(), volumeMountValues(caCertMounts())...))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	envVars := values["global"].(map[string]interface{})["env_vars"].(map[string]interface{})
+	exp := map[string]interface{}{
+		"SSL_CERT_FILE":       "/etc/abctl/ca/ca.crt",
+		"REQUESTS_CA_BUNDLE":  "/etc/abctl/ca/ca.crt",
+		"NODE_EXTRA_CA_CERTS": "/etc/abctl/ca/ca.crt",
+		"JAVA_TOOL_OPTIONS":   "-Djavax.net.ssl.trustStore=/etc/abctl/ca/truststore.jks -Djavax.net.ssl.trustStoreType=JKS -Djavax.net.ssl.trustStorePassword=changeit",
+	}
+	if d := cmp.Diff(exp, envVars); d != "" {
+		t.Error("env vars mismatch (-want +got):", d)
+	}
+
+	// the secret is mounted into every component, as the env vars apply to every component
+	for _, component := range volumeComponents {
+		mounts := values[component].(map[string]interface{})["extraVolumeMounts"].([]interface{})
+		exp := map[string]interface{}{"name": mounts[0].(map[string]interface{})["name"], "mountPath": caCertDir, "readOnly": true}
+		if d := cmp.Diff(exp, mounts[0]); d != "" {
+			t.Errorf("%s mount mismatch (-want +got): %s", component, d)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/registry"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Kinds of chart locations.
+const (
+	// ChartLocRepo is a chart of a helm repository, e.g. airbyte/airbyte.
+	ChartLocRepo = "repo"
+	// ChartLocURL is the url of a chart archive, e.g. https://example.com/airbyte-1.0.0.tgz.
+	ChartLocURL = "url"
+	// ChartLocLocal is a local chart directory or archive, e.g. ./charts/airbyte.
+	ChartLocLocal = "local"
+	// ChartLocOCI is a chart of an oci registry, e.g. oci://registry.example.com/charts/airbyte:1.0.0, which may be
+	// pinned to the digest of its manifest, e.g. oci://registry.example.com/charts/airbyte@sha256:<digest>.
+	ChartLocOCI = "oci"
+)
+
+// ChartLoc is the location of a chart, see ParseChartLoc.
+type ChartLoc struct {
+	// Kind is one of ChartLocRepo, ChartLocURL, ChartLocLocal, or ChartLocOCI.
+	Kind string
+	// Ref is the name of the repository chart, the url, the absolute path of the local chart, or the oci reference
+	// excluding its tag and digest.
+	Ref string
+	// Tag is the tag of the oci reference, which is the version of the chart.
+	Tag string
+	// Digest is the digest the oci reference is pinned to, e.g. sha256:<digest>.
+	Digest string
+}
+
+// String returns the location as it was provided.
+func (l ChartLoc) String() string {
+	s := l.Ref
+	if l.Tag != "" {
+		s += ":" + l.Tag
+	}
+	if l.Digest != "" {
+		s += "@" + l.Digest
+	}
+	return s
+}
+
+// digestPattern matches a sha256 digest of an oci manifest.
+var digestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// ParseChartLoc returns the location of the airbyte chart, which is the chart of the airbyte helm repository if loc is
+// empty, or else an oci:// reference, the http(s) url of a chart archive, or the path of a local chart directory or
+// archive.
+func ParseChartLoc(loc string) (ChartLoc, error) {
+	switch {
+	case loc == "":
+		return ChartLoc{Kind: ChartLocRepo, Ref: airbyteChartName}, nil
+	case registry.IsOCI(loc):
+		return parseOCIChartLoc(loc)
+	case strings.HasPrefix(loc, "http://") || strings.HasPrefix(loc, "https://"):
+		return ChartLoc{Kind: ChartLocURL, Ref: loc}, nil
+	}
+
+	abs, err := filepath.Abs(loc)
+	if err != nil {
+		return ChartLoc{}, fmt.Errorf("could not resolve chart path %s: %w", loc, err)
+	}
+	fi, err := os.Stat(abs)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ChartLoc{}, fmt.Errorf("chart %s must be an oci:// reference, an http(s) url, or an existing chart directory or archive", loc)
+	}
+	if err != nil {
+		return ChartLoc{}, fmt.Errorf("could not stat chart %s: %w", loc, err)
+	}
+	if fi.IsDir() {
+		if _, err := os.Stat(filepath.Join(abs, "Chart.yaml")); err != nil {
+			return ChartLoc{}, fmt.Errorf("chart directory %s contains no Chart.yaml", loc)
+		}
+	}
+	return ChartLoc{Kind: ChartLocLocal, Ref: abs}, nil
+}
+
+// parseOCIChartLoc parses the oci reference oci://host/path/chart[:tag][@digest].
+func parseOCIChartLoc(loc string) (ChartLoc, error) {
+	l := ChartLoc{Kind: ChartLocOCI, Ref: loc}
+	if i := strings.LastIndex(l.Ref, "@"); i >= 0 {
+		l.Ref, l.Digest = l.Ref[:i], l.Ref[i+1:]
+		if !digestPattern.MatchString(l.Digest) {
+			return ChartLoc{}, fmt.Errorf("invalid digest %s of chart %s, must be sha256:<64 hex characters>", l.Digest, loc)
+		}
+	}
+	if i := strings.LastIndex(l.Ref, ":"); i > strings.LastIndex(l.Ref, "/") {
+		l.Ref, l.Tag = l.Ref[:i], l.Ref[i+1:]
+	}
+	if strings.TrimPrefix(l.Ref, "oci://") == "" || !strings.Contains(strings.TrimPrefix(l.Ref, "oci://"), "/") {
+		return ChartLoc{}, fmt.Errorf("invalid chart %s, must be oci://<registry>/<repository>[:tag][@digest]", loc)
+	}
+	return l, nil
+}
+
+// RegistryAuth are the credentials of an oci registry. If empty, the credentials of 'helm registry login' or
+// 'docker login' are used.
+type RegistryAuth struct {
+	Username string
+	Password string
+}
+
+// pullOCIChart pulls the chart of the oci location into dir, returning the path of the chart archive.
+// The tag of the location takes precedence over the version, and the latest tag is pulled if neither is provided.
+// A pinned chart is pulled by its digest, the tag then only needs to match the version of the chart.
+func pullOCIChart(loc ChartLoc, version string, auth RegistryAuth, caCert, dir string) (string, error) {
+	host := strings.SplitN(strings.TrimPrefix(loc.Ref, "oci://"), "/", 2)[0]
+
+	// the credentials are only used for this pull, and not stored with those of 'helm registry login'
+	var credentials string
+	if auth.Username != "" {
+		f, err := os.CreateTemp("", "abctl-registry-*.json")
+		if err != nil {
+			return "", fmt.Errorf("could not create registry credentials file: %w", err)
+		}
+		_ = f.Close()
+		defer os.Remove(f.Name())
+		credentials = f.Name()
+	}
+
+	client, err := registry.NewRegistryClientWithTLS(io.Discard, "", "", caCert, false, credentials, false)
+	if err != nil {
+		return "", fmt.Errorf("could not create registry client: %w", err)
+	}
+	if auth.Username != "" {
+		if err := client.Login(host, registry.LoginOptBasicAuth(auth.Username, auth.Password), registry.LoginOptTLSClientConfig("", "", caCert)); err != nil {
+			return "", fmt.Errorf("could not log into registry %s: %w", host, err)
+		}
+	}
+
+	ref := strings.TrimPrefix(loc.Ref, "oci://")
+	tag := loc.Tag
+	if tag == "" {
+		tag = version
+	}
+	switch {
+	case loc.Digest != "":
+		ref += "@" + loc.Digest
+	case tag != "":
+		ref += ":" + tag
+	default:
+		tags, err := client.Tags(ref)
+		if err != nil {
+			return "", fmt.Errorf("could not list tags of chart %s: %w", loc, err)
+		}
+		if len(tags) == 0 {
+			return "", fmt.Errorf("chart %s has no tags", loc)
+		}
+		// the tags are sorted by semver, newest first
+		ref += ":" + tags[0]
+	}
+
+	res, err := client.Pull(ref)
+	if err != nil {
+		return "", fmt.Errorf("could not pull chart %s: %w", loc, err)
+	}
+	if loc.Digest != "" && res.Manifest.Digest != loc.Digest {
+		return "", fmt.Errorf("chart %s has digest %s, expected %s", loc, res.Manifest.Digest, loc.Digest)
+	}
+	if loc.Digest != "" && tag != "" && res.Chart.Meta.Version != tag {
+		return "", fmt.Errorf("chart %s is version %s, expected %s", loc, res.Chart.Meta.Version, tag)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("could not create directory %s: %w", dir, err)
+	}
+	dst := filepath.Join(dir, fmt.Sprintf("%s-%s.tgz", res.Chart.Meta.Name, res.Chart.Meta.Version))
+	if err := os.WriteFile(dst+".tmp", res.Chart.Data, 0644); err != nil {
+		return "", fmt.Errorf("could not write chart %s: %w", dst, err)
+	}
+	if err := os.Rename(dst+".tmp", dst); err != nil {
+		return "", fmt.Errorf("could not write chart %s: %w", dst, err)
+	}
+	return dst, nil
+}
+
+// checkDependencies returns an error if a dependency of the chart is missing, e.g. of a chart directory whose
+// dependencies were not built, which would otherwise be installed without them.
+func checkDependencies(c *chart.Chart) error {
+	if len(c.Metadata.Dependencies) == 0 {
+		return nil
+	}
+	if err := action.CheckDependencies(c, c.Metadata.Dependencies); err != nil {
+		return fmt.Errorf("chart %s is missing dependencies, run 'helm dependency build' for a chart directory: %w", c.Name(), err)
+	}
+	return nil
+}
+
+// locateChart fetches the chart of a location other than ChartLocRepo, pulling an oci chart into dir, and returns it
+// along with the path it is installed from.
+func locateChart(helm HelmClient, loc ChartLoc, version string, auth RegistryAuth, caCert, dir string) (*chart.Chart, string, error) {
+	name := loc.Ref
+	if loc.Kind == ChartLocOCI {
+		var err error
+		if name, err = pullOCIChart(loc, version, auth, caCert, dir); err != nil {
+			return nil, "", err
+		}
+	}
+
+	c, path, err := helm.GetChart(name, &action.ChartPathOptions{Version: version, CaFile: caCert})
+	if err != nil {
+		return nil, "", fmt.Errorf("could not fetch chart %s: %w", loc, err)
+	}
+	if err := checkDependencies(c); err != nil {
+		return nil, "", err
+	}
+	return c, path, nil
+}
+
+// LoadAirbyteChart fetches the airbyte helm chart of the location and version, see GetAirbyteChart for the charts of
+// the airbyte helm repository.
+func LoadAirbyteChart(helm HelmClient, loc ChartLoc, version string, auth RegistryAuth) (*chart.Chart, error) {
+	if loc.Kind == ChartLocRepo {
+		return GetAirbyteChart(helm, version)
+	}
+
+	// an oci chart is loaded once it is pulled, so its archive isn't kept
+	dir, err := os.MkdirTemp("", "abctl-chart-")
+	if err != nil {
+		return nil, fmt.Errorf("could not create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, _, err := locateChart(helm, loc, version, auth, "", dir)
+	return c, err
+}
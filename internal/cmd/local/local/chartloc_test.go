@@ -0,0 +1,162 @@
+package local
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestParseChartLoc(t *testing.T) {
+	dir := t.TempDir()
+	chartDir := filepath.Join(dir, "airbyte")
+	if err := os.MkdirAll(chartDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte("name: airbyte\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		loc    string
+		exp    ChartLoc
+		expErr string
+	}{
+		{
+			name: "default",
+			exp:  ChartLoc{Kind: ChartLocRepo, Ref: airbyteChartName},
+		},
+		{
+			name: "url",
+			loc:  "https://example.com/airbyte-1.0.0.tgz",
+			exp:  ChartLoc{Kind: ChartLocURL, Ref: "https://example.com/airbyte-1.0.0.tgz"},
+		},
+		{
+			name: "directory",
+			loc:  chartDir,
+			exp:  ChartLoc{Kind: ChartLocLocal, Ref: chartDir},
+		},
+		{
+			name:   "directory without chart",
+			loc:    dir,
+			expErr: "contains no Chart.yaml",
+		},
+		{
+			name:   "missing path",
+			loc:    filepath.Join(dir, "missing"),
+			expErr: "must be an oci:// reference, an http(s) url, or an existing chart directory or archive",
+		},
+		{
+			name: "oci",
+			loc:  "oci://registry.example.com:5000/charts/airbyte",
+			exp:  ChartLoc{Kind: ChartLocOCI, Ref: "oci://registry.example.com:5000/charts/airbyte"},
+		},
+		{
+			name: "oci tag",
+			loc:  "oci://registry.example.com/charts/airbyte:1.0.0",
+			exp:  ChartLoc{Kind: ChartLocOCI, Ref: "oci://registry.example.com/charts/airbyte", Tag: "1.0.0"},
+		},
+		{
+			name: "oci digest",
+			loc:  "oci://registry.example.com/charts/airbyte:1.0.0@" + testDigest,
+			exp:  ChartLoc{Kind: ChartLocOCI, Ref: "oci://registry.example.com/charts/airbyte", Tag: "1.0.0", Digest: testDigest},
+		},
+		{
+			name:   "oci invalid digest",
+			loc:    "oci://registry.example.com/charts/airbyte@sha256:abc",
+			expErr: "invalid digest sha256:abc",
+		},
+		{
+			name:   "oci without repository",
+			loc:    "oci://registry.example.com",
+			expErr: "must be oci://<registry>/<repository>[:tag][@digest]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := ParseChartLoc(tt.loc)
+			if tt.expErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expErr) {
+					t.Errorf("expected error containing %q, got %v", tt.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, loc); d != "" {
+				t.Error("location mismatch (-want +got):", d)
+			}
+			if tt.loc != "" {
+				if d := cmp.Diff(tt.loc, loc.String()); d != "" {
+					t.Error("string mismatch (-want +got):", d)
+				}
+			}
+		})
+	}
+}
+
+func TestLocateChart(t *testing.T) {
+	dir := t.TempDir()
+	airbyte := &chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "airbyte", Version: "1.0.0"}}
+	dependent := &chart.Chart{Metadata: &chart.Metadata{
+		APIVersion:   chart.APIVersionV2,
+		Name:         "airbyte",
+		Version:      "1.0.0",
+		Dependencies: []*chart.Dependency{{Name: "server", Version: "1.0.0"}},
+	}}
+
+	tests := []struct {
+		name   string
+		chart  *chart.Chart
+		expErr string
+	}{
+		{
+			name:  "chart",
+			chart: airbyte,
+		},
+		{
+			name:   "missing dependencies",
+			chart:  dependent,
+			expErr: "run 'helm dependency build'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the helm repository is not configured, as addOrUpdateChartRepo is nil
+			helm := &mockHelmClient{
+				getChart: func(name string, opts *action.ChartPathOptions) (*chart.Chart, string, error) {
+					if d := cmp.Diff(dir, name); d != "" {
+						t.Error("chart name mismatch (-want +got):", d)
+					}
+					return tt.chart, name, nil
+				},
+			}
+
+			c, path, err := locateChart(helm, ChartLoc{Kind: ChartLocLocal, Ref: dir}, "", RegistryAuth{}, "", t.TempDir())
+			if tt.expErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expErr) {
+					t.Errorf("expected error containing %q, got %v", tt.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(dir, path); d != "" {
+				t.Error("path mismatch (-want +got):", d)
+			}
+			if c != tt.chart {
+				t.Error("unexpected chart", c)
+			}
+		})
+	}
+}
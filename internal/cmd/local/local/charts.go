@@ -0,0 +1,218 @@
+package local
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/download"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/pterm/pterm"
+	"helm.sh/helm/v3/pkg/provenance"
+	"helm.sh/helm/v3/pkg/repo"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DownloadOpts configures how the helm charts are downloaded.
+type DownloadOpts struct {
+	// MaxRate limits the download rate to MaxRate bytes per second, zero is unlimited.
+	MaxRate int64
+	// Dir is the directory where the downloaded charts are stored.
+	Dir string
+	// NoCache, if true, always downloads the charts again instead of using any previously downloaded charts, and
+	// fails instead of using a previously downloaded repository index if the latest index cannot be downloaded.
+	NoCache bool
+	// Keyring is the path of the PGP keyring which verifies the provenance of the charts which require it.
+	// If empty, only the digests of the charts are verified.
+	Keyring string
+	// InsecureSkipVerify, if true, installs charts which cannot be verified.
+	InsecureSkipVerify bool
+}
+
+// downloadChart downloads the chart, resuming any previously interrupted download, and returns the local path
+// of the downloaded chart archive.
+// A chart archive which has already been downloaded and verified will not be downloaded again, unless opts.NoCache
+// is set. If the repository is unavailable, the previously downloaded index is used, allowing a previously
+// downloaded chart to be installed while offline.
+func (c *Command) downloadChart(ctx context.Context, req chartRequest, opts DownloadOpts) (string, error) {
+	dlOpts := []download.Option{download.WithMaxRate(opts.MaxRate)}
+
+	indexPath := filepath.Join(opts.Dir, req.repoName+"-index.yaml")
+	if err := c.downloadIndex(ctx, req, indexPath, opts, dlOpts); err != nil {
+		return "", err
+	}
+
+	idx, err := repo.LoadIndexFile(indexPath)
+	if err != nil {
+		return "", fmt.Errorf("could not load %s repository index: %w", req.repoName, err)
+	}
+
+	chartName := path.Base(req.chartName)
+	cv, err := idx.Get(chartName, req.chartVersion)
+	if err != nil {
+		return "", fmt.Errorf("could not find chart %s: %w", req.chartName, err)
+	}
+	if len(cv.URLs) == 0 {
+		return "", fmt.Errorf("chart %s (version: %s) has no download urls", req.chartName, cv.Version)
+	}
+
+	if cv.Digest == "" && !opts.InsecureSkipVerify {
+		return "", fmt.Errorf("%w: chart %s (version: %s) has no digest in the %s repository index", localerr.ErrUnverified, req.chartName, cv.Version, req.repoName)
+	}
+
+	url, err := repo.ResolveReferenceURL(req.repoURL, cv.URLs[0])
+	if err != nil {
+		return "", fmt.Errorf("could not resolve chart url %s: %w", cv.URLs[0], err)
+	}
+
+	dst := filepath.Join(opts.Dir, fmt.Sprintf("%s-%s.tgz", cv.Name, cv.Version))
+	if opts.NoCache {
+		if err := os.Remove(dst); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("could not remove cached chart %s: %w", dst, err)
+		}
+	}
+	if err := c.downloadVerified(ctx, req, cv, url, dst, dlOpts); err != nil {
+		return "", err
+	}
+
+	if req.provenance && opts.Keyring != "" && !opts.InsecureSkipVerify {
+		c.spinner.UpdateText(fmt.Sprintf("Verifying %s Helm Chart provenance", req.chartName))
+		if err := verifyProvenance(ctx, c.httpDownload, url, dst, opts.Keyring); err != nil {
+			return "", err
+		}
+	}
+
+	return dst, nil
+}
+
+// downloadVerified downloads the chart from the url to dst, unless dst has already been downloaded and matches the
+// digest, in which case it is not downloaded again.
+func (c *Command) downloadVerified(ctx context.Context, req chartRequest, cv *repo.ChartVersion, url, dst string, dlOpts []download.Option) error {
+	if _, err := os.Stat(dst); err == nil {
+		if err := verifyDigest(dst, cv.Digest); err == nil {
+			return nil
+		}
+		// the existing archive is invalid, download it again
+		if err := os.Remove(dst); err != nil {
+			return fmt.Errorf("could not remove invalid chart %s: %w", dst, err)
+		}
+	}
+
+	c.spinner.UpdateText(fmt.Sprintf("Downloading %s Helm Chart (version: %s)", req.chartName, cv.Version))
+	if err := download.File(ctx, c.httpDownload, url, dst, dlOpts...); err != nil {
+		return fmt.Errorf("could not download chart %s: %w", req.chartName, err)
+	}
+
+	if err := verifyDigest(dst, cv.Digest); err != nil {
+		_ = os.Remove(dst)
+		return err
+	}
+
+	return nil
+}
+
+// verifyProvenance downloads the provenance file of the chart, published alongside the chart at url + ".prov",
+// and verifies the chart was signed by a key of the keyring and has not been modified since.
+// The provenance file is always downloaded again, so a revoked signature is never trusted.
+func verifyProvenance(ctx context.Context, client download.Doer, url, chart, keyring string) error {
+	prov := chart + ".prov"
+	if err := os.Remove(prov); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("could not remove previous provenance file %s: %w", prov, err)
+	}
+	if err := download.File(ctx, client, url+".prov", prov, download.WithAttempts(3, time.Second)); err != nil {
+		return fmt.Errorf("%w: could not download provenance file %s.prov: %w", localerr.ErrUnverified, url, err)
+	}
+
+	sig, err := provenance.NewFromKeyring(keyring, "")
+	if err != nil {
+		return fmt.Errorf("could not load keyring %s: %w", keyring, err)
+	}
+	if _, err := sig.Verify(chart, prov); err != nil {
+		return fmt.Errorf("%w: chart %s failed provenance verification: %w", localerr.ErrUnverified, filepath.Base(chart), err)
+	}
+
+	return nil
+}
+
+// downloadIndex downloads the latest index of the repository to indexPath, as the latest chart version may have
+// changed. The previous index is only replaced once the latest index has been downloaded, and is used instead if
+// the latest index cannot be downloaded, unless opts.NoCache is set.
+func (c *Command) downloadIndex(ctx context.Context, req chartRequest, indexPath string, opts DownloadOpts, dlOpts []download.Option) error {
+	tmp := indexPath + ".download"
+	if err := os.Remove(tmp); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("could not remove previous index download %s: %w", tmp, err)
+	}
+
+	err := download.File(ctx, c.httpDownload, strings.TrimSuffix(req.repoURL, "/")+"/index.yaml", tmp, dlOpts...)
+	if err == nil {
+		if err := os.Rename(tmp, indexPath); err != nil {
+			return fmt.Errorf("could not replace index %s: %w", indexPath, err)
+		}
+		return nil
+	}
+
+	if _, statErr := os.Stat(indexPath); opts.NoCache || statErr != nil {
+		return fmt.Errorf("could not download %s repository index: %w", req.repoName, err)
+	}
+	pterm.Warning.Printfln("Unable to download the %s repository index, using the previously downloaded index", req.repoName)
+	logging.Debugf("could not download %s repository index: %s", req.repoName, err)
+	return nil
+}
+
+// verifyDigest verifies that the sha256 digest of the file matches the expected digest.
+// An empty expected digest is always considered valid.
+func verifyDigest(file, expected string) error {
+	if expected == "" {
+		return nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("could not open %s: %w", file, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("could not read %s: %w", file, err)
+	}
+
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != expected {
+		return fmt.Errorf("%w: digest mismatch for %s: expected %s, received %s", localerr.ErrUnverified, file, expected, actual)
+	}
+
+	return nil
+}
+
+// ChartVersions returns the versions of the Airbyte helm chart, newest first.
+// The repository index previously downloaded into dir is used, as it is only required to be reasonably recent,
+// otherwise the index is downloaded into dir.
+func ChartVersions(ctx context.Context, client download.Doer, dir string) ([]string, error) {
+	indexPath := filepath.Join(dir, airbyteRepoName+"-index.yaml")
+	if _, err := os.Stat(indexPath); err != nil {
+		url := strings.TrimSuffix(airbyteRepoURL, "/") + "/index.yaml"
+		if err := download.File(ctx, client, url, indexPath, download.WithAttempts(1, 0)); err != nil {
+			return nil, fmt.Errorf("could not download %s repository index: %w", airbyteRepoName, err)
+		}
+	}
+
+	idx, err := repo.LoadIndexFile(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not load %s repository index: %w", airbyteRepoName, err)
+	}
+	idx.SortEntries()
+
+	var versions []string
+	for _, cv := range idx.Entries[path.Base(airbyteChartName)] {
+		versions = append(versions, cv.Version)
+	}
+	return versions, nil
+}
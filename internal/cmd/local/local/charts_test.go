@@ -0,0 +1,277 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommand_DownloadChart(t *testing.T) {
+	const archive = "chart archive contents"
+	sum := sha256.Sum256([]byte(archive))
+	index := fmt.Sprintf(`apiVersion: v1
+entries:
+  airbyte:
+  - name: airbyte
+    version: 1.0.0
+    digest: %s
+    urls:
+    - charts/airbyte-1.0.0.tgz
+  - name: airbyte
+    version: 0.9.0
+    urls:
+    - https://example.com/airbyte-0.9.0.tgz
+`, hex.EncodeToString(sum[:]))
+
+	var requested []string
+	client := mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+		requested = append(requested, req.URL.String())
+		switch req.URL.String() {
+		case airbyteRepoURL + "/index.yaml":
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(index))}, nil
+		case airbyteRepoURL + "/charts/airbyte-1.0.0.tgz":
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(archive))}, nil
+		default:
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+	}}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&mockK8sClient{}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithDownloadHTTPClient(&client),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := chartRequest{repoName: airbyteRepoName, repoURL: airbyteRepoURL, chartName: airbyteChartName}
+	opts := DownloadOpts{Dir: t.TempDir()}
+
+	chart, err := c.downloadChart(context.Background(), req, opts)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(filepath.Join(opts.Dir, "airbyte-1.0.0.tgz"), chart); d != "" {
+		t.Error("chart mismatch (-want +got):", d)
+	}
+	if data, _ := os.ReadFile(chart); string(data) != archive {
+		t.Error("unexpected chart contents", string(data))
+	}
+
+	// a verified chart must not be downloaded again, only the index
+	requested = nil
+	if _, err := c.downloadChart(context.Background(), req, opts); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff([]string{airbyteRepoURL + "/index.yaml"}, requested); d != "" {
+		t.Error("requested mismatch (-want +got):", d)
+	}
+
+	// a corrupt chart must be downloaded again
+	if err := os.WriteFile(chart, []byte("corrupt"), 0644); err != nil {
+		t.Fatal("could not write chart", err)
+	}
+	requested = nil
+	if _, err := c.downloadChart(context.Background(), req, opts); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff([]string{airbyteRepoURL + "/index.yaml", airbyteRepoURL + "/charts/airbyte-1.0.0.tgz"}, requested); d != "" {
+		t.Error("requested mismatch (-want +got):", d)
+	}
+
+	// an unavailable repository must fall back to the previously downloaded index and chart
+	offline := false
+	online := client.do
+	client.do = func(req *http.Request) (*http.Response, error) {
+		if offline {
+			requested = append(requested, req.URL.String())
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+		return online(req)
+	}
+	offline = true
+	requested = nil
+	if chart, err = c.downloadChart(context.Background(), req, opts); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(filepath.Join(opts.Dir, "airbyte-1.0.0.tgz"), chart); d != "" {
+		t.Error("chart mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff([]string{airbyteRepoURL + "/index.yaml"}, requested); d != "" {
+		t.Error("requested mismatch (-want +got):", d)
+	}
+
+	// unless the cache must not be used
+	if _, err := c.downloadChart(context.Background(), req, DownloadOpts{Dir: opts.Dir, NoCache: true}); err == nil {
+		t.Error("expected error for an unavailable repository without the cache")
+	}
+
+	// no cache must download the chart again
+	offline = false
+	requested = nil
+	if _, err := c.downloadChart(context.Background(), req, DownloadOpts{Dir: opts.Dir, NoCache: true}); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff([]string{airbyteRepoURL + "/index.yaml", airbyteRepoURL + "/charts/airbyte-1.0.0.tgz"}, requested); d != "" {
+		t.Error("requested mismatch (-want +got):", d)
+	}
+
+	// an unknown version must fail
+	req.chartVersion = "2.0.0"
+	if _, err := c.downloadChart(context.Background(), req, opts); err == nil {
+		t.Error("expected error for unknown version")
+	}
+}
+
+func TestVerifyDigest(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "chart.tgz")
+	if err := os.WriteFile(file, []byte("contents"), 0644); err != nil {
+		t.Fatal("could not write file", err)
+	}
+	sum := sha256.Sum256([]byte("contents"))
+
+	if err := verifyDigest(file, hex.EncodeToString(sum[:])); err != nil {
+		t.Error("unexpected error", err)
+	}
+	if err := verifyDigest(file, ""); err != nil {
+		t.Error("unexpected error for empty digest", err)
+	}
+	if err := verifyDigest(file, "invalid"); err == nil {
+		t.Error("expected digest mismatch error")
+	}
+}
+
+func TestCommand_DownloadChart_NoDigest(t *testing.T) {
+	index := `apiVersion: v1
+entries:
+  airbyte:
+  - name: airbyte
+    version: 1.0.0
+    urls:
+    - charts/airbyte-1.0.0.tgz
+`
+	client := mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+		switch req.URL.String() {
+		case airbyteRepoURL + "/index.yaml":
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(index))}, nil
+		default:
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("chart archive contents"))}, nil
+		}
+	}}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&mockK8sClient{}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithDownloadHTTPClient(&client),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := chartRequest{repoName: airbyteRepoName, repoURL: airbyteRepoURL, chartName: airbyteChartName}
+
+	// a chart without a digest cannot be verified
+	if _, err := c.downloadChart(context.Background(), req, DownloadOpts{Dir: t.TempDir()}); !errors.Is(err, localerr.ErrUnverified) {
+		t.Error("expected an unverified error, received", err)
+	}
+
+	// unless verification is skipped
+	if _, err := c.downloadChart(context.Background(), req, DownloadOpts{Dir: t.TempDir(), InsecureSkipVerify: true}); err != nil {
+		t.Error("unexpected error", err)
+	}
+}
+
+func TestVerifyProvenance(t *testing.T) {
+	dir := t.TempDir()
+	chart := filepath.Join(dir, "hashtest-1.2.3.tgz")
+	data, err := os.ReadFile(filepath.Join("testdata", "provenance", "hashtest-1.2.3.tgz"))
+	if err != nil {
+		t.Fatal("could not read chart", err)
+	}
+	if err := os.WriteFile(chart, data, 0644); err != nil {
+		t.Fatal("could not write chart", err)
+	}
+	prov, err := os.ReadFile(filepath.Join("testdata", "provenance", "hashtest-1.2.3.tgz.prov"))
+	if err != nil {
+		t.Fatal("could not read provenance", err)
+	}
+	keyring := filepath.Join("testdata", "provenance", "helm-test-key.pub")
+
+	const url = "https://example.com/charts/hashtest-1.2.3.tgz"
+	client := &mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() != url+".prov" {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(prov))}, nil
+	}}
+
+	if err := verifyProvenance(context.Background(), client, url, chart, keyring); err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	// a missing provenance file must fail
+	if err := verifyProvenance(context.Background(), client, "https://example.com/charts/unsigned-1.0.0.tgz", chart, keyring); !errors.Is(err, localerr.ErrUnverified) {
+		t.Error("expected an unverified error for a missing provenance file, received", err)
+	}
+
+	// a modified chart must fail
+	if err := os.WriteFile(chart, append(data, 0), 0644); err != nil {
+		t.Fatal("could not write chart", err)
+	}
+	if err := verifyProvenance(context.Background(), client, url, chart, keyring); !errors.Is(err, localerr.ErrUnverified) {
+		t.Error("expected an unverified error for a modified chart, received", err)
+	}
+}
+
+func TestChartVersions(t *testing.T) {
+	const index = `apiVersion: v1
+entries:
+  airbyte:
+  - name: airbyte
+    version: 0.9.0
+  - name: airbyte
+    version: 1.0.0
+  airbyte-workers:
+  - name: airbyte-workers
+    version: 2.0.0
+`
+
+	var requested []string
+	client := mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+		requested = append(requested, req.URL.String())
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(index))}, nil
+	}}
+
+	dir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		versions, err := ChartVersions(context.Background(), &client, dir)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if d := cmp.Diff([]string{"1.0.0", "0.9.0"}, versions); d != "" {
+			t.Error("versions mismatch (-want +got):", d)
+		}
+	}
+
+	// the downloaded index must be reused
+	if d := cmp.Diff([]string{airbyteRepoURL + "/index.yaml"}, requested); d != "" {
+		t.Error("requested mismatch (-want +got):", d)
+	}
+}
@@ -4,7 +4,12 @@ import (
 	"context"
 	"fmt"
 	"github.com/airbytehq/abctl/internal/cmd/local/docker"
+	"github.com/airbytehq/abctl/internal/cmd/local/events"
 	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/progress"
+	"github.com/airbytehq/abctl/internal/cmd/local/readiness"
+	"github.com/airbytehq/abctl/internal/cmd/local/secretref"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -14,21 +19,26 @@ import (
 
 	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
 	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/airbytehq/abctl/internal/redact"
+	"github.com/airbytehq/abctl/internal/shutdown"
 	"github.com/airbytehq/abctl/internal/telemetry"
+	"github.com/airbytehq/abctl/internal/trace"
 	"github.com/cli/browser"
 	"github.com/google/uuid"
 	helmclient "github.com/mittwald/go-helm-client"
 	"github.com/mittwald/go-helm-client/values"
 	"github.com/pterm/pterm"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/sync/errgroup"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/repo"
 	eventsv1 "k8s.io/api/events/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/rest"
 )
 
 const (
@@ -54,6 +64,8 @@ type HelmClient interface {
 	GetChart(string, *action.ChartPathOptions) (*chart.Chart, string, error)
 	GetRelease(name string) (*release.Release, error)
 	InstallOrUpgradeChart(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error)
+	ListReleaseHistory(name string, max int) ([]*release.Release, error)
+	RollbackRelease(spec *helmclient.ChartSpec) error
 	UninstallReleaseByName(string) error
 }
 
@@ -61,6 +73,13 @@ type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// SecretResolver resolves references to the secrets of external secret stores, see secretref.Resolver.
+// Defined for testing purposes.
+type SecretResolver interface {
+	Resolve(ctx context.Context, s string) (string, error)
+	ResolveYAML(ctx context.Context, doc string) (string, error)
+}
+
 // BrowserLauncher primarily for testing purposes.
 type BrowserLauncher func(url string) error
 
@@ -69,13 +88,41 @@ type Command struct {
 	provider k8s.Provider
 	cluster  k8s.Cluster
 	http     HTTPClient
-	helm     HelmClient
-	k8s      k8s.Client
-	portHTTP int
-	spinner  *pterm.SpinnerPrinter
-	tel      telemetry.Client
-	launcher BrowserLauncher
-	userHome string
+	// httpDownload is used for downloading charts, it has no timeout as downloads may be large and/or throttled
+	httpDownload HTTPClient
+	helm         HelmClient
+	k8s          k8s.Client
+	host         string
+	portHTTP     int
+	spinner      *pterm.SpinnerPrinter
+	tel          telemetry.Client
+	launcher     BrowserLauncher
+	secrets      SecretResolver
+	userHome     string
+	// eventsFile is where the interesting kubernetes events are persisted
+	eventsFile string
+	// namespace is the namespace Airbyte is installed into
+	namespace string
+	// ingressController is the ingress controller installed in front of Airbyte
+	ingressController string
+	// ingressClass is the ingress class of the existing ingress controller serving Airbyte, if ingressController is
+	// IngressNone
+	ingressClass string
+	// apiPort, if not zero, is the port exposing the Airbyte api in addition to the webapp
+	apiPort int
+	// bindAddress is the host address the ports of the cluster are bound to, empty for all addresses
+	bindAddress string
+	// valuesDiff is which differences are displayed when a release is upgraded
+	valuesDiff string
+	// caCert, if not empty, is the path of a PEM bundle of CA certificates trusted by the chart downloads and the
+	// components of Airbyte, e.g. the CA of a TLS-intercepting proxy
+	caCert string
+	// k8sVersion is the version of the kubernetes server
+	k8sVersion string
+	// ingressHelm is the helm client of the ingress controller releases, which are shared by every installation of
+	// the cluster, so they are stored in airbyteNamespace rather than namespace, see releaseHelm. It is a client of
+	// its own, as a helm client is not safe for concurrent use and the controller is installed alongside Airbyte.
+	ingressHelm HelmClient
 }
 
 // Option for configuring the Command, primarily exists for testing
@@ -95,6 +142,13 @@ func WithHTTPClient(client HTTPClient) Option {
 	}
 }
 
+// WithDownloadHTTPClient define the http client used for downloading charts for this command.
+func WithDownloadHTTPClient(client HTTPClient) Option {
+	return func(c *Command) {
+		c.httpDownload = client
+	}
+}
+
 // WithHelmClient define the helm client for this command.
 func WithHelmClient(client HelmClient) Option {
 	return func(c *Command) {
@@ -102,6 +156,13 @@ func WithHelmClient(client HelmClient) Option {
 	}
 }
 
+// WithIngressHelmClient define the helm client of the ingress controller releases for this command.
+func WithIngressHelmClient(client HelmClient) Option {
+	return func(c *Command) {
+		c.ingressHelm = client
+	}
+}
+
 // WithK8sClient define the k8s client for this command.
 func WithK8sClient(client k8s.Client) Option {
 	return func(c *Command) {
@@ -123,12 +184,122 @@ func WithUserHome(home string) Option {
 	}
 }
 
+// WithEventsFile define the file where the interesting kubernetes events are persisted.
+// Defaults to paths.Events.
+func WithEventsFile(path string) Option {
+	return func(c *Command) {
+		c.eventsFile = path
+	}
+}
+
 func WithSpinner(spinner *pterm.SpinnerPrinter) Option {
 	return func(c *Command) {
 		c.spinner = spinner
 	}
 }
 
+// WithHost define the host used to access the ingress when verifying the status of this command.
+// Defaults to DefaultHost.
+func WithHost(host string) Option {
+	return func(c *Command) {
+		c.host = host
+	}
+}
+
+// ValidateNamespace verifies the namespace is a valid kubernetes namespace name.
+func ValidateNamespace(namespace string) error {
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return fmt.Errorf("invalid namespace %s: %s", namespace, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Namespaces returns the names of the namespaces within the cluster of the provider.
+func Namespaces(ctx context.Context, provider k8s.Provider) ([]string, error) {
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("could not determine user home directory: %w", err)
+	}
+
+	restCfg, err := k8s.RestConfig(provider.KubeconfigPath(userHome), provider.Context)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", localerr.ErrKubernetes, err)
+	}
+	client, err := k8s.NewClient(restCfg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", localerr.ErrKubernetes, err)
+	}
+
+	namespaces, err := client.NamespaceList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list namespaces: %w", err)
+	}
+	return namespaces, nil
+}
+
+// WithNamespace defines the namespace Airbyte is installed into, defaults to DefaultNamespace.
+func WithNamespace(namespace string) Option {
+	return func(c *Command) {
+		c.namespace = namespace
+	}
+}
+
+// WithIngressController defines the ingress controller installed in front of Airbyte, defaults to IngressNginx.
+// An installation with a different ingress controller replaces it.
+func WithIngressController(controller string) Option {
+	return func(c *Command) {
+		c.ingressController = controller
+	}
+}
+
+// WithIngressClass defines the ingress class of an existing ingress controller serving Airbyte, if the ingress
+// controller is IngressNone. Defaults to the default ingress class of the cluster.
+func WithIngressClass(class string) Option {
+	return func(c *Command) {
+		c.ingressClass = class
+	}
+}
+
+// WithAPIPort defines the port exposing the Airbyte api (airbyte-server) in addition to the webapp.
+// The api is not exposed on its own port if it is zero, which is the default.
+func WithAPIPort(port int) Option {
+	return func(c *Command) {
+		c.apiPort = port
+	}
+}
+
+// WithBindAddress defines the host address the ports of the cluster are bound to, defaults to all addresses.
+// The ingress is verified on the bind address, regardless of which address its hosts resolve to.
+func WithBindAddress(address string) Option {
+	return func(c *Command) {
+		c.bindAddress = address
+	}
+}
+
+// WithValuesDiff defines which differences are displayed when a release is upgraded, one of DiffNone, DiffValues, or
+// DiffAll. Defaults to DiffValues.
+func WithValuesDiff(diff string) Option {
+	return func(c *Command) {
+		c.valuesDiff = diff
+	}
+}
+
+// WithCACert defines the path of a PEM bundle of CA certificates, which the chart downloads and (once installed) the
+// components of Airbyte trust in addition to the certificates of the system.
+func WithCACert(path string) Option {
+	return func(c *Command) {
+		c.caCert = path
+	}
+}
+
+// WithSecretResolver defines the resolver of the secret references (e.g. vault://path#key) of the values file,
+// the basic-auth password, and the single sign-on credentials.
+func WithSecretResolver(resolver SecretResolver) Option {
+	return func(c *Command) {
+		c.secrets = resolver
+	}
+}
+
 func WithPortHTTP(port int) Option {
 	return func(c *Command) {
 		c.portHTTP = port
@@ -150,32 +321,83 @@ func New(provider k8s.Provider, opts ...Option) (*Command, error) {
 		}
 	}
 
+	if c.host == "" {
+		c.host = DefaultHost
+	}
+
 	// set http client, if not defined
 	if c.http == nil {
-		c.http = &http.Client{Timeout: 10 * time.Second}
+		c.http = &http.Client{Timeout: 10 * time.Second, Transport: bindTransport(c.bindAddress, c.host)}
+	}
+
+	// the chart downloads trust the ca certificate, which is ignored if it is no longer valid, so the existing
+	// installation remains manageable
+	if c.caCert != "" {
+		bundle, err := ReadCACert(c.caCert)
+		if err != nil {
+			pterm.Warning.Printfln("Unable to read the CA certificate '%s', it will not be trusted", c.caCert)
+			logging.Debugf("could not read ca certificate: %s", err)
+			c.caCert = ""
+		} else if c.httpDownload == nil {
+			transport, err := caCertTransport(bundle)
+			if err != nil {
+				return nil, fmt.Errorf("could not trust ca certificate %s: %w", c.caCert, err)
+			}
+			c.httpDownload = &http.Client{Transport: transport}
+		}
+	}
+
+	// set download http client, if not defined
+	if c.httpDownload == nil {
+		c.httpDownload = &http.Client{}
 	}
 
 	if c.portHTTP == 0 {
 		c.portHTTP = Port
 	}
 
-	// set k8s client, if not defined
-	if c.k8s == nil {
-		kubecfg := filepath.Join(c.userHome, provider.Kubeconfig)
-		var err error
-		if c.k8s, err = defaultK8s(kubecfg, provider.Context); err != nil {
-			return nil, err
-		}
+	if c.eventsFile == "" {
+		c.eventsFile = paths.Events
 	}
 
-	// set the helm client, if not defined
-	if c.helm == nil {
-		kubecfg := filepath.Join(c.userHome, provider.Kubeconfig)
-		var err error
-		if c.helm, err = defaultHelm(kubecfg, provider.Context); err != nil {
-			return nil, err
+	if c.namespace == "" {
+		c.namespace = DefaultNamespace
+	}
+
+	if c.ingressController == "" {
+		c.ingressController = IngressNginx
+	}
+
+	if c.valuesDiff == "" {
+		c.valuesDiff = DiffValues
+	}
+
+	// set the k8s and helm clients, if not defined, both of which share the rest config of the kubeconfig context
+	if c.k8s == nil || c.helm == nil {
+		restCfg, err := k8s.RestConfig(provider.KubeconfigPath(c.userHome), provider.Context)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", localerr.ErrKubernetes, err)
+		}
+
+		if c.k8s == nil {
+			if c.k8s, err = k8s.NewClient(restCfg); err != nil {
+				return nil, fmt.Errorf("%w: %w", localerr.ErrKubernetes, err)
+			}
+		}
+
+		if c.helm == nil {
+			if c.helm, err = defaultHelm(restCfg, c.namespace); err != nil {
+				return nil, err
+			}
+			if c.ingressHelm, err = defaultHelm(restCfg, airbyteNamespace); err != nil {
+				return nil, err
+			}
 		}
 	}
+	// a helm client defined for testing also stores the ingress controller releases, unless defined separately
+	if c.ingressHelm == nil {
+		c.ingressHelm = c.helm
+	}
 
 	// set telemetry client, if not defined
 	if c.tel == nil {
@@ -192,13 +414,18 @@ func New(provider k8s.Provider, opts ...Option) (*Command, error) {
 		c.launcher = browser.OpenURL
 	}
 
+	// set the secret resolver, if not defined
+	if c.secrets == nil {
+		c.secrets = secretref.New()
+	}
+
 	// fetch k8s version information
 	{
-		k8sVersion, err := c.k8s.ServerVersionGet()
-		if err != nil {
+		var err error
+		if c.k8sVersion, err = c.k8s.ServerVersionGet(); err != nil {
 			return nil, fmt.Errorf("%w: could not fetch kubernetes server version: %w", localerr.ErrKubernetes, err)
 		}
-		c.tel.Attr("k8s_version", k8sVersion)
+		c.tel.Attr("k8s_version", c.k8sVersion)
 	}
 
 	// set provider version
@@ -214,8 +441,67 @@ type InstallOpts struct {
 	ValuesFile       string
 	Migrate          bool
 	Docker           *docker.Docker
+	// AirbyteChartLoc is the location the airbyte chart is installed from, the airbyte helm repository if its Kind is
+	// empty or ChartLocRepo.
+	AirbyteChartLoc ChartLoc
+	// ChartAuth are the credentials of the oci registry of the AirbyteChartLoc.
+	ChartAuth RegistryAuth
+	// Hosts are the hostnames Airbyte will be accessible from, defaults to DefaultHost if empty.
+	Hosts []string
+	// ConnectorRegistry contains the custom connector definitions to create once Airbyte is ready, if not nil.
+	ConnectorRegistry *ConnectorRegistry
+	// Download, if not nil, downloads the charts directly (supporting throttling and resuming) instead of via helm.
+	Download *DownloadOpts
+	// StorageClass is the storage class of the persistent volumes.
+	// Defaults to k8s.StandardStorageClass, or the cluster's default storage class for an existing cluster.
+	StorageClass string
+	// Monitoring, if true, installs prometheus and grafana, with Airbyte publishing its metrics to prometheus.
+	Monitoring bool
+	// LogAggregation, if true, installs loki to retain the logs of every pod for LogRetention.
+	LogAggregation bool
+	// LogRetention is how long the aggregated logs are retained, defaults to DefaultLogRetention.
+	LogRetention time.Duration
+	// RegistryMirror, if set, is a registry host the airbyte and ingress controller chart images are pulled from
+	// instead.
+	RegistryMirror string
+	// IngressController is the ingress controller to install, defaults to the ingress controller of the Command.
+	IngressController string
+	// IngressClass is the ingress class of an existing ingress controller serving the ingress, if IngressController
+	// is IngressNone. The default ingress class of the cluster serves the ingress if it is empty.
+	IngressClass string
+	// ReadyTimeout is how long to wait for every component of Airbyte to become ready once it is installed,
+	// defaults to DefaultReadyTimeout.
+	ReadyTimeout time.Duration
+	// Demo, if true, creates a demo connection from a Faker source to the bundled postgres once Airbyte is ready and
+	// starts its first sync.
+	Demo bool
+	// SkipK8sVersionCheck, if true, installs onto a kubernetes version older than the HelmChartVersion supports,
+	// instead of failing.
+	SkipK8sVersionCheck bool
+	// ExtraManifests, if set, is a manifest file, a directory of manifest files, or a kustomization directory, whose
+	// objects are applied once the charts are installed. They are deleted once they are removed from the
+	// ExtraManifests of a subsequent installation, or Airbyte is uninstalled.
+	ExtraManifests string
+	// VolumeMounts are additional volumes mounted into the components of Airbyte.
+	VolumeMounts []k8s.VolumeMount
+	// DNS, if not nil, configures the host aliases and dns servers of the kind cluster, which resolve the names of
+	// every pod. Empty DNSOpts restore the default dns config. The dns config is left unchanged if DNS is nil.
+	DNS *DNSOpts
+	// Disabled are the optional components of Airbyte which are not installed, see OptionalComponents.
+	Disabled []string
+	// LowResource, if true, installs Airbyte with fewer components and smaller connector jobs, for machines with
+	// limited memory.
+	LowResource bool
+	// Tuning are the job concurrency and resource values of Airbyte.
+	Tuning TuningOpts
 }
 
+// DefaultNamespace is the namespace Airbyte is installed into if no other namespace is provided.
+const DefaultNamespace = airbyteNamespace
+
+// DefaultHost is the hostname Airbyte will be accessible from if no other hosts are provided.
+const DefaultHost = "localhost"
+
 const (
 	// persistent volume constants, these are named to match the values given in the helm chart
 	pvMinio = "airbyte-minio-pv"
@@ -226,10 +512,20 @@ const (
 	pvcPsql  = "airbyte-volume-db-airbyte-db-0"
 )
 
-func (c *Command) persistentVolume(ctx context.Context, namespace, name string) error {
+// volumeName returns the name of the persistent volume, which must be unique within the cluster.
+// The volumes of an installation into any namespace other than the DefaultNamespace are prefixed by the namespace,
+// so the data of multiple installations is kept apart.
+func (c *Command) volumeName(name string) string {
+	if c.namespace == DefaultNamespace {
+		return name
+	}
+	return c.namespace + "-" + name
+}
+
+func (c *Command) persistentVolume(ctx context.Context, namespace, name, storageClass string) error {
 	if !c.k8s.PersistentVolumeExists(ctx, namespace, name) {
 		c.spinner.UpdateText(fmt.Sprintf("Creating persistent volume '%s'", name))
-		if err := c.k8s.PersistentVolumeCreate(ctx, namespace, name); err != nil {
+		if err := c.k8s.PersistentVolumeCreate(ctx, namespace, name, storageClass); err != nil {
 			pterm.Error.Println(fmt.Sprintf("Could not create persistent volume '%s'", name))
 			return fmt.Errorf("could not create persistent volume '%s': %w", name, err)
 		}
@@ -241,10 +537,10 @@ func (c *Command) persistentVolume(ctx context.Context, namespace, name string)
 	return nil
 }
 
-func (c *Command) persistentVolumeClaim(ctx context.Context, namespace, name, volumeName string) error {
+func (c *Command) persistentVolumeClaim(ctx context.Context, namespace, name, volumeName, storageClass string) error {
 	if !c.k8s.PersistentVolumeClaimExists(ctx, namespace, name, volumeName) {
 		c.spinner.UpdateText(fmt.Sprintf("Creating persistent volume claim '%s'", name))
-		if err := c.k8s.PersistentVolumeClaimCreate(ctx, namespace, name, volumeName); err != nil {
+		if err := c.k8s.PersistentVolumeClaimCreate(ctx, namespace, name, volumeName, storageClass); err != nil {
 			pterm.Error.Println(fmt.Sprintf("Could not create persistent volume claim '%s'", name))
 			return fmt.Errorf("could not create persistent volume claim '%s': %w", name, err)
 		}
@@ -256,6 +552,47 @@ func (c *Command) persistentVolumeClaim(ctx context.Context, namespace, name, vo
 	return nil
 }
 
+// persistentVolumes creates the hostPath persistent volumes and claims, migrating the data of an existing
+// docker compose installation into them if requested.
+func (c *Command) persistentVolumes(ctx context.Context, opts InstallOpts) error {
+	storageClass := opts.StorageClass
+	if storageClass == "" {
+		storageClass = k8s.StandardStorageClass
+	}
+
+	if err := c.persistentVolume(ctx, c.namespace, c.volumeName(pvMinio), storageClass); err != nil {
+		return err
+	}
+
+	if err := c.persistentVolume(ctx, c.namespace, c.volumeName(pvPsql), storageClass); err != nil {
+		return err
+	}
+
+	if opts.Migrate {
+		c.spinner.UpdateText("Migrating airbyte data")
+		done := shutdown.Track(
+			"Migrating airbyte data",
+			fmt.Sprintf("The migrated data in '%s' may be incomplete, run 'abctl local uninstall --persisted' before migrating again.", paths.Data),
+			nil,
+		)
+		err := opts.Docker.MigrateComposeDB(ctx, "airbyte_db")
+		done()
+		if err != nil {
+			pterm.Error.Println("Failed to migrate data from previous Airbyte installation")
+			return fmt.Errorf("could not migrate data from previous airbyte installation: %w", err)
+		}
+	}
+
+	if err := c.persistentVolumeClaim(ctx, c.namespace, pvcMinio, c.volumeName(pvMinio), storageClass); err != nil {
+		return err
+	}
+	if err := c.persistentVolumeClaim(ctx, c.namespace, pvcPsql, c.volumeName(pvPsql), storageClass); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // Install handles the installation of Airbyte
 func (c *Command) Install(ctx context.Context, opts InstallOpts) error {
 	var values string
@@ -264,43 +601,72 @@ func (c *Command) Install(ctx context.Context, opts InstallOpts) error {
 		if err != nil {
 			return fmt.Errorf("could not read values file '%s': %w", opts.ValuesFile, err)
 		}
-		values = string(raw)
+		// the secrets are resolved every install, so an upgrade uses their current values
+		if values, err = c.secrets.ResolveYAML(ctx, string(raw)); err != nil {
+			pterm.Error.Printfln("Unable to resolve the secrets of the values file '%s'", opts.ValuesFile)
+			return fmt.Errorf("could not resolve secrets of values file '%s': %w", opts.ValuesFile, err)
+		}
 	}
 
-	go c.watchEvents(ctx)
-
-	if !c.k8s.NamespaceExists(ctx, airbyteNamespace) {
-		c.spinner.UpdateText(fmt.Sprintf("Creating namespace '%s'", airbyteNamespace))
-		if err := c.k8s.NamespaceCreate(ctx, airbyteNamespace); err != nil {
-			pterm.Error.Println(fmt.Sprintf("Could not create namespace '%s'", airbyteNamespace))
-			return fmt.Errorf("could not create airbyte namespace: %w", err)
-		}
-		pterm.Info.Println(fmt.Sprintf("Namespace '%s' created", airbyteNamespace))
-	} else {
-		pterm.Info.Printfln("Namespace '%s' already exists", airbyteNamespace)
+	// the password is also used by the readiness checks and the api, which require its value
+	pass, err := c.secrets.Resolve(ctx, opts.Pass)
+	if err != nil {
+		pterm.Error.Println("Unable to resolve the basic-auth password")
+		return fmt.Errorf("could not resolve basic-auth password: %w", err)
 	}
+	opts.Pass = pass
+
+	// the event watcher is stopped once the installation completes, or is interrupted
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+	go c.watchEvents(watchCtx)
 
-	if err := c.persistentVolume(ctx, airbyteNamespace, pvMinio); err != nil {
+	if err := c.checkK8sVersion(opts.HelmChartVersion, opts.SkipK8sVersionCheck); err != nil {
 		return err
 	}
 
-	if err := c.persistentVolume(ctx, airbyteNamespace, pvPsql); err != nil {
+	if err := c.checkPermissions(ctx, opts); err != nil {
 		return err
 	}
 
-	if opts.Migrate {
-		c.spinner.UpdateText("Migrating airbyte data")
-		if err := opts.Docker.MigrateComposeDB(ctx, "airbyte_db"); err != nil {
-			pterm.Error.Println("Failed to migrate data from previous Airbyte installation")
-			return fmt.Errorf("could not migrate data from previous airbyte installation: %w", err)
+	if !c.k8s.NamespaceExists(ctx, c.namespace) {
+		c.spinner.UpdateText(fmt.Sprintf("Creating namespace '%s'", c.namespace))
+		if err := c.k8s.NamespaceCreate(ctx, c.namespace); err != nil {
+			pterm.Error.Println(fmt.Sprintf("Could not create namespace '%s'", c.namespace))
+			return fmt.Errorf("could not create airbyte namespace: %w", err)
 		}
+		pterm.Info.Println(fmt.Sprintf("Namespace '%s' created", c.namespace))
+	} else {
+		pterm.Info.Printfln("Namespace '%s' already exists", c.namespace)
 	}
 
-	if err := c.persistentVolumeClaim(ctx, airbyteNamespace, pvcMinio, pvMinio); err != nil {
-		return err
+	if c.caCert != "" {
+		if err := c.handleCACert(ctx, c.caCert); err != nil {
+			return err
+		}
 	}
-	if err := c.persistentVolumeClaim(ctx, airbyteNamespace, pvcPsql, pvPsql); err != nil {
-		return err
+
+	// the dns of an existing cluster is not managed by abctl
+	if opts.DNS != nil && c.provider.Name != k8s.Existing {
+		if err := c.handleDNS(ctx, *opts.DNS); err != nil {
+			return err
+		}
+	}
+
+	// the hostPath persistent volumes are only created if the cluster's default StorageClass should not be used,
+	// otherwise the persistent volume claims of the helm chart are provisioned by the default StorageClass, unless
+	// another storage class was requested, in which case the claims are created to be provisioned by it instead
+	switch {
+	case !c.provider.DefaultStorageClass:
+		if err := c.persistentVolumes(ctx, opts); err != nil {
+			return err
+		}
+	case opts.StorageClass != "":
+		for _, pvc := range []string{pvcMinio, pvcPsql} {
+			if err := c.persistentVolumeClaim(ctx, c.namespace, pvc, "", opts.StorageClass); err != nil {
+				return err
+			}
+		}
 	}
 
 	var telUser string
@@ -309,50 +675,122 @@ func (c *Command) Install(ctx context.Context, opts InstallOpts) error {
 		telUser = c.tel.User().String()
 	}
 
-	if err := c.handleChart(ctx, chartRequest{
+	airbyteValues := []string{
+		fmt.Sprintf("global.env_vars.AIRBYTE_INSTALLATION_ID=%s", telUser),
+	}
+	if opts.Monitoring {
+		airbyteValues = append(airbyteValues, monitoringAirbyteValues(c.namespace)...)
+	}
+	volumeMounts := opts.VolumeMounts
+	if c.caCert != "" {
+		airbyteValues = append(airbyteValues, caCertValues()...)
+		volumeMounts = append(caCertMounts(), volumeMounts...)
+	}
+	airbyteValues = append(airbyteValues, volumeMountValues(volumeMounts)...)
+	airbyteValues = append(airbyteValues, disabledValues(opts.Disabled, opts.LowResource)...)
+	airbyteValues = append(airbyteValues, tuningValues(opts.Tuning)...)
+
+	controller := opts.IngressController
+	if controller == "" {
+		controller = c.ingressController
+	}
+	c.ingressClass = ""
+	if controller == IngressNone {
+		c.ingressClass = opts.IngressClass
+		// Airbyte is exposed by its webapp service, unless the ingress is served by an existing ingress controller
+		if c.ingressClass == "" {
+			airbyteValues = append(airbyteValues, fmt.Sprintf("webapp.service.type=%s", webappServiceType(c.provider)))
+		}
+	}
+
+	var ingressValues []string
+	if opts.RegistryMirror != "" {
+		var mirrorAirbyte []string
+		mirrorAirbyte, ingressValues = registryMirrorValues(opts.RegistryMirror, controller)
+		airbyteValues = append(airbyteValues, mirrorAirbyte...)
+	}
+
+	// the charts are prepared one after the other, displaying any changes of their values, before either is installed
+	airbyte, err := c.prepareRelease(ctx, chartRequest{
 		name:         "airbyte",
 		repoName:     airbyteRepoName,
 		repoURL:      airbyteRepoURL,
 		chartName:    airbyteChartName,
 		chartRelease: airbyteChartRelease,
 		chartVersion: opts.HelmChartVersion,
-		namespace:    airbyteNamespace,
-		values: []string{
-			fmt.Sprintf("global.env_vars.AIRBYTE_INSTALLATION_ID=%s", telUser),
-		},
-		valuesYAML: values,
-	}); err != nil {
+		namespace:    c.namespace,
+		values:       airbyteValues,
+		valuesYAML:   values,
+		download:     opts.Download,
+		provenance:   true,
+		loc:          opts.AirbyteChartLoc,
+		auth:         opts.ChartAuth,
+	})
+	if err != nil {
 		return fmt.Errorf("could not install airbyte chart: %w", err)
 	}
+	ingress, err := c.prepareIngressController(ctx, controller, ingressValues, opts.Download)
+	if err != nil {
+		return err
+	}
 
-	if err := c.handleChart(ctx, chartRequest{
-		name:         "nginx",
-		repoName:     nginxRepoName,
-		repoURL:      nginxRepoURL,
-		chartName:    nginxChartName,
-		chartRelease: nginxChartRelease,
-		namespace:    nginxNamespace,
-		values:       append(c.provider.HelmNginx, fmt.Sprintf("controller.service.ports.http=%d", c.portHTTP)),
-	}); err != nil {
-		// If we timed out, there is a good chance it's due to an unavailable port, check if this is the case.
-		// As the kubernetes client doesn't return usable error types, have to check for a specific string value.
-		if strings.Contains(err.Error(), "client rate limiter Wait returned an error") {
-			pterm.Warning.Printfln("Encountered an error while installing the %s Helm Chart.\n"+
-				"This could be an indication that port %d is not available.\n"+
-				"If installation fails, please try again with a different port.", nginxChartName, c.portHTTP)
-
-			srv, err := c.k8s.ServiceGet(ctx, nginxNamespace, "ingress-nginx-controller")
-			// If there is an error, we can ignore it as we only are checking for a missing ingress entry,
-			// and an error would indicate the inability to check for that entry.
-			if err == nil {
-				ingresses := srv.Status.LoadBalancer.Ingress
-				if len(ingresses) == 0 {
-					// if there are no ingresses, that is a possible indicator that the port is already in use.
-					return fmt.Errorf("%w: could not install nginx chart", localerr.ErrIngress)
-				}
+	// the ingress controller doesn't depend on airbyte until the ingress is created, so both charts are installed
+	// concurrently, as waiting for either to become ready takes minutes. Neither installation is cancelled if the
+	// other fails, as an interrupted installation leaves its release in a pending state. Each reports its progress
+	// as its own part of the spinner, and their results are displayed once both are complete.
+	c.spinner.UpdateText("Installing Helm Charts")
+	parts := progress.Join(progress.Spinner(c.spinner, "Installing Helm Charts"), 2)
+	var (
+		charts                 errgroup.Group
+		airbyteRel, ingressRel *release.Release
+		airbyteErr, ingressErr error
+		// replaced is true once any previous controller is uninstalled, uninstalled if there was such a controller
+		replaced, uninstalled bool
+	)
+	charts.Go(func() error {
+		airbyteRel, airbyteErr = c.installRelease(ctx, airbyte, chartReporter(airbyte, parts[0]))
+		parts[0]("")
+		return airbyteErr
+	})
+	if ingress != nil {
+		charts.Go(func() error {
+			// the previous controller is only uninstalled once its replacement is prepared and about to be installed
+			if uninstalled, ingressErr = c.uninstallIngressController(controller, parts[1]); ingressErr == nil {
+				replaced = true
+				ingressRel, ingressErr = c.installRelease(ctx, *ingress, chartReporter(*ingress, parts[1]))
 			}
+			parts[1]("")
+			return ingressErr
+		})
+	}
+	// the errors of both installations are displayed below
+	_ = charts.Wait()
+
+	airbyteErr = printRelease(airbyte, airbyteRel, airbyteErr)
+	// without a controller to install, the previous controller is only uninstalled once Airbyte is installed
+	if ingress == nil && airbyteErr == nil {
+		uninstalled, ingressErr = c.uninstallIngressController(controller, progress.Spinner(c.spinner, "Installing Helm Charts"))
+		if ingressErr != nil {
+			pterm.Error.Printfln("Unable to uninstall the %s ingress controller", c.ingressController)
 		}
-		return fmt.Errorf("could not install nginx chart: %w", err)
+		replaced = ingressErr == nil
+	}
+	if uninstalled {
+		pterm.Success.Printfln("Uninstalled the %s ingress controller", c.ingressController)
+	}
+	if replaced {
+		c.ingressController = controller
+	}
+	if ingress != nil {
+		if err := printRelease(*ingress, ingressRel, ingressErr); err != nil {
+			ingressErr = c.ingressControllerError(ctx, controller, *ingress, err)
+		}
+	}
+	if airbyteErr != nil {
+		return fmt.Errorf("could not install airbyte chart: %w", airbyteErr)
+	}
+	if ingressErr != nil {
+		return ingressErr
 	}
 
 	c.spinner.UpdateText("Configuring Basic-Auth")
@@ -361,24 +799,84 @@ func (c *Command) Install(ctx context.Context, opts InstallOpts) error {
 		return fmt.Errorf("could not create or update basic-auth secret: %w", err)
 	}
 
-	if err := c.handleIngress(ctx); err != nil {
+	hosts := opts.Hosts
+	if len(hosts) == 0 {
+		hosts = []string{DefaultHost}
+	}
+
+	if err := c.handleIngress(ctx, hosts); err != nil {
+		return err
+	}
+
+	if opts.LogAggregation {
+		if err := c.handleLogAggregation(ctx, opts.LogRetention, opts.Download); err != nil {
+			return err
+		}
+	}
+
+	if opts.Monitoring {
+		if err := c.handleMonitoring(ctx, hosts, opts.Download); err != nil {
+			return err
+		}
+	}
+
+	if opts.ExtraManifests != "" {
+		if err := c.handleExtraManifests(ctx, opts.ExtraManifests); err != nil {
+			return fmt.Errorf("could not apply extra manifests: %w", err)
+		}
+	}
+
+	if c.ingressController == IngressNone {
+		c.printIngressInstructions()
+		return nil
+	}
+
+	readyTimeout := opts.ReadyTimeout
+	if readyTimeout == 0 {
+		readyTimeout = DefaultReadyTimeout
+	}
+	if err := c.waitReady(ctx, readyTimeout, c.componentChecks(hosts[0], opts.User, opts.Pass)); err != nil {
 		return err
 	}
 
 	c.spinner.UpdateText("Verifying ingress")
-	if err := c.openBrowser(ctx, fmt.Sprintf("http://localhost:%d", c.portHTTP)); err != nil {
+	if err := c.openBrowser(ctx, hosts[0], ""); err != nil {
+		return err
+	}
+
+	if err := c.verifyAPIPort(ctx, hosts[0]); err != nil {
 		return err
 	}
 
+	if opts.ConnectorRegistry != nil {
+		c.spinner.UpdateText("Importing custom connectors")
+		if err := c.importConnectorRegistry(ctx, *opts.ConnectorRegistry, baseURL(hosts[0], c.portHTTP), opts.User, opts.Pass); err != nil {
+			return fmt.Errorf("could not import connector registry: %w", err)
+		}
+	}
+
+	if opts.Demo {
+		if err := c.createDemo(ctx, APIOpts{Host: hosts[0], User: opts.User, Pass: opts.Pass}); err != nil {
+			pterm.Error.Println("Unable to create the demo connection")
+			return fmt.Errorf("could not create demo connection: %w", err)
+		}
+	}
+
 	return nil
 }
 
-func (c *Command) handleIngress(ctx context.Context) error {
+func (c *Command) handleIngress(ctx context.Context, hosts []string) (err error) {
+	ctx, span := trace.NewSpan(ctx, "ingress")
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	c.spinner.UpdateText("Checking for existing Ingress")
 
-	if c.k8s.IngressExists(ctx, airbyteNamespace, airbyteIngress) {
+	if c.k8s.IngressExists(ctx, c.namespace, airbyteIngress) {
 		pterm.Success.Println("Found existing Ingress")
-		if err := c.k8s.IngressUpdate(ctx, airbyteNamespace, ingress()); err != nil {
+		if err := c.k8s.IngressUpdate(ctx, c.namespace, ingress(c.namespace, hosts, c.ingressController, c.ingressClass)); err != nil {
 			pterm.Error.Printfln("Unable to update existing Ingress")
 			return fmt.Errorf("could not update existing ingress: %w", err)
 		}
@@ -387,7 +885,7 @@ func (c *Command) handleIngress(ctx context.Context) error {
 	}
 
 	pterm.Info.Println("No existing Ingress found, creating one")
-	if err := c.k8s.IngressCreate(ctx, airbyteNamespace, ingress()); err != nil {
+	if err := c.k8s.IngressCreate(ctx, c.namespace, ingress(c.namespace, hosts, c.ingressController, c.ingressClass)); err != nil {
 		pterm.Error.Println("Unable to create ingress")
 		return fmt.Errorf("could not create ingress: %w", err)
 	}
@@ -396,7 +894,7 @@ func (c *Command) handleIngress(ctx context.Context) error {
 }
 
 func (c *Command) watchEvents(ctx context.Context) {
-	watcher, err := c.k8s.EventsWatch(ctx, airbyteNamespace)
+	watcher, err := c.k8s.EventsWatch(ctx, c.namespace)
 	if err != nil {
 		pterm.Warning.Printfln("Unable to watch airbyte events\n  %s", err)
 		return
@@ -407,16 +905,16 @@ func (c *Command) watchEvents(ctx context.Context) {
 		select {
 		case event, ok := <-watcher.ResultChan():
 			if !ok {
-				pterm.Debug.Println("Event watcher completed.")
+				logging.Debugf("Event watcher completed.")
 				return
 			}
 			if convertedEvent, ok := event.Object.(*eventsv1.Event); ok {
 				c.handleEvent(ctx, convertedEvent)
 			} else {
-				pterm.Debug.Printfln("Received unexpected event: %T", event.Object)
+				logging.Debugf("Received unexpected event: %T", event.Object)
 			}
 		case <-ctx.Done():
-			pterm.Debug.Printfln("Event watcher context completed:\n  %s", ctx.Err())
+			logging.Debugf("Event watcher context completed:\n  %s", ctx.Err())
 			return
 		}
 	}
@@ -438,16 +936,21 @@ func (c *Command) handleEvent(ctx context.Context, e *eventsv1.Event) {
 		return
 	}
 
+	c.recordEvent(e)
+
 	switch {
 	case strings.EqualFold(e.Type, "normal"):
-		pterm.Debug.Println(e.Note)
+		logging.Debugf("%s", e.Note)
 	case strings.EqualFold(e.Type, "warning"):
 		var logs = ""
 		if strings.EqualFold(e.Reason, "backoff") {
 			var err error
 			logs, err = c.k8s.LogsGet(ctx, e.Regarding.Namespace, e.Regarding.Name)
 			if err != nil {
-				pterm.Debug.Printfln("Unable to retrieve logs for %s:%s\n  %s", e.Regarding.Namespace, e.Regarding.Name, err)
+				logging.Debugf("Unable to retrieve logs for %s:%s\n  %s", e.Regarding.Namespace, e.Regarding.Name, err)
+			} else {
+				logs = redact.String(logs)
+				trace.AttachLog(ctx, fmt.Sprintf("%s:%s", e.Regarding.Namespace, e.Regarding.Name), logs)
 			}
 		}
 
@@ -456,7 +959,7 @@ func (c *Command) handleEvent(ctx context.Context, e *eventsv1.Event) {
 		if logs != "" {
 			msg := fmt.Sprintf("Encountered an issue deploying Airbyte:\n  Pod: %s\n  Reason: %s\n  Message: %s\n  Count: %d\n  Logs: %s",
 				e.Name, e.Reason, e.Note, e.DeprecatedCount, strings.TrimSpace(logs))
-			pterm.Debug.Println(msg)
+			logging.Debugf("%s", msg)
 			// only show the warning if the count is higher than 5
 			if e.DeprecatedCount > 5 {
 				pterm.Warning.Printfln(msg)
@@ -464,7 +967,7 @@ func (c *Command) handleEvent(ctx context.Context, e *eventsv1.Event) {
 		} else {
 			msg := fmt.Sprintf("Encountered an issue deploying Airbyte:\n  Pod: %s\n  Reason: %s\n  Message: %s\n  Count: %d",
 				e.Name, e.Reason, e.Note, e.DeprecatedCount)
-			pterm.Debug.Printfln(msg)
+			logging.Debugf(msg)
 			// only show the warning if the count is higher than 5
 			if e.DeprecatedCount > 5 {
 				pterm.Warning.Printfln(msg)
@@ -472,7 +975,27 @@ func (c *Command) handleEvent(ctx context.Context, e *eventsv1.Event) {
 		}
 
 	default:
-		pterm.Debug.Printfln("Received an unsupported event type: %s", e.Type)
+		logging.Debugf("Received an unsupported event type: %s", e.Type)
+	}
+}
+
+// recordEvent persists the event to the eventsFile, if it is interesting.
+func (c *Command) recordEvent(e *eventsv1.Event) {
+	event := events.Event{
+		Time:      e.DeprecatedLastTimestamp.Time,
+		Severity:  events.Severity(e.Type),
+		Reason:    e.Reason,
+		Object:    fmt.Sprintf("%s/%s", e.Regarding.Kind, e.Regarding.Name),
+		Namespace: e.Regarding.Namespace,
+		Message:   redact.String(e.Note),
+		Count:     e.DeprecatedCount,
+	}
+	if !events.Interesting(event) {
+		return
+	}
+
+	if err := events.Append(c.eventsFile, event); err != nil {
+		logging.Debugf("Unable to record event: %s", err)
 	}
 }
 
@@ -487,8 +1010,10 @@ func (c *Command) handleBasicAuthSecret(ctx context.Context, user, pass string)
 		return fmt.Errorf("could not hash basic auth password: %w", err)
 	}
 
-	data := map[string][]byte{"auth": []byte(fmt.Sprintf("%s:%s", user, hashedPass))}
-	if err := c.k8s.SecretCreateOrUpdate(ctx, airbyteNamespace, "basic-auth", data); err != nil {
+	// nginx reads the credentials from the auth key, traefik from the users key
+	auth := []byte(fmt.Sprintf("%s:%s", user, hashedPass))
+	data := map[string][]byte{"auth": auth, "users": auth}
+	if err := c.k8s.SecretCreateOrUpdate(ctx, c.namespace, basicAuthSecret, data); err != nil {
 		pterm.Error.Println("Could not create Basic-Auth secret")
 	}
 	pterm.Success.Println("Basic-Auth secret created")
@@ -500,13 +1025,30 @@ type UninstallOpts struct {
 }
 
 // Uninstall handles the uninstallation of Airbyte.
-func (c *Command) Uninstall(_ context.Context, opts UninstallOpts) error {
+// Airbyte installed into any namespace other than the DefaultNamespace may share the cluster with other
+// installations, so its helm releases, namespace, and persistent volumes are removed, instead of the cluster.
+func (c *Command) Uninstall(ctx context.Context, opts UninstallOpts) error {
+	if err := c.uninstallManifests(ctx); err != nil {
+		pterm.Error.Println("Unable to delete the extra manifests")
+		return fmt.Errorf("could not delete extra manifests: %w", err)
+	}
+
+	dirs := []string{paths.Data}
+	if c.namespace != DefaultNamespace {
+		if err := c.uninstallNamespace(ctx); err != nil {
+			return err
+		}
+		dirs = []string{filepath.Join(paths.Data, c.volumeName(pvMinio)), filepath.Join(paths.Data, c.volumeName(pvPsql))}
+	}
+
 	// check if persisted data should be removed, if not this is a noop
 	if opts.Persisted {
 		c.spinner.UpdateText("Removing persisted data")
-		if err := os.RemoveAll(paths.Data); err != nil {
-			pterm.Error.Println(fmt.Sprintf("Unable to remove persisted data '%s'", paths.Data))
-			return fmt.Errorf("could not remove persisted data '%s': %w", paths.Data, err)
+		for _, dir := range dirs {
+			if err := os.RemoveAll(dir); err != nil {
+				pterm.Error.Println(fmt.Sprintf("Unable to remove persisted data '%s'", dir))
+				return fmt.Errorf("could not remove persisted data '%s': %w", dir, err)
+			}
 		}
 		pterm.Success.Println("Removed persisted data")
 	}
@@ -514,16 +1056,60 @@ func (c *Command) Uninstall(_ context.Context, opts UninstallOpts) error {
 	return nil
 }
 
+// uninstallNamespace removes the helm releases, namespace, and persistent volumes of the Airbyte installation
+// within the namespace.
+func (c *Command) uninstallNamespace(ctx context.Context) error {
+	for _, name := range []string{airbyteChartRelease, monitoringChartRelease, logsChartRelease} {
+		// the monitoring and log aggregation releases are optional
+		if _, err := c.helm.GetRelease(name); err != nil {
+			logging.Debugf("could not get %s release: %s", name, err)
+			continue
+		}
+
+		c.spinner.UpdateText(fmt.Sprintf("Uninstalling Helm release '%s' from namespace '%s'", name, c.namespace))
+		if err := c.helm.UninstallReleaseByName(name); err != nil {
+			pterm.Error.Printfln("Unable to uninstall Helm release '%s'", name)
+			return fmt.Errorf("could not uninstall release %s: %w", name, err)
+		}
+		pterm.Success.Printfln("Uninstalled Helm release '%s'", name)
+	}
+
+	if c.k8s.NamespaceExists(ctx, c.namespace) {
+		c.spinner.UpdateText(fmt.Sprintf("Deleting namespace '%s'", c.namespace))
+		if err := c.k8s.NamespaceDelete(ctx, c.namespace); err != nil {
+			pterm.Error.Printfln("Unable to delete namespace '%s'", c.namespace)
+			return fmt.Errorf("could not delete namespace %s: %w", c.namespace, err)
+		}
+		pterm.Success.Printfln("Deleted namespace '%s'", c.namespace)
+	}
+
+	for _, pv := range []string{c.volumeName(pvMinio), c.volumeName(pvPsql)} {
+		if !c.k8s.PersistentVolumeExists(ctx, c.namespace, pv) {
+			continue
+		}
+		if err := c.k8s.PersistentVolumeDelete(ctx, c.namespace, pv); err != nil {
+			pterm.Error.Printfln("Unable to delete persistent volume '%s'", pv)
+			return fmt.Errorf("could not delete persistent volume %s: %w", pv, err)
+		}
+		pterm.Success.Printfln("Deleted persistent volume '%s'", pv)
+	}
+
+	return nil
+}
+
 // Status handles the status of local Airbyte.
-func (c *Command) Status(_ context.Context) error {
-	charts := []string{airbyteChartRelease, nginxChartRelease}
+func (c *Command) Status(ctx context.Context) error {
+	charts := []string{airbyteChartRelease}
+	if release, _ := ingressControllerRelease(c.ingressController); release != "" {
+		charts = append(charts, release)
+	}
 	for _, name := range charts {
 		c.spinner.UpdateText(fmt.Sprintf("Verifying %s Helm Chart installation status", name))
 
-		rel, err := c.helm.GetRelease(name)
+		rel, err := c.releaseHelm(name).GetRelease(name)
 		if err != nil {
 			pterm.Warning.Println("Could not get airbyte release")
-			pterm.Debug.Printfln("could not get airbyte release: %s", err)
+			logging.Debugf("could not get airbyte release: %s", err)
 			continue
 		}
 
@@ -533,7 +1119,23 @@ func (c *Command) Status(_ context.Context) error {
 		))
 	}
 
-	pterm.Info.Println(fmt.Sprintf("Airbyte should be accessible via http://localhost:%d", c.portHTTP))
+	c.spinner.UpdateText("Verifying Airbyte readiness")
+	for _, res := range readiness.Run(ctx, c.readinessChecks(c.host, "", "")...) {
+		if res.Err != nil {
+			pterm.Warning.Printfln("Not ready: %s\n  %s", res.Name, res.Err)
+			continue
+		}
+		pterm.Success.Printfln("Ready: %s", res.Name)
+	}
+
+	if c.ingressController == IngressNone {
+		c.printIngressInstructions()
+		return nil
+	}
+	pterm.Info.Printfln("Airbyte should be accessible via %s", baseURL(c.host, c.portHTTP))
+	if c.apiPort != 0 {
+		pterm.Info.Printfln("The Airbyte api should be accessible via %s", c.apiURL(c.host))
+	}
 
 	return nil
 }
@@ -549,6 +1151,15 @@ type chartRequest struct {
 	namespace    string
 	values       []string
 	valuesYAML   string
+	download     *DownloadOpts
+	// provenance, if true, verifies the provenance of the downloaded chart with the download keyring.
+	provenance bool
+	// reuseValues, if true, merges the values with the values of the existing release instead of replacing them.
+	reuseValues bool
+	// loc, if not a ChartLocRepo, is the location the chart is installed from instead of the helm repository.
+	loc ChartLoc
+	// auth are the credentials of the oci registry of the loc.
+	auth RegistryAuth
 }
 
 // handleChart will handle the installation of a chart
@@ -556,29 +1167,95 @@ func (c *Command) handleChart(
 	ctx context.Context,
 	req chartRequest,
 ) error {
-	c.spinner.UpdateText(fmt.Sprintf("Configuring %s Helm repository", req.name))
-
-	if err := c.helm.AddOrUpdateChartRepo(repo.Entry{
-		Name: req.repoName,
-		URL:  req.repoURL,
-	}); err != nil {
-		pterm.Error.Printfln("Unable to configure %s Helm repository", req.repoName)
-		return fmt.Errorf("could not add %s chart repo: %w", req.name, err)
+	p, err := c.prepareRelease(ctx, req)
+	if err != nil {
+		return err
 	}
 
-	c.spinner.UpdateText(fmt.Sprintf("Fetching %s Helm Chart", req.chartName))
-	helmChart, _, err := c.helm.GetChart(req.chartName, &action.ChartPathOptions{Version: req.chartVersion})
+	c.spinner.UpdateText(p.installing())
+	rel, err := c.installRelease(ctx, p, progress.Spinner(c.spinner, p.installing()))
+	return printRelease(p, rel, err)
+}
+
+// preparedChart is a chart prepared by prepareRelease, which is ready to be installed by installRelease.
+type preparedChart struct {
+	req       chartRequest
+	chartName string
+	chart     *chart.Chart
+}
+
+// installing describes the installation of the chart.
+func (p preparedChart) installing() string {
+	return fmt.Sprintf("Installing '%s' (version: %s) Helm Chart", p.req.chartName, p.chart.Metadata.Version)
+}
+
+// prepareRelease prepares the chart of the req and validates its values, displaying how the values of the existing
+// release will change. Charts installed concurrently are prepared one after the other beforehand, so their output
+// isn't interleaved.
+func (c *Command) prepareRelease(ctx context.Context, req chartRequest) (preparedChart, error) {
+	chartName, helmChart, err := c.prepareChart(ctx, req)
 	if err != nil {
-		pterm.Error.Printfln("Unable to fetch %s Helm Chart", req.chartName)
-		return fmt.Errorf("could not fetch chart %s: %w", req.chartName, err)
+		return preparedChart{}, err
 	}
 
-	c.tel.Attr(fmt.Sprintf("helm_%s_chart_version", req.name), helmChart.Metadata.Version)
+	// invalid values are reported before installing, instead of failing to render, or once helm times out waiting
+	var managed []byte
+	if req.chartRelease == airbyteChartRelease {
+		managed = []byte(managedValuesSchema)
+	}
+	if err := ValidateValues(helmChart, req.valuesYAML, req.values, managed); err != nil {
+		pterm.Error.Printfln("Invalid values of the %s Helm Chart", req.chartName)
+		return preparedChart{}, err
+	}
+	c.printValuesDiff(req, helmChart)
+
+	return preparedChart{req: req, chartName: chartName, chart: helmChart}, nil
+}
+
+// installRelease installs, or upgrades, the release of the prepared chart, waiting for it to become ready while its
+// progress is reported to report. Nothing else is displayed, so charts stored by different helm clients (see
+// releaseHelm) can be installed concurrently, each with a Reporter of its own, see printRelease for displaying the
+// result.
+func (c *Command) installRelease(ctx context.Context, p preparedChart, report progress.Reporter) (rel *release.Release, err error) {
+	req := p.req
+	ctx, span := trace.NewSpan(ctx, fmt.Sprintf("helm %s", req.name))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	span.SetAttr("chart_version", p.chart.Metadata.Version)
+
+	done := shutdown.Track(
+		p.installing(),
+		fmt.Sprintf("The Helm release '%s' will be rolled back, or uninstalled if it was being installed for the first time.", req.chartRelease),
+		// otherwise the release is left in a pending state, failing any operation until it is recovered
+		func(ctx context.Context) error {
+			return c.recoverRelease(req.chartRelease, false)
+		},
+	)
+	defer done()
+
+	// helm waits for the release to become ready, which takes many minutes while the images are pulled and the
+	// bootloader migrates the databases
+	podsCtx, podsCancel := context.WithCancel(ctx)
+	podsDone := make(chan struct{})
+	reporters := progress.Join(report, 2)
+	go func() {
+		defer close(podsDone)
+		c.reportPods(podsCtx, req.namespace, reporters[0])
+	}()
+	if req.chartRelease == airbyteChartRelease {
+		migrationsDone := make(chan struct{})
+		go func() {
+			defer close(migrationsDone)
+			c.reportMigrations(podsCtx, req.namespace, reporters[1])
+		}()
+		defer func() { <-migrationsDone }()
+	}
 
-	c.spinner.UpdateText(fmt.Sprintf("Installing '%s' (version: %s) Helm Chart", req.chartName, helmChart.Metadata.Version))
-	helmRelease, err := c.helm.InstallOrUpgradeChart(ctx, &helmclient.ChartSpec{
+	helmRelease, err := c.releaseHelm(req.chartRelease).InstallOrUpgradeChart(ctx, &helmclient.ChartSpec{
 		ReleaseName:     req.chartRelease,
-		ChartName:       req.chartName,
+		ChartName:       p.chartName,
 		CreateNamespace: true,
 		Namespace:       req.namespace,
 		Wait:            true,
@@ -586,72 +1263,186 @@ func (c *Command) handleChart(
 		ValuesOptions:   values.Options{Values: req.values},
 		ValuesYaml:      req.valuesYAML,
 		Version:         req.chartVersion,
+		ReuseValues:     req.reuseValues,
 	},
 		&helmclient.GenericHelmOptions{},
 	)
+	podsCancel()
+	<-podsDone
 	if err != nil {
-		pterm.Error.Printfln("Failed to install %s Helm Chart", req.chartName)
-		return fmt.Errorf("could not install helm: %w", err)
+		return nil, fmt.Errorf("could not install helm: %w", err)
 	}
 
 	c.tel.Attr(fmt.Sprintf("helm_%s_release_version", req.name), strconv.Itoa(helmRelease.Version))
+	return helmRelease, nil
+}
+
+// chartReporter returns the Reporter of the installation of the chart, as a part of the progress of the charts
+// installed concurrently, e.g. "airbyte 1.0.0: 3/12 pods ready".
+func chartReporter(p preparedChart, report progress.Reporter) progress.Reporter {
+	name := fmt.Sprintf("%s %s", p.req.chartName, p.chart.Metadata.Version)
+	report(name)
+	return func(status string) {
+		if status == "" {
+			report(name)
+			return
+		}
+		report(name + ": " + status)
+	}
+}
+
+// printRelease displays the result of installRelease, returning its error.
+func printRelease(p preparedChart, rel *release.Release, err error) error {
+	if err != nil {
+		pterm.Error.Printfln("Failed to install %s Helm Chart", p.req.chartName)
+		return err
+	}
 
 	pterm.Success.Printfln(
 		"Installed Helm Chart %s:\n  Name: %s\n  Namespace: %s\n  Version: %s\n  Release: %d",
-		req.chartName, helmRelease.Name, helmRelease.Namespace, helmRelease.Chart.Metadata.Version, helmRelease.Version)
+		p.req.chartName, rel.Name, rel.Namespace, rel.Chart.Metadata.Version, rel.Version)
 	return nil
 }
 
-// openBrowser will open the url in the user's browser but only if the url returns a 200 response code first
-// TODO: clean up this method, make it testable
-func (c *Command) openBrowser(ctx context.Context, url string) error {
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
+// prepareChart fetches the chart from its location, or else configures the helm repository of the chart, or downloads
+// it, and fetches it, returning the name of the chart to install. Any release of the chart stuck in a pending state is
+// recovered, so it can be installed.
+func (c *Command) prepareChart(ctx context.Context, req chartRequest) (chartName string, helmChart *chart.Chart, err error) {
+	if req.loc.Kind != "" && req.loc.Kind != ChartLocRepo {
+		chartName, helmChart, err = c.locateChart(req)
+	} else {
+		chartName, helmChart, err = c.fetchRepoChart(ctx, req)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	c.tel.Attr(fmt.Sprintf("helm_%s_chart_version", req.name), helmChart.Metadata.Version)
 
-	alive := make(chan error)
+	if err := c.recoverRelease(req.chartRelease, true); err != nil {
+		return "", nil, err
+	}
 
-	go func() {
-		tick := time.Tick(1 * time.Second)
-		for {
-			select {
-			case <-ctx.Done():
-				alive <- fmt.Errorf("liveness check failed: %w", ctx.Err())
-			case <-tick:
-				req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-				if err != nil {
-					alive <- fmt.Errorf("could not create request: %w", err)
-				}
-				res, _ := c.http.Do(req)
-				// if no auth, we should get a 200
-				if res != nil && res.StatusCode == http.StatusOK {
-					alive <- nil
-				}
-				// if basic auth, we should get a 401 with a specific header that contains abctl
-				if res != nil && res.StatusCode == http.StatusUnauthorized && strings.Contains(res.Header.Get("WWW-Authenticate"), "abctl") {
-					alive <- nil
-				}
+	return chartName, helmChart, nil
+}
+
+// fetchRepoChart configures the helm repository of the chart, or downloads it, and fetches it, returning the name of
+// the chart to install.
+func (c *Command) fetchRepoChart(ctx context.Context, req chartRequest) (chartName string, helmChart *chart.Chart, err error) {
+	// chartName is the name of the chart to install, this will be the path to the chart archive if it was downloaded.
+	// A downloaded chart is installed from its archive, which doesn't require the helm repository, whose index would
+	// otherwise be downloaded a second time.
+	chartName = req.chartName
+	if req.download != nil {
+		if chartName, err = c.downloadChart(ctx, req, *req.download); err != nil {
+			pterm.Error.Printfln("Unable to download %s Helm Chart", req.chartName)
+			return "", nil, err
+		}
+	} else {
+		c.spinner.UpdateText(fmt.Sprintf("Configuring %s Helm repository", req.name))
+		if err := c.helm.AddOrUpdateChartRepo(repo.Entry{
+			Name:   req.repoName,
+			URL:    req.repoURL,
+			CAFile: c.caCert,
+		}); err != nil {
+			pterm.Error.Printfln("Unable to configure %s Helm repository", req.repoName)
+			return "", nil, fmt.Errorf("could not add %s chart repo: %w", req.name, err)
+		}
+	}
+
+	c.spinner.UpdateText(fmt.Sprintf("Fetching %s Helm Chart", req.chartName))
+	helmChart, _, err = c.helm.GetChart(chartName, &action.ChartPathOptions{Version: req.chartVersion, CaFile: c.caCert})
+	if err != nil {
+		pterm.Error.Printfln("Unable to fetch %s Helm Chart", req.chartName)
+		return "", nil, fmt.Errorf("could not fetch chart %s: %w", req.chartName, err)
+	}
+
+	return chartName, helmChart, nil
+}
+
+// locateChart fetches the chart of the location of the request, returning the path of the chart to install.
+// A chart of an oci registry is pulled into the download directory.
+func (c *Command) locateChart(req chartRequest) (string, *chart.Chart, error) {
+	dir := filepath.Join(paths.AbCtl, "charts")
+	if req.download != nil {
+		dir = req.download.Dir
+	}
+
+	c.spinner.UpdateText(fmt.Sprintf("Fetching %s Helm Chart from %s", req.name, req.loc))
+	helmChart, chartPath, err := locateChart(c.helm, req.loc, req.chartVersion, req.auth, c.caCert, dir)
+	if err != nil {
+		pterm.Error.Printfln("Unable to fetch %s Helm Chart from %s", req.name, req.loc)
+		return "", nil, err
+	}
+	return chartPath, helmChart, nil
+}
+
+// podsReportInterval is how often the pods are listed by reportPods, defined here for testing purposes.
+var podsReportInterval = 5 * time.Second
+
+// reportPods reports how many pods of the namespace are ready, and the estimated remaining time, until ctx is done.
+// The progress is reported with an empty status once ctx is done.
+func (c *Command) reportPods(ctx context.Context, namespace string, report progress.Reporter) {
+	tracker := progress.NewTracker(progress.Pods)
+	ticker := time.NewTicker(podsReportInterval)
+	defer ticker.Stop()
+	defer report("")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// both may be ready, in which case either could be selected
+			if ctx.Err() != nil {
+				return
 			}
 		}
-	}()
 
-	select {
-	case <-ctx.Done():
-		pterm.Error.Println("Timed out waiting for ingress")
-		return fmt.Errorf("browser liveness check failed: %w", ctx.Err())
-	case err := <-alive:
+		pods, err := c.k8s.PodList(ctx, namespace)
 		if err != nil {
-			pterm.Error.Println("Ingress verification failed")
-			return fmt.Errorf("browser failed liveness check: %w", err)
+			logging.Debugf("could not list pods in namespace %s: %s", namespace, err)
+			continue
 		}
+		if len(pods.Items) == 0 {
+			continue
+		}
+		tracker.Update(int64(readiness.PodsReady(pods.Items)), int64(len(pods.Items)))
+		report(tracker.String())
+	}
+}
+
+// openBrowser will open the url in the user's browser but only if the url is being served by the ingress first
+func (c *Command) openBrowser(ctx context.Context, host, path string) (err error) {
+	ctx, span := trace.NewSpan(ctx, "ingress verify")
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	url := baseURL(host, c.portHTTP) + path
+	if err := readiness.Wait(waitCtx, time.Second, readiness.Ingress(c.http, url)); err != nil {
+		pterm.Error.Println("Timed out waiting for ingress")
+		return fmt.Errorf("browser failed liveness check: %w", err)
 	}
 	// if we're here, then no errors occurred
 
+	// the ingress is verified on this machine regardless of the host, which the web-browser must resolve however
+	if _, err := net.DefaultResolver.LookupHost(waitCtx, host); err != nil {
+		pterm.Warning.Printfln("The host '%s' does not resolve on this machine, add it to your hosts file or DNS to access %s", host, url)
+		logging.Debugf("could not resolve host %s: %s", host, err)
+		return nil
+	}
+
 	c.spinner.UpdateText(fmt.Sprintf("Attempting to launch web-browser for %s", url))
 
 	if err := c.launcher(url); err != nil {
 		pterm.Warning.Printfln("Failed to launch web-browser.\n"+
 			"Please launch your web-browser to access %s", url)
-		pterm.Debug.Printfln("failed to launch web-browser: %s", err.Error())
+		logging.Debugf("failed to launch web-browser: %s", err.Error())
 		// don't consider a failed web-browser to be a failed installation
 	}
 
@@ -660,39 +1451,10 @@ func (c *Command) openBrowser(ctx context.Context, url string) error {
 	return nil
 }
 
-// defaultK8s returns the default k8s client
-func defaultK8s(kubecfg, kubectx string) (k8s.Client, error) {
-	k8sCfg, err := k8sClientConfig(kubecfg, kubectx)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w", localerr.ErrKubernetes, err)
-	}
-
-	restCfg, err := k8sCfg.ClientConfig()
-	if err != nil {
-		return nil, fmt.Errorf("%w: could not create rest config: %w", localerr.ErrKubernetes, err)
-	}
-	k8sClient, err := kubernetes.NewForConfig(restCfg)
-	if err != nil {
-		return nil, fmt.Errorf("%w: could not create clientset: %w", localerr.ErrKubernetes, err)
-	}
-
-	return &k8s.DefaultK8sClient{ClientSet: k8sClient}, nil
-}
-
-// defaultHelm returns the default helm client
-func defaultHelm(kubecfg, kubectx string) (HelmClient, error) {
-	k8sCfg, err := k8sClientConfig(kubecfg, kubectx)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w", localerr.ErrKubernetes, err)
-	}
-
-	restCfg, err := k8sCfg.ClientConfig()
-	if err != nil {
-		return nil, fmt.Errorf("%w: could not create rest config: %w", localerr.ErrKubernetes, err)
-	}
-
+// defaultHelm returns the default helm client of the rest config
+func defaultHelm(restCfg *rest.Config, namespace string) (HelmClient, error) {
 	helm, err := helmclient.NewClientFromRestConf(&helmclient.RestConfClientOptions{
-		Options:    &helmclient.Options{Namespace: airbyteNamespace, Output: &noopWriter{}, DebugLog: func(format string, v ...interface{}) {}},
+		Options:    &helmclient.Options{Namespace: namespace, Output: &noopWriter{}, DebugLog: logging.Tracef},
 		RestConfig: restCfg,
 	})
 	if err != nil {
@@ -702,14 +1464,6 @@ func defaultHelm(kubecfg, kubectx string) (HelmClient, error) {
 	return helm, nil
 }
 
-// k8sClientConfig returns a k8s client config using the ~/.kubc/config file and the k8sContext context.
-func k8sClientConfig(kubecfg, kubectx string) (clientcmd.ClientConfig, error) {
-	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubecfg},
-		&clientcmd.ConfigOverrides{CurrentContext: kubectx},
-	), nil
-}
-
 // noopWriter is used by the helm client to suppress its verbose output
 type noopWriter struct {
 }
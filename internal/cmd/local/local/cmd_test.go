@@ -4,21 +4,32 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/events"
 	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
 	"github.com/airbytehq/abctl/internal/telemetry"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/uuid"
 	helmclient "github.com/mittwald/go-helm-client"
 	"github.com/mittwald/go-helm-client/values"
+	"golang.org/x/crypto/bcrypt"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"io"
+	appsv1 "k8s.io/api/apps/v1"
 	coreV1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/watch"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -26,24 +37,21 @@ import (
 const portTest = 9999
 
 func TestCommand_Install(t *testing.T) {
-	expChartRepoCnt := 0
-	expChartRepo := []struct {
-		name string
-		url  string
-	}{
-		{name: airbyteRepoName, url: airbyteRepoURL},
-		{name: nginxRepoName, url: nginxRepoURL},
+	// the charts are installed concurrently, in no particular order
+	expChartRepo := map[string]string{
+		airbyteRepoName: airbyteRepoURL,
+		nginxRepoName:   nginxRepoURL,
 	}
+	chartRepos := map[string]string{}
 
 	// userID is for telemetry tracking purposes
 	userID := uuid.New()
 
-	expChartCnt := 0
-	expChart := []struct {
+	expChart := map[string]struct {
 		chart   helmclient.ChartSpec
 		release release.Release
 	}{
-		{
+		airbyteChartRelease: {
 			chart: helmclient.ChartSpec{
 				ReleaseName:     airbyteChartRelease,
 				ChartName:       airbyteChartName,
@@ -62,7 +70,7 @@ func TestCommand_Install(t *testing.T) {
 				Version:   0,
 			},
 		},
-		{
+		nginxChartRelease: {
 			chart: helmclient.ChartSpec{
 				ReleaseName:     nginxChartRelease,
 				ChartName:       nginxChartName,
@@ -82,15 +90,7 @@ func TestCommand_Install(t *testing.T) {
 	}
 	helm := mockHelmClient{
 		addOrUpdateChartRepo: func(entry repo.Entry) error {
-			if d := cmp.Diff(expChartRepo[expChartRepoCnt].name, entry.Name); d != "" {
-				t.Error("chart name mismatch", d)
-			}
-			if d := cmp.Diff(expChartRepo[expChartRepoCnt].url, entry.URL); d != "" {
-				t.Error("chart url mismatch", d)
-			}
-
-			expChartRepoCnt++
-
+			chartRepos[entry.Name] = entry.URL
 			return nil
 		},
 
@@ -107,17 +107,21 @@ func TestCommand_Install(t *testing.T) {
 		},
 
 		installOrUpgradeChart: func(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
-			if d := cmp.Diff(&expChart[expChartCnt].chart, spec); d != "" {
+			exp, ok := expChart[spec.ReleaseName]
+			if !ok {
+				t.Error("unexpected release", spec.ReleaseName)
+				return nil, errors.New("unexpected release")
+			}
+			if d := cmp.Diff(&exp.chart, spec); d != "" {
 				t.Error("chart mismatch", d)
 			}
 
-			defer func() { expChartCnt++ }()
-
-			return &expChart[expChartCnt].release, nil
+			return &exp.release, nil
 		},
 	}
 
 	k8sClient := mockK8sClient{
+		podList: readyPods,
 		serverVersionGet: func() (string, error) {
 			return "test", nil
 		},
@@ -128,6 +132,13 @@ func TestCommand_Install(t *testing.T) {
 			return false
 		},
 		ingressCreate: func(ctx context.Context, namespace string, ingress *networkingv1.Ingress) error {
+			var hosts []string
+			for _, rule := range ingress.Spec.Rules {
+				hosts = append(hosts, rule.Host)
+			}
+			if d := cmp.Diff([]string{DefaultHost}, hosts); d != "" {
+				t.Error("ingress hosts mismatch (-want +got):", d)
+			}
 			return nil
 		},
 	}
@@ -138,9 +149,7 @@ func TestCommand_Install(t *testing.T) {
 		user: func() uuid.UUID { return userID },
 	}
 
-	httpClient := mockHTTP{do: func(req *http.Request) (*http.Response, error) {
-		return &http.Response{StatusCode: 200}, nil
-	}}
+	httpClient := mockHTTP{do: readyResponse}
 
 	c, err := New(
 		k8s.TestProvider,
@@ -161,27 +170,147 @@ func TestCommand_Install(t *testing.T) {
 	if err := c.Install(context.Background(), InstallOpts{User: "user", Pass: "pass"}); err != nil {
 		t.Fatal(err)
 	}
+
+	if d := cmp.Diff(expChartRepo, chartRepos); d != "" {
+		t.Error("chart repos mismatch (-want +got):", d)
+	}
 }
 
-func TestCommand_Install_ValuesFile(t *testing.T) {
-	expChartRepoCnt := 0
-	expChartRepo := []struct {
-		name string
-		url  string
+func TestCommand_Install_StorageClass(t *testing.T) {
+	// created is a volume or claim created during the installation
+	type created struct {
+		name         string
+		volumeName   string
+		storageClass string
+	}
+
+	tests := []struct {
+		name                string
+		defaultStorageClass bool
+		storageClass        string
+		expVolumes          []created
+		expClaims           []created
 	}{
-		{name: airbyteRepoName, url: airbyteRepoURL},
-		{name: nginxRepoName, url: nginxRepoURL},
+		{
+			name:       "kind",
+			expVolumes: []created{{name: pvMinio, storageClass: "standard"}, {name: pvPsql, storageClass: "standard"}},
+			expClaims: []created{
+				{name: pvcMinio, volumeName: pvMinio, storageClass: "standard"},
+				{name: pvcPsql, volumeName: pvPsql, storageClass: "standard"},
+			},
+		},
+		{
+			name:         "kind with storage class",
+			storageClass: "fast",
+			expVolumes:   []created{{name: pvMinio, storageClass: "fast"}, {name: pvPsql, storageClass: "fast"}},
+			expClaims: []created{
+				{name: pvcMinio, volumeName: pvMinio, storageClass: "fast"},
+				{name: pvcPsql, volumeName: pvPsql, storageClass: "fast"},
+			},
+		},
+		{
+			// the persistent volumes and claims must be provisioned by the default storage class, not abctl
+			name:                "existing cluster",
+			defaultStorageClass: true,
+		},
+		{
+			name:                "existing cluster with storage class",
+			defaultStorageClass: true,
+			storageClass:        "gp3",
+			expClaims:           []created{{name: pvcMinio, storageClass: "gp3"}, {name: pvcPsql, storageClass: "gp3"}},
+		},
 	}
 
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helm := mockHelmClient{
+				addOrUpdateChartRepo: func(entry repo.Entry) error {
+					return nil
+				},
+				getChart: func(name string, _ *action.ChartPathOptions) (*chart.Chart, string, error) {
+					return &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, "", nil
+				},
+				installOrUpgradeChart: func(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+					return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, Name: spec.ReleaseName}, nil
+				},
+			}
+
+			var volumes, claims []created
+			k8sClient := mockK8sClient{
+				podList: readyPods,
+				serverVersionGet: func() (string, error) {
+					return "test", nil
+				},
+				persistentVolumeExists: func(ctx context.Context, namespace, name string) bool {
+					return false
+				},
+				persistentVolumeCreate: func(ctx context.Context, namespace, name, storageClass string) error {
+					volumes = append(volumes, created{name: name, storageClass: storageClass})
+					return nil
+				},
+				persistentVolumeClaimExists: func(ctx context.Context, namespace, name, volumeName string) bool {
+					return false
+				},
+				persistentVolumeClaimCreate: func(ctx context.Context, namespace, name, volumeName, storageClass string) error {
+					claims = append(claims, created{name: name, volumeName: volumeName, storageClass: storageClass})
+					return nil
+				},
+			}
+
+			httpClient := mockHTTP{do: readyResponse}
+
+			tel := mockTelemetryClient{
+				user: func() uuid.UUID { return uuid.New() },
+			}
+
+			provider := k8s.TestProvider
+			provider.DefaultStorageClass = tt.defaultStorageClass
+
+			c, err := New(
+				provider,
+				WithPortHTTP(portTest),
+				WithHelmClient(&helm),
+				WithK8sClient(&k8sClient),
+				WithTelemetryClient(&tel),
+				WithHTTPClient(&httpClient),
+				WithBrowserLauncher(func(url string) error {
+					return nil
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := c.Install(context.Background(), InstallOpts{User: "user", Pass: "pass", StorageClass: tt.storageClass}); err != nil {
+				t.Fatal(err)
+			}
+
+			if d := cmp.Diff(tt.expVolumes, volumes, cmp.AllowUnexported(created{})); d != "" {
+				t.Error("volumes mismatch (-want +got):", d)
+			}
+			if d := cmp.Diff(tt.expClaims, claims, cmp.AllowUnexported(created{})); d != "" {
+				t.Error("claims mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestCommand_Install_ValuesFile(t *testing.T) {
+	// the charts are installed concurrently, in no particular order
+	expChartRepo := map[string]string{
+		airbyteRepoName: airbyteRepoURL,
+		nginxRepoName:   nginxRepoURL,
+	}
+	chartRepos := map[string]string{}
+
 	// userID is for telemetry tracking purposes
 	userID := uuid.New()
 
-	expChartCnt := 0
-	expChart := []struct {
+	expChart := map[string]struct {
 		chart   helmclient.ChartSpec
 		release release.Release
 	}{
-		{
+		airbyteChartRelease: {
 			chart: helmclient.ChartSpec{
 				ReleaseName:     airbyteChartRelease,
 				ChartName:       airbyteChartName,
@@ -199,7 +328,7 @@ func TestCommand_Install_ValuesFile(t *testing.T) {
 				Version:   0,
 			},
 		},
-		{
+		nginxChartRelease: {
 			chart: helmclient.ChartSpec{
 				ReleaseName:     nginxChartRelease,
 				ChartName:       nginxChartName,
@@ -219,15 +348,7 @@ func TestCommand_Install_ValuesFile(t *testing.T) {
 	}
 	helm := mockHelmClient{
 		addOrUpdateChartRepo: func(entry repo.Entry) error {
-			if d := cmp.Diff(expChartRepo[expChartRepoCnt].name, entry.Name); d != "" {
-				t.Error("chart name mismatch", d)
-			}
-			if d := cmp.Diff(expChartRepo[expChartRepoCnt].url, entry.URL); d != "" {
-				t.Error("chart url mismatch", d)
-			}
-
-			expChartRepoCnt++
-
+			chartRepos[entry.Name] = entry.URL
 			return nil
 		},
 
@@ -244,17 +365,21 @@ func TestCommand_Install_ValuesFile(t *testing.T) {
 		},
 
 		installOrUpgradeChart: func(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
-			if d := cmp.Diff(&expChart[expChartCnt].chart, spec); d != "" {
+			exp, ok := expChart[spec.ReleaseName]
+			if !ok {
+				t.Error("unexpected release", spec.ReleaseName)
+				return nil, errors.New("unexpected release")
+			}
+			if d := cmp.Diff(&exp.chart, spec); d != "" {
 				t.Error("chart mismatch", d)
 			}
 
-			defer func() { expChartCnt++ }()
-
-			return &expChart[expChartCnt].release, nil
+			return &exp.release, nil
 		},
 	}
 
 	k8sClient := mockK8sClient{
+		podList: readyPods,
 		serverVersionGet: func() (string, error) {
 			return "test", nil
 		},
@@ -275,9 +400,7 @@ func TestCommand_Install_ValuesFile(t *testing.T) {
 		user: func() uuid.UUID { return userID },
 	}
 
-	httpClient := mockHTTP{do: func(req *http.Request) (*http.Response, error) {
-		return &http.Response{StatusCode: 200}, nil
-	}}
+	httpClient := mockHTTP{do: readyResponse}
 
 	c, err := New(
 		k8s.TestProvider,
@@ -298,6 +421,155 @@ func TestCommand_Install_ValuesFile(t *testing.T) {
 	if err := c.Install(context.Background(), InstallOpts{User: "user", Pass: "pass", ValuesFile: "testdata/values.yml"}); err != nil {
 		t.Fatal(err)
 	}
+
+	if d := cmp.Diff(expChartRepo, chartRepos); d != "" {
+		t.Error("chart repos mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_Install_ChartFailure(t *testing.T) {
+	var installed []string
+	helm := mockHelmClient{
+		addOrUpdateChartRepo: func(entry repo.Entry) error {
+			return nil
+		},
+		getChart: func(name string, _ *action.ChartPathOptions) (*chart.Chart, string, error) {
+			return &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, "", nil
+		},
+		installOrUpgradeChart: func(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+			if spec.ReleaseName == airbyteChartRelease {
+				return nil, errors.New("test error")
+			}
+			installed = append(installed, spec.ReleaseName)
+			return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, Name: spec.ReleaseName}, nil
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithHelmClient(&helm),
+		WithK8sClient(&mockK8sClient{podList: readyPods}),
+		WithTelemetryClient(&mockTelemetryClient{user: func() uuid.UUID { return uuid.Nil }}),
+		WithHTTPClient(&mockHTTP{do: readyResponse}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Install(context.Background(), InstallOpts{User: "user", Pass: "pass"})
+	if err == nil || !strings.Contains(err.Error(), "could not install airbyte chart") {
+		t.Error("expected the airbyte chart to fail the installation, got", err)
+	}
+	// the ingress controller is installed regardless, as it is installed concurrently
+	if d := cmp.Diff([]string{nginxChartRelease}, installed); d != "" {
+		t.Error("installed mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_Install_IngressHelmClient(t *testing.T) {
+	// installed records the releases installed by each helm client, as a helm client is not safe for concurrent use,
+	// and the ingress controller release is stored apart from the namespace of the installation
+	installed := map[string][]string{}
+	var lock sync.Mutex
+	var created []string
+	helmClient := func(name string) *mockHelmClient {
+		return &mockHelmClient{
+			addOrUpdateChartRepo: func(entry repo.Entry) error {
+				return nil
+			},
+			getChart: func(chartName string, _ *action.ChartPathOptions) (*chart.Chart, string, error) {
+				return &chart.Chart{Metadata: &chart.Metadata{Name: chartName, Version: "test.version"}}, "", nil
+			},
+			installOrUpgradeChart: func(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+				lock.Lock()
+				installed[name] = append(installed[name], spec.ReleaseName)
+				lock.Unlock()
+				return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, Name: spec.ReleaseName}, nil
+			},
+		}
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithNamespace("team-b"),
+		WithHelmClient(helmClient("default")),
+		WithIngressHelmClient(helmClient("ingress")),
+		WithK8sClient(&mockK8sClient{
+			podList: readyPods,
+			namespaceExists: func(_ context.Context, namespace string) bool {
+				return namespace != airbyteNamespace
+			},
+			namespaceCreate: func(_ context.Context, namespace string) error {
+				created = append(created, namespace)
+				return nil
+			},
+		}),
+		WithTelemetryClient(&mockTelemetryClient{user: func() uuid.UUID { return uuid.Nil }}),
+		WithHTTPClient(&mockHTTP{do: readyResponse}),
+		WithBrowserLauncher(func(url string) error {
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Install(context.Background(), InstallOpts{User: "user", Pass: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := map[string][]string{
+		"default": {airbyteChartRelease},
+		"ingress": {nginxChartRelease},
+	}
+	if d := cmp.Diff(exp, installed); d != "" {
+		t.Error("installed mismatch (-want +got):", d)
+	}
+	// the namespace storing the ingress controller release is created, if missing
+	if d := cmp.Diff([]string{airbyteNamespace}, created); d != "" {
+		t.Error("created namespaces mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_Install_InvalidValues(t *testing.T) {
+	valuesFile := filepath.Join(t.TempDir(), "values.yml")
+	if err := os.WriteFile(valuesFile, []byte("global:\n  edition: 1\n"), 0644); err != nil {
+		t.Fatal("could not write values file", err)
+	}
+
+	helm := mockHelmClient{
+		addOrUpdateChartRepo: func(entry repo.Entry) error {
+			return nil
+		},
+		getChart: func(name string, _ *action.ChartPathOptions) (*chart.Chart, string, error) {
+			return &chart.Chart{Metadata: &chart.Metadata{Name: name, Version: "test.version"}}, "", nil
+		},
+		installOrUpgradeChart: func(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+			if spec.ReleaseName == airbyteChartRelease {
+				t.Error("expected the airbyte chart not to be installed")
+			}
+			return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, Name: spec.ReleaseName}, nil
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithHelmClient(&helm),
+		WithK8sClient(&mockK8sClient{podList: readyPods}),
+		WithTelemetryClient(&mockTelemetryClient{user: func() uuid.UUID { return uuid.Nil }}),
+		WithHTTPClient(&mockHTTP{do: readyResponse}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Install(context.Background(), InstallOpts{User: "user", Pass: "pass", ValuesFile: valuesFile})
+	if err == nil || !strings.Contains(err.Error(), "global.edition") {
+		t.Error("expected the invalid value to fail the installation, got", err)
+	}
 }
 
 func TestCommand_Install_InvalidValuesFile(t *testing.T) {
@@ -329,36 +601,397 @@ func TestCommand_Install_InvalidValuesFile(t *testing.T) {
 
 }
 
+func TestCommand_Install_SecretReferences(t *testing.T) {
+	valuesFile := filepath.Join(t.TempDir(), "values.yml")
+	if err := os.WriteFile(valuesFile, []byte("global:\n  database:\n    password: vault://secret/data/airbyte#db-password\n"), 0644); err != nil {
+		t.Fatal("could not write values file", err)
+	}
+
+	var (
+		valuesYAML string
+		auth       []byte
+	)
+	helm := mockHelmClient{
+		addOrUpdateChartRepo: func(entry repo.Entry) error {
+			return nil
+		},
+		getChart: func(name string, _ *action.ChartPathOptions) (*chart.Chart, string, error) {
+			return &chart.Chart{Metadata: &chart.Metadata{Name: name, Version: "test.version"}}, "", nil
+		},
+		installOrUpgradeChart: func(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+			if spec.ReleaseName == airbyteChartRelease {
+				valuesYAML = spec.ValuesYaml
+			}
+			return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, Name: spec.ReleaseName}, nil
+		},
+	}
+
+	k8sClient := mockK8sClient{
+		podList: readyPods,
+		secretCreateOrUpdate: func(ctx context.Context, namespace, name string, data map[string][]byte) error {
+			if name == basicAuthSecret {
+				auth = data["auth"]
+			}
+			return nil
+		},
+	}
+
+	resolver := mockSecretResolver{secrets: map[string]string{
+		"vault://secret/data/airbyte#db-password": "s3cr3t",
+		"aws-sm://airbyte/basic-auth#password":    "p4ss",
+	}}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithHelmClient(&helm),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{user: func() uuid.UUID { return uuid.Nil }}),
+		WithHTTPClient(&mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+			if _, pass, ok := req.BasicAuth(); ok && pass != "p4ss" {
+				t.Error("expected the api to be accessed with the resolved password, got", pass)
+			}
+			return readyResponse(req)
+		}}),
+		WithSecretResolver(&resolver),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Install(context.Background(), InstallOpts{User: "user", Pass: "aws-sm://airbyte/basic-auth#password", ValuesFile: valuesFile}); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if d := cmp.Diff("global:\n  database:\n    password: s3cr3t\n", valuesYAML); d != "" {
+		t.Error("values mismatch (-want +got):", d)
+	}
+	user, hash, _ := strings.Cut(string(auth), ":")
+	if user != "user" || bcrypt.CompareHashAndPassword([]byte(hash), []byte("p4ss")) != nil {
+		t.Error("expected the basic-auth secret to contain the resolved password, got", string(auth))
+	}
+}
+
+func TestCommand_Install_Namespace(t *testing.T) {
+	const namespace = "airbyte-team"
+	var (
+		namespaces []string
+		volumes    []string
+		claims     []string
+		releases   = map[string]string{}
+		ingresses  []string
+		secrets    []string
+	)
+
+	helm := mockHelmClient{
+		addOrUpdateChartRepo: func(entry repo.Entry) error {
+			return nil
+		},
+		getChart: func(name string, _ *action.ChartPathOptions) (*chart.Chart, string, error) {
+			return &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, "", nil
+		},
+		installOrUpgradeChart: func(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+			releases[spec.ReleaseName] = spec.Namespace
+			return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, Name: spec.ReleaseName}, nil
+		},
+	}
+
+	k8sClient := mockK8sClient{
+		podList: readyPods,
+		serverVersionGet: func() (string, error) {
+			return "test", nil
+		},
+		namespaceExists: func(ctx context.Context, namespace string) bool {
+			return false
+		},
+		namespaceCreate: func(ctx context.Context, namespace string) error {
+			namespaces = append(namespaces, namespace)
+			return nil
+		},
+		persistentVolumeExists: func(ctx context.Context, namespace, name string) bool {
+			return false
+		},
+		persistentVolumeCreate: func(ctx context.Context, namespace, name, storageClass string) error {
+			volumes = append(volumes, name)
+			return nil
+		},
+		persistentVolumeClaimExists: func(ctx context.Context, namespace, name, volumeName string) bool {
+			return false
+		},
+		persistentVolumeClaimCreate: func(ctx context.Context, namespace, name, volumeName, storageClass string) error {
+			claims = append(claims, fmt.Sprintf("%s/%s:%s", namespace, name, volumeName))
+			return nil
+		},
+		ingressExists: func(ctx context.Context, namespace string, ingress string) bool {
+			return false
+		},
+		ingressCreate: func(ctx context.Context, namespace string, ingress *networkingv1.Ingress) error {
+			ingresses = append(ingresses, fmt.Sprintf("%s/%s", namespace, ingress.Namespace))
+			return nil
+		},
+		secretCreateOrUpdate: func(ctx context.Context, namespace, name string, data map[string][]byte) error {
+			secrets = append(secrets, fmt.Sprintf("%s/%s", namespace, name))
+			return nil
+		},
+	}
+
+	httpClient := mockHTTP{do: readyResponse}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithHelmClient(&helm),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{user: func() uuid.UUID { return uuid.Nil }}),
+		WithHTTPClient(&httpClient),
+		WithBrowserLauncher(func(url string) error {
+			return nil
+		}),
+		WithNamespace(namespace),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Install(context.Background(), InstallOpts{User: "user", Pass: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the namespace storing the release of the ingress controller is created as well
+	if d := cmp.Diff([]string{namespace, airbyteNamespace}, namespaces); d != "" {
+		t.Error("namespaces mismatch (-want +got):", d)
+	}
+	// the cluster scoped volumes must not conflict with the volumes of other installations
+	if d := cmp.Diff([]string{namespace + "-" + pvMinio, namespace + "-" + pvPsql}, volumes); d != "" {
+		t.Error("volumes mismatch (-want +got):", d)
+	}
+	expClaims := []string{
+		fmt.Sprintf("%s/%s:%s-%s", namespace, pvcMinio, namespace, pvMinio),
+		fmt.Sprintf("%s/%s:%s-%s", namespace, pvcPsql, namespace, pvPsql),
+	}
+	if d := cmp.Diff(expClaims, claims); d != "" {
+		t.Error("claims mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff(map[string]string{airbyteChartRelease: namespace, nginxChartRelease: nginxNamespace}, releases); d != "" {
+		t.Error("releases mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff([]string{namespace + "/" + namespace}, ingresses); d != "" {
+		t.Error("ingresses mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff([]string{namespace + "/basic-auth"}, secrets); d != "" {
+		t.Error("secrets mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_Uninstall_Namespace(t *testing.T) {
+	const namespace = "airbyte-team"
+	var (
+		uninstalled []string
+		deleted     []string
+	)
+
+	helm := mockHelmClient{
+		getRelease: func(name string) (*release.Release, error) {
+			if name != airbyteChartRelease {
+				return nil, errors.New("release: not found")
+			}
+			return &release.Release{Name: name}, nil
+		},
+		uninstallReleaseByName: func(name string) error {
+			uninstalled = append(uninstalled, name)
+			return nil
+		},
+	}
+
+	k8sClient := mockK8sClient{
+		serverVersionGet: func() (string, error) {
+			return "test", nil
+		},
+		namespaceDelete: func(ctx context.Context, namespace string) error {
+			deleted = append(deleted, "namespace/"+namespace)
+			return nil
+		},
+		persistentVolumeDelete: func(ctx context.Context, namespace, name string) error {
+			deleted = append(deleted, "pv/"+name)
+			return nil
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&helm),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithNamespace(namespace),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Uninstall(context.Background(), UninstallOpts{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if d := cmp.Diff([]string{airbyteChartRelease}, uninstalled); d != "" {
+		t.Error("uninstalled mismatch (-want +got):", d)
+	}
+	exp := []string{"namespace/" + namespace, "pv/" + namespace + "-" + pvMinio, "pv/" + namespace + "-" + pvPsql}
+	if d := cmp.Diff(exp, deleted); d != "" {
+		t.Error("deleted mismatch (-want +got):", d)
+	}
+}
+
 // ---
 // only mocks below here
 // ---
 var _ HelmClient = (*mockHelmClient)(nil)
 
+func TestCommand_HandleEvent_Record(t *testing.T) {
+	eventsFile := filepath.Join(t.TempDir(), "events.jsonl")
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&mockK8sClient{
+			logsGet: func(ctx context.Context, namespace string, name string) (string, error) {
+				return "", errors.New("no logs")
+			},
+		}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithEventsFile(eventsFile),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := metav1.NewTime(time.Now().Add(time.Hour).UTC().Truncate(time.Second))
+	for _, e := range []eventsv1.Event{
+		{Type: "Warning", Reason: "BackOff", Note: "Back-off restarting failed container", DeprecatedCount: 3},
+		{Type: "Normal", Reason: "Pulling", Note: "Pulling image airbyte/server"},
+		{Type: "Normal", Reason: "Scheduled", Note: "Successfully assigned airbyte-abctl/server"},
+	} {
+		e.Regarding = coreV1.ObjectReference{Kind: "Pod", Namespace: airbyteNamespace, Name: "airbyte-abctl-server"}
+		e.DeprecatedLastTimestamp = at
+		c.handleEvent(context.Background(), &e)
+	}
+
+	recorded, err := events.Load(eventsFile)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	exp := []events.Event{
+		{Time: at.Time, Severity: events.SeverityWarning, Reason: "BackOff", Object: "Pod/airbyte-abctl-server", Namespace: airbyteNamespace, Message: "Back-off restarting failed container", Count: 3},
+		{Time: at.Time, Severity: events.SeverityNormal, Reason: "Pulling", Object: "Pod/airbyte-abctl-server", Namespace: airbyteNamespace, Message: "Pulling image airbyte/server"},
+	}
+	if d := cmp.Diff(exp, recorded); d != "" {
+		t.Error("events mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_ReportPods(t *testing.T) {
+	origInterval := podsReportInterval
+	podsReportInterval = time.Millisecond
+	t.Cleanup(func() { podsReportInterval = origInterval })
+
+	running := func(name string, ready bool) coreV1.Pod {
+		return coreV1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: coreV1.PodStatus{
+				Phase:             coreV1.PodRunning,
+				ContainerStatuses: []coreV1.ContainerStatus{{Ready: ready}},
+			},
+		}
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&mockK8sClient{
+			podList: func(ctx context.Context, namespace string) (*coreV1.PodList, error) {
+				if d := cmp.Diff(airbyteNamespace, namespace); d != "" {
+					t.Error("namespace mismatch (-want +got):", d)
+				}
+				return &coreV1.PodList{Items: []coreV1.Pod{
+					running("server", true),
+					running("worker", false),
+					{Status: coreV1.PodStatus{Phase: coreV1.PodSucceeded}},
+				}}, nil
+			},
+		}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var reports []string
+	c.reportPods(ctx, airbyteNamespace, func(status string) {
+		reports = append(reports, status)
+		cancel()
+	})
+
+	if d := cmp.Diff([]string{"2/3 pods ready", ""}, reports); d != "" {
+		t.Error("reports mismatch (-want +got):", d)
+	}
+}
+
 type mockHelmClient struct {
 	addOrUpdateChartRepo   func(entry repo.Entry) error
 	getChart               func(string, *action.ChartPathOptions) (*chart.Chart, string, error)
 	getRelease             func(name string) (*release.Release, error)
 	installOrUpgradeChart  func(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error)
+	listReleaseHistory     func(name string, max int) ([]*release.Release, error)
+	rollbackRelease        func(spec *helmclient.ChartSpec) error
 	uninstallReleaseByName func(s string) error
+	// lock serializes the calls, as charts are installed concurrently
+	lock sync.Mutex
 }
 
 func (m *mockHelmClient) AddOrUpdateChartRepo(entry repo.Entry) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	return m.addOrUpdateChartRepo(entry)
 }
 
 func (m *mockHelmClient) GetChart(s string, options *action.ChartPathOptions) (*chart.Chart, string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	return m.getChart(s, options)
 }
 
 func (m *mockHelmClient) GetRelease(name string) (*release.Release, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.getRelease == nil {
+		return nil, driver.ErrReleaseNotFound
+	}
 	return m.getRelease(name)
 }
 
 func (m *mockHelmClient) InstallOrUpgradeChart(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	return m.installOrUpgradeChart(ctx, spec, opts)
 }
 
+func (m *mockHelmClient) ListReleaseHistory(name string, max int) ([]*release.Release, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.listReleaseHistory == nil {
+		return nil, driver.ErrReleaseNotFound
+	}
+	return m.listReleaseHistory(name, max)
+}
+
+func (m *mockHelmClient) RollbackRelease(spec *helmclient.ChartSpec) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.rollbackRelease(spec)
+}
+
 func (m *mockHelmClient) UninstallReleaseByName(s string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	return m.uninstallReleaseByName(s)
 }
 
@@ -366,22 +999,43 @@ var _ k8s.Client = (*mockK8sClient)(nil)
 
 type mockK8sClient struct {
 	ingressCreate               func(ctx context.Context, namespace string, ingress *networkingv1.Ingress) error
+	ingressGet                  func(ctx context.Context, namespace string, ingress string) (*networkingv1.Ingress, error)
 	ingressExists               func(ctx context.Context, namespace string, ingress string) bool
 	ingressUpdate               func(ctx context.Context, namespace string, ingress *networkingv1.Ingress) error
+	ingressClassList            func(ctx context.Context) (*networkingv1.IngressClassList, error)
 	namespaceCreate             func(ctx context.Context, namespace string) error
 	namespaceExists             func(ctx context.Context, namespace string) bool
+	namespaceList               func(ctx context.Context) ([]string, error)
 	namespaceDelete             func(ctx context.Context, namespace string) error
-	persistentVolumeCreate      func(ctx context.Context, namespace, name string) error
+	persistentVolumeCreate      func(ctx context.Context, namespace, name, storageClass string) error
 	persistentVolumeExists      func(ctx context.Context, namespace, name string) bool
 	persistentVolumeDelete      func(ctx context.Context, namespace, name string) error
-	persistentVolumeClaimCreate func(ctx context.Context, namespace, name, volumeName string) error
+	persistentVolumeList        func(ctx context.Context) (*coreV1.PersistentVolumeList, error)
+	persistentVolumeClaimCreate func(ctx context.Context, namespace, name, volumeName, storageClass string) error
 	persistentVolumeClaimExists func(ctx context.Context, namespace, name, volumeName string) bool
 	persistentVolumeClaimDelete func(ctx context.Context, namespace, name, volumeName string) error
 	secretCreateOrUpdate        func(ctx context.Context, namespace, name string, data map[string][]byte) error
+	secretGet                   func(ctx context.Context, namespace, name string) (*coreV1.Secret, error)
+	secretList                  func(ctx context.Context, namespace, selector string) (*coreV1.SecretList, error)
+	secretDelete                func(ctx context.Context, namespace, name string) error
 	serviceGet                  func(ctx context.Context, namespace, name string) (*coreV1.Service, error)
+	serviceProxyGet             func(ctx context.Context, namespace, name, port, path string, params map[string]string) ([]byte, error)
 	serverVersionGet            func() (string, error)
+	permissionsDenied           func(ctx context.Context, namespace string, permissions []k8s.Permission) ([]k8s.Permission, error)
+	manifestApply               func(ctx context.Context, namespace string, obj *unstructured.Unstructured) error
+	manifestDelete              func(ctx context.Context, obj *unstructured.Unstructured) error
 	eventsWatch                 func(ctx context.Context, namespace string) (watch.Interface, error)
 	logsGet                     func(ctx context.Context, namespace string, name string) (string, error)
+	eventsList                  func(ctx context.Context, namespace string) (*eventsv1.EventList, error)
+	podList                     func(ctx context.Context, namespace string) (*coreV1.PodList, error)
+	logsFollow                  func(ctx context.Context, namespace, name string) (io.ReadCloser, error)
+	podExec                     func(ctx context.Context, namespace, name string, cmd []string, stdout io.Writer) error
+	podExecStreams              func(ctx context.Context, namespace, name string, cmd []string, streams k8s.ExecStreams) error
+	deploymentList              func(ctx context.Context, namespace string) (*appsv1.DeploymentList, error)
+	deploymentScale             func(ctx context.Context, namespace, name string, replicas int32) error
+	deploymentRestart           func(ctx context.Context, namespace, name string) error
+	statefulSetList             func(ctx context.Context, namespace string) (*appsv1.StatefulSetList, error)
+	statefulSetScale            func(ctx context.Context, namespace, name string, replicas int32) error
 }
 
 func (m *mockK8sClient) IngressCreate(ctx context.Context, namespace string, ingress *networkingv1.Ingress) error {
@@ -391,6 +1045,13 @@ func (m *mockK8sClient) IngressCreate(ctx context.Context, namespace string, ing
 	return nil
 }
 
+func (m *mockK8sClient) IngressGet(ctx context.Context, namespace string, ingress string) (*networkingv1.Ingress, error) {
+	if m.ingressGet != nil {
+		return m.ingressGet(ctx, namespace, ingress)
+	}
+	return nil, nil
+}
+
 func (m *mockK8sClient) IngressExists(ctx context.Context, namespace string, ingress string) bool {
 	if m.ingressExists != nil {
 		return m.ingressExists(ctx, namespace, ingress)
@@ -405,6 +1066,13 @@ func (m *mockK8sClient) IngressUpdate(ctx context.Context, namespace string, ing
 	return nil
 }
 
+func (m *mockK8sClient) IngressClassList(ctx context.Context) (*networkingv1.IngressClassList, error) {
+	if m.ingressClassList != nil {
+		return m.ingressClassList(ctx)
+	}
+	return &networkingv1.IngressClassList{}, nil
+}
+
 func (m *mockK8sClient) NamespaceCreate(ctx context.Context, namespace string) error {
 	if m.namespaceCreate != nil {
 		return m.namespaceCreate(ctx, namespace)
@@ -419,6 +1087,13 @@ func (m *mockK8sClient) NamespaceExists(ctx context.Context, namespace string) b
 	return true
 }
 
+func (m *mockK8sClient) NamespaceList(ctx context.Context) ([]string, error) {
+	if m.namespaceList == nil {
+		return nil, nil
+	}
+	return m.namespaceList(ctx)
+}
+
 func (m *mockK8sClient) NamespaceDelete(ctx context.Context, namespace string) error {
 	if m.namespaceDelete != nil {
 		return m.namespaceDelete(ctx, namespace)
@@ -426,9 +1101,9 @@ func (m *mockK8sClient) NamespaceDelete(ctx context.Context, namespace string) e
 	return nil
 }
 
-func (m *mockK8sClient) PersistentVolumeCreate(ctx context.Context, namespace, name string) error {
+func (m *mockK8sClient) PersistentVolumeCreate(ctx context.Context, namespace, name, storageClass string) error {
 	if m.persistentVolumeCreate != nil {
-		return m.persistentVolumeCreate(ctx, namespace, name)
+		return m.persistentVolumeCreate(ctx, namespace, name, storageClass)
 	}
 	return nil
 }
@@ -444,10 +1119,16 @@ func (m *mockK8sClient) PersistentVolumeDelete(ctx context.Context, namespace, n
 	}
 	return nil
 }
+func (m *mockK8sClient) PersistentVolumeList(ctx context.Context) (*coreV1.PersistentVolumeList, error) {
+	if m.persistentVolumeList != nil {
+		return m.persistentVolumeList(ctx)
+	}
+	return &coreV1.PersistentVolumeList{}, nil
+}
 
-func (m *mockK8sClient) PersistentVolumeClaimCreate(ctx context.Context, namespace, name, volumeName string) error {
+func (m *mockK8sClient) PersistentVolumeClaimCreate(ctx context.Context, namespace, name, volumeName, storageClass string) error {
 	if m.persistentVolumeClaimCreate != nil {
-		return m.persistentVolumeClaimCreate(ctx, namespace, name, volumeName)
+		return m.persistentVolumeClaimCreate(ctx, namespace, name, volumeName, storageClass)
 	}
 	return nil
 }
@@ -472,10 +1153,35 @@ func (m *mockK8sClient) SecretCreateOrUpdate(ctx context.Context, namespace, nam
 	return nil
 }
 
+func (m *mockK8sClient) SecretGet(ctx context.Context, namespace, name string) (*coreV1.Secret, error) {
+	if m.secretGet != nil {
+		return m.secretGet(ctx, namespace, name)
+	}
+	return nil, nil
+}
+
+func (m *mockK8sClient) SecretList(ctx context.Context, namespace, selector string) (*coreV1.SecretList, error) {
+	if m.secretList == nil {
+		return &coreV1.SecretList{}, nil
+	}
+	return m.secretList(ctx, namespace, selector)
+}
+
+func (m *mockK8sClient) SecretDelete(ctx context.Context, namespace, name string) error {
+	if m.secretDelete == nil {
+		return nil
+	}
+	return m.secretDelete(ctx, namespace, name)
+}
+
 func (m *mockK8sClient) ServiceGet(ctx context.Context, namespace, name string) (*coreV1.Service, error) {
 	return m.serviceGet(ctx, namespace, name)
 }
 
+func (m *mockK8sClient) ServiceProxyGet(ctx context.Context, namespace, name, port, path string, params map[string]string) ([]byte, error) {
+	return m.serviceProxyGet(ctx, namespace, name, port, path, params)
+}
+
 func (m *mockK8sClient) ServerVersionGet() (string, error) {
 	if m.serverVersionGet != nil {
 		return m.serverVersionGet()
@@ -483,6 +1189,27 @@ func (m *mockK8sClient) ServerVersionGet() (string, error) {
 	return "test", nil
 }
 
+func (m *mockK8sClient) PermissionsDenied(ctx context.Context, namespace string, permissions []k8s.Permission) ([]k8s.Permission, error) {
+	if m.permissionsDenied != nil {
+		return m.permissionsDenied(ctx, namespace, permissions)
+	}
+	return nil, nil
+}
+
+func (m *mockK8sClient) ManifestApply(ctx context.Context, namespace string, obj *unstructured.Unstructured) error {
+	if m.manifestApply != nil {
+		return m.manifestApply(ctx, namespace, obj)
+	}
+	return nil
+}
+
+func (m *mockK8sClient) ManifestDelete(ctx context.Context, obj *unstructured.Unstructured) error {
+	if m.manifestDelete != nil {
+		return m.manifestDelete(ctx, obj)
+	}
+	return nil
+}
+
 func (m *mockK8sClient) EventsWatch(ctx context.Context, namespace string) (watch.Interface, error) {
 	if m.eventsWatch == nil {
 		return watch.NewFake(), nil
@@ -497,6 +1224,76 @@ func (m *mockK8sClient) LogsGet(ctx context.Context, namespace string, name stri
 	return m.logsGet(ctx, namespace, name)
 }
 
+func (m *mockK8sClient) LogsFollow(ctx context.Context, namespace string, name string) (io.ReadCloser, error) {
+	if m.logsFollow == nil {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	return m.logsFollow(ctx, namespace, name)
+}
+
+func (m *mockK8sClient) EventsList(ctx context.Context, namespace string) (*eventsv1.EventList, error) {
+	if m.eventsList == nil {
+		return &eventsv1.EventList{}, nil
+	}
+	return m.eventsList(ctx, namespace)
+}
+
+func (m *mockK8sClient) PodList(ctx context.Context, namespace string) (*coreV1.PodList, error) {
+	if m.podList == nil {
+		return &coreV1.PodList{}, nil
+	}
+	return m.podList(ctx, namespace)
+}
+
+func (m *mockK8sClient) PodExec(ctx context.Context, namespace, name string, cmd []string, stdout io.Writer) error {
+	if m.podExec == nil {
+		return nil
+	}
+	return m.podExec(ctx, namespace, name, cmd, stdout)
+}
+
+func (m *mockK8sClient) PodExecStreams(ctx context.Context, namespace, name string, cmd []string, streams k8s.ExecStreams) error {
+	if m.podExecStreams == nil {
+		return nil
+	}
+	return m.podExecStreams(ctx, namespace, name, cmd, streams)
+}
+
+func (m *mockK8sClient) DeploymentList(ctx context.Context, namespace string) (*appsv1.DeploymentList, error) {
+	if m.deploymentList == nil {
+		return &appsv1.DeploymentList{}, nil
+	}
+	return m.deploymentList(ctx, namespace)
+}
+
+func (m *mockK8sClient) DeploymentScale(ctx context.Context, namespace, name string, replicas int32) error {
+	if m.deploymentScale == nil {
+		return nil
+	}
+	return m.deploymentScale(ctx, namespace, name, replicas)
+}
+
+func (m *mockK8sClient) DeploymentRestart(ctx context.Context, namespace, name string) error {
+	if m.deploymentRestart == nil {
+		return nil
+	}
+	return m.deploymentRestart(ctx, namespace, name)
+}
+
+func (m *mockK8sClient) StatefulSetList(ctx context.Context, namespace string) (*appsv1.StatefulSetList, error) {
+	if m.statefulSetList == nil {
+		return &appsv1.StatefulSetList{}, nil
+	}
+	return m.statefulSetList(ctx, namespace)
+}
+
+func (m *mockK8sClient) StatefulSetScale(ctx context.Context, namespace, name string, replicas int32) error {
+	if m.statefulSetScale == nil {
+		return nil
+	}
+	return m.statefulSetScale(ctx, namespace, name, replicas)
+}
+
 var _ telemetry.Client = (*mockTelemetryClient)(nil)
 
 type mockTelemetryClient struct {
@@ -505,6 +1302,8 @@ type mockTelemetryClient struct {
 	failure func(context.Context, telemetry.EventType, error) error
 	attr    func(key, val string)
 	user    func() uuid.UUID
+	// lock serializes the attrs, as charts are installed concurrently
+	lock sync.Mutex
 }
 
 func (m *mockTelemetryClient) Start(ctx context.Context, eventType telemetry.EventType) error {
@@ -520,6 +1319,8 @@ func (m *mockTelemetryClient) Failure(ctx context.Context, eventType telemetry.E
 }
 
 func (m *mockTelemetryClient) Attr(key, val string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	if m.attr != nil {
 		m.attr(key, val)
 	}
@@ -538,3 +1339,45 @@ type mockHTTP struct {
 func (m *mockHTTP) Do(req *http.Request) (*http.Response, error) {
 	return m.do(req)
 }
+
+// readyResponse responds as the ingress of a ready Airbyte installation, whose api health reports it is available.
+// mockSecretResolver resolves the references of its secrets, any other value is returned unchanged.
+type mockSecretResolver struct {
+	secrets map[string]string
+}
+
+func (m *mockSecretResolver) Resolve(_ context.Context, s string) (string, error) {
+	if value, ok := m.secrets[s]; ok {
+		return value, nil
+	}
+	if strings.Contains(s, "://") {
+		return "", fmt.Errorf("unknown secret %s", s)
+	}
+	return s, nil
+}
+
+func (m *mockSecretResolver) ResolveYAML(_ context.Context, doc string) (string, error) {
+	for ref, value := range m.secrets {
+		doc = strings.ReplaceAll(doc, ref, value)
+	}
+	return doc, nil
+}
+
+func readyResponse(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == "/api/v1/health" {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"available": true}`))}, nil
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+}
+
+// readyPods lists a ready pod of every component which is verified once Airbyte is installed.
+func readyPods(context.Context, string) (*coreV1.PodList, error) {
+	var pods []coreV1.Pod
+	for _, component := range []string{componentServer, componentTemporal, componentWebapp} {
+		pods = append(pods, coreV1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: componentPrefix(component) + "-0"},
+			Status:     coreV1.PodStatus{Phase: coreV1.PodRunning},
+		})
+	}
+	return &coreV1.PodList{Items: pods}, nil
+}
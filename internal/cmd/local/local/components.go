@@ -0,0 +1,69 @@
+package local
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ComponentMetrics is the component publishing the Airbyte metrics, which is required by the monitoring.
+const ComponentMetrics = "metrics"
+
+// optionalComponents are the components of Airbyte which can be disabled, and the airbyte chart values disabling them.
+var optionalComponents = map[string]string{
+	"connector-builder": "connector-builder-server.enabled=false",
+	"cron":              "cron.enabled=false",
+	ComponentMetrics:    "metrics.enabled=false",
+	"temporal-ui":       "temporal-ui.enabled=false",
+}
+
+// lowResourceComponents are the components disabled by the low resource preset.
+var lowResourceComponents = []string{"connector-builder", "temporal-ui"}
+
+// OptionalComponents returns the sorted names of the components of Airbyte which can be disabled.
+func OptionalComponents() []string {
+	names := make([]string, 0, len(optionalComponents))
+	for name := range optionalComponents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateDisabledComponents returns an error if any of the components cannot be disabled.
+func ValidateDisabledComponents(components []string) error {
+	for _, c := range components {
+		if _, ok := optionalComponents[c]; !ok {
+			return fmt.Errorf("invalid component %s, must be one of %s", c, strings.Join(OptionalComponents(), ", "))
+		}
+	}
+	return nil
+}
+
+// disabledValues returns the airbyte chart values disabling the components. The low resource preset additionally
+// disables the lowResourceComponents, and runs the connector jobs with the low resource variant of their resource
+// requirements.
+func disabledValues(components []string, lowResource bool) []string {
+	if lowResource {
+		components = append(components[:len(components):len(components)], lowResourceComponents...)
+	}
+
+	var values []string
+	seen := map[string]bool{}
+	for _, c := range components {
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		values = append(values, optionalComponents[c])
+	}
+
+	if lowResource {
+		values = append(values,
+			"server.env_vars.JOB_RESOURCE_VARIANT_OVERRIDE=lowresource",
+			"workload-launcher.env_vars.JOB_RESOURCE_VARIANT_OVERRIDE=lowresource",
+		)
+	}
+
+	return values
+}
@@ -0,0 +1,69 @@
+package local
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+func TestValidateDisabledComponents(t *testing.T) {
+	if err := ValidateDisabledComponents([]string{"connector-builder", "temporal-ui"}); err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	err := ValidateDisabledComponents([]string{"cron", "server"})
+	if err == nil || !strings.Contains(err.Error(), "invalid component server, must be one of connector-builder, cron, metrics, temporal-ui") {
+		t.Error("expected an invalid component error, got", err)
+	}
+}
+
+func TestDisabledValues(t *testing.T) {
+	tests := []struct {
+		name        string
+		components  []string
+		lowResource bool
+		exp         map[string]interface{}
+	}{
+		{
+			name: "none",
+			exp:  map[string]interface{}{},
+		},
+		{
+			name:       "components",
+			components: []string{"cron", "metrics"},
+			exp: map[string]interface{}{
+				"cron":    map[string]interface{}{"enabled": false},
+				"metrics": map[string]interface{}{"enabled": false},
+			},
+		},
+		{
+			name:        "low resource",
+			components:  []string{"temporal-ui", "cron"},
+			lowResource: true,
+			exp: map[string]interface{}{
+				"connector-builder-server": map[string]interface{}{"enabled": false},
+				"cron":                     map[string]interface{}{"enabled": false},
+				"temporal-ui":              map[string]interface{}{"enabled": false},
+				"server": map[string]interface{}{
+					"env_vars": map[string]interface{}{"JOB_RESOURCE_VARIANT_OVERRIDE": "lowresource"},
+				},
+				"workload-launcher": map[string]interface{}{
+					"env_vars": map[string]interface{}{"JOB_RESOURCE_VARIANT_OVERRIDE": "lowresource"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the values are set as helm --set values, so they are compared once merged
+			values, err := mergedValues("", disabledValues(tt.components, tt.lowResource))
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, values); d != "" {
+				t.Error("values mismatch (-want +got):", d)
+			}
+		})
+	}
+}
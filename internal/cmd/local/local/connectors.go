@@ -0,0 +1,205 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The kinds of connectors.
+const (
+	ConnectorSource      = "source"
+	ConnectorDestination = "destination"
+)
+
+// ValidateConnectorKind verifies the kind is either ConnectorSource or ConnectorDestination.
+func ValidateConnectorKind(kind string) error {
+	switch kind {
+	case ConnectorSource, ConnectorDestination:
+		return nil
+	default:
+		return fmt.Errorf("invalid connector kind %s, must be either %s or %s", kind, ConnectorSource, ConnectorDestination)
+	}
+}
+
+// ParseConnectorImage returns the docker repository and tag of the connector image, e.g. "airbyte/source-test" and
+// "dev" for "airbyte/source-test:dev".
+// An explicit tag other than "latest" is required, as kubernetes always pulls images tagged latest, which would replace
+// an image loaded into the cluster.
+func ParseConnectorImage(image string) (repository string, tag string, err error) {
+	i := strings.LastIndex(image, ":")
+	if i <= strings.LastIndex(image, "/") || i == len(image)-1 {
+		return "", "", fmt.Errorf("invalid connector image %s, must be repository:tag", image)
+	}
+	repository, tag = image[:i], image[i+1:]
+	if tag == "latest" {
+		return "", "", fmt.Errorf("invalid connector image %s, the tag latest is always pulled and cannot be used", image)
+	}
+	return repository, tag, nil
+}
+
+// APIOpts are the options for accessing the Airbyte api via the ingress.
+type APIOpts struct {
+	// Host is the ingress host, defaults to the host of the Command.
+	Host string
+	// User and Pass are the basic-auth credentials protecting the ingress.
+	User string
+	Pass string
+}
+
+// api returns the airbyteAPI accessed via the ingress of the host, which requires an ingress controller of abctl.
+func (c *Command) api(opts APIOpts) (airbyteAPI, error) {
+	if c.ingressController == IngressNone {
+		return airbyteAPI{}, fmt.Errorf("the Airbyte api is not accessible without an ingress controller, it was installed with %s", IngressNone)
+	}
+
+	host := opts.Host
+	if host == "" {
+		host = c.host
+	}
+	return airbyteAPI{http: c.http, baseURL: baseURL(host, c.portHTTP), user: opts.User, pass: opts.Pass}, nil
+}
+
+// workspaceAPI returns the airbyteAPI and the id of the default workspace.
+func (c *Command) workspaceAPI(ctx context.Context, opts APIOpts) (airbyteAPI, string, error) {
+	api, err := c.api(opts)
+	if err != nil {
+		return api, "", err
+	}
+	workspaceID, err := api.workspaceID(ctx, connectorRegistryRetryInterval)
+	if err != nil {
+		return api, "", fmt.Errorf("could not determine workspace: %w", err)
+	}
+	return api, workspaceID, nil
+}
+
+// Connector is a custom connector definition of the workspace.
+type Connector struct {
+	ConnectorDefinition
+	// Kind is either ConnectorSource or ConnectorDestination.
+	Kind string
+	// ID is the id of the definition.
+	ID string
+}
+
+// workspaceDefinition is a definition, of either kind, as returned by the list_for_workspace api.
+type workspaceDefinition struct {
+	ConnectorDefinition
+	SourceDefinitionID      string `json:"sourceDefinitionId"`
+	DestinationDefinitionID string `json:"destinationDefinitionId"`
+	Custom                  bool   `json:"custom"`
+}
+
+// customConnectors returns the custom definitions of the kind available to the workspace.
+func (a airbyteAPI) customConnectors(ctx context.Context, kind, workspaceID string) ([]Connector, error) {
+	var res map[string][]workspaceDefinition
+	if err := a.post(ctx, fmt.Sprintf("/api/v1/%s_definitions/list_for_workspace", kind), map[string]string{"workspaceId": workspaceID}, &res); err != nil {
+		return nil, fmt.Errorf("could not list %s definitions: %w", kind, err)
+	}
+
+	var connectors []Connector
+	for _, def := range res[kind+"Definitions"] {
+		if !def.Custom {
+			continue
+		}
+		id := def.SourceDefinitionID
+		if kind == ConnectorDestination {
+			id = def.DestinationDefinitionID
+		}
+		connectors = append(connectors, Connector{ConnectorDefinition: def.ConnectorDefinition, Kind: kind, ID: id})
+	}
+	return connectors, nil
+}
+
+// ListConnectors returns the custom source and destination connectors of the default workspace, the sources first,
+// each sorted by name.
+func (c *Command) ListConnectors(ctx context.Context, opts APIOpts) ([]Connector, error) {
+	api, workspaceID, err := c.workspaceAPI(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var connectors []Connector
+	for _, kind := range []string{ConnectorSource, ConnectorDestination} {
+		custom, err := api.customConnectors(ctx, kind, workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		connectors = append(connectors, custom...)
+	}
+
+	sort.SliceStable(connectors, func(i, j int) bool {
+		if connectors[i].Kind != connectors[j].Kind {
+			return connectors[i].Kind > connectors[j].Kind
+		}
+		return connectors[i].Name < connectors[j].Name
+	})
+	return connectors, nil
+}
+
+// AddConnector creates the custom connector of the kind in the default workspace.
+// If a custom connector of the kind with the same docker repository already exists, its tag is updated instead, so the
+// connector can be added again after each build of a new tag. Returns true if the connector was created.
+func (c *Command) AddConnector(ctx context.Context, opts APIOpts, kind string, def ConnectorDefinition) (bool, error) {
+	api, workspaceID, err := c.workspaceAPI(ctx, opts)
+	if err != nil {
+		return false, err
+	}
+
+	custom, err := api.customConnectors(ctx, kind, workspaceID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, existing := range custom {
+		if existing.DockerRepository != def.DockerRepository {
+			continue
+		}
+		req := map[string]string{
+			kind + "DefinitionId": existing.ID,
+			"dockerImageTag":      def.DockerImageTag,
+		}
+		if err := api.post(ctx, fmt.Sprintf("/api/v1/%s_definitions/update", kind), req, nil); err != nil {
+			return false, fmt.Errorf("could not update %s definition %s: %w", kind, existing.Name, err)
+		}
+		return false, nil
+	}
+
+	req := map[string]interface{}{
+		"workspaceId":       workspaceID,
+		kind + "Definition": def,
+	}
+	if err := api.post(ctx, fmt.Sprintf("/api/v1/%s_definitions/create_custom", kind), req, nil); err != nil {
+		return false, fmt.Errorf("could not create %s definition %s: %w", kind, def.Name, err)
+	}
+	return true, nil
+}
+
+// RemoveConnector deletes the custom connector of the kind from the default workspace, which is identified by either
+// its name or its docker repository. Returns the removed connector.
+// The connections using the connector are deleted by Airbyte as well.
+func (c *Command) RemoveConnector(ctx context.Context, opts APIOpts, kind, connector string) (Connector, error) {
+	api, workspaceID, err := c.workspaceAPI(ctx, opts)
+	if err != nil {
+		return Connector{}, err
+	}
+
+	custom, err := api.customConnectors(ctx, kind, workspaceID)
+	if err != nil {
+		return Connector{}, err
+	}
+
+	for _, existing := range custom {
+		if existing.Name != connector && existing.DockerRepository != connector {
+			continue
+		}
+		req := map[string]string{kind + "DefinitionId": existing.ID}
+		if err := api.post(ctx, fmt.Sprintf("/api/v1/%s_definitions/delete", kind), req, nil); err != nil {
+			return Connector{}, fmt.Errorf("could not delete %s definition %s: %w", kind, existing.Name, err)
+		}
+		return existing, nil
+	}
+
+	return Connector{}, fmt.Errorf("custom %s connector %s does not exist", kind, connector)
+}
@@ -0,0 +1,223 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseConnectorImage(t *testing.T) {
+	tests := []struct {
+		image string
+		repo  string
+		tag   string
+	}{
+		{image: "airbyte/source-test:dev", repo: "airbyte/source-test", tag: "dev"},
+		{image: "localhost:5000/source-test:0.1.0", repo: "localhost:5000/source-test", tag: "0.1.0"},
+	}
+	for _, tt := range tests {
+		repo, tag, err := ParseConnectorImage(tt.image)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %s", tt.image, err)
+			continue
+		}
+		if d := cmp.Diff([]string{tt.repo, tt.tag}, []string{repo, tag}); d != "" {
+			t.Errorf("image mismatch for %s (-want +got): %s", tt.image, d)
+		}
+	}
+
+	for _, image := range []string{"airbyte/source-test", "airbyte/source-test:", "localhost:5000/source-test", "airbyte/source-test:latest"} {
+		if _, _, err := ParseConnectorImage(image); err == nil {
+			t.Errorf("expected error for %s", image)
+		}
+	}
+}
+
+// connectorsHTTP returns the mockHTTP serving the custom definitions of the workspace, recording every definition
+// request to requests as "<path> <body>".
+func connectorsHTTP(requests *[]string) *mockHTTP {
+	return &mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+		respond := func(body string) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+		}
+
+		switch req.URL.Path {
+		case "/api/v1/workspaces/list":
+			return respond(`{"workspaces": [{"workspaceId": "ws"}]}`)
+		case "/api/v1/source_definitions/list_for_workspace":
+			return respond(`{"sourceDefinitions": [
+				{"sourceDefinitionId": "faker", "name": "Faker", "dockerRepository": "airbyte/source-faker", "dockerImageTag": "6.0.0"},
+				{"sourceDefinitionId": "s2", "name": "Zeta", "dockerRepository": "example/source-zeta", "dockerImageTag": "dev", "custom": true},
+				{"sourceDefinitionId": "s1", "name": "Alpha", "dockerRepository": "example/source-alpha", "dockerImageTag": "0.1.0", "custom": true}
+			]}`)
+		case "/api/v1/destination_definitions/list_for_workspace":
+			return respond(`{"destinationDefinitions": [
+				{"destinationDefinitionId": "d1", "name": "Warehouse", "dockerRepository": "example/destination-warehouse", "dockerImageTag": "dev", "custom": true}
+			]}`)
+		default:
+			body, _ := io.ReadAll(req.Body)
+			*requests = append(*requests, req.URL.Path+" "+string(body))
+			return respond("{}")
+		}
+	}}
+}
+
+func TestCommand_ListConnectors(t *testing.T) {
+	var requests []string
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&mockK8sClient{}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithHTTPClient(connectorsHTTP(&requests)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connectors, err := c.ListConnectors(context.Background(), APIOpts{User: "user", Pass: "pass"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := []Connector{
+		{Kind: ConnectorSource, ID: "s1", ConnectorDefinition: ConnectorDefinition{Name: "Alpha", DockerRepository: "example/source-alpha", DockerImageTag: "0.1.0"}},
+		{Kind: ConnectorSource, ID: "s2", ConnectorDefinition: ConnectorDefinition{Name: "Zeta", DockerRepository: "example/source-zeta", DockerImageTag: "dev"}},
+		{Kind: ConnectorDestination, ID: "d1", ConnectorDefinition: ConnectorDefinition{Name: "Warehouse", DockerRepository: "example/destination-warehouse", DockerImageTag: "dev"}},
+	}
+	if d := cmp.Diff(exp, connectors); d != "" {
+		t.Error("connectors mismatch (-want +got):", d)
+	}
+	if len(requests) > 0 {
+		t.Error("unexpected requests", requests)
+	}
+}
+
+func TestCommand_AddConnector(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		def     ConnectorDefinition
+		created bool
+		path    string
+		body    map[string]interface{}
+	}{
+		{
+			name:    "create",
+			kind:    ConnectorSource,
+			def:     ConnectorDefinition{Name: "Beta", DockerRepository: "example/source-beta", DockerImageTag: "dev"},
+			created: true,
+			path:    "/api/v1/source_definitions/create_custom",
+			body: map[string]interface{}{
+				"workspaceId": "ws",
+				"sourceDefinition": map[string]interface{}{
+					"name": "Beta", "dockerRepository": "example/source-beta", "dockerImageTag": "dev", "documentationUrl": "",
+				},
+			},
+		},
+		{
+			name: "update existing",
+			kind: ConnectorDestination,
+			def:  ConnectorDefinition{Name: "Warehouse", DockerRepository: "example/destination-warehouse", DockerImageTag: "dev2"},
+			path: "/api/v1/destination_definitions/update",
+			body: map[string]interface{}{"destinationDefinitionId": "d1", "dockerImageTag": "dev2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			c, err := New(
+				k8s.TestProvider,
+				WithHelmClient(&mockHelmClient{}),
+				WithK8sClient(&mockK8sClient{}),
+				WithTelemetryClient(&mockTelemetryClient{}),
+				WithHTTPClient(connectorsHTTP(&requests)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			created, err := c.AddConnector(context.Background(), APIOpts{User: "user", Pass: "pass"}, tt.kind, tt.def)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.created, created); d != "" {
+				t.Error("created mismatch (-want +got):", d)
+			}
+
+			if len(requests) != 1 {
+				t.Fatal("expected a single request, got", requests)
+			}
+			path, raw, _ := strings.Cut(requests[0], " ")
+			var body map[string]interface{}
+			if err := json.Unmarshal([]byte(raw), &body); err != nil {
+				t.Fatal("could not decode body", err)
+			}
+			if d := cmp.Diff(tt.path, path); d != "" {
+				t.Error("path mismatch (-want +got):", d)
+			}
+			if d := cmp.Diff(tt.body, body); d != "" {
+				t.Error("body mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestCommand_RemoveConnector(t *testing.T) {
+	var requests []string
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&mockK8sClient{}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithHTTPClient(connectorsHTTP(&requests)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := c.RemoveConnector(context.Background(), APIOpts{User: "user", Pass: "pass"}, ConnectorSource, "example/source-zeta")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff("Zeta", removed.Name); d != "" {
+		t.Error("removed mismatch (-want +got):", d)
+	}
+
+	// only custom connectors can be removed
+	if _, err := c.RemoveConnector(context.Background(), APIOpts{User: "user", Pass: "pass"}, ConnectorSource, "Faker"); err == nil {
+		t.Error("expected error removing a connector which is not custom")
+	}
+
+	exp := []string{`/api/v1/source_definitions/delete {"sourceDefinitionId":"s2"}`}
+	if d := cmp.Diff(exp, requests); d != "" {
+		t.Error("requests mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_Connectors_IngressNone(t *testing.T) {
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&mockK8sClient{}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithHTTPClient(&mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+			t.Error("unexpected request", req.URL.Path)
+			return nil, nil
+		}}),
+		WithIngressController(IngressNone),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.ListConnectors(context.Background(), APIOpts{}); err == nil {
+		t.Error("expected error without an ingress controller")
+	}
+}
@@ -0,0 +1,65 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// CredentialsSecret is the secret the airbyte chart generates on first install, containing the credentials of the
+// instance admin.
+const CredentialsSecret = "airbyte-auth-secrets"
+
+const (
+	credentialsKeyEmail        = "instance-admin-email"
+	credentialsKeyPassword     = "instance-admin-password"
+	credentialsKeyClientID     = "instance-admin-client-id"
+	credentialsKeyClientSecret = "instance-admin-client-secret"
+)
+
+// Credentials are the credentials of the instance admin generated by the airbyte chart.
+type Credentials struct {
+	// Email is the email of the instance admin, empty until it is set when first signing in.
+	Email string `json:"email,omitempty"`
+	// Password is the password of the instance admin.
+	Password string `json:"password"`
+	// ClientID and ClientSecret authenticate the instance admin to the api.
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// Masked returns the credentials with the password and client secret replaced by asterisks.
+func (c Credentials) Masked() Credentials {
+	mask := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return "********"
+	}
+	c.Password = mask(c.Password)
+	c.ClientSecret = mask(c.ClientSecret)
+	return c
+}
+
+// Credentials returns the instance admin credentials of the CredentialsSecret.
+// An error is returned if the airbyte chart generated no credentials, as older charts protect Airbyte by basic-auth.
+func (c *Command) Credentials(ctx context.Context) (Credentials, error) {
+	secret, err := c.k8s.SecretGet(ctx, c.namespace, CredentialsSecret)
+	if k8serrors.IsNotFound(err) || (err == nil && secret == nil) {
+		return Credentials{}, fmt.Errorf("secret %s not found, the airbyte chart of this installation generates no credentials", CredentialsSecret)
+	}
+	if err != nil {
+		return Credentials{}, fmt.Errorf("could not get secret %s: %w", CredentialsSecret, err)
+	}
+
+	creds := Credentials{
+		Email:        string(secret.Data[credentialsKeyEmail]),
+		Password:     string(secret.Data[credentialsKeyPassword]),
+		ClientID:     string(secret.Data[credentialsKeyClientID]),
+		ClientSecret: string(secret.Data[credentialsKeyClientSecret]),
+	}
+	if creds.Password == "" {
+		return Credentials{}, fmt.Errorf("secret %s contains no %s", CredentialsSecret, credentialsKeyPassword)
+	}
+	return creds, nil
+}
@@ -0,0 +1,82 @@
+package local
+
+import (
+	"context"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	coreV1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"strings"
+	"testing"
+)
+
+func TestCommand_Credentials(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret *coreV1.Secret
+		err    error
+		exp    Credentials
+		expErr string
+	}{
+		{
+			name: "generated",
+			secret: &coreV1.Secret{Data: map[string][]byte{
+				"instance-admin-password":      []byte("p4ss"),
+				"instance-admin-client-id":     []byte("client"),
+				"instance-admin-client-secret": []byte("s3cr3t"),
+			}},
+			exp: Credentials{Password: "p4ss", ClientID: "client", ClientSecret: "s3cr3t"},
+		},
+		{
+			name:   "not generated",
+			err:    k8serrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, CredentialsSecret),
+			expErr: "the airbyte chart of this installation generates no credentials",
+		},
+		{
+			name:   "no password",
+			secret: &coreV1.Secret{Data: map[string][]byte{"instance-admin-client-id": []byte("client")}},
+			expErr: "secret airbyte-auth-secrets contains no instance-admin-password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := mockK8sClient{
+				secretGet: func(ctx context.Context, namespace, name string) (*coreV1.Secret, error) {
+					if name != CredentialsSecret {
+						t.Error("unexpected secret", name)
+					}
+					return tt.secret, tt.err
+				},
+			}
+
+			c, err := New(
+				k8s.TestProvider,
+				WithHelmClient(&mockHelmClient{}),
+				WithK8sClient(&k8sClient),
+				WithTelemetryClient(&mockTelemetryClient{}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			creds, err := c.Credentials(context.Background())
+			if tt.expErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expErr) {
+					t.Errorf("expected error containing %q, got %v", tt.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, creds); d != "" {
+				t.Error("credentials mismatch (-want +got):", d)
+			}
+			if d := cmp.Diff(Credentials{Password: "********", ClientID: "client", ClientSecret: "********"}, creds.Masked()); d != "" {
+				t.Error("masked credentials mismatch (-want +got):", d)
+			}
+		})
+	}
+}
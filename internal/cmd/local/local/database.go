@@ -0,0 +1,151 @@
+package local
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/pterm/pterm"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// dbPod is the pod of the database stateful set the airbyte chart installs, unless an external database is used.
+	dbPod  = "airbyte-db-0"
+	dbUser = "airbyte"
+	dbName = "db-airbyte"
+)
+
+// dbSecret is the secret the airbyte chart generates with the credentials of the database.
+var dbSecret = airbyteChartRelease + "-airbyte-secrets"
+
+// pgCustomMagic is how a dump in the pg_dump custom format starts, which must be restored by pg_restore.
+const pgCustomMagic = "PGDMP"
+
+// podExists returns errNotFound if the pod does not exist in the namespace of the Command, e.g. localerr.ErrNoDatabase
+// for the database pod.
+func (c *Command) podExists(ctx context.Context, name string, errNotFound error) error {
+	pods, err := c.k8s.PodList(ctx, c.namespace)
+	if err != nil {
+		return fmt.Errorf("could not list pods: %w", err)
+	}
+	for _, pod := range pods.Items {
+		if pod.Name == name {
+			return nil
+		}
+	}
+	return errNotFound
+}
+
+// dbCredentials returns the credentials of the database of the generated secret. If the secret cannot be read the
+// default user without a password is returned, which is trusted within the pod.
+func (c *Command) dbCredentials(ctx context.Context) (user string, pass string) {
+	user = dbUser
+	secret, err := c.k8s.SecretGet(ctx, c.namespace, dbSecret)
+	switch {
+	case err != nil:
+		logging.Debugf("could not get secret %s: %s", dbSecret, err)
+	case secret != nil:
+		if v := string(secret.Data["DATABASE_USER"]); v != "" {
+			user = v
+		}
+		pass = string(secret.Data["DATABASE_PASSWORD"])
+	}
+	return user, pass
+}
+
+// dbCommand returns the postgres client command executed within the database pod, authenticated by the credentials
+// of the generated secret.
+func (c *Command) dbCommand(ctx context.Context, cmd ...string) []string {
+	user, pass := c.dbCredentials(ctx)
+	return append([]string{"env", "PGPASSWORD=" + pass, cmd[0], "-U", user, "-d", dbName}, cmd[1:]...)
+}
+
+// DatabaseShell runs an interactive psql session connected to the airbyte database, until stdin is exhausted or the
+// session is quit. If tty is true a terminal is allocated, which stdin and stdout must be connected to.
+func (c *Command) DatabaseShell(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, tty bool) error {
+	if err := c.podExists(ctx, dbPod, localerr.ErrNoDatabase); err != nil {
+		return err
+	}
+
+	streams := k8s.ExecStreams{Stdin: stdin, Stdout: stdout, Stderr: stderr, TTY: tty}
+	if err := c.k8s.PodExecStreams(ctx, c.namespace, dbPod, c.dbCommand(ctx, "psql"), streams); err != nil {
+		return fmt.Errorf("could not run psql: %w", err)
+	}
+	return nil
+}
+
+// DumpDatabase dumps the airbyte database as plain SQL into w, which can be restored by RestoreDatabase.
+// The dump drops every object before creating it again, so it can be restored into the existing database.
+func (c *Command) DumpDatabase(ctx context.Context, w io.Writer) error {
+	if err := c.podExists(ctx, dbPod, localerr.ErrNoDatabase); err != nil {
+		return err
+	}
+
+	c.spinner.UpdateText("Dumping the Airbyte database")
+	cmd := c.dbCommand(ctx, "pg_dump", "--format=plain", "--clean", "--if-exists")
+	if err := c.k8s.PodExecStreams(ctx, c.namespace, dbPod, cmd, k8s.ExecStreams{Stdout: w, Stderr: io.Discard}); err != nil {
+		return fmt.Errorf("could not dump database: %w", err)
+	}
+	return nil
+}
+
+// RestoreDatabase restores a dump of the airbyte database read from r, either a plain SQL dump of DumpDatabase or a
+// dump in the custom format of BackupDatabase. The restore is stopped at the first error.
+func (c *Command) RestoreDatabase(ctx context.Context, r io.Reader) error {
+	if err := c.podExists(ctx, dbPod, localerr.ErrNoDatabase); err != nil {
+		return err
+	}
+
+	buf := bufio.NewReader(r)
+	cmd := c.dbCommand(ctx, "psql", "--quiet", "--set=ON_ERROR_STOP=1")
+	if magic, _ := buf.Peek(len(pgCustomMagic)); string(magic) == pgCustomMagic {
+		cmd = c.dbCommand(ctx, "pg_restore", "--clean", "--if-exists", "--exit-on-error", "--no-owner")
+	}
+
+	c.spinner.UpdateText("Restoring the Airbyte database")
+	var stderr bytes.Buffer
+	if err := c.k8s.PodExecStreams(ctx, c.namespace, dbPod, cmd, k8s.ExecStreams{Stdin: buf, Stdout: io.Discard, Stderr: &stderr}); err != nil {
+		return fmt.Errorf("could not restore database: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// BackupDatabase dumps the airbyte database into the directory, returning the path of the dump.
+// The dump is in the pg_dump custom format and can be restored with pg_restore.
+func (c *Command) BackupDatabase(ctx context.Context, dir string) (string, error) {
+	if err := c.podExists(ctx, dbPod, localerr.ErrNoDatabase); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("could not create backup directory %s: %w", dir, err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.dump", c.namespace, time.Now().UTC().Format("20060102T150405Z")))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", fmt.Errorf("could not create backup %s: %w", path, err)
+	}
+
+	c.spinner.UpdateText("Backing up the Airbyte database")
+	err = c.k8s.PodExec(ctx, c.namespace, dbPod, []string{"pg_dump", "-U", dbUser, "-d", dbName, "--format=custom"}, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// a partial dump must not be mistaken for a usable backup
+		_ = os.Remove(path)
+		return "", fmt.Errorf("could not backup database: %w", err)
+	}
+
+	pterm.Success.Printfln("Backed up the Airbyte database to %s", path)
+	return path, nil
+}
@@ -0,0 +1,140 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+	"testing"
+)
+
+// dbCommandTest returns a Command whose database pod records the commands executed within it, with their stdin.
+func dbCommandTest(t *testing.T, secret *coreV1.Secret, executed *[][]string, stdin *[]string) *Command {
+	k8sClient := mockK8sClient{
+		podList: func(ctx context.Context, namespace string) (*coreV1.PodList, error) {
+			return &coreV1.PodList{Items: []coreV1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: dbPod}}}}, nil
+		},
+		secretGet: func(ctx context.Context, namespace, name string) (*coreV1.Secret, error) {
+			if d := cmp.Diff("airbyte-abctl-airbyte-secrets", name); d != "" {
+				t.Error("secret mismatch (-want +got):", d)
+			}
+			if secret == nil {
+				return nil, errors.New("not found")
+			}
+			return secret, nil
+		},
+		podExecStreams: func(ctx context.Context, namespace, name string, cmd []string, streams k8s.ExecStreams) error {
+			if d := cmp.Diff(dbPod, name); d != "" {
+				t.Error("pod mismatch (-want +got):", d)
+			}
+			*executed = append(*executed, cmd)
+			if streams.Stdin != nil {
+				in, err := io.ReadAll(streams.Stdin)
+				if err != nil {
+					t.Fatal(err)
+				}
+				*stdin = append(*stdin, string(in))
+			}
+			if streams.Stdout != nil {
+				_, _ = io.WriteString(streams.Stdout, "-- dump")
+			}
+			return nil
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCommand_DumpDatabase(t *testing.T) {
+	var (
+		executed [][]string
+		stdin    []string
+	)
+	secret := &coreV1.Secret{Data: map[string][]byte{"DATABASE_USER": []byte("user"), "DATABASE_PASSWORD": []byte("secret")}}
+	c := dbCommandTest(t, secret, &executed, &stdin)
+
+	var dump strings.Builder
+	if err := c.DumpDatabase(context.Background(), &dump); err != nil {
+		t.Fatal(err)
+	}
+
+	if d := cmp.Diff("-- dump", dump.String()); d != "" {
+		t.Error("dump mismatch (-want +got):", d)
+	}
+	exp := [][]string{{"env", "PGPASSWORD=secret", "pg_dump", "-U", "user", "-d", dbName, "--format=plain", "--clean", "--if-exists"}}
+	if d := cmp.Diff(exp, executed); d != "" {
+		t.Error("executed mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_RestoreDatabase(t *testing.T) {
+	tests := []struct {
+		name string
+		dump string
+		exp  []string
+	}{
+		{
+			name: "plain",
+			dump: "DROP TABLE IF EXISTS public.connection;",
+			exp:  []string{"env", "PGPASSWORD=", "psql", "-U", dbUser, "-d", dbName, "--quiet", "--set=ON_ERROR_STOP=1"},
+		},
+		{
+			name: "custom format",
+			dump: "PGDMP\x01\x0f\x00",
+			exp:  []string{"env", "PGPASSWORD=", "pg_restore", "-U", dbUser, "-d", dbName, "--clean", "--if-exists", "--exit-on-error", "--no-owner"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				executed [][]string
+				stdin    []string
+			)
+			// without the secret, the default user is assumed
+			c := dbCommandTest(t, nil, &executed, &stdin)
+
+			if err := c.RestoreDatabase(context.Background(), strings.NewReader(tt.dump)); err != nil {
+				t.Fatal(err)
+			}
+
+			if d := cmp.Diff([][]string{tt.exp}, executed); d != "" {
+				t.Error("executed mismatch (-want +got):", d)
+			}
+			// the peeked magic must still be restored
+			if d := cmp.Diff([]string{tt.dump}, stdin); d != "" {
+				t.Error("stdin mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestCommand_DatabaseShell_NoDatabase(t *testing.T) {
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&mockK8sClient{}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.DatabaseShell(context.Background(), strings.NewReader(""), io.Discard, io.Discard, false); err != localerr.ErrNoDatabase {
+		t.Error("expected ErrNoDatabase, received", err)
+	}
+}
@@ -0,0 +1,206 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/pterm/pterm"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// demoDatabase is the database of the bundled postgres the demo connection syncs into, separate from the
+	// database of Airbyte.
+	demoDatabase    = "airbyte_demo"
+	demoSource      = "Demo Faker"
+	demoDestination = "Demo Postgres"
+	demoConnection  = "Demo Faker → Postgres"
+	// dbDefaultPass is the password of the database of the airbyte chart, unless another was configured.
+	dbDefaultPass = "airbyte"
+	// dbService is the service of the database stateful set.
+	dbService = "airbyte-db-svc"
+)
+
+// demoTimeout is how long creating the demo connection may take, as discovering the schema of the source pulls the
+// image of the connector first.
+var demoTimeout = 10 * time.Minute
+
+// definitionID returns the id of the definition of the kind with the docker repository.
+func (a airbyteAPI) definitionID(ctx context.Context, kind, workspaceID, repository string) (string, error) {
+	var res map[string][]workspaceDefinition
+	if err := a.post(ctx, fmt.Sprintf("/api/v1/%s_definitions/list_for_workspace", kind), map[string]string{"workspaceId": workspaceID}, &res); err != nil {
+		return "", fmt.Errorf("could not list %s definitions: %w", kind, err)
+	}
+
+	for _, def := range res[kind+"Definitions"] {
+		if def.DockerRepository != repository {
+			continue
+		}
+		if kind == ConnectorDestination {
+			return def.DestinationDefinitionID, nil
+		}
+		return def.SourceDefinitionID, nil
+	}
+	return "", fmt.Errorf("%s connector %s is not available", kind, repository)
+}
+
+// demoExists returns true if the demo source was created previously.
+func (a airbyteAPI) demoExists(ctx context.Context, workspaceID string) (bool, error) {
+	var res struct {
+		Sources []struct {
+			Name string `json:"name"`
+		} `json:"sources"`
+	}
+	if err := a.post(ctx, "/api/v1/sources/list", map[string]string{"workspaceId": workspaceID}, &res); err != nil {
+		return false, fmt.Errorf("could not list sources: %w", err)
+	}
+
+	for _, src := range res.Sources {
+		if src.Name == demoSource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// createDemoDatabase creates the demo database within the bundled postgres, unless it already exists.
+func (c *Command) createDemoDatabase(ctx context.Context) error {
+	sql := fmt.Sprintf("SELECT 'CREATE DATABASE %s' WHERE NOT EXISTS (SELECT FROM pg_database WHERE datname = '%s')\\gexec\n", demoDatabase, demoDatabase)
+
+	var stderr bytes.Buffer
+	cmd := c.dbCommand(ctx, "psql", "--quiet", "--set=ON_ERROR_STOP=1")
+	if err := c.k8s.PodExecStreams(ctx, c.namespace, dbPod, cmd, k8s.ExecStreams{Stdin: strings.NewReader(sql), Stdout: io.Discard, Stderr: &stderr}); err != nil {
+		return fmt.Errorf("could not create database %s: %w: %s", demoDatabase, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// createDemo creates a Faker source, a Postgres destination writing into the demo database of the bundled postgres,
+// and the connection between them, and then starts its first sync. Nothing is created if the demo source already
+// exists. The bundled database is required, the demo is not created if Airbyte uses an external database.
+func (c *Command) createDemo(ctx context.Context, opts APIOpts) error {
+	if err := c.podExists(ctx, dbPod, localerr.ErrNoDatabase); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, demoTimeout)
+	defer cancel()
+
+	c.spinner.UpdateText("Waiting for the Airbyte API to become ready")
+	api, workspaceID, err := c.workspaceAPI(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if exists, err := api.demoExists(ctx, workspaceID); err != nil {
+		return err
+	} else if exists {
+		pterm.Info.Printfln("The demo source '%s' already exists", demoSource)
+		return nil
+	}
+
+	c.spinner.UpdateText("Creating the demo database")
+	if err := c.createDemoDatabase(ctx); err != nil {
+		return err
+	}
+
+	sourceDefID, err := api.definitionID(ctx, ConnectorSource, workspaceID, "airbyte/source-faker")
+	if err != nil {
+		return err
+	}
+	destinationDefID, err := api.definitionID(ctx, ConnectorDestination, workspaceID, "airbyte/destination-postgres")
+	if err != nil {
+		return err
+	}
+
+	c.spinner.UpdateText(fmt.Sprintf("Creating the demo source '%s'", demoSource))
+	var source struct {
+		SourceID string `json:"sourceId"`
+	}
+	sourceReq := map[string]interface{}{
+		"workspaceId":             workspaceID,
+		"sourceDefinitionId":      sourceDefID,
+		"name":                    demoSource,
+		"connectionConfiguration": map[string]interface{}{"count": 1000, "seed": -1},
+	}
+	if err := api.post(ctx, "/api/v1/sources/create", sourceReq, &source); err != nil {
+		return fmt.Errorf("could not create source: %w", err)
+	}
+
+	c.spinner.UpdateText(fmt.Sprintf("Creating the demo destination '%s'", demoDestination))
+	user, pass := c.dbCredentials(ctx)
+	if pass == "" {
+		pass = dbDefaultPass
+	}
+	var destination struct {
+		DestinationID string `json:"destinationId"`
+	}
+	destinationReq := map[string]interface{}{
+		"workspaceId":             workspaceID,
+		"destinationDefinitionId": destinationDefID,
+		"name":                    demoDestination,
+		"connectionConfiguration": map[string]interface{}{
+			"host":          fmt.Sprintf("%s.%s.svc.cluster.local", dbService, c.namespace),
+			"port":          5432,
+			"database":      demoDatabase,
+			"schema":        "public",
+			"username":      user,
+			"password":      pass,
+			"ssl":           false,
+			"ssl_mode":      map[string]string{"mode": "disable"},
+			"tunnel_method": map[string]string{"tunnel_method": "NO_TUNNEL"},
+		},
+	}
+	if err := api.post(ctx, "/api/v1/destinations/create", destinationReq, &destination); err != nil {
+		return fmt.Errorf("could not create destination: %w", err)
+	}
+
+	c.spinner.UpdateText("Discovering the streams of the demo source")
+	var discovered struct {
+		Catalog *struct {
+			Streams []map[string]interface{} `json:"streams"`
+		} `json:"catalog"`
+	}
+	if err := api.post(ctx, "/api/v1/sources/discover_schema", map[string]interface{}{"sourceId": source.SourceID, "disable_cache": true}, &discovered); err != nil {
+		return fmt.Errorf("could not discover schema: %w", err)
+	}
+	if discovered.Catalog == nil {
+		return errors.New("could not discover schema: no catalog returned")
+	}
+	for _, stream := range discovered.Catalog.Streams {
+		if config, ok := stream["config"].(map[string]interface{}); ok {
+			config["selected"] = true
+		}
+	}
+
+	c.spinner.UpdateText(fmt.Sprintf("Creating the demo connection '%s'", demoConnection))
+	var connection struct {
+		ConnectionID string `json:"connectionId"`
+	}
+	connectionReq := map[string]interface{}{
+		"name":                demoConnection,
+		"sourceId":            source.SourceID,
+		"destinationId":       destination.DestinationID,
+		"syncCatalog":         discovered.Catalog,
+		"status":              "active",
+		"scheduleType":        "manual",
+		"namespaceDefinition": "destination",
+	}
+	if err := api.post(ctx, "/api/v1/connections/create", connectionReq, &connection); err != nil {
+		return fmt.Errorf("could not create connection: %w", err)
+	}
+
+	if err := api.post(ctx, "/api/v1/connections/sync", map[string]string{"connectionId": connection.ConnectionID}, nil); err != nil {
+		return fmt.Errorf("could not start sync: %w", err)
+	}
+
+	pterm.Success.Printfln("Created the demo connection '%s', its first sync is running", demoConnection)
+	pterm.Info.Printfln("The synced data is written to the database '%s', which can be queried by 'abctl local db psql'\n"+
+		"after connecting to it with '\\c %s'", demoDatabase, demoDatabase)
+	return nil
+}
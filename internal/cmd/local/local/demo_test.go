@@ -0,0 +1,130 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// demoHTTP returns the mockHTTP of the api creating the demo, recording the path of every request creating something
+// to created, with its decoded body to bodies. The demo source exists if exists is true.
+func demoHTTP(t *testing.T, exists bool, created *[]string, bodies map[string]map[string]interface{}) *mockHTTP {
+	return &mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+		respond := func(body string) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+		}
+
+		switch req.URL.Path {
+		case "/api/v1/workspaces/list":
+			return respond(`{"workspaces": [{"workspaceId": "ws"}]}`)
+		case "/api/v1/sources/list":
+			if exists {
+				return respond(`{"sources": [{"name": "Demo Faker"}]}`)
+			}
+			return respond(`{"sources": []}`)
+		case "/api/v1/source_definitions/list_for_workspace":
+			return respond(`{"sourceDefinitions": [{"sourceDefinitionId": "faker-def", "dockerRepository": "airbyte/source-faker"}]}`)
+		case "/api/v1/destination_definitions/list_for_workspace":
+			return respond(`{"destinationDefinitions": [{"destinationDefinitionId": "postgres-def", "dockerRepository": "airbyte/destination-postgres"}]}`)
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Fatal("could not decode body", err)
+		}
+		*created = append(*created, req.URL.Path)
+		bodies[req.URL.Path] = body
+
+		switch req.URL.Path {
+		case "/api/v1/sources/create":
+			return respond(`{"sourceId": "source"}`)
+		case "/api/v1/destinations/create":
+			return respond(`{"destinationId": "destination"}`)
+		case "/api/v1/sources/discover_schema":
+			return respond(`{"catalog": {"streams": [{"stream": {"name": "users"}, "config": {"selected": false, "syncMode": "full_refresh"}}]}}`)
+		case "/api/v1/connections/create":
+			return respond(`{"connectionId": "connection"}`)
+		case "/api/v1/connections/sync":
+			return respond(`{}`)
+		default:
+			t.Error("unexpected path", req.URL.Path)
+			return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody}, nil
+		}
+	}}
+}
+
+func TestCommand_CreateDemo(t *testing.T) {
+	var (
+		executed [][]string
+		stdin    []string
+		created  []string
+		bodies   = map[string]map[string]interface{}{}
+	)
+	c := dbCommandTest(t, nil, &executed, &stdin)
+	c.http = demoHTTP(t, false, &created, bodies)
+
+	if err := c.createDemo(context.Background(), APIOpts{User: "user", Pass: "pass"}); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expCreated := []string{
+		"/api/v1/sources/create",
+		"/api/v1/destinations/create",
+		"/api/v1/sources/discover_schema",
+		"/api/v1/connections/create",
+		"/api/v1/connections/sync",
+	}
+	if d := cmp.Diff(expCreated, created); d != "" {
+		t.Error("created mismatch (-want +got):", d)
+	}
+
+	if d := cmp.Diff(1, len(stdin)); d != "" {
+		t.Fatal("database commands mismatch (-want +got):", d)
+	}
+	if !strings.Contains(stdin[0], "CREATE DATABASE airbyte_demo") {
+		t.Error("expected the demo database to be created, got", stdin[0])
+	}
+
+	destination := bodies["/api/v1/destinations/create"]
+	if d := cmp.Diff("postgres-def", destination["destinationDefinitionId"]); d != "" {
+		t.Error("destination definition mismatch (-want +got):", d)
+	}
+	config := destination["connectionConfiguration"].(map[string]interface{})
+	exp := map[string]interface{}{"host": "airbyte-db-svc.airbyte-abctl.svc.cluster.local", "database": "airbyte_demo", "username": "airbyte", "password": "airbyte"}
+	for k, v := range exp {
+		if d := cmp.Diff(v, config[k]); d != "" {
+			t.Errorf("destination %s mismatch (-want +got): %s", k, d)
+		}
+	}
+
+	// every discovered stream is synced
+	connection := bodies["/api/v1/connections/create"]
+	streams := connection["syncCatalog"].(map[string]interface{})["streams"].([]interface{})
+	if d := cmp.Diff(true, streams[0].(map[string]interface{})["config"].(map[string]interface{})["selected"]); d != "" {
+		t.Error("selected mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff(map[string]interface{}{"connectionId": "connection"}, bodies["/api/v1/connections/sync"]); d != "" {
+		t.Error("sync mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_CreateDemo_Exists(t *testing.T) {
+	var (
+		executed [][]string
+		stdin    []string
+		created  []string
+	)
+	c := dbCommandTest(t, nil, &executed, &stdin)
+	c.http = demoHTTP(t, true, &created, map[string]map[string]interface{}{})
+
+	if err := c.createDemo(context.Background(), APIOpts{User: "user", Pass: "pass"}); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if len(created) > 0 || len(executed) > 0 {
+		t.Error("expected nothing to be created, got", created, executed)
+	}
+}
@@ -0,0 +1,185 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/trace"
+	"github.com/pterm/pterm"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	corednsNamespace = "kube-system"
+	// corednsName is the name of both the config map and the deployment of the coredns of a kind cluster
+	corednsName = "coredns"
+	// HostGatewayName is the hostname of the docker host within containers, which Docker Desktop resolves by itself,
+	// and which is otherwise resolved by a host alias to the gateway of the kind network.
+	HostGatewayName = "host.docker.internal"
+)
+
+// HostAlias resolves the Host to the IP within the cluster, as an /etc/hosts entry would.
+type HostAlias struct {
+	Host string
+	IP   string
+}
+
+// DNSServer is a dns server the cluster forwards queries to. If Zone is not empty, only queries within the zone are
+// forwarded to it, otherwise every query outside the cluster is.
+type DNSServer struct {
+	Zone string
+	// Address is the ip, and optional port, of the dns server.
+	Address string
+}
+
+// DNSOpts are the host aliases and dns servers of the cluster, which resolve the names of every pod of the cluster,
+// including the pods of the connector jobs.
+type DNSOpts struct {
+	HostAliases []HostAlias
+	Servers     []DNSServer
+}
+
+// ParseHostAlias parses a host alias in the format host=ip, e.g. db.internal=10.0.0.5.
+func ParseHostAlias(s string) (HostAlias, error) {
+	host, ip, ok := strings.Cut(s, "=")
+	if !ok {
+		return HostAlias{}, fmt.Errorf("invalid host alias %s, expected the format host=ip", s)
+	}
+	if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+		return HostAlias{}, fmt.Errorf("invalid host alias %s, the host is invalid: %s", s, strings.Join(errs, ", "))
+	}
+	if net.ParseIP(ip) == nil {
+		return HostAlias{}, fmt.Errorf("invalid host alias %s, the ip %s is not an ipv4 or ipv6 address", s, ip)
+	}
+	return HostAlias{Host: host, IP: ip}, nil
+}
+
+// ParseDNSServer parses a dns server in the format [zone=]ip[:port], e.g. 10.0.0.53, or corp.internal=10.0.0.53:5353.
+func ParseDNSServer(s string) (DNSServer, error) {
+	var server DNSServer
+
+	server.Address = s
+	if zone, address, ok := strings.Cut(s, "="); ok {
+		if errs := validation.IsDNS1123Subdomain(zone); len(errs) > 0 {
+			return DNSServer{}, fmt.Errorf("invalid dns server %s, the zone is invalid: %s", s, strings.Join(errs, ", "))
+		}
+		server.Zone, server.Address = zone, address
+	}
+
+	ip := server.Address
+	if host, port, err := net.SplitHostPort(server.Address); err == nil {
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return DNSServer{}, fmt.Errorf("invalid dns server %s, the port %s is invalid", s, port)
+		}
+		ip = host
+	}
+	if net.ParseIP(ip) == nil {
+		return DNSServer{}, fmt.Errorf("invalid dns server %s, expected the format [zone=]ip[:port]", s)
+	}
+	return server, nil
+}
+
+// corefile returns the coredns config of a kind cluster, resolving the host aliases and forwarding queries to the
+// dns servers of the opts. Without any, it is the default config of kind.
+func corefile(opts DNSOpts) string {
+	upstream := "/etc/resolv.conf"
+	var zones []DNSServer
+	var defaults []string
+	for _, s := range opts.Servers {
+		if s.Zone != "" {
+			zones = append(zones, s)
+			continue
+		}
+		defaults = append(defaults, s.Address)
+	}
+	if len(defaults) > 0 {
+		upstream = strings.Join(defaults, " ")
+	}
+
+	var hosts string
+	if len(opts.HostAliases) > 0 {
+		hosts = "    hosts {\n"
+		for _, alias := range opts.HostAliases {
+			hosts += fmt.Sprintf("       %s %s\n", alias.IP, alias.Host)
+		}
+		hosts += "       fallthrough\n    }\n"
+	}
+
+	cfg := fmt.Sprintf(`.:53 {
+    errors
+    health {
+       lameduck 5s
+    }
+    ready
+    kubernetes cluster.local in-addr.arpa ip6.arpa {
+       pods insecure
+       fallthrough in-addr.arpa ip6.arpa
+       ttl 30
+    }
+%s    prometheus :9153
+    forward . %s {
+       max_concurrent 1000
+    }
+    cache 30
+    loop
+    reload
+    loadbalance
+}
+`, hosts, upstream)
+
+	// the queries of each zone are forwarded to its dns servers, in the order they were provided
+	var order []string
+	servers := map[string][]string{}
+	for _, s := range zones {
+		if _, ok := servers[s.Zone]; !ok {
+			order = append(order, s.Zone)
+		}
+		servers[s.Zone] = append(servers[s.Zone], s.Address)
+	}
+	for _, zone := range order {
+		cfg += fmt.Sprintf(`%s:53 {
+    errors
+    cache 30
+    forward . %s
+}
+`, zone, strings.Join(servers[zone], " "))
+	}
+
+	return cfg
+}
+
+// handleDNS configures the coredns of the kind cluster with the host aliases and dns servers of the opts, restarting
+// coredns so they apply immediately.
+func (c *Command) handleDNS(ctx context.Context, opts DNSOpts) (err error) {
+	ctx, span := trace.NewSpan(ctx, "dns")
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	c.spinner.UpdateText("Configuring the DNS of the cluster")
+
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetNamespace(corednsNamespace)
+	configMap.SetName(corednsName)
+	if err := unstructured.SetNestedField(configMap.Object, corefile(opts), "data", "Corefile"); err != nil {
+		return fmt.Errorf("could not set the corefile: %w", err)
+	}
+
+	if err := c.k8s.ManifestApply(ctx, corednsNamespace, configMap); err != nil {
+		pterm.Error.Println("Unable to configure the DNS of the cluster")
+		return fmt.Errorf("could not apply coredns config: %w", err)
+	}
+	if err := c.k8s.DeploymentRestart(ctx, corednsNamespace, corednsName); err != nil {
+		pterm.Error.Println("Unable to restart the DNS of the cluster")
+		return fmt.Errorf("could not restart coredns: %w", err)
+	}
+
+	pterm.Info.Printfln("DNS of the cluster configured with %d host alias(es) and %d DNS server(s)", len(opts.HostAliases), len(opts.Servers))
+	return nil
+}
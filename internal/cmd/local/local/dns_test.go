@@ -0,0 +1,139 @@
+package local
+
+import (
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pterm/pterm"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"strings"
+	"testing"
+)
+
+func TestParseHostAlias(t *testing.T) {
+	alias, err := ParseHostAlias("db.internal=10.0.0.5")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(HostAlias{Host: "db.internal", IP: "10.0.0.5"}, alias); d != "" {
+		t.Error("host alias mismatch (-want +got):", d)
+	}
+
+	for _, input := range []string{"", "db.internal", "db.internal=", "=10.0.0.5", "db.internal=db", "DB_INTERNAL=10.0.0.5"} {
+		if _, err := ParseHostAlias(input); err == nil {
+			t.Errorf("expected an error for '%s'", input)
+		}
+	}
+}
+
+func TestParseDNSServer(t *testing.T) {
+	tests := []struct {
+		input string
+		exp   DNSServer
+	}{
+		{input: "10.0.0.53", exp: DNSServer{Address: "10.0.0.53"}},
+		{input: "10.0.0.53:5353", exp: DNSServer{Address: "10.0.0.53:5353"}},
+		{input: "fd00::53", exp: DNSServer{Address: "fd00::53"}},
+		{input: "corp.internal=[fd00::53]:5353", exp: DNSServer{Zone: "corp.internal", Address: "[fd00::53]:5353"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			server, err := ParseDNSServer(tt.input)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, server); d != "" {
+				t.Error("dns server mismatch (-want +got):", d)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "dns.internal", "corp.internal=", "corp_internal=10.0.0.53", "10.0.0.53:port"} {
+		if _, err := ParseDNSServer(input); err == nil {
+			t.Errorf("expected an error for '%s'", input)
+		}
+	}
+}
+
+func TestCorefile(t *testing.T) {
+	// without any host aliases or dns servers, the default config of kind is restored
+	if cfg := corefile(DNSOpts{}); !strings.Contains(cfg, "forward . /etc/resolv.conf {") || strings.Contains(cfg, "hosts") {
+		t.Error("expected the default config, got", cfg)
+	}
+
+	cfg := corefile(DNSOpts{
+		HostAliases: []HostAlias{{Host: "db.internal", IP: "10.0.0.5"}, {Host: "api.internal", IP: "10.0.0.6"}},
+		Servers: []DNSServer{
+			{Address: "10.0.0.53"},
+			{Zone: "corp.internal", Address: "10.1.0.53"},
+			{Address: "10.0.0.54"},
+			{Zone: "corp.internal", Address: "10.1.0.54"},
+		},
+	})
+	exp := `.:53 {
+    errors
+    health {
+       lameduck 5s
+    }
+    ready
+    kubernetes cluster.local in-addr.arpa ip6.arpa {
+       pods insecure
+       fallthrough in-addr.arpa ip6.arpa
+       ttl 30
+    }
+    hosts {
+       10.0.0.5 db.internal
+       10.0.0.6 api.internal
+       fallthrough
+    }
+    prometheus :9153
+    forward . 10.0.0.53 10.0.0.54 {
+       max_concurrent 1000
+    }
+    cache 30
+    loop
+    reload
+    loadbalance
+}
+corp.internal:53 {
+    errors
+    cache 30
+    forward . 10.1.0.53 10.1.0.54
+}
+`
+	if d := cmp.Diff(exp, cfg); d != "" {
+		t.Error("corefile mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_handleDNS(t *testing.T) {
+	var applied *unstructured.Unstructured
+	var restarted []string
+	k8sClient := mockK8sClient{
+		manifestApply: func(ctx context.Context, namespace string, obj *unstructured.Unstructured) error {
+			applied = obj
+			return nil
+		},
+		deploymentRestart: func(ctx context.Context, namespace, name string) error {
+			restarted = append(restarted, namespace+"/"+name)
+			return nil
+		},
+	}
+
+	spinner, _ := pterm.DefaultSpinner.Start()
+	c := &Command{k8s: &k8sClient, namespace: airbyteNamespace, spinner: spinner}
+	opts := DNSOpts{HostAliases: []HostAlias{{Host: "db.internal", IP: "10.0.0.5"}}}
+	if err := c.handleDNS(context.Background(), opts); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if applied == nil || applied.GetKind() != "ConfigMap" || applied.GetNamespace() != "kube-system" || applied.GetName() != "coredns" {
+		t.Fatal("expected the coredns config map to be applied, got", applied)
+	}
+	data, _, _ := unstructured.NestedString(applied.Object, "data", "Corefile")
+	if d := cmp.Diff(corefile(opts), data); d != "" {
+		t.Error("corefile mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff([]string{"kube-system/coredns"}, restarted); d != "" {
+		t.Error("restarted mismatch (-want +got):", d)
+	}
+}
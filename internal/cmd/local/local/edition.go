@@ -0,0 +1,143 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/pterm/pterm"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	EditionCommunity  = "community"
+	EditionEnterprise = "enterprise"
+)
+
+// EditionOpts configures switching the edition of the installed Airbyte.
+type EditionOpts struct {
+	// Edition is the edition to switch to, either EditionCommunity or EditionEnterprise.
+	Edition string
+	// SSO is required when switching to the enterprise edition.
+	SSO SSOOpts
+	// BackupDir is the directory the database is backed up into before switching.
+	BackupDir string
+	// SkipBackup skips backing up the database, which is required when an external database is configured.
+	SkipBackup bool
+	Download   *DownloadOpts
+}
+
+// Edition returns the edition of the installed Airbyte.
+func (c *Command) Edition() (string, error) {
+	rel, err := c.helm.GetRelease(airbyteChartRelease)
+	if err != nil {
+		return "", fmt.Errorf("could not get airbyte release: %w", err)
+	}
+	return releaseEdition(rel.Config), nil
+}
+
+// SwitchEdition switches the installed Airbyte to another edition, backing up the database first.
+// Switching to the enterprise edition configures SSO, see ConfigureSSO. Switching to the community edition removes
+// the enterprise values and restarts every deployment.
+func (c *Command) SwitchEdition(ctx context.Context, opts EditionOpts) error {
+	switch opts.Edition {
+	case EditionCommunity:
+	case EditionEnterprise:
+		if err := opts.SSO.Validate(); err != nil {
+			return fmt.Errorf("invalid sso configuration: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported edition %s, must be %s or %s", opts.Edition, EditionCommunity, EditionEnterprise)
+	}
+
+	c.spinner.UpdateText("Checking for the Airbyte Helm Chart")
+	rel, err := c.helm.GetRelease(airbyteChartRelease)
+	if err != nil {
+		pterm.Error.Println("Airbyte is not installed, run 'abctl local install' to install it")
+		return fmt.Errorf("could not get airbyte release: %w", err)
+	}
+
+	if current := releaseEdition(rel.Config); current == opts.Edition {
+		pterm.Info.Printfln("Airbyte is already the %s edition", current)
+		return nil
+	}
+
+	if !opts.SkipBackup {
+		if _, err := c.BackupDatabase(ctx, opts.BackupDir); err != nil {
+			if errors.Is(err, localerr.ErrNoDatabase) {
+				pterm.Error.Println("Unable to backup the database, an external database must be backed up separately before switching with --skip-backup")
+			}
+			return err
+		}
+	}
+
+	if opts.Edition == EditionEnterprise {
+		return c.ConfigureSSO(ctx, opts.SSO, opts.Download)
+	}
+
+	values, err := communityValues(rel.Config)
+	if err != nil {
+		return err
+	}
+
+	if err := c.handleChart(ctx, chartRequest{
+		name:         "airbyte",
+		repoName:     airbyteRepoName,
+		repoURL:      airbyteRepoURL,
+		chartName:    airbyteChartName,
+		chartRelease: airbyteChartRelease,
+		chartVersion: rel.Chart.Metadata.Version,
+		namespace:    c.namespace,
+		valuesYAML:   values,
+		download:     opts.Download,
+		provenance:   true,
+	}); err != nil {
+		return fmt.Errorf("could not upgrade airbyte chart: %w", err)
+	}
+
+	return c.restartDeployments(ctx)
+}
+
+// releaseEdition returns the edition configured by the values of a release, which is community unless specified.
+func releaseEdition(config map[string]interface{}) string {
+	if global, ok := config["global"].(map[string]interface{}); ok {
+		if edition, ok := global["edition"].(string); ok && edition != "" {
+			return edition
+		}
+	}
+	return EditionCommunity
+}
+
+// communityValues returns the values of a release with the enterprise values, as set by ssoValues, removed.
+// The values must replace the values of the release, as removed values would otherwise be reused.
+func communityValues(config map[string]interface{}) (string, error) {
+	raw, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal release values: %w", err)
+	}
+	// unmarshalling the marshalled values is the simplest deep copy, ensuring the release is never modified
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(raw, &values); err != nil {
+		return "", fmt.Errorf("could not unmarshal release values: %w", err)
+	}
+
+	global, ok := values["global"].(map[string]interface{})
+	if !ok {
+		global = map[string]interface{}{}
+		values["global"] = global
+	}
+	global["edition"] = EditionCommunity
+	delete(global, "enterprise")
+	if auth, ok := global["auth"].(map[string]interface{}); ok {
+		delete(auth, "identityProvider")
+		delete(auth, "instanceAdmin")
+		if len(auth) == 0 {
+			delete(global, "auth")
+		}
+	}
+
+	if raw, err = yaml.Marshal(values); err != nil {
+		return "", fmt.Errorf("could not marshal community values: %w", err)
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,204 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/google/go-cmp/cmp"
+	helmclient "github.com/mittwald/go-helm-client"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/repo"
+	"io"
+	appsv1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReleaseEdition(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		exp    string
+	}{
+		{
+			name: "no values",
+			exp:  EditionCommunity,
+		},
+		{
+			name:   "no edition",
+			config: map[string]interface{}{"global": map[string]interface{}{"airbyteUrl": "http://localhost:8000"}},
+			exp:    EditionCommunity,
+		},
+		{
+			name:   "enterprise",
+			config: map[string]interface{}{"global": map[string]interface{}{"edition": "enterprise"}},
+			exp:    EditionEnterprise,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.exp, releaseEdition(tt.config)); d != "" {
+				t.Error("edition mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestCommunityValues(t *testing.T) {
+	raw, err := ssoValues(ssoOptsTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(raw), &config); err != nil {
+		t.Fatal(err)
+	}
+	config["webapp"] = map[string]interface{}{"replicaCount": 2}
+
+	raw, err = communityValues(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(raw), &values); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := map[string]interface{}{
+		"global": map[string]interface{}{
+			"edition":    "community",
+			"airbyteUrl": "http://localhost:8000",
+		},
+		"webapp": map[string]interface{}{"replicaCount": 2},
+	}
+	if d := cmp.Diff(exp, values); d != "" {
+		t.Error("values mismatch (-want +got):", d)
+	}
+
+	if releaseEdition(config) != EditionEnterprise {
+		t.Error("release values were modified")
+	}
+}
+
+func TestCommand_SwitchEdition(t *testing.T) {
+	var (
+		spec      *helmclient.ChartSpec
+		restarted []string
+		dumped    []string
+	)
+
+	helm := mockHelmClient{
+		addOrUpdateChartRepo: func(entry repo.Entry) error {
+			return nil
+		},
+		getRelease: func(name string) (*release.Release, error) {
+			return &release.Release{
+				Name:   name,
+				Chart:  &chart.Chart{Metadata: &chart.Metadata{Version: "1.2.3"}},
+				Config: map[string]interface{}{"global": map[string]interface{}{"edition": "enterprise", "enterprise": map[string]interface{}{}}},
+			}, nil
+		},
+		getChart: func(name string, opts *action.ChartPathOptions) (*chart.Chart, string, error) {
+			return &chart.Chart{Metadata: &chart.Metadata{Version: opts.Version}}, "", nil
+		},
+		installOrUpgradeChart: func(ctx context.Context, s *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+			spec = s
+			return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: s.Version}}, Name: s.ReleaseName}, nil
+		},
+	}
+
+	k8sClient := mockK8sClient{
+		serverVersionGet: func() (string, error) {
+			return "test", nil
+		},
+		podList: func(ctx context.Context, namespace string) (*coreV1.PodList, error) {
+			return &coreV1.PodList{Items: []coreV1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: dbPod}}}}, nil
+		},
+		podExec: func(ctx context.Context, namespace, name string, cmd []string, stdout io.Writer) error {
+			dumped = append(dumped, name)
+			_, err := stdout.Write([]byte("dump"))
+			return err
+		},
+		deploymentList: func(ctx context.Context, namespace string) (*appsv1.DeploymentList, error) {
+			return &appsv1.DeploymentList{Items: []appsv1.Deployment{
+				{ObjectMeta: metav1.ObjectMeta{Name: "airbyte-abctl-server"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "airbyte-abctl-worker"}},
+			}}, nil
+		},
+		deploymentRestart: func(ctx context.Context, namespace, name string) error {
+			restarted = append(restarted, name)
+			return nil
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&helm),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := c.SwitchEdition(context.Background(), EditionOpts{Edition: EditionCommunity, BackupDir: dir}); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if d := cmp.Diff([]string{dbPod}, dumped); d != "" {
+		t.Error("dumped mismatch (-want +got):", d)
+	}
+	backups, err := filepath.Glob(filepath.Join(dir, airbyteNamespace+"-*.dump"))
+	if err != nil || len(backups) != 1 {
+		t.Fatal("expected one backup, got", backups, err)
+	}
+	if content, _ := os.ReadFile(backups[0]); string(content) != "dump" {
+		t.Error("unexpected backup content", string(content))
+	}
+
+	if spec == nil {
+		t.Fatal("chart was not upgraded")
+	}
+	if spec.Version != "1.2.3" || spec.ReuseValues {
+		t.Errorf("unexpected chart spec: version %s, reuse values %t", spec.Version, spec.ReuseValues)
+	}
+	if d := cmp.Diff("global:\n    edition: community\n", spec.ValuesYaml); d != "" {
+		t.Error("values mismatch (-want +got):", d)
+	}
+
+	if d := cmp.Diff([]string{"airbyte-abctl-server", "airbyte-abctl-worker"}, restarted); d != "" {
+		t.Error("restarted mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_SwitchEdition_NoDatabase(t *testing.T) {
+	helm := mockHelmClient{
+		getRelease: func(name string) (*release.Release, error) {
+			return &release.Release{Name: name, Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "1.2.3"}}}, nil
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&helm),
+		WithK8sClient(&mockK8sClient{serverVersionGet: func() (string, error) { return "test", nil }}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.SwitchEdition(context.Background(), EditionOpts{Edition: EditionEnterprise, SSO: ssoOptsTest, BackupDir: t.TempDir()})
+	if !errors.Is(err, localerr.ErrNoDatabase) {
+		t.Error("expected ErrNoDatabase, got", err)
+	}
+}
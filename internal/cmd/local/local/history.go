@@ -0,0 +1,68 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"sort"
+	"time"
+)
+
+// Revision is a revision of a helm release of the installation.
+type Revision struct {
+	Release      string    `json:"release"`
+	Revision     int       `json:"revision"`
+	Updated      time.Time `json:"updated"`
+	Status       string    `json:"status"`
+	ChartVersion string    `json:"chart_version"`
+	AppVersion   string    `json:"app_version"`
+	// Description describes what happened to the revision, e.g. "Upgrade complete" or "Rollback to 2", including any
+	// revision rolled back, or release uninstalled, by abctl while recovering it from a pending state.
+	Description string `json:"description"`
+}
+
+// History returns the revisions of the airbyte release, the release of the ingress controller, and the monitoring
+// and log aggregation releases if installed, oldest first. At most max revisions of each release are returned.
+func (c *Command) History(max int) ([]Revision, error) {
+	releases := []string{airbyteChartRelease}
+	if release, _ := ingressControllerRelease(c.ingressController); release != "" {
+		releases = append(releases, release)
+	}
+	releases = append(releases, monitoringChartRelease, logsChartRelease)
+
+	var revisions []Revision
+	for _, name := range releases {
+		history, err := c.releaseHelm(name).ListReleaseHistory(name, max)
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not get history of release %s: %w", name, err)
+		}
+
+		var release []Revision
+		for _, rel := range history {
+			r := Revision{Release: name, Revision: rel.Version}
+			if rel.Info != nil {
+				r.Updated = rel.Info.LastDeployed.Time
+				r.Status = rel.Info.Status.String()
+				r.Description = rel.Info.Description
+			}
+			if rel.Chart != nil && rel.Chart.Metadata != nil {
+				r.ChartVersion = rel.Chart.Metadata.Version
+				r.AppVersion = rel.Chart.Metadata.AppVersion
+			}
+			release = append(release, r)
+		}
+		sort.Slice(release, func(i, j int) bool {
+			return release[i].Revision < release[j].Revision
+		})
+		// only the latest revisions are kept, if helm returned more
+		if max > 0 && len(release) > max {
+			release = release[len(release)-max:]
+		}
+		revisions = append(revisions, release...)
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,78 @@
+package local
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	helmtime "helm.sh/helm/v3/pkg/time"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommand_History(t *testing.T) {
+	deployed := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rev := func(name string, version int, status release.Status, description string) *release.Release {
+		return &release.Release{
+			Name:    name,
+			Version: version,
+			Info:    &release.Info{Status: status, Description: description, LastDeployed: helmtime.Time{Time: deployed}},
+			Chart:   &chart.Chart{Metadata: &chart.Metadata{Version: "1.0.0", AppVersion: "0.60.0"}},
+		}
+	}
+
+	histories := map[string][]*release.Release{
+		airbyteChartRelease: {
+			rev(airbyteChartRelease, 3, release.StatusDeployed, "Rollback to 1"),
+			rev(airbyteChartRelease, 1, release.StatusSuperseded, "Install complete"),
+			rev(airbyteChartRelease, 2, release.StatusPendingUpgrade, "Preparing upgrade"),
+		},
+		nginxChartRelease: {rev(nginxChartRelease, 1, release.StatusDeployed, "Install complete")},
+	}
+
+	var requested []string
+	helm := mockHelmClient{
+		listReleaseHistory: func(name string, max int) ([]*release.Release, error) {
+			requested = append(requested, name)
+			history, ok := histories[name]
+			if !ok {
+				return nil, driver.ErrReleaseNotFound
+			}
+			return history, nil
+		},
+	}
+
+	c := &Command{helm: &helm, ingressHelm: &helm, ingressController: IngressNginx}
+	revisions, err := c.History(2)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// the latest revisions of each release are returned, oldest first
+	exp := []Revision{
+		{Release: airbyteChartRelease, Revision: 2, Updated: deployed, Status: "pending-upgrade", ChartVersion: "1.0.0", AppVersion: "0.60.0", Description: "Preparing upgrade"},
+		{Release: airbyteChartRelease, Revision: 3, Updated: deployed, Status: "deployed", ChartVersion: "1.0.0", AppVersion: "0.60.0", Description: "Rollback to 1"},
+		{Release: nginxChartRelease, Revision: 1, Updated: deployed, Status: "deployed", ChartVersion: "1.0.0", AppVersion: "0.60.0", Description: "Install complete"},
+	}
+	if d := cmp.Diff(exp, revisions); d != "" {
+		t.Error("revisions mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff([]string{airbyteChartRelease, nginxChartRelease, monitoringChartRelease, logsChartRelease}, requested); d != "" {
+		t.Error("releases mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_History_Error(t *testing.T) {
+	helm := mockHelmClient{
+		listReleaseHistory: func(name string, max int) ([]*release.Release, error) {
+			return nil, errors.New("test error")
+		},
+	}
+
+	c := &Command{helm: &helm, ingressHelm: &helm, ingressController: IngressNone}
+	if _, err := c.History(10); err == nil || !strings.Contains(err.Error(), "could not get history of release "+airbyteChartRelease) {
+		t.Error("expected the history error, got", err)
+	}
+}
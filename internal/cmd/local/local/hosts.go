@@ -0,0 +1,118 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/readiness"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/pterm/pterm"
+	"slices"
+	"time"
+)
+
+// Hosts returns the hosts Airbyte is accessible from, as configured by its ingress.
+func (c *Command) Hosts(ctx context.Context) ([]string, error) {
+	ing, err := c.k8s.IngressGet(ctx, c.namespace, airbyteIngress)
+	if err != nil {
+		return nil, fmt.Errorf("could not get ingress: %w", err)
+	}
+
+	var hosts []string
+	for _, rule := range ing.Spec.Rules {
+		hosts = append(hosts, rule.Host)
+	}
+	return hosts, nil
+}
+
+// AddHosts adds the hosts to the ingress, then verifies Airbyte is accessible from every added host.
+// Hosts which are already configured are ignored.
+func (c *Command) AddHosts(ctx context.Context, hosts []string) error {
+	existing, err := c.Hosts(ctx)
+	if err != nil {
+		return err
+	}
+
+	var added []string
+	for _, host := range hosts {
+		if slices.Contains(existing, host) || slices.Contains(added, host) {
+			pterm.Info.Printfln("Host '%s' is already configured", host)
+			continue
+		}
+		added = append(added, host)
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	if err := c.updateHosts(ctx, append(existing, added...)); err != nil {
+		return err
+	}
+
+	// the hosts are verified to be served on this machine, whether or not they resolve to it, see bindTransport
+	for _, host := range added {
+		url := baseURL(host, c.portHTTP)
+		c.spinner.UpdateText(fmt.Sprintf("Verifying Airbyte is accessible via %s", url))
+
+		waitCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		err := readiness.Wait(waitCtx, time.Second, readiness.Ingress(c.http, url))
+		cancel()
+		if err != nil {
+			pterm.Warning.Printfln("Unable to verify Airbyte is accessible via %s", url)
+			logging.Debugf("could not verify ingress %s: %s", url, err)
+			continue
+		}
+		pterm.Success.Printfln("Airbyte is accessible via %s", url)
+	}
+
+	return nil
+}
+
+// RemoveHosts removes the hosts from the ingress.
+// At least one host must remain, as Airbyte would otherwise no longer be accessible.
+func (c *Command) RemoveHosts(ctx context.Context, hosts []string) error {
+	existing, err := c.Hosts(ctx)
+	if err != nil {
+		return err
+	}
+
+	var remaining []string
+	for _, host := range existing {
+		if !slices.Contains(hosts, host) {
+			remaining = append(remaining, host)
+		}
+	}
+	for _, host := range hosts {
+		if !slices.Contains(existing, host) {
+			pterm.Info.Printfln("Host '%s' is not configured", host)
+		}
+	}
+
+	if len(remaining) == len(existing) {
+		return nil
+	}
+	if len(remaining) == 0 {
+		return errors.New("at least one host must remain")
+	}
+
+	return c.updateHosts(ctx, remaining)
+}
+
+// updateHosts replaces the hosts of the airbyte ingress, and of the monitoring ingress if monitoring is installed.
+func (c *Command) updateHosts(ctx context.Context, hosts []string) error {
+	c.spinner.UpdateText("Updating Ingress")
+	if err := c.k8s.IngressUpdate(ctx, c.namespace, ingress(c.namespace, hosts, c.ingressController, c.ingressClass)); err != nil {
+		pterm.Error.Println("Unable to update the Ingress")
+		return fmt.Errorf("could not update ingress: %w", err)
+	}
+
+	if c.k8s.IngressExists(ctx, c.namespace, monitoringIngress) {
+		if err := c.k8s.IngressUpdate(ctx, c.namespace, grafanaIngress(c.namespace, hosts, c.ingressController, c.ingressClass)); err != nil {
+			pterm.Error.Println("Unable to update the monitoring Ingress")
+			return fmt.Errorf("could not update monitoring ingress: %w", err)
+		}
+	}
+
+	pterm.Success.Println("Updated Ingress")
+	return nil
+}
@@ -0,0 +1,125 @@
+package local
+
+import (
+	"context"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	"net/http"
+	"testing"
+)
+
+func TestCommand_Hosts(t *testing.T) {
+	tests := []struct {
+		name       string
+		add        []string
+		remove     []string
+		monitoring bool
+		exp        []string
+		updates    []string
+		requested  []string
+		err        bool
+	}{
+		{
+			name:      "add",
+			add:       []string{"airbyte.lan", "localhost", "airbyte.lan"},
+			exp:       []string{"localhost", "example.com", "airbyte.lan"},
+			updates:   []string{airbyteIngress},
+			requested: []string{"http://airbyte.lan:9999"},
+		},
+		{
+			name:       "add with monitoring",
+			add:        []string{"airbyte.lan"},
+			monitoring: true,
+			exp:        []string{"localhost", "example.com", "airbyte.lan"},
+			updates:    []string{airbyteIngress, monitoringIngress},
+			requested:  []string{"http://airbyte.lan:9999"},
+		},
+		{
+			name: "add existing",
+			add:  []string{"localhost"},
+			exp:  []string{"localhost", "example.com"},
+		},
+		{
+			name:    "remove",
+			remove:  []string{"example.com", "unknown"},
+			exp:     []string{"localhost"},
+			updates: []string{airbyteIngress},
+		},
+		{
+			name:   "remove all",
+			remove: []string{"example.com", "localhost"},
+			exp:    []string{"localhost", "example.com"},
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				current   = ingress(airbyteNamespace, []string{"localhost", "example.com"}, IngressNginx, "")
+				updates   []string
+				requested []string
+			)
+
+			k8sClient := mockK8sClient{
+				serverVersionGet: func() (string, error) {
+					return "test", nil
+				},
+				ingressGet: func(ctx context.Context, namespace string, name string) (*networkingv1.Ingress, error) {
+					return current, nil
+				},
+				ingressExists: func(ctx context.Context, namespace string, name string) bool {
+					return name == airbyteIngress || tt.monitoring
+				},
+				ingressUpdate: func(ctx context.Context, namespace string, ing *networkingv1.Ingress) error {
+					updates = append(updates, ing.Name)
+					if ing.Name == airbyteIngress {
+						current = ing
+					}
+					return nil
+				},
+			}
+
+			httpClient := mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+				requested = append(requested, req.URL.String())
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			}}
+
+			c, err := New(
+				k8s.TestProvider,
+				WithPortHTTP(9999),
+				WithHelmClient(&mockHelmClient{}),
+				WithK8sClient(&k8sClient),
+				WithTelemetryClient(&mockTelemetryClient{}),
+				WithHTTPClient(&httpClient),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(tt.add) > 0 {
+				err = c.AddHosts(context.Background(), tt.add)
+			} else {
+				err = c.RemoveHosts(context.Background(), tt.remove)
+			}
+			if tt.err != (err != nil) {
+				t.Fatal("unexpected error result", err)
+			}
+
+			hosts, err := c.Hosts(context.Background())
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, hosts); d != "" {
+				t.Error("hosts mismatch (-want +got):", d)
+			}
+			if d := cmp.Diff(tt.updates, updates); d != "" {
+				t.Error("updates mismatch (-want +got):", d)
+			}
+			if d := cmp.Diff(tt.requested, requested); d != "" {
+				t.Error("requested mismatch (-want +got):", d)
+			}
+		})
+	}
+}
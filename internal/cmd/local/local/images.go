@@ -0,0 +1,94 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"io"
+	"sort"
+	"strings"
+)
+
+// FindImagesFromChart renders the chart, with the valuesYAML overriding its default values, and returns the images
+// referenced by the rendered manifests, sorted and without duplicates.
+// Besides the image of every container, the values of environment variables and config map keys ending in "_IMAGE"
+// are included, as Airbyte launches the job and connector sidecar images it is configured with at runtime.
+// The chart is rendered locally, no kubernetes cluster is required. A chart missing any of its dependencies, e.g. a
+// chart directory whose dependencies were not built, returns an error, as the images of the dependencies would be
+// missing.
+func FindImagesFromChart(c *chart.Chart, valuesYAML string) ([]string, error) {
+	if err := checkDependencies(c); err != nil {
+		return nil, err
+	}
+
+	vals, err := chartutil.ReadValues([]byte(valuesYAML))
+	if err != nil {
+		return nil, fmt.Errorf("could not read values: %w", err)
+	}
+
+	install := action.NewInstall(&action.Configuration{Log: func(string, ...interface{}) {}})
+	install.ClientOnly = true
+	install.DryRun = true
+	install.Replace = true
+	install.ReleaseName = airbyteChartRelease
+	install.Namespace = airbyteNamespace
+
+	rel, err := install.Run(c, vals)
+	if err != nil {
+		return nil, fmt.Errorf("could not render chart %s: %w", c.Name(), err)
+	}
+
+	manifests := []string{rel.Manifest}
+	for _, hook := range rel.Hooks {
+		manifests = append(manifests, hook.Manifest)
+	}
+
+	found := map[string]struct{}{}
+	for _, manifest := range manifests {
+		dec := yaml.NewDecoder(strings.NewReader(manifest))
+		for {
+			var doc interface{}
+			if err := dec.Decode(&doc); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("could not decode rendered manifest: %w", err)
+			}
+			collectImages(doc, found)
+		}
+	}
+
+	images := make([]string, 0, len(found))
+	for img := range found {
+		images = append(images, img)
+	}
+	sort.Strings(images)
+
+	return images, nil
+}
+
+// collectImages walks the decoded manifest, adding every image it references to found.
+func collectImages(v interface{}, found map[string]struct{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		// environment variables are defined as a list of name and value pairs
+		if name, ok := v["name"].(string); ok && strings.HasSuffix(name, "_IMAGE") {
+			if img, ok := v["value"].(string); ok && img != "" {
+				found[img] = struct{}{}
+			}
+		}
+		for k, val := range v {
+			if img, ok := val.(string); ok && img != "" && (k == "image" || strings.HasSuffix(k, "_IMAGE")) {
+				found[img] = struct{}{}
+				continue
+			}
+			collectImages(val, found)
+		}
+	case []interface{}:
+		for _, val := range v {
+			collectImages(val, found)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package local
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"helm.sh/helm/v3/pkg/chart"
+	"testing"
+)
+
+const testImagesTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox:1.35
+      containers:
+      - name: server
+        image: "airbyte/server:{{ .Values.version }}"
+        env:
+        - name: JOB_KUBE_SOCAT_IMAGE
+          value: alpine/socat:1.7.4.4-r0
+        - name: LOG_LEVEL
+          value: INFO
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: env
+data:
+  CONTAINER_ORCHESTRATOR_IMAGE: "airbyte/container-orchestrator:{{ .Values.version }}"
+  JOB_KUBE_BUSYBOX_IMAGE: busybox:1.35
+  UNUSED_IMAGE: ""
+`
+
+const testImagesHook = `apiVersion: v1
+kind: Pod
+metadata:
+  name: bootloader
+  annotations:
+    helm.sh/hook: pre-install
+spec:
+  containers:
+  - name: bootloader
+    image: "airbyte/bootloader:{{ .Values.version }}"
+`
+
+func TestFindImagesFromChart(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "airbyte", Version: "1.0.0"},
+		Values:   map[string]interface{}{"version": "0.50.0"},
+		Templates: []*chart.File{
+			{Name: "templates/server.yaml", Data: []byte(testImagesTemplate)},
+			{Name: "templates/bootloader.yaml", Data: []byte(testImagesHook)},
+		},
+	}
+
+	images, err := FindImagesFromChart(c, "version: 0.60.0")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := []string{
+		"airbyte/bootloader:0.60.0",
+		"airbyte/container-orchestrator:0.60.0",
+		"airbyte/server:0.60.0",
+		"alpine/socat:1.7.4.4-r0",
+		"busybox:1.35",
+	}
+	if d := cmp.Diff(exp, images); d != "" {
+		t.Error("images mismatch (-want +got):", d)
+	}
+
+	if _, err := FindImagesFromChart(c, "invalid: [yaml"); err == nil {
+		t.Error("expected error for invalid values")
+	}
+}
@@ -0,0 +1,314 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/cmd/local/progress"
+	"github.com/airbytehq/abctl/internal/cmd/local/readiness"
+	"github.com/pterm/pterm"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The ingress controllers which can be installed in front of Airbyte.
+const (
+	// IngressNginx installs ingress-nginx, which is the default.
+	IngressNginx = "nginx"
+	// IngressTraefik installs traefik, with its Middleware protecting the ingress by basic-auth.
+	IngressTraefik = "traefik"
+	// IngressNone installs no ingress controller, Airbyte is exposed by its webapp service instead.
+	IngressNone = "none"
+)
+
+const (
+	traefikChartName    = "traefik/traefik"
+	traefikChartRelease = "traefik"
+	traefikNamespace    = "traefik"
+	traefikRepoName     = "traefik"
+	traefikRepoURL      = "https://traefik.github.io/charts"
+)
+
+// ValidateIngressController verifies the controller is one of IngressNginx, IngressTraefik, or IngressNone.
+func ValidateIngressController(controller string) error {
+	switch controller {
+	case IngressNginx, IngressTraefik, IngressNone:
+		return nil
+	default:
+		return fmt.Errorf("invalid ingress controller %s, must be one of %s, %s, or %s", controller, IngressNginx, IngressTraefik, IngressNone)
+	}
+}
+
+// ingressControllerRelease returns the helm release and namespace of the controller, which are empty for IngressNone.
+func ingressControllerRelease(controller string) (release string, namespace string) {
+	switch controller {
+	case IngressTraefik:
+		return traefikChartRelease, traefikNamespace
+	case IngressNone:
+		return "", ""
+	default:
+		return nginxChartRelease, nginxNamespace
+	}
+}
+
+// releaseHelm returns the helm client storing the release, which is ingressHelm for the release of any ingress
+// controller, as a single controller serves every installation of the cluster, whatever their namespace.
+func (c *Command) releaseHelm(release string) HelmClient {
+	if release == nginxChartRelease || release == traefikChartRelease {
+		return c.ingressHelm
+	}
+	return c.helm
+}
+
+// ingressControllerChart returns the chartRequest installing the controller, which is not valid for IngressNone.
+// The values include the mirror values, if any.
+func (c *Command) ingressControllerChart(controller string, mirror []string, download *DownloadOpts) chartRequest {
+	if controller == IngressTraefik {
+		values := append(append([]string{}, c.provider.HelmTraefik...),
+			fmt.Sprintf("ports.web.exposedPort=%d", c.portHTTP),
+			// traefik must not claim the ingresses of any other ingress controller of an existing cluster
+			"ingressClass.isDefaultClass=false",
+		)
+		return chartRequest{
+			name:         "traefik",
+			repoName:     traefikRepoName,
+			repoURL:      traefikRepoURL,
+			chartName:    traefikChartName,
+			chartRelease: traefikChartRelease,
+			namespace:    traefikNamespace,
+			values:       append(values, mirror...),
+			valuesYAML:   traefikBasicAuthValues(c.namespace),
+			download:     download,
+		}
+	}
+
+	values := append(append([]string{}, c.provider.HelmNginx...), fmt.Sprintf("controller.service.ports.http=%d", c.portHTTP))
+	if c.apiPort != 0 {
+		// the api port is proxied by nginx directly to the airbyte-server service
+		values = append(values, fmt.Sprintf("tcp.%d=%s/%s:%d", c.apiPort, c.namespace, airbyteServerService, airbyteServerPort))
+	}
+	return chartRequest{
+		name:         "nginx",
+		repoName:     nginxRepoName,
+		repoURL:      nginxRepoURL,
+		chartName:    nginxChartName,
+		chartRelease: nginxChartRelease,
+		namespace:    nginxNamespace,
+		values:       append(values, mirror...),
+		download:     download,
+	}
+}
+
+// prepareIngressController prepares the chart of the controller, which is nil if it is IngressNone.
+// Any other controller installed previously is kept until uninstallIngressController, so a chart which cannot be
+// prepared leaves the installation with its previous controller.
+func (c *Command) prepareIngressController(ctx context.Context, controller string, mirror []string, download *DownloadOpts) (*preparedChart, error) {
+	if controller == IngressNone {
+		return nil, nil
+	}
+
+	// the release of the controller is stored in airbyteNamespace, which only exists if Airbyte was installed into it
+	if c.namespace != airbyteNamespace && !c.k8s.NamespaceExists(ctx, airbyteNamespace) {
+		if err := c.k8s.NamespaceCreate(ctx, airbyteNamespace); err != nil {
+			pterm.Error.Printfln("Could not create namespace '%s'", airbyteNamespace)
+			return nil, fmt.Errorf("could not create namespace %s: %w", airbyteNamespace, err)
+		}
+	}
+
+	req := c.ingressControllerChart(controller, mirror, download)
+	p, err := c.prepareRelease(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("could not install %s chart: %w", req.name, err)
+	}
+	return &p, nil
+}
+
+// uninstallIngressController uninstalls the controller installed previously, if it is not the controller, as it would
+// otherwise conflict on the port. Its progress is only reported to report, so it can be uninstalled right before the
+// controller is installed, concurrently with Airbyte.
+// It returns true if a previous controller was uninstalled.
+func (c *Command) uninstallIngressController(controller string, report progress.Reporter) (bool, error) {
+	if controller == c.ingressController {
+		return false, nil
+	}
+	release, _ := ingressControllerRelease(c.ingressController)
+	if release == "" {
+		return false, nil
+	}
+
+	report(fmt.Sprintf("Uninstalling the %s ingress controller", c.ingressController))
+	switch err := c.ingressHelm.UninstallReleaseByName(release); {
+	case errors.Is(err, driver.ErrReleaseNotFound):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("could not uninstall the %s ingress controller: %w", c.ingressController, err)
+	}
+	return true, nil
+}
+
+// ingressControllerError returns the error of installing the controller, diagnosing whether the port is unavailable.
+func (c *Command) ingressControllerError(ctx context.Context, controller string, p preparedChart, err error) error {
+	// If we timed out, there is a good chance it's due to an unavailable port, check if this is the case.
+	// As the kubernetes client doesn't return usable error types, have to check for a specific string value.
+	if controller == IngressNginx && strings.Contains(err.Error(), "client rate limiter Wait returned an error") {
+		pterm.Warning.Printfln("Encountered an error while installing the %s Helm Chart.\n"+
+			"This could be an indication that port %d is not available.\n"+
+			"If installation fails, please try again with a different port.", nginxChartName, c.portHTTP)
+
+		srv, err := c.k8s.ServiceGet(ctx, nginxNamespace, "ingress-nginx-controller")
+		// If there is an error, we can ignore it as we only are checking for a missing ingress entry,
+		// and an error would indicate the inability to check for that entry.
+		if err == nil {
+			ingresses := srv.Status.LoadBalancer.Ingress
+			if len(ingresses) == 0 {
+				// if there are no ingresses, that is a possible indicator that the port is already in use.
+				return fmt.Errorf("%w: could not install nginx chart", localerr.ErrIngress)
+			}
+		}
+	}
+	return fmt.Errorf("could not install %s chart: %w", p.req.name, err)
+}
+
+// defaultIngressClassAnnotation marks the default ingress class of a cluster.
+const defaultIngressClassAnnotation = "ingressclass.kubernetes.io/is-default-class"
+
+// ExistingIngressClass returns the ingress class of an ingress controller of the cluster which was not installed by
+// abctl, so it can serve Airbyte instead of installing one. The default ingress class is preferred, otherwise the only
+// ingress class is returned. An empty class is returned if there is no such controller, or if it is ambiguous.
+func (c *Command) ExistingIngressClass(ctx context.Context) (string, error) {
+	classes, err := c.k8s.IngressClassList(ctx)
+	if err != nil {
+		return "", fmt.Errorf("could not list ingress classes: %w", err)
+	}
+
+	var existing []string
+	for _, class := range classes.Items {
+		if abctlIngressClass(class.Annotations) {
+			continue
+		}
+		if class.Annotations[defaultIngressClassAnnotation] == "true" {
+			return class.Name, nil
+		}
+		existing = append(existing, class.Name)
+	}
+
+	if len(existing) != 1 {
+		return "", nil
+	}
+	return existing[0], nil
+}
+
+// abctlIngressClass returns true if the annotations are of an ingress class of a helm release of an ingress
+// controller installed by abctl.
+func abctlIngressClass(annotations map[string]string) bool {
+	for _, controller := range []string{IngressNginx, IngressTraefik} {
+		release, namespace := ingressControllerRelease(controller)
+		if annotations["meta.helm.sh/release-name"] == release && annotations["meta.helm.sh/release-namespace"] == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// webappServiceType returns the type of the webapp service, which is how Airbyte is accessed without an ingress
+// controller of abctl.
+func webappServiceType(provider k8s.Provider) string {
+	if provider.Name == k8s.Kind {
+		return "NodePort"
+	}
+	return "LoadBalancer"
+}
+
+// printIngressInstructions prints how Airbyte can be accessed without an ingress controller of abctl.
+func (c *Command) printIngressInstructions() {
+	svc := fmt.Sprintf("%s-airbyte-webapp-svc", airbyteChartRelease)
+
+	if c.ingressClass != "" {
+		pterm.Info.Printfln("No ingress controller was installed, the Ingress '%s' is served by the existing ingress class '%s'.\n"+
+			"Access Airbyte via the hosts of the Ingress on the ports of its ingress controller. The basic-auth credentials\n"+
+			"are only enforced if the ingress controller is ingress-nginx, using the '%s' secret.",
+			airbyteIngress, c.ingressClass, basicAuthSecret)
+		return
+	}
+
+	pterm.Info.Printfln("No ingress controller was installed, Airbyte is exposed by the %s service '%s' in namespace '%s'.\n"+
+		"The Ingress '%s' is served by the default ingress class of the cluster, if any. Otherwise route an ingress of\n"+
+		"your own ingress controller to port 80 of the service, or access Airbyte via\n"+
+		"  kubectl --kubeconfig %s --context %s --namespace %s port-forward svc/%s %d:80\n"+
+		"and then http://localhost:%d. The basic-auth credentials are only enforced by an ingress using the '%s' secret.",
+		webappServiceType(c.provider), svc, c.namespace, airbyteIngress,
+		c.provider.KubeconfigPath(c.userHome), c.provider.Context, c.namespace, svc, c.portHTTP,
+		c.portHTTP, basicAuthSecret)
+}
+
+const (
+	// airbyteServerService is the service of the Airbyte api, which is exposed on the api port.
+	airbyteServerService = airbyteChartRelease + "-airbyte-server-svc"
+	airbyteServerPort    = 8001
+)
+
+// apiURL returns the url of the Airbyte api exposed on the api port of the host.
+func (c *Command) apiURL(host string) string {
+	return baseURL(host, c.apiPort)
+}
+
+// apiPortCheck returns the readiness.Check verifying the Airbyte api is served on the api port of the host.
+// The api port is not protected by basic-auth, as it is not served by an ingress.
+func (c *Command) apiPortCheck(host string) readiness.Check {
+	return readiness.New(fmt.Sprintf("api port %d", c.apiPort), readiness.Health(c.http, c.apiURL(host), "", "").Ready)
+}
+
+// verifyAPIPort verifies the Airbyte api is served on the api port of the host, if it is exposed.
+func (c *Command) verifyAPIPort(ctx context.Context, host string) error {
+	if c.apiPort == 0 {
+		return nil
+	}
+
+	c.spinner.UpdateText(fmt.Sprintf("Verifying api port %d", c.apiPort))
+	waitCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := readiness.Wait(waitCtx, time.Second, c.apiPortCheck(host)); err != nil {
+		pterm.Error.Printfln("Timed out waiting for the api on port %d", c.apiPort)
+		return fmt.Errorf("could not verify api port %d: %w", c.apiPort, err)
+	}
+
+	pterm.Success.Printfln("The Airbyte api is accessible via %s", c.apiURL(host))
+	pterm.Warning.Printfln("The api port %d is not protected by basic-auth, ensure it is only reachable by trusted clients", c.apiPort)
+	return nil
+}
+
+// bindTransport returns the http.RoundTripper verifying the ingress on the bindAddress its port is bound to, or on
+// localhost if it is bound to all addresses. Every connection is made to that address, while the requests keep the
+// host of their url, which is matched by the rules of the ingress, so a host which does not resolve to this machine
+// (e.g. a hostname only resolved by other machines) is verified regardless.
+// Nil (the default transport) is returned if the port is bound to all addresses and the ingress is accessed via a host
+// other than DefaultHost, e.g. a remote docker host.
+func bindTransport(bindAddress, host string) http.RoundTripper {
+	dialAddress := k8s.DialAddress(bindAddress)
+	if dialAddress == "localhost" && host != DefaultHost {
+		return nil
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		_, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		return dialer.DialContext(ctx, network, net.JoinHostPort(dialAddress, port))
+	}
+	return transport
+}
+
+// baseURL returns the url of the port of the host, which may be an ipv6 address.
+func baseURL(host string, port int) string {
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(port))
+}
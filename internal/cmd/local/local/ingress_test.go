@@ -0,0 +1,539 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	helmclient "github.com/mittwald/go-helm-client"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"io"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidateIngressController(t *testing.T) {
+	for _, controller := range []string{IngressNginx, IngressTraefik, IngressNone} {
+		if err := ValidateIngressController(controller); err != nil {
+			t.Errorf("unexpected error for '%s': %s", controller, err)
+		}
+	}
+
+	for _, controller := range []string{"", "haproxy", "Nginx"} {
+		if err := ValidateIngressController(controller); err == nil {
+			t.Errorf("expected error for '%s'", controller)
+		}
+	}
+}
+
+func TestIngress_Controller(t *testing.T) {
+	nginx := "nginx"
+	traefik := "traefik"
+
+	tests := []struct {
+		controller  string
+		class       *string
+		annotations map[string]string
+	}{
+		{
+			controller: IngressNginx,
+			class:      &nginx,
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/auth-type":   "basic",
+				"nginx.ingress.kubernetes.io/auth-secret": "basic-auth",
+				"nginx.ingress.kubernetes.io/auth-realm":  "Authentication Required - Airbyte (abctl)",
+			},
+		},
+		{
+			controller: IngressTraefik,
+			class:      &traefik,
+			annotations: map[string]string{
+				"traefik.ingress.kubernetes.io/router.middlewares": "test-basic-auth@kubernetescrd",
+			},
+		},
+		{
+			controller: IngressNone,
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/auth-type":   "basic",
+				"nginx.ingress.kubernetes.io/auth-secret": "basic-auth",
+				"nginx.ingress.kubernetes.io/auth-realm":  "Authentication Required - Airbyte (abctl)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.controller, func(t *testing.T) {
+			for _, ing := range []interface{ GetAnnotations() map[string]string }{
+				ingress("test", []string{"localhost"}, tt.controller, ""),
+				grafanaIngress("test", []string{"localhost"}, tt.controller, ""),
+			} {
+				if d := cmp.Diff(tt.annotations, ing.GetAnnotations()); d != "" {
+					t.Error("annotations mismatch (-want +got):", d)
+				}
+			}
+
+			if d := cmp.Diff(tt.class, ingress("test", []string{"localhost"}, tt.controller, "").Spec.IngressClassName); d != "" {
+				t.Error("ingress class mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestCommand_Install_IngressController(t *testing.T) {
+	tests := []struct {
+		name       string
+		installed  string
+		controller string
+		values     map[string][]string
+		uninstalls []string
+		launched   bool
+	}{
+		{
+			name:       "nginx",
+			installed:  IngressNginx,
+			controller: IngressNginx,
+			values: map[string][]string{
+				airbyteChartRelease: {"global.env_vars.AIRBYTE_INSTALLATION_ID="},
+				nginxChartRelease:   {fmt.Sprintf("controller.service.ports.http=%d", portTest)},
+			},
+			launched: true,
+		},
+		{
+			name:       "traefik replacing nginx",
+			installed:  IngressNginx,
+			controller: IngressTraefik,
+			values: map[string][]string{
+				airbyteChartRelease: {"global.env_vars.AIRBYTE_INSTALLATION_ID="},
+				traefikChartRelease: {fmt.Sprintf("ports.web.exposedPort=%d", portTest), "ingressClass.isDefaultClass=false"},
+			},
+			uninstalls: []string{nginxChartRelease},
+			launched:   true,
+		},
+		{
+			name:       "none replacing traefik",
+			installed:  IngressTraefik,
+			controller: IngressNone,
+			values: map[string][]string{
+				airbyteChartRelease: {"global.env_vars.AIRBYTE_INSTALLATION_ID=", "webapp.service.type=LoadBalancer"},
+			},
+			uninstalls: []string{traefikChartRelease},
+		},
+		{
+			name:       "none without any previous ingress controller",
+			installed:  IngressNone,
+			controller: IngressNone,
+			values: map[string][]string{
+				airbyteChartRelease: {"global.env_vars.AIRBYTE_INSTALLATION_ID=", "webapp.service.type=LoadBalancer"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				values     = map[string][]string{}
+				uninstalls []string
+				launched   bool
+			)
+
+			helm := mockHelmClient{
+				addOrUpdateChartRepo: func(entry repo.Entry) error {
+					return nil
+				},
+				getChart: func(name string, _ *action.ChartPathOptions) (*chart.Chart, string, error) {
+					return &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, "", nil
+				},
+				installOrUpgradeChart: func(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+					values[spec.ReleaseName] = spec.ValuesOptions.Values
+					return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, Name: spec.ReleaseName}, nil
+				},
+				uninstallReleaseByName: func(name string) error {
+					uninstalls = append(uninstalls, name)
+					return driver.ErrReleaseNotFound
+				},
+			}
+
+			k8sClient := mockK8sClient{
+				podList: readyPods,
+				serverVersionGet: func() (string, error) {
+					return "test", nil
+				},
+			}
+
+			httpClient := mockHTTP{do: readyResponse}
+
+			c, err := New(
+				k8s.TestProvider,
+				WithPortHTTP(portTest),
+				WithHelmClient(&helm),
+				WithK8sClient(&k8sClient),
+				WithTelemetryClient(&mockTelemetryClient{user: func() uuid.UUID { return uuid.Nil }}),
+				WithHTTPClient(&httpClient),
+				WithIngressController(tt.installed),
+				WithBrowserLauncher(func(url string) error {
+					launched = true
+					return nil
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := c.Install(context.Background(), InstallOpts{User: "user", Pass: "pass", IngressController: tt.controller}); err != nil {
+				t.Fatal(err)
+			}
+
+			if d := cmp.Diff(tt.values, values); d != "" {
+				t.Error("values mismatch (-want +got):", d)
+			}
+			if d := cmp.Diff(tt.uninstalls, uninstalls); d != "" {
+				t.Error("uninstalls mismatch (-want +got):", d)
+			}
+			if d := cmp.Diff(tt.launched, launched); d != "" {
+				t.Error("launched mismatch (-want +got):", d)
+			}
+			if d := cmp.Diff(tt.controller, c.ingressController); d != "" {
+				t.Error("ingress controller mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestCommand_Install_IngressControllerKept(t *testing.T) {
+	tests := []struct {
+		name        string
+		controller  string
+		failChart   string
+		failRelease string
+	}{
+		{name: "traefik chart unavailable", controller: IngressTraefik, failChart: traefikChartName},
+		{name: "airbyte failing without an ingress controller", controller: IngressNone, failRelease: airbyteChartRelease},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var uninstalls []string
+
+			helm := mockHelmClient{
+				addOrUpdateChartRepo: func(entry repo.Entry) error {
+					return nil
+				},
+				getChart: func(name string, _ *action.ChartPathOptions) (*chart.Chart, string, error) {
+					if name == tt.failChart {
+						return nil, "", errors.New("digest mismatch")
+					}
+					return &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, "", nil
+				},
+				installOrUpgradeChart: func(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+					if spec.ReleaseName == tt.failRelease {
+						return nil, errors.New("timed out waiting for the condition")
+					}
+					return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, Name: spec.ReleaseName}, nil
+				},
+				uninstallReleaseByName: func(name string) error {
+					uninstalls = append(uninstalls, name)
+					return nil
+				},
+			}
+
+			c, err := New(
+				k8s.TestProvider,
+				WithPortHTTP(portTest),
+				WithHelmClient(&helm),
+				WithK8sClient(&mockK8sClient{podList: readyPods}),
+				WithTelemetryClient(&mockTelemetryClient{user: func() uuid.UUID { return uuid.Nil }}),
+				WithHTTPClient(&mockHTTP{do: readyResponse}),
+				WithIngressController(IngressNginx),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := c.Install(context.Background(), InstallOpts{User: "user", Pass: "pass", IngressController: tt.controller}); err == nil {
+				t.Fatal("expected an error")
+			}
+
+			// the installation is left with its previous ingress controller
+			if len(uninstalls) > 0 {
+				t.Error("expected the previous ingress controller to be kept, uninstalled", uninstalls)
+			}
+			if d := cmp.Diff(IngressNginx, c.ingressController); d != "" {
+				t.Error("ingress controller mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestCommand_ExistingIngressClass(t *testing.T) {
+	class := func(name string, annotations map[string]string) networkingv1.IngressClass {
+		return networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+	}
+	abctlNginx := class("nginx", map[string]string{
+		"meta.helm.sh/release-name":      nginxChartRelease,
+		"meta.helm.sh/release-namespace": nginxNamespace,
+	})
+
+	tests := []struct {
+		name    string
+		classes []networkingv1.IngressClass
+		exp     string
+	}{
+		{name: "none"},
+		{name: "abctl only", classes: []networkingv1.IngressClass{abctlNginx}},
+		{name: "single", classes: []networkingv1.IngressClass{abctlNginx, class("haproxy", nil)}, exp: "haproxy"},
+		{name: "ambiguous", classes: []networkingv1.IngressClass{class("haproxy", nil), class("contour", nil)}},
+		{
+			name:    "default",
+			classes: []networkingv1.IngressClass{class("haproxy", nil), class("contour", map[string]string{defaultIngressClassAnnotation: "true"})},
+			exp:     "contour",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := mockK8sClient{
+				ingressClassList: func(ctx context.Context) (*networkingv1.IngressClassList, error) {
+					return &networkingv1.IngressClassList{Items: tt.classes}, nil
+				},
+			}
+			c, err := New(k8s.TestProvider, WithK8sClient(&k8sClient), WithHelmClient(&mockHelmClient{}), WithTelemetryClient(&mockTelemetryClient{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			class, err := c.ExistingIngressClass(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := cmp.Diff(tt.exp, class); d != "" {
+				t.Error("class mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestCommand_Install_IngressClass(t *testing.T) {
+	var (
+		values  = map[string][]string{}
+		created *networkingv1.Ingress
+	)
+
+	helm := mockHelmClient{
+		addOrUpdateChartRepo: func(entry repo.Entry) error {
+			return nil
+		},
+		getChart: func(name string, _ *action.ChartPathOptions) (*chart.Chart, string, error) {
+			return &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, "", nil
+		},
+		installOrUpgradeChart: func(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+			values[spec.ReleaseName] = spec.ValuesOptions.Values
+			return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, Name: spec.ReleaseName}, nil
+		},
+		uninstallReleaseByName: func(name string) error {
+			return driver.ErrReleaseNotFound
+		},
+	}
+
+	k8sClient := mockK8sClient{
+		podList: readyPods,
+		ingressExists: func(ctx context.Context, namespace string, ingress string) bool {
+			return false
+		},
+		ingressCreate: func(ctx context.Context, namespace string, ingress *networkingv1.Ingress) error {
+			created = ingress
+			return nil
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithHelmClient(&helm),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{user: func() uuid.UUID { return uuid.Nil }}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Install(context.Background(), InstallOpts{User: "user", Pass: "pass", IngressController: IngressNone, IngressClass: "haproxy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the webapp service is not exposed, as the existing ingress controller serves Airbyte
+	exp := map[string][]string{airbyteChartRelease: {"global.env_vars.AIRBYTE_INSTALLATION_ID="}}
+	if d := cmp.Diff(exp, values); d != "" {
+		t.Error("values mismatch (-want +got):", d)
+	}
+	if created == nil {
+		t.Fatal("expected the ingress to be created")
+	}
+	class := "haproxy"
+	if d := cmp.Diff(&class, created.Spec.IngressClassName); d != "" {
+		t.Error("ingress class mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_Install_APIPort(t *testing.T) {
+	var (
+		values    = map[string][]string{}
+		requested []string
+	)
+
+	helm := mockHelmClient{
+		addOrUpdateChartRepo: func(entry repo.Entry) error {
+			return nil
+		},
+		getChart: func(name string, _ *action.ChartPathOptions) (*chart.Chart, string, error) {
+			return &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, "", nil
+		},
+		installOrUpgradeChart: func(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+			values[spec.ReleaseName] = spec.ValuesOptions.Values
+			return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, Name: spec.ReleaseName}, nil
+		},
+	}
+
+	k8sClient := mockK8sClient{
+		podList: readyPods,
+		serverVersionGet: func() (string, error) {
+			return "test", nil
+		},
+	}
+
+	httpClient := mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+		requested = append(requested, req.URL.String())
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(`{"available":true}`))}, nil
+	}}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithAPIPort(8001),
+		WithHelmClient(&helm),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{user: func() uuid.UUID { return uuid.Nil }}),
+		WithHTTPClient(&httpClient),
+		WithBrowserLauncher(func(url string) error {
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Install(context.Background(), InstallOpts{User: "user", Pass: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{
+		fmt.Sprintf("controller.service.ports.http=%d", portTest),
+		"tcp.8001=airbyte-abctl/airbyte-abctl-airbyte-server-svc:8001",
+	}
+	if d := cmp.Diff(exp, values[nginxChartRelease]); d != "" {
+		t.Error("nginx values mismatch (-want +got):", d)
+	}
+
+	expRequested := []string{
+		fmt.Sprintf("http://localhost:%d/api/v1/health", portTest),
+		fmt.Sprintf("http://localhost:%d", portTest),
+		fmt.Sprintf("http://localhost:%d", portTest),
+		"http://localhost:8001/api/v1/health",
+	}
+	if d := cmp.Diff(expRequested, requested); d != "" {
+		t.Error("requests mismatch (-want +got):", d)
+	}
+}
+
+func TestBindTransport(t *testing.T) {
+	for _, address := range []string{"", "0.0.0.0", "::"} {
+		if transport := bindTransport(address, "remote.example.com"); transport != nil {
+			t.Errorf("expected the default transport for '%s'", address)
+		}
+	}
+
+	var host string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+	}))
+	defer srv.Close()
+
+	_, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the host does not resolve, the request must be dialed to the bind address, or localhost, regardless
+	for _, address := range []string{"127.0.0.1", ""} {
+		host = ""
+		client := &http.Client{Transport: bindTransport(address, DefaultHost)}
+		res, err := client.Get("http://airbyte.invalid:" + port)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if d := cmp.Diff("airbyte.invalid:"+port, host); d != "" {
+			t.Errorf("host mismatch for '%s' (-want +got): %s", address, d)
+		}
+	}
+}
+
+func TestCommand_OpenBrowser_Unresolved(t *testing.T) {
+	var launched []string
+	c, err := New(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&mockK8sClient{}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithHTTPClient(&mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}}),
+		WithBrowserLauncher(func(url string) error {
+			launched = append(launched, url)
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the ingress serves the host, which the web-browser cannot resolve however
+	if err := c.openBrowser(context.Background(), "airbyte.invalid", ""); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if err := c.openBrowser(context.Background(), "localhost", ""); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff([]string{fmt.Sprintf("http://localhost:%d", portTest)}, launched); d != "" {
+		t.Error("launched mismatch (-want +got):", d)
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	tests := []struct {
+		host string
+		exp  string
+	}{
+		{host: "localhost", exp: "http://localhost:8000"},
+		{host: "192.168.1.10", exp: "http://192.168.1.10:8000"},
+		{host: "::1", exp: "http://[::1]:8000"},
+	}
+
+	for _, tt := range tests {
+		if d := cmp.Diff(tt.exp, baseURL(tt.host, 8000)); d != "" {
+			t.Errorf("url mismatch for %s (-want +got): %s", tt.host, d)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package local
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/pterm/pterm"
+	"golang.org/x/mod/semver"
+	"strings"
+)
+
+// k8sSupport is the range of kubernetes minor versions the airbyte chart versions from chartVersion onwards, up to
+// the chartVersion of the next k8sSupport, support.
+type k8sSupport struct {
+	chartVersion string
+	min          string
+	max          string
+}
+
+// k8sSupportMatrix is ordered by chartVersion, its last entry applies to the latest chart version.
+var k8sSupportMatrix = []k8sSupport{
+	{chartVersion: "v0.0.0", min: "v1.21", max: "v1.29"},
+	{chartVersion: "v1.0.0", min: "v1.25", max: "v1.31"},
+}
+
+// canonicalVersion returns the version with a 'v' prefix, as semver requires, e.g. 1.29.1 as v1.29.1.
+func canonicalVersion(version string) string {
+	if version == "" || strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
+
+// supportedK8s returns the kubernetes versions the chartVersion supports.
+// An empty chartVersion is the latest chart version, as is any chartVersion which is not a semver.
+func supportedK8s(chartVersion string) k8sSupport {
+	version := canonicalVersion(chartVersion)
+	if !semver.IsValid(version) {
+		return k8sSupportMatrix[len(k8sSupportMatrix)-1]
+	}
+
+	for i := len(k8sSupportMatrix) - 1; i > 0; i-- {
+		if semver.Compare(version, k8sSupportMatrix[i].chartVersion) >= 0 {
+			return k8sSupportMatrix[i]
+		}
+	}
+	return k8sSupportMatrix[0]
+}
+
+// checkK8sVersion verifies the kubernetes version of the cluster is supported by the chartVersion.
+// An older version than the chart supports fails the installation, unless skip is true, as the chart relies on apis
+// the cluster does not provide. A newer version than the chart was verified with only warns, as it usually works.
+func (c *Command) checkK8sVersion(chartVersion string, skip bool) error {
+	version := semver.MajorMinor(canonicalVersion(c.k8sVersion))
+	if version == "" {
+		logging.Debugf("could not determine the minor version of kubernetes %s", c.k8sVersion)
+		return nil
+	}
+
+	chart := chartVersion
+	if chart == "" {
+		chart = "latest"
+	}
+
+	support := supportedK8s(chartVersion)
+	switch {
+	case semver.Compare(version, support.min) < 0 && skip:
+		pterm.Warning.Printfln("Kubernetes %s is older than %s, the oldest version the Airbyte chart %s supports, installing regardless",
+			version, support.min, chart)
+	case semver.Compare(version, support.min) < 0:
+		pterm.Error.Printfln("Kubernetes %s is older than %s, the oldest version the Airbyte chart %s supports.\n"+
+			"Upgrade the cluster, or provide --skip-k8s-version-check to install regardless.", version, support.min, chart)
+		return fmt.Errorf("%w: kubernetes %s is older than %s", localerr.ErrK8sVersion, c.k8sVersion, support.min)
+	case semver.Compare(version, support.max) > 0:
+		pterm.Warning.Printfln("Kubernetes %s is newer than %s, the newest version the Airbyte chart %s was verified with",
+			version, support.max, chart)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestSupportedK8s(t *testing.T) {
+	tests := []struct {
+		chartVersion string
+		exp          k8sSupport
+	}{
+		{chartVersion: "", exp: k8sSupportMatrix[1]},
+		{chartVersion: "not-a-version", exp: k8sSupportMatrix[1]},
+		{chartVersion: "0.293.4", exp: k8sSupportMatrix[0]},
+		{chartVersion: "v0.293.4", exp: k8sSupportMatrix[0]},
+		{chartVersion: "1.0.0", exp: k8sSupportMatrix[1]},
+		{chartVersion: "1.1.0", exp: k8sSupportMatrix[1]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.chartVersion, func(t *testing.T) {
+			if d := cmp.Diff(tt.exp, supportedK8s(tt.chartVersion), cmp.AllowUnexported(k8sSupport{})); d != "" {
+				t.Error("support mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestCommand_checkK8sVersion(t *testing.T) {
+	tests := []struct {
+		name         string
+		k8sVersion   string
+		chartVersion string
+		skip         bool
+		expErr       error
+	}{
+		{name: "supported", k8sVersion: "v1.29.1"},
+		{name: "supported with build metadata", k8sVersion: "v1.30.2+k3s1"},
+		{name: "supported with prerelease", k8sVersion: "v1.28.3-gke.1286000"},
+		{name: "older", k8sVersion: "v1.24.17", expErr: localerr.ErrK8sVersion},
+		{name: "older skipped", k8sVersion: "v1.24.17", skip: true},
+		{name: "older supported by the chart", k8sVersion: "v1.24.17", chartVersion: "0.293.4"},
+		{name: "newer", k8sVersion: "v1.32.0"},
+		{name: "unknown", k8sVersion: "test"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Command{k8sVersion: tt.k8sVersion}
+			if err := c.checkK8sVersion(tt.chartVersion, tt.skip); !errors.Is(err, tt.expErr) {
+				t.Errorf("expected error %v, got %v", tt.expErr, err)
+			}
+		})
+	}
+}
+
+func TestCommand_Install_K8sVersion(t *testing.T) {
+	c, err := New(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&mockK8sClient{
+			serverVersionGet: func() (string, error) {
+				return "v1.20.0", nil
+			},
+			namespaceCreate: func(ctx context.Context, namespace string) error {
+				t.Error("expected nothing to be created")
+				return nil
+			},
+			namespaceExists: func(ctx context.Context, namespace string) bool {
+				return false
+			},
+		}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithHTTPClient(&mockHTTP{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Install(context.Background(), InstallOpts{User: "user", Pass: "pass"}); !errors.Is(err, localerr.ErrK8sVersion) {
+		t.Error("expected ErrK8sVersion, got", err)
+	}
+}
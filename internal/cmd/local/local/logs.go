@@ -0,0 +1,166 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/trace"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	logsChartName    = "grafana/loki-stack"
+	logsChartRelease = "airbyte-logs"
+	logsRepoName     = "grafana"
+	logsRepoURL      = "https://grafana.github.io/helm-charts"
+	// logsPort is the port of the loki service.
+	logsPort = "3100"
+)
+
+// DefaultLogRetention is how long the aggregated logs are retained, unless another retention is provided.
+const DefaultLogRetention = 7 * 24 * time.Hour
+
+// logsValues returns the loki-stack values, which deploy loki with promtail collecting the logs of every pod, and
+// delete the logs older than the retention.
+// The retention must be a multiple of 24h, the period of the loki index.
+func logsValues(retention time.Duration) string {
+	return fmt.Sprintf(`loki:
+  persistence:
+    enabled: true
+    size: 2Gi
+  config:
+    table_manager:
+      retention_deletes_enabled: true
+      retention_period: %dh
+promtail:
+  enabled: true
+grafana:
+  enabled: false
+`, int(retention.Hours()))
+}
+
+// ValidateLogRetention returns an error if the retention is not supported by loki.
+func ValidateLogRetention(retention time.Duration) error {
+	if retention <= 0 || retention%(24*time.Hour) != 0 {
+		return fmt.Errorf("log retention must be a multiple of 24h, received %s", retention)
+	}
+	return nil
+}
+
+// handleLogAggregation installs the log aggregation chart.
+func (c *Command) handleLogAggregation(ctx context.Context, retention time.Duration, download *DownloadOpts) (err error) {
+	ctx, span := trace.NewSpan(ctx, "log aggregation")
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	if retention == 0 {
+		retention = DefaultLogRetention
+	}
+	if err := ValidateLogRetention(retention); err != nil {
+		return err
+	}
+
+	if err := c.handleChart(ctx, chartRequest{
+		name:         "logs",
+		repoName:     logsRepoName,
+		repoURL:      logsRepoURL,
+		chartName:    logsChartName,
+		chartRelease: logsChartRelease,
+		namespace:    c.namespace,
+		valuesYAML:   logsValues(retention),
+		download:     download,
+	}); err != nil {
+		return fmt.Errorf("could not install log aggregation chart: %w", err)
+	}
+
+	return nil
+}
+
+// LogsOpts configures which of the aggregated logs are returned by Logs.
+type LogsOpts struct {
+	// Query is the LogQL query selecting the logs, defaults to every log of the Airbyte namespace.
+	Query string
+	// Since only returns logs newer than Since ago.
+	Since time.Duration
+	// Limit is the maximum number of (most recent) logs returned.
+	Limit int
+}
+
+// LogEntry is a single aggregated log line.
+type LogEntry struct {
+	Time   time.Time
+	Labels map[string]string
+	Line   string
+}
+
+// Logs returns the aggregated logs matching the opts, oldest first.
+// Returns an error if log aggregation was not installed.
+func (c *Command) Logs(ctx context.Context, opts LogsOpts) ([]LogEntry, error) {
+	if _, err := c.helm.GetRelease(logsChartRelease); err != nil {
+		return nil, fmt.Errorf("log aggregation is not installed, run 'abctl local install --log-aggregation' to install it: %w", err)
+	}
+
+	query := opts.Query
+	if query == "" {
+		query = fmt.Sprintf(`{namespace="%s"}`, c.namespace)
+	}
+	end := time.Now()
+	params := map[string]string{
+		"query":     query,
+		"start":     strconv.FormatInt(end.Add(-opts.Since).UnixNano(), 10),
+		"end":       strconv.FormatInt(end.UnixNano(), 10),
+		"limit":     strconv.Itoa(opts.Limit),
+		"direction": "backward",
+	}
+
+	data, err := c.k8s.ServiceProxyGet(ctx, c.namespace, logsChartRelease, logsPort, "/loki/api/v1/query_range", params)
+	if err != nil {
+		return nil, fmt.Errorf("could not query logs: %w", err)
+	}
+
+	return parseLogs(data)
+}
+
+// parseLogs parses the loki query_range response of a log query, returning the entries of every stream sorted
+// oldest first.
+func parseLogs(data []byte) ([]LogEntry, error) {
+	var res struct {
+		Status string `json:"status"`
+		Data   struct {
+			ResultType string `json:"resultType"`
+			Result     []struct {
+				Stream map[string]string `json:"stream"`
+				Values [][2]string       `json:"values"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("could not decode logs: %w", err)
+	}
+	if res.Status != "success" {
+		return nil, fmt.Errorf("unexpected logs query status %s", res.Status)
+	}
+	if res.Data.ResultType != "streams" {
+		return nil, fmt.Errorf("query returned %s instead of logs", res.Data.ResultType)
+	}
+
+	var entries []LogEntry
+	for _, stream := range res.Data.Result {
+		for _, v := range stream.Values {
+			ns, err := strconv.ParseInt(v[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid log timestamp %s: %w", v[0], err)
+			}
+			entries = append(entries, LogEntry{Time: time.Unix(0, ns).UTC(), Labels: stream.Stream, Line: v[1]})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+
+	return entries, nil
+}
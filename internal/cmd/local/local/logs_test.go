@@ -0,0 +1,103 @@
+package local
+
+import (
+	"context"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	"helm.sh/helm/v3/pkg/release"
+	"strings"
+	"testing"
+	"time"
+)
+
+const lokiResponse = `{
+  "status": "success",
+  "data": {
+    "resultType": "streams",
+    "result": [
+      {
+        "stream": {"pod": "airbyte-abctl-server-1"},
+        "values": [["1700000002000000000", "server started"], ["1700000000000000000", "server starting"]]
+      },
+      {
+        "stream": {"pod": "airbyte-abctl-worker-1"},
+        "values": [["1700000001000000000", "worker started"]]
+      }
+    ]
+  }
+}`
+
+func TestCommand_Logs(t *testing.T) {
+	var params map[string]string
+	k8sClient := mockK8sClient{
+		serverVersionGet: func() (string, error) {
+			return "test", nil
+		},
+		serviceProxyGet: func(ctx context.Context, namespace, name, port, path string, p map[string]string) ([]byte, error) {
+			if d := cmp.Diff([]string{airbyteNamespace, logsChartRelease, logsPort, "/loki/api/v1/query_range"}, []string{namespace, name, port, path}); d != "" {
+				t.Error("proxy mismatch (-want +got):", d)
+			}
+			params = p
+			return []byte(lokiResponse), nil
+		},
+	}
+	helm := mockHelmClient{
+		getRelease: func(name string) (*release.Release, error) {
+			return &release.Release{Name: name}, nil
+		},
+	}
+
+	c, err := New(k8s.TestProvider, WithK8sClient(&k8sClient), WithHelmClient(&helm), WithTelemetryClient(&mockTelemetryClient{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := c.Logs(context.Background(), LogsOpts{Since: time.Hour, Limit: 10})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := []LogEntry{
+		{Time: time.Unix(1700000000, 0).UTC(), Labels: map[string]string{"pod": "airbyte-abctl-server-1"}, Line: "server starting"},
+		{Time: time.Unix(1700000001, 0).UTC(), Labels: map[string]string{"pod": "airbyte-abctl-worker-1"}, Line: "worker started"},
+		{Time: time.Unix(1700000002, 0).UTC(), Labels: map[string]string{"pod": "airbyte-abctl-server-1"}, Line: "server started"},
+	}
+	if d := cmp.Diff(exp, entries); d != "" {
+		t.Error("entries mismatch (-want +got):", d)
+	}
+
+	if d := cmp.Diff(`{namespace="airbyte-abctl"}`, params["query"]); d != "" {
+		t.Error("query mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff("10", params["limit"]); d != "" {
+		t.Error("limit mismatch (-want +got):", d)
+	}
+}
+
+func TestParseLogs_Errors(t *testing.T) {
+	for _, data := range []string{
+		"not json",
+		`{"status": "error"}`,
+		`{"status": "success", "data": {"resultType": "matrix"}}`,
+		`{"status": "success", "data": {"resultType": "streams", "result": [{"values": [["now", "line"]]}]}}`,
+	} {
+		if _, err := parseLogs([]byte(data)); err == nil {
+			t.Errorf("expected an error for %s", data)
+		}
+	}
+}
+
+func TestLogRetention(t *testing.T) {
+	if err := ValidateLogRetention(DefaultLogRetention); err != nil {
+		t.Error("unexpected error", err)
+	}
+	for _, retention := range []time.Duration{0, -24 * time.Hour, 36 * time.Hour} {
+		if err := ValidateLogRetention(retention); err == nil {
+			t.Errorf("expected an error for %s", retention)
+		}
+	}
+
+	if !strings.Contains(logsValues(48*time.Hour), "retention_period: 48h\n") {
+		t.Error("expected the retention period to be 48h", logsValues(48*time.Hour))
+	}
+}
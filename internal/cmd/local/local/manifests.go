@@ -0,0 +1,327 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/airbytehq/abctl/internal/trace"
+	"github.com/pterm/pterm"
+	"io"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"strings"
+)
+
+const (
+	// extraManifestsSecret records the objects of the extra manifests applied to the installation, so they are
+	// deleted once they are removed from the extra manifests, or Airbyte is uninstalled.
+	extraManifestsSecret = "abctl-extra-manifests"
+	extraManifestsKey    = "manifests"
+)
+
+// manifestRef identifies an object of the extra manifests.
+type manifestRef struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+func refOf(obj *unstructured.Unstructured) manifestRef {
+	return manifestRef{APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+}
+
+// String returns the ref as kind namespace/name, or kind name for a cluster-scoped object.
+func (r manifestRef) String() string {
+	if r.Namespace == "" {
+		return r.Kind + " " + r.Name
+	}
+	return r.Kind + " " + r.Namespace + "/" + r.Name
+}
+
+func (r manifestRef) object() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(r.APIVersion)
+	obj.SetKind(r.Kind)
+	obj.SetNamespace(r.Namespace)
+	obj.SetName(r.Name)
+	return obj
+}
+
+// ReadManifests returns the objects of the kubernetes manifests at path, in the order they are defined.
+// The path is either a yaml (or json) file of one or more manifests, a directory of such files, or a directory
+// containing a kustomization file, which is built with kustomize.
+func ReadManifests(path string) ([]*unstructured.Unstructured, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifests %s: %w", path, err)
+	}
+
+	var objs []*unstructured.Unstructured
+	switch {
+	case !info.IsDir():
+		if objs, err = readManifestFile(path); err != nil {
+			return nil, err
+		}
+	case isKustomization(path):
+		resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(filesys.MakeFsOnDisk(), path)
+		if err != nil {
+			return nil, fmt.Errorf("could not build kustomization %s: %w", path, err)
+		}
+		raw, err := resources.AsYaml()
+		if err != nil {
+			return nil, fmt.Errorf("could not marshal kustomization %s: %w", path, err)
+		}
+		if objs, err = decodeManifests(bytes.NewReader(raw)); err != nil {
+			return nil, fmt.Errorf("could not decode kustomization %s: %w", path, err)
+		}
+	default:
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read manifests %s: %w", path, err)
+		}
+		for _, e := range entries {
+			switch strings.ToLower(filepath.Ext(e.Name())) {
+			case ".yaml", ".yml", ".json":
+			default:
+				continue
+			}
+			if e.IsDir() {
+				continue
+			}
+			fileObjs, err := readManifestFile(filepath.Join(path, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, fileObjs...)
+		}
+	}
+
+	seen := map[manifestRef]bool{}
+	for _, obj := range objs {
+		ref := refOf(obj)
+		if seen[ref] {
+			return nil, fmt.Errorf("manifests %s define %s more than once", path, ref)
+		}
+		seen[ref] = true
+	}
+
+	return objs, nil
+}
+
+// isKustomization returns true if the dir contains a kustomization file.
+func isKustomization(dir string) bool {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func readManifestFile(path string) ([]*unstructured.Unstructured, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifests %s: %w", path, err)
+	}
+	defer f.Close()
+
+	objs, err := decodeManifests(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode manifests %s: %w", path, err)
+	}
+	return objs, nil
+}
+
+// decodeManifests decodes every yaml document (or json object) of r, expanding the items of any list.
+// Every object must have an apiVersion, kind, and name.
+func decodeManifests(r io.Reader) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	dec := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for i := 1; ; i++ {
+		var doc map[string]interface{}
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("manifest %d is invalid: %w", i, err)
+		}
+		// empty documents, e.g. between two separators, are skipped
+		if len(doc) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: doc}
+		items := []*unstructured.Unstructured{obj}
+		if obj.IsList() {
+			items = nil
+			if err := obj.EachListItem(func(o runtime.Object) error {
+				items = append(items, o.(*unstructured.Unstructured))
+				return nil
+			}); err != nil {
+				return nil, fmt.Errorf("manifest %d is an invalid list: %w", i, err)
+			}
+		}
+
+		for _, item := range items {
+			if item.GetAPIVersion() == "" || item.GetKind() == "" || item.GetName() == "" {
+				return nil, fmt.Errorf("manifest %d must have an apiVersion, kind, and metadata.name", i)
+			}
+			objs = append(objs, item)
+		}
+	}
+
+	return objs, nil
+}
+
+// appliedManifests returns the objects of the extra manifests previously applied to the installation.
+func (c *Command) appliedManifests(ctx context.Context) ([]manifestRef, error) {
+	secret, err := c.k8s.SecretGet(ctx, c.namespace, extraManifestsSecret)
+	if k8serrors.IsNotFound(err) || (err == nil && secret == nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get secret %s: %w", extraManifestsSecret, err)
+	}
+
+	var refs []manifestRef
+	if raw := secret.Data[extraManifestsKey]; len(raw) > 0 {
+		if err := json.Unmarshal(raw, &refs); err != nil {
+			return nil, fmt.Errorf("could not decode secret %s: %w", extraManifestsSecret, err)
+		}
+	}
+	return refs, nil
+}
+
+// recordManifests records the refs as the objects of the extra manifests applied to the installation.
+func (c *Command) recordManifests(ctx context.Context, refs []manifestRef) error {
+	if refs == nil {
+		refs = []manifestRef{}
+	}
+	raw, err := json.Marshal(refs)
+	if err != nil {
+		return fmt.Errorf("could not encode extra manifests: %w", err)
+	}
+	if err := c.k8s.SecretCreateOrUpdate(ctx, c.namespace, extraManifestsSecret, map[string][]byte{extraManifestsKey: raw}); err != nil {
+		return fmt.Errorf("could not create or update secret %s: %w", extraManifestsSecret, err)
+	}
+	return nil
+}
+
+// deleteManifests deletes the objects of the refs, in reverse order, returning the refs which could not be deleted.
+// Objects which no longer exist, including those whose custom resource definition was deleted, are ignored.
+func (c *Command) deleteManifests(ctx context.Context, refs []manifestRef) []manifestRef {
+	var remaining []manifestRef
+	for i := len(refs) - 1; i >= 0; i-- {
+		ref := refs[i]
+		c.spinner.UpdateText(fmt.Sprintf("Deleting %s", ref))
+		err := c.k8s.ManifestDelete(ctx, ref.object())
+		if err != nil && !k8serrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			pterm.Warning.Printfln("Unable to delete %s of the extra manifests", ref)
+			logging.Debugf("could not delete %s: %s", ref, err)
+			remaining = append([]manifestRef{ref}, remaining...)
+			continue
+		}
+		pterm.Info.Printfln("Deleted %s of the extra manifests", ref)
+	}
+	return remaining
+}
+
+// handleExtraManifests applies the extra manifests at path with server-side apply, within the namespace of the
+// installation unless they have a namespace of their own. Objects of previously applied extra manifests which are no
+// longer part of them are deleted.
+func (c *Command) handleExtraManifests(ctx context.Context, path string) (err error) {
+	ctx, span := trace.NewSpan(ctx, "extra manifests")
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	objs, err := ReadManifests(path)
+	if err != nil {
+		return err
+	}
+
+	previous, err := c.appliedManifests(ctx)
+	if err != nil {
+		return err
+	}
+
+	var applied []manifestRef
+	for _, obj := range objs {
+		c.spinner.UpdateText(fmt.Sprintf("Applying %s", refOf(obj)))
+		if err := c.k8s.ManifestApply(ctx, c.namespace, obj); err != nil {
+			pterm.Error.Printfln("Unable to apply %s of the extra manifests", refOf(obj))
+			// the objects applied so far are recorded in addition to the previous ones, so none are left behind
+			if err := c.recordManifests(ctx, mergeRefs(applied, previous)); err != nil {
+				logging.Debugf("could not record extra manifests: %s", err)
+			}
+			return fmt.Errorf("could not apply %s: %w", refOf(obj), err)
+		}
+		applied = append(applied, refOf(obj))
+	}
+
+	var stale []manifestRef
+	for _, ref := range previous {
+		if !containsRef(applied, ref) {
+			stale = append(stale, ref)
+		}
+	}
+	// any stale object which could not be deleted remains recorded, so it is deleted by the next attempt
+	remaining := c.deleteManifests(ctx, stale)
+	if err := c.recordManifests(ctx, append(applied, remaining...)); err != nil {
+		pterm.Error.Println("Unable to record the extra manifests")
+		return err
+	}
+
+	pterm.Success.Printfln("Applied %d object(s) of the extra manifests '%s'", len(applied), path)
+	return nil
+}
+
+// uninstallManifests deletes the objects of the extra manifests applied to the installation.
+func (c *Command) uninstallManifests(ctx context.Context) error {
+	refs, err := c.appliedManifests(ctx)
+	if err != nil || len(refs) == 0 {
+		return err
+	}
+
+	remaining := c.deleteManifests(ctx, refs)
+	if err := c.recordManifests(ctx, remaining); err != nil {
+		return err
+	}
+	if len(remaining) > 0 {
+		return fmt.Errorf("could not delete %d object(s) of the extra manifests", len(remaining))
+	}
+	return nil
+}
+
+func containsRef(refs []manifestRef, ref manifestRef) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeRefs returns the refs of a, followed by the refs of b which are not in a.
+func mergeRefs(a, b []manifestRef) []manifestRef {
+	merged := append([]manifestRef{}, a...)
+	for _, ref := range b {
+		if !containsRef(merged, ref) {
+			merged = append(merged, ref)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,242 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pterm/pterm"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testManifests = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: networking.k8s.io/v1
+  kind: NetworkPolicy
+  metadata:
+    name: deny-all
+    namespace: other
+`
+
+func refsOf(objs []*unstructured.Unstructured) []manifestRef {
+	refs := make([]manifestRef, len(objs))
+	for i, obj := range objs {
+		refs[i] = refOf(obj)
+	}
+	return refs
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal("could not write file", err)
+	}
+}
+
+func TestReadManifests(t *testing.T) {
+	exp := []manifestRef{
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "settings"},
+		{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy", Namespace: "other", Name: "deny-all"},
+	}
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "manifests.yaml")
+		writeFile(t, path, testManifests)
+
+		objs, err := ReadManifests(path)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if d := cmp.Diff(exp, refsOf(objs)); d != "" {
+			t.Error("manifests mismatch (-want +got):", d)
+		}
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "a.yaml"), testManifests)
+		writeFile(t, filepath.Join(dir, "b.json"), `{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "token"}}`)
+		writeFile(t, filepath.Join(dir, "README.md"), "not a manifest")
+
+		objs, err := ReadManifests(dir)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if d := cmp.Diff(append(exp, manifestRef{APIVersion: "v1", Kind: "Secret", Name: "token"}), refsOf(objs)); d != "" {
+			t.Error("manifests mismatch (-want +got):", d)
+		}
+	})
+
+	t.Run("kustomization", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "manifests.yaml"), testManifests)
+		writeFile(t, filepath.Join(dir, "kustomization.yaml"), "resources:\n- manifests.yaml\nnamePrefix: abctl-\n")
+
+		objs, err := ReadManifests(dir)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		exp := []manifestRef{
+			{APIVersion: "v1", Kind: "ConfigMap", Name: "abctl-settings"},
+			{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy", Namespace: "other", Name: "abctl-deny-all"},
+		}
+		if d := cmp.Diff(exp, refsOf(objs)); d != "" {
+			t.Error("manifests mismatch (-want +got):", d)
+		}
+	})
+
+	invalid := []struct {
+		name     string
+		manifest string
+		err      string
+	}{
+		{name: "no name", manifest: "apiVersion: v1\nkind: ConfigMap\n", err: "must have an apiVersion, kind, and metadata.name"},
+		{name: "invalid yaml", manifest: "apiVersion: [v1\n", err: "manifest 1 is invalid"},
+		{name: "duplicate", manifest: testManifests + "---\n" + testManifests, err: "define ConfigMap settings more than once"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "manifests.yaml")
+			writeFile(t, path, tt.manifest)
+
+			if _, err := ReadManifests(path); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestCommand_handleExtraManifests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifests.yaml")
+	writeFile(t, path, testManifests)
+
+	previous, _ := json.Marshal([]manifestRef{
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "settings"},
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "removed"},
+	})
+
+	var applied, deleted, recorded []manifestRef
+	k8sClient := mockK8sClient{
+		secretGet: func(ctx context.Context, namespace, name string) (*coreV1.Secret, error) {
+			return &coreV1.Secret{Data: map[string][]byte{extraManifestsKey: previous}}, nil
+		},
+		secretCreateOrUpdate: func(ctx context.Context, namespace, name string, data map[string][]byte) error {
+			if name != extraManifestsSecret {
+				t.Error("unexpected secret", name)
+			}
+			return json.Unmarshal(data[extraManifestsKey], &recorded)
+		},
+		manifestApply: func(ctx context.Context, namespace string, obj *unstructured.Unstructured) error {
+			if namespace != airbyteNamespace {
+				t.Error("unexpected namespace", namespace)
+			}
+			applied = append(applied, refOf(obj))
+			return nil
+		},
+		manifestDelete: func(ctx context.Context, obj *unstructured.Unstructured) error {
+			deleted = append(deleted, refOf(obj))
+			return nil
+		},
+	}
+
+	spinner, _ := pterm.DefaultSpinner.Start()
+	c := &Command{k8s: &k8sClient, namespace: airbyteNamespace, spinner: spinner}
+	if err := c.handleExtraManifests(context.Background(), path); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := []manifestRef{
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "settings"},
+		{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy", Namespace: "other", Name: "deny-all"},
+	}
+	if d := cmp.Diff(exp, applied); d != "" {
+		t.Error("applied mismatch (-want +got):", d)
+	}
+	// only the object no longer part of the manifests is deleted
+	if d := cmp.Diff([]manifestRef{{APIVersion: "v1", Kind: "ConfigMap", Name: "removed"}}, deleted); d != "" {
+		t.Error("deleted mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff(exp, recorded); d != "" {
+		t.Error("recorded mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_handleExtraManifests_ApplyError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifests.yaml")
+	writeFile(t, path, testManifests)
+
+	var recorded []manifestRef
+	k8sClient := mockK8sClient{
+		secretCreateOrUpdate: func(ctx context.Context, namespace, name string, data map[string][]byte) error {
+			return json.Unmarshal(data[extraManifestsKey], &recorded)
+		},
+		manifestApply: func(ctx context.Context, namespace string, obj *unstructured.Unstructured) error {
+			if obj.GetKind() == "NetworkPolicy" {
+				return errors.New("test error")
+			}
+			return nil
+		},
+	}
+
+	spinner, _ := pterm.DefaultSpinner.Start()
+	c := &Command{k8s: &k8sClient, namespace: airbyteNamespace, spinner: spinner}
+	err := c.handleExtraManifests(context.Background(), path)
+	if err == nil || !strings.Contains(err.Error(), "could not apply NetworkPolicy other/deny-all") {
+		t.Fatal("expected the apply error, got", err)
+	}
+	// the object applied before the error is recorded, so it is deleted on uninstall
+	if d := cmp.Diff([]manifestRef{{APIVersion: "v1", Kind: "ConfigMap", Name: "settings"}}, recorded); d != "" {
+		t.Error("recorded mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_Uninstall_ExtraManifests(t *testing.T) {
+	applied, _ := json.Marshal([]manifestRef{
+		{APIVersion: "v1", Kind: "Namespace", Name: "extra"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "extra", Name: "settings"},
+	})
+
+	var deleted, recorded []manifestRef
+	k8sClient := mockK8sClient{
+		secretGet: func(ctx context.Context, namespace, name string) (*coreV1.Secret, error) {
+			return &coreV1.Secret{Data: map[string][]byte{extraManifestsKey: applied}}, nil
+		},
+		secretCreateOrUpdate: func(ctx context.Context, namespace, name string, data map[string][]byte) error {
+			return json.Unmarshal(data[extraManifestsKey], &recorded)
+		},
+		manifestDelete: func(ctx context.Context, obj *unstructured.Unstructured) error {
+			deleted = append(deleted, refOf(obj))
+			return nil
+		},
+	}
+
+	spinner, _ := pterm.DefaultSpinner.Start()
+	c := &Command{k8s: &k8sClient, namespace: airbyteNamespace, spinner: spinner}
+	if err := c.Uninstall(context.Background(), UninstallOpts{}); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// the objects are deleted in the reverse order they were applied
+	exp := []manifestRef{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "extra", Name: "settings"},
+		{APIVersion: "v1", Kind: "Namespace", Name: "extra"},
+	}
+	if d := cmp.Diff(exp, deleted); d != "" {
+		t.Error("deleted mismatch (-want +got):", d)
+	}
+	if len(recorded) != 0 {
+		t.Error("expected no recorded manifests", recorded)
+	}
+}
@@ -0,0 +1,151 @@
+package local
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/progress"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/pterm/pterm"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bootloaderPod is the name of the pod which migrates the databases of Airbyte before it is installed or upgraded.
+var bootloaderPod = componentPrefix("airbyte-bootloader")
+
+// The markers flyway logs while the bootloader migrates a database.
+var (
+	migrationCurrentRe   = regexp.MustCompile(`Current version of schema \S+: (\S+)`)
+	migrationMigratingRe = regexp.MustCompile(`Migrating schema \S+ to version "?([^" ]+)`)
+	migrationAppliedRe   = regexp.MustCompile(`Successfully applied (\d+) migrations? to schema \S+, now at version (\S+)`)
+	migrationUpToDateRe  = regexp.MustCompile(`Schema \S+ is up to date\. No migration necessary\.`)
+)
+
+// newLogScanner returns a bufio.Scanner of the lines of the logs, which allows for lines longer than the default
+// limit of bufio.Scanner, e.g. a stack trace logged as a single line.
+func newLogScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return scanner
+}
+
+// migrations follows the flyway migrations of a database, as logged by the bootloader.
+// Flyway does not log how many migrations are pending, so only the migrations applied so far are counted.
+type migrations struct {
+	from    string
+	to      string
+	applied int
+}
+
+// parse updates the migrations from the line of the bootloader logs.
+// The step describing the migration is returned if the line is a migration marker, with done being true once the
+// migration of the database has completed.
+func (m *migrations) parse(line string) (step string, done bool, ok bool) {
+	if match := migrationCurrentRe.FindStringSubmatch(line); match != nil {
+		*m = migrations{from: shortVersion(match[1])}
+		return fmt.Sprintf("database at version %s", m.from), false, true
+	}
+
+	if match := migrationMigratingRe.FindStringSubmatch(line); match != nil {
+		// the migration being logged is only applied after this line, the count is of the migrations completed
+		if m.to != "" {
+			m.applied++
+		}
+		m.to = shortVersion(match[1])
+		return fmt.Sprintf("migrating %s -> %s: %d migrations applied", m.from, m.to, m.applied), false, true
+	}
+
+	if match := migrationAppliedRe.FindStringSubmatch(line); match != nil {
+		m.applied, _ = strconv.Atoi(match[1])
+		m.to = shortVersion(match[2])
+		step = fmt.Sprintf("migrated %s -> %s: %d migrations applied", m.from, m.to, m.applied)
+		*m = migrations{}
+		return step, true, true
+	}
+
+	if migrationUpToDateRe.MatchString(line) {
+		step = fmt.Sprintf("at version %s is up to date", m.from)
+		*m = migrations{}
+		return step, true, true
+	}
+
+	return "", false, false
+}
+
+// shortVersion returns the major and minor components of the version of a migration, e.g. "0.57" for "v0.57.4.001".
+func shortVersion(version string) string {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 {
+		return parts[0]
+	}
+	return strings.Join(parts[:2], ".")
+}
+
+// reportMigrations reports the progress of the database migrations of the bootloader pod, once it has been created
+// within the namespace, until its logs end or ctx is done. The progress is reported with an empty status once done.
+// The completed migration of each database is printed, as migrating a large database can take many minutes.
+func (c *Command) reportMigrations(ctx context.Context, namespace string, report progress.Reporter) {
+	defer report("")
+
+	// the bootloader pod of a previous installation may still exist until it is replaced, a minute of clock skew
+	// between the host and the cluster is allowed for
+	start := time.Now().Add(-time.Minute)
+	ticker := time.NewTicker(podsReportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pods, err := c.k8s.PodList(ctx, namespace)
+		if err != nil {
+			logging.Debugf("could not list pods in namespace %s: %s", namespace, err)
+			continue
+		}
+
+		for _, pod := range pods.Items {
+			if pod.Name != bootloaderPod || pod.CreationTimestamp.Time.Before(start) || pod.Status.Phase == corev1.PodPending {
+				continue
+			}
+			c.followMigrations(ctx, namespace, report)
+			return
+		}
+	}
+}
+
+// followMigrations reports the steps of the migrations parsed from the logs of the bootloader pod.
+func (c *Command) followMigrations(ctx context.Context, namespace string, report progress.Reporter) {
+	logs, err := c.k8s.LogsFollow(ctx, namespace, bootloaderPod)
+	if err != nil {
+		logging.Debugf("could not follow logs of pod %s: %s", bootloaderPod, err)
+		return
+	}
+	defer logs.Close()
+
+	var m migrations
+	scanner := newLogScanner(logs)
+	for scanner.Scan() {
+		step, done, ok := m.parse(scanner.Text())
+		if !ok {
+			continue
+		}
+		logging.Debugf("bootloader: %s", step)
+		if done {
+			pterm.Info.Printfln("Database %s", step)
+			report("")
+			continue
+		}
+		report(step)
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		logging.Debugf("could not read logs of pod %s: %s", bootloaderPod, err)
+	}
+}
@@ -0,0 +1,122 @@
+package local
+
+import (
+	"context"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+	"testing"
+	"time"
+)
+
+const bootloaderLogs = `2024-06-01 12:00:00 INFO i.a.b.Bootloader(load):72 - Initializing databases...
+2024-06-01 12:00:01 INFO o.f.c.i.c.DbValidate(validate):84 - Successfully validated 75 migrations (execution time 00:00.105s)
+2024-06-01 12:00:01 INFO o.f.c.i.c.DbMigrate(migrateGroup):126 - Current version of schema "public": 0.57.4.001
+2024-06-01 12:00:01 INFO o.f.c.i.c.DbMigrate(doMigrateGroup):336 - Migrating schema "public" to version "0.60.0.001 - AddScopedConfigurationTable"
+2024-06-01 12:00:02 ERROR i.a.b.Bootloader(load):80 - unrelated error
+2024-06-01 12:00:03 INFO o.f.c.i.c.DbMigrate(doMigrateGroup):336 - Migrating schema "public" to version "0.63.0.001 - AddConnectorRollout"
+2024-06-01 12:00:04 INFO o.f.c.i.c.DbMigrate(logSummary):418 - Successfully applied 2 migrations to schema "public", now at version v0.63.0.001 (execution time 00:02.512s)
+2024-06-01 12:00:04 INFO o.f.c.i.c.DbMigrate(migrateGroup):126 - Current version of schema "public": 0.50.5.005
+2024-06-01 12:00:04 INFO o.f.c.i.c.DbMigrate(migrate):148 - Schema "public" is up to date. No migration necessary.
+`
+
+func TestMigrations_Parse(t *testing.T) {
+	type step struct {
+		step string
+		done bool
+	}
+
+	var (
+		m     migrations
+		steps []step
+	)
+	scanner := newLogScanner(strings.NewReader(bootloaderLogs))
+	for scanner.Scan() {
+		if s, done, ok := m.parse(scanner.Text()); ok {
+			steps = append(steps, step{step: s, done: done})
+		}
+	}
+
+	exp := []step{
+		{step: "database at version 0.57"},
+		{step: "migrating 0.57 -> 0.60: 0 migrations applied"},
+		{step: "migrating 0.57 -> 0.63: 1 migrations applied"},
+		{step: "migrated 0.57 -> 0.63: 2 migrations applied", done: true},
+		{step: "database at version 0.50"},
+		{step: "at version 0.50 is up to date", done: true},
+	}
+	if d := cmp.Diff(exp, steps, cmp.AllowUnexported(step{})); d != "" {
+		t.Error("steps mismatch (-want +got):", d)
+	}
+}
+
+func TestShortVersion(t *testing.T) {
+	for version, exp := range map[string]string{
+		"0.57.4.001":  "0.57",
+		"v0.63.0.001": "0.63",
+		"1":           "1",
+	} {
+		if d := cmp.Diff(exp, shortVersion(version)); d != "" {
+			t.Errorf("version mismatch for %s (-want +got): %s", version, d)
+		}
+	}
+}
+
+func TestCommand_ReportMigrations(t *testing.T) {
+	origInterval := podsReportInterval
+	podsReportInterval = time.Millisecond
+	t.Cleanup(func() { podsReportInterval = origInterval })
+
+	var followed []string
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&mockK8sClient{
+			podList: func(ctx context.Context, namespace string) (*coreV1.PodList, error) {
+				return &coreV1.PodList{Items: []coreV1.Pod{
+					// a bootloader pod of a previous installation must be ignored
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: bootloaderPod, CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))},
+						Status:     coreV1.PodStatus{Phase: coreV1.PodSucceeded},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: bootloaderPod, CreationTimestamp: metav1.Now()},
+						Status:     coreV1.PodStatus{Phase: coreV1.PodRunning},
+					},
+				}}, nil
+			},
+			logsFollow: func(ctx context.Context, namespace, name string) (io.ReadCloser, error) {
+				followed = append(followed, namespace+"/"+name)
+				return io.NopCloser(strings.NewReader(bootloaderLogs)), nil
+			},
+		}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reported []string
+	c.reportMigrations(context.Background(), airbyteNamespace, func(status string) {
+		reported = append(reported, status)
+	})
+
+	if d := cmp.Diff([]string{airbyteNamespace + "/airbyte-abctl-airbyte-bootloader"}, followed); d != "" {
+		t.Error("followed mismatch (-want +got):", d)
+	}
+	exp := []string{
+		"database at version 0.57",
+		"migrating 0.57 -> 0.60: 0 migrations applied",
+		"migrating 0.57 -> 0.63: 1 migrations applied",
+		"",
+		"database at version 0.50",
+		"",
+		"",
+	}
+	if d := cmp.Diff(exp, reported); d != "" {
+		t.Error("reported mismatch (-want +got):", d)
+	}
+}
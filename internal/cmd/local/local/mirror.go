@@ -0,0 +1,49 @@
+package local
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ValidateRegistryMirror verifies the mirror is a registry host with an optional port, e.g. my.registry.example.com
+// or my.registry.example.com:5000.
+// A path is not supported, as the mirror must serve the images under the same repository names as docker hub.
+func ValidateRegistryMirror(mirror string) error {
+	if strings.Contains(mirror, "://") {
+		return fmt.Errorf("registry mirror %s must not include a scheme", mirror)
+	}
+
+	u, err := url.Parse("https://" + mirror)
+	if err != nil || u.Host == "" || strings.Trim(u.Path, "/") != "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("registry mirror %s must be a registry host with an optional port", mirror)
+	}
+
+	return nil
+}
+
+// RegistryMirrorEndpoint returns the url of the mirror, as a containerd mirror endpoint of docker hub.
+func RegistryMirrorEndpoint(mirror string) string {
+	return "https://" + strings.TrimSuffix(mirror, "/")
+}
+
+// registryMirrorValues returns the helm values which rewrite the image references of the airbyte chart and the chart
+// of the ingress controller to the mirror.
+func registryMirrorValues(mirror string, controller string) (airbyte []string, ingress []string) {
+	mirror = strings.TrimSuffix(mirror, "/")
+	airbyte = []string{
+		fmt.Sprintf("global.image.registry=%s", mirror),
+	}
+	switch controller {
+	case IngressNginx:
+		ingress = []string{
+			fmt.Sprintf("controller.image.registry=%s", mirror),
+			fmt.Sprintf("controller.admissionWebhooks.patch.image.registry=%s", mirror),
+		}
+	case IngressTraefik:
+		ingress = []string{
+			fmt.Sprintf("image.registry=%s", mirror),
+		}
+	}
+	return airbyte, ingress
+}
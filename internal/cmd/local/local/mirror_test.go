@@ -0,0 +1,118 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	helmclient "github.com/mittwald/go-helm-client"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/repo"
+	"testing"
+)
+
+func TestValidateRegistryMirror(t *testing.T) {
+	for _, mirror := range []string{"my.registry.example.com", "my.registry.example.com:5000", "10.0.0.1:5000", "my.registry.example.com/"} {
+		if err := ValidateRegistryMirror(mirror); err != nil {
+			t.Errorf("unexpected error for '%s': %s", mirror, err)
+		}
+	}
+
+	for _, mirror := range []string{"", "https://my.registry.example.com", "my.registry.example.com/airbyte", "user@my.registry.example.com", "my.registry.example.com?a=b"} {
+		if err := ValidateRegistryMirror(mirror); err == nil {
+			t.Errorf("expected error for '%s'", mirror)
+		}
+	}
+}
+
+func TestRegistryMirrorValues(t *testing.T) {
+	airbyte, nginx := registryMirrorValues("my.registry.example.com:5000/", IngressNginx)
+
+	if d := cmp.Diff([]string{"global.image.registry=my.registry.example.com:5000"}, airbyte); d != "" {
+		t.Error("airbyte values mismatch (-want +got):", d)
+	}
+	exp := []string{
+		"controller.image.registry=my.registry.example.com:5000",
+		"controller.admissionWebhooks.patch.image.registry=my.registry.example.com:5000",
+	}
+	if d := cmp.Diff(exp, nginx); d != "" {
+		t.Error("nginx values mismatch (-want +got):", d)
+	}
+
+	_, traefik := registryMirrorValues("my.registry.example.com:5000/", IngressTraefik)
+	if d := cmp.Diff([]string{"image.registry=my.registry.example.com:5000"}, traefik); d != "" {
+		t.Error("traefik values mismatch (-want +got):", d)
+	}
+
+	_, none := registryMirrorValues("my.registry.example.com:5000/", IngressNone)
+	if d := cmp.Diff([]string(nil), none); d != "" {
+		t.Error("no values expected without an ingress controller (-want +got):", d)
+	}
+
+	if d := cmp.Diff("https://my.registry.example.com:5000", RegistryMirrorEndpoint("my.registry.example.com:5000/")); d != "" {
+		t.Error("endpoint mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_Install_RegistryMirror(t *testing.T) {
+	values := map[string][]string{}
+
+	helm := mockHelmClient{
+		addOrUpdateChartRepo: func(entry repo.Entry) error {
+			return nil
+		},
+		getChart: func(name string, _ *action.ChartPathOptions) (*chart.Chart, string, error) {
+			return &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, "", nil
+		},
+		installOrUpgradeChart: func(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+			values[spec.ReleaseName] = spec.ValuesOptions.Values
+			return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, Name: spec.ReleaseName}, nil
+		},
+	}
+
+	k8sClient := mockK8sClient{
+		podList: readyPods,
+		serverVersionGet: func() (string, error) {
+			return "test", nil
+		},
+	}
+
+	httpClient := mockHTTP{do: readyResponse}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithHelmClient(&helm),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{user: func() uuid.UUID { return uuid.Nil }}),
+		WithHTTPClient(&httpClient),
+		WithBrowserLauncher(func(url string) error {
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Install(context.Background(), InstallOpts{User: "user", Pass: "pass", RegistryMirror: "my.registry.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := map[string][]string{
+		airbyteChartRelease: {
+			"global.env_vars.AIRBYTE_INSTALLATION_ID=",
+			"global.image.registry=my.registry.example.com",
+		},
+		nginxChartRelease: {
+			fmt.Sprintf("controller.service.ports.http=%d", portTest),
+			"controller.image.registry=my.registry.example.com",
+			"controller.admissionWebhooks.patch.image.registry=my.registry.example.com",
+		},
+	}
+	if d := cmp.Diff(exp, values); d != "" {
+		t.Error("values mismatch (-want +got):", d)
+	}
+}
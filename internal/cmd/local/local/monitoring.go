@@ -0,0 +1,103 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/trace"
+	"github.com/pterm/pterm"
+)
+
+const (
+	monitoringChartName    = "prometheus-community/kube-prometheus-stack"
+	monitoringChartRelease = "airbyte-monitoring"
+	monitoringIngress      = "monitoring-abctl"
+	monitoringRepoName     = "prometheus-community"
+	monitoringRepoURL      = "https://prometheus-community.github.io/helm-charts"
+	// monitoringPath is the path of the ingress which serves grafana.
+	monitoringPath = "/grafana"
+)
+
+// monitoringValues are the kube-prometheus-stack values of a slim installation suitable for a local cluster.
+// Grafana is served from the monitoringPath of the ingress, which is protected by the same basic-auth as Airbyte,
+// therefore grafana's own basic-auth is disabled and anonymous users are allowed to view the dashboards.
+// Prometheus accepts OTLP metrics, which is how Airbyte publishes its metrics.
+const monitoringValues = `fullnameOverride: airbyte-monitoring
+alertmanager:
+  enabled: false
+nodeExporter:
+  enabled: false
+prometheus:
+  prometheusSpec:
+    retention: 7d
+    enableFeatures:
+      - otlp-write-receiver
+grafana:
+  grafana.ini:
+    server:
+      root_url: "%(protocol)s://%(domain)s:%(http_port)s` + monitoringPath + `/"
+      serve_from_sub_path: true
+    auth.basic:
+      enabled: false
+    auth.anonymous:
+      enabled: true
+      org_role: Viewer
+`
+
+// monitoringAirbyteValues returns the airbyte chart values which publish the Airbyte metrics to the prometheus
+// installed in the namespace.
+func monitoringAirbyteValues(namespace string) []string {
+	return []string{
+		"metrics.enabled=true",
+		"global.metrics.metricClient=otel",
+		fmt.Sprintf("global.metrics.otelCollectorEndpoint=http://%s-prometheus.%s:9090/api/v1/otlp", monitoringChartRelease, namespace),
+	}
+}
+
+// handleMonitoring installs the monitoring chart and the ingress which serves grafana for the hosts.
+func (c *Command) handleMonitoring(ctx context.Context, hosts []string, download *DownloadOpts) (err error) {
+	ctx, span := trace.NewSpan(ctx, "monitoring")
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	if err := c.handleChart(ctx, chartRequest{
+		name:         "monitoring",
+		repoName:     monitoringRepoName,
+		repoURL:      monitoringRepoURL,
+		chartName:    monitoringChartName,
+		chartRelease: monitoringChartRelease,
+		namespace:    c.namespace,
+		valuesYAML:   monitoringValues,
+		download:     download,
+	}); err != nil {
+		return fmt.Errorf("could not install monitoring chart: %w", err)
+	}
+
+	c.spinner.UpdateText("Configuring monitoring Ingress")
+	spec := grafanaIngress(c.namespace, hosts, c.ingressController, c.ingressClass)
+	if c.k8s.IngressExists(ctx, c.namespace, monitoringIngress) {
+		if err := c.k8s.IngressUpdate(ctx, c.namespace, spec); err != nil {
+			pterm.Error.Println("Unable to update the monitoring Ingress")
+			return fmt.Errorf("could not update monitoring ingress: %w", err)
+		}
+	} else if err := c.k8s.IngressCreate(ctx, c.namespace, spec); err != nil {
+		pterm.Error.Println("Unable to create the monitoring Ingress")
+		return fmt.Errorf("could not create monitoring ingress: %w", err)
+	}
+
+	pterm.Success.Printfln("Monitoring is accessible via %s%s", baseURL(hosts[0], c.portHTTP), monitoringPath)
+	return nil
+}
+
+// OpenMonitoring opens grafana in the user's browser, once it is being served by the ingress.
+// Returns an error if monitoring was not installed.
+func (c *Command) OpenMonitoring(ctx context.Context) error {
+	c.spinner.UpdateText("Checking for the monitoring Helm Chart")
+	if _, err := c.helm.GetRelease(monitoringChartRelease); err != nil {
+		pterm.Error.Println("Monitoring is not installed, run 'abctl local install --monitoring' to install it")
+		return fmt.Errorf("could not get monitoring release: %w", err)
+	}
+
+	return c.openBrowser(ctx, c.host, monitoringPath+"/")
+}
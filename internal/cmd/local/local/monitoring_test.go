@@ -0,0 +1,157 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	helmclient "github.com/mittwald/go-helm-client"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/repo"
+	networkingv1 "k8s.io/api/networking/v1"
+	"net/http"
+	"sort"
+	"testing"
+)
+
+func TestCommand_Install_Monitoring(t *testing.T) {
+	var (
+		releases      []string
+		airbyteValues []string
+		ingresses     = map[string]*networkingv1.Ingress{}
+	)
+
+	helm := mockHelmClient{
+		addOrUpdateChartRepo: func(entry repo.Entry) error {
+			return nil
+		},
+		getChart: func(name string, _ *action.ChartPathOptions) (*chart.Chart, string, error) {
+			return &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, "", nil
+		},
+		installOrUpgradeChart: func(ctx context.Context, spec *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+			releases = append(releases, spec.ReleaseName)
+			switch spec.ReleaseName {
+			case airbyteChartRelease:
+				airbyteValues = spec.ValuesOptions.Values
+			case monitoringChartRelease:
+				if d := cmp.Diff(airbyteNamespace, spec.Namespace); d != "" {
+					t.Error("monitoring namespace mismatch (-want +got):", d)
+				}
+				if d := cmp.Diff(monitoringValues, spec.ValuesYaml); d != "" {
+					t.Error("monitoring values mismatch (-want +got):", d)
+				}
+			}
+			return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, Name: spec.ReleaseName}, nil
+		},
+	}
+
+	k8sClient := mockK8sClient{
+		podList: readyPods,
+		serverVersionGet: func() (string, error) {
+			return "test", nil
+		},
+		ingressExists: func(ctx context.Context, namespace string, ingress string) bool {
+			return false
+		},
+		ingressCreate: func(ctx context.Context, namespace string, ingress *networkingv1.Ingress) error {
+			ingresses[ingress.Name] = ingress
+			return nil
+		},
+	}
+
+	httpClient := mockHTTP{do: readyResponse}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithHelmClient(&helm),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{user: func() uuid.UUID { return uuid.Nil }}),
+		WithHTTPClient(&httpClient),
+		WithBrowserLauncher(func(url string) error {
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Install(context.Background(), InstallOpts{User: "user", Pass: "pass", Monitoring: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// airbyte and nginx are installed concurrently, in no particular order, before monitoring
+	if len(releases) > 2 {
+		sort.Strings(releases[:2])
+	}
+	if d := cmp.Diff([]string{airbyteChartRelease, nginxChartRelease, monitoringChartRelease}, releases); d != "" {
+		t.Error("releases mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff(append([]string{"global.env_vars.AIRBYTE_INSTALLATION_ID="}, monitoringAirbyteValues(airbyteNamespace)...), airbyteValues); d != "" {
+		t.Error("airbyte values mismatch (-want +got):", d)
+	}
+
+	grafana, ok := ingresses[monitoringIngress]
+	if !ok {
+		t.Fatal("expected the monitoring ingress to be created")
+	}
+	rule := grafana.Spec.Rules[0]
+	if d := cmp.Diff(DefaultHost, rule.Host); d != "" {
+		t.Error("host mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff(monitoringPath, rule.HTTP.Paths[0].Path); d != "" {
+		t.Error("path mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff("basic-auth", grafana.Annotations["nginx.ingress.kubernetes.io/auth-secret"]); d != "" {
+		t.Error("auth secret mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_OpenMonitoring(t *testing.T) {
+	installed := false
+	helm := mockHelmClient{
+		getRelease: func(name string) (*release.Release, error) {
+			if d := cmp.Diff(monitoringChartRelease, name); d != "" {
+				t.Error("release mismatch (-want +got):", d)
+			}
+			if !installed {
+				return nil, errors.New("release: not found")
+			}
+			return &release.Release{Name: name}, nil
+		},
+	}
+
+	var launched []string
+	c, err := New(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithHelmClient(&helm),
+		WithK8sClient(&mockK8sClient{serverVersionGet: func() (string, error) { return "test", nil }}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithHTTPClient(&mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: 200, Body: http.NoBody}, nil
+		}}),
+		WithBrowserLauncher(func(url string) error {
+			launched = append(launched, url)
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.OpenMonitoring(context.Background()); err == nil {
+		t.Error("expected an error when monitoring is not installed")
+	}
+
+	installed = true
+	if err := c.OpenMonitoring(context.Background()); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff([]string{"http://localhost:9999/grafana/"}, launched); d != "" {
+		t.Error("launched mismatch (-want +got):", d)
+	}
+}
@@ -0,0 +1,11 @@
+package local
+
+import (
+	"context"
+	"github.com/airbytehq/abctl/internal/cmd/local/netdiag"
+)
+
+// NetworkDiagnostics runs the network checks of the host and, within the namespace of Airbyte, of the cluster.
+func (c *Command) NetworkDiagnostics(ctx context.Context) []netdiag.Finding {
+	return netdiag.New(netdiag.WithPodClient(c.k8s, c.namespace)).Run(ctx)
+}
@@ -0,0 +1,145 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/readiness"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/airbytehq/abctl/internal/trace"
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+	"sort"
+	"strings"
+	"time"
+)
+
+// scaleDownTimeout is how long to wait for the pods to terminate after scaling down.
+var scaleDownTimeout = 2 * time.Minute
+
+const (
+	// workloadDeployment is the prefix of a deployment in the replicas returned by ScaleDown.
+	workloadDeployment = "deployment/"
+	// workloadStatefulSet is the prefix of a stateful set in the replicas returned by ScaleDown.
+	workloadStatefulSet = "statefulset/"
+)
+
+// ScaleDown scales every Airbyte deployment and stateful set to zero replicas, and waits for their pods to terminate.
+// The deployments are scaled down before the stateful sets (e.g. the database), allowing Airbyte to shut down cleanly.
+//
+// The returned replicas are the replicas of each workload prior to being scaled down (keyed by deployment/name or
+// statefulset/name), which should be provided to ScaleUp.
+func (c *Command) ScaleDown(ctx context.Context) (replicas map[string]int32, err error) {
+	ctx, span := trace.NewSpan(ctx, "scale down")
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	replicas = map[string]int32{}
+
+	c.spinner.UpdateText("Scaling down Airbyte deployments")
+	deployments, err := c.k8s.DeploymentList(ctx, c.namespace)
+	if err != nil {
+		return replicas, fmt.Errorf("could not list deployments: %w", err)
+	}
+	for _, d := range deployments.Items {
+		if d.Spec.Replicas == nil || *d.Spec.Replicas == 0 {
+			continue
+		}
+		if err := c.k8s.DeploymentScale(ctx, c.namespace, d.Name, 0); err != nil {
+			return replicas, err
+		}
+		replicas[workloadDeployment+d.Name] = *d.Spec.Replicas
+	}
+
+	c.spinner.UpdateText("Scaling down Airbyte stateful sets")
+	statefulSets, err := c.k8s.StatefulSetList(ctx, c.namespace)
+	if err != nil {
+		return replicas, fmt.Errorf("could not list stateful sets: %w", err)
+	}
+	for _, s := range statefulSets.Items {
+		if s.Spec.Replicas == nil || *s.Spec.Replicas == 0 {
+			continue
+		}
+		if err := c.k8s.StatefulSetScale(ctx, c.namespace, s.Name, 0); err != nil {
+			return replicas, err
+		}
+		replicas[workloadStatefulSet+s.Name] = *s.Spec.Replicas
+	}
+
+	c.spinner.UpdateText("Waiting for Airbyte pods to terminate")
+	ctx, cancel := context.WithTimeout(ctx, scaleDownTimeout)
+	defer cancel()
+	if err := readiness.Wait(ctx, readinessInterval, c.podsTerminated(c.namespace)); err != nil {
+		// the pods are stopped regardless once the cluster is stopped, so this is not considered a failure
+		pterm.Warning.Printfln("Not every Airbyte pod terminated within %s", scaleDownTimeout)
+		logging.Debugf("pods did not terminate: %s", err)
+	}
+
+	pterm.Success.Println("Airbyte scaled down")
+	return replicas, nil
+}
+
+// ScaleUp restores the replicas, as returned from ScaleDown, of every Airbyte deployment and stateful set.
+// The stateful sets are scaled up before the deployments, which depend on them.
+func (c *Command) ScaleUp(ctx context.Context, replicas map[string]int32) (err error) {
+	ctx, span := trace.NewSpan(ctx, "scale up")
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	c.spinner.UpdateText("Scaling up Airbyte")
+
+	// sort the workloads to ensure the stateful sets are scaled first, followed by the deployments
+	workloads := make([]string, 0, len(replicas))
+	for workload := range replicas {
+		workloads = append(workloads, workload)
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		iStatefulSet, jStatefulSet := strings.HasPrefix(workloads[i], workloadStatefulSet), strings.HasPrefix(workloads[j], workloadStatefulSet)
+		if iStatefulSet != jStatefulSet {
+			return iStatefulSet
+		}
+		return workloads[i] < workloads[j]
+	})
+
+	for _, workload := range workloads {
+		switch {
+		case strings.HasPrefix(workload, workloadStatefulSet):
+			err = c.k8s.StatefulSetScale(ctx, c.namespace, strings.TrimPrefix(workload, workloadStatefulSet), replicas[workload])
+		case strings.HasPrefix(workload, workloadDeployment):
+			err = c.k8s.DeploymentScale(ctx, c.namespace, strings.TrimPrefix(workload, workloadDeployment), replicas[workload])
+		default:
+			err = fmt.Errorf("unsupported workload %s", workload)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	pterm.Success.Println("Airbyte scaled up")
+	return nil
+}
+
+// podsTerminated returns a check which is ready once the namespace contains no running pods.
+// Completed pods (e.g. of jobs) are ignored, as they are not removed by scaling down.
+func (c *Command) podsTerminated(namespace string) readiness.Check {
+	return readiness.New(fmt.Sprintf("pods terminated in namespace %s", namespace), func(ctx context.Context) error {
+		pods, err := c.k8s.PodList(ctx, namespace)
+		if err != nil {
+			return fmt.Errorf("could not list pods: %w", err)
+		}
+
+		var remaining int
+		for _, pod := range pods.Items {
+			if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+				remaining++
+			}
+		}
+		if remaining > 0 {
+			return fmt.Errorf("%d pods remaining", remaining)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,86 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestCommand_ScaleDownUp(t *testing.T) {
+	replicas := func(r int32) *int32 { return &r }
+
+	var scaled []string
+	record := func(kind string) func(ctx context.Context, namespace, name string, replicas int32) error {
+		return func(ctx context.Context, namespace, name string, replicas int32) error {
+			if namespace != airbyteNamespace {
+				t.Errorf("unexpected namespace %s", namespace)
+			}
+			scaled = append(scaled, fmt.Sprintf("%s/%s=%d", kind, name, replicas))
+			return nil
+		}
+	}
+
+	k8sClient := mockK8sClient{
+		deploymentList: func(ctx context.Context, namespace string) (*appsv1.DeploymentList, error) {
+			return &appsv1.DeploymentList{Items: []appsv1.Deployment{
+				{ObjectMeta: metav1.ObjectMeta{Name: "server"}, Spec: appsv1.DeploymentSpec{Replicas: replicas(1)}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "worker"}, Spec: appsv1.DeploymentSpec{Replicas: replicas(2)}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "disabled"}, Spec: appsv1.DeploymentSpec{Replicas: replicas(0)}},
+			}}, nil
+		},
+		deploymentScale: record("deployment"),
+		statefulSetList: func(ctx context.Context, namespace string) (*appsv1.StatefulSetList, error) {
+			return &appsv1.StatefulSetList{Items: []appsv1.StatefulSet{
+				{ObjectMeta: metav1.ObjectMeta{Name: "db"}, Spec: appsv1.StatefulSetSpec{Replicas: replicas(1)}},
+			}}, nil
+		},
+		statefulSetScale: record("statefulset"),
+		podList: func(ctx context.Context, namespace string) (*coreV1.PodList, error) {
+			// completed pods must not prevent the scale down from completing
+			return &coreV1.PodList{Items: []coreV1.Pod{
+				{ObjectMeta: metav1.ObjectMeta{Name: "bootloader"}, Status: coreV1.PodStatus{Phase: coreV1.PodSucceeded}},
+			}}, nil
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.ScaleDown(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expReplicas := map[string]int32{"deployment/server": 1, "deployment/worker": 2, "statefulset/db": 1}
+	if d := cmp.Diff(expReplicas, got); d != "" {
+		t.Error("replicas mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff([]string{"deployment/server=0", "deployment/worker=0", "statefulset/db=0"}, scaled); d != "" {
+		t.Error("scale down mismatch (-want +got):", d)
+	}
+
+	scaled = nil
+	if err := c.ScaleUp(context.Background(), got); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	// the stateful sets must be scaled up first
+	if d := cmp.Diff([]string{"statefulset/db=1", "deployment/server=1", "deployment/worker=2"}, scaled); d != "" {
+		t.Error("scale up mismatch (-want +got):", d)
+	}
+
+	if err := c.ScaleUp(context.Background(), map[string]int32{"daemonset/dne": 1}); err == nil {
+		t.Error("expected an error for an unsupported workload")
+	}
+}
@@ -0,0 +1,107 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/pterm/pterm"
+	"strings"
+)
+
+const (
+	groupApps       = "apps"
+	groupBatch      = "batch"
+	groupRBAC       = "rbac.authorization.k8s.io"
+	groupNetworking = "networking.k8s.io"
+	groupAdmission  = "admissionregistration.k8s.io"
+	groupExtensions = "apiextensions.k8s.io"
+)
+
+// airbytePermissions are the permissions every installation requires, to create the namespace, the secrets, and the
+// resources of the airbyte chart, and to verify its pods are ready.
+var airbytePermissions = []k8s.Permission{
+	{Verb: "create", Resource: "namespaces"},
+	{Verb: "create", Resource: "secrets"},
+	{Verb: "create", Resource: "configmaps"},
+	{Verb: "create", Resource: "services"},
+	{Verb: "create", Resource: "serviceaccounts"},
+	{Verb: "create", Resource: "persistentvolumeclaims"},
+	{Verb: "list", Resource: "pods"},
+	{Verb: "create", Group: groupApps, Resource: "deployments"},
+	{Verb: "create", Group: groupApps, Resource: "statefulsets"},
+	{Verb: "create", Group: groupRBAC, Resource: "roles"},
+	{Verb: "create", Group: groupRBAC, Resource: "rolebindings"},
+	{Verb: "create", Group: groupNetworking, Resource: "ingresses"},
+}
+
+// clusterPermissions are the permissions of the charts creating cluster-wide resources, i.e. the ingress controllers,
+// the log aggregation, and the monitoring.
+var clusterPermissions = []k8s.Permission{
+	{Verb: "create", Group: groupRBAC, Resource: "clusterroles"},
+	{Verb: "create", Group: groupRBAC, Resource: "clusterrolebindings"},
+}
+
+// requiredPermissions returns the permissions the installation of the opts requires, in the order they are reviewed.
+func (c *Command) requiredPermissions(opts InstallOpts) []k8s.Permission {
+	permissions := append([]k8s.Permission{}, airbytePermissions...)
+	if !c.provider.DefaultStorageClass {
+		permissions = append(permissions, k8s.Permission{Verb: "create", Resource: "persistentvolumes"})
+	}
+
+	controller := opts.IngressController
+	if controller == "" {
+		controller = c.ingressController
+	}
+	if controller != IngressNone || opts.LogAggregation || opts.Monitoring {
+		permissions = append(permissions, clusterPermissions...)
+	}
+	if controller != IngressNone {
+		permissions = append(permissions, k8s.Permission{Verb: "create", Group: groupNetworking, Resource: "ingressclasses"})
+	}
+	if controller == IngressNginx {
+		// the admission webhook of ingress-nginx is patched by jobs
+		permissions = append(permissions, k8s.Permission{Verb: "create", Group: groupBatch, Resource: "jobs"})
+	}
+	if controller == IngressNginx || opts.Monitoring {
+		permissions = append(permissions, k8s.Permission{Verb: "create", Group: groupAdmission, Resource: "validatingwebhookconfigurations"})
+	}
+	if opts.LogAggregation || opts.Monitoring {
+		permissions = append(permissions, k8s.Permission{Verb: "create", Group: groupApps, Resource: "daemonsets"})
+	}
+	if opts.Monitoring {
+		permissions = append(permissions, k8s.Permission{Verb: "create", Group: groupExtensions, Resource: "customresourcedefinitions"})
+	}
+
+	return permissions
+}
+
+// checkPermissions verifies the user of the kubeconfig context is allowed every permission the installation of the
+// opts requires, before anything is installed, instead of failing midway through the installation.
+// Every denied permission is reported at once. If the permissions cannot be reviewed, e.g. because the cluster
+// does not support SelfSubjectAccessReviews, the installation proceeds regardless.
+func (c *Command) checkPermissions(ctx context.Context, opts InstallOpts) error {
+	c.spinner.UpdateText("Checking permissions")
+
+	denied, err := c.k8s.PermissionsDenied(ctx, c.namespace, c.requiredPermissions(opts))
+	if err != nil {
+		pterm.Warning.Println("Unable to verify the permissions of the installation")
+		logging.Debugf("could not review permissions: %s", err)
+		return nil
+	}
+	if len(denied) == 0 {
+		pterm.Success.Println("Permissions verified")
+		return nil
+	}
+
+	names := make([]string, len(denied))
+	for i, p := range denied {
+		names[i] = p.String()
+	}
+	pterm.Error.Printfln("The user of context '%s' is missing the permissions the installation requires:\n  %s",
+		c.provider.Context, strings.Join(names, "\n  "))
+	pterm.Info.Printfln("Verify a permission with 'kubectl auth can-i <verb> <resource> --namespace %s'", c.namespace)
+
+	return fmt.Errorf("%w: %s", localerr.ErrForbidden, strings.Join(names, ", "))
+}
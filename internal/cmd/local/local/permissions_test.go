@@ -0,0 +1,147 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+func TestCommand_requiredPermissions(t *testing.T) {
+	var (
+		pv         = k8s.Permission{Verb: "create", Resource: "persistentvolumes"}
+		class      = k8s.Permission{Verb: "create", Group: groupNetworking, Resource: "ingressclasses"}
+		jobs       = k8s.Permission{Verb: "create", Group: groupBatch, Resource: "jobs"}
+		webhooks   = k8s.Permission{Verb: "create", Group: groupAdmission, Resource: "validatingwebhookconfigurations"}
+		daemonsets = k8s.Permission{Verb: "create", Group: groupApps, Resource: "daemonsets"}
+		crds       = k8s.Permission{Verb: "create", Group: groupExtensions, Resource: "customresourcedefinitions"}
+	)
+
+	tests := []struct {
+		name                string
+		defaultStorageClass bool
+		opts                InstallOpts
+		exp                 []k8s.Permission
+	}{
+		{
+			name: "nginx",
+			opts: InstallOpts{IngressController: IngressNginx},
+			exp:  append(append([]k8s.Permission{pv}, clusterPermissions...), class, jobs, webhooks),
+		},
+		{
+			name: "traefik",
+			opts: InstallOpts{IngressController: IngressTraefik},
+			exp:  append(append([]k8s.Permission{pv}, clusterPermissions...), class),
+		},
+		{
+			name:                "existing cluster without an ingress controller",
+			defaultStorageClass: true,
+			opts:                InstallOpts{IngressController: IngressNone},
+		},
+		{
+			name:                "log aggregation",
+			defaultStorageClass: true,
+			opts:                InstallOpts{IngressController: IngressNone, LogAggregation: true},
+			exp:                 append(append([]k8s.Permission{}, clusterPermissions...), daemonsets),
+		},
+		{
+			name:                "monitoring",
+			defaultStorageClass: true,
+			opts:                InstallOpts{IngressController: IngressNone, Monitoring: true},
+			exp:                 append(append([]k8s.Permission{}, clusterPermissions...), webhooks, daemonsets, crds),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := k8s.TestProvider
+			provider.DefaultStorageClass = tt.defaultStorageClass
+			c := &Command{provider: provider, ingressController: IngressNginx}
+
+			exp := append(append([]k8s.Permission{}, airbytePermissions...), tt.exp...)
+			if d := cmp.Diff(exp, c.requiredPermissions(tt.opts)); d != "" {
+				t.Error("permissions mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestCommand_Install_Forbidden(t *testing.T) {
+	var reviewed []k8s.Permission
+	k8sClient := mockK8sClient{
+		permissionsDenied: func(ctx context.Context, namespace string, permissions []k8s.Permission) ([]k8s.Permission, error) {
+			reviewed = permissions
+			return []k8s.Permission{
+				{Verb: "create", Resource: "namespaces"},
+				{Verb: "create", Group: groupApps, Resource: "deployments"},
+			}, nil
+		},
+		namespaceCreate: func(ctx context.Context, namespace string) error {
+			t.Error("expected nothing to be created")
+			return nil
+		},
+		namespaceExists: func(ctx context.Context, namespace string) bool {
+			return false
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithHTTPClient(&mockHTTP{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := InstallOpts{User: "user", Pass: "pass"}
+	err = c.Install(context.Background(), opts)
+	if !errors.Is(err, localerr.ErrForbidden) {
+		t.Error("expected ErrForbidden, got", err)
+	}
+	// every denied permission is reported at once
+	if err == nil || !strings.HasSuffix(err.Error(), "create namespaces, create deployments.apps") {
+		t.Error("expected the denied permissions in the error, got", err)
+	}
+	if d := cmp.Diff(c.requiredPermissions(opts), reviewed); d != "" {
+		t.Error("permissions mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_Install_PermissionsUnreviewable(t *testing.T) {
+	k8sClient := mockK8sClient{
+		permissionsDenied: func(ctx context.Context, namespace string, permissions []k8s.Permission) ([]k8s.Permission, error) {
+			return nil, errors.New("test error")
+		},
+		namespaceCreate: func(ctx context.Context, namespace string) error {
+			return errors.New("namespace error")
+		},
+		namespaceExists: func(ctx context.Context, namespace string) bool {
+			return false
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithHTTPClient(&mockHTTP{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the installation proceeds until the namespace cannot be created
+	err = c.Install(context.Background(), InstallOpts{User: "user", Pass: "pass"})
+	if err == nil || !strings.Contains(err.Error(), "namespace error") {
+		t.Error("expected the installation to proceed, got", err)
+	}
+}
@@ -0,0 +1,234 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/pterm/pterm"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ConnectorDefinition is a custom connector definition, matching the format used by the Airbyte connector registry.
+type ConnectorDefinition struct {
+	Name             string `json:"name"`
+	DockerRepository string `json:"dockerRepository"`
+	DockerImageTag   string `json:"dockerImageTag"`
+	DocumentationURL string `json:"documentationUrl"`
+}
+
+// ConnectorRegistry is a list of custom source and destination connector definitions.
+type ConnectorRegistry struct {
+	Sources      []ConnectorDefinition `json:"sources"`
+	Destinations []ConnectorDefinition `json:"destinations"`
+}
+
+// LoadConnectorRegistry loads the ConnectorRegistry from src, which can be either an http(s) url or a file path.
+func LoadConnectorRegistry(ctx context.Context, client HTTPClient, src string) (ConnectorRegistry, error) {
+	var (
+		reg  ConnectorRegistry
+		data []byte
+		err  error
+	)
+
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		data, err = fetchConnectorRegistry(ctx, client, src)
+	} else {
+		data, err = os.ReadFile(src)
+	}
+	if err != nil {
+		return reg, fmt.Errorf("could not read connector registry '%s': %w", src, err)
+	}
+
+	if err := json.Unmarshal(data, &reg); err != nil {
+		return reg, fmt.Errorf("could not unmarshal connector registry '%s': %w", src, err)
+	}
+
+	for _, def := range append(reg.Sources, reg.Destinations...) {
+		if def.Name == "" || def.DockerRepository == "" || def.DockerImageTag == "" {
+			return reg, fmt.Errorf("invalid connector registry '%s': name, dockerRepository, and dockerImageTag are required", src)
+		}
+	}
+
+	return reg, nil
+}
+
+func fetchConnectorRegistry(ctx context.Context, client HTTPClient, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+// airbyteAPI is a minimal client for the Airbyte configuration api, accessed via the ingress.
+type airbyteAPI struct {
+	http    HTTPClient
+	baseURL string
+	user    string
+	pass    string
+}
+
+// errAPINotReady is returned if the api returned a response indicating it is not yet ready to serve requests.
+var errAPINotReady = errors.New("api not ready")
+
+// post sends the req as json to the api path, unmarshalling the response into res (if res is not nil).
+func (a airbyteAPI) post(ctx context.Context, path string, req, res interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("could not marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.SetBasicAuth(a.user, a.pass)
+
+	httpRes, err := a.http.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("could not send request to %s: %w", path, err)
+	}
+	defer httpRes.Body.Close()
+
+	switch {
+	case httpRes.StatusCode == http.StatusBadGateway || httpRes.StatusCode == http.StatusServiceUnavailable:
+		return fmt.Errorf("%w: %s returned %d", errAPINotReady, path, httpRes.StatusCode)
+	case httpRes.StatusCode != http.StatusOK:
+		msg, _ := io.ReadAll(httpRes.Body)
+		return fmt.Errorf("%s returned %d: %s", path, httpRes.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if res == nil {
+		return nil
+	}
+	if err := json.NewDecoder(httpRes.Body).Decode(res); err != nil {
+		return fmt.Errorf("could not decode response from %s: %w", path, err)
+	}
+	return nil
+}
+
+// workspaceID returns the id of the first workspace, retrying until the api is ready or the ctx is done.
+func (a airbyteAPI) workspaceID(ctx context.Context, retryInterval time.Duration) (string, error) {
+	var res struct {
+		Workspaces []struct {
+			WorkspaceID string `json:"workspaceId"`
+		} `json:"workspaces"`
+	}
+
+	for {
+		err := a.post(ctx, "/api/v1/workspaces/list", struct{}{}, &res)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errAPINotReady) {
+			return "", err
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("api was not ready: %w", ctx.Err())
+		case <-time.After(retryInterval):
+		}
+	}
+
+	if len(res.Workspaces) == 0 {
+		return "", errors.New("no workspaces found")
+	}
+	return res.Workspaces[0].WorkspaceID, nil
+}
+
+// existingRepositories returns the docker repositories of every definition of the kind ("source" or "destination")
+// already available to the workspace.
+func (a airbyteAPI) existingRepositories(ctx context.Context, kind, workspaceID string) (map[string]bool, error) {
+	var res map[string][]ConnectorDefinition
+	if err := a.post(ctx, fmt.Sprintf("/api/v1/%s_definitions/list_for_workspace", kind), map[string]string{"workspaceId": workspaceID}, &res); err != nil {
+		return nil, err
+	}
+
+	repos := map[string]bool{}
+	for _, def := range res[kind+"Definitions"] {
+		repos[def.DockerRepository] = true
+	}
+	return repos, nil
+}
+
+// connectorRegistryRetryInterval is how long to wait between attempts while waiting for the api to become ready.
+var connectorRegistryRetryInterval = 5 * time.Second
+
+// importConnectorRegistry creates every definition in the reg that does not already exist in the default workspace.
+// A definition failing to be created is not considered fatal, all remaining definitions will still be created.
+func (c *Command) importConnectorRegistry(ctx context.Context, reg ConnectorRegistry, baseURL, user, pass string) error {
+	api := airbyteAPI{http: c.http, baseURL: baseURL, user: user, pass: pass}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	c.spinner.UpdateText("Waiting for the Airbyte API to become ready")
+	workspaceID, err := api.workspaceID(ctx, connectorRegistryRetryInterval)
+	if err != nil {
+		pterm.Error.Println("Unable to determine the Airbyte workspace")
+		return fmt.Errorf("could not determine workspace: %w", err)
+	}
+
+	var failed int
+	for _, kind := range []string{"source", "destination"} {
+		defs := reg.Sources
+		if kind == "destination" {
+			defs = reg.Destinations
+		}
+		if len(defs) == 0 {
+			continue
+		}
+
+		existing, err := api.existingRepositories(ctx, kind, workspaceID)
+		if err != nil {
+			pterm.Error.Printfln("Unable to list existing %s connectors", kind)
+			return fmt.Errorf("could not list %s definitions: %w", kind, err)
+		}
+
+		for _, def := range defs {
+			if existing[def.DockerRepository] {
+				pterm.Info.Printfln("Custom %s connector '%s' already exists", kind, def.Name)
+				continue
+			}
+
+			c.spinner.UpdateText(fmt.Sprintf("Creating custom %s connector '%s'", kind, def.Name))
+			req := map[string]interface{}{
+				"workspaceId":       workspaceID,
+				kind + "Definition": def,
+			}
+			if err := api.post(ctx, fmt.Sprintf("/api/v1/%s_definitions/create_custom", kind), req, nil); err != nil {
+				failed++
+				pterm.Warning.Printfln("Unable to create custom %s connector '%s'", kind, def.Name)
+				logging.Debugf("could not create %s definition %s: %s", kind, def.Name, err)
+				continue
+			}
+			pterm.Success.Printfln("Custom %s connector '%s' created", kind, def.Name)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("could not create %d custom connector(s)", failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConnectorRegistry(t *testing.T) {
+	fromFile, err := LoadConnectorRegistry(context.Background(), &mockHTTP{}, "testdata/registry.json")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(2, len(fromFile.Sources)); d != "" {
+		t.Error("sources mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff("example/destination-internal-warehouse", fromFile.Destinations[0].DockerRepository); d != "" {
+		t.Error("destination mismatch (-want +got):", d)
+	}
+
+	raw, err := os.ReadFile("testdata/registry.json")
+	if err != nil {
+		t.Fatal("could not read testdata", err)
+	}
+	client := mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+		if d := cmp.Diff("https://example.com/registry.json", req.URL.String()); d != "" {
+			t.Error("url mismatch (-want +got):", d)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(string(raw)))}, nil
+	}}
+
+	fromURL, err := LoadConnectorRegistry(context.Background(), &client, "https://example.com/registry.json")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(fromFile, fromURL); d != "" {
+		t.Error("registry mismatch (-want +got):", d)
+	}
+}
+
+func TestLoadConnectorRegistry_Invalid(t *testing.T) {
+	invalid := filepath.Join(t.TempDir(), "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"sources": [{"name": "missing repository"}]}`), 0644); err != nil {
+		t.Fatal("could not write file", err)
+	}
+
+	for _, src := range []string{"testdata/dne.json", "testdata/values.yml", invalid} {
+		if _, err := LoadConnectorRegistry(context.Background(), &mockHTTP{}, src); err == nil {
+			t.Errorf("expected an error for %s", src)
+		}
+	}
+}
+
+func TestCommand_ImportConnectorRegistry(t *testing.T) {
+	connectorRegistryRetryInterval = time.Millisecond
+	t.Cleanup(func() {
+		connectorRegistryRetryInterval = 5 * time.Second
+	})
+
+	reg, err := LoadConnectorRegistry(context.Background(), &mockHTTP{}, "testdata/registry.json")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	var (
+		notReady = 2
+		created  []string
+	)
+
+	client := mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+		if user, pass, _ := req.BasicAuth(); user != "user" || pass != "pass" {
+			t.Error("expected basic auth credentials")
+		}
+
+		respond := func(status int, body string) (*http.Response, error) {
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}, nil
+		}
+
+		switch req.URL.Path {
+		case "/api/v1/workspaces/list":
+			if notReady > 0 {
+				notReady--
+				return respond(http.StatusBadGateway, "")
+			}
+			return respond(http.StatusOK, `{"workspaces": [{"workspaceId": "ws"}]}`)
+		case "/api/v1/source_definitions/list_for_workspace":
+			return respond(http.StatusOK, `{"sourceDefinitions": [{"name": "Faker", "dockerRepository": "airbyte/source-faker"}]}`)
+		case "/api/v1/destination_definitions/list_for_workspace":
+			return respond(http.StatusOK, `{"destinationDefinitions": []}`)
+		case "/api/v1/source_definitions/create_custom", "/api/v1/destination_definitions/create_custom":
+			var body map[string]json.RawMessage
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				t.Fatal("could not decode body", err)
+			}
+			if d := cmp.Diff(`"ws"`, string(body["workspaceId"])); d != "" {
+				t.Error("workspace mismatch (-want +got):", d)
+			}
+			created = append(created, req.URL.Path)
+			return respond(http.StatusOK, "{}")
+		default:
+			t.Error("unexpected path", req.URL.Path)
+			return respond(http.StatusNotFound, "")
+		}
+	}}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&mockK8sClient{}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithHTTPClient(&client),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.importConnectorRegistry(context.Background(), reg, "http://localhost:8000", "user", "pass"); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// the faker source already exists and should not be created
+	exp := []string{"/api/v1/source_definitions/create_custom", "/api/v1/destination_definitions/create_custom"}
+	if d := cmp.Diff(exp, created); d != "" {
+		t.Error("created mismatch (-want +got):", d)
+	}
+}
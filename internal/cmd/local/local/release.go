@@ -0,0 +1,89 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	helmclient "github.com/mittwald/go-helm-client"
+	"github.com/pterm/pterm"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// releaseHistoryMax is the maximum number of revisions inspected by recoverRelease, matching helm's default.
+const releaseHistoryMax = 256
+
+// recoverRelease recovers the helm release from a pending state, which is left behind if abctl is interrupted while
+// the release is being installed, upgraded, or rolled back, and which otherwise fails every further operation with
+// "another operation (install/upgrade/rollback) is in progress".
+//
+// A pending revision which has a previous revision is rolled back to it, preserving the history of the release.
+// A pending install without any previous revision has nothing to preserve, so the release is uninstalled instead.
+// Its history is not kept, as an uninstalled release can neither be upgraded nor installed again.
+//
+// If wait is false the rollback does not wait for the resources of the previous revision to become ready, e.g. when
+// the release is recovered after abctl was interrupted and is about to exit.
+func (c *Command) recoverRelease(name string, wait bool) error {
+	helm := c.releaseHelm(name)
+	history, err := helm.ListReleaseHistory(name, releaseHistoryMax)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not get history of release %s: %w", name, err)
+	}
+
+	pending, previous := pendingRevision(history)
+	if pending == nil {
+		return nil
+	}
+
+	if previous != nil {
+		pterm.Warning.Printfln("Helm release '%s' revision %d is stuck in the '%s' state, rolling it back to revision %d",
+			name, pending.Version, pending.Info.Status, previous.Version)
+		c.spinner.UpdateText(fmt.Sprintf("Rolling back Helm release '%s' to revision %d", name, previous.Version))
+		if err := helm.RollbackRelease(&helmclient.ChartSpec{
+			ReleaseName: name,
+			Wait:        wait,
+			Timeout:     10 * time.Minute,
+		}); err != nil {
+			pterm.Error.Printfln("Unable to roll back Helm release '%s'", name)
+			return fmt.Errorf("could not roll back pending release %s: %w", name, err)
+		}
+		pterm.Success.Printfln("Helm release '%s' rolled back to revision %d", name, previous.Version)
+		return nil
+	}
+
+	pterm.Warning.Printfln("Helm release '%s' is stuck in the '%s' state, uninstalling it", name, pending.Info.Status)
+	c.spinner.UpdateText(fmt.Sprintf("Uninstalling Helm release '%s'", name))
+	if err := helm.UninstallReleaseByName(name); err != nil {
+		pterm.Error.Printfln("Unable to uninstall Helm release '%s'", name)
+		return fmt.Errorf("could not uninstall pending release %s: %w", name, err)
+	}
+	pterm.Success.Printfln("Helm release '%s' uninstalled", name)
+	return nil
+}
+
+// pendingRevision returns the latest revision of the history if it is pending, and the revision preceding it.
+// Helm rolls back to the preceding revision, whether or not it was deployed successfully.
+func pendingRevision(history []*release.Release) (pending, previous *release.Release) {
+	for _, rel := range history {
+		if rel.Info == nil {
+			continue
+		}
+		if pending == nil || rel.Version > pending.Version {
+			pending = rel
+		}
+	}
+	if pending == nil || !pending.Info.Status.IsPending() {
+		return nil, nil
+	}
+
+	for _, rel := range history {
+		if rel.Version == pending.Version-1 {
+			previous = rel
+		}
+	}
+	return pending, previous
+}
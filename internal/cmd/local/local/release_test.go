@@ -0,0 +1,116 @@
+package local
+
+import (
+	"errors"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	helmclient "github.com/mittwald/go-helm-client"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"testing"
+)
+
+func revision(version int, status release.Status) *release.Release {
+	return &release.Release{Name: airbyteChartRelease, Version: version, Info: &release.Info{Status: status}}
+}
+
+func TestCommand_RecoverRelease(t *testing.T) {
+	tests := []struct {
+		name       string
+		history    []*release.Release
+		historyErr error
+		exp        string
+	}{
+		{
+			name:       "not installed",
+			historyErr: driver.ErrReleaseNotFound,
+		},
+		{
+			name:    "deployed",
+			history: []*release.Release{revision(1, release.StatusSuperseded), revision(2, release.StatusDeployed)},
+		},
+		{
+			name:    "failed",
+			history: []*release.Release{revision(1, release.StatusDeployed), revision(2, release.StatusFailed)},
+		},
+		{
+			name:    "pending upgrade",
+			history: []*release.Release{revision(2, release.StatusPendingUpgrade), revision(1, release.StatusDeployed)},
+			exp:     "rollback",
+		},
+		{
+			name:    "pending rollback",
+			history: []*release.Release{revision(1, release.StatusSuperseded), revision(2, release.StatusFailed), revision(3, release.StatusPendingRollback)},
+			exp:     "rollback",
+		},
+		{
+			name:    "pending install",
+			history: []*release.Release{revision(1, release.StatusPendingInstall)},
+			exp:     "uninstall",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recovered string
+			helm := mockHelmClient{
+				listReleaseHistory: func(name string, max int) ([]*release.Release, error) {
+					return tt.history, tt.historyErr
+				},
+				rollbackRelease: func(spec *helmclient.ChartSpec) error {
+					if spec.ReleaseName != airbyteChartRelease {
+						t.Error("unexpected release", spec.ReleaseName)
+					}
+					recovered = "rollback"
+					return nil
+				},
+				uninstallReleaseByName: func(name string) error {
+					recovered = "uninstall"
+					return nil
+				},
+			}
+
+			c, err := New(
+				k8s.TestProvider,
+				WithHelmClient(&helm),
+				WithK8sClient(&mockK8sClient{serverVersionGet: func() (string, error) { return "test", nil }}),
+				WithTelemetryClient(&mockTelemetryClient{}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := c.recoverRelease(airbyteChartRelease, true); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, recovered); d != "" {
+				t.Error("recovery mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestCommand_RecoverRelease_Error(t *testing.T) {
+	helm := mockHelmClient{
+		listReleaseHistory: func(name string, max int) ([]*release.Release, error) {
+			return []*release.Release{revision(1, release.StatusDeployed), revision(2, release.StatusPendingUpgrade)}, nil
+		},
+		rollbackRelease: func(spec *helmclient.ChartSpec) error {
+			return errors.New("rollback failed")
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&helm),
+		WithK8sClient(&mockK8sClient{serverVersionGet: func() (string, error) { return "test", nil }}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.recoverRelease(airbyteChartRelease, true); err == nil {
+		t.Error("expected an error")
+	}
+}
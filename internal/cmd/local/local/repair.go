@@ -0,0 +1,96 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/pterm/pterm"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Problem is an inconsistency of an installation, which would otherwise fail abctl with a generic error.
+type Problem struct {
+	// Description describes the inconsistency.
+	Description string
+	// Remediation describes what Fix does.
+	Remediation string
+	// Destructive is true if Fix removes data which cannot be recovered.
+	Destructive bool
+	// Fix remediates the inconsistency.
+	Fix func(ctx context.Context) error
+}
+
+// Diagnose returns the inconsistencies of the Airbyte installation within the namespace of the existing cluster.
+func (c *Command) Diagnose(ctx context.Context) ([]Problem, error) {
+	c.spinner.UpdateText("Checking for the Airbyte Helm Chart")
+
+	history, err := c.helm.ListReleaseHistory(airbyteChartRelease, releaseHistoryMax)
+	switch {
+	case errors.Is(err, driver.ErrReleaseNotFound):
+		if !c.k8s.NamespaceExists(ctx, c.namespace) {
+			return nil, nil
+		}
+		// an interrupted installation or a helm release deleted outside of abctl leaves the namespace behind,
+		// whose resources are not owned by a release and fail the next installation
+		return []Problem{{
+			Description: fmt.Sprintf("Namespace '%s' exists, but the Airbyte Helm release does not", c.namespace),
+			Remediation: fmt.Sprintf("Delete namespace '%s' and its persistent volumes, keeping the persisted data, so Airbyte can be installed again", c.namespace),
+			Fix:         c.uninstallNamespace,
+		}}, nil
+	case err != nil:
+		return nil, fmt.Errorf("could not get history of release %s: %w", airbyteChartRelease, err)
+	}
+
+	pending, previous := pendingRevision(history)
+	if pending == nil {
+		return nil, nil
+	}
+
+	remediation := fmt.Sprintf("Uninstall Helm release '%s', as it has no previous revision", airbyteChartRelease)
+	if previous != nil {
+		remediation = fmt.Sprintf("Roll back Helm release '%s' to revision %d", airbyteChartRelease, previous.Version)
+	}
+	return []Problem{{
+		Description: fmt.Sprintf("Helm release '%s' revision %d is stuck in the '%s' state", airbyteChartRelease, pending.Version, pending.Info.Status),
+		Remediation: remediation,
+		Fix: func(ctx context.Context) error {
+			return c.recoverRelease(airbyteChartRelease, true)
+		},
+	}}, nil
+}
+
+// PersistedData returns the directories of the data persisted by any installation into a kind cluster, which is
+// reused when Airbyte is installed again.
+func PersistedData() ([]string, error) {
+	entries, err := os.ReadDir(paths.Data)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read persisted data %s: %w", paths.Data, err)
+	}
+
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			dirs = append(dirs, filepath.Join(paths.Data, e.Name()))
+		}
+	}
+	return dirs, nil
+}
+
+// RemovePersistedData removes the directories returned by PersistedData.
+func RemovePersistedData(dirs []string) error {
+	for _, dir := range dirs {
+		if err := os.RemoveAll(dir); err != nil {
+			pterm.Error.Printfln("Unable to remove persisted data '%s'", dir)
+			return fmt.Errorf("could not remove persisted data '%s': %w", dir, err)
+		}
+	}
+	pterm.Success.Println("Removed persisted data")
+	return nil
+}
@@ -0,0 +1,108 @@
+package local
+
+import (
+	"context"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	helmclient "github.com/mittwald/go-helm-client"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"testing"
+)
+
+func TestCommand_Diagnose(t *testing.T) {
+	tests := []struct {
+		name            string
+		history         []*release.Release
+		historyErr      error
+		namespaceExists bool
+		exp             []string
+		expFix          string
+	}{
+		{
+			name:       "not installed",
+			historyErr: driver.ErrReleaseNotFound,
+		},
+		{
+			name:            "namespace without release",
+			historyErr:      driver.ErrReleaseNotFound,
+			namespaceExists: true,
+			exp:             []string{"Namespace 'airbyte-abctl' exists, but the Airbyte Helm release does not"},
+			expFix:          "namespace deleted",
+		},
+		{
+			name:            "deployed",
+			history:         []*release.Release{revision(1, release.StatusDeployed)},
+			namespaceExists: true,
+		},
+		{
+			name:            "pending upgrade",
+			history:         []*release.Release{revision(1, release.StatusDeployed), revision(2, release.StatusPendingUpgrade)},
+			namespaceExists: true,
+			exp:             []string{"Helm release 'airbyte-abctl' revision 2 is stuck in the 'pending-upgrade' state"},
+			expFix:          "rollback",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fixed string
+			helm := mockHelmClient{
+				getRelease: func(name string) (*release.Release, error) {
+					return nil, driver.ErrReleaseNotFound
+				},
+				listReleaseHistory: func(name string, max int) ([]*release.Release, error) {
+					return tt.history, tt.historyErr
+				},
+				rollbackRelease: func(spec *helmclient.ChartSpec) error {
+					fixed = "rollback"
+					return nil
+				},
+			}
+			k8sClient := mockK8sClient{
+				serverVersionGet: func() (string, error) {
+					return "test", nil
+				},
+				namespaceExists: func(ctx context.Context, namespace string) bool {
+					return tt.namespaceExists
+				},
+				namespaceDelete: func(ctx context.Context, namespace string) error {
+					fixed = "namespace deleted"
+					return nil
+				},
+				persistentVolumeExists: func(ctx context.Context, namespace, name string) bool {
+					return false
+				},
+			}
+
+			c, err := New(
+				k8s.TestProvider,
+				WithHelmClient(&helm),
+				WithK8sClient(&k8sClient),
+				WithTelemetryClient(&mockTelemetryClient{}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			problems, err := c.Diagnose(context.Background())
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			var descriptions []string
+			for _, p := range problems {
+				descriptions = append(descriptions, p.Description)
+				if err := p.Fix(context.Background()); err != nil {
+					t.Error("unexpected error fixing", p.Description, err)
+				}
+			}
+			if d := cmp.Diff(tt.exp, descriptions); d != "" {
+				t.Error("problems mismatch (-want +got):", d)
+			}
+			if d := cmp.Diff(tt.expFix, fixed); d != "" {
+				t.Error("fix mismatch (-want +got):", d)
+			}
+		})
+	}
+}
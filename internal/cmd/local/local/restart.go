@@ -0,0 +1,147 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/readiness"
+	"github.com/airbytehq/abctl/internal/trace"
+	"github.com/pterm/pterm"
+	appsv1 "k8s.io/api/apps/v1"
+	"strings"
+	"time"
+)
+
+// restartComponents are the components of Airbyte which can be restarted, with the name of their deployment (without
+// the release prefix). They are restarted in this order, as each depends on those before it, e.g. the workers connect
+// to temporal and the server.
+var restartComponents = []struct {
+	name       string
+	deployment string
+}{
+	{name: "temporal", deployment: "temporal"},
+	{name: "server", deployment: "server"},
+	{name: "workload-api-server", deployment: "workload-api-server"},
+	{name: "worker", deployment: "worker"},
+	{name: "workload-launcher", deployment: "workload-launcher"},
+	{name: "cron", deployment: "cron"},
+	{name: "connector-builder", deployment: "connector-builder-server"},
+	{name: "webapp", deployment: "webapp"},
+}
+
+// RestartComponents returns the names of the components which can be restarted, in the order they are restarted.
+func RestartComponents() []string {
+	names := make([]string, len(restartComponents))
+	for i, c := range restartComponents {
+		names[i] = c.name
+	}
+	return names
+}
+
+// ValidateRestartComponents returns an error if any of the components cannot be restarted.
+func ValidateRestartComponents(components []string) error {
+	names := RestartComponents()
+	for _, c := range components {
+		found := false
+		for _, name := range names {
+			found = found || c == name
+		}
+		if !found {
+			return fmt.Errorf("invalid component %s, must be one of %s", c, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// Restart restarts the deployments of the components, or of every component if none are provided, one at a time in
+// the order of RestartComponents. Each restart is waited on for up to timeout until its rollout completed, before the
+// next component is restarted. Components which are not installed (e.g. disabled) are skipped, unless requested.
+func (c *Command) Restart(ctx context.Context, components []string, timeout time.Duration) (err error) {
+	ctx, span := trace.NewSpan(ctx, "restart")
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	deployments, err := c.k8s.DeploymentList(ctx, c.namespace)
+	if err != nil {
+		return fmt.Errorf("could not list deployments: %w", err)
+	}
+	installed := map[string]bool{}
+	for _, d := range deployments.Items {
+		installed[d.Name] = true
+	}
+
+	for _, component := range restartComponents {
+		requested := len(components) == 0
+		for _, name := range components {
+			requested = requested || name == component.name
+		}
+		if !requested {
+			continue
+		}
+
+		name := componentPrefix(component.deployment)
+		if !installed[name] {
+			if len(components) == 0 {
+				continue
+			}
+			pterm.Error.Printfln("The %s component is not installed", component.name)
+			return fmt.Errorf("deployment %s not found in namespace %s", name, c.namespace)
+		}
+
+		c.spinner.UpdateText(fmt.Sprintf("Restarting %s", component.name))
+		if err := c.k8s.DeploymentRestart(ctx, c.namespace, name); err != nil {
+			pterm.Error.Printfln("Unable to restart the %s component", component.name)
+			return fmt.Errorf("could not restart deployment %s: %w", name, err)
+		}
+
+		c.spinner.UpdateText(fmt.Sprintf("Waiting for %s to become ready", component.name))
+		waitCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := readiness.Wait(waitCtx, readinessInterval, c.rolledOut(name))
+		cancel()
+		if err != nil {
+			pterm.Error.Printfln("The %s component was not ready within %s", component.name, timeout)
+			return fmt.Errorf("deployment %s was not rolled out: %w", name, err)
+		}
+		pterm.Success.Printfln("Restarted %s", component.name)
+	}
+
+	return nil
+}
+
+// rolledOut returns a check which is ready once the rollout of the deployment completed, the same as kubectl rollout
+// status, i.e. every replica is updated and available, and no replica of the previous rollout remains.
+func (c *Command) rolledOut(name string) readiness.Check {
+	return readiness.New(fmt.Sprintf("deployment %s", name), func(ctx context.Context) error {
+		deployments, err := c.k8s.DeploymentList(ctx, c.namespace)
+		if err != nil {
+			return fmt.Errorf("could not list deployments: %w", err)
+		}
+		for _, d := range deployments.Items {
+			if d.Name == name {
+				return deploymentRolledOut(d)
+			}
+		}
+		return fmt.Errorf("deployment %s not found", name)
+	})
+}
+
+// deploymentRolledOut returns an error describing why the rollout of the deployment did not complete yet.
+func deploymentRolledOut(d appsv1.Deployment) error {
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+
+	switch {
+	case d.Status.ObservedGeneration < d.Generation:
+		return fmt.Errorf("waiting for the rollout to be observed")
+	case d.Status.UpdatedReplicas < replicas:
+		return fmt.Errorf("%d of %d replicas updated", d.Status.UpdatedReplicas, replicas)
+	case d.Status.Replicas > d.Status.UpdatedReplicas:
+		return fmt.Errorf("%d old replicas pending termination", d.Status.Replicas-d.Status.UpdatedReplicas)
+	case d.Status.AvailableReplicas < d.Status.UpdatedReplicas:
+		return fmt.Errorf("%d of %d updated replicas available", d.Status.AvailableReplicas, d.Status.UpdatedReplicas)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package local
+
+import (
+	"context"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateRestartComponents(t *testing.T) {
+	if err := ValidateRestartComponents([]string{"server", "connector-builder"}); err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	err := ValidateRestartComponents([]string{"server", "db"})
+	if err == nil || !strings.Contains(err.Error(), "invalid component db, must be one of temporal, server") {
+		t.Error("expected an invalid component error, got", err)
+	}
+}
+
+func TestCommand_Restart(t *testing.T) {
+	// a deployment whose rollout completed
+	deployment := func(name string) appsv1.Deployment {
+		replicas := int32(1)
+		return appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: componentPrefix(name), Generation: 2},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		components []string
+		exp        []string
+		expErr     string
+	}{
+		{
+			name: "all",
+			// the components are restarted in order, skipping those which are not installed
+			exp: []string{componentPrefix("temporal"), componentPrefix("server"), componentPrefix("worker"), componentPrefix("webapp")},
+		},
+		{
+			name:       "components",
+			components: []string{"webapp", "server"},
+			exp:        []string{componentPrefix("server"), componentPrefix("webapp")},
+		},
+		{
+			name:       "not installed",
+			components: []string{"server", "connector-builder"},
+			exp:        []string{componentPrefix("server")},
+			expErr:     "deployment airbyte-abctl-connector-builder-server not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var restarted []string
+			k8sClient := mockK8sClient{
+				deploymentList: func(ctx context.Context, namespace string) (*appsv1.DeploymentList, error) {
+					return &appsv1.DeploymentList{Items: []appsv1.Deployment{
+						deployment("webapp"), deployment("worker"), deployment("server"), deployment("temporal"),
+					}}, nil
+				},
+				deploymentRestart: func(ctx context.Context, namespace, name string) error {
+					restarted = append(restarted, name)
+					return nil
+				},
+			}
+
+			c, err := New(
+				k8s.TestProvider,
+				WithHelmClient(&mockHelmClient{}),
+				WithK8sClient(&k8sClient),
+				WithTelemetryClient(&mockTelemetryClient{}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = c.Restart(context.Background(), tt.components, time.Second)
+			if tt.expErr == "" && err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if tt.expErr != "" && (err == nil || !strings.Contains(err.Error(), tt.expErr)) {
+				t.Errorf("expected error containing %q, got %v", tt.expErr, err)
+			}
+			if d := cmp.Diff(tt.exp, restarted); d != "" {
+				t.Error("restarted mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestDeploymentRolledOut(t *testing.T) {
+	replicas := int32(2)
+	tests := []struct {
+		name   string
+		status appsv1.DeploymentStatus
+		expErr string
+	}{
+		{name: "not observed", status: appsv1.DeploymentStatus{ObservedGeneration: 1}, expErr: "waiting for the rollout to be observed"},
+		{name: "not updated", status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 1}, expErr: "1 of 2 replicas updated"},
+		{name: "old replicas", status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 2}, expErr: "1 old replicas pending termination"},
+		{name: "not available", status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1}, expErr: "1 of 2 updated replicas available"},
+		{name: "rolled out", status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := deploymentRolledOut(appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status:     tt.status,
+			})
+			if tt.expErr == "" && err != nil {
+				t.Error("unexpected error", err)
+			}
+			if tt.expErr != "" && (err == nil || err.Error() != tt.expErr) {
+				t.Errorf("expected error %q, got %v", tt.expErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package local
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/trace"
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// managedByLabel identifies the secrets managed by `abctl local secrets` with the value managedByAbctl.
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByAbctl = "abctl"
+	// managedByHelm is the managedByLabel of the secrets of the helm charts, which are overwritten by every upgrade.
+	managedByHelm = "Helm"
+)
+
+// reservedSecrets are the secrets abctl manages by itself, via the flags of install.
+var reservedSecrets = []string{basicAuthSecret, caCertSecret, extraManifestsSecret}
+
+// Secret is a secret managed by abctl.
+type Secret struct {
+	Name    string    `json:"name"`
+	Keys    []string  `json:"keys"`
+	Created time.Time `json:"created"`
+	// Consumers are the deployments referencing the secret.
+	Consumers []string `json:"consumers"`
+}
+
+// ParseSecretData returns the data of a secret from files, as key=path (or path, keyed by the name of the file), and
+// literals, as key=value. The contents of the files are read by readFile.
+func ParseSecretData(files, literals []string, readFile func(string) ([]byte, error)) (map[string][]byte, error) {
+	data := map[string][]byte{}
+	add := func(key string, value []byte) error {
+		if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+			return fmt.Errorf("invalid secret key %s: %s", key, strings.Join(errs, ", "))
+		}
+		if _, ok := data[key]; ok {
+			return fmt.Errorf("secret key %s is provided more than once", key)
+		}
+		data[key] = value
+		return nil
+	}
+
+	for _, f := range files {
+		key, path, ok := strings.Cut(f, "=")
+		if !ok {
+			key, path = filepath.Base(f), f
+		}
+		value, err := readFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read secret file %s: %w", path, err)
+		}
+		if err := add(key, value); err != nil {
+			return nil, err
+		}
+	}
+	for _, l := range literals {
+		key, value, ok := strings.Cut(l, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid secret literal %s, expected the format key=value", key)
+		}
+		if err := add(key, []byte(value)); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no secret keys provided")
+	}
+	return data, nil
+}
+
+// validateSecretName returns an error if the secret cannot be managed by abctl, as it is invalid, reserved, or owned
+// by a helm chart, which would overwrite it.
+func validateSecretName(name string, existing *corev1.Secret) error {
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return fmt.Errorf("invalid secret name %s: %s", name, strings.Join(errs, ", "))
+	}
+	for _, reserved := range reservedSecrets {
+		if name == reserved {
+			return fmt.Errorf("the secret %s is managed by abctl local install", name)
+		}
+	}
+	if existing != nil && existing.Labels[managedByLabel] == managedByHelm {
+		return fmt.Errorf("the secret %s is managed by the helm release %s", name, existing.Annotations["meta.helm.sh/release-name"])
+	}
+	return nil
+}
+
+// SetSecret sets the keys of the secret, keeping the other keys of an existing secret, and labels it as managed by
+// abctl. The deployments consuming the secret are restarted if restart is true, so they use the new values.
+func (c *Command) SetSecret(ctx context.Context, name string, data map[string][]byte, restart bool) (err error) {
+	ctx, span := trace.NewSpan(ctx, "secret set")
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	existing, err := c.k8s.SecretGet(ctx, c.namespace, name)
+	if k8serrors.IsNotFound(err) {
+		existing, err = nil, nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not get secret %s: %w", name, err)
+	}
+	if err := validateSecretName(name, existing); err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{}
+	if existing != nil {
+		for key, value := range existing.Data {
+			values[key] = base64.StdEncoding.EncodeToString(value)
+		}
+	}
+	for key, value := range data {
+		values[key] = base64.StdEncoding.EncodeToString(value)
+	}
+
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{"data": values}}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetName(name)
+	secret.SetLabels(map[string]string{managedByLabel: managedByAbctl})
+
+	c.spinner.UpdateText(fmt.Sprintf("Setting the secret '%s'", name))
+	if err := c.k8s.ManifestApply(ctx, c.namespace, secret); err != nil {
+		pterm.Error.Printfln("Unable to set the secret '%s'", name)
+		return fmt.Errorf("could not apply secret %s: %w", name, err)
+	}
+	pterm.Success.Printfln("Set %d key(s) of the secret '%s'", len(data), name)
+
+	if !restart {
+		return nil
+	}
+	return c.restartConsumers(ctx, name)
+}
+
+// Secrets returns the secrets managed by abctl, sorted by name.
+func (c *Command) Secrets(ctx context.Context) ([]Secret, error) {
+	list, err := c.k8s.SecretList(ctx, c.namespace, fmt.Sprintf("%s=%s", managedByLabel, managedByAbctl))
+	if err != nil {
+		return nil, fmt.Errorf("could not list secrets: %w", err)
+	}
+	consumers, err := c.secretConsumers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make([]Secret, 0, len(list.Items))
+	for _, s := range list.Items {
+		keys := make([]string, 0, len(s.Data))
+		for key := range s.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		secrets = append(secrets, Secret{Name: s.Name, Keys: keys, Created: s.CreationTimestamp.Time, Consumers: consumers[s.Name]})
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
+
+// DeleteSecret deletes the secret managed by abctl. A secret consumed by any deployment is only deleted if force is
+// true, in which case the consuming deployments are restarted if restart is true.
+func (c *Command) DeleteSecret(ctx context.Context, name string, force, restart bool) (err error) {
+	ctx, span := trace.NewSpan(ctx, "secret delete")
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	existing, err := c.k8s.SecretGet(ctx, c.namespace, name)
+	if k8serrors.IsNotFound(err) {
+		pterm.Warning.Printfln("The secret '%s' does not exist", name)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not get secret %s: %w", name, err)
+	}
+	if existing == nil || existing.Labels[managedByLabel] != managedByAbctl {
+		return fmt.Errorf("the secret %s is not managed by abctl", name)
+	}
+
+	consumers, err := c.secretConsumers(ctx)
+	if err != nil {
+		return err
+	}
+	if len(consumers[name]) > 0 && !force {
+		pterm.Error.Printfln("The secret '%s' is used by the deployments %s", name, strings.Join(consumers[name], ", "))
+		return fmt.Errorf("the secret %s is in use, provide --force to delete it regardless", name)
+	}
+
+	c.spinner.UpdateText(fmt.Sprintf("Deleting the secret '%s'", name))
+	if err := c.k8s.SecretDelete(ctx, c.namespace, name); err != nil && !k8serrors.IsNotFound(err) {
+		pterm.Error.Printfln("Unable to delete the secret '%s'", name)
+		return fmt.Errorf("could not delete secret %s: %w", name, err)
+	}
+	pterm.Success.Printfln("Deleted the secret '%s'", name)
+
+	if !restart {
+		return nil
+	}
+	return c.restartConsumers(ctx, name)
+}
+
+// restartConsumers restarts the deployments consuming the secret.
+func (c *Command) restartConsumers(ctx context.Context, name string) error {
+	consumers, err := c.secretConsumers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, deployment := range consumers[name] {
+		c.spinner.UpdateText(fmt.Sprintf("Restarting deployment '%s'", deployment))
+		if err := c.k8s.DeploymentRestart(ctx, c.namespace, deployment); err != nil {
+			pterm.Error.Printfln("Unable to restart deployment '%s'", deployment)
+			return fmt.Errorf("could not restart deployment %s: %w", deployment, err)
+		}
+		pterm.Success.Printfln("Restarted deployment '%s'", deployment)
+	}
+	return nil
+}
+
+// secretConsumers returns the names of the deployments referencing each secret, via env vars or volumes.
+func (c *Command) secretConsumers(ctx context.Context) (map[string][]string, error) {
+	deployments, err := c.k8s.DeploymentList(ctx, c.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("could not list deployments: %w", err)
+	}
+
+	consumers := map[string][]string{}
+	for _, d := range deployments.Items {
+		for _, secret := range podSecrets(d.Spec.Template.Spec) {
+			consumers[secret] = append(consumers[secret], d.Name)
+		}
+	}
+	for _, names := range consumers {
+		sort.Strings(names)
+	}
+	return consumers, nil
+}
+
+// podSecrets returns the names of the secrets the pod references, each only once.
+func podSecrets(spec corev1.PodSpec) []string {
+	var secrets []string
+	add := func(name string) {
+		for _, s := range secrets {
+			if s == name {
+				return
+			}
+		}
+		secrets = append(secrets, name)
+	}
+
+	for _, v := range spec.Volumes {
+		if v.Secret != nil {
+			add(v.Secret.SecretName)
+		}
+		if v.Projected != nil {
+			for _, source := range v.Projected.Sources {
+				if source.Secret != nil {
+					add(source.Secret.Name)
+				}
+			}
+		}
+	}
+	for _, container := range append(spec.InitContainers, spec.Containers...) {
+		for _, from := range container.EnvFrom {
+			if from.SecretRef != nil {
+				add(from.SecretRef.Name)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				add(env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+	return secrets
+}
@@ -0,0 +1,331 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"strings"
+	"testing"
+)
+
+func TestParseSecretData(t *testing.T) {
+	readFile := func(path string) ([]byte, error) {
+		if path == "/missing" {
+			return nil, errors.New("no such file")
+		}
+		return []byte("contents of " + path), nil
+	}
+
+	tests := []struct {
+		name     string
+		files    []string
+		literals []string
+		exp      map[string][]byte
+		expErr   string
+	}{
+		{
+			name:     "files and literals",
+			files:    []string{"/certs/ca.crt", "key.json=/creds/sa.json"},
+			literals: []string{"password=a=b"},
+			exp: map[string][]byte{
+				"ca.crt":   []byte("contents of /certs/ca.crt"),
+				"key.json": []byte("contents of /creds/sa.json"),
+				"password": []byte("a=b"),
+			},
+		},
+		{name: "empty", expErr: "no secret keys provided"},
+		{name: "missing file", files: []string{"/missing"}, expErr: "could not read secret file /missing: no such file"},
+		{name: "invalid literal", literals: []string{"password"}, expErr: "invalid secret literal password"},
+		{name: "invalid key", literals: []string{"pass word=x"}, expErr: "invalid secret key pass word"},
+		{name: "duplicate key", files: []string{"password=/p"}, literals: []string{"password=x"}, expErr: "secret key password is provided more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ParseSecretData(tt.files, tt.literals, readFile)
+			if tt.expErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expErr) {
+					t.Errorf("expected error containing %q, got %v", tt.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, data); d != "" {
+				t.Error("data mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+// consumingDeployments returns deployments referencing the secret "creds" in every supported way, and one which does not.
+func consumingDeployments() *appsv1.DeploymentList {
+	deployment := func(name string, spec coreV1.PodSpec) appsv1.Deployment {
+		return appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       appsv1.DeploymentSpec{Template: coreV1.PodTemplateSpec{Spec: spec}},
+		}
+	}
+
+	return &appsv1.DeploymentList{Items: []appsv1.Deployment{
+		deployment("worker", coreV1.PodSpec{Containers: []coreV1.Container{{
+			Env: []coreV1.EnvVar{{Name: "PASSWORD", ValueFrom: &coreV1.EnvVarSource{
+				SecretKeyRef: &coreV1.SecretKeySelector{LocalObjectReference: coreV1.LocalObjectReference{Name: "creds"}},
+			}}},
+		}}}),
+		deployment("server", coreV1.PodSpec{Containers: []coreV1.Container{{
+			EnvFrom: []coreV1.EnvFromSource{{SecretRef: &coreV1.SecretEnvSource{LocalObjectReference: coreV1.LocalObjectReference{Name: "creds"}}}},
+		}}}),
+		deployment("launcher", coreV1.PodSpec{Volumes: []coreV1.Volume{{
+			VolumeSource: coreV1.VolumeSource{Projected: &coreV1.ProjectedVolumeSource{Sources: []coreV1.VolumeProjection{
+				{Secret: &coreV1.SecretProjection{LocalObjectReference: coreV1.LocalObjectReference{Name: "creds"}}},
+			}}},
+		}}}),
+		deployment("webapp", coreV1.PodSpec{Volumes: []coreV1.Volume{{
+			VolumeSource: coreV1.VolumeSource{Secret: &coreV1.SecretVolumeSource{SecretName: "other"}},
+		}}}),
+	}}
+}
+
+func TestCommand_SetSecret(t *testing.T) {
+	var (
+		applied   *unstructured.Unstructured
+		restarted []string
+	)
+	k8sClient := mockK8sClient{
+		secretGet: func(ctx context.Context, namespace, name string) (*coreV1.Secret, error) {
+			return &coreV1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{managedByLabel: managedByAbctl}},
+				Data:       map[string][]byte{"user": []byte("airbyte"), "password": []byte("old")},
+			}, nil
+		},
+		manifestApply: func(ctx context.Context, namespace string, obj *unstructured.Unstructured) error {
+			applied = obj
+			return nil
+		},
+		deploymentList: func(ctx context.Context, namespace string) (*appsv1.DeploymentList, error) {
+			return consumingDeployments(), nil
+		},
+		deploymentRestart: func(ctx context.Context, namespace, name string) error {
+			restarted = append(restarted, name)
+			return nil
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.SetSecret(context.Background(), "creds", map[string][]byte{"password": []byte("new")}, true); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name":   "creds",
+			"labels": map[string]interface{}{managedByLabel: managedByAbctl},
+		},
+		// the existing keys are kept
+		"data": map[string]interface{}{"user": "YWlyYnl0ZQ==", "password": "bmV3"},
+	}
+	if d := cmp.Diff(exp, applied.Object); d != "" {
+		t.Error("secret mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff([]string{"launcher", "server", "worker"}, restarted); d != "" {
+		t.Error("restarted mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_SetSecret_Refused(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		labels map[string]string
+		expErr string
+	}{
+		{name: "invalid", secret: "Creds", expErr: "invalid secret name Creds"},
+		{name: "reserved", secret: basicAuthSecret, expErr: "the secret basic-auth is managed by abctl local install"},
+		{name: "helm", secret: "creds", labels: map[string]string{managedByLabel: managedByHelm}, expErr: "the secret creds is managed by the helm release"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := mockK8sClient{
+				secretGet: func(ctx context.Context, namespace, name string) (*coreV1.Secret, error) {
+					return &coreV1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: tt.labels}}, nil
+				},
+				manifestApply: func(ctx context.Context, namespace string, obj *unstructured.Unstructured) error {
+					t.Error("unexpected call to apply the secret")
+					return nil
+				},
+			}
+
+			c, err := New(
+				k8s.TestProvider,
+				WithHelmClient(&mockHelmClient{}),
+				WithK8sClient(&k8sClient),
+				WithTelemetryClient(&mockTelemetryClient{}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = c.SetSecret(context.Background(), tt.secret, map[string][]byte{"password": []byte("x")}, false)
+			if err == nil || !strings.Contains(err.Error(), tt.expErr) {
+				t.Errorf("expected error containing %q, got %v", tt.expErr, err)
+			}
+		})
+	}
+}
+
+func TestCommand_Secrets(t *testing.T) {
+	var selector string
+	k8sClient := mockK8sClient{
+		secretList: func(ctx context.Context, namespace, s string) (*coreV1.SecretList, error) {
+			selector = s
+			return &coreV1.SecretList{Items: []coreV1.Secret{
+				{ObjectMeta: metav1.ObjectMeta{Name: "other"}, Data: map[string][]byte{"token": nil}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "creds"}, Data: map[string][]byte{"user": nil, "password": nil}},
+			}}, nil
+		},
+		deploymentList: func(ctx context.Context, namespace string) (*appsv1.DeploymentList, error) {
+			return consumingDeployments(), nil
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secrets, err := c.Secrets(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if d := cmp.Diff("app.kubernetes.io/managed-by=abctl", selector); d != "" {
+		t.Error("selector mismatch (-want +got):", d)
+	}
+	exp := []Secret{
+		{Name: "creds", Keys: []string{"password", "user"}, Consumers: []string{"launcher", "server", "worker"}},
+		{Name: "other", Keys: []string{"token"}, Consumers: []string{"webapp"}},
+	}
+	if d := cmp.Diff(exp, secrets); d != "" {
+		t.Error("secrets mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_DeleteSecret(t *testing.T) {
+	tests := []struct {
+		name         string
+		secret       string
+		labels       map[string]string
+		force        bool
+		expDeleted   bool
+		expRestarted []string
+		expErr       string
+	}{
+		{
+			name:   "in use",
+			secret: "creds",
+			labels: map[string]string{managedByLabel: managedByAbctl},
+			expErr: "the secret creds is in use, provide --force to delete it regardless",
+		},
+		{
+			name:         "forced",
+			secret:       "creds",
+			labels:       map[string]string{managedByLabel: managedByAbctl},
+			force:        true,
+			expDeleted:   true,
+			expRestarted: []string{"launcher", "server", "worker"},
+		},
+		{
+			name:       "unused",
+			secret:     "unused",
+			labels:     map[string]string{managedByLabel: managedByAbctl},
+			expDeleted: true,
+		},
+		{
+			name:   "not managed",
+			secret: "creds",
+			expErr: "the secret creds is not managed by abctl",
+		},
+		{
+			name:   "missing",
+			secret: "missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				deleted   bool
+				restarted []string
+			)
+			k8sClient := mockK8sClient{
+				secretGet: func(ctx context.Context, namespace, name string) (*coreV1.Secret, error) {
+					if name == "missing" {
+						return nil, k8serrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+					}
+					return &coreV1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: tt.labels}}, nil
+				},
+				secretDelete: func(ctx context.Context, namespace, name string) error {
+					deleted = true
+					return nil
+				},
+				deploymentList: func(ctx context.Context, namespace string) (*appsv1.DeploymentList, error) {
+					return consumingDeployments(), nil
+				},
+				deploymentRestart: func(ctx context.Context, namespace, name string) error {
+					restarted = append(restarted, name)
+					return nil
+				},
+			}
+
+			c, err := New(
+				k8s.TestProvider,
+				WithHelmClient(&mockHelmClient{}),
+				WithK8sClient(&k8sClient),
+				WithTelemetryClient(&mockTelemetryClient{}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = c.DeleteSecret(context.Background(), tt.secret, tt.force, true)
+			if tt.expErr == "" && err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if tt.expErr != "" && (err == nil || err.Error() != tt.expErr) {
+				t.Errorf("expected error %q, got %v", tt.expErr, err)
+			}
+			if deleted != tt.expDeleted {
+				t.Errorf("expected deleted %t, got %t", tt.expDeleted, deleted)
+			}
+			if d := cmp.Diff(tt.expRestarted, restarted); d != "" {
+				t.Error("restarted mismatch (-want +got):", d)
+			}
+		})
+	}
+}
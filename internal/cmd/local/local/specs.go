@@ -6,40 +6,79 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// ingress creates an ingress type for defining the webapp ingress rules.
-func ingress() *networkingv1.Ingress {
-	var pathType = networkingv1.PathType("Prefix")
-	var ingressClassName = "nginx"
+// basicAuthSecret is the secret containing the basic-auth credentials protecting the ingresses.
+const basicAuthSecret = "basic-auth"
+
+// ingress creates an ingress type, within the namespace, for defining the webapp ingress rules.
+// A rule is created for every host provided, which is served by the ingress controller, or by the ingress class of an
+// existing ingress controller if there is no ingress controller of abctl.
+func ingress(namespace string, hosts []string, controller string, class string) *networkingv1.Ingress {
+	var rules []networkingv1.IngressRule
+	for _, host := range hosts {
+		rules = append(rules, ingressRule(host))
+	}
 
 	return &networkingv1.Ingress{
 		TypeMeta: metav1.TypeMeta{},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      airbyteIngress,
-			Namespace: airbyteNamespace,
-			Annotations: map[string]string{
-				"nginx.ingress.kubernetes.io/auth-type":   "basic",
-				"nginx.ingress.kubernetes.io/auth-secret": "basic-auth",
-				"nginx.ingress.kubernetes.io/auth-realm":  "Authentication Required - Airbyte (abctl)",
-			},
+			Name:        airbyteIngress,
+			Namespace:   namespace,
+			Annotations: ingressAnnotations(namespace, controller),
 		},
 		Spec: networkingv1.IngressSpec{
-			IngressClassName: &ingressClassName,
-			Rules: []networkingv1.IngressRule{
-				{
-					Host: "localhost",
-					IngressRuleValue: networkingv1.IngressRuleValue{
-						HTTP: &networkingv1.HTTPIngressRuleValue{
-							Paths: []networkingv1.HTTPIngressPath{
-								{
-									Path:     "/",
-									PathType: &pathType,
-									Backend: networkingv1.IngressBackend{
-										Service: &networkingv1.IngressServiceBackend{
-											Name: fmt.Sprintf("%s-airbyte-webapp-svc", airbyteChartRelease),
-											Port: networkingv1.ServiceBackendPort{
-												Name: "http",
-											},
-										},
+			IngressClassName: ingressClassName(controller, class),
+			Rules:            rules,
+		},
+	}
+}
+
+// ingressRule creates an ingress rule routing the host to the webapp service.
+func ingressRule(host string) networkingv1.IngressRule {
+	var pathType = networkingv1.PathType("Prefix")
+
+	return networkingv1.IngressRule{
+		Host: host,
+		IngressRuleValue: networkingv1.IngressRuleValue{
+			HTTP: &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{
+					{
+						Path:     "/",
+						PathType: &pathType,
+						Backend: networkingv1.IngressBackend{
+							Service: &networkingv1.IngressServiceBackend{
+								Name: fmt.Sprintf("%s-airbyte-webapp-svc", airbyteChartRelease),
+								Port: networkingv1.ServiceBackendPort{
+									Name: "http",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// grafanaIngress creates an ingress type routing the monitoringPath of every host to the grafana service.
+// It is protected by the same basic-auth as the webapp ingress.
+func grafanaIngress(namespace string, hosts []string, controller string, class string) *networkingv1.Ingress {
+	var pathType = networkingv1.PathType("Prefix")
+
+	var rules []networkingv1.IngressRule
+	for _, host := range hosts {
+		rules = append(rules, networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{
+							Path:     monitoringPath,
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: fmt.Sprintf("%s-grafana", monitoringChartRelease),
+									Port: networkingv1.ServiceBackendPort{
+										Number: 80,
 									},
 								},
 							},
@@ -47,6 +86,62 @@ func ingress() *networkingv1.Ingress {
 					},
 				},
 			},
+		})
+	}
+
+	return &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        monitoringIngress,
+			Namespace:   namespace,
+			Annotations: ingressAnnotations(namespace, controller),
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: ingressClassName(controller, class),
+			Rules:            rules,
 		},
 	}
 }
+
+// ingressClassName returns the ingress class of the controller.
+// Without an ingress controller of abctl, the ingress is served by the class of an existing ingress controller, or by
+// the default ingress class of the cluster if the class is empty.
+func ingressClassName(controller string, class string) *string {
+	if controller == IngressNone {
+		if class == "" {
+			return nil
+		}
+		return &class
+	}
+	return &controller
+}
+
+// ingressAnnotations returns the annotations protecting an ingress served by the controller with basic-auth.
+// Without an ingress controller of abctl, the nginx annotations are used, as nginx is the most common one.
+func ingressAnnotations(namespace string, controller string) map[string]string {
+	if controller == IngressTraefik {
+		return map[string]string{
+			"traefik.ingress.kubernetes.io/router.middlewares": fmt.Sprintf("%s-%s@kubernetescrd", namespace, basicAuthSecret),
+		}
+	}
+	return map[string]string{
+		"nginx.ingress.kubernetes.io/auth-type":   "basic",
+		"nginx.ingress.kubernetes.io/auth-secret": basicAuthSecret,
+		"nginx.ingress.kubernetes.io/auth-realm":  "Authentication Required - Airbyte (abctl)",
+	}
+}
+
+// traefikBasicAuthValues returns the traefik helm values creating the Middleware, within the namespace, which
+// protects the ingresses by the basic-auth secret.
+func traefikBasicAuthValues(namespace string) string {
+	return fmt.Sprintf(`extraObjects:
+  - apiVersion: traefik.io/v1alpha1
+    kind: Middleware
+    metadata:
+      name: %s
+      namespace: %s
+    spec:
+      basicAuth:
+        secret: %s
+`, basicAuthSecret, namespace, basicAuthSecret)
+}
@@ -0,0 +1,221 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/pterm/pterm"
+	"gopkg.in/yaml.v3"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const (
+	// ssoSecret is the secret containing the license key, the oidc client credentials, and the instance admin
+	// credentials, referenced by the enterprise helm values.
+	ssoSecret = "airbyte-config-secrets"
+
+	ssoKeyLicense       = "license-key"
+	ssoKeyClientID      = "client-id"
+	ssoKeyClientSecret  = "client-secret"
+	ssoKeyAdminEmail    = "instance-admin-email"
+	ssoKeyAdminPassword = "instance-admin-password"
+)
+
+// SSOOpts configures the OIDC single sign-on of an enterprise installation.
+type SSOOpts struct {
+	// Issuer is the url of the OIDC identity provider, e.g. https://example.okta.com.
+	Issuer string
+	// AppName is the name of the Airbyte application within the identity provider.
+	AppName string
+	// ClientID is the client id of the Airbyte application.
+	ClientID string
+	// ClientSecret is the client secret of the Airbyte application.
+	ClientSecret string
+	// LicenseKey is the Airbyte enterprise license key.
+	LicenseKey string
+	// AirbyteURL is the url Airbyte is accessed from, which the identity provider redirects back to.
+	AirbyteURL string
+	// AdminFirstName and AdminLastName are the name of the instance admin.
+	AdminFirstName string
+	AdminLastName  string
+	// AdminEmail and AdminPassword are the credentials of the instance admin, who can sign in without the identity
+	// provider.
+	AdminEmail    string
+	AdminPassword string
+}
+
+// Validate returns an error describing every missing or invalid option.
+func (o SSOOpts) Validate() error {
+	var errs []error
+	for _, required := range []struct{ name, value string }{
+		{"issuer", o.Issuer},
+		{"app name", o.AppName},
+		{"client id", o.ClientID},
+		{"client secret", o.ClientSecret},
+		{"license key", o.LicenseKey},
+		{"airbyte url", o.AirbyteURL},
+		{"admin email", o.AdminEmail},
+		{"admin password", o.AdminPassword},
+	} {
+		if strings.TrimSpace(required.value) == "" {
+			errs = append(errs, fmt.Errorf("%s is required", required.name))
+		}
+	}
+
+	if o.Issuer != "" {
+		if u, err := url.Parse(o.Issuer); err != nil || u.Scheme != "https" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("issuer %s must be an https url", o.Issuer))
+		}
+	}
+	if o.AirbyteURL != "" {
+		if u, err := url.Parse(o.AirbyteURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("airbyte url %s must be an http or https url", o.AirbyteURL))
+		}
+	}
+	if o.AdminEmail != "" && !strings.Contains(o.AdminEmail, "@") {
+		errs = append(errs, fmt.Errorf("admin email %s is invalid", o.AdminEmail))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ssoValues returns the enterprise helm values which enable OIDC, referencing the credentials within the ssoSecret.
+func ssoValues(opts SSOOpts) (string, error) {
+	issuer, _ := url.Parse(opts.Issuer)
+	values := map[string]interface{}{
+		"global": map[string]interface{}{
+			"edition":    "enterprise",
+			"airbyteUrl": strings.TrimSuffix(opts.AirbyteURL, "/"),
+			"enterprise": map[string]interface{}{
+				"secretName":          ssoSecret,
+				"licenseKeySecretKey": ssoKeyLicense,
+			},
+			"auth": map[string]interface{}{
+				"instanceAdmin": map[string]interface{}{
+					"firstName":         opts.AdminFirstName,
+					"lastName":          opts.AdminLastName,
+					"emailSecretKey":    ssoKeyAdminEmail,
+					"passwordSecretKey": ssoKeyAdminPassword,
+				},
+				"identityProvider": map[string]interface{}{
+					"type":       "oidc",
+					"secretName": ssoSecret,
+					"oidc": map[string]interface{}{
+						// the domain excludes the scheme, as the identity provider is always accessed via https
+						"domain":                strings.TrimSuffix(issuer.Host+issuer.Path, "/"),
+						"appName":               opts.AppName,
+						"clientIdSecretKey":     ssoKeyClientID,
+						"clientSecretSecretKey": ssoKeyClientSecret,
+					},
+				},
+			},
+		},
+	}
+
+	raw, err := yaml.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal sso values: %w", err)
+	}
+	return string(raw), nil
+}
+
+// ConfigureSSO configures OIDC single sign-on for the installed Airbyte, converting it to the enterprise edition.
+// The credentials are stored in the ssoSecret, the airbyte release is upgraded with its existing values and the
+// enterprise values, and the server and webapp are restarted so they use the updated credentials.
+func (c *Command) ConfigureSSO(ctx context.Context, opts SSOOpts, download *DownloadOpts) error {
+	if err := opts.Validate(); err != nil {
+		return fmt.Errorf("invalid sso configuration: %w", err)
+	}
+	if err := c.resolveSSOSecrets(ctx, &opts); err != nil {
+		return err
+	}
+
+	c.spinner.UpdateText("Checking for the Airbyte Helm Chart")
+	rel, err := c.helm.GetRelease(airbyteChartRelease)
+	if err != nil {
+		pterm.Error.Println("Airbyte is not installed, run 'abctl local install' to install it")
+		return fmt.Errorf("could not get airbyte release: %w", err)
+	}
+
+	values, err := ssoValues(opts)
+	if err != nil {
+		return err
+	}
+
+	c.spinner.UpdateText("Configuring the SSO secret")
+	data := map[string][]byte{
+		ssoKeyLicense:       []byte(opts.LicenseKey),
+		ssoKeyClientID:      []byte(opts.ClientID),
+		ssoKeyClientSecret:  []byte(opts.ClientSecret),
+		ssoKeyAdminEmail:    []byte(opts.AdminEmail),
+		ssoKeyAdminPassword: []byte(opts.AdminPassword),
+	}
+	if err := c.k8s.SecretCreateOrUpdate(ctx, c.namespace, ssoSecret, data); err != nil {
+		pterm.Error.Printfln("Unable to configure the secret '%s'", ssoSecret)
+		return fmt.Errorf("could not create or update secret %s: %w", ssoSecret, err)
+	}
+	pterm.Success.Printfln("Configured the secret '%s'", ssoSecret)
+
+	if err := c.handleChart(ctx, chartRequest{
+		name:         "airbyte",
+		repoName:     airbyteRepoName,
+		repoURL:      airbyteRepoURL,
+		chartName:    airbyteChartName,
+		chartRelease: airbyteChartRelease,
+		chartVersion: rel.Chart.Metadata.Version,
+		namespace:    c.namespace,
+		valuesYAML:   values,
+		reuseValues:  true,
+		download:     download,
+		provenance:   true,
+	}); err != nil {
+		return fmt.Errorf("could not upgrade airbyte chart: %w", err)
+	}
+
+	return c.restartDeployments(ctx, "-server", "-webapp")
+}
+
+// resolveSSOSecrets replaces the credentials of the options which reference the secrets of an external secret store
+// by their values.
+func (c *Command) resolveSSOSecrets(ctx context.Context, opts *SSOOpts) error {
+	for _, o := range []struct {
+		name  string
+		value *string
+	}{
+		{"client secret", &opts.ClientSecret},
+		{"license key", &opts.LicenseKey},
+		{"admin password", &opts.AdminPassword},
+	} {
+		value, err := c.secrets.Resolve(ctx, *o.value)
+		if err != nil {
+			pterm.Error.Printfln("Unable to resolve the %s", o.name)
+			return fmt.Errorf("could not resolve %s: %w", o.name, err)
+		}
+		*o.value = value
+	}
+	return nil
+}
+
+// restartDeployments restarts the deployments whose names end with any of the suffixes, or every deployment if no
+// suffixes are provided.
+func (c *Command) restartDeployments(ctx context.Context, suffixes ...string) error {
+	deployments, err := c.k8s.DeploymentList(ctx, c.namespace)
+	if err != nil {
+		return fmt.Errorf("could not list deployments: %w", err)
+	}
+	for _, d := range deployments.Items {
+		if len(suffixes) > 0 && !slices.ContainsFunc(suffixes, func(suffix string) bool { return strings.HasSuffix(d.Name, suffix) }) {
+			continue
+		}
+		c.spinner.UpdateText(fmt.Sprintf("Restarting deployment '%s'", d.Name))
+		if err := c.k8s.DeploymentRestart(ctx, c.namespace, d.Name); err != nil {
+			pterm.Error.Printfln("Unable to restart deployment '%s'", d.Name)
+			return err
+		}
+		pterm.Success.Printfln("Restarted deployment '%s'", d.Name)
+	}
+
+	return nil
+}
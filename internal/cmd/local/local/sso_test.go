@@ -0,0 +1,305 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	helmclient "github.com/mittwald/go-helm-client"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/repo"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+	"testing"
+)
+
+var ssoOptsTest = SSOOpts{
+	Issuer:         "https://example.okta.com/oauth2/",
+	AppName:        "airbyte",
+	ClientID:       "client",
+	ClientSecret:   "secret",
+	LicenseKey:     "license",
+	AirbyteURL:     "http://localhost:8000/",
+	AdminFirstName: "Jane",
+	AdminLastName:  "Doe",
+	AdminEmail:     "admin@example.com",
+	AdminPassword:  "password",
+}
+
+func TestSSOOpts_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(o *SSOOpts)
+		errs   []string
+	}{
+		{
+			name:   "valid",
+			modify: func(o *SSOOpts) {},
+		},
+		{
+			name: "missing",
+			modify: func(o *SSOOpts) {
+				o.ClientID = ""
+				o.LicenseKey = " "
+			},
+			errs: []string{"client id is required", "license key is required"},
+		},
+		{
+			name: "http issuer",
+			modify: func(o *SSOOpts) {
+				o.Issuer = "http://example.okta.com"
+			},
+			errs: []string{"issuer http://example.okta.com must be an https url"},
+		},
+		{
+			name: "invalid urls and email",
+			modify: func(o *SSOOpts) {
+				o.Issuer = "example.okta.com"
+				o.AirbyteURL = "localhost:8000"
+				o.AdminEmail = "admin"
+			},
+			errs: []string{
+				"issuer example.okta.com must be an https url",
+				"airbyte url localhost:8000 must be an http or https url",
+				"admin email admin is invalid",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ssoOptsTest
+			tt.modify(&opts)
+
+			err := opts.Validate()
+			if len(tt.errs) == 0 {
+				if err != nil {
+					t.Fatal("unexpected error", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if d := cmp.Diff(tt.errs, strings.Split(err.Error(), "\n")); d != "" {
+				t.Error("errors mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestSSOValues(t *testing.T) {
+	raw, err := ssoValues(ssoOptsTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var values map[string]interface{}
+	if err := yaml.Unmarshal([]byte(raw), &values); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := map[string]interface{}{
+		"global": map[string]interface{}{
+			"edition":    "enterprise",
+			"airbyteUrl": "http://localhost:8000",
+			"enterprise": map[string]interface{}{
+				"secretName":          "airbyte-config-secrets",
+				"licenseKeySecretKey": "license-key",
+			},
+			"auth": map[string]interface{}{
+				"instanceAdmin": map[string]interface{}{
+					"firstName":         "Jane",
+					"lastName":          "Doe",
+					"emailSecretKey":    "instance-admin-email",
+					"passwordSecretKey": "instance-admin-password",
+				},
+				"identityProvider": map[string]interface{}{
+					"type":       "oidc",
+					"secretName": "airbyte-config-secrets",
+					"oidc": map[string]interface{}{
+						"domain":                "example.okta.com/oauth2",
+						"appName":               "airbyte",
+						"clientIdSecretKey":     "client-id",
+						"clientSecretSecretKey": "client-secret",
+					},
+				},
+			},
+		},
+	}
+	if d := cmp.Diff(exp, values); d != "" {
+		t.Error("values mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_ConfigureSSO(t *testing.T) {
+	var (
+		secret    map[string][]byte
+		spec      *helmclient.ChartSpec
+		restarted []string
+	)
+
+	helm := mockHelmClient{
+		addOrUpdateChartRepo: func(entry repo.Entry) error {
+			return nil
+		},
+		getRelease: func(name string) (*release.Release, error) {
+			return &release.Release{Name: name, Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "1.2.3"}}}, nil
+		},
+		getChart: func(name string, opts *action.ChartPathOptions) (*chart.Chart, string, error) {
+			return &chart.Chart{Metadata: &chart.Metadata{Version: opts.Version}}, "", nil
+		},
+		installOrUpgradeChart: func(ctx context.Context, s *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+			spec = s
+			return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: s.Version}}, Name: s.ReleaseName}, nil
+		},
+	}
+
+	k8sClient := mockK8sClient{
+		serverVersionGet: func() (string, error) {
+			return "test", nil
+		},
+		secretCreateOrUpdate: func(ctx context.Context, namespace, name string, data map[string][]byte) error {
+			if name != ssoSecret {
+				t.Error("unexpected secret", name)
+			}
+			secret = data
+			return nil
+		},
+		deploymentList: func(ctx context.Context, namespace string) (*appsv1.DeploymentList, error) {
+			return &appsv1.DeploymentList{Items: []appsv1.Deployment{
+				{ObjectMeta: metav1.ObjectMeta{Name: "airbyte-abctl-server"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "airbyte-abctl-worker"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "airbyte-abctl-webapp"}},
+			}}, nil
+		},
+		deploymentRestart: func(ctx context.Context, namespace, name string) error {
+			restarted = append(restarted, name)
+			return nil
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&helm),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.ConfigureSSO(context.Background(), ssoOptsTest, nil); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expSecret := map[string][]byte{
+		"license-key":             []byte("license"),
+		"client-id":               []byte("client"),
+		"client-secret":           []byte("secret"),
+		"instance-admin-email":    []byte("admin@example.com"),
+		"instance-admin-password": []byte("password"),
+	}
+	if d := cmp.Diff(expSecret, secret); d != "" {
+		t.Error("secret mismatch (-want +got):", d)
+	}
+
+	if spec == nil {
+		t.Fatal("chart was not upgraded")
+	}
+	if spec.ReleaseName != airbyteChartRelease || spec.Version != "1.2.3" || !spec.ReuseValues {
+		t.Errorf("unexpected chart spec: release %s, version %s, reuse values %t", spec.ReleaseName, spec.Version, spec.ReuseValues)
+	}
+	if !strings.Contains(spec.ValuesYaml, "edition: enterprise") {
+		t.Error("expected enterprise values, got", spec.ValuesYaml)
+	}
+
+	if d := cmp.Diff([]string{"airbyte-abctl-server", "airbyte-abctl-webapp"}, restarted); d != "" {
+		t.Error("restarted mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_ConfigureSSO_SecretReferences(t *testing.T) {
+	var secret map[string][]byte
+	helm := mockHelmClient{
+		addOrUpdateChartRepo: func(entry repo.Entry) error {
+			return nil
+		},
+		getRelease: func(name string) (*release.Release, error) {
+			return &release.Release{Name: name, Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "1.2.3"}}}, nil
+		},
+		getChart: func(name string, opts *action.ChartPathOptions) (*chart.Chart, string, error) {
+			return &chart.Chart{Metadata: &chart.Metadata{Version: opts.Version}}, "", nil
+		},
+		installOrUpgradeChart: func(ctx context.Context, s *helmclient.ChartSpec, opts *helmclient.GenericHelmOptions) (*release.Release, error) {
+			return &release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: s.Version}}, Name: s.ReleaseName}, nil
+		},
+	}
+	k8sClient := mockK8sClient{
+		secretCreateOrUpdate: func(ctx context.Context, namespace, name string, data map[string][]byte) error {
+			secret = data
+			return nil
+		},
+	}
+	resolver := mockSecretResolver{secrets: map[string]string{
+		"vault://secret/data/sso#client-secret": "vault-secret",
+		"aws-sm://airbyte/license":              "aws-license",
+	}}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&helm),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithSecretResolver(&resolver),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := ssoOptsTest
+	opts.ClientSecret = "vault://secret/data/sso#client-secret"
+	opts.LicenseKey = "aws-sm://airbyte/license"
+	if err := c.ConfigureSSO(context.Background(), opts, nil); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if d := cmp.Diff("vault-secret", string(secret["client-secret"])); d != "" {
+		t.Error("client secret mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff("aws-license", string(secret["license-key"])); d != "" {
+		t.Error("license key mismatch (-want +got):", d)
+	}
+
+	opts.AdminPassword = "vault://secret/data/sso#missing"
+	err = c.ConfigureSSO(context.Background(), opts, nil)
+	if err == nil || !strings.Contains(err.Error(), "could not resolve admin password: unknown secret vault://secret/data/sso#missing") {
+		t.Error("expected an unresolved secret error, got", err)
+	}
+}
+
+func TestCommand_ConfigureSSO_NotInstalled(t *testing.T) {
+	helm := mockHelmClient{
+		getRelease: func(name string) (*release.Release, error) {
+			return nil, errors.New("release: not found")
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&helm),
+		WithK8sClient(&mockK8sClient{serverVersionGet: func() (string, error) { return "test", nil }}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.ConfigureSSO(context.Background(), ssoOptsTest, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+}
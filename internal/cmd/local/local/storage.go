@@ -0,0 +1,100 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/logging"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const (
+	// storagePod is the pod of the minio stateful set the airbyte chart installs, unless another storage is configured.
+	storagePod = "airbyte-minio-0"
+	// storageAlias is the alias of the minio server within the pod, configured by the MC_HOST_<alias> variable of mc.
+	storageAlias = "airbyte"
+	// storageUser and storagePass are the minio credentials of the airbyte chart, unless others were generated.
+	storageUser = "minio"
+	storagePass = "minio123"
+)
+
+// StoragePath returns the cleaned path of an object or prefix, as bucket/key, rejecting any path outside the buckets.
+// An empty path is the root, listing the buckets.
+func StoragePath(p string) (string, error) {
+	for _, part := range strings.Split(p, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("invalid storage path %s, must be bucket/key", p)
+		}
+	}
+	return strings.Trim(path.Clean("/"+p), "/"), nil
+}
+
+// storageCommand returns the mc command executed within the minio pod, authenticated by the credentials of the
+// generated secret. If the secret cannot be read the default credentials of the airbyte chart are assumed.
+func (c *Command) storageCommand(ctx context.Context, args ...string) []string {
+	user, pass := storageUser, storagePass
+	secret, err := c.k8s.SecretGet(ctx, c.namespace, dbSecret)
+	switch {
+	case err != nil:
+		logging.Debugf("could not get secret %s: %s", dbSecret, err)
+	case secret != nil:
+		if v := string(secret.Data["MINIO_ACCESS_KEY_ID"]); v != "" {
+			user = v
+		}
+		if v := string(secret.Data["MINIO_SECRET_ACCESS_KEY"]); v != "" {
+			pass = v
+		}
+	}
+
+	host := url.URL{Scheme: "http", User: url.UserPassword(user, pass), Host: "localhost:9000"}
+	return append([]string{"env", fmt.Sprintf("MC_HOST_%s=%s", storageAlias, host.String()), "mc", "--quiet", "--no-color"}, args...)
+}
+
+// storageExec executes mc within the minio pod, reading stdin (if not nil) and writing its output to stdout.
+func (c *Command) storageExec(ctx context.Context, stdin io.Reader, stdout io.Writer, args ...string) error {
+	if err := c.podExists(ctx, storagePod, localerr.ErrNoStorage); err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	streams := k8s.ExecStreams{Stdin: stdin, Stdout: stdout, Stderr: &stderr}
+	if err := c.k8s.PodExecStreams(ctx, c.namespace, storagePod, c.storageCommand(ctx, args...), streams); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// ListStorage writes the buckets, or the objects and prefixes within the bucket/prefix, of the minio storage to w.
+// If recursive is true, every object below the prefix is listed.
+func (c *Command) ListStorage(ctx context.Context, p string, recursive bool, w io.Writer) error {
+	args := []string{"ls"}
+	if recursive {
+		args = append(args, "--recursive")
+	}
+	if err := c.storageExec(ctx, nil, w, append(args, path.Join(storageAlias, p)+"/")...); err != nil {
+		return fmt.Errorf("could not list %s: %w", p, err)
+	}
+	return nil
+}
+
+// GetStorage writes the contents of the bucket/key object of the minio storage to w.
+func (c *Command) GetStorage(ctx context.Context, p string, w io.Writer) error {
+	if err := c.storageExec(ctx, nil, w, "cat", path.Join(storageAlias, p)); err != nil {
+		return fmt.Errorf("could not get %s: %w", p, err)
+	}
+	return nil
+}
+
+// PutStorage writes the contents read from r to the bucket/key object of the minio storage, replacing any existing
+// object.
+func (c *Command) PutStorage(ctx context.Context, p string, r io.Reader) error {
+	if err := c.storageExec(ctx, r, io.Discard, "pipe", path.Join(storageAlias, p)); err != nil {
+		return fmt.Errorf("could not put %s: %w", p, err)
+	}
+	return nil
+}
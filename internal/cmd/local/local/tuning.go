@@ -0,0 +1,118 @@
+package local
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/docker"
+	"github.com/pterm/pterm"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// TuningOpts are the job concurrency and resource values of Airbyte. The zero value of every field keeps the default
+// of the airbyte chart.
+type TuningOpts struct {
+	// MaxSyncWorkers is the maximum number of syncs each worker runs concurrently.
+	MaxSyncWorkers int
+	// JobCPURequest, JobCPULimit, JobMemoryRequest, and JobMemoryLimit are the resources of the pods of the connector
+	// jobs, as kubernetes quantities (e.g. 500m or 2 cpus, 512Mi or 2Gi memory).
+	JobCPURequest    string
+	JobCPULimit      string
+	JobMemoryRequest string
+	JobMemoryLimit   string
+	// WorkerReplicas is the number of replicas of the worker.
+	WorkerReplicas int
+}
+
+// Validate returns an error if any of the values is negative or not a valid quantity, or a request exceeds its limit.
+func (t TuningOpts) Validate() error {
+	if t.MaxSyncWorkers < 0 {
+		return fmt.Errorf("invalid max sync workers %d, must not be negative", t.MaxSyncWorkers)
+	}
+	if t.WorkerReplicas < 0 {
+		return fmt.Errorf("invalid worker replicas %d, must not be negative", t.WorkerReplicas)
+	}
+
+	for _, r := range []struct {
+		name           string
+		request, limit string
+	}{
+		{name: "cpu", request: t.JobCPURequest, limit: t.JobCPULimit},
+		{name: "memory", request: t.JobMemoryRequest, limit: t.JobMemoryLimit},
+	} {
+		request, err := parseQuantity(r.request)
+		if err != nil {
+			return fmt.Errorf("invalid job %s request %s: %w", r.name, r.request, err)
+		}
+		limit, err := parseQuantity(r.limit)
+		if err != nil {
+			return fmt.Errorf("invalid job %s limit %s: %w", r.name, r.limit, err)
+		}
+		if request != nil && limit != nil && request.Cmp(*limit) > 0 {
+			return fmt.Errorf("the job %s request %s exceeds its limit %s", r.name, r.request, r.limit)
+		}
+	}
+
+	return nil
+}
+
+// CheckResources returns an error if a job requests more cpus or memory than the docker host has, as the job would
+// never be scheduled. A warning is printed if the concurrent syncs together request more memory than it has.
+func (t TuningOpts) CheckResources(res docker.Resources) error {
+	if cpu, _ := parseQuantity(t.JobCPURequest); cpu != nil && res.CPUs > 0 && cpu.MilliValue() > int64(res.CPUs)*1000 {
+		return fmt.Errorf("the job cpu request %s exceeds the %d cpus of the docker host, jobs would never be scheduled", t.JobCPURequest, res.CPUs)
+	}
+
+	memory, _ := parseQuantity(t.JobMemoryRequest)
+	if memory == nil || res.Memory <= 0 {
+		return nil
+	}
+	if memory.Value() > res.Memory {
+		return fmt.Errorf("the job memory request %s exceeds the %s memory of the docker host, jobs would never be scheduled",
+			t.JobMemoryRequest, resource.NewQuantity(res.Memory, resource.BinarySI))
+	}
+	// each sync runs a source and a destination job
+	if syncs := int64(t.MaxSyncWorkers); syncs > 0 && 2*syncs*memory.Value() > res.Memory {
+		pterm.Warning.Printfln("%d concurrent syncs request more memory than the %s of the docker host, syncs may wait to be scheduled",
+			syncs, resource.NewQuantity(res.Memory, resource.BinarySI))
+	}
+
+	return nil
+}
+
+// parseQuantity returns the parsed quantity, or nil if s is empty.
+func parseQuantity(s string) (*resource.Quantity, error) {
+	if s == "" {
+		return nil, nil
+	}
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return nil, err
+	}
+	if q.Sign() < 0 {
+		return nil, fmt.Errorf("must not be negative")
+	}
+	return &q, nil
+}
+
+// tuningValues returns the airbyte chart values of the tuning options which are set.
+func tuningValues(t TuningOpts) []string {
+	var values []string
+	if t.MaxSyncWorkers > 0 {
+		values = append(values, fmt.Sprintf("worker.env_vars.MAX_SYNC_WORKERS=%d", t.MaxSyncWorkers))
+	}
+	if t.WorkerReplicas > 0 {
+		values = append(values, fmt.Sprintf("worker.replicaCount=%d", t.WorkerReplicas))
+	}
+	for _, r := range []struct {
+		key, value string
+	}{
+		{key: "requests.cpu", value: t.JobCPURequest},
+		{key: "limits.cpu", value: t.JobCPULimit},
+		{key: "requests.memory", value: t.JobMemoryRequest},
+		{key: "limits.memory", value: t.JobMemoryLimit},
+	} {
+		if r.value != "" {
+			values = append(values, fmt.Sprintf("global.jobs.resources.%s=%s", r.key, r.value))
+		}
+	}
+	return values
+}
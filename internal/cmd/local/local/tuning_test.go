@@ -0,0 +1,93 @@
+package local
+
+import (
+	"github.com/airbytehq/abctl/internal/cmd/local/docker"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+func TestTuningOpts_Validate(t *testing.T) {
+	valid := TuningOpts{MaxSyncWorkers: 2, WorkerReplicas: 1, JobCPURequest: "500m", JobCPULimit: "1", JobMemoryRequest: "512Mi", JobMemoryLimit: "1Gi"}
+	if err := valid.Validate(); err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	tests := []struct {
+		name string
+		opts TuningOpts
+		err  string
+	}{
+		{name: "negative workers", opts: TuningOpts{MaxSyncWorkers: -1}, err: "invalid max sync workers -1"},
+		{name: "negative replicas", opts: TuningOpts{WorkerReplicas: -1}, err: "invalid worker replicas -1"},
+		{name: "invalid quantity", opts: TuningOpts{JobMemoryRequest: "1GB"}, err: "invalid job memory request 1GB"},
+		{name: "negative quantity", opts: TuningOpts{JobCPULimit: "-1"}, err: "invalid job cpu limit -1"},
+		{name: "request exceeds limit", opts: TuningOpts{JobCPURequest: "2", JobCPULimit: "1500m"}, err: "the job cpu request 2 exceeds its limit 1500m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestTuningOpts_CheckResources(t *testing.T) {
+	res := docker.Resources{CPUs: 4, Memory: 8 << 30}
+
+	if err := (TuningOpts{MaxSyncWorkers: 8, JobCPURequest: "4", JobMemoryRequest: "2Gi"}).CheckResources(res); err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	tests := []struct {
+		name string
+		opts TuningOpts
+		err  string
+	}{
+		{name: "cpu", opts: TuningOpts{JobCPURequest: "4500m"}, err: "the job cpu request 4500m exceeds the 4 cpus of the docker host"},
+		{name: "memory", opts: TuningOpts{JobMemoryRequest: "10Gi"}, err: "the job memory request 10Gi exceeds the 8Gi memory of the docker host"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.CheckResources(res); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestTuningValues(t *testing.T) {
+	if values := tuningValues(TuningOpts{}); len(values) != 0 {
+		t.Error("expected no values, got", values)
+	}
+
+	values, err := mergedValues("", tuningValues(TuningOpts{
+		MaxSyncWorkers:   3,
+		WorkerReplicas:   2,
+		JobCPURequest:    "500m",
+		JobMemoryRequest: "512Mi",
+		JobMemoryLimit:   "2Gi",
+	}))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := map[string]interface{}{
+		"worker": map[string]interface{}{
+			"env_vars":     map[string]interface{}{"MAX_SYNC_WORKERS": int64(3)},
+			"replicaCount": int64(2),
+		},
+		"global": map[string]interface{}{
+			"jobs": map[string]interface{}{
+				"resources": map[string]interface{}{
+					"requests": map[string]interface{}{"cpu": "500m", "memory": "512Mi"},
+					"limits":   map[string]interface{}{"memory": "2Gi"},
+				},
+			},
+		},
+	}
+	if d := cmp.Diff(exp, values); d != "" {
+		t.Error("values mismatch (-want +got):", d)
+	}
+}
@@ -0,0 +1,365 @@
+package local
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/logging"
+	helmclient "github.com/mittwald/go-helm-client"
+	helmvalues "github.com/mittwald/go-helm-client/values"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/repo"
+	"sort"
+	"strings"
+)
+
+// ErrValueNotFound is returned if the requested key does not exist within the chart's values.
+var ErrValueNotFound = errors.New("value not found")
+
+// ValueDoc contains the documentation of a single helm chart value.
+type ValueDoc struct {
+	// Key is the full dotted path of the value, e.g. "global.edition"
+	Key string
+	// Chart is the name of the chart (or subchart) the value was found in
+	Chart string
+	// Type of the value, as defined by the values schema or inferred from the default value
+	Type string
+	// Default value, formatted as yaml
+	Default string
+	// Description of the value, as defined by the values schema or the comments within the values file
+	Description string
+}
+
+// NewHelmChartClient returns a helm client suitable only for fetching charts,
+// as it does not require access to a kubernetes cluster.
+func NewHelmChartClient() (HelmClient, error) {
+	helm, err := helmclient.New(&helmclient.Options{
+		Namespace: airbyteNamespace,
+		Output:    &noopWriter{},
+		DebugLog:  logging.Tracef,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create helm client: %w", err)
+	}
+
+	return helm, nil
+}
+
+// GetAirbyteChart fetches the airbyte helm chart of the given version.
+// If the version is empty, the latest version will be returned.
+func GetAirbyteChart(helm HelmClient, version string) (*chart.Chart, error) {
+	if err := helm.AddOrUpdateChartRepo(repo.Entry{Name: airbyteRepoName, URL: airbyteRepoURL}); err != nil {
+		return nil, fmt.Errorf("could not add airbyte chart repo: %w", err)
+	}
+
+	c, _, err := helm.GetChart(airbyteChartName, &action.ChartPathOptions{Version: version})
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch chart %s: %w", airbyteChartName, err)
+	}
+
+	return c, nil
+}
+
+// ExplainValue returns the documentation of the key within the chart.
+// If the key is not defined in the chart itself, the subchart whose name matches the first part
+// of the key will be searched.
+// Returns ErrValueNotFound if the key cannot be found.
+func ExplainValue(c *chart.Chart, key string) (ValueDoc, error) {
+	parts := strings.Split(key, ".")
+
+	val, ok := lookupValue(c.Values, parts)
+	if !ok {
+		if len(parts) > 1 {
+			for _, dep := range c.Dependencies() {
+				if dep.Name() != parts[0] {
+					continue
+				}
+				doc, err := ExplainValue(dep, strings.Join(parts[1:], "."))
+				if err != nil {
+					return ValueDoc{}, err
+				}
+				doc.Key = key
+				return doc, nil
+			}
+		}
+		return ValueDoc{}, fmt.Errorf("%w: %s", ErrValueNotFound, key)
+	}
+
+	doc := ValueDoc{
+		Key:   key,
+		Chart: c.Name(),
+		Type:  valueType(val),
+	}
+
+	if raw, err := yaml.Marshal(val); err == nil {
+		doc.Default = strings.TrimSpace(string(raw))
+	}
+
+	// prefer the schema for the type and description, falling back to the values file comments
+	if schema := schemaProperty(c.Schema, parts); schema != nil {
+		if t, ok := schema["type"].(string); ok {
+			doc.Type = t
+		}
+		if d, ok := schema["description"].(string); ok {
+			doc.Description = d
+		}
+	}
+
+	if doc.Description == "" {
+		doc.Description = valueComment(c, parts)
+	}
+
+	return doc, nil
+}
+
+// ValueKeys returns the dotted keys of all the values within the chart (but not its subcharts), sorted.
+func ValueKeys(c *chart.Chart) []string {
+	var keys []string
+
+	var walk func(prefix string, values map[string]interface{})
+	walk = func(prefix string, values map[string]interface{}) {
+		for k, v := range values {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			keys = append(keys, key)
+			if nested, ok := v.(map[string]interface{}); ok {
+				walk(key, nested)
+			}
+		}
+	}
+	walk("", c.Values)
+
+	sort.Strings(keys)
+	return keys
+}
+
+// lookupValue walks the values map following the parts, returning the value if it exists.
+func lookupValue(values map[string]interface{}, parts []string) (interface{}, bool) {
+	var cur interface{} = values
+	for _, part := range parts {
+		m, ok := asMap(cur)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+
+	return cur, true
+}
+
+// asMap converts v to a map, if possible.
+// Chart values may either be a map[string]interface{} or chartutil.Values depending on how they were loaded.
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case chartutil.Values:
+		return m, true
+	default:
+		return nil, false
+	}
+}
+
+// valueType returns the json schema type name of the value.
+func valueType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case int, int64, float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		if _, ok := asMap(v); ok {
+			return "object"
+		}
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// schemaProperty returns the json schema definition of the value located at parts, or nil if none exists.
+func schemaProperty(schema []byte, parts []string) map[string]interface{} {
+	if len(schema) == 0 {
+		return nil
+	}
+
+	var cur map[string]interface{}
+	if err := json.Unmarshal(schema, &cur); err != nil {
+		return nil
+	}
+
+	for _, part := range parts {
+		props, ok := cur["properties"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if cur, ok = props[part].(map[string]interface{}); !ok {
+			return nil
+		}
+	}
+
+	return cur
+}
+
+// valueComment returns the comment directly above the value located at parts within the values.yaml file.
+// Comments following the helm-docs convention ("# -- description") are supported.
+func valueComment(c *chart.Chart, parts []string) string {
+	var raw []byte
+	for _, f := range c.Raw {
+		if f.Name == chartutil.ValuesfileName {
+			raw = f.Data
+			break
+		}
+	}
+	if raw == nil {
+		return ""
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil || len(doc.Content) == 0 {
+		return ""
+	}
+
+	node := doc.Content[0]
+	var keyNode *yaml.Node
+	for _, part := range parts {
+		if node.Kind != yaml.MappingNode {
+			return ""
+		}
+		found := false
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == part {
+				keyNode, node = node.Content[i], node.Content[i+1]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ""
+		}
+	}
+
+	if keyNode == nil {
+		return ""
+	}
+
+	var lines []string
+	for _, line := range strings.Split(keyNode.HeadComment, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#"))
+		line = strings.TrimSpace(strings.TrimPrefix(line, "--"))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// managedValuesSchema is the json schema of the airbyte chart values abctl manages, which are validated in addition to
+// the values.schema.json of the chart, as the chart doesn't define a schema for most of them.
+// The edition is not restricted to the editions abctl knows of, as charts may support others.
+const managedValuesSchema = `{
+  "type": "object",
+  "properties": {
+    "global": {
+      "type": "object",
+      "properties": {
+        "edition": {"type": "string"},
+        "env_vars": {"type": "object", "additionalProperties": {"type": ["string", "number", "boolean"]}},
+        "image": {"type": "object", "properties": {"registry": {"type": "string"}}},
+        "metrics": {
+          "type": "object",
+          "properties": {
+            "metricClient": {"type": "string"},
+            "otelCollectorEndpoint": {"type": "string"}
+          }
+        }
+      }
+    },
+    "webapp": {
+      "type": "object",
+      "properties": {
+        "service": {
+          "type": "object",
+          "properties": {
+            "type": {"type": "string", "enum": ["ClusterIP", "NodePort", "LoadBalancer"]}
+          }
+        }
+      }
+    }
+  }
+}`
+
+// mergedValues returns the values (in the --set syntax of helm) merged over the valuesYAML, the same as helm merges
+// the values a chart is installed with.
+func mergedValues(valuesYAML string, values []string) (map[string]interface{}, error) {
+	spec := helmclient.ChartSpec{ValuesYaml: valuesYAML, ValuesOptions: helmvalues.Options{Values: values}}
+	return spec.GetValuesMap(nil)
+}
+
+// ValidateValues validates the values the chart would be installed with, the valuesYAML and then the values (in the
+// --set syntax of helm) merged over the defaults of the chart, the same as helm merges them.
+// The merged values are validated against the values.schema.json of the chart and its subcharts, if any, and against
+// the managed schema, if not empty. The error lists the path of every invalid value, e.g. global.edition.
+func ValidateValues(c *chart.Chart, valuesYAML string, values []string, managed []byte) error {
+	merged, err := mergedValues(valuesYAML, values)
+	if err != nil {
+		return fmt.Errorf("could not parse values of chart %s: %w", c.Name(), err)
+	}
+
+	coalesced, err := chartutil.CoalesceValues(c, merged)
+	if err != nil {
+		return fmt.Errorf("could not merge values of chart %s: %w", c.Name(), err)
+	}
+
+	// the schemas of the subcharts are validated against their values, which helm expects to be maps
+	if err := validateSubchartValues(c, coalesced, ""); err != nil {
+		return fmt.Errorf("invalid values of chart %s: %w", c.Name(), err)
+	}
+
+	var problems []string
+	if err := chartutil.ValidateAgainstSchema(c, coalesced); err != nil {
+		problems = append(problems, strings.TrimSpace(err.Error()))
+	}
+	if len(managed) > 0 {
+		if err := chartutil.ValidateAgainstSingleSchema(coalesced, managed); err != nil {
+			problems = append(problems, "abctl:\n"+strings.TrimSpace(err.Error()))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid values of chart %s:\n%s", c.Name(), strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
+
+// validateSubchartValues returns an error if the values of any subchart of the chart, or of their subcharts, are not a
+// map, as helm requires to validate them against the schema of the subchart. The prefix is the path of the values of
+// the chart, empty for the chart being installed.
+func validateSubchartValues(c *chart.Chart, values map[string]interface{}, prefix string) error {
+	for _, sub := range c.Dependencies() {
+		key := sub.Name()
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		subValues, ok := values[sub.Name()].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s must be a map of the values of the %s subchart, got %T", key, sub.Name(), values[sub.Name()])
+		}
+		if err := validateSubchartValues(sub, subValues, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package local
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"helm.sh/helm/v3/pkg/chart"
+	"strings"
+	"testing"
+)
+
+const testValuesYAML = `global:
+  # -- The edition of Airbyte to install
+  edition: community
+  env_vars: {}
+# Number of replicas
+# for the server deployment
+replicas: 1
+`
+
+const testSchema = `{
+  "properties": {
+    "global": {
+      "properties": {
+        "edition": {
+          "type": "string",
+          "description": "Either community or enterprise"
+        }
+      }
+    }
+  }
+}`
+
+func testChart(schema string) *chart.Chart {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "airbyte", Version: "1.0.0"},
+		Values: map[string]interface{}{
+			"global": map[string]interface{}{
+				"edition":  "community",
+				"env_vars": map[string]interface{}{},
+			},
+			"replicas": float64(1),
+		},
+		Raw:    []*chart.File{{Name: "values.yaml", Data: []byte(testValuesYAML)}},
+		Schema: []byte(schema),
+	}
+
+	c.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "webapp", Version: "1.0.0"},
+		Values:   map[string]interface{}{"enabled": true},
+		Raw:      []*chart.File{{Name: "values.yaml", Data: []byte("# -- Enable the webapp\nenabled: true\n")}},
+	})
+
+	return c
+}
+
+func TestValidateSubchartValues(t *testing.T) {
+	c := testChart(testSchema)
+	if err := validateSubchartValues(c, map[string]interface{}{"webapp": map[string]interface{}{}}, ""); err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	for _, values := range []map[string]interface{}{{}, {"webapp": "enabled"}, {"webapp": nil}} {
+		err := validateSubchartValues(c, values, "")
+		if err == nil || !strings.Contains(err.Error(), "webapp must be a map of the values of the webapp subchart") {
+			t.Errorf("expected an error for the values %v, got %v", values, err)
+		}
+	}
+}
+
+func TestExplainValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		key    string
+		exp    ValueDoc
+	}{
+		{
+			name: "helm-docs comment",
+			key:  "global.edition",
+			exp:  ValueDoc{Key: "global.edition", Chart: "airbyte", Type: "string", Default: "community", Description: "The edition of Airbyte to install"},
+		},
+		{
+			name: "multiline comment",
+			key:  "replicas",
+			exp:  ValueDoc{Key: "replicas", Chart: "airbyte", Type: "number", Default: "1", Description: "Number of replicas\nfor the server deployment"},
+		},
+		{
+			name: "object without comment",
+			key:  "global.env_vars",
+			exp:  ValueDoc{Key: "global.env_vars", Chart: "airbyte", Type: "object", Default: "{}"},
+		},
+		{
+			name:   "schema",
+			schema: testSchema,
+			key:    "global.edition",
+			exp:    ValueDoc{Key: "global.edition", Chart: "airbyte", Type: "string", Default: "community", Description: "Either community or enterprise"},
+		},
+		{
+			name: "subchart",
+			key:  "webapp.enabled",
+			exp:  ValueDoc{Key: "webapp.enabled", Chart: "webapp", Type: "boolean", Default: "true", Description: "Enable the webapp"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ExplainValue(testChart(tt.schema), tt.key)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, doc); d != "" {
+				t.Error("doc mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestExplainValue_NotFound(t *testing.T) {
+	for _, key := range []string{"dne", "global.dne", "global.edition.dne", "webapp.dne"} {
+		if _, err := ExplainValue(testChart(""), key); !errors.Is(err, ErrValueNotFound) {
+			t.Errorf("expected ErrValueNotFound for %s, received %v", key, err)
+		}
+	}
+}
+
+func TestValueKeys(t *testing.T) {
+	exp := []string{"global", "global.edition", "global.env_vars", "replicas"}
+	if d := cmp.Diff(exp, ValueKeys(testChart(""))); d != "" {
+		t.Error("keys mismatch (-want +got):", d)
+	}
+}
+
+func TestValidateValues(t *testing.T) {
+	tests := []struct {
+		name       string
+		valuesYAML string
+		values     []string
+		expErr     string
+	}{
+		{name: "defaults"},
+		{
+			name:       "valid",
+			valuesYAML: "global:\n  edition: enterprise\nwebapp:\n  service:\n    type: NodePort\n",
+			values:     []string{"global.env_vars.AIRBYTE_INSTALLATION_ID=test", "global.env_vars.REPLICAS=2"},
+		},
+		{
+			name:   "chart schema",
+			values: []string{"global.edition=1"},
+			expErr: "airbyte:\n- global.edition: Invalid type. Expected: string, given: integer",
+		},
+		{
+			name:       "managed schema",
+			valuesYAML: "webapp:\n  service:\n    type: Ingress\n",
+			expErr:     "abctl:\n- webapp.service.type: webapp.service.type must be one of the following",
+		},
+		{
+			name:       "values override the values file",
+			valuesYAML: "global:\n  edition: test\n",
+			values:     []string{"global.edition=community"},
+		},
+		{
+			name:       "unknown edition",
+			valuesYAML: "global:\n  edition: test\n",
+		},
+		{
+			name:       "subchart values not a map",
+			valuesYAML: "webapp: 5\n",
+			expErr:     "could not merge values of chart airbyte",
+		},
+		{
+			name:       "invalid yaml",
+			valuesYAML: "global: [",
+			expErr:     "could not parse values of chart airbyte",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateValues(testChart(testSchema), tt.valuesYAML, tt.values, []byte(managedValuesSchema))
+			if tt.expErr == "" {
+				if err != nil {
+					t.Error("unexpected error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expErr) {
+				t.Errorf("expected error containing %q, got %v", tt.expErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/airbytehq/abctl/internal/redact"
+	helmvalues "github.com/mittwald/go-helm-client/values"
+	"github.com/pterm/pterm"
+	"helm.sh/helm/v3/pkg/chart"
+	"sort"
+	"strings"
+)
+
+const (
+	// DiffNone displays no differences when a release is upgraded.
+	DiffNone = "none"
+	// DiffValues displays the differences between the values of the release and the values it is upgraded with.
+	DiffValues = "values"
+	// DiffAll displays the differences of DiffValues, and the differences between the default values of the chart
+	// versions, if the release is upgraded to another chart version.
+	DiffAll = "all"
+)
+
+// diffValues returns every difference between the current and the desired values, including the values which are
+// removed, ordered by field. Values are compared as json, as the values of a release are decoded from json.
+func diffValues(prefix string, current, desired map[string]interface{}) []Change {
+	keys := make([]string, 0, len(current)+len(desired))
+	for k := range current {
+		keys = append(keys, k)
+	}
+	for k := range desired {
+		if _, ok := current[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []Change
+	for _, key := range keys {
+		field := key
+		if prefix != "" {
+			field = prefix + "." + key
+		}
+
+		currentValue, currentOK := current[key]
+		desiredValue, desiredOK := desired[key]
+		currentMap, currentMapOK := asMap(currentValue)
+		desiredMap, desiredMapOK := asMap(desiredValue)
+		if currentMapOK && desiredMapOK {
+			changes = append(changes, diffValues(field, currentMap, desiredMap)...)
+			continue
+		}
+
+		var currentJSON, desiredJSON []byte
+		if currentOK {
+			currentJSON, _ = json.Marshal(currentValue)
+		}
+		if desiredOK {
+			desiredJSON, _ = json.Marshal(desiredValue)
+		}
+		if !bytes.Equal(currentJSON, desiredJSON) {
+			changes = append(changes, Change{Field: field, Current: string(currentJSON), Desired: string(desiredJSON)})
+		}
+	}
+	return changes
+}
+
+// redactChange returns the change with its values redacted, entirely if any part of its field is sensitive.
+func redactChange(change Change) Change {
+	for _, part := range strings.Split(change.Field, ".") {
+		if !redact.Key(part) {
+			continue
+		}
+		if change.Current != "" {
+			change.Current = redact.Redacted
+		}
+		if change.Desired != "" {
+			change.Desired = redact.Redacted
+		}
+		return change
+	}
+
+	change.Current = redact.String(change.Current)
+	change.Desired = redact.String(change.Desired)
+	return change
+}
+
+// formatChanges returns the changes as lines of added (+), removed (-), and changed (~) fields, redacted.
+func formatChanges(changes []Change) string {
+	lines := make([]string, len(changes))
+	for i, change := range changes {
+		change = redactChange(change)
+		switch {
+		case change.Current == "":
+			lines[i] = fmt.Sprintf("  + %s: %s", change.Field, change.Desired)
+		case change.Desired == "":
+			lines[i] = fmt.Sprintf("  - %s: %s", change.Field, change.Current)
+		default:
+			lines[i] = fmt.Sprintf("  ~ %s: %s -> %s", change.Field, change.Current, change.Desired)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// printValuesDiff displays the differences between the existing release of the req and its upgrade to the helmChart,
+// before the release is upgraded. Nothing is displayed for a release which is installed for the first time.
+func (c *Command) printValuesDiff(req chartRequest, helmChart *chart.Chart) {
+	if c.valuesDiff == DiffNone {
+		return
+	}
+
+	rel, err := c.releaseHelm(req.chartRelease).GetRelease(req.chartRelease)
+	if err != nil || rel == nil {
+		return
+	}
+
+	desired, err := mergedValues(req.valuesYAML, req.values)
+	if err != nil {
+		logging.Debugf("could not merge values of %s: %s", req.chartRelease, err)
+		return
+	}
+	if req.reuseValues {
+		desired = helmvalues.MergeMaps(rel.Config, desired)
+	}
+
+	if changes := diffValues("", rel.Config, desired); len(changes) > 0 {
+		pterm.Info.Printfln("Changing the values of the Helm release '%s':\n%s", req.chartRelease, formatChanges(changes))
+	}
+
+	if c.valuesDiff != DiffAll || rel.Chart == nil || rel.Chart.Metadata == nil {
+		return
+	}
+	from, to := rel.Chart.Metadata.Version, helmChart.Metadata.Version
+	if from == to {
+		return
+	}
+	if changes := diffValues("", rel.Chart.Values, helmChart.Values); len(changes) > 0 {
+		pterm.Info.Printfln("Changing the default values of the '%s' Helm Chart from version %s to %s:\n%s",
+			req.chartName, from, to, formatChanges(changes))
+	}
+}
@@ -0,0 +1,115 @@
+package local
+
+import (
+	"bytes"
+	"github.com/airbytehq/abctl/internal/redact"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pterm/pterm"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDiffValues(t *testing.T) {
+	current := map[string]interface{}{
+		"global": map[string]interface{}{
+			"edition":  "community",
+			"env_vars": map[string]interface{}{"A": "1", "REMOVED": "x"},
+		},
+		// release values are decoded from json
+		"replicas": float64(1),
+		"webapp":   map[string]interface{}{"enabled": true},
+	}
+	desired := map[string]interface{}{
+		"global": map[string]interface{}{
+			"edition":  "enterprise",
+			"env_vars": map[string]interface{}{"A": "1", "ADDED": "y"},
+		},
+		"replicas": int64(1),
+		"webapp":   true,
+	}
+
+	exp := []Change{
+		{Field: "global.edition", Current: `"community"`, Desired: `"enterprise"`},
+		{Field: "global.env_vars.ADDED", Desired: `"y"`},
+		{Field: "global.env_vars.REMOVED", Current: `"x"`},
+		{Field: "webapp", Current: `{"enabled":true}`, Desired: "true"},
+	}
+	if d := cmp.Diff(exp, diffValues("", current, desired)); d != "" {
+		t.Error("changes mismatch (-want +got):", d)
+	}
+
+	if changes := diffValues("", current, current); len(changes) != 0 {
+		t.Error("expected no changes", changes)
+	}
+}
+
+func TestFormatChanges(t *testing.T) {
+	changes := []Change{
+		{Field: "global.edition", Current: `"community"`, Desired: `"enterprise"`},
+		{Field: "global.auth.instanceAdmin.password", Current: `"old"`, Desired: `"new"`},
+		{Field: "global.database.secretName", Desired: `"db-secrets"`},
+		{Field: "global.database.host", Current: `"postgres://user:pass@db:5432/airbyte"`},
+	}
+
+	exp := `  ~ global.edition: "community" -> "enterprise"
+  ~ global.auth.instanceAdmin.password: ` + redact.Redacted + ` -> ` + redact.Redacted + `
+  + global.database.secretName: ` + redact.Redacted + `
+  - global.database.host: "postgres://user:` + redact.Redacted + `@db:5432/airbyte"`
+	if d := cmp.Diff(exp, formatChanges(changes)); d != "" {
+		t.Error("format mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_printValuesDiff(t *testing.T) {
+	b := bytes.NewBufferString("")
+	pterm.SetDefaultOutput(b)
+	t.Cleanup(func() {
+		pterm.SetDefaultOutput(os.Stdout)
+	})
+
+	helm := mockHelmClient{
+		getRelease: func(name string) (*release.Release, error) {
+			return &release.Release{
+				Config: map[string]interface{}{"global": map[string]interface{}{"edition": "community"}},
+				Chart: &chart.Chart{
+					Metadata: &chart.Metadata{Version: "1.0.0"},
+					Values:   map[string]interface{}{"replicas": float64(1)},
+				},
+			}, nil
+		},
+	}
+	req := chartRequest{chartName: airbyteChartName, chartRelease: airbyteChartRelease, valuesYAML: "global:\n  edition: enterprise\n"}
+	upgrade := &chart.Chart{Metadata: &chart.Metadata{Version: "1.1.0"}, Values: map[string]interface{}{"replicas": float64(2)}}
+
+	tests := []struct {
+		diff string
+		exp  []string
+		nexp []string
+	}{
+		{diff: DiffNone, nexp: []string{"global.edition", "replicas"}},
+		{diff: DiffValues, exp: []string{`~ global.edition: "community" -> "enterprise"`}, nexp: []string{"replicas"}},
+		{diff: DiffAll, exp: []string{`~ global.edition: "community" -> "enterprise"`, "from version 1.0.0 to 1.1.0", "~ replicas: 1 -> 2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.diff, func(t *testing.T) {
+			b.Reset()
+			c := &Command{helm: &helm, valuesDiff: tt.diff}
+			c.printValuesDiff(req, upgrade)
+
+			for _, s := range tt.exp {
+				if !strings.Contains(b.String(), s) {
+					t.Errorf("expected %q to be displayed, got %s", s, b.String())
+				}
+			}
+			for _, s := range tt.nexp {
+				if strings.Contains(b.String(), s) {
+					t.Errorf("expected %q not to be displayed, got %s", s, b.String())
+				}
+			}
+		})
+	}
+}
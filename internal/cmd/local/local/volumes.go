@@ -0,0 +1,72 @@
+package local
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"strings"
+)
+
+// volumeComponents are the components of the airbyte chart supporting extraVolumes and extraVolumeMounts values.
+// The pods of the connector jobs are launched by the workload-launcher (or the worker of older charts), and not by the
+// chart, so they cannot be mounted into.
+var volumeComponents = []string{
+	"connector-builder-server",
+	"cron",
+	"server",
+	"temporal",
+	"webapp",
+	"worker",
+	"workload-launcher",
+}
+
+// ValidateVolumeMounts returns an error if any of the mounts is mounted into an unsupported component.
+func ValidateVolumeMounts(mounts []k8s.VolumeMount) error {
+	for _, m := range mounts {
+		supported := false
+		for _, c := range volumeComponents {
+			supported = supported || m.Component == c
+		}
+		if !supported {
+			return fmt.Errorf("invalid volume mount component %s, must be one of %s", m.Component, strings.Join(volumeComponents, ", "))
+		}
+	}
+	return nil
+}
+
+// volumeMountValues returns the airbyte chart values mounting the volumes into their components.
+// The volumes are named abctl-volume-0, abctl-volume-1, etc. in the order of the mounts.
+func volumeMountValues(mounts []k8s.VolumeMount) []string {
+	var values []string
+	// the index of the next volume of each component
+	next := map[string]int{}
+	for i, m := range mounts {
+		j := next[m.Component]
+		next[m.Component]++
+
+		name := fmt.Sprintf("abctl-volume-%d", i)
+		volume := fmt.Sprintf("%s.extraVolumes[%d]", m.Component, j)
+		mount := fmt.Sprintf("%s.extraVolumeMounts[%d]", m.Component, j)
+
+		values = append(values, fmt.Sprintf("%s.name=%s", volume, name))
+		switch m.Type {
+		case k8s.VolumeSecret:
+			values = append(values, fmt.Sprintf("%s.secret.secretName=%s", volume, m.Source))
+		case k8s.VolumeConfigMap:
+			values = append(values, fmt.Sprintf("%s.configMap.name=%s", volume, m.Source))
+		case k8s.VolumeHostPath:
+			values = append(values, fmt.Sprintf("%s.hostPath.path=%s", volume, m.Source))
+		case k8s.VolumeEmptyDir:
+			// an empty medium is the default medium of the node, which also makes emptyDir an object
+			values = append(values, fmt.Sprintf("%s.emptyDir.medium=", volume))
+		}
+
+		values = append(values,
+			fmt.Sprintf("%s.name=%s", mount, name),
+			fmt.Sprintf("%s.mountPath=%s", mount, m.MountPath),
+		)
+		if m.ReadOnly {
+			values = append(values, fmt.Sprintf("%s.readOnly=true", mount))
+		}
+	}
+	return values
+}
@@ -0,0 +1,60 @@
+package local
+
+import (
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+func TestValidateVolumeMounts(t *testing.T) {
+	if err := ValidateVolumeMounts([]k8s.VolumeMount{{Component: "worker"}, {Component: "workload-launcher"}}); err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	err := ValidateVolumeMounts([]k8s.VolumeMount{{Component: "worker"}, {Component: "jobs"}})
+	if err == nil || !strings.Contains(err.Error(), "invalid volume mount component jobs") {
+		t.Error("expected an invalid component error, got", err)
+	}
+}
+
+func TestVolumeMountValues(t *testing.T) {
+	mounts := []k8s.VolumeMount{
+		{Component: "worker", Type: k8s.VolumeSecret, Source: "ca-bundle", MountPath: "/etc/ssl/custom", ReadOnly: true},
+		{Component: "server", Type: k8s.VolumeConfigMap, Source: "settings", MountPath: "/settings"},
+		{Component: "worker", Type: k8s.VolumeEmptyDir, MountPath: "/scratch"},
+		{Component: "worker", Type: k8s.VolumeHostPath, Source: "/datasets", MountPath: "/datasets"},
+	}
+
+	// the values are set as helm --set values, so they are compared once merged
+	values, err := mergedValues("", volumeMountValues(mounts))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := map[string]interface{}{
+		"server": map[string]interface{}{
+			"extraVolumes": []interface{}{
+				map[string]interface{}{"name": "abctl-volume-1", "configMap": map[string]interface{}{"name": "settings"}},
+			},
+			"extraVolumeMounts": []interface{}{
+				map[string]interface{}{"name": "abctl-volume-1", "mountPath": "/settings"},
+			},
+		},
+		"worker": map[string]interface{}{
+			"extraVolumes": []interface{}{
+				map[string]interface{}{"name": "abctl-volume-0", "secret": map[string]interface{}{"secretName": "ca-bundle"}},
+				map[string]interface{}{"name": "abctl-volume-2", "emptyDir": map[string]interface{}{"medium": ""}},
+				map[string]interface{}{"name": "abctl-volume-3", "hostPath": map[string]interface{}{"path": "/datasets"}},
+			},
+			"extraVolumeMounts": []interface{}{
+				map[string]interface{}{"name": "abctl-volume-0", "mountPath": "/etc/ssl/custom", "readOnly": true},
+				map[string]interface{}{"name": "abctl-volume-2", "mountPath": "/scratch"},
+				map[string]interface{}{"name": "abctl-volume-3", "mountPath": "/datasets"},
+			},
+		},
+	}
+	if d := cmp.Diff(exp, values); d != "" {
+		t.Error("values mismatch (-want +got):", d)
+	}
+}
@@ -0,0 +1,129 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/progress"
+	"github.com/airbytehq/abctl/internal/cmd/local/readiness"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/airbytehq/abctl/internal/trace"
+	"github.com/pterm/pterm"
+	"strings"
+	"time"
+)
+
+// DefaultReadyTimeout is how long Install waits for every component of Airbyte to become ready, unless another
+// timeout is provided.
+const DefaultReadyTimeout = 5 * time.Minute
+
+// readinessInterval is how long to wait between each attempt of the readiness checks.
+var readinessInterval = 2 * time.Second
+
+// WaitOpts configures what Wait considers to be ready.
+type WaitOpts struct {
+	// Host is the ingress host used to verify the ingress and api, defaults to the host of the Command.
+	Host string
+	// User and Pass are the basic-auth credentials, the api health is only checked if User is provided.
+	User string
+	Pass string
+	// Timeout is how long to wait for Airbyte to become ready.
+	Timeout time.Duration
+}
+
+// The components of Airbyte which are verified individually, with the prefix of the names of their pods.
+const (
+	componentServer   = "server"
+	componentTemporal = "temporal"
+	componentWebapp   = "webapp"
+)
+
+// componentPrefix returns the prefix of the names of the pods of the component.
+func componentPrefix(component string) string {
+	return fmt.Sprintf("%s-%s", airbyteChartRelease, component)
+}
+
+// componentChecks returns a check for each component of Airbyte, so the component which is not ready is reported.
+// The server is verified by the api health if a user is provided, as the api is protected by basic-auth, and the
+// webapp by the ingress. Otherwise, or without an ingress controller of abctl, only their pods are checked.
+func (c *Command) componentChecks(host, user, pass string) []readiness.Check {
+	server := readiness.Component(c.k8s, c.namespace, componentServer, componentPrefix(componentServer))
+	temporal := readiness.Component(c.k8s, c.namespace, componentTemporal, componentPrefix(componentTemporal))
+	webapp := readiness.Component(c.k8s, c.namespace, componentWebapp, componentPrefix(componentWebapp))
+	if c.ingressController == IngressNone {
+		return []readiness.Check{server, temporal, webapp}
+	}
+
+	baseURL := baseURL(host, c.portHTTP)
+	if user != "" {
+		server = readiness.New(componentServer, readiness.Health(c.http, baseURL, user, pass).Ready)
+	}
+	webapp = readiness.New(componentWebapp, readiness.Ingress(c.http, baseURL).Ready)
+	return []readiness.Check{server, temporal, webapp}
+}
+
+// readinessChecks returns the checks which verify the local Airbyte installation is ready.
+// The api health is only checked if a user is provided, as the api is protected by basic-auth.
+func (c *Command) readinessChecks(host, user, pass string) []readiness.Check {
+	if host == "" {
+		host = c.host
+	}
+
+	// without an ingress controller of abctl, Airbyte is not served on the port, so only its pods are checked
+	if c.ingressController == IngressNone {
+		return append([]readiness.Check{readiness.Pods(c.k8s, c.namespace)}, c.componentChecks(host, user, pass)...)
+	}
+
+	_, controllerNamespace := ingressControllerRelease(c.ingressController)
+	checks := []readiness.Check{
+		readiness.Pods(c.k8s, controllerNamespace),
+		readiness.Pods(c.k8s, c.namespace),
+	}
+	checks = append(checks, c.componentChecks(host, user, pass)...)
+	if c.apiPort != 0 {
+		checks = append(checks, c.apiPortCheck(host))
+	}
+
+	return checks
+}
+
+// Wait waits until every component of the local Airbyte installation is ready, or the timeout is reached.
+func (c *Command) Wait(ctx context.Context, opts WaitOpts) (err error) {
+	ctx, span := trace.NewSpan(ctx, "wait")
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	return c.waitReady(ctx, opts.Timeout, c.readinessChecks(opts.Host, opts.User, opts.Pass))
+}
+
+// waitReady waits until every check is ready, or the timeout is reached.
+// The checks which are not ready yet, and for how long, are reported by the spinner.
+func (c *Command) waitReady(ctx context.Context, timeout time.Duration, checks []readiness.Check) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	report := progress.Spinner(c.spinner, "Waiting for Airbyte to become ready")
+	defer report("")
+
+	err := readiness.WaitReport(ctx, readinessInterval, func(notReady []readiness.Status) {
+		report(notReadyStatus(notReady))
+	}, checks...)
+	if err != nil {
+		pterm.Error.Printfln("Airbyte was not ready within %s", timeout)
+		return fmt.Errorf("airbyte was not ready: %w", err)
+	}
+
+	pterm.Success.Println("Airbyte is ready")
+	return nil
+}
+
+// notReadyStatus describes the checks which are not ready, e.g. "temporal not ready for 1m20s".
+func notReadyStatus(notReady []readiness.Status) string {
+	status := make([]string, len(notReady))
+	for i, s := range notReady {
+		logging.Debugf("%s not ready for %s: %s", s.Name, s.Since, s.Err)
+		status[i] = fmt.Sprintf("%s not ready for %s", s.Name, s.Since)
+	}
+	return strings.Join(status, ", ")
+}
@@ -0,0 +1,183 @@
+package local
+
+import (
+	"context"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/readiness"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	coreV1 "k8s.io/api/core/v1"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommand_Wait(t *testing.T) {
+	readinessInterval = time.Millisecond
+	t.Cleanup(func() {
+		readinessInterval = 2 * time.Second
+	})
+
+	var (
+		podLists   int
+		namespaces = map[string]bool{}
+		requested  = map[string]bool{}
+	)
+
+	k8sClient := mockK8sClient{podList: func(ctx context.Context, namespace string) (*coreV1.PodList, error) {
+		podLists++
+		namespaces[namespace] = true
+		phase := coreV1.PodRunning
+		// the first attempt for each namespace is not ready
+		if podLists <= 2 {
+			phase = coreV1.PodPending
+		}
+		pods, _ := readyPods(ctx, namespace)
+		for i := range pods.Items {
+			pods.Items[i].Status.Phase = phase
+		}
+		return pods, nil
+	}}
+
+	httpClient := mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+		requested[req.URL.String()] = true
+		if req.URL.Path == "/api/v1/health" {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"available": true}`))}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithHTTPClient(&httpClient),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Wait(context.Background(), WaitOpts{Host: "example.com", User: "user", Pass: "pass", Timeout: time.Second}); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if d := cmp.Diff(map[string]bool{airbyteNamespace: true, nginxNamespace: true}, namespaces); d != "" {
+		t.Error("namespaces mismatch (-want +got):", d)
+	}
+
+	var urls []string
+	for url := range requested {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	exp := []string{"http://example.com:9999", "http://example.com:9999/api/v1/health"}
+	if d := cmp.Diff(exp, urls); d != "" {
+		t.Error("urls mismatch (-want +got):", d)
+	}
+}
+
+func TestCommand_WaitTimeout(t *testing.T) {
+	readinessInterval = time.Millisecond
+	t.Cleanup(func() {
+		readinessInterval = 2 * time.Second
+	})
+
+	httpClient := mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusBadGateway, Body: http.NoBody}, nil
+	}}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&mockK8sClient{}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithHTTPClient(&httpClient),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Wait(context.Background(), WaitOpts{Timeout: 20 * time.Millisecond}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestCommand_ComponentChecks(t *testing.T) {
+	tests := []struct {
+		name       string
+		controller string
+		user       string
+		requested  []string
+	}{
+		{
+			name:       "ingress with credentials",
+			controller: IngressNginx,
+			user:       "user",
+			requested:  []string{"http://example.com:9999", "http://example.com:9999/api/v1/health"},
+		},
+		{
+			name:       "ingress without credentials",
+			controller: IngressNginx,
+			requested:  []string{"http://example.com:9999"},
+		},
+		{
+			name:       "no ingress controller",
+			controller: IngressNone,
+			user:       "user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested []string
+			httpClient := mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+				requested = append(requested, req.URL.String())
+				return readyResponse(req)
+			}}
+
+			c, err := New(
+				k8s.TestProvider,
+				WithPortHTTP(portTest),
+				WithHelmClient(&mockHelmClient{}),
+				WithK8sClient(&mockK8sClient{podList: readyPods}),
+				WithTelemetryClient(&mockTelemetryClient{}),
+				WithHTTPClient(&httpClient),
+				WithIngressController(tt.controller),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, res := range readiness.Run(context.Background(), c.componentChecks("example.com", tt.user, "pass")...) {
+				if res.Err != nil {
+					t.Errorf("unexpected error for %s: %s", res.Name, res.Err)
+				}
+				names = append(names, res.Name)
+			}
+
+			if d := cmp.Diff([]string{componentServer, componentTemporal, componentWebapp}, names); d != "" {
+				t.Error("names mismatch (-want +got):", d)
+			}
+			sort.Strings(requested)
+			if d := cmp.Diff(tt.requested, requested); d != "" {
+				t.Error("requested mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestNotReadyStatus(t *testing.T) {
+	status := notReadyStatus([]readiness.Status{
+		{Name: componentTemporal, Since: 80 * time.Second},
+		{Name: componentWebapp, Since: 5 * time.Second},
+	})
+	if d := cmp.Diff("temporal not ready for 1m20s, webapp not ready for 5s", status); d != "" {
+		t.Error("status mismatch (-want +got):", d)
+	}
+}
@@ -0,0 +1,66 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"strings"
+)
+
+// CrashedDeployments returns the Airbyte deployments which have a crashed pod, keyed by the deployment name with the
+// reason the pod is considered crashed.
+//
+// A pod is considered crashed if any of its containers are in a CrashLoopBackOff, or if the pod has failed.
+// Pods which are not owned by a deployment (e.g. of jobs) are ignored, as they cannot be restarted.
+func (c *Command) CrashedDeployments(ctx context.Context) (map[string]string, error) {
+	pods, err := c.k8s.PodList(ctx, c.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("could not list pods: %w", err)
+	}
+
+	crashed := map[string]string{}
+	for _, pod := range pods.Items {
+		deployment := podDeployment(pod)
+		if deployment == "" {
+			continue
+		}
+		if reason := podCrashed(pod); reason != "" {
+			crashed[deployment] = fmt.Sprintf("pod %s %s", pod.Name, reason)
+		}
+	}
+
+	return crashed, nil
+}
+
+// RestartDeployment restarts the Airbyte deployment.
+func (c *Command) RestartDeployment(ctx context.Context, name string) error {
+	return c.k8s.DeploymentRestart(ctx, c.namespace, name)
+}
+
+// podDeployment returns the name of the deployment which owns the pod, or an empty string if the pod is not owned by
+// a deployment. The replica set of a deployment is named after the deployment, suffixed with the pod-template-hash.
+func podDeployment(pod corev1.Pod) string {
+	hash := pod.Labels["pod-template-hash"]
+	if hash == "" {
+		return ""
+	}
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "ReplicaSet" && strings.HasSuffix(owner.Name, "-"+hash) {
+			return strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+	}
+	return ""
+}
+
+// podCrashed returns why the pod is considered crashed, or an empty string if it has not crashed.
+func podCrashed(pod corev1.Pod) string {
+	if pod.Status.Phase == corev1.PodFailed {
+		return "failed"
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff" {
+			return fmt.Sprintf("container %s is in CrashLoopBackOff", status.Name)
+		}
+	}
+	return ""
+}
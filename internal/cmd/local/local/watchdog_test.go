@@ -0,0 +1,63 @@
+package local
+
+import (
+	"context"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestCommand_CrashedDeployments(t *testing.T) {
+	pod := func(name, replicaSet, hash string, status coreV1.PodStatus) coreV1.Pod {
+		p := coreV1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: status}
+		if replicaSet != "" {
+			p.Labels = map[string]string{"pod-template-hash": hash}
+			p.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: replicaSet}}
+		}
+		return p
+	}
+	crashLoop := coreV1.PodStatus{
+		Phase: coreV1.PodRunning,
+		ContainerStatuses: []coreV1.ContainerStatus{{
+			Name:  "main",
+			State: coreV1.ContainerState{Waiting: &coreV1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		}},
+	}
+
+	k8sClient := mockK8sClient{
+		podList: func(ctx context.Context, namespace string) (*coreV1.PodList, error) {
+			return &coreV1.PodList{Items: []coreV1.Pod{
+				pod("server-abc12-x1", "airbyte-abctl-server-abc12", "abc12", crashLoop),
+				pod("worker-def34-x1", "airbyte-abctl-worker-def34", "def34", coreV1.PodStatus{Phase: coreV1.PodFailed}),
+				pod("webapp-ghi56-x1", "airbyte-abctl-webapp-ghi56", "ghi56", coreV1.PodStatus{Phase: coreV1.PodRunning}),
+				// pods which are not owned by a deployment can't be restarted
+				pod("bootloader", "", "", coreV1.PodStatus{Phase: coreV1.PodFailed}),
+			}}, nil
+		},
+	}
+
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&mockTelemetryClient{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	crashed, err := c.CrashedDeployments(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := map[string]string{
+		"airbyte-abctl-server": "pod server-abc12-x1 container main is in CrashLoopBackOff",
+		"airbyte-abctl-worker": "pod worker-def34-x1 failed",
+	}
+	if d := cmp.Diff(exp, crashed); d != "" {
+		t.Error("crashed mismatch (-want +got):", d)
+	}
+}
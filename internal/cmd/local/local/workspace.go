@@ -0,0 +1,416 @@
+package local
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/build"
+	"github.com/pterm/pterm"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maskedSecret is the value the api returns instead of the value of every secret of a configuration.
+const maskedSecret = "**********"
+
+// WorkspaceResource is a source or destination of a workspace.
+type WorkspaceResource struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// DockerRepository identifies the definition, as the ids of custom definitions differ between instances.
+	DockerRepository string                 `json:"dockerRepository"`
+	Configuration    map[string]interface{} `json:"connectionConfiguration"`
+}
+
+// WorkspaceExport contains the sources, destinations, and connections of a workspace.
+// The values of the secrets of the configurations are masked, they must be provided again before importing.
+type WorkspaceExport struct {
+	Sources      []WorkspaceResource      `json:"sources"`
+	Destinations []WorkspaceResource      `json:"destinations"`
+	Connections  []map[string]interface{} `json:"connections"`
+}
+
+// workspaceManifest is the file of the archive describing the export.
+type workspaceManifest struct {
+	Version    string    `json:"abctlVersion"`
+	ExportedAt time.Time `json:"exportedAt"`
+}
+
+const workspaceManifestName = "workspace.json"
+
+// definitionRepositories returns the docker repository of every definition id of the kind available to the
+// workspace.
+func (a airbyteAPI) definitionRepositories(ctx context.Context, kind, workspaceID string) (map[string]string, error) {
+	var res map[string][]workspaceDefinition
+	if err := a.post(ctx, fmt.Sprintf("/api/v1/%s_definitions/list_for_workspace", kind), map[string]string{"workspaceId": workspaceID}, &res); err != nil {
+		return nil, fmt.Errorf("could not list %s definitions: %w", kind, err)
+	}
+
+	repos := map[string]string{}
+	for _, def := range res[kind+"Definitions"] {
+		id := def.SourceDefinitionID
+		if kind == ConnectorDestination {
+			id = def.DestinationDefinitionID
+		}
+		repos[id] = def.DockerRepository
+	}
+	return repos, nil
+}
+
+// resources returns the sources or destinations of the workspace.
+func (a airbyteAPI) resources(ctx context.Context, kind, workspaceID string) ([]WorkspaceResource, error) {
+	repos, err := a.definitionRepositories(ctx, kind, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var res map[string][]map[string]interface{}
+	if err := a.post(ctx, fmt.Sprintf("/api/v1/%ss/list", kind), map[string]string{"workspaceId": workspaceID}, &res); err != nil {
+		return nil, fmt.Errorf("could not list %ss: %w", kind, err)
+	}
+
+	var resources []WorkspaceResource
+	for _, r := range res[kind+"s"] {
+		id, _ := r[kind+"Id"].(string)
+		name, _ := r["name"].(string)
+		defID, _ := r[kind+"DefinitionId"].(string)
+		config, _ := r["connectionConfiguration"].(map[string]interface{})
+		resources = append(resources, WorkspaceResource{ID: id, Name: name, DockerRepository: repos[defID], Configuration: config})
+	}
+	return resources, nil
+}
+
+// ExportWorkspace returns the sources, destinations, and connections of the default workspace.
+func (c *Command) ExportWorkspace(ctx context.Context, opts APIOpts) (WorkspaceExport, error) {
+	var export WorkspaceExport
+
+	api, workspaceID, err := c.workspaceAPI(ctx, opts)
+	if err != nil {
+		return export, err
+	}
+
+	if export.Sources, err = api.resources(ctx, ConnectorSource, workspaceID); err != nil {
+		return export, err
+	}
+	if export.Destinations, err = api.resources(ctx, ConnectorDestination, workspaceID); err != nil {
+		return export, err
+	}
+
+	var res struct {
+		Connections []map[string]interface{} `json:"connections"`
+	}
+	if err := api.post(ctx, "/api/v1/connections/list", map[string]string{"workspaceId": workspaceID}, &res); err != nil {
+		return export, fmt.Errorf("could not list connections: %w", err)
+	}
+	export.Connections = res.Connections
+
+	return export, nil
+}
+
+// WriteWorkspace writes the export as a tar archive to w, with a json file of every source, destination, and
+// connection.
+func WriteWorkspace(w io.Writer, export WorkspaceExport) error {
+	tw := tar.NewWriter(w)
+	now := time.Now()
+
+	writeFile := func(name string, v interface{}) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("could not marshal %s: %w", name, err)
+		}
+		hdr := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("could not write header for %s: %w", name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("could not write %s: %w", name, err)
+		}
+		return nil
+	}
+
+	if err := writeFile(workspaceManifestName, workspaceManifest{Version: build.Version, ExportedAt: now.UTC()}); err != nil {
+		return err
+	}
+	for _, src := range export.Sources {
+		if err := writeFile(path.Join("sources", src.ID+".json"), src); err != nil {
+			return err
+		}
+	}
+	for _, dst := range export.Destinations {
+		if err := writeFile(path.Join("destinations", dst.ID+".json"), dst); err != nil {
+			return err
+		}
+	}
+	for _, conn := range export.Connections {
+		id, _ := conn["connectionId"].(string)
+		if err := writeFile(path.Join("connections", id+".json"), conn); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("could not close tar writer: %w", err)
+	}
+	return nil
+}
+
+// ReadWorkspace reads the export of the tar archive written by WriteWorkspace.
+func ReadWorkspace(r io.Reader) (WorkspaceExport, error) {
+	var (
+		export   WorkspaceExport
+		manifest bool
+	)
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return export, fmt.Errorf("could not read archive: %w", err)
+		}
+
+		dec := json.NewDecoder(tr)
+		switch dir := path.Dir(hdr.Name); {
+		case hdr.Name == workspaceManifestName:
+			manifest = true
+		case dir == "sources" || dir == "destinations":
+			var res WorkspaceResource
+			if err := dec.Decode(&res); err != nil {
+				return export, fmt.Errorf("could not decode %s: %w", hdr.Name, err)
+			}
+			if dir == "sources" {
+				export.Sources = append(export.Sources, res)
+			} else {
+				export.Destinations = append(export.Destinations, res)
+			}
+		case dir == "connections":
+			var conn map[string]interface{}
+			if err := dec.Decode(&conn); err != nil {
+				return export, fmt.Errorf("could not decode %s: %w", hdr.Name, err)
+			}
+			export.Connections = append(export.Connections, conn)
+		}
+	}
+
+	if !manifest {
+		return export, fmt.Errorf("not a workspace export, %s is missing", workspaceManifestName)
+	}
+	return export, nil
+}
+
+// WorkspaceSecret is a masked secret of the configuration of a source or destination, which must be provided again
+// before importing.
+type WorkspaceSecret struct {
+	// Kind is either ConnectorSource or ConnectorDestination.
+	Kind string
+	// Name is the name of the source or destination.
+	Name string
+	// Path is the dotted path of the secret within the configuration, e.g. credentials.password.
+	Path string
+}
+
+// MaskedSecrets returns every masked secret of the configurations of the export, in order.
+func (e WorkspaceExport) MaskedSecrets() []WorkspaceSecret {
+	var secrets []WorkspaceSecret
+	for _, kind := range []string{ConnectorSource, ConnectorDestination} {
+		for _, r := range e.resources(kind) {
+			for _, p := range maskedPaths(r.Configuration, "") {
+				secrets = append(secrets, WorkspaceSecret{Kind: kind, Name: r.Name, Path: p})
+			}
+		}
+	}
+	return secrets
+}
+
+// SetSecret replaces the masked value of the secret with the value.
+func (e WorkspaceExport) SetSecret(secret WorkspaceSecret, value string) error {
+	for _, r := range e.resources(secret.Kind) {
+		if r.Name == secret.Name {
+			return setPath(r.Configuration, strings.Split(secret.Path, "."), value)
+		}
+	}
+	return fmt.Errorf("%s %s does not exist", secret.Kind, secret.Name)
+}
+
+func (e WorkspaceExport) resources(kind string) []WorkspaceResource {
+	if kind == ConnectorDestination {
+		return e.Destinations
+	}
+	return e.Sources
+}
+
+// maskedPaths returns the dotted paths of every masked value within v, sorted.
+func maskedPaths(v interface{}, prefix string) []string {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	var paths []string
+	switch v := v.(type) {
+	case string:
+		if v == maskedSecret {
+			paths = append(paths, prefix)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			paths = append(paths, maskedPaths(v[k], join(k))...)
+		}
+	case []interface{}:
+		for i, item := range v {
+			paths = append(paths, maskedPaths(item, join(strconv.Itoa(i)))...)
+		}
+	}
+	return paths
+}
+
+// setPath sets the value at the path within v, which must exist.
+func setPath(v interface{}, p []string, value string) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if _, ok := v[p[0]]; !ok {
+			break
+		}
+		if len(p) == 1 {
+			v[p[0]] = value
+			return nil
+		}
+		return setPath(v[p[0]], p[1:], value)
+	case []interface{}:
+		i, err := strconv.Atoi(p[0])
+		if err != nil || i < 0 || i >= len(v) {
+			break
+		}
+		if len(p) == 1 {
+			v[i] = value
+			return nil
+		}
+		return setPath(v[i], p[1:], value)
+	}
+	return fmt.Errorf("invalid path %s", strings.Join(p, "."))
+}
+
+// connectionCreateFields are the fields of an exported connection which are used to create it again.
+var connectionCreateFields = []string{
+	"name", "namespaceDefinition", "namespaceFormat", "prefix", "syncCatalog", "scheduleType", "scheduleData",
+	"status", "nonBreakingChangesPreference", "notifySchemaChanges", "geography",
+}
+
+// ImportWorkspace creates the sources, destinations, and connections of the export in the default workspace.
+// Those with the name of an existing one are not created again, allowing an import to be repeated. Every masked
+// secret must have been replaced by SetSecret first.
+func (c *Command) ImportWorkspace(ctx context.Context, opts APIOpts, export WorkspaceExport) error {
+	if secrets := export.MaskedSecrets(); len(secrets) > 0 {
+		return fmt.Errorf("%d secrets must be provided, e.g. %s %s %s", len(secrets), secrets[0].Kind, secrets[0].Name, secrets[0].Path)
+	}
+
+	api, workspaceID, err := c.workspaceAPI(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	// ids maps the ids of the exported sources and destinations to their ids within the workspace
+	ids := map[string]string{}
+	for _, kind := range []string{ConnectorSource, ConnectorDestination} {
+		existing, err := api.resources(ctx, kind, workspaceID)
+		if err != nil {
+			return err
+		}
+		existingIDs := map[string]string{}
+		for _, r := range existing {
+			existingIDs[r.Name] = r.ID
+		}
+
+		repos, err := api.definitionRepositories(ctx, kind, workspaceID)
+		if err != nil {
+			return err
+		}
+		definitions := map[string]string{}
+		for id, repo := range repos {
+			definitions[repo] = id
+		}
+
+		for _, r := range export.resources(kind) {
+			if id, ok := existingIDs[r.Name]; ok {
+				pterm.Info.Printfln("The %s '%s' already exists", kind, r.Name)
+				ids[r.ID] = id
+				continue
+			}
+
+			defID, ok := definitions[r.DockerRepository]
+			if !ok {
+				return fmt.Errorf("could not create %s %s: the connector %s is not available", kind, r.Name, r.DockerRepository)
+			}
+
+			c.spinner.UpdateText(fmt.Sprintf("Creating the %s '%s'", kind, r.Name))
+			req := map[string]interface{}{
+				"workspaceId":             workspaceID,
+				kind + "DefinitionId":     defID,
+				"name":                    r.Name,
+				"connectionConfiguration": r.Configuration,
+			}
+			var res map[string]interface{}
+			if err := api.post(ctx, fmt.Sprintf("/api/v1/%ss/create", kind), req, &res); err != nil {
+				return fmt.Errorf("could not create %s %s: %w", kind, r.Name, err)
+			}
+			ids[r.ID], _ = res[kind+"Id"].(string)
+			pterm.Success.Printfln("Created the %s '%s'", kind, r.Name)
+		}
+	}
+
+	var existing struct {
+		Connections []struct {
+			Name string `json:"name"`
+		} `json:"connections"`
+	}
+	if err := api.post(ctx, "/api/v1/connections/list", map[string]string{"workspaceId": workspaceID}, &existing); err != nil {
+		return fmt.Errorf("could not list connections: %w", err)
+	}
+	existingNames := map[string]bool{}
+	for _, conn := range existing.Connections {
+		existingNames[conn.Name] = true
+	}
+
+	for _, conn := range export.Connections {
+		name, _ := conn["name"].(string)
+		if existingNames[name] {
+			pterm.Info.Printfln("The connection '%s' already exists", name)
+			continue
+		}
+
+		sourceID, _ := conn["sourceId"].(string)
+		destinationID, _ := conn["destinationId"].(string)
+		if ids[sourceID] == "" || ids[destinationID] == "" {
+			return fmt.Errorf("could not create connection %s: its source or destination was not exported", name)
+		}
+
+		c.spinner.UpdateText(fmt.Sprintf("Creating the connection '%s'", name))
+		req := map[string]interface{}{"sourceId": ids[sourceID], "destinationId": ids[destinationID]}
+		for _, field := range connectionCreateFields {
+			if v, ok := conn[field]; ok {
+				req[field] = v
+			}
+		}
+		if err := api.post(ctx, "/api/v1/connections/create", req, nil); err != nil {
+			return fmt.Errorf("could not create connection %s: %w", name, err)
+		}
+		pterm.Success.Printfln("Created the connection '%s'", name)
+	}
+
+	return nil
+}
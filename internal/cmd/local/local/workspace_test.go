@@ -0,0 +1,192 @@
+package local
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// workspaceHTTP returns the mockHTTP of the api of a workspace with the sources, destinations, and connections,
+// recording the body of every create request to created.
+func workspaceHTTP(t *testing.T, sources, destinations, connections string, created *[]string) *mockHTTP {
+	return &mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+		respond := func(body string) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+		}
+
+		switch req.URL.Path {
+		case "/api/v1/workspaces/list":
+			return respond(`{"workspaces": [{"workspaceId": "ws"}]}`)
+		case "/api/v1/source_definitions/list_for_workspace":
+			return respond(`{"sourceDefinitions": [{"sourceDefinitionId": "faker-def", "dockerRepository": "airbyte/source-faker"}]}`)
+		case "/api/v1/destination_definitions/list_for_workspace":
+			return respond(`{"destinationDefinitions": [{"destinationDefinitionId": "postgres-def", "dockerRepository": "airbyte/destination-postgres"}]}`)
+		case "/api/v1/sources/list":
+			return respond(`{"sources": [` + sources + `]}`)
+		case "/api/v1/destinations/list":
+			return respond(`{"destinations": [` + destinations + `]}`)
+		case "/api/v1/connections/list":
+			return respond(`{"connections": [` + connections + `]}`)
+		case "/api/v1/sources/create", "/api/v1/destinations/create", "/api/v1/connections/create":
+			body, _ := io.ReadAll(req.Body)
+			*created = append(*created, req.URL.Path+" "+string(body))
+			kind := strings.TrimSuffix(strings.Split(req.URL.Path, "/")[3], "s")
+			return respond(`{"` + kind + `Id": "new-` + kind + `"}`)
+		default:
+			t.Error("unexpected path", req.URL.Path)
+			return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody}, nil
+		}
+	}}
+}
+
+func workspaceCommand(t *testing.T, client *mockHTTP) *Command {
+	c, err := New(
+		k8s.TestProvider,
+		WithHelmClient(&mockHelmClient{}),
+		WithK8sClient(&mockK8sClient{}),
+		WithTelemetryClient(&mockTelemetryClient{}),
+		WithHTTPClient(client),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+const (
+	testWorkspaceSource      = `{"sourceId": "s1", "sourceDefinitionId": "faker-def", "name": "Faker", "connectionConfiguration": {"count": 10, "api_key": "**********"}}`
+	testWorkspaceDestination = `{"destinationId": "d1", "destinationDefinitionId": "postgres-def", "name": "Postgres", "connectionConfiguration": {"host": "db", "credentials": [{"password": "**********"}]}}`
+	testWorkspaceConnection  = `{"connectionId": "c1", "name": "Faker → Postgres", "sourceId": "s1", "destinationId": "d1", "status": "active", "syncCatalog": {"streams": []}, "latestSyncJobStatus": "succeeded"}`
+)
+
+func TestWorkspace_ExportImport(t *testing.T) {
+	var created []string
+	src := workspaceCommand(t, workspaceHTTP(t, testWorkspaceSource, testWorkspaceDestination, testWorkspaceConnection, &created))
+
+	export, err := src.ExportWorkspace(context.Background(), APIOpts{})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	var archive bytes.Buffer
+	if err := WriteWorkspace(&archive, export); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	read, err := ReadWorkspace(&archive)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(export, read); d != "" {
+		t.Error("export mismatch (-want +got):", d)
+	}
+
+	expSecrets := []WorkspaceSecret{
+		{Kind: ConnectorSource, Name: "Faker", Path: "api_key"},
+		{Kind: ConnectorDestination, Name: "Postgres", Path: "credentials.0.password"},
+	}
+	if d := cmp.Diff(expSecrets, read.MaskedSecrets()); d != "" {
+		t.Error("secrets mismatch (-want +got):", d)
+	}
+
+	dst := workspaceCommand(t, workspaceHTTP(t, "", "", "", &created))
+	if err := dst.ImportWorkspace(context.Background(), APIOpts{}, read); err == nil {
+		t.Error("expected error importing masked secrets")
+	}
+
+	for i, secret := range expSecrets {
+		if err := read.SetSecret(secret, "secret-"+string(rune('a'+i))); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+	if err := dst.ImportWorkspace(context.Background(), APIOpts{}, read); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if d := cmp.Diff(3, len(created)); d != "" {
+		t.Fatal("created mismatch (-want +got):", d, created)
+	}
+	exp := []map[string]interface{}{
+		{
+			"workspaceId":             "ws",
+			"sourceDefinitionId":      "faker-def",
+			"name":                    "Faker",
+			"connectionConfiguration": map[string]interface{}{"count": float64(10), "api_key": "secret-a"},
+		},
+		{
+			"workspaceId":             "ws",
+			"destinationDefinitionId": "postgres-def",
+			"name":                    "Postgres",
+			"connectionConfiguration": map[string]interface{}{"host": "db", "credentials": []interface{}{map[string]interface{}{"password": "secret-b"}}},
+		},
+		{
+			"name":          "Faker → Postgres",
+			"sourceId":      "new-source",
+			"destinationId": "new-destination",
+			"status":        "active",
+			"syncCatalog":   map[string]interface{}{"streams": []interface{}{}},
+		},
+	}
+	for i, c := range created {
+		_, raw, _ := strings.Cut(c, " ")
+		var body map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &body); err != nil {
+			t.Fatal("could not decode body", err)
+		}
+		if d := cmp.Diff(exp[i], body); d != "" {
+			t.Errorf("request %d mismatch (-want +got): %s", i, d)
+		}
+	}
+}
+
+func TestWorkspace_ImportExisting(t *testing.T) {
+	var created []string
+	c := workspaceCommand(t, workspaceHTTP(t, testWorkspaceSource, testWorkspaceDestination, testWorkspaceConnection, &created))
+
+	export, err := c.ExportWorkspace(context.Background(), APIOpts{})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	for _, secret := range export.MaskedSecrets() {
+		if err := export.SetSecret(secret, "secret"); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+
+	// everything already exists, nothing is created again
+	if err := c.ImportWorkspace(context.Background(), APIOpts{}, export); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if len(created) > 0 {
+		t.Error("unexpected requests", created)
+	}
+}
+
+func TestReadWorkspace_Invalid(t *testing.T) {
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	if err := tw.WriteHeader(&tar.Header{Name: "other.json", Mode: 0600, Size: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, r := range map[string]io.Reader{
+		"not a tar":        strings.NewReader("workspace"),
+		"missing manifest": &archive,
+	} {
+		if _, err := ReadWorkspace(r); err == nil {
+			t.Errorf("expected error for %s", name)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package local
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"path/filepath"
+)
+
+func NewCmdApply(provider *k8s.Provider) *cobra.Command {
+	var (
+		flagFile     string
+		flagCheck    bool
+		flagKeyring  string
+		flagInsecure bool
+
+		spec local.ApplySpec
+	)
+
+	cmd := &cobra.Command{
+		Use:         "apply",
+		Annotations: audit.Annotations(),
+		Short:       "Reconcile local Airbyte with a declarative spec",
+		Long: `Reconcile local Airbyte with a declarative spec, changing only what differs from it.
+
+The spec sets the chart version, helm values, hosts, and secrets of an existing installation, and may be kept in git
+alongside the values file it references. The values of secrets are referenced from environment variables or files,
+rather than being contained by the spec. Anything the spec does not set, including helm values, is left unchanged.
+The port is only checked, as changing it requires reinstalling.
+
+  namespace: airbyte-abctl
+  port: 8000
+  chartVersion: 0.63.0
+  valuesFile: values.yaml
+  values:
+    global:
+      auth:
+        enabled: true
+  hosts: [localhost, airbyte.lan]
+  secrets:
+    - name: airbyte-config-secrets
+      fromEnv:
+        license-key: AIRBYTE_LICENSE_KEY
+      fromFile:
+        client-secret: secrets/client-secret`,
+		Example: `  abctl local apply -f abctl.yaml
+  abctl local apply -f abctl.yaml --check`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if spec, err = local.LoadApplySpec(flagFile, flagNamespace); err != nil {
+				pterm.Error.Printfln("Invalid spec '%s'", flagFile)
+				return err
+			}
+			if cmd.Flags().Changed("namespace") && spec.Namespace != flagNamespace {
+				pterm.Error.Printfln("The spec namespace '%s' does not match --namespace '%s'", spec.Namespace, flagNamespace)
+				return fmt.Errorf("spec namespace %s conflicts with --namespace %s", spec.Namespace, flagNamespace)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ := pterm.DefaultSpinner.Start("Checking for drift")
+			lc, err := existingCommand(cmd.Context(), provider, spinner, spec.Namespace)
+			if err != nil {
+				return err
+			}
+
+			if flagCheck {
+				changes, err := lc.Drift(cmd.Context(), spec)
+				if err != nil {
+					spinner.Fail("Unable to check for drift")
+					return err
+				}
+				if len(changes) == 0 {
+					spinner.Success("No drift, Airbyte matches the spec")
+					return nil
+				}
+				spinner.Warning(fmt.Sprintf("Airbyte differs from the spec by %d change(s)", len(changes)))
+				if err := printChanges(changes); err != nil {
+					return err
+				}
+				return localerr.ErrDrift
+			}
+
+			spinner.UpdateText("Applying the spec")
+			unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			download := &local.DownloadOpts{Dir: filepath.Join(paths.AbCtl, "charts"), Keyring: flagKeyring, InsecureSkipVerify: flagInsecure}
+			changes, err := lc.Apply(cmd.Context(), spec, download)
+			if err != nil {
+				spinner.Fail("Unable to apply the spec")
+				if len(changes) > 0 {
+					_ = printChanges(changes)
+				}
+				return err
+			}
+			if len(changes) == 0 {
+				spinner.Success("No changes, Airbyte already matches the spec")
+				return nil
+			}
+			spinner.Success(fmt.Sprintf("Applied %d change(s)", len(changes)))
+			return printChanges(changes)
+		},
+	}
+
+	cmd.Flags().StringVarP(&flagFile, "file", "f", "abctl.yaml", "the spec to apply")
+	cmd.Flags().BoolVar(&flagCheck, "check", false, "only check for drift, listing the changes and failing if there are any, instead of applying them")
+	cmd.Flags().StringVar(&flagKeyring, "chart-keyring", "", "path of a PGP keyring used to verify the provenance (.prov) of the airbyte helm chart, only the chart digest is verified by default")
+	cmd.Flags().BoolVar(&flagInsecure, "insecure-skip-verify", false, "upgrade helm charts which cannot be verified, because they have no digest, a mismatched digest, or an invalid provenance")
+
+	return cmd
+}
+
+// printChanges prints the changes as a table.
+func printChanges(changes []local.Change) error {
+	data := pterm.TableData{{"FIELD", "CURRENT", "DESIRED"}}
+	for _, c := range changes {
+		data = append(data, []string{c.Field, c.Current, c.Desired})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
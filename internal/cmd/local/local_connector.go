@@ -0,0 +1,286 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"path"
+	"time"
+)
+
+// connectorAPITimeout is how long the connector commands wait for the Airbyte api to become ready.
+const connectorAPITimeout = 2 * time.Minute
+
+// apiFlags are the flags shared by every command accessing the Airbyte api.
+type apiFlags struct {
+	host     string
+	username string
+	password string
+}
+
+// register registers the flags as persistent flags of the cmd.
+func (f *apiFlags) register(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&f.host, "host", local.DefaultHost, "ingress http host used to access the api")
+	cmd.PersistentFlags().StringVarP(&f.username, "username", "u", "airbyte", "basic auth username, can also be specified via "+envBasicAuthUser)
+	cmd.PersistentFlags().StringVarP(&f.password, "password", "p", "password", "basic auth password, can also be specified via "+envBasicAuthPass)
+}
+
+// apiOpts returns the local.APIOpts of the flags, with the credentials of the env-vars taking precedence.
+func (f apiFlags) apiOpts(cmd *cobra.Command) local.APIOpts {
+	opts := local.APIOpts{Host: f.host, User: f.username, Pass: f.password}
+	// the remote host must be used to access the ingress, unless another host was explicitly requested
+	if remote := remoteDockerHost(); remote != "" && !cmd.Flags().Changed("host") {
+		opts.Host = remote
+	}
+	if env := os.Getenv(envBasicAuthUser); env != "" {
+		opts.User = env
+	}
+	if env := os.Getenv(envBasicAuthPass); env != "" {
+		opts.Pass = env
+	}
+	return opts
+}
+
+func NewCmdConnector(provider *k8s.Provider) *cobra.Command {
+	var flags apiFlags
+
+	cmd := &cobra.Command{
+		Use:   "connector",
+		Short: "Manage the custom connectors of local Airbyte",
+		Long: `Manage the custom connectors of local Airbyte, e.g. while developing a connector.
+
+The connectors are managed by the Airbyte api, accessed via the ingress with the basic-auth credentials of the
+installation. A connector image built locally is loaded into the kind cluster, so it does not have to be pushed to a
+registry first.`,
+		Example: `  docker build -t example/source-test:dev .
+  abctl local connector add example/source-test:dev --name Test
+  # after every rebuild, add the connector again to load the new image
+  abctl local connector add example/source-test:dev
+  abctl local connector remove example/source-test`,
+	}
+
+	flags.register(cmd)
+
+	cmd.AddCommand(newCmdConnectorList(provider, &flags), newCmdConnectorAdd(provider, &flags), newCmdConnectorRemove(provider, &flags))
+
+	return cmd
+}
+
+// connectorKindFlag verifies the --kind flag is a valid connector kind.
+func connectorKindFlag(kind string) error {
+	if err := local.ValidateConnectorKind(kind); err != nil {
+		pterm.Error.Printfln("Invalid --kind '%s'", kind)
+		return err
+	}
+	return nil
+}
+
+func newCmdConnectorList(provider *k8s.Provider, flags *apiFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the custom connectors",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ := pterm.DefaultSpinner.Start("Listing the custom connectors")
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), connectorAPITimeout)
+			defer cancel()
+
+			connectors, err := lc.ListConnectors(ctx, flags.apiOpts(cmd))
+			if err != nil {
+				spinner.Fail("Unable to list the custom connectors")
+				return err
+			}
+			_ = spinner.Stop()
+
+			if len(connectors) == 0 {
+				pterm.Info.Println("No custom connectors")
+				return nil
+			}
+
+			table := pterm.TableData{{"KIND", "NAME", "IMAGE", "ID"}}
+			for _, c := range connectors {
+				table = append(table, []string{c.Kind, c.Name, c.DockerRepository + ":" + c.DockerImageTag, c.ID})
+			}
+			return pterm.DefaultTable.WithHasHeader().WithData(table).Render()
+		},
+	}
+}
+
+func newCmdConnectorAdd(provider *k8s.Provider, flags *apiFlags) *cobra.Command {
+	var (
+		flagKind   string
+		flagName   string
+		flagDocs   string
+		flagNoLoad bool
+	)
+
+	cmd := &cobra.Command{
+		Use:         "add <image>",
+		Annotations: audit.Annotations(),
+		Short:       "Add a custom connector, or update the tag of an existing one",
+		Long: `Add a custom connector, or update the tag of an existing one.
+
+The image (repository:tag) is loaded into the kind cluster from docker, unless --no-load is provided. An existing
+custom connector of the same repository is updated to the tag, so adding the connector again after a rebuild picks up
+the new image. The images of an existing cluster are not loaded, they must be pulled from a registry.`,
+		Example: `  abctl local connector add example/source-test:dev --name Test
+  abctl local connector add example/destination-test:0.1.0 --kind destination`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := connectorKindFlag(flagKind); err != nil {
+				return err
+			}
+			repository, tag, err := local.ParseConnectorImage(args[0])
+			if err != nil {
+				pterm.Error.Printfln("Invalid image '%s'", args[0])
+				return err
+			}
+			if flagName == "" {
+				flagName = path.Base(repository)
+			}
+
+			spinner, _ := pterm.DefaultSpinner.Start(fmt.Sprintf("Adding the custom %s connector '%s'", flagKind, flagName))
+
+			unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			if !flagNoLoad && provider.Name == k8s.Kind {
+				spinner.UpdateText(fmt.Sprintf("Loading the image '%s' into the cluster", args[0]))
+				if err := loadConnectorImage(cmd.Context(), provider, args[0]); err != nil {
+					spinner.Fail(fmt.Sprintf("Unable to load the image '%s'", args[0]))
+					return err
+				}
+				pterm.Success.Printfln("Loaded the image '%s' into the cluster", args[0])
+			}
+
+			spinner.UpdateText(fmt.Sprintf("Adding the custom %s connector '%s'", flagKind, flagName))
+			ctx, cancel := context.WithTimeout(cmd.Context(), connectorAPITimeout)
+			defer cancel()
+
+			def := local.ConnectorDefinition{Name: flagName, DockerRepository: repository, DockerImageTag: tag, DocumentationURL: flagDocs}
+			created, err := lc.AddConnector(ctx, flags.apiOpts(cmd), flagKind, def)
+			if err != nil {
+				spinner.Fail(fmt.Sprintf("Unable to add the custom %s connector '%s'", flagKind, flagName))
+				return err
+			}
+
+			if created {
+				spinner.Success(fmt.Sprintf("Added the custom %s connector '%s'", flagKind, flagName))
+			} else {
+				spinner.Success(fmt.Sprintf("Updated the custom %s connector of '%s' to tag %s", flagKind, repository, tag))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flagKind, "kind", local.ConnectorSource, "the kind of the connector, either "+local.ConnectorSource+" or "+local.ConnectorDestination)
+	cmd.Flags().StringVar(&flagName, "name", "", "the name of the connector (defaults to the last path element of the repository)")
+	cmd.Flags().StringVar(&flagDocs, "documentation-url", "", "the url of the documentation of the connector")
+	cmd.Flags().BoolVar(&flagNoLoad, "no-load", false, "do not load the image into the cluster, it is pulled from its registry instead")
+
+	return cmd
+}
+
+// loadConnectorImage loads the image from docker into every node of the kind cluster of the provider.
+func loadConnectorImage(ctx context.Context, provider *k8s.Provider, image string) error {
+	cluster, err := provider.Cluster()
+	if err != nil {
+		return err
+	}
+
+	archive, err := dockerClient.SaveImage(ctx, image)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	// every node reads the archive, which is why it is written to a file first
+	f, err := os.CreateTemp("", "abctl-connector-*.tar")
+	if err != nil {
+		return fmt.Errorf("could not create image archive: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = io.Copy(f, archive)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("could not write image archive: %w", err)
+	}
+
+	if err := cluster.LoadImage(f.Name()); err != nil {
+		return fmt.Errorf("could not load image %s: %w", image, err)
+	}
+	return nil
+}
+
+func newCmdConnectorRemove(provider *k8s.Provider, flags *apiFlags) *cobra.Command {
+	var flagKind string
+
+	cmd := &cobra.Command{
+		Use:         "remove <name|repository>",
+		Annotations: audit.Annotations(),
+		Short:       "Remove a custom connector",
+		Long: `Remove a custom connector, identified by either its name or its docker repository.
+
+Airbyte deletes the sources or destinations, and the connections, using the connector as well.`,
+		Example: `  abctl local connector remove example/source-test`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := connectorKindFlag(flagKind); err != nil {
+				return err
+			}
+
+			spinner, _ := pterm.DefaultSpinner.Start(fmt.Sprintf("Removing the custom %s connector '%s'", flagKind, args[0]))
+
+			unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), connectorAPITimeout)
+			defer cancel()
+
+			removed, err := lc.RemoveConnector(ctx, flags.apiOpts(cmd), flagKind, args[0])
+			if err != nil {
+				spinner.Fail(fmt.Sprintf("Unable to remove the custom %s connector '%s'", flagKind, args[0]))
+				return err
+			}
+
+			spinner.Success(fmt.Sprintf("Removed the custom %s connector '%s'", flagKind, removed.Name))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flagKind, "kind", local.ConnectorSource, "the kind of the connector, either "+local.ConnectorSource+" or "+local.ConnectorDestination)
+
+	return cmd
+}
@@ -0,0 +1,94 @@
+package local
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"io"
+)
+
+const (
+	credentialsFormatText = "text"
+	credentialsFormatJSON = "json"
+)
+
+func NewCmdCredentials(provider *k8s.Provider) *cobra.Command {
+	var (
+		flagShow   bool
+		flagFormat string
+		flagCopy   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "credentials",
+		Short: "Print the instance admin credentials of local Airbyte",
+		Long: `Print the instance admin credentials the Airbyte Helm Chart generated on first install.
+
+The password and client secret are masked unless --show is provided. They are never stored by abctl, which only
+records where they are stored within the cluster.`,
+		Example: `  abctl local credentials --show
+  abctl local credentials --format json --show
+  abctl local credentials --copy`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			switch flagFormat {
+			case credentialsFormatText, credentialsFormatJSON:
+				return nil
+			default:
+				return fmt.Errorf("format must be one of %s or %s, received %s", credentialsFormatText, credentialsFormatJSON, flagFormat)
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ := pterm.DefaultSpinner.Start("Fetching the credentials")
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			creds, err := lc.Credentials(cmd.Context())
+			if err != nil {
+				spinner.Fail("Unable to fetch the credentials")
+				return err
+			}
+			spinner.Success("Fetched the credentials")
+
+			if flagCopy {
+				if err := copyToClipboard(cmd.Context(), creds.Password); err != nil {
+					pterm.Error.Println("Unable to copy the password to the clipboard")
+					return err
+				}
+				pterm.Success.Println("Copied the password to the clipboard")
+			}
+
+			if !flagShow {
+				creds = creds.Masked()
+			}
+			return printCredentials(cmd.OutOrStdout(), flagFormat, creds)
+		},
+	}
+
+	cmd.Flags().BoolVar(&flagShow, "show", false, "print the password and client secret instead of masking them")
+	cmd.Flags().StringVar(&flagFormat, "format", credentialsFormatText, "the output format, either text or json")
+	cmd.Flags().BoolVar(&flagCopy, "copy", false, "copy the password to the clipboard")
+
+	return cmd
+}
+
+// printCredentials writes the credentials to w in the format.
+func printCredentials(w io.Writer, format string, creds local.Credentials) error {
+	if format == credentialsFormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(creds)
+	}
+
+	email := creds.Email
+	if email == "" {
+		email = "(set when first signing in)"
+	}
+	_, err := fmt.Fprintf(w, "Email: %s\nPassword: %s\nClient-Id: %s\nClient-Secret: %s\n", email, creds.Password, creds.ClientID, creds.ClientSecret)
+	return err
+}
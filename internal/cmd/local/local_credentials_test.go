@@ -0,0 +1,53 @@
+package local
+
+import (
+	"bytes"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestPrintCredentials(t *testing.T) {
+	creds := local.Credentials{Password: "p4ss", ClientID: "client", ClientSecret: "s3cr3t"}
+
+	tests := []struct {
+		name   string
+		format string
+		creds  local.Credentials
+		exp    string
+	}{
+		{
+			name:   "text",
+			format: credentialsFormatText,
+			creds:  creds,
+			exp:    "Email: (set when first signing in)\nPassword: p4ss\nClient-Id: client\nClient-Secret: s3cr3t\n",
+		},
+		{
+			name:   "json masked",
+			format: credentialsFormatJSON,
+			creds:  creds.Masked(),
+			exp:    "{\n  \"password\": \"********\",\n  \"client_id\": \"client\",\n  \"client_secret\": \"********\"\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := printCredentials(&buf, tt.format, tt.creds); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, buf.String()); d != "" {
+				t.Error("output mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestClipboardCommands(t *testing.T) {
+	if d := cmp.Diff([][]string{{"pbcopy"}}, clipboardCommands("darwin")); d != "" {
+		t.Error("darwin mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff("wl-copy", clipboardCommands("linux")[0][0]); d != "" {
+		t.Error("linux mismatch (-want +got):", d)
+	}
+}
@@ -0,0 +1,174 @@
+package local
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"os"
+	"time"
+)
+
+func NewCmdDB(provider *k8s.Provider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Access the database of local Airbyte",
+		Long: `Access the database of local Airbyte.
+
+The commands are executed within the database pod deployed by the Airbyte Helm Chart, authenticated by the
+credentials of the secret it generates. An external database must be accessed with the tools of its provider.`,
+	}
+
+	cmd.AddCommand(newCmdDBPsql(provider), newCmdDBDump(provider), newCmdDBRestore(provider))
+
+	return cmd
+}
+
+func newCmdDBPsql(provider *k8s.Provider) *cobra.Command {
+	return &cobra.Command{
+		Use:   "psql",
+		Short: "Open an interactive psql session connected to the Airbyte database",
+		Long: `Open an interactive psql session connected to the Airbyte database.
+
+If stdin is not a terminal, the SQL read from stdin is executed instead, e.g.
+  echo 'select count(*) from connection' | abctl local db psql`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ := pterm.DefaultSpinner.Start("Connecting to the Airbyte database")
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+			// the spinner must not write over the session
+			_ = spinner.Stop()
+
+			tty := term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+			if tty {
+				state, err := term.MakeRaw(int(os.Stdin.Fd()))
+				if err != nil {
+					return fmt.Errorf("could not configure the terminal: %w", err)
+				}
+				defer func() { _ = term.Restore(int(os.Stdin.Fd()), state) }()
+			}
+
+			if err := lc.DatabaseShell(cmd.Context(), os.Stdin, os.Stdout, os.Stderr, tty); err != nil {
+				pterm.Error.Println("Unable to run psql")
+				return err
+			}
+			return nil
+		},
+	}
+}
+
+func newCmdDBDump(provider *k8s.Provider) *cobra.Command {
+	var flagOutput string
+
+	cmd := &cobra.Command{
+		Use:   "dump",
+		Short: "Dump the Airbyte database as plain SQL",
+		Long: `Dump the Airbyte database as plain SQL.
+
+The dump can be restored by 'abctl local db restore', replacing the contents of the database.`,
+		Example: `  abctl local db dump --output airbyte.sql`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flagOutput == "" {
+				flagOutput = fmt.Sprintf("airbyte-db-%s.sql", time.Now().UTC().Format("20060102T150405Z"))
+			}
+
+			spinner, _ := pterm.DefaultSpinner.Start("Dumping the Airbyte database")
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			f, err := os.OpenFile(flagOutput, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+			if err != nil {
+				spinner.Fail(fmt.Sprintf("Unable to create '%s'", flagOutput))
+				return fmt.Errorf("could not create dump %s: %w", flagOutput, err)
+			}
+
+			err = lc.DumpDatabase(cmd.Context(), f)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				// a partial dump must not be mistaken for a usable dump
+				_ = os.Remove(flagOutput)
+				spinner.Fail("Unable to dump the Airbyte database")
+				return err
+			}
+
+			spinner.Success(fmt.Sprintf("Dumped the Airbyte database to %s", flagOutput))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flagOutput, "output", "o", "", "the file to write the dump to (defaults to airbyte-db-<timestamp>.sql)")
+
+	return cmd
+}
+
+func newCmdDBRestore(provider *k8s.Provider) *cobra.Command {
+	var flagYes bool
+
+	cmd := &cobra.Command{
+		Use:         "restore <file>",
+		Annotations: audit.Annotations(),
+		Short:       "Restore a dump of the Airbyte database",
+		Long: `Restore a dump of the Airbyte database.
+
+Either a plain SQL dump of 'abctl local db dump', or a backup in the pg_dump custom format, e.g. of
+'abctl local edition switch' in ~/.airbyte/abctl/backups, can be restored. Every object of the dump replaces the
+existing object in the database. Pause any syncs first, as Airbyte keeps using the database while it is restored.`,
+		Example: `  abctl local db restore airbyte.sql`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !flagYes && term.IsTerminal(int(os.Stdin.Fd())) {
+				confirmed, err := pterm.DefaultInteractiveConfirm.Show(fmt.Sprintf("Restore '%s', replacing the contents of the Airbyte database?", args[0]))
+				if err != nil {
+					return fmt.Errorf("could not prompt for confirmation: %w", err)
+				}
+				if !confirmed {
+					return fmt.Errorf("database restore %w", localerr.ErrCancelled)
+				}
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				pterm.Error.Printfln("Unable to open '%s'", args[0])
+				return fmt.Errorf("could not open dump %s: %w", args[0], err)
+			}
+			defer f.Close()
+
+			spinner, _ := pterm.DefaultSpinner.Start("Restoring the Airbyte database")
+			unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			if err := lc.RestoreDatabase(cmd.Context(), f); err != nil {
+				spinner.Fail("Unable to restore the Airbyte database")
+				return err
+			}
+
+			spinner.Success(fmt.Sprintf("Restored '%s'", args[0]))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "restore without prompting for confirmation")
+
+	return cmd
+}
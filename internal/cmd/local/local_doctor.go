@@ -0,0 +1,80 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/netdiag"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+func NewCmdDoctor(provider *k8s.Provider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the conditions interfering with the networking of local Airbyte",
+		Long: `Diagnose the conditions interfering with the networking of local Airbyte.
+
+On linux, the routes of the host are checked for a VPN routing the subnet of the kind network, and firewalld and ufw
+for blocking the traffic of its bridge. If the cluster exists, a pod checks whether the cluster resolves both its own
+services and the hosts of the internet, and is created in the namespace of --namespace. A fix is printed for each
+condition found.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ := pterm.DefaultSpinner.Start("Checking the network")
+
+			var findings []netdiag.Finding
+			if lc := doctorCommand(cmd.Context(), provider, spinner, flagNamespace); lc != nil {
+				spinner.UpdateText("Checking the network of the host and the cluster")
+				findings = lc.NetworkDiagnostics(cmd.Context())
+			} else {
+				findings = netdiag.New().Run(cmd.Context())
+			}
+
+			if len(findings) == 0 {
+				spinner.Success("No network issues found")
+				return nil
+			}
+			spinner.Warning(fmt.Sprintf("Found %d network issue(s)", len(findings)))
+			printFindings(findings)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// doctorCommand returns the local.Command of the existing cluster, or nil if there is none, in which case only the
+// host is checked.
+func doctorCommand(ctx context.Context, provider *k8s.Provider, spinner *pterm.SpinnerPrinter, namespace string) *local.Command {
+	// the docker client must be created before the cluster, as it determines the docker host kind uses
+	if dockerClient == nil && provider.Name != k8s.Existing {
+		var err error
+		if dockerClient, err = newDockerClient(ctx); err != nil {
+			logging.Debugf("Only checking the host, could not connect to docker: %s", err)
+			return nil
+		}
+	}
+
+	cluster, err := provider.Cluster()
+	if err != nil || !cluster.Exists() {
+		logging.Debugf("Only checking the host, cluster '%s' does not exist: %v", provider.ClusterName, err)
+		return nil
+	}
+	lc, err := local.New(*provider, local.WithTelemetryClient(telClient), local.WithSpinner(spinner), local.WithNamespace(namespace), installationOption(*provider, namespace))
+	if err != nil {
+		logging.Debugf("Only checking the host, could not connect to cluster '%s': %s", provider.ClusterName, err)
+		return nil
+	}
+	return lc
+}
+
+// printFindings prints the network findings and their fixes.
+func printFindings(findings []netdiag.Finding) {
+	for _, f := range findings {
+		pterm.Warning.Printfln("[%s] %s\nTo resolve it:\n  %s", f.Check, f.Problem, strings.Join(f.Fixes, "\n  "))
+	}
+}
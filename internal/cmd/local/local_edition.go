@@ -0,0 +1,121 @@
+package local
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"os"
+	"path/filepath"
+)
+
+func NewCmdEdition(provider *k8s.Provider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edition",
+		Short: "Show or switch the edition of local Airbyte",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ := pterm.DefaultSpinner.Start("Fetching the edition")
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			edition, err := lc.Edition()
+			if err != nil {
+				spinner.Fail("Unable to fetch the edition")
+				return err
+			}
+			spinner.Success(fmt.Sprintf("Airbyte is the %s edition", edition))
+			return nil
+		},
+	}
+
+	cmd.AddCommand(newCmdEditionSwitch(provider))
+
+	return cmd
+}
+
+func newCmdEditionSwitch(provider *k8s.Provider) *cobra.Command {
+	var (
+		opts         local.EditionOpts
+		flagKeyring  string
+		flagInsecure bool
+		flagYes      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:         "switch <community|enterprise>",
+		Annotations: audit.Annotations(),
+		Short:       "Switch local Airbyte between the community and enterprise editions",
+		Long: `Switch local Airbyte between the community and enterprise editions.
+
+The database is backed up into ~/.airbyte/abctl/backups before the helm values are changed, and the deployments are
+restarted afterwards. Switching to the enterprise edition configures single sign-on, see 'abctl local sso configure'
+for the required values, which are prompted for when running in a terminal.`,
+		Example: `  abctl local edition switch enterprise --issuer https://example.okta.com --app-name airbyte ...
+  abctl local edition switch community`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{local.EditionCommunity, local.EditionEnterprise},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.Edition = args[0]
+			switch opts.Edition {
+			case local.EditionCommunity:
+				return nil
+			case local.EditionEnterprise:
+				if err := promptSSOOpts(&opts.SSO); err != nil {
+					return err
+				}
+				return opts.SSO.Validate()
+			default:
+				return fmt.Errorf("unsupported edition %s, must be %s or %s", opts.Edition, local.EditionCommunity, local.EditionEnterprise)
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !flagYes && term.IsTerminal(int(os.Stdin.Fd())) {
+				confirmed, err := pterm.DefaultInteractiveConfirm.Show(fmt.Sprintf("Switch Airbyte to the %s edition? Airbyte will be unavailable while it restarts", opts.Edition))
+				if err != nil {
+					return fmt.Errorf("could not prompt for confirmation: %w", err)
+				}
+				if !confirmed {
+					return fmt.Errorf("edition switch %w", localerr.ErrCancelled)
+				}
+			}
+
+			spinner, _ := pterm.DefaultSpinner.Start(fmt.Sprintf("Switching to the %s edition", opts.Edition))
+			unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			opts.BackupDir = paths.Backups
+			opts.Download = &local.DownloadOpts{Dir: filepath.Join(paths.AbCtl, "charts"), Keyring: flagKeyring, InsecureSkipVerify: flagInsecure}
+			if err := lc.SwitchEdition(cmd.Context(), opts); err != nil {
+				spinner.Fail(fmt.Sprintf("Unable to switch to the %s edition", opts.Edition))
+				return err
+			}
+			spinner.Success(fmt.Sprintf("Switched to the %s edition", opts.Edition))
+			return nil
+		},
+	}
+
+	ssoFlags(cmd, &opts.SSO)
+	cmd.Flags().BoolVar(&opts.SkipBackup, "skip-backup", false, "do not backup the database before switching, required when an external database is configured")
+	cmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "do not prompt for confirmation")
+	cmd.Flags().StringVar(&flagKeyring, "chart-keyring", "", "path of a PGP keyring used to verify the provenance (.prov) of the airbyte helm chart, only the chart digest is verified by default")
+	cmd.Flags().BoolVar(&flagInsecure, "insecure-skip-verify", false, "upgrade helm charts which cannot be verified, because they have no digest, a mismatched digest, or an invalid provenance")
+
+	return cmd
+}
@@ -0,0 +1,73 @@
+package local
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/events"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"strings"
+	"time"
+)
+
+func NewCmdEvents() *cobra.Command {
+	var (
+		flagSince    time.Duration
+		flagSeverity string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "List the recorded Kubernetes events of local Airbyte",
+		Long: `List the recorded Kubernetes events of local Airbyte.
+
+Interesting events (warnings, backoffs, image pulls) observed while installing Airbyte are recorded, so they are
+available after the installation has completed. Only the most recent ` + fmt.Sprintf("%d", events.MaxEvents) + ` events are retained, of which those
+of the --namespace are listed.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			flagSeverity = strings.ToLower(flagSeverity)
+			switch flagSeverity {
+			case "", events.SeverityNormal, events.SeverityWarning:
+				return nil
+			default:
+				return fmt.Errorf("severity must be one of %s or %s, received %s", events.SeverityNormal, events.SeverityWarning, flagSeverity)
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			recorded, err := events.Load(paths.Events)
+			if err != nil {
+				pterm.Error.Println("Unable to load the recorded events")
+				return err
+			}
+
+			var since time.Time
+			if flagSince > 0 {
+				since = time.Now().Add(-flagSince)
+			}
+
+			filtered := events.Filter(recorded, since, flagSeverity, flagNamespace)
+			if len(filtered) == 0 {
+				pterm.Info.Println("No events found")
+				return nil
+			}
+
+			data := pterm.TableData{{"TIME", "SEVERITY", "REASON", "OBJECT", "COUNT", "MESSAGE"}}
+			for _, e := range filtered {
+				count := ""
+				if e.Count > 0 {
+					count = fmt.Sprintf("%d", e.Count)
+				}
+				data = append(data, []string{
+					e.Time.Local().Format(time.RFC3339), e.Severity, e.Reason, e.Object, count, e.Message,
+				})
+			}
+
+			return pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+		},
+	}
+
+	cmd.Flags().DurationVar(&flagSince, "since", 0, "only list events newer than this duration ago, e.g. 1h (defaults to every recorded event)")
+	cmd.Flags().StringVar(&flagSeverity, "severity", "", "only list events of this severity, either normal or warning")
+
+	return cmd
+}
@@ -0,0 +1,93 @@
+package local
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	historyOutputTable = "table"
+	historyOutputJSON  = "json"
+)
+
+func NewCmdHistory(provider *k8s.Provider) *cobra.Command {
+	var (
+		flagMax    int
+		flagOutput string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List the revisions of the Helm releases of local Airbyte",
+		Long: `List the revisions of the Helm releases of local Airbyte, oldest first.
+
+Every install, upgrade, and rollback of the airbyte release, the release of the ingress controller, and the monitoring
+and log aggregation releases (if installed) creates a revision, whose description records what happened to it. This
+includes any release abctl rolled back, or uninstalled, after finding it stuck in a pending state.`,
+		Example: `  abctl local history
+  abctl local history --max 5 --output json`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if flagMax < 1 {
+				return fmt.Errorf("--max must be at least 1, received %d", flagMax)
+			}
+			switch flagOutput {
+			case historyOutputTable, historyOutputJSON:
+				return nil
+			default:
+				return fmt.Errorf("output must be one of %s or %s, received %s", historyOutputTable, historyOutputJSON, flagOutput)
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ := pterm.DefaultSpinner.Start("Fetching the history of the Helm releases")
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			revisions, err := lc.History(flagMax)
+			if err != nil {
+				spinner.Fail("Unable to fetch the history of the Helm releases")
+				return err
+			}
+			if len(revisions) == 0 {
+				spinner.Warning("No Helm releases found, Airbyte does not appear to be installed")
+				return nil
+			}
+			spinner.Success(fmt.Sprintf("Fetched %d revision(s) of the Helm releases", len(revisions)))
+
+			return printRevisions(cmd.OutOrStdout(), flagOutput, revisions)
+		},
+	}
+
+	cmd.Flags().IntVar(&flagMax, "max", 10, "the maximum number of revisions listed of each release, the latest revisions are listed")
+	cmd.Flags().StringVarP(&flagOutput, "output", "o", historyOutputTable, "the output format, either table or json")
+
+	return cmd
+}
+
+// printRevisions writes the revisions to w in the output format.
+func printRevisions(w io.Writer, output string, revisions []local.Revision) error {
+	if output == historyOutputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(revisions)
+	}
+
+	data := pterm.TableData{{"RELEASE", "REVISION", "UPDATED", "STATUS", "CHART VERSION", "APP VERSION", "DESCRIPTION"}}
+	for _, r := range revisions {
+		updated := ""
+		if !r.Updated.IsZero() {
+			updated = r.Updated.Local().Format(time.RFC3339)
+		}
+		data = append(data, []string{r.Release, strconv.Itoa(r.Revision), updated, r.Status, r.ChartVersion, r.AppVersion, r.Description})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithWriter(w).WithData(data).Render()
+}
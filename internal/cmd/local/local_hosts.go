@@ -0,0 +1,158 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdHosts(provider *k8s.Provider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hosts",
+		Short: "Manage the hosts local Airbyte is accessible from",
+		Long: `Manage the hosts local Airbyte is accessible from.
+
+The hosts are initially configured by 'abctl local install --host', these commands change them without reinstalling.`,
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "List the hosts local Airbyte is accessible from",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				spinner, _ := pterm.DefaultSpinner.Start("Fetching the configured hosts")
+				lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+				if err != nil {
+					return err
+				}
+
+				hosts, err := lc.Hosts(cmd.Context())
+				if err != nil {
+					spinner.Fail("Unable to fetch the configured hosts")
+					return err
+				}
+				spinner.Success("Fetched the configured hosts")
+
+				for _, host := range hosts {
+					pterm.Println(host)
+				}
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:         "add <host>...",
+			Annotations: audit.Annotations(),
+			Short:       "Make local Airbyte accessible from additional hosts",
+			Example:     "  abctl local hosts add airbyte.lan 192.168.1.10",
+			Args:        cobra.MinimumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				spinner, _ := pterm.DefaultSpinner.Start("Adding hosts")
+
+				unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+				if err != nil {
+					return err
+				}
+				defer unlock()
+
+				lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+				if err != nil {
+					return err
+				}
+
+				if err := lc.AddHosts(cmd.Context(), args); err != nil {
+					spinner.Fail("Unable to add hosts")
+					return err
+				}
+				spinner.Success("Hosts added")
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:         "remove <host>...",
+			Annotations: audit.Annotations(),
+			Short:       "Stop local Airbyte from being accessible from hosts",
+			Example:     "  abctl local hosts remove airbyte.lan",
+			Args:        cobra.MinimumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				spinner, _ := pterm.DefaultSpinner.Start("Removing hosts")
+
+				unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+				if err != nil {
+					return err
+				}
+				defer unlock()
+
+				lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+				if err != nil {
+					return err
+				}
+
+				if err := lc.RemoveHosts(cmd.Context(), args); err != nil {
+					spinner.Fail("Unable to remove hosts")
+					return err
+				}
+				spinner.Success("Hosts removed")
+				return nil
+			},
+		},
+	)
+
+	return cmd
+}
+
+// existingCommand returns the local command of the existing cluster, configured with the port of its ingress.
+func existingCommand(ctx context.Context, provider *k8s.Provider, spinner *pterm.SpinnerPrinter, namespace string) (*local.Command, error) {
+	if err := namespaceFlag(namespace); err != nil {
+		spinner.Fail()
+		return nil, err
+	}
+
+	// the docker client must be created before the cluster, as it determines the docker host kind uses
+	var err error
+	if dockerClient == nil && provider.Name != k8s.Existing {
+		if dockerClient, err = newDockerClient(ctx); err != nil {
+			spinner.Fail("Could not connect to Docker daemon")
+			return nil, fmt.Errorf("could not connect to docker: %w", err)
+		}
+	}
+
+	cluster, err := provider.Cluster()
+	if err != nil {
+		spinner.Fail(fmt.Sprintf("Could not determine status of any existing '%s' cluster", provider.ClusterName))
+		return nil, err
+	}
+
+	if !cluster.Exists() {
+		spinner.Fail("Airbyte does not appear to be installed locally")
+		return nil, fmt.Errorf("cluster '%s' does not exist", provider.ClusterName)
+	}
+
+	port := local.Port
+	// only for kind do we need to check the existing port
+	if provider.Name == k8s.Kind {
+		if port, err = dockerClient.Port(ctx, fmt.Sprintf("%s-control-plane", provider.ClusterName)); err != nil {
+			spinner.Fail(fmt.Sprintf("Could not determine docker port for cluster '%s'", provider.ClusterName))
+			return nil, err
+		}
+	}
+
+	lc, err := local.New(*provider,
+		local.WithPortHTTP(port),
+		local.WithTelemetryClient(telClient),
+		local.WithSpinner(spinner),
+		local.WithNamespace(namespace),
+		installationOption(*provider, namespace),
+	)
+	if err != nil {
+		spinner.Fail("Failed to initialize 'local' command")
+		return nil, fmt.Errorf("could not initialize local command: %w", err)
+	}
+
+	return lc, nil
+}
@@ -1,14 +1,34 @@
 package local
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/airbytehq/abctl/internal/build"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
 	"github.com/airbytehq/abctl/internal/cmd/local/docker"
 	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
 	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/cmd/local/netdiag"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/progress"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/airbytehq/abctl/internal/download"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/airbytehq/abctl/internal/shutdown"
 	"github.com/airbytehq/abctl/internal/telemetry"
+	"github.com/airbytehq/abctl/internal/trace"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"io/fs"
+	"net"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 )
 
 const (
@@ -18,23 +38,253 @@ const (
 	envBasicAuthPass = "ABCTL_LOCAL_INSTALL_PASSWORD"
 )
 
-func NewCmdInstall(provider k8s.Provider) *cobra.Command {
+func NewCmdInstall(provider *k8s.Provider) *cobra.Command {
 	spinner := &pterm.DefaultSpinner
 
 	var (
 		flagChartValuesFile string
+		flagConnectorReg    string
+		flagDemo            bool
+		flagMaxDownloadRate string
 		flagChartVersion    string
+		flagChart           string
+		flagChartUsername   string
+		flagChartPassword   string
+		flagKindNodeImage   string
+		flagKindConfig      string
+		flagKindAPIPort     int
+		flagKindIPFamily    string
+		flagBindAddress     string
+		flagKindMounts      []string
+		flagVolumeMounts    []string
+		flagImageCache      bool
+		flagRegistryMirror  string
+		flagIngress         string
+		flagIngressClass    string
+		flagSkipNginx       bool
+		flagAPIPort         int
+		flagReadyTimeout    time.Duration
+		flagNoCache         bool
+		flagChartKeyring    string
+		flagInsecureSkip    bool
+		flagSkipK8sVersion  bool
+		flagShowDiff        bool
+		flagNoDiff          bool
+		flagExtraManifests  string
+		flagCACert          string
+		flagHostAliases     []string
+		flagDNSServers      []string
+		flagHostGateway     bool
+		flagDisabled        []string
+		flagLowResource     bool
+		flagTuning          local.TuningOpts
+		flagHosts           []string
 		flagMigrate         bool
+		flagAutoStart       bool
 		flagUsername        string
 		flagPassword        string
 		flagPort            int
+		flagStorageClass    string
+		flagMonitoring      bool
+		flagLogAggregation  bool
+		flagLogRetention    time.Duration
+		flagInteractive     bool
+
+		// sso is only set if the enterprise edition was chosen by the install wizard
+		sso *local.SSOOpts
+		// volumeMounts are the parsed flagVolumeMounts
+		volumeMounts []k8s.VolumeMount
+		// chartLoc is the parsed flagChart
+		chartLoc local.ChartLoc
+		// connectorReg is the loaded flagConnectorReg
+		connectorReg *local.ConnectorRegistry
+		// maxDownloadRate is the parsed flagMaxDownloadRate
+		maxDownloadRate int64
+		// dockerDesktop is true if the docker host is Docker Desktop, which resolves the HostGatewayName by itself
+		dockerDesktop bool
 	)
 
 	cmd := &cobra.Command{
-		Use:   "install",
-		Short: "Install Airbyte locally",
+		Use:         "install",
+		Annotations: audit.Annotations(),
+		Short:       "Install Airbyte locally",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if flagInteractive {
+				if !term.IsTerminal(int(os.Stdin.Fd())) {
+					pterm.Error.Println("The interactive installation requires a terminal")
+					return errors.New("--interactive requires stdin to be a terminal")
+				}
+				var err error
+				if sso, err = installWizard(cmd, *provider, ptermPrompter{}); err != nil {
+					return err
+				}
+			}
+
 			spinner, _ = spinner.Start("Starting installation")
+
+			if err := local.ValidateLogRetention(flagLogRetention); err != nil {
+				pterm.Error.Printfln("Invalid --log-retention '%s'", flagLogRetention)
+				return err
+			}
+
+			// the migrated data is only mounted by the persistent volumes of the default namespace
+			if flagMigrate && flagNamespace != local.DefaultNamespace {
+				pterm.Error.Println("Migrating a docker compose installation is only supported with the default namespace")
+				return fmt.Errorf("--migrate is not supported with the namespace %s", flagNamespace)
+			}
+
+			var err error
+			if flagRegistryMirror != "" {
+				if err := local.ValidateRegistryMirror(flagRegistryMirror); err != nil {
+					pterm.Error.Printfln("Invalid --registry-mirror '%s'", flagRegistryMirror)
+					return err
+				}
+			}
+
+			if chartLoc, err = local.ParseChartLoc(flagChart); err != nil {
+				pterm.Error.Printfln("Invalid --chart '%s'", flagChart)
+				return err
+			}
+			if flagChartUsername != "" && chartLoc.Kind != local.ChartLocOCI {
+				pterm.Error.Println("--chart-username is only supported with an oci:// --chart")
+				return fmt.Errorf("--chart-username is not supported with the chart %s", flagChart)
+			}
+
+			if flagAPIPort != 0 && (flagAPIPort < 1 || flagAPIPort > 65535 || flagAPIPort == flagPort) {
+				pterm.Error.Printfln("Invalid --api-port %d", flagAPIPort)
+				return fmt.Errorf("--api-port %d must be a valid port other than --port %d", flagAPIPort, flagPort)
+			}
+
+			if flagMaxDownloadRate != "" {
+				if maxDownloadRate, err = download.ParseRate(flagMaxDownloadRate); err != nil {
+					pterm.Error.Printfln("Invalid --max-download-rate '%s'", flagMaxDownloadRate)
+					return err
+				}
+			}
+
+			if flagShowDiff && flagNoDiff {
+				pterm.Error.Println("--show-diff and --no-diff are mutually exclusive")
+				return fmt.Errorf("--show-diff and --no-diff cannot both be provided")
+			}
+
+			if volumeMounts, err = k8s.ParseVolumeMounts(flagVolumeMounts); err == nil {
+				err = local.ValidateVolumeMounts(volumeMounts)
+			}
+			if err != nil {
+				pterm.Error.Println("Invalid --volume-mount")
+				return err
+			}
+
+			if err := local.ValidateDisabledComponents(flagDisabled); err != nil {
+				pterm.Error.Println("Invalid --disable")
+				return err
+			}
+			if err := flagTuning.Validate(); err != nil {
+				pterm.Error.Println("Invalid job concurrency or resource flags")
+				return err
+			}
+
+			if _, err := dnsOpts(flagHostAliases, flagDNSServers); err != nil {
+				pterm.Error.Println("Invalid --host-alias or --dns-server")
+				return err
+			}
+
+			if flagCACert != "" {
+				if _, err := local.ReadCACert(flagCACert); err != nil {
+					pterm.Error.Printfln("Invalid --ca-cert '%s'", flagCACert)
+					return err
+				}
+				// the path is recorded for subsequent installations, which may run from another directory
+				if flagCACert, err = filepath.Abs(flagCACert); err != nil {
+					return fmt.Errorf("could not determine the absolute path of --ca-cert: %w", err)
+				}
+			}
+
+			if flagExtraManifests != "" {
+				spinner.UpdateText(fmt.Sprintf("Validating extra manifests '%s'", flagExtraManifests))
+				if _, err := local.ReadManifests(flagExtraManifests); err != nil {
+					pterm.Error.Printfln("Invalid extra manifests '%s'", flagExtraManifests)
+					return err
+				}
+			}
+
+			if err := local.ValidateIngressController(flagIngress); err != nil {
+				pterm.Error.Printfln("Invalid --ingress-controller '%s'", flagIngress)
+				return err
+			}
+			if flagSkipNginx {
+				if cmd.Flags().Changed("ingress-controller") && flagIngress != local.IngressNone {
+					pterm.Error.Println("--skip-nginx and --ingress-controller are mutually exclusive")
+					return fmt.Errorf("--skip-nginx is not supported with the ingress controller %s", flagIngress)
+				}
+				flagIngress = local.IngressNone
+			}
+			if flagIngressClass != "" && flagIngress != local.IngressNone {
+				pterm.Error.Println("An ingress class can only be provided without an ingress controller of abctl")
+				return fmt.Errorf("--ingress-class requires --skip-nginx or the ingress controller %s", local.IngressNone)
+			}
+			// the connector registry is imported via the ingress, which abctl cannot reach without its ingress controller
+			if flagIngress == local.IngressNone && flagConnectorReg != "" {
+				pterm.Error.Println("Importing a connector registry requires an ingress controller")
+				return fmt.Errorf("--connector-registry is not supported with the ingress controller %s", local.IngressNone)
+			}
+			if flagConnectorReg != "" {
+				spinner.UpdateText(fmt.Sprintf("Loading connector registry '%s'", flagConnectorReg))
+				reg, err := local.LoadConnectorRegistry(cmd.Context(), httpClient, flagConnectorReg)
+				if err != nil {
+					pterm.Error.Printfln("Unable to load connector registry '%s'", flagConnectorReg)
+					return err
+				}
+				connectorReg = &reg
+			}
+			if flagIngress == local.IngressNone && flagDemo {
+				pterm.Error.Println("Creating the demo connection requires an ingress controller")
+				return fmt.Errorf("--demo is not supported with the ingress controller %s", local.IngressNone)
+			}
+
+			// an existing cluster does not require docker, and its ingress is not bound to a port on this machine
+			if provider.Name == k8s.Existing {
+				if flagMigrate {
+					pterm.Error.Println("Migrating a docker compose installation is not supported with an existing cluster")
+					return fmt.Errorf("--migrate is not supported with the existing cluster %s", provider.ClusterName)
+				}
+				if kindFlagsChanged(cmd) {
+					pterm.Error.Println("The kind options are not supported with an existing cluster")
+					return fmt.Errorf("the --kind-*, --bind-address, and --image-cache flags are not supported with the existing cluster %s", provider.ClusterName)
+				}
+				if cmd.Flags().Changed("host-alias") || cmd.Flags().Changed("dns-server") || cmd.Flags().Changed("host-gateway") {
+					pterm.Error.Println("The DNS of an existing cluster is not managed by abctl")
+					return fmt.Errorf("the --host-alias, --dns-server, and --host-gateway flags are not supported with the existing cluster %s", provider.ClusterName)
+				}
+				return nil
+			}
+
+			if flagKindConfig != "" {
+				spinner.UpdateText(fmt.Sprintf("Validating kind config '%s'", flagKindConfig))
+				if _, err := k8s.ReadKindConfig(flagKindConfig); err != nil {
+					pterm.Error.Printfln("Invalid kind config '%s'", flagKindConfig)
+					return err
+				}
+			}
+
+			if flagKindIPFamily != "" {
+				if err := k8s.ValidIPFamily(flagKindIPFamily); err != nil {
+					pterm.Error.Printfln("Invalid --kind-ip-family '%s'", flagKindIPFamily)
+					return err
+				}
+			}
+
+			if flagBindAddress != "" {
+				if err := k8s.ValidBindAddress(flagBindAddress); err != nil {
+					pterm.Error.Printfln("Invalid --bind-address '%s'", flagBindAddress)
+					return err
+				}
+				// the ingress only serves its hosts, which must resolve to the bind address to access Airbyte
+				if dial := k8s.DialAddress(flagBindAddress); !cmd.Flags().Changed("host") && dial != "localhost" && !net.ParseIP(dial).IsLoopback() {
+					pterm.Warning.Printfln("Airbyte is only accessible on %s, provide --host with a hostname resolving to it to access Airbyte from a browser", flagBindAddress)
+				}
+			}
+
 			spinner.UpdateText("Checking for Docker installation")
 
 			dockerVersion, err := dockerInstalled(cmd.Context())
@@ -46,15 +296,44 @@ func NewCmdInstall(provider k8s.Provider) *cobra.Command {
 			telClient.Attr("docker_version", dockerVersion.Version)
 			telClient.Attr("docker_arch", dockerVersion.Arch)
 			telClient.Attr("docker_platform", dockerVersion.Platform)
+			dockerDesktop = strings.Contains(dockerVersion.Platform, "Docker Desktop")
+
+			if remote := remoteDockerHost(); remote != "" {
+				pterm.Info.Printfln("Using remote Docker host '%s', the cluster will be created on the remote host", remote)
+				if flagMigrate {
+					pterm.Error.Println("Migrating a docker compose installation is not supported with a remote Docker host")
+					return fmt.Errorf("--migrate is not supported with the remote docker host %s", remote)
+				}
+				// the remote host must be used to access the ingress, unless other hosts were explicitly requested
+				if !cmd.Flags().Changed("host") {
+					flagHosts = []string{remote}
+				}
+
+				spinner.UpdateText(fmt.Sprintf("Checking if port %d is available on %s", flagPort, remote))
+				if err := remotePortAvailable(cmd.Context(), remote, flagPort); err != nil {
+					return fmt.Errorf("port %d is not available: %w", flagPort, err)
+				}
+
+				pterm.Info.Printfln("Ensure port %d, and the Kubernetes API server port, are accessible on '%s' from this machine", flagPort, remote)
+				return nil
+			}
 
 			spinner.UpdateText(fmt.Sprintf("Checking if port %d is available", flagPort))
-			if err := portAvailable(cmd.Context(), flagPort); err != nil {
+			if err := portAvailable(cmd.Context(), flagBindAddress, flagPort); err != nil {
 				return fmt.Errorf("port %d is not available: %w", flagPort, err)
 			}
+
+			firewallGuidance(cmd.Context(), flagHosts, flagPort)
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return telemetry.Wrapper(cmd.Context(), telemetry.Install, func() error {
+				unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+				if err != nil {
+					return err
+				}
+				defer unlock()
+
 				spinner.UpdateText(fmt.Sprintf("Checking for existing Kubernetes cluster '%s'", provider.ClusterName))
 
 				cluster, err := provider.Cluster()
@@ -63,15 +342,36 @@ func NewCmdInstall(provider k8s.Provider) *cobra.Command {
 					return err
 				}
 
+				var st state.State
+
 				if cluster.Exists() {
 					// existing cluster, validate it
 					pterm.Success.Printfln("Existing cluster '%s' found", provider.ClusterName)
+					if provider.Name == k8s.Kind && !k8s.ContextExists(provider.KubeconfigPath(paths.UserHome), provider.Context) {
+						pterm.Error.Printfln("The context '%s' of cluster '%s' is missing from the kubeconfig, run 'abctl local repair' to restore it", provider.Context, provider.ClusterName)
+						return fmt.Errorf("%w: context %s not found", localerr.ErrKubernetes, provider.Context)
+					}
 					spinner.UpdateText(fmt.Sprintf("Validating existing cluster '%s'", provider.ClusterName))
 
+					if st, err = state.Load(statePath(*provider, flagNamespace)); err != nil {
+						pterm.Warning.Printfln("Unable to determine which version of abctl last modified this installation")
+						logging.Debugf("could not load state: %s", err)
+					}
+					if err := st.Check(build.Version); err != nil {
+						pterm.Error.Printfln("Cluster '%s' was last modified by a newer version of abctl", provider.ClusterName)
+						return err
+					}
+					// the api port of the existing installation is kept, unless another one was requested
+					if !cmd.Flags().Changed("api-port") && st.APIPort != 0 {
+						flagAPIPort = st.APIPort
+					}
+					// the ports of the existing cluster remain bound to the address it was created with
+					flagBindAddress = st.BindAddress
+
 					// only for kind do we need to check the existing port
 					if provider.Name == k8s.Kind {
 						if dockerClient == nil {
-							dockerClient, err = docker.New(cmd.Context())
+							dockerClient, err = newDockerClient(cmd.Context())
 							if err != nil {
 								pterm.Error.Printfln("Could not connect to Docker daemon")
 								return fmt.Errorf("could not connect to docker: %w", err)
@@ -80,6 +380,19 @@ func NewCmdInstall(provider k8s.Provider) *cobra.Command {
 
 						providedPort := flagPort
 						flagPort, err = dockerClient.Port(cmd.Context(), fmt.Sprintf("%s-control-plane", provider.ClusterName))
+						if errors.Is(err, localerr.ErrClusterStopped) {
+							if !autoStart(flagAutoStart, provider.ClusterName) {
+								pterm.Error.Printfln("Cluster '%s' is stopped", provider.ClusterName)
+								return err
+							}
+							ctx, cancel := context.WithTimeout(cmd.Context(), startTimeout)
+							_, err := startCluster(ctx, provider, cluster, spinner)
+							cancel()
+							if err != nil {
+								return err
+							}
+							flagPort, err = dockerClient.Port(cmd.Context(), fmt.Sprintf("%s-control-plane", provider.ClusterName))
+						}
 						if err != nil {
 							pterm.Warning.Printfln("Unable to determine which port the existing cluster was configured to use.\n" +
 								"Installation will continue but may ultimately fail, in which case it will be necessarily to uninstall first.")
@@ -90,41 +403,302 @@ func NewCmdInstall(provider k8s.Provider) *cobra.Command {
 							pterm.Warning.Printfln("The existing cluster was found to be using port %d, which differs from the provided port %d.\n"+
 								"The existing port will be used, as changing ports currently requires the existing installation to be uninstalled first.", flagPort, providedPort)
 						}
+
+						// the port mappings of a kind cluster cannot be changed once it is created
+						if flagAPIPort != 0 {
+							mapped, err := dockerClient.PortMapped(cmd.Context(), fmt.Sprintf("%s-control-plane", provider.ClusterName), flagAPIPort)
+							if err != nil {
+								pterm.Error.Printfln("Unable to determine if api port %d is mapped by the existing cluster", flagAPIPort)
+								return err
+							}
+							if !mapped {
+								pterm.Error.Printfln("The existing cluster was created without api port %d.\n"+
+									"Exposing the api on another port currently requires the existing installation to be uninstalled first.", flagAPIPort)
+								return fmt.Errorf("api port %d is not mapped by cluster %s", flagAPIPort, provider.ClusterName)
+							}
+						}
+					}
+
+					if provider.Name == k8s.Kind && cmd.Flags().Changed("ca-cert") && flagCACert != st.CACert {
+						pterm.Warning.Printfln("The CA certificate is only trusted by the kind node when the cluster is created, images are pulled without it.\n" +
+							"It is still trusted by the chart downloads and the components of Airbyte.")
+					}
+
+					if kindFlagsChanged(cmd) {
+						pterm.Warning.Printfln("The kind options only apply when the cluster is created and will be ignored.\n" +
+							"Changing them currently requires the existing installation to be uninstalled first.")
 					}
 
 					pterm.Success.Printfln("Cluster '%s' validation complete", provider.ClusterName)
+				} else if provider.Name == k8s.Existing {
+					pterm.Error.Printfln("Context '%s' does not exist in kubeconfig '%s'", provider.Context, provider.Kubeconfig)
+					return fmt.Errorf("%w: context %s not found", localerr.ErrKubernetes, provider.Context)
 				} else {
 					// no existing cluster, need to create one
+					mounts, err := k8s.ParseMounts(flagKindMounts)
+					if err != nil {
+						pterm.Error.Println("Invalid --kind-extra-mounts")
+						return err
+					}
+
+					kindConfig, err := loadKindConfig(flagKindConfig, cmd.Flags().Changed("kind-config"))
+					if err != nil {
+						return err
+					}
+
+					// the api server port of the remote host cannot be checked, and the ip family of the remote host is unknown
+					if remoteDockerHost() == "" {
+						if flagKindAPIPort != 0 {
+							spinner.UpdateText(fmt.Sprintf("Checking if Kubernetes API server port %d is available", flagKindAPIPort))
+							if err := apiPortAvailable(flagKindAPIPort); err != nil {
+								return err
+							}
+						}
+
+						if flagAPIPort != 0 {
+							spinner.UpdateText(fmt.Sprintf("Checking if port %d is available", flagAPIPort))
+							if err := portAvailable(cmd.Context(), flagBindAddress, flagAPIPort); err != nil {
+								return fmt.Errorf("port %d is not available: %w", flagAPIPort, err)
+							}
+						}
+
+						if flagKindIPFamily == "" {
+							if flagKindIPFamily = detectIPFamily(); flagKindIPFamily != "" {
+								pterm.Info.Printfln("Detected an IPv6-only host, the cluster will use the '%s' ip family", flagKindIPFamily)
+							}
+						}
+					}
+
+					// the image cache stores the image layers on the docker host, which must be this machine
+					var registryMirrors []string
+					if flagImageCache {
+						if remote := remoteDockerHost(); remote != "" {
+							pterm.Warning.Printfln("The image cache is not supported with the remote docker host %s and will not be used", remote)
+						} else {
+							registryMirrors = append(registryMirrors, docker.CacheMirror)
+						}
+					}
+					if flagRegistryMirror != "" {
+						registryMirrors = append(registryMirrors, local.RegistryMirrorEndpoint(flagRegistryMirror))
+					}
+
+					// the ca certificate is mounted into the kind node, so it must be on the docker host
+					nodeCACert := flagCACert
+					if remote := remoteDockerHost(); remote != "" && nodeCACert != "" {
+						pterm.Warning.Printfln("The CA certificate is not trusted by the kind node with the remote docker host %s, images are pulled without it", remote)
+						nodeCACert = ""
+					}
+
 					pterm.Info.Println(fmt.Sprintf("No existing cluster found, cluster '%s' will be created", provider.ClusterName))
 					spinner.UpdateText(fmt.Sprintf("Creating cluster '%s'", provider.ClusterName))
-					if err := cluster.Create(flagPort); err != nil {
+					abandoned := "The cluster may be partially created, run 'abctl local uninstall' before installing again."
+					var cleanup shutdown.Cleanup
+					if term.IsTerminal(int(os.Stdin.Fd())) {
+						abandoned = "The partially created cluster will be deleted, if confirmed."
+						cleanup = deletePartialCluster(provider.ClusterName, cluster.Delete, spinner, ptermPrompter{})
+					}
+					done := shutdown.Track(fmt.Sprintf("Creating cluster '%s'", provider.ClusterName), abandoned, cleanup)
+					_, span := trace.NewSpan(cmd.Context(), "cluster create")
+					err = cluster.Create(flagPort, k8s.CreateOpts{
+						NodeImage:       flagKindNodeImage,
+						ExtraMounts:     mounts,
+						KindConfig:      kindConfig,
+						APIServerPort:   flagKindAPIPort,
+						BindAddress:     flagBindAddress,
+						IPFamily:        flagKindIPFamily,
+						RegistryMirrors: registryMirrors,
+						APIPort:         flagAPIPort,
+						CACert:          nodeCACert,
+					})
+					span.RecordError(err)
+					var createErr *k8s.CreateError
+					if errors.As(err, &createErr) {
+						span.AttachLog("kind", createErr.Log)
+					}
+					span.End()
+					done()
+					if err != nil {
 						pterm.Error.Printfln("Cluster '%s' could not be created", provider.ClusterName)
+						if createErr != nil {
+							printCreateDiagnosis(k8s.DiagnoseCreate(createErr.Log))
+						}
+						networkGuidance(cmd.Context(), spinner, func(ctx context.Context) []netdiag.Finding {
+							return netdiag.New().Run(ctx)
+						})
 						return err
 					}
 					pterm.Success.Printfln("Cluster '%s' created", provider.ClusterName)
+
+					if len(registryMirrors) > 0 && registryMirrors[0] == docker.CacheMirror {
+						spinner.UpdateText("Starting the image cache")
+						if err := dockerClient.StartCache(cmd.Context(), paths.Cache, progress.Spinner(spinner, "Starting the image cache")); err != nil {
+							pterm.Warning.Println("Unable to start the image cache, images will be pulled without it")
+							logging.Debugf("could not start image cache: %s", err)
+						} else {
+							pterm.Info.Printfln("Image cache started, cached images are stored in %s", paths.Cache)
+						}
+					}
+
+					// store the kind config so the cluster is created with the same config when installing again
+					if flagKindConfig != "" {
+						if err := k8s.SaveKindConfig(paths.KindConfig, kindConfig); err != nil {
+							pterm.Warning.Printfln("Unable to store the kind config, it must be provided when installing again")
+							logging.Debugf("could not save kind config: %s", err)
+						}
+					}
+				}
+
+				// the ingress controller of the existing installation is kept, unless another one was requested
+				ingressRequested := cmd.Flags().Changed("ingress-controller") || flagSkipNginx
+				if !ingressRequested && st.IngressController != "" {
+					flagIngress = st.IngressController
+				}
+				if flagIngress == local.IngressNone && !cmd.Flags().Changed("ingress-class") && st.IngressClass != "" {
+					flagIngressClass = st.IngressClass
+				}
+				// the ca certificate of the existing installation is kept, unless another one (or none) was requested
+				if !cmd.Flags().Changed("ca-cert") {
+					flagCACert = st.CACert
+				}
+				// as are the host aliases and dns servers, the dns config is only changed if any are provided or kept
+				dnsChanged := cmd.Flags().Changed("host-alias") || cmd.Flags().Changed("dns-server") || cmd.Flags().Changed("host-gateway")
+				if !cmd.Flags().Changed("host-alias") {
+					flagHostAliases = st.HostAliases
+				}
+				if !cmd.Flags().Changed("dns-server") {
+					flagDNSServers = st.DNSServers
+				}
+				if !cmd.Flags().Changed("host-gateway") {
+					flagHostGateway = st.HostGateway
+				}
+				// as are the disabled components, unless others (or none) were requested
+				if !cmd.Flags().Changed("disable") {
+					flagDisabled = st.Disabled
+				}
+				if !cmd.Flags().Changed("low-resource") {
+					flagLowResource = st.LowResource
+				}
+				// as are the job concurrency and resource values
+				for name, value := range st.Tuning {
+					if !cmd.Flags().Changed(name) {
+						if err := cmd.Flags().Set(name, value); err != nil {
+							pterm.Warning.Printfln("Unable to keep the --%s of the existing installation", name)
+							logging.Debugf("could not set flag %s to %s: %s", name, value, err)
+						}
+					}
+				}
+				if err := flagTuning.Validate(); err != nil {
+					pterm.Error.Println("Invalid job concurrency or resource values of the existing installation")
+					return err
+				}
+				// the jobs of the kind cluster are scheduled onto the docker host, the nodes of an existing cluster are unknown
+				if provider.Name == k8s.Kind {
+					if res, err := dockerClient.Resources(cmd.Context()); err != nil {
+						pterm.Warning.Println("Unable to determine the resources of the docker host, the job resources are not validated")
+						logging.Debugf("could not determine docker resources: %s", err)
+					} else if err := flagTuning.CheckResources(res); err != nil {
+						pterm.Error.Println("The job resources exceed the resources of the docker host")
+						return err
+					}
+				}
+				// the monitoring requires the metrics published by Airbyte
+				if flagMonitoring && slices.Contains(flagDisabled, local.ComponentMetrics) {
+					pterm.Error.Printfln("The monitoring requires the %s component", local.ComponentMetrics)
+					return fmt.Errorf("--monitoring is not supported with --disable %s", local.ComponentMetrics)
+				}
+				installedIngress := st.IngressController
+				if !cluster.Exists() {
+					installedIngress = flagIngress
+				}
+				// the api port is exposed by a tcp service of nginx
+				if flagAPIPort != 0 && flagIngress != local.IngressNginx {
+					pterm.Error.Printfln("Exposing the api on its own port requires the %s ingress controller", local.IngressNginx)
+					return fmt.Errorf("--api-port is not supported with the ingress controller %s", flagIngress)
 				}
 
-				lc, err := local.New(provider,
+				lc, err := local.New(*provider,
 					local.WithPortHTTP(flagPort),
 					local.WithTelemetryClient(telClient),
 					local.WithSpinner(spinner),
+					local.WithNamespace(flagNamespace),
+					local.WithIngressController(installedIngress),
+					local.WithAPIPort(flagAPIPort),
+					local.WithBindAddress(flagBindAddress),
+					local.WithValuesDiff(valuesDiff(flagShowDiff, flagNoDiff)),
+					local.WithCACert(flagCACert),
 				)
 				if err != nil {
 					pterm.Error.Printfln("Failed to initialize 'local' command")
 					return fmt.Errorf("could not initialize local command: %w", err)
 				}
 
+				// an ingress controller of the cluster serves Airbyte instead of installing nginx, which would conflict
+				// with it, unless an ingress controller was requested or is required by any other option
+				autoIngress := !ingressRequested && st.IngressController == "" && flagAPIPort == 0 && flagConnectorReg == "" && !flagDemo
+				if flagIngressClass == "" && (flagSkipNginx || autoIngress) {
+					spinner.UpdateText("Checking for an existing ingress controller")
+					class, err := lc.ExistingIngressClass(cmd.Context())
+					switch {
+					case err != nil:
+						pterm.Warning.Println("Unable to determine if the cluster has an existing ingress controller")
+						logging.Debugf("could not determine existing ingress class: %s", err)
+					case class != "":
+						pterm.Info.Printfln("Using the existing ingress class '%s' instead of installing an ingress controller.\n"+
+							"Provide --ingress-controller %s to install it regardless.", class, local.IngressNginx)
+						flagIngress = local.IngressNone
+						flagIngressClass = class
+					case flagSkipNginx:
+						pterm.Warning.Println("No existing ingress controller was found, the Ingress is only served by the default ingress class of the cluster, if any")
+					}
+				}
+
 				opts := local.InstallOpts{
-					User:             flagUsername,
-					Pass:             flagPassword,
-					HelmChartVersion: flagChartVersion,
-					ValuesFile:       flagChartValuesFile,
-					Migrate:          flagMigrate,
-					Docker:           dockerClient,
+					User:                flagUsername,
+					Pass:                flagPassword,
+					HelmChartVersion:    chartVersion(flagChartVersion),
+					AirbyteChartLoc:     chartLoc,
+					ChartAuth:           local.RegistryAuth{Username: flagChartUsername, Password: flagChartPassword},
+					ValuesFile:          flagChartValuesFile,
+					Migrate:             flagMigrate,
+					Docker:              dockerClient,
+					Hosts:               flagHosts,
+					ConnectorRegistry:   connectorReg,
+					Download:            &local.DownloadOpts{Dir: filepath.Join(paths.AbCtl, "charts"), MaxRate: maxDownloadRate, NoCache: flagNoCache, Keyring: flagChartKeyring, InsecureSkipVerify: flagInsecureSkip},
+					StorageClass:        flagStorageClass,
+					Monitoring:          flagMonitoring,
+					LogAggregation:      flagLogAggregation,
+					LogRetention:        flagLogRetention,
+					RegistryMirror:      flagRegistryMirror,
+					IngressController:   flagIngress,
+					IngressClass:        flagIngressClass,
+					ReadyTimeout:        flagReadyTimeout,
+					Demo:                flagDemo,
+					SkipK8sVersionCheck: flagSkipK8sVersion,
+					ExtraManifests:      flagExtraManifests,
+					VolumeMounts:        volumeMounts,
+					Disabled:            flagDisabled,
+					LowResource:         flagLowResource,
+					Tuning:              flagTuning,
 				}
 
-				if opts.HelmChartVersion == "latest" {
-					opts.HelmChartVersion = ""
+				// the gateway of the kind network may change whenever the cluster is created, so it is not stored as an
+				// alias, but determined by every installation
+				hostAliases := flagHostAliases
+				var hostGateway string
+				if flagHostGateway && !dockerDesktop && provider.Name == k8s.Kind {
+					spinner.UpdateText("Determining the gateway of the cluster")
+					if hostGateway, err = dockerClient.Gateway(cmd.Context(), fmt.Sprintf("%s-control-plane", provider.ClusterName), "kind"); err != nil {
+						pterm.Warning.Printfln("Unable to determine the gateway of the cluster, %s will not resolve to this machine", local.HostGatewayName)
+						logging.Debugf("could not determine gateway: %s", err)
+					} else if !hostAliased(hostAliases, local.HostGatewayName) {
+						hostAliases = append(hostAliases[:len(hostAliases):len(hostAliases)], fmt.Sprintf("%s=%s", local.HostGatewayName, hostGateway))
+					}
+				}
+
+				if dnsChanged || len(hostAliases) > 0 || len(flagDNSServers) > 0 {
+					if opts.DNS, err = dnsOpts(hostAliases, flagDNSServers); err != nil {
+						return err
+					}
 				}
 
 				if env := os.Getenv(envBasicAuthUser); env != "" {
@@ -137,9 +711,52 @@ func NewCmdInstall(provider k8s.Provider) *cobra.Command {
 
 				if err := lc.Install(cmd.Context(), opts); err != nil {
 					spinner.Fail("Unable to install Airbyte locally")
+					networkGuidance(cmd.Context(), spinner, lc.NetworkDiagnostics)
 					return err
 				}
 
+				if sso != nil {
+					if err := lc.ConfigureSSO(cmd.Context(), *sso, opts.Download); err != nil {
+						spinner.Fail("Unable to configure SSO")
+						return err
+					}
+				}
+
+				// the credentials generated by the chart are printed once, on first install, only their secret is recorded
+				var generatedCreds *local.Credentials
+				if st.Credentials == nil {
+					if creds, err := lc.Credentials(cmd.Context()); err != nil {
+						logging.Debugf("could not fetch generated credentials: %s", err)
+					} else {
+						st.Credentials = &state.SecretRef{Namespace: flagNamespace, Name: local.CredentialsSecret}
+						generatedCreds = &creds
+					}
+				}
+
+				st.Touch(build.Version)
+				st.IngressController = flagIngress
+				st.IngressClass = flagIngressClass
+				st.APIPort = flagAPIPort
+				st.BindAddress = flagBindAddress
+				st.CACert = flagCACert
+				st.HostAliases = flagHostAliases
+				st.DNSServers = flagDNSServers
+				st.HostGateway = flagHostGateway
+				st.Disabled = flagDisabled
+				st.LowResource = flagLowResource
+				st.Tuning = tuningFlags(cmd)
+				if err := state.Save(statePath(*provider, flagNamespace), st); err != nil {
+					pterm.Warning.Printfln("Unable to record the version of abctl used for this installation")
+					logging.Debugf("could not save state: %s", err)
+				}
+
+				if flagHostGateway {
+					printHostGateway(hostGateway)
+				}
+				if generatedCreds != nil {
+					printGeneratedCredentials(*generatedCreds)
+				}
+
 				spinner.Success("Airbyte installation complete")
 				return nil
 			})
@@ -147,12 +764,237 @@ func NewCmdInstall(provider k8s.Provider) *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&flagUsername, "username", "u", "airbyte", "basic auth username, can also be specified via "+envBasicAuthUser)
-	cmd.Flags().StringVarP(&flagPassword, "password", "p", "password", "basic auth password, can also be specified via "+envBasicAuthPass)
+	cmd.Flags().StringVarP(&flagPassword, "password", "p", "password", "basic auth password, or a secret reference (vault://path#key or aws-sm://name[#key]), can also be specified via "+envBasicAuthPass)
 	cmd.Flags().IntVar(&flagPort, "port", local.Port, "ingress http port")
+	cmd.Flags().StringSliceVar(&flagHosts, "host", []string{local.DefaultHost}, "ingress http host(s) Airbyte is accessible from, replacing localhost unless it is included, e.g. --host localhost,airbyte.lan to also access Airbyte from other machines")
 
 	cmd.Flags().StringVar(&flagChartVersion, "chart-version", "latest", "specify the Airbyte helm chart version to install")
-	cmd.Flags().StringVar(&flagChartValuesFile, "values", "", "the Airbyte helm chart values file to load")
+	cmd.Flags().StringVar(&flagChart, "chart", "", "install the Airbyte helm chart from a local chart directory or archive, an http(s) url, or an oci:// reference, optionally pinned to a digest (oci://host/repo/airbyte@sha256:<digest>), instead of the Airbyte helm repository")
+	cmd.Flags().StringVar(&flagChartUsername, "chart-username", "", "the username of the oci registry of --chart, defaults to the credentials of 'helm registry login' or 'docker login'")
+	cmd.Flags().StringVar(&flagChartPassword, "chart-password", "", "the password of the oci registry of --chart (also ABCTL_LOCAL_INSTALL_CHART_PASSWORD)")
+	cmd.Flags().StringVar(&flagChartValuesFile, "values", "", "the Airbyte helm chart values file to load, whose values may be secret references (vault://path#key or aws-sm://name[#key]) resolved on every install")
 	cmd.Flags().BoolVar(&flagMigrate, "migrate", false, "migrate data from docker compose installation")
+	cmd.Flags().BoolVar(&flagAutoStart, "auto-start", false, "start the existing cluster if it is stopped, instead of prompting")
+	cmd.Flags().StringVar(&flagConnectorReg, "connector-registry", "", "url or file of a connector registry containing custom connector definitions to create once installed")
+	cmd.Flags().BoolVar(&flagDemo, "demo", false, "create a demo connection from a Faker source to the bundled postgres once installed, and start its first sync")
+	cmd.Flags().StringVar(&flagMaxDownloadRate, "max-download-rate", "", "limit the helm chart download rate, e.g. 500K or 2M (bytes per second), unlimited by default")
+	cmd.Flags().StringVar(&flagKindNodeImage, "kind-node-image", "", "the kind node image of the created cluster, e.g. a custom or arm64 specific kindest/node image")
+	cmd.Flags().StringSliceVar(&flagKindMounts, "kind-extra-mounts", nil, "additional directories to mount into the created kind node, as host-path:container-path[:ro]")
+	cmd.Flags().StringSliceVar(&flagVolumeMounts, "volume-mount", nil, "additional volumes to mount into a component of Airbyte (e.g. worker or workload-launcher), as component:type[:source]:mount-path[:ro], where type is secret or configmap (source is its name), hostpath (source is a path within the kind node), or emptydir (no source)")
+	cmd.Flags().StringSliceVar(&flagHostAliases, "host-alias", nil, "hostnames the pods of the created kind cluster (including the connector jobs) resolve to an ip, as host=ip, kept for subsequent installations unless provided")
+	cmd.Flags().StringSliceVar(&flagDNSServers, "dns-server", nil, "dns servers the created kind cluster forwards queries to instead of the dns of the docker host, as [zone=]ip[:port] where a zone only forwards its queries, kept for subsequent installations unless provided")
+	cmd.Flags().BoolVar(&flagHostGateway, "host-gateway", false, "resolve host.docker.internal to this machine within the created kind cluster (as Docker Desktop does), so connectors can connect to the services of this machine, kept for subsequent installations unless provided")
+	cmd.Flags().StringVar(&flagKindConfig, "kind-config", "", "kind config file merged with the config of the created kind cluster, reused for subsequent installations (an empty value reverts to the default config)")
+	cmd.Flags().BoolVar(&flagImageCache, "image-cache", false, "cache the images pulled by the created kind cluster in ~/.airbyte/abctl/cache, so they are not downloaded again when the cluster is recreated")
+	cmd.Flags().IntVar(&flagAPIPort, "api-port", 0, "http port exposing the Airbyte api (airbyte-server) directly, in addition to the webapp on --port, kept for subsequent installations unless provided (disabled by default)")
+	cmd.Flags().DurationVar(&flagReadyTimeout, "ready-timeout", local.DefaultReadyTimeout, "how long to wait for the server, temporal, and the webapp to become ready once Airbyte is installed")
+	cmd.Flags().StringVar(&flagIngress, "ingress-controller", local.IngressNginx, "the ingress controller installed in front of Airbyte, one of nginx, traefik, or none (to use an ingress controller of your own), kept for subsequent installations unless provided")
+	cmd.Flags().BoolVar(&flagSkipNginx, "skip-nginx", false, "install no ingress controller, serving Airbyte by the existing ingress controller of the cluster instead (the default for a new installation into a cluster which has one)")
+	cmd.Flags().StringVar(&flagIngressClass, "ingress-class", "", "the ingress class of the existing ingress controller serving Airbyte without an ingress controller of abctl (defaults to the detected ingress class)")
+	cmd.Flags().StringVar(&flagRegistryMirror, "registry-mirror", "", "a registry host (e.g. my.registry.example.com) mirroring docker hub and the chart images, which every image is pulled from instead")
+	cmd.Flags().IntVar(&flagKindAPIPort, "kind-api-port", 0, "the Kubernetes API server port of the created kind cluster (default chosen by kind)")
+	cmd.Flags().StringVar(&flagKindIPFamily, "kind-ip-family", "", "the ip family of the created kind cluster, one of ipv4, ipv6, or dual (default ipv6 on IPv6-only hosts, otherwise ipv4)")
+	cmd.Flags().StringVar(&flagBindAddress, "bind-address", "", "the host address (ipv4 or ipv6) the ingress http port of the created kind cluster is bound to, and which the ingress is verified on (default all addresses)")
+	cmd.Flags().StringVar(&flagStorageClass, "storage-class", "", "the storage class of the persistent volumes (defaults to \"standard\", or the default storage class of an existing cluster)")
+	cmd.Flags().BoolVar(&flagNoCache, "no-cache", false, "always download the helm charts again, instead of using the previously downloaded charts when they are unchanged or the helm repository is unavailable")
+	cmd.Flags().StringVar(&flagChartKeyring, "chart-keyring", "", "path of a PGP keyring used to verify the provenance (.prov) of the airbyte helm chart, only the chart digest is verified by default")
+	cmd.Flags().BoolVar(&flagShowDiff, "show-diff", false, "when upgrading, display the changes of the default values between the chart versions, in addition to the changes of the values")
+	cmd.Flags().BoolVar(&flagNoDiff, "no-diff", false, "when upgrading, do not display the changes of the values of the helm releases")
+	cmd.Flags().BoolVar(&flagSkipK8sVersion, "skip-k8s-version-check", false, "install onto a cluster whose kubernetes version is older than the Airbyte helm chart supports, instead of failing")
+	cmd.Flags().StringVar(&flagExtraManifests, "extra-manifests", "", "a manifest file, directory of manifest files, or kustomization directory, whose objects (e.g. NetworkPolicies or ConfigMaps) are applied once Airbyte is installed, and deleted once removed from it or when Airbyte is uninstalled")
+	cmd.Flags().StringVar(&flagCACert, "ca-cert", "", "a PEM bundle of CA certificates (e.g. of a TLS-intercepting proxy) trusted by the created kind node, the helm chart downloads, and the components of Airbyte, kept for subsequent installations unless provided (an empty value removes it)")
+	cmd.Flags().BoolVar(&flagInsecureSkip, "insecure-skip-verify", false, "install helm charts which cannot be verified, because they have no digest, a mismatched digest, or an invalid provenance")
+	cmd.Flags().BoolVar(&flagMonitoring, "monitoring", false, "install prometheus and grafana to monitor Airbyte, see 'abctl local monitoring open'")
+	cmd.Flags().StringSliceVar(&flagDisabled, "disable", nil, fmt.Sprintf("optional components of Airbyte which are not installed, any of %s, kept for subsequent installations unless provided", strings.Join(local.OptionalComponents(), ", ")))
+	cmd.Flags().BoolVar(&flagLowResource, "low-resource", false, "install Airbyte for machines with limited memory (e.g. 8GB), disabling the connector-builder and temporal-ui components and running connectors with smaller resource requests, kept for subsequent installations unless provided")
+	cmd.Flags().IntVar(&flagTuning.MaxSyncWorkers, "max-sync-workers", 0, "the maximum number of syncs each worker runs concurrently (default chosen by the chart)")
+	cmd.Flags().IntVar(&flagTuning.WorkerReplicas, "worker-replicas", 0, "the number of replicas of the worker (default chosen by the chart)")
+	cmd.Flags().StringVar(&flagTuning.JobCPURequest, "job-cpu-request", "", "the cpu request of the connector jobs, e.g. 500m or 1 (default chosen by the chart)")
+	cmd.Flags().StringVar(&flagTuning.JobCPULimit, "job-cpu-limit", "", "the cpu limit of the connector jobs, e.g. 2 (default chosen by the chart)")
+	cmd.Flags().StringVar(&flagTuning.JobMemoryRequest, "job-memory-request", "", "the memory request of the connector jobs, e.g. 512Mi or 1Gi (default chosen by the chart)")
+	cmd.Flags().StringVar(&flagTuning.JobMemoryLimit, "job-memory-limit", "", "the memory limit of the connector jobs, e.g. 2Gi (default chosen by the chart)")
+	cmd.Flags().BoolVar(&flagLogAggregation, "log-aggregation", false, "install loki to retain the logs of every pod, see 'abctl local logs'")
+	cmd.Flags().DurationVar(&flagLogRetention, "log-retention", local.DefaultLogRetention, "how long the aggregated logs are retained, a multiple of 24h")
+	cmd.Flags().BoolVar(&flagInteractive, "interactive", false, "prompt for the main installation options (port, hosts, edition, storage, values file, and add-ons) not provided as flags")
 
 	return cmd
 }
+
+// valuesDiff returns which differences are displayed when the helm releases are upgraded.
+func valuesDiff(show, hide bool) string {
+	switch {
+	case show:
+		return local.DiffAll
+	case hide:
+		return local.DiffNone
+	default:
+		return local.DiffValues
+	}
+}
+
+// loadKindConfig returns the custom kind config the cluster should be created with.
+//
+// If a path is provided, that kind config is used. Otherwise, any kind config stored by a previous installation is
+// used, unless the path was explicitly set to empty, in which case the stored kind config is removed.
+// Nil is returned if no custom kind config should be used.
+func loadKindConfig(path string, changed bool) ([]byte, error) {
+	if path != "" {
+		data, err := k8s.ReadKindConfig(path)
+		if err != nil {
+			pterm.Error.Printfln("Invalid kind config '%s'", path)
+			return nil, err
+		}
+		return data, nil
+	}
+
+	if changed {
+		if err := os.Remove(paths.KindConfig); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			pterm.Error.Printfln("Unable to remove the stored kind config '%s'", paths.KindConfig)
+			return nil, fmt.Errorf("could not remove kind config %s: %w", paths.KindConfig, err)
+		}
+		return nil, nil
+	}
+
+	if _, err := os.Stat(paths.KindConfig); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	data, err := k8s.ReadKindConfig(paths.KindConfig)
+	if err != nil {
+		pterm.Error.Printfln("Invalid stored kind config '%s', provide '--kind-config \"\"' to remove it", paths.KindConfig)
+		return nil, err
+	}
+	pterm.Info.Printfln("Using the kind config '%s' stored by a previous installation", paths.KindConfig)
+	return data, nil
+}
+
+// dnsOpts returns the DNSOpts of the --host-alias and --dns-server flags.
+func dnsOpts(aliases, servers []string) (*local.DNSOpts, error) {
+	var opts local.DNSOpts
+	for _, s := range aliases {
+		alias, err := local.ParseHostAlias(s)
+		if err != nil {
+			return nil, err
+		}
+		opts.HostAliases = append(opts.HostAliases, alias)
+	}
+	for _, s := range servers {
+		server, err := local.ParseDNSServer(s)
+		if err != nil {
+			return nil, err
+		}
+		opts.Servers = append(opts.Servers, server)
+	}
+	return &opts, nil
+}
+
+// hostAliased returns true if any of the host=ip aliases is for the host.
+func hostAliased(aliases []string, host string) bool {
+	for _, alias := range aliases {
+		if h, _, _ := strings.Cut(alias, "="); h == host {
+			return true
+		}
+	}
+	return false
+}
+
+// printHostGateway prints the host connectors use to connect to the services of this machine, and, if the host is
+// resolved to the gateway of the kind network, which address those services must listen on.
+func printHostGateway(gateway string) {
+	if gateway == "" {
+		pterm.Info.Printfln("Connectors can connect to the services of this machine via the host '%s'", local.HostGatewayName)
+		return
+	}
+	pterm.Info.Printfln("Connectors can connect to the services of this machine via the host '%s', which resolves to %s.\n"+
+		"The services must listen on %s (or all addresses), rather than only on 127.0.0.1, and be allowed by the firewall.",
+		local.HostGatewayName, gateway, gateway)
+}
+
+// printGeneratedCredentials prints the masked credentials generated by the first installation, and how to show them.
+func printGeneratedCredentials(creds local.Credentials) {
+	creds = creds.Masked()
+	pterm.Info.Printfln("Airbyte generated the credentials of the instance admin:\n"+
+		"  Password: %s\n  Client-Id: %s\n  Client-Secret: %s\n"+
+		"Run 'abctl local credentials --show' to print them, or 'abctl local credentials --copy' to copy the password.",
+		creds.Password, creds.ClientID, creds.ClientSecret)
+}
+
+// printCreateDiagnosis prints the lines of the kind output relevant to the failure to create the cluster, and how to
+// resolve their causes.
+func printCreateDiagnosis(d k8s.Diagnosis) {
+	if len(d.Lines) == 0 {
+		return
+	}
+	pterm.Println("The kind output ended with:\n  " + strings.Join(d.Lines, "\n  "))
+	for _, hint := range d.Hints {
+		pterm.Info.Println(hint)
+	}
+	pterm.Info.Println("The full kind output is included in the log file, and in the trace written by --trace-file.")
+}
+
+// networkGuidance runs the network diagnostics after a failed installation, printing any condition which may have
+// caused it. Nothing is checked if the installation was interrupted.
+func networkGuidance(ctx context.Context, spinner *pterm.SpinnerPrinter, diagnose func(context.Context) []netdiag.Finding) {
+	if ctx.Err() != nil {
+		return
+	}
+	spinner, _ = spinner.Start("Checking the network for known issues")
+	findings := diagnose(ctx)
+	if len(findings) == 0 {
+		spinner.Info("No known network issues found")
+		return
+	}
+	spinner.Warning("Found network issues which may have caused the failure")
+	printFindings(findings)
+}
+
+// tuningFlagNames are the flags of the job concurrency and resource values, which are kept for subsequent
+// installations.
+var tuningFlagNames = []string{"max-sync-workers", "worker-replicas", "job-cpu-request", "job-cpu-limit", "job-memory-request", "job-memory-limit"}
+
+// tuningFlags returns the values of the tuning flags which differ from their defaults.
+func tuningFlags(cmd *cobra.Command) map[string]string {
+	values := map[string]string{}
+	for _, name := range tuningFlagNames {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Value.String() != f.DefValue {
+			values[name] = f.Value.String()
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// kindFlagsChanged returns true if any of the flags which only apply when a kind cluster is created were provided.
+func kindFlagsChanged(cmd *cobra.Command) bool {
+	for _, name := range []string{"kind-node-image", "kind-extra-mounts", "kind-config", "kind-api-port", "kind-ip-family", "bind-address", "image-cache"} {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// deletePartialCluster returns a cleanup handler which deletes the cluster being created when abctl is interrupted,
+// if confirmed, as a partially created cluster fails the next installation.
+func deletePartialCluster(name string, deleteCluster func() error, spinner *pterm.SpinnerPrinter, p prompter) shutdown.Cleanup {
+	return func(ctx context.Context) error {
+		_ = spinner.Stop()
+		ok, err := p.Confirm(fmt.Sprintf("Delete the partially created cluster '%s'?", name), true)
+		if err != nil {
+			return fmt.Errorf("could not prompt for confirmation: %w", err)
+		}
+		if !ok {
+			pterm.Info.Println("The cluster was kept, run 'abctl local uninstall' before installing again")
+			return nil
+		}
+
+		pterm.Info.Printfln("Deleting cluster '%s'", name)
+		if err := deleteCluster(); err != nil {
+			return fmt.Errorf("could not delete cluster %s: %w", name, err)
+		}
+		pterm.Success.Printfln("Cluster '%s' deleted", name)
+		return nil
+	}
+}
@@ -0,0 +1,70 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pterm/pterm"
+	"testing"
+)
+
+func TestDeletePartialCluster(t *testing.T) {
+	tests := []struct {
+		name       string
+		confirmed  bool
+		deleteErr  error
+		expDeleted bool
+		expErr     bool
+	}{
+		{name: "confirmed", confirmed: true, expDeleted: true},
+		{name: "declined"},
+		{name: "delete failed", confirmed: true, deleteErr: errors.New("test"), expDeleted: true, expErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleted bool
+			p := &mockPrompter{t: t, answers: []mockAnswer{
+				{prompt: "Delete the partially created cluster 'airbyte-abctl'?", ok: tt.confirmed},
+			}}
+			cleanup := deletePartialCluster("airbyte-abctl", func() error {
+				deleted = true
+				return tt.deleteErr
+			}, &pterm.DefaultSpinner, p)
+
+			err := cleanup(context.Background())
+			if tt.expErr != (err != nil) {
+				t.Error("unexpected error", err)
+			}
+			if tt.expDeleted != deleted {
+				t.Errorf("expected deleted %t, got %t", tt.expDeleted, deleted)
+			}
+		})
+	}
+}
+
+func TestHostAliased(t *testing.T) {
+	aliases := []string{"db.internal=10.0.0.5", "host.docker.internal=192.168.1.10"}
+	for host, exp := range map[string]bool{"host.docker.internal": true, "db.internal": true, "docker.internal": false} {
+		if got := hostAliased(aliases, host); got != exp {
+			t.Errorf("expected %s aliased %t, got %t", host, exp, got)
+		}
+	}
+}
+
+func TestTuningFlags(t *testing.T) {
+	cmd := NewCmdInstall(&k8s.Provider{})
+	if values := tuningFlags(cmd); values != nil {
+		t.Error("expected no values, got", values)
+	}
+
+	for name, value := range map[string]string{"max-sync-workers": "4", "job-memory-limit": "2Gi", "job-cpu-request": ""} {
+		if err := cmd.Flags().Set(name, value); err != nil {
+			t.Fatal("could not set flag", err)
+		}
+	}
+	if d := cmp.Diff(map[string]string{"max-sync-workers": "4", "job-memory-limit": "2Gi"}, tuningFlags(cmd)); d != "" {
+		t.Error("values mismatch (-want +got):", d)
+	}
+}
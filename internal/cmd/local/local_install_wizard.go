@@ -0,0 +1,192 @@
+package local
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// installWizard prompts for the main decisions of an installation, which are applied to the flags of the install
+// command, and confirms the equivalent command before the installation proceeds.
+// Flags provided on the command line are not prompted for.
+// The single sign-on options are returned if the enterprise edition was chosen, otherwise nil.
+func installWizard(cmd *cobra.Command, provider k8s.Provider, p prompter) (*local.SSOOpts, error) {
+	flags := cmd.Flags()
+
+	if !flags.Changed("port") {
+		port, _ := flags.GetInt("port")
+		for {
+			value, err := p.Text("Ingress http port", strconv.Itoa(port))
+			if err != nil {
+				return nil, err
+			}
+			if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && n > 0 && n <= 65535 {
+				port = n
+				break
+			}
+			pterm.Warning.Printfln("Invalid port '%s', the port must be between 1 and 65535", value)
+		}
+		if err := flags.Set("port", strconv.Itoa(port)); err != nil {
+			return nil, err
+		}
+	}
+
+	if !flags.Changed("host") {
+		hosts, _ := flags.GetStringSlice("host")
+		value, err := p.Text("Ingress http host(s), comma separated", strings.Join(hosts, ","))
+		if err != nil {
+			return nil, err
+		}
+		if err := flags.Set("host", strings.ReplaceAll(value, " ", "")); err != nil {
+			return nil, err
+		}
+	}
+
+	edition, err := p.Select("Edition", []string{local.EditionCommunity, local.EditionEnterprise}, local.EditionCommunity)
+	if err != nil {
+		return nil, err
+	}
+
+	// the storage class of a created kind cluster is always the standard one
+	if provider.Name == k8s.Existing && !flags.Changed("storage-class") {
+		value, err := p.Text("Storage class of the persistent volumes (empty for the default storage class)", "")
+		if err != nil {
+			return nil, err
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			if err := flags.Set("storage-class", value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !flags.Changed("values") {
+		for {
+			value, err := p.Text("Helm chart values file (empty for none)", "")
+			if err != nil {
+				return nil, err
+			}
+			if value = strings.TrimSpace(value); value == "" {
+				break
+			}
+			if _, err := os.Stat(value); err == nil {
+				if err := flags.Set("values", value); err != nil {
+					return nil, err
+				}
+				break
+			}
+			pterm.Warning.Printfln("Values file '%s' does not exist", value)
+		}
+	}
+
+	for _, c := range []struct {
+		flag string
+		text string
+	}{
+		{flag: "monitoring", text: "Install prometheus and grafana to monitor Airbyte?"},
+		{flag: "log-aggregation", text: "Install loki to retain the logs of every pod?"},
+	} {
+		if flags.Changed(c.flag) {
+			continue
+		}
+		enabled, err := p.Confirm(c.text, false)
+		if err != nil {
+			return nil, err
+		}
+		if enabled {
+			if err := flags.Set(c.flag, "true"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var sso *local.SSOOpts
+	if edition == local.EditionEnterprise {
+		port, _ := flags.GetInt("port")
+		hosts, _ := flags.GetStringSlice("host")
+		sso = &local.SSOOpts{AirbyteURL: fmt.Sprintf("http://%s:%d", hosts[0], port)}
+		if err := promptSSO(p, sso); err != nil {
+			return nil, err
+		}
+		if err := sso.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	pterm.Info.Printfln("The equivalent command is:\n\n  %s\n", wizardCommand(cmd))
+	if sso != nil {
+		pterm.Info.Printfln("Followed by (the secrets are omitted):\n\n  %s\n", wizardSSOCommand(*sso))
+	}
+
+	ok, err := p.Confirm("Install Airbyte?", true)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("installation %w", localerr.ErrCancelled)
+	}
+	return sso, nil
+}
+
+// wizardCommand returns the command line equivalent to the provided flags of the command, with the password masked.
+func wizardCommand(cmd *cobra.Command) string {
+	args := []string{cmd.CommandPath()}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if f.Name == "interactive" {
+			return
+		}
+
+		value := f.Value.String()
+		if s, ok := f.Value.(pflag.SliceValue); ok {
+			value = strings.Join(s.GetSlice(), ",")
+		}
+		switch {
+		case f.Name == "password":
+			args = append(args, "--password ********")
+		case f.Value.Type() == "bool" && value == "true":
+			args = append(args, "--"+f.Name)
+		case f.Value.Type() == "bool":
+			args = append(args, fmt.Sprintf("--%s=%s", f.Name, value))
+		default:
+			args = append(args, fmt.Sprintf("--%s %s", f.Name, quoteArg(value)))
+		}
+	})
+	return strings.Join(args, " ")
+}
+
+// wizardSSOCommand returns the sso configure command equivalent to the options, without the secrets.
+func wizardSSOCommand(opts local.SSOOpts) string {
+	args := []string{"abctl local sso configure"}
+	for _, o := range []struct {
+		flag  string
+		value string
+	}{
+		{flag: "issuer", value: opts.Issuer},
+		{flag: "app-name", value: opts.AppName},
+		{flag: "client-id", value: opts.ClientID},
+		{flag: "airbyte-url", value: opts.AirbyteURL},
+		{flag: "admin-first-name", value: opts.AdminFirstName},
+		{flag: "admin-last-name", value: opts.AdminLastName},
+		{flag: "admin-email", value: opts.AdminEmail},
+	} {
+		if o.value != "" {
+			args = append(args, fmt.Sprintf("--%s %s", o.flag, quoteArg(o.value)))
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// quoteArg quotes the value if it is empty or contains characters interpreted by the shell.
+func quoteArg(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\"'$`\\*?&;|<>()") {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,100 @@
+package local
+
+import (
+	"errors"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+// mockPrompter answers every prompt with the next answer, failing the test if the prompt is unexpected.
+type mockPrompter struct {
+	t       *testing.T
+	answers []mockAnswer
+}
+
+type mockAnswer struct {
+	prompt string
+	text   string
+	ok     bool
+}
+
+func (m *mockPrompter) next(prompt string) mockAnswer {
+	if len(m.answers) == 0 {
+		m.t.Fatal("unexpected prompt", prompt)
+	}
+	a := m.answers[0]
+	m.answers = m.answers[1:]
+	if a.prompt != prompt {
+		m.t.Fatalf("expected prompt %q, got %q", a.prompt, prompt)
+	}
+	return a
+}
+
+func (m *mockPrompter) Text(prompt, _ string) (string, error) {
+	return m.next(prompt).text, nil
+}
+
+func (m *mockPrompter) Secret(prompt string) (string, error) {
+	return m.next(prompt).text, nil
+}
+
+func (m *mockPrompter) Select(prompt string, _ []string, _ string) (string, error) {
+	return m.next(prompt).text, nil
+}
+
+func (m *mockPrompter) Confirm(prompt string, _ bool) (bool, error) {
+	return m.next(prompt).ok, nil
+}
+
+func TestInstallWizard(t *testing.T) {
+	provider := k8s.TestProvider
+	cmd := NewCmdInstall(&provider)
+	if err := cmd.ParseFlags([]string{"--interactive", "--password", "secret", "--monitoring=false"}); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &mockPrompter{t: t, answers: []mockAnswer{
+		{prompt: "Ingress http port", text: "http"},
+		{prompt: "Ingress http port", text: "9000"},
+		{prompt: "Ingress http host(s), comma separated", text: "localhost, airbyte.lan"},
+		{prompt: "Edition", text: "community"},
+		{prompt: "Helm chart values file (empty for none)", text: ""},
+		{prompt: "Install loki to retain the logs of every pod?", ok: true},
+		{prompt: "Install Airbyte?", ok: true},
+	}}
+
+	sso, err := installWizard(cmd, provider, p)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if sso != nil {
+		t.Error("expected no sso options for the community edition")
+	}
+	if len(p.answers) > 0 {
+		t.Error("expected every prompt to be answered, remaining", p.answers)
+	}
+
+	exp := "install --host localhost,airbyte.lan --log-aggregation --monitoring=false --password ******** --port 9000"
+	if d := cmp.Diff(exp, wizardCommand(cmd)); d != "" {
+		t.Error("command mismatch (-want +got):", d)
+	}
+}
+
+func TestInstallWizard_Cancelled(t *testing.T) {
+	provider := k8s.TestProvider
+	cmd := NewCmdInstall(&provider)
+	if err := cmd.ParseFlags([]string{"--port", "9000", "--host", "localhost", "--values", "", "--monitoring", "--log-aggregation"}); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &mockPrompter{t: t, answers: []mockAnswer{
+		{prompt: "Edition", text: "community"},
+		{prompt: "Install Airbyte?", ok: false},
+	}}
+
+	if _, err := installWizard(cmd, provider, p); !errors.Is(err, localerr.ErrCancelled) {
+		t.Error("expected the installation to be cancelled, got", err)
+	}
+}
@@ -0,0 +1,89 @@
+package local
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/redact"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"time"
+)
+
+func NewCmdLogs(provider *k8s.Provider) *cobra.Command {
+	var (
+		flagQuery string
+		flagSince time.Duration
+		flagLimit int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Search the aggregated logs of local Airbyte",
+		Long: `Search the aggregated logs of local Airbyte, including the logs of pods which have since restarted or been removed.
+
+Log aggregation is installed by 'abctl local install --log-aggregation'.
+The --query is a LogQL query (https://grafana.com/docs/loki/latest/query/), e.g. '{app="server"} |= "error"'.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if flagLimit <= 0 {
+				return fmt.Errorf("limit must be greater than zero, received %d", flagLimit)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// the docker client must be created before the cluster, as it determines the docker host kind uses
+			var err error
+			if dockerClient == nil && provider.Name != k8s.Existing {
+				if dockerClient, err = newDockerClient(cmd.Context()); err != nil {
+					pterm.Error.Printfln("Could not connect to Docker daemon")
+					return fmt.Errorf("could not connect to docker: %w", err)
+				}
+			}
+
+			cluster, err := provider.Cluster()
+			if err != nil {
+				pterm.Error.Printfln("Could not determine status of any existing '%s' cluster", provider.ClusterName)
+				return err
+			}
+
+			if !cluster.Exists() {
+				pterm.Error.Println("Airbyte does not appear to be installed locally")
+				return fmt.Errorf("cluster '%s' does not exist", provider.ClusterName)
+			}
+
+			lc, err := local.New(*provider, local.WithTelemetryClient(telClient), local.WithSpinner(&pterm.SpinnerPrinter{}), local.WithNamespace(flagNamespace))
+			if err != nil {
+				pterm.Error.Printfln("Failed to initialize 'local' command")
+				return fmt.Errorf("could not initialize local command: %w", err)
+			}
+
+			entries, err := lc.Logs(cmd.Context(), local.LogsOpts{Query: flagQuery, Since: flagSince, Limit: flagLimit})
+			if err != nil {
+				pterm.Error.Println("Unable to search the aggregated logs")
+				return err
+			}
+
+			if len(entries) == 0 {
+				pterm.Info.Println("No logs found")
+				return nil
+			}
+			printLogs(os.Stdout, entries)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flagQuery, "query", "", "LogQL query selecting the logs (defaults to every log of Airbyte)")
+	cmd.Flags().DurationVar(&flagSince, "since", time.Hour, "only return logs newer than this duration ago")
+	cmd.Flags().IntVar(&flagLimit, "limit", 100, "the maximum number of the most recent logs returned")
+
+	return cmd
+}
+
+// printLogs writes a line of every log entry to w, with any secrets of the log line redacted.
+func printLogs(w io.Writer, entries []local.LogEntry) {
+	for _, e := range entries {
+		_, _ = fmt.Fprintf(w, "%s %s %s\n", e.Time.Local().Format(time.RFC3339), e.Labels["pod"], redact.String(e.Line))
+	}
+}
@@ -0,0 +1,26 @@
+package local
+
+import (
+	"bytes"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+func TestPrintLogs(t *testing.T) {
+	ts := time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)
+	entries := []local.LogEntry{
+		{Time: ts, Labels: map[string]string{"pod": "airbyte-abctl-server"}, Line: "connected to postgresql://airbyte:hunter2@db:5432/airbyte"},
+		{Time: ts, Labels: map[string]string{"pod": "airbyte-abctl-worker"}, Line: "started job 1"},
+	}
+
+	var buf bytes.Buffer
+	printLogs(&buf, entries)
+
+	exp := ts.Format(time.RFC3339) + " airbyte-abctl-server connected to postgresql://airbyte:REDACTED@db:5432/airbyte\n" +
+		ts.Format(time.RFC3339) + " airbyte-abctl-worker started job 1\n"
+	if d := cmp.Diff(exp, buf.String()); d != "" {
+		t.Error("output mismatch (-want +got):", d)
+	}
+}
@@ -0,0 +1,96 @@
+package local
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdMonitoring(provider *k8s.Provider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "monitoring",
+		Short: "Access the monitoring of local Airbyte",
+		Long: `Access the monitoring of local Airbyte.
+
+Monitoring is installed by 'abctl local install --monitoring'.`,
+	}
+
+	cmd.AddCommand(newCmdMonitoringOpen(provider))
+
+	return cmd
+}
+
+func newCmdMonitoringOpen(provider *k8s.Provider) *cobra.Command {
+	spinner := &pterm.DefaultSpinner
+
+	var flagHost string
+
+	cmd := &cobra.Command{
+		Use:   "open",
+		Short: "Open the monitoring dashboards in the web-browser",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ = spinner.Start(fmt.Sprintf("Checking for existing Kubernetes cluster '%s'", provider.ClusterName))
+
+			// the docker client must be created before the cluster, as it determines the docker host kind uses
+			var err error
+			if dockerClient == nil && provider.Name != k8s.Existing {
+				if dockerClient, err = newDockerClient(cmd.Context()); err != nil {
+					pterm.Error.Printfln("Could not connect to Docker daemon")
+					return fmt.Errorf("could not connect to docker: %w", err)
+				}
+			}
+
+			cluster, err := provider.Cluster()
+			if err != nil {
+				pterm.Error.Printfln("Could not determine status of any existing '%s' cluster", provider.ClusterName)
+				return err
+			}
+
+			if !cluster.Exists() {
+				spinner.Fail("Airbyte does not appear to be installed locally")
+				return fmt.Errorf("cluster '%s' does not exist", provider.ClusterName)
+			}
+
+			port := local.Port
+			// only for kind do we need to check the existing port
+			if provider.Name == k8s.Kind {
+				if port, err = dockerClient.Port(cmd.Context(), fmt.Sprintf("%s-control-plane", provider.ClusterName)); err != nil {
+					pterm.Error.Printfln("Could not determine docker port for cluster '%s'", provider.ClusterName)
+					return err
+				}
+			}
+
+			// the remote host must be used to access the ingress, unless another host was explicitly requested
+			if remote := remoteDockerHost(); remote != "" && !cmd.Flags().Changed("host") {
+				flagHost = remote
+			}
+
+			lc, err := local.New(*provider,
+				local.WithHost(flagHost),
+				local.WithPortHTTP(port),
+				local.WithTelemetryClient(telClient),
+				local.WithSpinner(spinner),
+				local.WithNamespace(flagNamespace),
+				installationOption(*provider, flagNamespace),
+			)
+			if err != nil {
+				pterm.Error.Printfln("Failed to initialize 'local' command")
+				return fmt.Errorf("could not initialize local command: %w", err)
+			}
+
+			if err := lc.OpenMonitoring(cmd.Context()); err != nil {
+				spinner.Fail("Unable to open monitoring")
+				return err
+			}
+
+			spinner.Success("Monitoring opened")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flagHost, "host", local.DefaultHost, "ingress http host the monitoring is accessed from")
+
+	return cmd
+}
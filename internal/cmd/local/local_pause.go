@@ -0,0 +1,119 @@
+package local
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+func NewCmdPause(provider *k8s.Provider) *cobra.Command {
+	spinner := &pterm.DefaultSpinner
+
+	var (
+		flagScaleDown bool
+		flagTimeout   time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:         "pause",
+		Annotations: audit.Annotations(),
+		Short:       "Pause local Airbyte",
+		Long: `Pause local Airbyte, stopping the cluster to free its resources without uninstalling Airbyte.
+
+Use 'abctl local resume' to start Airbyte again.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ = spinner.Start(fmt.Sprintf("Checking for existing Kubernetes cluster '%s'", provider.ClusterName))
+
+			if provider.Name == k8s.Existing {
+				spinner.Fail("Pausing is not supported with an existing cluster")
+				return fmt.Errorf("pause is not supported with the existing cluster %s", provider.ClusterName)
+			}
+
+			unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			// the docker client must be created before the cluster, as it determines the docker host kind uses
+			if dockerClient == nil {
+				if dockerClient, err = newDockerClient(cmd.Context()); err != nil {
+					pterm.Error.Printfln("Could not connect to Docker daemon")
+					return fmt.Errorf("could not connect to docker: %w", err)
+				}
+			}
+
+			cluster, err := provider.Cluster()
+			if err != nil {
+				pterm.Error.Printfln("Could not determine status of any existing '%s' cluster", provider.ClusterName)
+				return err
+			}
+
+			if !cluster.Exists() {
+				spinner.Fail("Airbyte does not appear to be installed locally")
+				return fmt.Errorf("cluster '%s' does not exist", provider.ClusterName)
+			}
+
+			if flagScaleDown {
+				lc, err := local.New(*provider, local.WithTelemetryClient(telClient), local.WithSpinner(spinner), local.WithNamespace(flagNamespace))
+				if err != nil {
+					pterm.Error.Printfln("Failed to initialize 'local' command")
+					return fmt.Errorf("could not initialize local command: %w", err)
+				}
+
+				st, err := state.Load(statePath(*provider, flagNamespace))
+				if err != nil {
+					pterm.Error.Println("Unable to load the installation state")
+					return err
+				}
+
+				replicas, err := lc.ScaleDown(cmd.Context())
+				// record whatever was scaled down, even on failure, so resume is able to scale it back up
+				if len(replicas) > 0 {
+					if st.Paused == nil {
+						st.Paused = map[string]int32{}
+					}
+					for workload, r := range replicas {
+						st.Paused[workload] = r
+					}
+					if err := state.Save(statePath(*provider, flagNamespace), st); err != nil {
+						pterm.Error.Println("Unable to record the scaled down Airbyte workloads")
+						return err
+					}
+				}
+				if err != nil {
+					spinner.Fail("Unable to scale down Airbyte")
+					return err
+				}
+			}
+
+			nodes, err := cluster.Nodes()
+			if err != nil {
+				pterm.Error.Printfln("Could not determine the nodes of cluster '%s'", provider.ClusterName)
+				return err
+			}
+
+			for _, node := range nodes {
+				spinner.UpdateText(fmt.Sprintf("Stopping node '%s'", node))
+				if err := dockerClient.Stop(cmd.Context(), node, flagTimeout); err != nil {
+					spinner.Fail(fmt.Sprintf("Unable to stop node '%s'", node))
+					return err
+				}
+			}
+
+			spinner.Success("Airbyte paused, run 'abctl local resume' to start it again")
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flagScaleDown, "scale-down", false, "scale down the Airbyte deployments before stopping the cluster, for a clean shutdown")
+	cmd.Flags().DurationVar(&flagTimeout, "timeout", time.Minute, "how long to wait for each node to stop before it is killed")
+
+	return cmd
+}
@@ -0,0 +1,169 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"os"
+	"strings"
+)
+
+func NewCmdRepair(provider *k8s.Provider) *cobra.Command {
+	spinner := &pterm.DefaultSpinner
+
+	var (
+		flagYes        bool
+		flagRemoveData bool
+	)
+
+	cmd := &cobra.Command{
+		Use:         "repair",
+		Annotations: audit.Annotations(),
+		Short:       "Detect and remediate inconsistencies of local Airbyte",
+		Long: `Detect and remediate inconsistencies of local Airbyte, which otherwise fail abctl with generic errors.
+
+The following inconsistencies are detected:
+  - the kind cluster exists, but its context is missing from the kubeconfig
+  - the namespace exists, but the Airbyte helm release does not
+  - the Airbyte helm release is stuck in a pending state
+
+Each remediation is confirmed before it is applied when running in a terminal, otherwise the inconsistencies are
+only listed unless --yes is provided.
+
+The data persisted by an installation whose cluster was deleted (e.g. by 'abctl local uninstall' without
+--persisted) is kept for the next installation, and is only removed with --remove-data.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ = spinner.Start(fmt.Sprintf("Checking for existing Kubernetes cluster '%s'", provider.ClusterName))
+
+			unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			var p prompter
+			if term.IsTerminal(int(os.Stdin.Fd())) {
+				p = ptermPrompter{}
+			}
+
+			cluster, err := provider.Cluster()
+			if err != nil {
+				spinner.Fail(fmt.Sprintf("Could not determine status of any existing '%s' cluster", provider.ClusterName))
+				return err
+			}
+
+			if !cluster.Exists() {
+				if provider.Name == k8s.Existing {
+					spinner.Fail(fmt.Sprintf("Context '%s' does not exist in kubeconfig '%s'", provider.Context, provider.Kubeconfig))
+					return fmt.Errorf("context %s not found", provider.Context)
+				}
+
+				dirs, err := local.PersistedData()
+				if err != nil {
+					spinner.Fail("Unable to check for persisted data")
+					return err
+				}
+				return persistedData(spinner, dirs, flagRemoveData)
+			}
+
+			// the context is required to diagnose the installation within the cluster
+			if provider.Name == k8s.Kind && !k8s.ContextExists(provider.KubeconfigPath(paths.UserHome), provider.Context) {
+				problem := local.Problem{
+					Description: fmt.Sprintf("Cluster '%s' exists, but its context '%s' is missing from the kubeconfig '%s'",
+						provider.ClusterName, provider.Context, provider.KubeconfigPath(paths.UserHome)),
+					Remediation: "Export the context of the cluster to the kubeconfig again",
+					Fix: func(context.Context) error {
+						return cluster.ExportKubeconfig()
+					},
+				}
+				if err := remediate(cmd.Context(), spinner, []local.Problem{problem}, p, flagYes); err != nil {
+					return err
+				}
+				spinner, _ = spinner.Start("Checking the installation")
+			}
+
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			problems, err := lc.Diagnose(cmd.Context())
+			if err != nil {
+				spinner.Fail("Unable to check the installation")
+				return err
+			}
+			return remediate(cmd.Context(), spinner, problems, p, flagYes)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "apply every remediation without prompting, which never removes persisted data")
+	cmd.Flags().BoolVar(&flagRemoveData, "remove-data", false, "remove the data persisted by a previous installation whose cluster no longer exists")
+
+	return cmd
+}
+
+// persistedData reports the persisted data of a deleted cluster, which is not an inconsistency as it was kept by
+// uninstall for the next installation, and only removes it if remove is true.
+func persistedData(spinner *pterm.SpinnerPrinter, dirs []string, remove bool) error {
+	switch {
+	case len(dirs) == 0:
+		spinner.Success("No inconsistencies found")
+	case remove:
+		spinner.UpdateText(fmt.Sprintf("Removing the persisted data (%s)", strings.Join(dirs, ", ")))
+		err := local.RemovePersistedData(dirs)
+		_ = spinner.Stop()
+		return err
+	default:
+		spinner.Success("No inconsistencies found")
+		pterm.Info.Printfln("Persisted data of a previous installation exists in '%s', which the next installation "+
+			"reuses, run 'abctl local repair --remove-data' to remove it", paths.Data)
+	}
+	return nil
+}
+
+// remediate fixes each of the problems which is confirmed, or every problem if yes is true.
+// Without a prompter the problems are only listed.
+// An error is returned if any problem remains.
+func remediate(ctx context.Context, spinner *pterm.SpinnerPrinter, problems []local.Problem, p prompter, yes bool) error {
+	if len(problems) == 0 {
+		spinner.Success("No inconsistencies found")
+		return nil
+	}
+	_ = spinner.Stop()
+
+	var remaining int
+	for _, problem := range problems {
+		pterm.Warning.Println(problem.Description)
+
+		fix := yes
+		if !yes && p != nil {
+			var err error
+			if fix, err = p.Confirm(problem.Remediation+"?", !problem.Destructive); err != nil {
+				return err
+			}
+		}
+		if !fix {
+			pterm.Info.Printfln("Remediation: %s", problem.Remediation)
+			remaining++
+			continue
+		}
+
+		if err := problem.Fix(ctx); err != nil {
+			pterm.Error.Printfln("Unable to remediate: %s", problem.Description)
+			return err
+		}
+		pterm.Success.Printfln("Remediated: %s", problem.Description)
+	}
+
+	if remaining > 0 {
+		return fmt.Errorf("not every inconsistency was remediated, %d remaining", remaining)
+	}
+	return nil
+}
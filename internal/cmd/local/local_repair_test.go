@@ -0,0 +1,84 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pterm/pterm"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemediate(t *testing.T) {
+	var fixed []string
+	problems := []local.Problem{
+		{
+			Description: "first",
+			Remediation: "Fix first",
+			Fix: func(context.Context) error {
+				fixed = append(fixed, "first")
+				return nil
+			},
+		},
+		{
+			Description: "second",
+			Remediation: "Fix second",
+			Destructive: true,
+			Fix: func(context.Context) error {
+				fixed = append(fixed, "second")
+				return nil
+			},
+		},
+	}
+
+	p := &mockPrompter{t: t, answers: []mockAnswer{
+		{prompt: "Fix first?", ok: true},
+		{prompt: "Fix second?", ok: false},
+	}}
+	if err := remediate(context.Background(), &pterm.DefaultSpinner, problems, p, false); err == nil {
+		t.Error("expected an error for the declined remediation")
+	}
+	if d := cmp.Diff([]string{"first"}, fixed); d != "" {
+		t.Error("fixed mismatch (-want +got):", d)
+	}
+
+	// without a prompter the problems are only listed, unless every remediation is applied
+	fixed = nil
+	if err := remediate(context.Background(), &pterm.DefaultSpinner, problems, nil, false); err == nil {
+		t.Error("expected an error for the listed problems")
+	}
+	if len(fixed) > 0 {
+		t.Error("expected nothing to be fixed, got", fixed)
+	}
+	if err := remediate(context.Background(), &pterm.DefaultSpinner, problems, nil, true); err != nil {
+		t.Error("unexpected error", err)
+	}
+	if d := cmp.Diff([]string{"first", "second"}, fixed); d != "" {
+		t.Error("fixed mismatch (-want +got):", d)
+	}
+}
+
+func TestPersistedData(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "airbyte-volume-db")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// the persisted data is kept, unless its removal is requested
+	if err := persistedData(&pterm.DefaultSpinner, []string{dir}, false); err != nil {
+		t.Error("unexpected error", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Error("expected the persisted data to be kept", err)
+	}
+
+	if err := persistedData(&pterm.DefaultSpinner, []string{dir}, true); err != nil {
+		t.Error("unexpected error", err)
+	}
+	if _, err := os.Stat(dir); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("expected the persisted data to be removed", err)
+	}
+}
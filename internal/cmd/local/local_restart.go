@@ -0,0 +1,69 @@
+package local
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"strings"
+	"time"
+)
+
+func NewCmdRestart(provider *k8s.Provider) *cobra.Command {
+	var (
+		flagAll     bool
+		flagTimeout time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:         "restart [component...]",
+		Annotations: audit.Annotations(),
+		Short:       "Restart components of local Airbyte",
+		Long: fmt.Sprintf(`Restart components of local Airbyte, e.g. after changing their configuration.
+
+The components are restarted one at a time, in the order %s,
+as each depends on those before it. Every component is ready again before the next one is restarted.`,
+			strings.Join(local.RestartComponents(), ", ")),
+		Example: `  abctl local restart server
+  abctl local restart worker workload-launcher
+  abctl local restart --all`,
+		ValidArgs: local.RestartComponents(),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if flagAll == (len(args) > 0) {
+				return fmt.Errorf("either components or --all must be provided")
+			}
+			return local.ValidateRestartComponents(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ := pterm.DefaultSpinner.Start("Restarting Airbyte")
+
+			unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			if err := lc.Restart(cmd.Context(), args, flagTimeout); err != nil {
+				spinner.Fail("Unable to restart Airbyte")
+				return err
+			}
+
+			spinner.Success("Airbyte restarted")
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flagAll, "all", false, "restart every component of Airbyte")
+	cmd.Flags().DurationVar(&flagTimeout, "timeout", local.DefaultReadyTimeout, "how long to wait for each component to become ready once restarted")
+
+	return cmd
+}
@@ -0,0 +1,180 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/readiness"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"os"
+	"time"
+)
+
+func NewCmdResume(provider *k8s.Provider) *cobra.Command {
+	spinner := &pterm.DefaultSpinner
+
+	var (
+		flagHost    string
+		flagTimeout time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:         "resume",
+		Annotations: audit.Annotations(),
+		Short:       "Resume paused local Airbyte",
+		Long: `Resume paused local Airbyte, starting the cluster and waiting for Airbyte to become ready.
+
+Any Airbyte deployments scaled down by 'abctl local pause --scale-down' are scaled back up.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ = spinner.Start(fmt.Sprintf("Checking for existing Kubernetes cluster '%s'", provider.ClusterName))
+
+			if provider.Name == k8s.Existing {
+				spinner.Fail("Resuming is not supported with an existing cluster")
+				return fmt.Errorf("resume is not supported with the existing cluster %s", provider.ClusterName)
+			}
+
+			unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			// the docker client must be created before the cluster, as it determines the docker host kind uses
+			if dockerClient == nil {
+				if dockerClient, err = newDockerClient(cmd.Context()); err != nil {
+					pterm.Error.Printfln("Could not connect to Docker daemon")
+					return fmt.Errorf("could not connect to docker: %w", err)
+				}
+			}
+
+			cluster, err := provider.Cluster()
+			if err != nil {
+				pterm.Error.Printfln("Could not determine status of any existing '%s' cluster", provider.ClusterName)
+				return err
+			}
+
+			if !cluster.Exists() {
+				spinner.Fail("Airbyte does not appear to be installed locally")
+				return fmt.Errorf("cluster '%s' does not exist", provider.ClusterName)
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), flagTimeout)
+			defer cancel()
+
+			lc, err := startCluster(ctx, provider, cluster, spinner)
+			if err != nil {
+				return err
+			}
+
+			// the remote host must be used to access the ingress, unless another host was explicitly requested
+			if remote := remoteDockerHost(); remote != "" && !cmd.Flags().Changed("host") {
+				flagHost = remote
+			}
+
+			if err := lc.Wait(ctx, local.WaitOpts{Host: flagHost, Timeout: flagTimeout}); err != nil {
+				spinner.Fail("Airbyte is not ready")
+				return err
+			}
+
+			spinner.Success("Airbyte resumed")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flagHost, "host", local.DefaultHost, "ingress http host used to verify the ingress")
+	cmd.Flags().DurationVar(&flagTimeout, "timeout", startTimeout, "how long to wait for Airbyte to become ready")
+
+	return cmd
+}
+
+// startTimeout is how long to wait for Airbyte to become ready once a stopped cluster is started, unless another
+// timeout is provided.
+const startTimeout = 10 * time.Minute
+
+// startCluster starts every node of the stopped kind cluster, waits for the kubernetes api server to become available,
+// and scales up any Airbyte workloads which were scaled down when the cluster was paused.
+// Returns the local command for the started cluster, which is not guaranteed to be ready yet.
+func startCluster(ctx context.Context, provider *k8s.Provider, cluster k8s.Cluster, spinner *pterm.SpinnerPrinter) (*local.Command, error) {
+	nodes, err := cluster.Nodes()
+	if err != nil {
+		pterm.Error.Printfln("Could not determine the nodes of cluster '%s'", provider.ClusterName)
+		return nil, err
+	}
+
+	for _, node := range nodes {
+		spinner.UpdateText(fmt.Sprintf("Starting node '%s'", node))
+		if err := dockerClient.Start(ctx, node); err != nil {
+			spinner.Fail(fmt.Sprintf("Unable to start node '%s'", node))
+			return nil, err
+		}
+	}
+
+	port, err := dockerClient.Port(ctx, fmt.Sprintf("%s-control-plane", provider.ClusterName))
+	if err != nil {
+		pterm.Error.Printfln("Could not determine docker port for cluster '%s'", provider.ClusterName)
+		return nil, err
+	}
+
+	// the kubernetes api server must be available before the local command can be initialized
+	spinner.UpdateText("Waiting for the Kubernetes API server")
+	var lc *local.Command
+	apiReady := readiness.New("kubernetes api server", func(context.Context) error {
+		lc, err = local.New(*provider,
+			local.WithHost(remoteDockerHost()),
+			local.WithPortHTTP(port),
+			local.WithTelemetryClient(telClient),
+			local.WithSpinner(spinner),
+			local.WithNamespace(flagNamespace),
+		)
+		return err
+	})
+	if err := readiness.Wait(ctx, 2*time.Second, apiReady); err != nil {
+		spinner.Fail("The Kubernetes API server did not become available")
+		return nil, err
+	}
+	pterm.Success.Printfln("Cluster '%s' started", provider.ClusterName)
+
+	st, err := state.Load(statePath(*provider, flagNamespace))
+	if err != nil {
+		pterm.Error.Println("Unable to load the installation state")
+		return nil, err
+	}
+	if len(st.Paused) > 0 {
+		if err := lc.ScaleUp(ctx, st.Paused); err != nil {
+			spinner.Fail("Unable to scale up Airbyte")
+			return nil, err
+		}
+		st.Paused = nil
+		if err := state.Save(statePath(*provider, flagNamespace), st); err != nil {
+			pterm.Warning.Println("Unable to record that Airbyte was scaled up")
+			logging.Debugf("could not save state: %s", err)
+		}
+	}
+
+	return lc, nil
+}
+
+// autoStart returns true if the stopped cluster should be started, either as the --auto-start flag was provided, or
+// as the user confirmed it should be started when prompted. The user is only prompted in an interactive terminal.
+func autoStart(flagAutoStart bool, clusterName string) bool {
+	if flagAutoStart {
+		return true
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+
+	start, err := pterm.DefaultInteractiveConfirm.Show(fmt.Sprintf("Cluster '%s' is stopped, start it now?", clusterName))
+	if err != nil {
+		logging.Debugf("could not prompt to start the cluster: %s", err)
+		return false
+	}
+	return start
+}
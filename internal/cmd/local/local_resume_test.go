@@ -0,0 +1,21 @@
+package local
+
+import (
+	"golang.org/x/term"
+	"os"
+	"testing"
+)
+
+func TestAutoStart(t *testing.T) {
+	if !autoStart(true, "test") {
+		t.Error("expected the flag to start the cluster")
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		t.Skip("stdin is a terminal, the user would be prompted")
+	}
+	// a non-interactive terminal must never start the cluster without the flag
+	if autoStart(false, "test") {
+		t.Error("expected the cluster to not be started")
+	}
+}
@@ -0,0 +1,161 @@
+package local
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+	"time"
+)
+
+func NewCmdSecrets(provider *k8s.Provider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage the secrets of local Airbyte",
+		Long: `Manage the secrets of local Airbyte, e.g. credentials referenced by connectors or the values of the Helm Chart.
+
+The secrets are created in the namespace of Airbyte, labeled app.kubernetes.io/managed-by=abctl. Only these secrets
+are listed and deleted, and the secrets of the Helm Chart and those abctl creates on install cannot be set.`,
+	}
+
+	cmd.AddCommand(newCmdSecretsSet(provider), newCmdSecretsList(provider), newCmdSecretsDelete(provider))
+
+	return cmd
+}
+
+func newCmdSecretsSet(provider *k8s.Provider) *cobra.Command {
+	var (
+		flagFromFile    []string
+		flagFromLiteral []string
+		flagNoRestart   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:         "set <name>",
+		Annotations: audit.Annotations(),
+		Short:       "Create a secret, or set keys of an existing secret",
+		Long: `Create a secret, or set keys of an existing secret, keeping its other keys.
+
+The deployments using the secret are restarted, so they read the new values.`,
+		Example: `  abctl local secrets set gcs-creds --from-file credentials.json
+  abctl local secrets set db-creds --from-literal username=airbyte --from-literal password=secret`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := local.ParseSecretData(flagFromFile, flagFromLiteral, os.ReadFile)
+			if err != nil {
+				return err
+			}
+
+			spinner, _ := pterm.DefaultSpinner.Start(fmt.Sprintf("Setting the secret '%s'", args[0]))
+
+			unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			if err := lc.SetSecret(cmd.Context(), args[0], data, !flagNoRestart); err != nil {
+				spinner.Fail(fmt.Sprintf("Unable to set the secret '%s'", args[0]))
+				return err
+			}
+			spinner.Success(fmt.Sprintf("Secret '%s' set", args[0]))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&flagFromFile, "from-file", nil, "a key of the secret set to the contents of a file, as key=path or path (keyed by the file name), may be specified multiple times")
+	cmd.Flags().StringArrayVar(&flagFromLiteral, "from-literal", nil, "a key of the secret set to a value, as key=value, may be specified multiple times")
+	cmd.Flags().BoolVar(&flagNoRestart, "no-restart", false, "do not restart the deployments using the secret")
+
+	return cmd
+}
+
+func newCmdSecretsList(provider *k8s.Provider) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the secrets managed by abctl",
+		Long: `List the secrets managed by abctl, with their keys and the deployments using them.
+
+The values of the secrets are never printed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ := pterm.DefaultSpinner.Start("Fetching the secrets")
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			secrets, err := lc.Secrets(cmd.Context())
+			if err != nil {
+				spinner.Fail("Unable to fetch the secrets")
+				return err
+			}
+			if len(secrets) == 0 {
+				spinner.Info("No secrets are managed by abctl")
+				return nil
+			}
+			spinner.Success(fmt.Sprintf("Fetched %d secret(s)", len(secrets)))
+
+			data := pterm.TableData{{"NAME", "KEYS", "CREATED", "USED BY"}}
+			for _, s := range secrets {
+				data = append(data, []string{s.Name, strings.Join(s.Keys, ", "), s.Created.Local().Format(time.RFC3339), strings.Join(s.Consumers, ", ")})
+			}
+			return pterm.DefaultTable.WithHasHeader().WithWriter(cmd.OutOrStdout()).WithData(data).Render()
+		},
+	}
+}
+
+func newCmdSecretsDelete(provider *k8s.Provider) *cobra.Command {
+	var (
+		flagForce     bool
+		flagNoRestart bool
+	)
+
+	cmd := &cobra.Command{
+		Use:         "delete <name>",
+		Annotations: audit.Annotations(),
+		Short:       "Delete a secret managed by abctl",
+		Long: `Delete a secret managed by abctl.
+
+A secret used by any deployment is only deleted with --force, which restarts the deployments using it.`,
+		Example: `  abctl local secrets delete gcs-creds`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ := pterm.DefaultSpinner.Start(fmt.Sprintf("Deleting the secret '%s'", args[0]))
+
+			unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			if err := lc.DeleteSecret(cmd.Context(), args[0], flagForce, !flagNoRestart); err != nil {
+				spinner.Fail(fmt.Sprintf("Unable to delete the secret '%s'", args[0]))
+				return err
+			}
+			spinner.Success(fmt.Sprintf("Secret '%s' deleted", args[0]))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flagForce, "force", false, "delete the secret even if deployments use it")
+	cmd.Flags().BoolVar(&flagNoRestart, "no-restart", false, "do not restart the deployments using the secret")
+
+	return cmd
+}
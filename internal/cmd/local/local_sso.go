@@ -0,0 +1,143 @@
+package local
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"os"
+	"path/filepath"
+)
+
+func NewCmdSSO(provider *k8s.Provider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sso",
+		Short: "Manage single sign-on of local Airbyte Enterprise",
+	}
+
+	cmd.AddCommand(newCmdSSOConfigure(provider))
+
+	return cmd
+}
+
+func newCmdSSOConfigure(provider *k8s.Provider) *cobra.Command {
+	var (
+		opts         local.SSOOpts
+		flagKeyring  string
+		flagInsecure bool
+	)
+
+	cmd := &cobra.Command{
+		Use:         "configure",
+		Annotations: audit.Annotations(),
+		Short:       "Configure OIDC single sign-on, converting local Airbyte to the enterprise edition",
+		Long: `Configure OIDC single sign-on, converting local Airbyte to the enterprise edition.
+
+The identity provider credentials, license key, and instance admin credentials are stored in the
+'airbyte-config-secrets' secret, the enterprise helm values are applied to the existing installation,
+and the Airbyte server and webapp are restarted.
+
+Values which are not provided as flags are prompted for when running in a terminal.`,
+		Example: `  abctl local sso configure \
+    --issuer https://example.okta.com --app-name airbyte \
+    --client-id 0oa1b2c3 --client-secret "$CLIENT_SECRET" \
+    --license-key "$LICENSE_KEY" --airbyte-url http://localhost:8000 \
+    --admin-email admin@example.com --admin-password "$ADMIN_PASSWORD"`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := promptSSOOpts(&opts); err != nil {
+				return err
+			}
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ := pterm.DefaultSpinner.Start("Configuring SSO")
+			unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			download := &local.DownloadOpts{Dir: filepath.Join(paths.AbCtl, "charts"), Keyring: flagKeyring, InsecureSkipVerify: flagInsecure}
+			if err := lc.ConfigureSSO(cmd.Context(), opts, download); err != nil {
+				spinner.Fail("Unable to configure SSO")
+				return err
+			}
+			spinner.Success(fmt.Sprintf("SSO configured, sign in to Airbyte via %s", opts.AirbyteURL))
+			return nil
+		},
+	}
+
+	ssoFlags(cmd, &opts)
+	cmd.Flags().StringVar(&flagKeyring, "chart-keyring", "", "path of a PGP keyring used to verify the provenance (.prov) of the airbyte helm chart, only the chart digest is verified by default")
+	cmd.Flags().BoolVar(&flagInsecure, "insecure-skip-verify", false, "upgrade helm charts which cannot be verified, because they have no digest, a mismatched digest, or an invalid provenance")
+
+	return cmd
+}
+
+// ssoFlags adds the flags configuring the options of single sign-on to the command.
+func ssoFlags(cmd *cobra.Command, opts *local.SSOOpts) {
+	cmd.Flags().StringVar(&opts.Issuer, "issuer", "", "the https url of the OIDC identity provider, e.g. https://example.okta.com")
+	cmd.Flags().StringVar(&opts.AppName, "app-name", "", "the name of the Airbyte application within the identity provider")
+	cmd.Flags().StringVar(&opts.ClientID, "client-id", "", "the client id of the Airbyte application")
+	cmd.Flags().StringVar(&opts.ClientSecret, "client-secret", "", "the client secret of the Airbyte application, or a secret reference (vault://path#key or aws-sm://name[#key])")
+	cmd.Flags().StringVar(&opts.LicenseKey, "license-key", "", "the Airbyte enterprise license key, or a secret reference (vault://path#key or aws-sm://name[#key])")
+	cmd.Flags().StringVar(&opts.AirbyteURL, "airbyte-url", fmt.Sprintf("http://localhost:%d", local.Port), "the url Airbyte is accessed from, the identity provider redirects to it after signing in")
+	cmd.Flags().StringVar(&opts.AdminFirstName, "admin-first-name", "", "the first name of the instance admin")
+	cmd.Flags().StringVar(&opts.AdminLastName, "admin-last-name", "", "the last name of the instance admin")
+	cmd.Flags().StringVar(&opts.AdminEmail, "admin-email", "", "the email of the instance admin, who can sign in without the identity provider")
+	cmd.Flags().StringVar(&opts.AdminPassword, "admin-password", "", "the password of the instance admin, or a secret reference (vault://path#key or aws-sm://name[#key])")
+}
+
+// promptSSOOpts prompts for every option which was not provided as a flag.
+// Nothing is prompted for unless stdin is a terminal, the missing options are then reported by validation instead.
+func promptSSOOpts(opts *local.SSOOpts) error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil
+	}
+	return promptSSO(ptermPrompter{}, opts)
+}
+
+// promptSSO prompts for every option which is not already set.
+func promptSSO(p prompter, opts *local.SSOOpts) error {
+	for _, o := range []struct {
+		text   string
+		value  *string
+		secret bool
+	}{
+		{text: "Identity provider issuer url", value: &opts.Issuer},
+		{text: "Application name", value: &opts.AppName},
+		{text: "Client id", value: &opts.ClientID},
+		{text: "Client secret", value: &opts.ClientSecret, secret: true},
+		{text: "License key", value: &opts.LicenseKey, secret: true},
+		{text: "Instance admin first name", value: &opts.AdminFirstName},
+		{text: "Instance admin last name", value: &opts.AdminLastName},
+		{text: "Instance admin email", value: &opts.AdminEmail},
+		{text: "Instance admin password", value: &opts.AdminPassword, secret: true},
+	} {
+		if *o.value != "" {
+			continue
+		}
+
+		var err error
+		if o.secret {
+			*o.value, err = p.Secret(o.text)
+		} else {
+			*o.value, err = p.Text(o.text, "")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
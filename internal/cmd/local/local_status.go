@@ -1,23 +1,43 @@
 package local
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"github.com/airbytehq/abctl/internal/cmd/local/docker"
+	"github.com/airbytehq/abctl/internal/build"
 	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
 	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/airbytehq/abctl/internal/cmd/local/watchdog"
+	"github.com/airbytehq/abctl/internal/logging"
 	"github.com/airbytehq/abctl/internal/telemetry"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+	"io/fs"
+	"time"
 )
 
-func NewCmdStatus(provider k8s.Provider) *cobra.Command {
+func NewCmdStatus(provider *k8s.Provider) *cobra.Command {
 	spinner := &pterm.DefaultSpinner
 
+	var (
+		flagAutoStart bool
+		flagTimeout   time.Duration
+	)
+
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Status of local Airbyte",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			spinner, _ = spinner.Start("Starting status check")
+
+			// an existing cluster does not require docker
+			if provider.Name == k8s.Existing {
+				return nil
+			}
+
 			spinner.UpdateText("Checking for Docker installation")
 
 			dockerVersion, err := dockerInstalled(cmd.Context())
@@ -49,12 +69,21 @@ func NewCmdStatus(provider k8s.Provider) *cobra.Command {
 
 				var port int
 				pterm.Success.Printfln("Existing cluster '%s' found", provider.ClusterName)
+
+				if st, err := state.Load(statePath(*provider, flagNamespace)); err != nil {
+					logging.Debugf("could not load state: %s", err)
+				} else if st.ModifiedBy != "" {
+					pterm.Info.Printfln("Installation last modified by abctl %s", st.ModifiedBy)
+					if err := st.Check(build.Version); err != nil {
+						pterm.Warning.Println(err)
+					}
+				}
 				spinner.UpdateText(fmt.Sprintf("Validating existing cluster '%s'", provider.ClusterName))
 
 				// only for kind do we need to check the existing port
 				if provider.Name == k8s.Kind {
 					if dockerClient == nil {
-						dockerClient, err = docker.New(cmd.Context())
+						dockerClient, err = newDockerClient(cmd.Context())
 						if err != nil {
 							pterm.Error.Printfln("Could not connect to Docker daemon")
 							return fmt.Errorf("could not connect to docker: %w", err)
@@ -62,16 +91,36 @@ func NewCmdStatus(provider k8s.Provider) *cobra.Command {
 					}
 
 					port, err = dockerClient.Port(cmd.Context(), fmt.Sprintf("%s-control-plane", provider.ClusterName))
+					if errors.Is(err, localerr.ErrClusterStopped) {
+						if !autoStart(flagAutoStart, provider.ClusterName) {
+							pterm.Warning.Printfln("Cluster '%s' is stopped, run 'abctl local resume' to start it", provider.ClusterName)
+							return nil
+						}
+
+						ctx, cancel := context.WithTimeout(cmd.Context(), flagTimeout)
+						defer cancel()
+						lc, err := startCluster(ctx, provider, cluster, spinner)
+						if err != nil {
+							return err
+						}
+						if err := lc.Wait(ctx, local.WaitOpts{Timeout: flagTimeout}); err != nil {
+							pterm.Warning.Printfln("Airbyte did not become ready within %s", flagTimeout)
+						}
+						port, err = dockerClient.Port(cmd.Context(), fmt.Sprintf("%s-control-plane", provider.ClusterName))
+					}
 					if err != nil {
 						pterm.Warning.Printfln("Could not determine docker port for cluster '%s'", provider.ClusterName)
 						return nil
 					}
 				}
 
-				lc, err := local.New(provider,
+				lc, err := local.New(*provider,
+					local.WithHost(remoteDockerHost()),
 					local.WithPortHTTP(port),
 					local.WithTelemetryClient(telClient),
 					local.WithSpinner(spinner),
+					local.WithNamespace(flagNamespace),
+					installationOption(*provider, flagNamespace),
 				)
 				if err != nil {
 					pterm.Error.Printfln("Failed to initialize 'local' command")
@@ -83,11 +132,41 @@ func NewCmdStatus(provider k8s.Provider) *cobra.Command {
 					return err
 				}
 
+				printWatchdogStatus()
+
 				spinner.Success("Status check")
 				return nil
 			})
 		},
 	}
 
+	cmd.Flags().BoolVar(&flagAutoStart, "auto-start", false, "start the existing cluster if it is stopped, instead of prompting")
+	cmd.Flags().DurationVar(&flagTimeout, "timeout", startTimeout, "how long to wait for Airbyte to become ready, if the cluster was started")
+
 	return cmd
 }
+
+// printWatchdogStatus prints the outcome of the most recent watchdog check, if the watchdog has ever run.
+func printWatchdogStatus() {
+	st, err := watchdog.Load(paths.Watchdog)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			logging.Debugf("could not load watchdog status: %s", err)
+		}
+		return
+	}
+
+	checked := st.CheckedAt.Local().Format(time.RFC1123)
+	switch {
+	case st.Error != "":
+		pterm.Warning.Printfln("Watchdog check at %s failed: %s", checked, st.Error)
+	case st.Healthy:
+		pterm.Info.Printfln("Watchdog check at %s found no crashed deployments", checked)
+	default:
+		pterm.Warning.Printfln("Watchdog check at %s found %d crashed deployment(s)", checked, len(st.Crashed))
+	}
+	if n := len(st.Restarts); n > 0 {
+		last := st.Restarts[n-1]
+		pterm.Info.Printfln("Watchdog last restarted deployment '%s' at %s: %s", last.Deployment, last.At.Local().Format(time.RFC1123), last.Reason)
+	}
+}
@@ -0,0 +1,176 @@
+package local
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"os"
+	"path"
+)
+
+func NewCmdStorage(provider *k8s.Provider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "storage",
+		Short: "Browse the object storage of local Airbyte",
+		Long: `Browse the object storage of local Airbyte, e.g. to fetch the logs and state of syncs.
+
+The commands are executed within the minio pod deployed by the Airbyte Helm Chart, authenticated by the credentials
+of the secret it generates. Paths are bucket/key, e.g. airbyte-storage/job-logging. Another object storage (e.g. S3)
+must be accessed with the tools of its provider.`,
+	}
+
+	cmd.AddCommand(newCmdStorageLs(provider), newCmdStorageGet(provider), newCmdStoragePut(provider))
+
+	return cmd
+}
+
+// storagePathArg returns the storage path of the argument, printing an error if it is invalid.
+func storagePathArg(arg string) (string, error) {
+	p, err := local.StoragePath(arg)
+	if err != nil {
+		pterm.Error.Printfln("Invalid path '%s'", arg)
+		return "", err
+	}
+	return p, nil
+}
+
+func newCmdStorageLs(provider *k8s.Provider) *cobra.Command {
+	var flagRecursive bool
+
+	cmd := &cobra.Command{
+		Use:   "ls [bucket/prefix]",
+		Short: "List the buckets, or the objects of a bucket/prefix",
+		Example: `  abctl local storage ls
+  abctl local storage ls airbyte-storage/job-logging --recursive`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var p string
+			if len(args) == 1 {
+				var err error
+				if p, err = storagePathArg(args[0]); err != nil {
+					return err
+				}
+			}
+
+			spinner, _ := pterm.DefaultSpinner.Start(fmt.Sprintf("Listing '%s'", p))
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			var listing bytes.Buffer
+			if err := lc.ListStorage(cmd.Context(), p, flagRecursive, &listing); err != nil {
+				spinner.Fail(fmt.Sprintf("Unable to list '%s'", p))
+				return err
+			}
+			_ = spinner.Stop()
+
+			fmt.Print(listing.String())
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&flagRecursive, "recursive", "r", false, "list every object below the prefix")
+
+	return cmd
+}
+
+func newCmdStorageGet(provider *k8s.Provider) *cobra.Command {
+	var flagOutput string
+
+	cmd := &cobra.Command{
+		Use:     "get <bucket/key>",
+		Short:   "Download an object",
+		Example: `  abctl local storage get airbyte-storage/job-logging/workspace/1/0/logs.log --output sync.log`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := storagePathArg(args[0])
+			if err != nil {
+				return err
+			}
+			if flagOutput == "" {
+				flagOutput = path.Base(p)
+			}
+
+			spinner, _ := pterm.DefaultSpinner.Start(fmt.Sprintf("Downloading '%s'", p))
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			f, err := os.OpenFile(flagOutput, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+			if err != nil {
+				spinner.Fail(fmt.Sprintf("Unable to create '%s'", flagOutput))
+				return fmt.Errorf("could not create %s: %w", flagOutput, err)
+			}
+
+			err = lc.GetStorage(cmd.Context(), p, f)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				// a partial download must not be mistaken for the object
+				_ = os.Remove(flagOutput)
+				spinner.Fail(fmt.Sprintf("Unable to download '%s'", p))
+				return err
+			}
+
+			spinner.Success(fmt.Sprintf("Downloaded '%s' to %s", p, flagOutput))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flagOutput, "output", "o", "", "the file to write the object to (defaults to the name of the object)")
+
+	return cmd
+}
+
+func newCmdStoragePut(provider *k8s.Provider) *cobra.Command {
+	return &cobra.Command{
+		Use:         "put <file> <bucket/key>",
+		Annotations: audit.Annotations(),
+		Short:       "Upload a file as an object, replacing any existing object",
+		Example:     `  abctl local storage put state.json airbyte-storage/state/connection-1.json`,
+		Args:        cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := storagePathArg(args[1])
+			if err != nil {
+				return err
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				pterm.Error.Printfln("Unable to open '%s'", args[0])
+				return fmt.Errorf("could not open %s: %w", args[0], err)
+			}
+			defer f.Close()
+
+			spinner, _ := pterm.DefaultSpinner.Start(fmt.Sprintf("Uploading '%s'", args[0]))
+
+			unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			if err := lc.PutStorage(cmd.Context(), p, f); err != nil {
+				spinner.Fail(fmt.Sprintf("Unable to upload '%s'", args[0]))
+				return err
+			}
+
+			spinner.Success(fmt.Sprintf("Uploaded '%s' to '%s'", args[0], p))
+			return nil
+		},
+	}
+}
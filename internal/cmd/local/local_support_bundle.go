@@ -0,0 +1,162 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/build"
+	"github.com/airbytehq/abctl/internal/cmd/local/bundle"
+	"github.com/airbytehq/abctl/internal/cmd/local/docker"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+func NewCmdSupportBundle(provider *k8s.Provider) *cobra.Command {
+	spinner := &pterm.DefaultSpinner
+
+	var (
+		flagOutput string
+		flagTraces []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "support-bundle",
+		Short: "Generate a support bundle for troubleshooting local Airbyte",
+		Long: `Generate a support bundle for troubleshooting local Airbyte.
+
+The support bundle is a tar.gz file containing the abctl version, docker information, helm release status,
+kubernetes events, and pod logs. Sensitive helm values are redacted.
+The bundle can be attached to a GitHub issue.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ = spinner.Start("Generating support bundle")
+
+			if flagOutput == "" {
+				flagOutput = fmt.Sprintf("abctl-support-bundle-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z"))
+			}
+
+			// the docker client is created before the items are collected concurrently, a failure only fails its item
+			var errDocker error
+			if dockerClient == nil {
+				dockerClient, errDocker = newDockerClient(cmd.Context())
+			}
+			client := dockerClient
+
+			items := []bundle.Item{
+				{Name: "abctl/version.txt", Collect: func(context.Context) ([]byte, error) {
+					return []byte(versionInfo()), nil
+				}},
+				{Name: "docker/version.yaml", Collect: func(ctx context.Context) ([]byte, error) {
+					if errDocker != nil {
+						return nil, fmt.Errorf("could not connect to docker: %w", errDocker)
+					}
+					return dockerInfo(ctx, client)
+				}},
+			}
+
+			for _, trace := range flagTraces {
+				trace := trace
+				items = append(items, bundle.Item{
+					Name: path.Join("traces", filepath.Base(trace)),
+					Collect: func(context.Context) ([]byte, error) {
+						return os.ReadFile(trace)
+					},
+				})
+			}
+
+			spinner.UpdateText(fmt.Sprintf("Checking for existing Kubernetes cluster '%s'", provider.ClusterName))
+			items = append(items, clusterItems(cmd.Context(), *provider, spinner)...)
+
+			spinner.UpdateText(fmt.Sprintf("Collecting %d items", len(items)))
+			results := bundle.DefaultCollector.Collect(cmd.Context(), items)
+
+			f, err := os.Create(flagOutput)
+			if err != nil {
+				spinner.Fail("Unable to create support bundle")
+				return fmt.Errorf("could not create support bundle %s: %w", flagOutput, err)
+			}
+			defer f.Close()
+
+			root := strings.TrimSuffix(filepath.Base(flagOutput), ".tar.gz")
+			if err := bundle.Write(f, root, results); err != nil {
+				spinner.Fail("Unable to write support bundle")
+				return fmt.Errorf("could not write support bundle %s: %w", flagOutput, err)
+			}
+
+			var failed int
+			for _, r := range results {
+				if r.Err != nil {
+					failed++
+					logging.Debugf("Could not collect %s: %s", r.Name, r.Err)
+				}
+			}
+			if failed > 0 {
+				pterm.Warning.Printfln("%d of %d items could not be collected, see summary.txt within the bundle for details", failed, len(results))
+			}
+
+			spinner.Success(fmt.Sprintf("Support bundle written to %s\nPlease review its contents before attaching it to a GitHub issue", flagOutput))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flagOutput, "output", "o", "", "the file to write the support bundle to (defaults to abctl-support-bundle-<timestamp>.tar.gz)")
+	cmd.Flags().StringSliceVar(&flagTraces, "trace", nil, "trace file(s), as written by --trace-file, to include in the support bundle")
+
+	return cmd
+}
+
+// clusterItems returns the bundle.Items for the cluster, if the cluster exists and is reachable.
+// A failure to connect to the cluster is not fatal, as a support bundle is still useful without it.
+func clusterItems(ctx context.Context, provider k8s.Provider, spinner *pterm.SpinnerPrinter) []bundle.Item {
+	cluster, err := provider.Cluster()
+	if err != nil {
+		pterm.Warning.Printfln("Could not determine status of any existing '%s' cluster", provider.ClusterName)
+		logging.Debugf("could not determine cluster status: %s", err)
+		return nil
+	}
+
+	if !cluster.Exists() {
+		pterm.Warning.Printfln("Cluster '%s' does not exist, no cluster information will be included", provider.ClusterName)
+		return nil
+	}
+
+	lc, err := local.New(provider, local.WithTelemetryClient(telClient), local.WithSpinner(spinner), local.WithNamespace(flagNamespace), installationOption(provider, flagNamespace))
+	if err != nil {
+		pterm.Warning.Printfln("Could not connect to cluster '%s', no cluster information will be included", provider.ClusterName)
+		logging.Debugf("could not initialize local command: %s", err)
+		return nil
+	}
+
+	items, err := lc.BundleItems(ctx)
+	if err != nil {
+		pterm.Warning.Printfln("Could not collect information from cluster '%s'", provider.ClusterName)
+		logging.Debugf("could not determine bundle items: %s", err)
+		return nil
+	}
+
+	return items
+}
+
+// versionInfo returns the build information of abctl.
+func versionInfo() string {
+	return fmt.Sprintf("version: %s\nrevision: %s\nmodified: %t\nos: %s\narch: %s\n",
+		build.Version, build.Revision, build.Modified, runtime.GOOS, runtime.GOARCH)
+}
+
+// dockerInfo returns the yaml version information of the docker daemon of the client.
+func dockerInfo(ctx context.Context, client *docker.Docker) ([]byte, error) {
+	version, err := client.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(version)
+}
@@ -0,0 +1,48 @@
+package local
+
+import (
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/google/go-cmp/cmp"
+	"path/filepath"
+	"testing"
+)
+
+func TestStatePath(t *testing.T) {
+	existing := k8s.Provider{Name: k8s.Existing, Context: "arn:aws:eks:us-east-1:123456789012:cluster/airbyte"}
+
+	tests := []struct {
+		name      string
+		provider  k8s.Provider
+		namespace string
+		exp       string
+	}{
+		{
+			name:      "default",
+			provider:  k8s.DefaultProvider,
+			namespace: local.DefaultNamespace,
+			exp:       paths.State,
+		},
+		{
+			name:      "kind namespace",
+			provider:  k8s.DefaultProvider,
+			namespace: "team-a",
+			exp:       filepath.Join(paths.States, "kind-airbyte-abctl_team-a.yaml"),
+		},
+		{
+			name:      "existing",
+			provider:  existing,
+			namespace: local.DefaultNamespace,
+			exp:       filepath.Join(paths.States, "arn-aws-eks-us-east-1-123456789012-cluster-airbyte_airbyte-abctl.yaml"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.exp, statePath(tt.provider, tt.namespace)); d != "" {
+				t.Error("path mismatch (-want +got):", d)
+			}
+		})
+	}
+}
@@ -2,23 +2,38 @@ package local
 
 import (
 	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
 	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
 	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/airbytehq/abctl/internal/logging"
 	"github.com/airbytehq/abctl/internal/telemetry"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 )
 
-func NewCmdUninstall(provider k8s.Provider) *cobra.Command {
+func NewCmdUninstall(provider *k8s.Provider) *cobra.Command {
 	spinner := &pterm.DefaultSpinner
 
 	var flagPersisted bool
 
 	cmd := &cobra.Command{
-		Use:   "uninstall",
-		Short: "Uninstall Airbyte locally",
+		Use:         "uninstall",
+		Annotations: audit.Annotations(),
+		Short:       "Uninstall Airbyte locally",
+		Long: `Uninstall Airbyte locally, deleting the cluster.
+
+Airbyte installed into any namespace other than the default is only uninstalled from that namespace, which keeps the
+cluster and any other installation within it.`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			spinner, _ = spinner.Start("Starting uninstallation")
+
+			// an existing cluster does not require docker
+			if provider.Name == k8s.Existing {
+				return nil
+			}
+
 			spinner.UpdateText("Checking for Docker installation")
 
 			dockerVersion, err := dockerInstalled(cmd.Context())
@@ -35,6 +50,12 @@ func NewCmdUninstall(provider k8s.Provider) *cobra.Command {
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return telemetry.Wrapper(cmd.Context(), telemetry.Uninstall, func() error {
+				unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+				if err != nil {
+					return err
+				}
+				defer unlock()
+
 				spinner.UpdateText(fmt.Sprintf("Checking for existing Kubernetes cluster '%s'", provider.ClusterName))
 
 				cluster, err := provider.Cluster()
@@ -46,15 +67,38 @@ func NewCmdUninstall(provider k8s.Provider) *cobra.Command {
 				// if no cluster exists, there is nothing to do
 				if !cluster.Exists() {
 					pterm.Success.Printfln("Cluster '%s' does not exist\nNo additional action required", provider.ClusterName)
+					if dirs, _ := local.PersistedData(); flagPersisted && len(dirs) > 0 {
+						pterm.Info.Printfln("Persisted data remains in '%s', run 'abctl local repair' to remove it", paths.Data)
+					}
 					return nil
 				}
 
 				pterm.Success.Printfln("Existing cluster '%s' found", provider.ClusterName)
 
-				lc, err := local.New(provider, local.WithTelemetryClient(telClient), local.WithSpinner(spinner))
+				// any other namespace may share the cluster with other installations, only Airbyte is uninstalled from it
+				if flagNamespace != local.DefaultNamespace {
+					lc, err := local.New(*provider, local.WithTelemetryClient(telClient), local.WithSpinner(spinner), local.WithNamespace(flagNamespace))
+					if err != nil {
+						pterm.Error.Printfln("Failed to initialize 'local' command")
+						return fmt.Errorf("could not initialize local command: %w", err)
+					}
+					if err := lc.Uninstall(cmd.Context(), local.UninstallOpts{Persisted: flagPersisted}); err != nil {
+						pterm.Error.Printfln("Unable to uninstall Airbyte from namespace '%s'", flagNamespace)
+						return err
+					}
+
+					if err := state.Remove(statePath(*provider, flagNamespace)); err != nil {
+						logging.Debugf("could not remove state: %s", err)
+					}
+
+					spinner.Success(fmt.Sprintf("Airbyte uninstallation from namespace '%s' complete", flagNamespace))
+					return nil
+				}
+
+				lc, err := local.New(*provider, local.WithTelemetryClient(telClient), local.WithSpinner(spinner))
 				if err != nil {
 					pterm.Warning.Printfln("Failed to initialize 'local' command\nUninstallation attempt will continue")
-					pterm.Debug.Printfln("Initialization of 'local' failed with %s", err.Error())
+					logging.Debugf("Initialization of 'local' failed with %s", err.Error())
 				} else {
 					if err := lc.Uninstall(cmd.Context(), local.UninstallOpts{Persisted: flagPersisted}); err != nil {
 						pterm.Warning.Printfln("could not complete uninstall: %s", err.Error())
@@ -62,12 +106,26 @@ func NewCmdUninstall(provider k8s.Provider) *cobra.Command {
 					}
 				}
 
-				spinner.UpdateText(fmt.Sprintf("Verifying uninstallation status of cluster '%s'", provider.ClusterName))
-				if err := cluster.Delete(); err != nil {
-					pterm.Error.Printfln(fmt.Sprintf("Uninstallation of cluster '%s' failed", provider.ClusterName))
-					return fmt.Errorf("could not uninstall cluster %s", provider.ClusterName)
+				// an existing cluster is not managed by abctl, only Airbyte is uninstalled from it
+				if provider.Name == k8s.Existing {
+					pterm.Info.Printfln("Existing cluster '%s' was not deleted", provider.ClusterName)
+				} else {
+					spinner.UpdateText(fmt.Sprintf("Verifying uninstallation status of cluster '%s'", provider.ClusterName))
+					if err := cluster.Delete(); err != nil {
+						pterm.Error.Printfln(fmt.Sprintf("Uninstallation of cluster '%s' failed", provider.ClusterName))
+						return fmt.Errorf("could not uninstall cluster %s", provider.ClusterName)
+					}
+					pterm.Success.Printfln(fmt.Sprintf("Uninstallation of cluster '%s' completed successfully", provider.ClusterName))
+				}
+
+				// the installations of a deleted cluster are gone as well
+				if provider.Name == k8s.Existing {
+					if err := state.Remove(statePath(*provider, flagNamespace)); err != nil {
+						logging.Debugf("could not remove state: %s", err)
+					}
+				} else {
+					removeClusterStates(*provider)
 				}
-				pterm.Success.Printfln(fmt.Sprintf("Uninstallation of cluster '%s' completed successfully", provider.ClusterName))
 
 				spinner.Success("Airbyte uninstallation complete")
 
@@ -0,0 +1,111 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+// NewCmdValues returns the command for inspecting the Airbyte helm chart values.
+func NewCmdValues() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "values",
+		Short: "Inspect the Airbyte helm chart values",
+	}
+
+	cmd.AddCommand(newCmdValuesExplain())
+
+	return cmd
+}
+
+func newCmdValuesExplain() *cobra.Command {
+	var flagChartVersion string
+
+	cmd := &cobra.Command{
+		Use:   "explain <key>",
+		Short: "Display the documentation, default, and type of an Airbyte helm chart value",
+		Example: `  abctl local values explain global.edition
+  abctl local values explain webapp.enabled --chart-version 0.64.0`,
+		Args: cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+
+			helm, err := local.NewHelmChartClient()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+			chart, err := local.GetAirbyteChart(helm, chartVersion(flagChartVersion))
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+
+			var keys []string
+			for _, key := range local.ValueKeys(chart) {
+				if strings.HasPrefix(key, toComplete) {
+					keys = append(keys, key)
+				}
+			}
+			return keys, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ := pterm.DefaultSpinner.Start("Fetching Airbyte Helm Chart")
+
+			helm, err := local.NewHelmChartClient()
+			if err != nil {
+				spinner.Fail("Unable to create Helm client")
+				return err
+			}
+
+			chart, err := local.GetAirbyteChart(helm, chartVersion(flagChartVersion))
+			if err != nil {
+				spinner.Fail("Unable to fetch Airbyte Helm Chart")
+				return err
+			}
+			spinner.Success(fmt.Sprintf("Fetched Airbyte Helm Chart (version: %s)", chart.Metadata.Version))
+
+			doc, err := local.ExplainValue(chart, args[0])
+			if errors.Is(err, local.ErrValueNotFound) {
+				pterm.Error.Printfln("The value '%s' does not exist in version %s of the Airbyte Helm Chart", args[0], chart.Metadata.Version)
+				return err
+			} else if err != nil {
+				return fmt.Errorf("could not explain value '%s': %w", args[0], err)
+			}
+
+			description := doc.Description
+			if description == "" {
+				description = "(no documentation available)"
+			}
+
+			pterm.Println(fmt.Sprintf("Key: %s\nChart: %s\nType: %s\nDefault:\n%s\nDescription:\n%s",
+				doc.Key, doc.Chart, doc.Type, indent(doc.Default), indent(description)))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flagChartVersion, "chart-version", "latest", "specify the Airbyte helm chart version to explain")
+
+	return cmd
+}
+
+// chartVersion converts the "latest" chart version into the empty string expected by the helm client.
+func chartVersion(version string) string {
+	if version == "latest" {
+		return ""
+	}
+	return version
+}
+
+// indent prefixes every line of s with two spaces.
+func indent(s string) string {
+	lines := strings.Split(s, "\n")
+	for i := range lines {
+		lines[i] = "  " + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,108 @@
+package local
+
+import (
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+func NewCmdWait(provider *k8s.Provider) *cobra.Command {
+	spinner := &pterm.DefaultSpinner
+
+	var (
+		flagHost     string
+		flagUsername string
+		flagPassword string
+		flagTimeout  time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "wait",
+		Short: "Wait for local Airbyte to become ready",
+		Long: `Wait for local Airbyte to become ready.
+
+Airbyte is considered ready once every pod is ready, the ingress is serving requests, and (if credentials are
+provided) the Airbyte API reports it is healthy.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ = spinner.Start(fmt.Sprintf("Checking for existing Kubernetes cluster '%s'", provider.ClusterName))
+
+			// the docker client must be created before the cluster, as it determines the docker host kind uses
+			var err error
+			if dockerClient == nil && provider.Name != k8s.Existing {
+				if dockerClient, err = newDockerClient(cmd.Context()); err != nil {
+					pterm.Error.Printfln("Could not connect to Docker daemon")
+					return fmt.Errorf("could not connect to docker: %w", err)
+				}
+			}
+
+			cluster, err := provider.Cluster()
+			if err != nil {
+				pterm.Error.Printfln("Could not determine status of any existing '%s' cluster", provider.ClusterName)
+				return err
+			}
+
+			if !cluster.Exists() {
+				spinner.Fail("Airbyte does not appear to be installed locally")
+				return fmt.Errorf("cluster '%s' does not exist", provider.ClusterName)
+			}
+
+			port := local.Port
+			// only for kind do we need to check the existing port
+			if provider.Name == k8s.Kind {
+				if port, err = dockerClient.Port(cmd.Context(), fmt.Sprintf("%s-control-plane", provider.ClusterName)); err != nil {
+					pterm.Error.Printfln("Could not determine docker port for cluster '%s'", provider.ClusterName)
+					return err
+				}
+			}
+
+			// the remote host must be used to access the ingress, unless another host was explicitly requested
+			if remote := remoteDockerHost(); remote != "" && !cmd.Flags().Changed("host") {
+				flagHost = remote
+			}
+
+			lc, err := local.New(*provider,
+				local.WithPortHTTP(port),
+				local.WithTelemetryClient(telClient),
+				local.WithSpinner(spinner),
+				local.WithNamespace(flagNamespace),
+				installationOption(*provider, flagNamespace),
+			)
+			if err != nil {
+				pterm.Error.Printfln("Failed to initialize 'local' command")
+				return fmt.Errorf("could not initialize local command: %w", err)
+			}
+
+			opts := local.WaitOpts{
+				Host:    flagHost,
+				User:    flagUsername,
+				Pass:    flagPassword,
+				Timeout: flagTimeout,
+			}
+			if env := os.Getenv(envBasicAuthUser); env != "" {
+				opts.User = env
+			}
+			if env := os.Getenv(envBasicAuthPass); env != "" {
+				opts.Pass = env
+			}
+
+			if err := lc.Wait(cmd.Context(), opts); err != nil {
+				spinner.Fail("Airbyte is not ready")
+				return err
+			}
+
+			spinner.Success("Airbyte is ready")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flagHost, "host", local.DefaultHost, "ingress http host used to verify the ingress and api")
+	cmd.Flags().StringVarP(&flagUsername, "username", "u", "", "basic auth username, can also be specified via "+envBasicAuthUser+", the api health is only checked if provided")
+	cmd.Flags().StringVarP(&flagPassword, "password", "p", "", "basic auth password, can also be specified via "+envBasicAuthPass)
+	cmd.Flags().DurationVar(&flagTimeout, "timeout", 10*time.Minute, "how long to wait for Airbyte to become ready")
+
+	return cmd
+}
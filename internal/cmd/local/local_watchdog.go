@@ -0,0 +1,195 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/airbytehq/abctl/internal/cmd/local/watchdog"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"io/fs"
+	"os"
+	"runtime"
+	"time"
+)
+
+func NewCmdWatchdog(provider *k8s.Provider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watchdog",
+		Short: "Manage the watchdog which restarts crashed Airbyte deployments",
+		Long: `Manage the watchdog, a background service which periodically checks the health of the Airbyte pods and
+restarts any crashed deployments.
+
+The outcome of the most recent check is reported by 'abctl local status'.`,
+	}
+
+	cmd.AddCommand(newCmdWatchdogStart(provider), newCmdWatchdogStop(), newCmdWatchdogRun(provider))
+
+	return cmd
+}
+
+func newCmdWatchdogStart(provider *k8s.Provider) *cobra.Command {
+	var flagInterval time.Duration
+
+	cmd := &cobra.Command{
+		Use:         "start",
+		Annotations: audit.Annotations(),
+		Short:       "Install and start the watchdog background service",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if flagInterval < time.Minute {
+				return fmt.Errorf("interval must be at least 1m, received %s", flagInterval)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			exe, err := os.Executable()
+			if err != nil {
+				pterm.Error.Println("Unable to determine the location of abctl")
+				return fmt.Errorf("could not determine executable: %w", err)
+			}
+
+			svc := watchdog.Service{
+				Args:     append([]string{exe}, watchdogRunArgs(*provider, flagNamespace)...),
+				Interval: flagInterval,
+				Home:     paths.UserHome,
+				LogFile:  paths.WatchdogLog,
+			}
+
+			installed, err := watchdog.Install(cmd.Context(), watchdog.RunCommand, runtime.GOOS, svc)
+			if err != nil {
+				pterm.Error.Println("Unable to install the watchdog")
+				return err
+			}
+
+			pterm.Success.Printfln("Watchdog installed as %s, checking every %s", installed, flagInterval)
+			pterm.Info.Printfln("Watchdog output is written to %s", paths.WatchdogLog)
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&flagInterval, "interval", 5*time.Minute, "how often the watchdog checks the health of Airbyte")
+
+	return cmd
+}
+
+func newCmdWatchdogStop() *cobra.Command {
+	return &cobra.Command{
+		Use:         "stop",
+		Annotations: audit.Annotations(),
+		Short:       "Stop and remove the watchdog background service",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := watchdog.Uninstall(cmd.Context(), watchdog.RunCommand, runtime.GOOS, paths.UserHome); err != nil {
+				pterm.Error.Println("Unable to remove the watchdog")
+				return err
+			}
+
+			pterm.Success.Println("Watchdog removed")
+			return nil
+		},
+	}
+}
+
+func newCmdWatchdogRun(provider *k8s.Provider) *cobra.Command {
+	return &cobra.Command{
+		Use:    "run",
+		Short:  "Run a single watchdog check",
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// the deployments of an installation being modified are not restarted, the check runs again later
+			unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), &pterm.DefaultSpinner)
+			if err != nil {
+				if errors.Is(err, localerr.ErrLocked) {
+					pterm.Info.Println("Skipping the watchdog check")
+					return nil
+				}
+				return err
+			}
+			defer unlock()
+
+			prev, err := watchdog.Load(paths.Watchdog)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				pterm.Warning.Printfln("Ignoring the previous watchdog status: %s", err)
+			}
+
+			now := time.Now()
+			var status watchdog.Status
+			if lc, err := watchdogCommand(cmd, provider); err != nil {
+				// keep the previous restarts, so the cooldown still applies once the cluster is available again
+				status = watchdog.Status{CheckedAt: now, Error: err.Error(), Restarts: prev.Restarts}
+			} else {
+				status = watchdog.Check(cmd.Context(), lc, prev, now)
+			}
+
+			for _, r := range status.Restarts {
+				if r.At.Equal(now) {
+					pterm.Info.Printfln("Restarted deployment '%s': %s", r.Deployment, r.Reason)
+				}
+			}
+			if status.Error != "" {
+				pterm.Warning.Printfln("Watchdog check failed: %s", status.Error)
+			}
+
+			if err := watchdog.Save(paths.Watchdog, status); err != nil {
+				pterm.Error.Println("Unable to record the watchdog status")
+				return err
+			}
+
+			return nil
+		},
+	}
+}
+
+// watchdogCommand returns the local.Command which the watchdog uses to check the health of Airbyte.
+func watchdogCommand(cmd *cobra.Command, provider *k8s.Provider) (*local.Command, error) {
+	// the docker client must be created before the cluster, as it determines the docker host kind uses
+	if provider.Name == k8s.Kind && dockerClient == nil {
+		var err error
+		if dockerClient, err = newDockerClient(cmd.Context()); err != nil {
+			return nil, fmt.Errorf("could not connect to docker: %w", err)
+		}
+	}
+
+	cluster, err := provider.Cluster()
+	if err != nil {
+		return nil, err
+	}
+	if !cluster.Exists() {
+		return nil, fmt.Errorf("cluster '%s' does not exist", provider.ClusterName)
+	}
+
+	if provider.Name == k8s.Kind {
+		if _, err := dockerClient.Port(cmd.Context(), fmt.Sprintf("%s-control-plane", provider.ClusterName)); err != nil {
+			if errors.Is(err, localerr.ErrClusterStopped) {
+				return nil, fmt.Errorf("cluster '%s' is stopped", provider.ClusterName)
+			}
+			return nil, err
+		}
+	}
+
+	lc, err := local.New(*provider, local.WithTelemetryClient(telClient), local.WithNamespace(flagNamespace))
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize local command: %w", err)
+	}
+
+	return lc, nil
+}
+
+// watchdogRunArgs returns the abctl arguments which run a single watchdog check against the provider and namespace.
+func watchdogRunArgs(provider k8s.Provider, namespace string) []string {
+	args := []string{"local", "watchdog", "run"}
+	if dockerHost != "" {
+		args = append(args, "--docker-host", dockerHost)
+	}
+	if provider.Name == k8s.Existing {
+		args = append(args, "--kubeconfig", provider.Kubeconfig, "--context", provider.Context)
+	}
+	if namespace != local.DefaultNamespace {
+		args = append(args, "--namespace", namespace)
+	}
+	return args
+}
@@ -0,0 +1,58 @@
+package local
+
+import (
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestWatchdogRunArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		provider   k8s.Provider
+		dockerHost string
+		namespace  string
+		exp        []string
+	}{
+		{
+			name:     "kind",
+			provider: k8s.DefaultProvider,
+			exp:      []string{"local", "watchdog", "run"},
+		},
+		{
+			name:       "docker host",
+			provider:   k8s.DefaultProvider,
+			dockerHost: "tcp://10.0.0.2:2375",
+			exp:        []string{"local", "watchdog", "run", "--docker-host", "tcp://10.0.0.2:2375"},
+		},
+		{
+			name:     "existing",
+			provider: k8s.Provider{Name: k8s.Existing, Kubeconfig: "/home/airbyte/.kube/config", Context: "prod"},
+			exp:      []string{"local", "watchdog", "run", "--kubeconfig", "/home/airbyte/.kube/config", "--context", "prod"},
+		},
+		{
+			name:      "namespace",
+			provider:  k8s.DefaultProvider,
+			namespace: "team-a",
+			exp:       []string{"local", "watchdog", "run", "--namespace", "team-a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := dockerHost
+			dockerHost = tt.dockerHost
+			t.Cleanup(func() { dockerHost = prev })
+
+			namespace := tt.namespace
+			if namespace == "" {
+				namespace = local.DefaultNamespace
+			}
+
+			if d := cmp.Diff(tt.exp, watchdogRunArgs(tt.provider, namespace)); d != "" {
+				t.Error("args mismatch (-want +got):", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/cmd/local/k8s"
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/cmd/local/state"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"os"
+	"time"
+)
+
+// workspaceAPITimeout is how long the workspace commands may take, including waiting for the api to become ready.
+const workspaceAPITimeout = 5 * time.Minute
+
+func NewCmdWorkspace(provider *k8s.Provider) *cobra.Command {
+	var flags apiFlags
+
+	cmd := &cobra.Command{
+		Use:   "workspace",
+		Short: "Export or import the sources, destinations, and connections of local Airbyte",
+		Long: `Export or import the sources, destinations, and connections of the default workspace of local Airbyte, e.g. to
+move them from a laptop to a server installation.
+
+The export does not contain the values of any secrets, as the api masks them. They are prompted for when importing.`,
+		Example: `  abctl local workspace export --output workspace.tar
+  abctl local workspace import workspace.tar --host airbyte.example.com`,
+	}
+
+	flags.register(cmd)
+
+	cmd.AddCommand(newCmdWorkspaceExport(provider, &flags), newCmdWorkspaceImport(provider, &flags))
+
+	return cmd
+}
+
+func newCmdWorkspaceExport(provider *k8s.Provider, flags *apiFlags) *cobra.Command {
+	var flagOutput string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the sources, destinations, and connections of the workspace",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spinner, _ := pterm.DefaultSpinner.Start("Exporting the Airbyte workspace")
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), workspaceAPITimeout)
+			defer cancel()
+
+			export, err := lc.ExportWorkspace(ctx, flags.apiOpts(cmd))
+			if err != nil {
+				spinner.Fail("Unable to export the Airbyte workspace")
+				return err
+			}
+
+			f, err := os.OpenFile(flagOutput, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+			if err != nil {
+				spinner.Fail(fmt.Sprintf("Unable to create '%s'", flagOutput))
+				return fmt.Errorf("could not create export %s: %w", flagOutput, err)
+			}
+			err = local.WriteWorkspace(f, export)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(flagOutput)
+				spinner.Fail("Unable to write the workspace export")
+				return err
+			}
+
+			spinner.Success(fmt.Sprintf("Exported %d sources, %d destinations, and %d connections to %s",
+				len(export.Sources), len(export.Destinations), len(export.Connections), flagOutput))
+			if secrets := export.MaskedSecrets(); len(secrets) > 0 {
+				pterm.Info.Printfln("The %d secrets of the sources and destinations are not exported, they are prompted for when importing", len(secrets))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flagOutput, "output", "o", "workspace.tar", "the file to write the export to")
+
+	return cmd
+}
+
+func newCmdWorkspaceImport(provider *k8s.Provider, flags *apiFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:         "import <file>",
+		Annotations: audit.Annotations(),
+		Short:       "Import the sources, destinations, and connections of an export into the workspace",
+		Long: `Import the sources, destinations, and connections of an export into the workspace.
+
+Every secret of the sources and destinations is prompted for, which requires a terminal. Those with the name of an
+existing source, destination, or connection are not imported again, so an import can be repeated.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				pterm.Error.Printfln("Unable to open '%s'", args[0])
+				return fmt.Errorf("could not open export %s: %w", args[0], err)
+			}
+			export, err := local.ReadWorkspace(f)
+			_ = f.Close()
+			if err != nil {
+				pterm.Error.Printfln("Unable to read '%s'", args[0])
+				return err
+			}
+
+			if secrets := export.MaskedSecrets(); len(secrets) > 0 {
+				if !term.IsTerminal(int(os.Stdin.Fd())) {
+					pterm.Error.Printfln("The %d secrets of the export must be entered in a terminal", len(secrets))
+					return fmt.Errorf("could not prompt for %d secrets, stdin is not a terminal", len(secrets))
+				}
+				if err := promptSecrets(export, ptermPrompter{}); err != nil {
+					return err
+				}
+			}
+
+			spinner, _ := pterm.DefaultSpinner.Start("Importing the Airbyte workspace")
+
+			unlock, err := state.LockInstallation(paths.Lock, cmd.CommandPath(), spinner)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			lc, err := existingCommand(cmd.Context(), provider, spinner, flagNamespace)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), workspaceAPITimeout)
+			defer cancel()
+
+			if err := lc.ImportWorkspace(ctx, flags.apiOpts(cmd), export); err != nil {
+				spinner.Fail("Unable to import the Airbyte workspace")
+				return err
+			}
+
+			spinner.Success(fmt.Sprintf("Imported '%s'", args[0]))
+			return nil
+		},
+	}
+}
+
+// promptSecrets prompts for the value of every masked secret of the export.
+func promptSecrets(export local.WorkspaceExport, p prompter) error {
+	for _, secret := range export.MaskedSecrets() {
+		value, err := p.Secret(fmt.Sprintf("%s '%s': %s", secret.Kind, secret.Name, secret.Path))
+		if err != nil {
+			return err
+		}
+		if err := export.SetSecret(secret, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package local
+
+import (
+	"github.com/airbytehq/abctl/internal/cmd/local/local"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestPromptSecrets(t *testing.T) {
+	export := local.WorkspaceExport{
+		Sources: []local.WorkspaceResource{{
+			Name:          "Faker",
+			Configuration: map[string]interface{}{"count": 10, "api_key": "**********"},
+		}},
+		Destinations: []local.WorkspaceResource{{
+			Name:          "Postgres",
+			Configuration: map[string]interface{}{"credentials": map[string]interface{}{"password": "**********"}},
+		}},
+	}
+
+	p := &mockPrompter{t: t, answers: []mockAnswer{
+		{prompt: "source 'Faker': api_key", text: "key"},
+		{prompt: "destination 'Postgres': credentials.password", text: "secret"},
+	}}
+	if err := promptSecrets(export, p); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if len(export.MaskedSecrets()) > 0 {
+		t.Error("expected every secret to be provided, remaining", export.MaskedSecrets())
+	}
+	if d := cmp.Diff("key", export.Sources[0].Configuration["api_key"]); d != "" {
+		t.Error("source secret mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff(map[string]interface{}{"password": "secret"}, export.Destinations[0].Configuration["credentials"]); d != "" {
+		t.Error("destination secret mismatch (-want +got):", d)
+	}
+}
@@ -1,17 +1,178 @@
 package localerr
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
+
+// docsURL is the documentation of every error code, each of which is linked to by its anchor.
+const docsURL = "https://github.com/airbytehq/abctl/blob/main/docs/errors.md"
+
+// LocalError is an error with a machine-readable code, which support can triage by instead of its message, and help
+// text describing how to resolve it.
+type LocalError struct {
+	code string
+	msg  string
+	help string
+}
+
+// Error returns the message of the error.
+func (e *LocalError) Error() string {
+	return e.msg
+}
+
+// Code returns the code of the error, e.g. ABCTL-0001.
+func (e *LocalError) Code() string {
+	return e.code
+}
+
+// Help returns the text describing how to resolve the error.
+func (e *LocalError) Help() string {
+	return e.help
+}
+
+// DocsURL returns the link to the documentation of the error.
+func (e *LocalError) DocsURL() string {
+	return docsURL + "#" + strings.ToLower(e.code)
+}
 
 var (
 	// ErrDocker is returned anytime an error occurs when attempting to communicate with docker.
-	ErrDocker = errors.New("error communicating with docker")
+	ErrDocker = &LocalError{
+		code: "ABCTL-0001",
+		msg:  "error communicating with docker",
+		help: `An error occurred while communicating with the Docker daemon.
+Ensure that Docker is running and is accessible.  You may need to upgrade to a newer version of Docker.
+For additional help please visit https://docs.docker.com/get-docker/`,
+	}
 
 	// ErrKubernetes is returned anytime an error occurs when attempting to communicate with the kubernetes cluster.
-	ErrKubernetes = errors.New("error communicating with kubernetes")
+	ErrKubernetes = &LocalError{
+		code: "ABCTL-0002",
+		msg:  "error communicating with kubernetes",
+		help: `An error occurred while communicating with the Kubernetes cluster.
+If this error persists, you may need to run the uninstall command before attempting to run
+the install command again.`,
+	}
 
 	// ErrIngress is returned in the event that ingress configuration failed.
-	ErrIngress = errors.New("error configuring ingress")
+	ErrIngress = &LocalError{
+		code: "ABCTL-0003",
+		msg:  "error configuring ingress",
+		help: `An error occurred while configuring ingress.
+This could be in indication that the ingress port is already in use by a different application.
+The ingress port can be changed by passing the flag --port.`,
+	}
 
 	// ErrPort is returned in the event that the requested port is unavailable.
-	ErrPort = errors.New("error verifying port availability")
+	ErrPort = &LocalError{
+		code: "ABCTL-0004",
+		msg:  "error verifying port availability",
+		help: `An error occurred while verifying if the request port is available.
+This could be in indication that the ingress port is already in use by a different application.
+The ingress port can be changed by passing the flag --port.`,
+	}
+
+	// ErrVersion is returned in the event that the installation was last modified by a newer version of abctl.
+	ErrVersion = &LocalError{
+		code: "ABCTL-0005",
+		msg:  "error verifying abctl version",
+		help: `This installation was last modified by a newer version of abctl.
+Running an older version of abctl against it may leave it in an inconsistent state.
+Upgrade abctl to the latest version (https://github.com/airbytehq/abctl/releases), or
+run the uninstall command before attempting to run the install command again.`,
+	}
+
+	// ErrClusterStopped is returned in the event that the kind cluster exists, but is not running.
+	ErrClusterStopped = &LocalError{
+		code: "ABCTL-0006",
+		msg:  "cluster is not running",
+		help: `The cluster has been stopped, either by the pause command or by Docker being restarted.
+Run the resume command to start it again, or pass the flag --auto-start.`,
+	}
+
+	// ErrUnverified is returned in the event that a downloaded artifact could not be verified.
+	ErrUnverified = &LocalError{
+		code: "ABCTL-0007",
+		msg:  "error verifying download",
+		help: `A downloaded Helm Chart could not be verified, it may have been tampered with.
+If the Helm repository or keyring is trusted, and the failure is expected (e.g. the chart is unsigned),
+pass the flag --insecure-skip-verify to install it without verification.`,
+	}
+
+	// ErrLocked is returned in the event that another abctl operation is modifying the installation.
+	ErrLocked = &LocalError{
+		code: "ABCTL-0008",
+		msg:  "another abctl operation is in progress",
+		help: `Another abctl operation is modifying the installation, wait for it to complete before trying again.
+If no other abctl operation is running, remove the lock file ~/.airbyte/abctl/abctl.lock.`,
+	}
+
+	// ErrDrift is returned in the event that the installation differs from the spec, but was only checked.
+	ErrDrift = &LocalError{
+		code: "ABCTL-0009",
+		msg:  "installation differs from the spec",
+		help: `The installation differs from the spec by the changes listed above.
+Run the apply command without the flag --check to apply them.`,
+	}
+
+	// ErrNoDatabase is returned in the event that the database pod does not exist, e.g. because an external database
+	// is configured.
+	ErrNoDatabase = &LocalError{
+		code: "ABCTL-0010",
+		msg:  "the airbyte database pod does not exist",
+		help: `The command requires the database deployed by the Airbyte Helm Chart, which does not exist.
+If an external database is configured, use the tools of its provider instead.`,
+	}
+
+	// ErrCancelled is returned in the event that a prompted confirmation was declined.
+	ErrCancelled = &LocalError{
+		code: "ABCTL-0011",
+		msg:  "cancelled",
+		help: `The operation was not confirmed, so nothing was changed.`,
+	}
+
+	// ErrNoStorage is returned in the event that the minio pod does not exist, e.g. because another object storage
+	// is configured.
+	ErrNoStorage = &LocalError{
+		code: "ABCTL-0012",
+		msg:  "the airbyte minio pod does not exist",
+		help: `The command requires the minio object storage deployed by the Airbyte Helm Chart, which does not exist.
+If another object storage (e.g. S3) is configured, use the tools of its provider instead.`,
+	}
+
+	// ErrForbidden is returned in the event that the user of the kubeconfig is not allowed a permission abctl requires.
+	ErrForbidden = &LocalError{
+		code: "ABCTL-0013",
+		msg:  "missing kubernetes permissions",
+		help: `The user of the kubeconfig context is not allowed every permission the installation requires.
+Ask the administrator of the cluster to grant the listed permissions, or use a context with more permissions.`,
+	}
+
+	// ErrK8sVersion is returned in the event that the kubernetes version of the cluster is older than the airbyte
+	// chart supports.
+	ErrK8sVersion = &LocalError{
+		code: "ABCTL-0014",
+		msg:  "unsupported kubernetes version",
+		help: `The Kubernetes version of the cluster is older than the Airbyte Helm Chart supports.
+Upgrade the cluster, install an older --chart-version, or pass --skip-k8s-version-check to install regardless.`,
+	}
 )
+
+// Catalog returns every LocalError, ordered by code.
+func Catalog() []*LocalError {
+	return []*LocalError{
+		ErrDocker, ErrKubernetes, ErrIngress, ErrPort, ErrVersion, ErrClusterStopped,
+		ErrUnverified, ErrLocked, ErrDrift, ErrNoDatabase, ErrCancelled, ErrNoStorage, ErrForbidden,
+		ErrK8sVersion,
+	}
+}
+
+// Find returns the LocalError the err wraps, or nil if it does not wrap one.
+func Find(err error) *LocalError {
+	var e *LocalError
+	if errors.As(err, &e) {
+		return e
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package localerr
+
+import (
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	docs, err := os.ReadFile("../../../../docs/errors.md")
+	if err != nil {
+		t.Fatal("could not read docs", err)
+	}
+
+	code := regexp.MustCompile(`^ABCTL-\d{4}$`)
+	for i, e := range Catalog() {
+		if d := cmp.Diff(fmt.Sprintf("ABCTL-%04d", i+1), e.Code()); d != "" {
+			t.Error("code mismatch (-want +got):", d)
+		}
+		if !code.MatchString(e.Code()) {
+			t.Error("invalid code", e.Code())
+		}
+		if e.Error() == "" || e.Help() == "" {
+			t.Error("missing message or help for", e.Code())
+		}
+		if !strings.Contains(string(docs), "### "+e.Code()+"\n") {
+			t.Error("undocumented code", e.Code())
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	if e := Find(fmt.Errorf("%w: port 8000 is in use", ErrPort)); e != ErrPort {
+		t.Error("expected ErrPort, got", e)
+	}
+	if Find(fmt.Errorf("unknown")) != nil {
+		t.Error("expected no LocalError")
+	}
+	if Find(nil) != nil {
+		t.Error("expected no LocalError for nil")
+	}
+	if d := cmp.Diff("https://github.com/airbytehq/abctl/blob/main/docs/errors.md#abctl-0004", ErrPort.DocsURL()); d != "" {
+		t.Error("docs url mismatch (-want +got):", d)
+	}
+}
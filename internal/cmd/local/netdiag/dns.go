@@ -0,0 +1,172 @@
+package netdiag
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"strings"
+	"time"
+)
+
+// PodClient is the subset of the k8s client the dns check requires.
+type PodClient interface {
+	ManifestApply(ctx context.Context, namespace string, obj *unstructured.Unstructured) error
+	ManifestDelete(ctx context.Context, obj *unstructured.Unstructured) error
+	PodList(ctx context.Context, namespace string) (*corev1.PodList, error)
+	LogsGet(ctx context.Context, namespace string, name string) (string, error)
+}
+
+const (
+	// dnsPod is the name of the pod resolving the dnsHosts.
+	dnsPod = "abctl-dns-check"
+	// dnsImage is the image of the dnsPod, which provides nslookup.
+	dnsImage = "busybox:1.36"
+	// clusterHost is resolved by the dns of the cluster itself.
+	clusterHost = "kubernetes.default.svc.cluster.local"
+	// externalHost is resolved by the dns servers the dns of the cluster forwards to, and is required for pulling images.
+	externalHost = "registry-1.docker.io"
+)
+
+// dnsTimeout is how long the dnsPod is waited for, including pulling its image. Defined for testing purposes.
+var dnsTimeout = 90 * time.Second
+
+// dnsPollInterval is how often the dnsPod is checked for completion. Defined for testing purposes.
+var dnsPollInterval = 2 * time.Second
+
+// dns resolves a host of the cluster and a host of the internet within a pod of the cluster, finding whether either
+// fails to resolve.
+func (d *Diagnostics) dns(ctx context.Context) []Finding {
+	pod := dnsPodManifest(d.namespace)
+	if err := d.pods.ManifestApply(ctx, d.namespace, pod); err != nil {
+		logging.Debugf("Skipping the dns check, could not create pod %s: %s", dnsPod, err)
+		return nil
+	}
+	defer func() {
+		// the pod is removed even if the ctx was cancelled
+		if err := d.pods.ManifestDelete(context.WithoutCancel(ctx), pod); err != nil {
+			logging.Debugf("Unable to delete pod %s: %s", dnsPod, err)
+		}
+	}()
+
+	if err := d.waitPod(ctx); err != nil {
+		return []Finding{{
+			Check:   CheckDNS,
+			Problem: fmt.Sprintf("The dns check could not run, as pod %s did not complete: %s", dnsPod, err),
+			Fixes: []string{
+				fmt.Sprintf("If the image %s could not be pulled, the nodes cannot reach docker hub, see the other findings", dnsImage),
+			},
+		}}
+	}
+
+	out, err := d.pods.LogsGet(ctx, d.namespace, dnsPod)
+	if err != nil {
+		logging.Debugf("Skipping the dns check, could not get the logs of pod %s: %s", dnsPod, err)
+		return nil
+	}
+	return dnsFindings(out)
+}
+
+// waitPod waits for the dnsPod to complete.
+func (d *Diagnostics) waitPod(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, dnsTimeout)
+	defer cancel()
+
+	var reason string
+	for {
+		pods, err := d.pods.PodList(ctx, d.namespace)
+		if err != nil {
+			reason = err.Error()
+		}
+		if pods != nil {
+			for _, p := range pods.Items {
+				if p.Name != dnsPod {
+					continue
+				}
+				switch p.Status.Phase {
+				case corev1.PodSucceeded, corev1.PodFailed:
+					return nil
+				}
+				reason = fmt.Sprintf("phase %s", p.Status.Phase)
+				for _, cs := range p.Status.ContainerStatuses {
+					if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+						reason = cs.State.Waiting.Reason
+					}
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if reason == "" {
+				reason = ctx.Err().Error()
+			}
+			return fmt.Errorf("timed out waiting for pod: %s", reason)
+		case <-time.After(dnsPollInterval):
+		}
+	}
+}
+
+// dnsFindings returns the findings of the output of the dnsPod, which prints '<host> ok' or '<host> failed' for each
+// host it resolved.
+func dnsFindings(out string) []Finding {
+	failed := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		if host, result, ok := strings.Cut(strings.TrimSpace(line), " "); ok {
+			failed[host] = result == "failed"
+		}
+	}
+
+	switch {
+	case failed[clusterHost]:
+		return []Finding{{
+			Check:   CheckDNS,
+			Problem: fmt.Sprintf("The dns of the cluster (CoreDNS) could not resolve %s, the components of Airbyte cannot reach each other.", clusterHost),
+			Fixes: []string{
+				"Check the CoreDNS pods are running: kubectl --kubeconfig ~/.airbyte/abctl/abctl.kubeconfig -n kube-system get pods -l k8s-app=kube-dns",
+				"Ensure no host firewall blocks the traffic of the kind network, see the other findings",
+			},
+		}}
+	case failed[externalHost]:
+		return []Finding{{
+			Check:   CheckDNS,
+			Problem: fmt.Sprintf("The cluster could not resolve %s, the dns servers of the docker host (which a VPN may have replaced) are not reachable from the cluster.", externalHost),
+			Fixes: []string{
+				"Forward the dns queries of the cluster to a reachable dns server: abctl local install --dns-server <ip>",
+				`Or configure the dns servers of docker in /etc/docker/daemon.json, e.g. {"dns": ["<ip>"]}, and restart docker`,
+			},
+		}}
+	}
+	return nil
+}
+
+// dnsPodManifest returns the manifest of the dnsPod.
+func dnsPodManifest(namespace string) *unstructured.Unstructured {
+	var script strings.Builder
+	for _, host := range []string{clusterHost, externalHost} {
+		fmt.Fprintf(&script, "if nslookup %s >/dev/null 2>&1; then echo '%s ok'; else echo '%s failed'; fi; ", host, host, host)
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      dnsPod,
+			"namespace": namespace,
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/managed-by": "abctl",
+			},
+		},
+		"spec": map[string]interface{}{
+			"restartPolicy": "Never",
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name":    "dns",
+					"image":   dnsImage,
+					"command": []interface{}{"sh", "-c", strings.TrimSpace(script.String())},
+				},
+			},
+		},
+	}}
+}
@@ -0,0 +1,172 @@
+package netdiag
+
+import (
+	"context"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/logging"
+	"net/netip"
+	"strings"
+)
+
+// network is the docker network of the kind nodes.
+type network struct {
+	// bridge is the host interface of the network, e.g. br-0123456789ab.
+	bridge  string
+	subnets []netip.Prefix
+}
+
+// kindNetwork returns the docker network of the kind nodes, as inspected by the docker cli.
+func kindNetwork(ctx context.Context, run CommandRunner) (network, error) {
+	out, err := run(ctx, "docker", "network", "inspect", "kind", "--format",
+		`{{.Id}}|{{index .Options "com.docker.network.bridge.name"}}|{{range .IPAM.Config}}{{.Subnet}},{{end}}`)
+	if err != nil {
+		return network{}, fmt.Errorf("could not inspect the kind network: %w: %s", err, strings.TrimSpace(out))
+	}
+	return parseNetwork(out)
+}
+
+// parseNetwork parses the output of the docker network inspect of kindNetwork.
+func parseNetwork(out string) (network, error) {
+	parts := strings.Split(strings.TrimSpace(out), "|")
+	if len(parts) != 3 {
+		return network{}, fmt.Errorf("unexpected output of docker network inspect: %s", out)
+	}
+
+	var n network
+	// docker names the bridge of a network after its id, unless the network defines another name
+	switch {
+	case parts[1] != "" && parts[1] != "<no value>":
+		n.bridge = parts[1]
+	case len(parts[0]) >= 12:
+		n.bridge = "br-" + parts[0][:12]
+	}
+	for _, s := range strings.Split(parts[2], ",") {
+		if s == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return network{}, fmt.Errorf("could not parse subnet %s of the kind network: %w", s, err)
+		}
+		n.subnets = append(n.subnets, prefix)
+	}
+	return n, nil
+}
+
+// route is a route of the routing table of the host.
+type route struct {
+	dst netip.Prefix
+	dev string
+}
+
+// parseRoutes parses the routes of the output of 'ip -o route show', skipping the default routes.
+func parseRoutes(out string) []route {
+	var routes []route
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] == "default" {
+			continue
+		}
+		// the type of the route may precede its destination, e.g. 'unreachable 10.0.0.0/8'
+		dst := fields[0]
+		switch dst {
+		case "unicast", "local", "broadcast", "unreachable", "blackhole", "prohibit", "throw":
+			dst = fields[1]
+		}
+		prefix, err := netip.ParsePrefix(dst)
+		if err != nil {
+			addr, errAddr := netip.ParseAddr(dst)
+			if errAddr != nil {
+				continue
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+
+		r := route{dst: prefix.Masked()}
+		for i := 0; i < len(fields)-1; i++ {
+			if fields[i] == "dev" {
+				r.dev = fields[i+1]
+			}
+		}
+		routes = append(routes, r)
+	}
+	return routes
+}
+
+// routes finds any route of another interface, e.g. of a VPN, taking precedence over the route of the bridge of the
+// kind network for (a part of) its subnet, which makes the nodes unreachable.
+func (d *Diagnostics) routes(ctx context.Context, n network) []Finding {
+	var routes []route
+	for _, family := range []string{"-4", "-6"} {
+		out, err := d.run(ctx, "ip", family, "-o", "route", "show")
+		if err != nil {
+			logging.Debugf("Unable to list the %s routes: %s", family, err)
+			continue
+		}
+		routes = append(routes, parseRoutes(out)...)
+	}
+
+	var findings []Finding
+	for _, subnet := range n.subnets {
+		for _, r := range routes {
+			if r.dev == n.bridge || !r.dst.Overlaps(subnet) || r.dst.Bits() < subnet.Bits() {
+				continue
+			}
+			findings = append(findings, Finding{
+				Check: CheckRoutes,
+				Problem: fmt.Sprintf("The route of %s via %s (likely a VPN) conflicts with the subnet %s of the kind network, "+
+					"the traffic to the cluster is routed to %s instead of the kind network.", r.dst, r.dev, subnet, r.dev),
+				Fixes: []string{
+					fmt.Sprintf("Exclude %s from the routes of the VPN (split tunneling), or disconnect the VPN while using Airbyte", subnet),
+					"Or recreate the kind network with a subnet the VPN does not route: abctl local uninstall; docker network rm kind; " +
+						"docker network create kind --subnet <subnet>; abctl local install",
+				},
+			})
+		}
+	}
+	return findings
+}
+
+// firewall finds whether an active firewalld or ufw blocks the traffic of the bridge of the kind network, which the
+// nodes require to pull images and reach the internet.
+func (d *Diagnostics) firewall(ctx context.Context, n network) []Finding {
+	if n.bridge == "" {
+		return nil
+	}
+
+	var findings []Finding
+	if out, err := d.run(ctx, "firewall-cmd", "--state"); err == nil && strings.Contains(out, "running") {
+		zone, err := d.run(ctx, "firewall-cmd", "--get-zone-of-interface="+n.bridge)
+		zone = strings.TrimSpace(zone)
+		// docker adds its bridges to the docker zone, which accepts their traffic, as does the trusted zone
+		if err != nil || (zone != "trusted" && zone != "docker") {
+			findings = append(findings, Finding{
+				Check:   CheckFirewall,
+				Problem: fmt.Sprintf("firewalld is running and the bridge %s of the kind network is not within a zone accepting its traffic, which may block the nodes from reaching the internet.", n.bridge),
+				Fixes: []string{
+					fmt.Sprintf("sudo firewall-cmd --permanent --zone=trusted --add-interface=%s", n.bridge),
+					"sudo firewall-cmd --reload",
+				},
+			})
+		}
+	}
+
+	if out, err := d.run(ctx, "ufw", "status", "verbose"); err == nil {
+		status := strings.ToLower(out)
+		if strings.Contains(status, "status: active") && (strings.Contains(status, "deny (routed)") || strings.Contains(status, "reject (routed)")) {
+			findings = append(findings, Finding{
+				Check:   CheckFirewall,
+				Problem: fmt.Sprintf("ufw is active and denies routed traffic by default, which may block the traffic of the bridge %s of the kind network.", n.bridge),
+				Fixes: []string{
+					fmt.Sprintf("sudo ufw allow in on %s", n.bridge),
+					fmt.Sprintf("sudo ufw route allow in on %s", n.bridge),
+					fmt.Sprintf("sudo ufw route allow out on %s", n.bridge),
+				},
+			})
+		}
+	} else {
+		logging.Debugf("Unable to determine the ufw status: %s", err)
+	}
+
+	return findings
+}
@@ -0,0 +1,109 @@
+// Package netdiag diagnoses the common conditions of the host and cluster network which break kind clusters: a VPN
+// routing the subnet of the kind network, a host firewall blocking the traffic of its bridge, and dns resolution
+// failing within the cluster.
+package netdiag
+
+import (
+	"context"
+	"github.com/airbytehq/abctl/internal/cmd/local/docker"
+	"github.com/airbytehq/abctl/internal/logging"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// Finding is a condition which likely interferes with the networking of the cluster.
+type Finding struct {
+	// Check is the name of the check which found the condition.
+	Check string
+	// Problem describes the condition and its consequence.
+	Problem string
+	// Fixes are the commands or steps resolving the condition.
+	Fixes []string
+}
+
+// The names of the checks.
+const (
+	CheckRoutes   = "routes"
+	CheckFirewall = "firewall"
+	CheckDNS      = "dns"
+)
+
+// CommandRunner runs the command and returns its combined output.
+type CommandRunner func(ctx context.Context, name string, args ...string) (string, error)
+
+func runCommand(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	return string(out), err
+}
+
+// Diagnostics runs the network checks.
+type Diagnostics struct {
+	run        CommandRunner
+	goos       string
+	remoteHost string
+	pods       PodClient
+	namespace  string
+}
+
+// Option for configuring the Diagnostics.
+type Option func(*Diagnostics)
+
+// WithCommandRunner defines the runner of the commands inspecting the host, primarily for testing purposes.
+func WithCommandRunner(run CommandRunner) Option {
+	return func(d *Diagnostics) {
+		d.run = run
+	}
+}
+
+// WithGOOS defines the operating system of the host, primarily for testing purposes.
+func WithGOOS(goos string) Option {
+	return func(d *Diagnostics) {
+		d.goos = goos
+	}
+}
+
+// WithPodClient enables the dns check, which runs a pod within the namespace of the cluster.
+func WithPodClient(pods PodClient, namespace string) Option {
+	return func(d *Diagnostics) {
+		d.pods = pods
+		d.namespace = namespace
+	}
+}
+
+// New returns the Diagnostics of the host, and of the cluster if WithPodClient is provided.
+func New(opts ...Option) *Diagnostics {
+	d := &Diagnostics{
+		run:        runCommand,
+		goos:       runtime.GOOS,
+		remoteHost: docker.RemoteHost(os.Getenv("DOCKER_HOST")),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Run runs every check, returning the findings of all of them.
+// A check which cannot be performed, e.g. as the kind network does not exist yet, is skipped.
+func (d *Diagnostics) Run(ctx context.Context) []Finding {
+	var findings []Finding
+
+	// the kind network is only routed by this machine for a local docker host on linux, docker desktop runs it within
+	// a virtual machine
+	if d.goos == "linux" && d.remoteHost == "" {
+		network, err := kindNetwork(ctx, d.run)
+		if err != nil {
+			logging.Debugf("Skipping the route and firewall checks: %s", err)
+		} else {
+			findings = append(findings, d.routes(ctx, network)...)
+			findings = append(findings, d.firewall(ctx, network)...)
+		}
+	}
+
+	if d.pods != nil {
+		findings = append(findings, d.dns(ctx)...)
+	}
+
+	return findings
+}
@@ -0,0 +1,219 @@
+package netdiag
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"strings"
+	"testing"
+	"time"
+)
+
+const networkOutput = "0123456789abcdef0123|<no value>|172.18.0.0/16,fc00:f853:ccd:e793::/64,\n"
+
+func TestParseNetwork(t *testing.T) {
+	n, err := parseNetwork(networkOutput)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff("br-0123456789ab", n.bridge); d != "" {
+		t.Error("bridge mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff([]string{"172.18.0.0/16", "fc00:f853:ccd:e793::/64"}, []string{n.subnets[0].String(), n.subnets[1].String()}); d != "" {
+		t.Error("subnets mismatch (-want +got):", d)
+	}
+
+	n, err = parseNetwork("0123456789abcdef|kind0|172.18.0.0/16,")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff("kind0", n.bridge); d != "" {
+		t.Error("named bridge mismatch (-want +got):", d)
+	}
+
+	if _, err := parseNetwork("unexpected"); err == nil {
+		t.Error("expected error for unexpected output")
+	}
+}
+
+func TestRun_Host(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes string
+		ufw    string
+		zone   string
+		exp    []string
+	}{
+		{
+			name:   "no issues",
+			routes: "default via 192.168.1.1 dev wlp2s0 proto dhcp metric 600\n172.18.0.0/16 dev br-0123456789ab proto kernel scope link src 172.18.0.1\n172.16.0.0/12 dev tun0 scope link\n",
+			ufw:    "Status: inactive\n",
+			zone:   "docker\n",
+		},
+		{
+			name:   "vpn route",
+			routes: "172.18.0.0/16 dev br-0123456789ab proto kernel scope link src 172.18.0.1\n172.18.4.0/24 via 10.8.0.1 dev tun0\n",
+			ufw:    "Status: inactive\n",
+			zone:   "trusted\n",
+			exp:    []string{CheckRoutes},
+		},
+		{
+			name:   "firewalls",
+			routes: "172.18.0.0/16 dev br-0123456789ab proto kernel scope link src 172.18.0.1\n",
+			ufw:    "Status: active\nDefault: deny (incoming), allow (outgoing), deny (routed)\n",
+			zone:   "public\n",
+			exp:    []string{CheckFirewall, CheckFirewall},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := func(ctx context.Context, name string, args ...string) (string, error) {
+				cmd := name + " " + strings.Join(args, " ")
+				switch {
+				case strings.HasPrefix(cmd, "docker network inspect kind"):
+					return networkOutput, nil
+				case cmd == "ip -4 -o route show":
+					return tt.routes, nil
+				case cmd == "ip -6 -o route show":
+					return "fc00:f853:ccd:e793::/64 dev br-0123456789ab proto kernel metric 256 pref medium\n", nil
+				case cmd == "firewall-cmd --state":
+					return "running\n", nil
+				case cmd == "firewall-cmd --get-zone-of-interface=br-0123456789ab":
+					return tt.zone, nil
+				case cmd == "ufw status verbose":
+					return tt.ufw, nil
+				}
+				t.Error("unexpected command", cmd)
+				return "", errors.New("unexpected command")
+			}
+
+			d := New(WithCommandRunner(run), WithGOOS("linux"))
+			d.remoteHost = ""
+			var checks []string
+			for _, f := range d.Run(context.Background()) {
+				checks = append(checks, f.Check)
+				if len(f.Fixes) == 0 {
+					t.Error("expected fixes of finding", f.Problem)
+				}
+			}
+			if d := cmp.Diff(tt.exp, checks); d != "" {
+				t.Error("findings mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestRun_HostSkipped(t *testing.T) {
+	run := func(ctx context.Context, name string, args ...string) (string, error) {
+		if name != "docker" {
+			t.Error("unexpected command", name)
+		}
+		return "Error: No such network: kind", errors.New("exit status 1")
+	}
+
+	d := New(WithCommandRunner(run), WithGOOS("linux"))
+	d.remoteHost = ""
+	if findings := d.Run(context.Background()); len(findings) != 0 {
+		t.Error("expected no findings without the kind network", findings)
+	}
+
+	darwin := New(WithCommandRunner(func(ctx context.Context, name string, args ...string) (string, error) {
+		t.Error("unexpected command", name)
+		return "", nil
+	}), WithGOOS("darwin"))
+	if findings := darwin.Run(context.Background()); len(findings) != 0 {
+		t.Error("expected no findings on darwin", findings)
+	}
+}
+
+func TestRun_DNS(t *testing.T) {
+	origPoll := dnsPollInterval
+	t.Cleanup(func() { dnsPollInterval = origPoll })
+	dnsPollInterval = time.Millisecond
+
+	tests := []struct {
+		name string
+		logs string
+		exp  string
+	}{
+		{name: "resolved", logs: "kubernetes.default.svc.cluster.local ok\nregistry-1.docker.io ok\n"},
+		{name: "external", logs: "kubernetes.default.svc.cluster.local ok\nregistry-1.docker.io failed\n", exp: "could not resolve registry-1.docker.io"},
+		{name: "cluster", logs: "kubernetes.default.svc.cluster.local failed\nregistry-1.docker.io failed\n", exp: "(CoreDNS) could not resolve"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var polls int
+			var deleted bool
+			pods := &mockPodClient{
+				apply: func(ctx context.Context, namespace string, obj *unstructured.Unstructured) error {
+					if d := cmp.Diff("abctl-dns-check", obj.GetName()); d != "" {
+						t.Error("pod mismatch (-want +got):", d)
+					}
+					return nil
+				},
+				delete: func(ctx context.Context, obj *unstructured.Unstructured) error {
+					deleted = true
+					return nil
+				},
+				list: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+					polls++
+					phase := corev1.PodPending
+					if polls > 1 {
+						phase = corev1.PodSucceeded
+					}
+					return &corev1.PodList{Items: []corev1.Pod{
+						{ObjectMeta: metav1.ObjectMeta{Name: "airbyte-abctl-server"}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+						{ObjectMeta: metav1.ObjectMeta{Name: dnsPod}, Status: corev1.PodStatus{Phase: phase}},
+					}}, nil
+				},
+				logs: func(ctx context.Context, namespace, name string) (string, error) {
+					return tt.logs, nil
+				},
+			}
+
+			findings := New(WithGOOS("darwin"), WithPodClient(pods, "airbyte-abctl")).Run(context.Background())
+			if !deleted {
+				t.Error("expected the pod to be deleted")
+			}
+			if tt.exp == "" {
+				if len(findings) != 0 {
+					t.Error("expected no findings", findings)
+				}
+				return
+			}
+			if len(findings) != 1 || !strings.Contains(findings[0].Problem, tt.exp) {
+				t.Errorf("expected a finding containing %q, got %v", tt.exp, findings)
+			}
+		})
+	}
+}
+
+var _ PodClient = (*mockPodClient)(nil)
+
+type mockPodClient struct {
+	apply  func(ctx context.Context, namespace string, obj *unstructured.Unstructured) error
+	delete func(ctx context.Context, obj *unstructured.Unstructured) error
+	list   func(ctx context.Context, namespace string) (*corev1.PodList, error)
+	logs   func(ctx context.Context, namespace, name string) (string, error)
+}
+
+func (m *mockPodClient) ManifestApply(ctx context.Context, namespace string, obj *unstructured.Unstructured) error {
+	return m.apply(ctx, namespace, obj)
+}
+
+func (m *mockPodClient) ManifestDelete(ctx context.Context, obj *unstructured.Unstructured) error {
+	return m.delete(ctx, obj)
+}
+
+func (m *mockPodClient) PodList(ctx context.Context, namespace string) (*corev1.PodList, error) {
+	return m.list(ctx, namespace)
+}
+
+func (m *mockPodClient) LogsGet(ctx context.Context, namespace, name string) (string, error) {
+	return m.logs(ctx, namespace, name)
+}
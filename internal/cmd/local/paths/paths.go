@@ -17,6 +17,35 @@ var (
 	AbCtl = abctl()
 	// Data is the full path to the ~/.airbyte/abctl/data directory
 	Data = data()
+	// State is the full path to the ~/.airbyte/abctl/state.yaml file
+	State = state()
+	// States is the full path to the ~/.airbyte/abctl/states directory, containing the state files of the
+	// installations other than the default one
+	States = states()
+	// Config is the full path to the ~/.airbyte/abctl/config.yaml file
+	Config = config()
+	// KindConfig is the full path to the ~/.airbyte/abctl/kind.yaml file
+	KindConfig = kindConfig()
+	// Watchdog is the full path to the ~/.airbyte/abctl/watchdog.yaml file
+	Watchdog = watchdog()
+	// WatchdogLog is the full path to the ~/.airbyte/abctl/watchdog.log file
+	WatchdogLog = watchdogLog()
+	// Audit is the full path to the ~/.airbyte/abctl/audit.jsonl file
+	Audit = audit()
+	// Events is the full path to the ~/.airbyte/abctl/events.jsonl file
+	Events = events()
+	// Cache is the full path to the ~/.airbyte/abctl/cache directory
+	Cache = cache()
+	// UpdateCheck is the full path to the ~/.airbyte/abctl/update-check.json file
+	UpdateCheck = updateCheck()
+	// Crashes is the full path to the ~/.airbyte/abctl/crashes directory
+	Crashes = crashes()
+	// Backups is the full path to the ~/.airbyte/abctl/backups directory
+	Backups = backups()
+	// Lock is the full path to the ~/.airbyte/abctl/abctl.lock file
+	Lock = lock()
+	// Logs is the full path to the ~/.airbyte/abctl/logs directory
+	Logs = logs()
 )
 
 func airbyte() string {
@@ -30,3 +59,59 @@ func abctl() string {
 func data() string {
 	return filepath.Join(abctl(), "data")
 }
+
+func state() string {
+	return filepath.Join(abctl(), "state.yaml")
+}
+
+func states() string {
+	return filepath.Join(abctl(), "states")
+}
+
+func config() string {
+	return filepath.Join(abctl(), "config.yaml")
+}
+
+func kindConfig() string {
+	return filepath.Join(abctl(), "kind.yaml")
+}
+
+func watchdog() string {
+	return filepath.Join(abctl(), "watchdog.yaml")
+}
+
+func watchdogLog() string {
+	return filepath.Join(abctl(), "watchdog.log")
+}
+
+func audit() string {
+	return filepath.Join(abctl(), "audit.jsonl")
+}
+
+func events() string {
+	return filepath.Join(abctl(), "events.jsonl")
+}
+
+func cache() string {
+	return filepath.Join(abctl(), "cache")
+}
+
+func updateCheck() string {
+	return filepath.Join(abctl(), "update-check.json")
+}
+
+func crashes() string {
+	return filepath.Join(abctl(), "crashes")
+}
+
+func backups() string {
+	return filepath.Join(abctl(), "backups")
+}
+
+func lock() string {
+	return filepath.Join(abctl(), "abctl.lock")
+}
+
+func logs() string {
+	return filepath.Join(abctl(), "logs")
+}
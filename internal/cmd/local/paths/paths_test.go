@@ -32,4 +32,16 @@ func Test_Paths(t *testing.T) {
 			t.Errorf("Data mismatch (-want +got):\n%s", d)
 		}
 	})
+	t.Run("State", func(t *testing.T) {
+		exp := filepath.Join(UserHome, ".airbyte", "abctl", "state.yaml")
+		if d := cmp.Diff(exp, State); d != "" {
+			t.Errorf("State mismatch (-want +got):\n%s", d)
+		}
+	})
+	t.Run("States", func(t *testing.T) {
+		exp := filepath.Join(UserHome, ".airbyte", "abctl", "states")
+		if d := cmp.Diff(exp, States); d != "" {
+			t.Errorf("States mismatch (-want +got):\n%s", d)
+		}
+	})
 }
@@ -0,0 +1,149 @@
+package progress
+
+import (
+	"fmt"
+	"github.com/pterm/pterm"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minElapsed is how long progress must be observed before the remaining time is estimated, as an estimate based on
+// the first few seconds of a phase fluctuates wildly.
+const minElapsed = 10 * time.Second
+
+// now returns the current time, defined here for testing purposes.
+var now = time.Now
+
+// Reporter displays the status of a long-running phase, e.g. by updating the text of a spinner.
+type Reporter func(status string)
+
+// Spinner returns a Reporter which appends the status to the text of the spinner.
+// An empty status resets the spinner to the text.
+func Spinner(spinner *pterm.SpinnerPrinter, text string) Reporter {
+	return func(status string) {
+		if status == "" {
+			spinner.UpdateText(text)
+			return
+		}
+		spinner.UpdateText(fmt.Sprintf("%s (%s)", text, status))
+	}
+}
+
+// Join returns n Reporters, each reporting its own part of the status reported by report, so concurrent phases can
+// share a single spinner, e.g. the pods becoming ready and the database being migrated.
+// The parts which are not empty are joined in the order of the returned Reporters.
+func Join(report Reporter, n int) []Reporter {
+	var lock sync.Mutex
+	parts := make([]string, n)
+	reporters := make([]Reporter, n)
+	for i := range reporters {
+		i := i
+		reporters[i] = func(status string) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			parts[i] = status
+			var statuses []string
+			for _, part := range parts {
+				if part != "" {
+					statuses = append(statuses, part)
+				}
+			}
+			report(strings.Join(statuses, "; "))
+		}
+	}
+	return reporters
+}
+
+// Unit describes the progress of a phase in its units, e.g. "3/12 pods ready".
+type Unit func(done, total int64) string
+
+// Bytes describes the progress of an image pull.
+func Bytes(done, total int64) string {
+	return fmt.Sprintf("%.1f/%.1f MB pulled", float64(done)/(1024*1024), float64(total)/(1024*1024))
+}
+
+// Pods describes the progress of the pods of a helm release becoming ready.
+func Pods(done, total int64) string {
+	return fmt.Sprintf("%d/%d pods ready", done, total)
+}
+
+// Tracker estimates the remaining time of a long-running phase, e.g. an image pull or a helm release becoming ready,
+// from the rate of its progress so far.
+type Tracker struct {
+	lock sync.Mutex
+	unit Unit
+
+	// start is when the progress was first updated, with initial being the progress done at that time.
+	start   time.Time
+	initial int64
+
+	done  int64
+	total int64
+}
+
+// NewTracker returns a Tracker describing its progress in the unit.
+func NewTracker(unit Unit) *Tracker {
+	return &Tracker{unit: unit}
+}
+
+// Update sets the progress done out of the total.
+// The total may change as the phase progresses, e.g. as the layers of an image are discovered.
+func (t *Tracker) Update(done, total int64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.start.IsZero() {
+		t.start = now()
+		t.initial = done
+	}
+	t.done, t.total = done, total
+}
+
+// Remaining returns the estimated remaining time, which is extrapolated from the progress made since the first
+// update. False is returned if there is not enough progress to estimate it yet.
+func (t *Tracker) Remaining() (time.Duration, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	elapsed := now().Sub(t.start)
+	progressed := t.done - t.initial
+	if t.start.IsZero() || elapsed < minElapsed || progressed <= 0 || t.done >= t.total {
+		return 0, false
+	}
+
+	rate := float64(progressed) / float64(elapsed)
+	return time.Duration(float64(t.total-t.done) / rate), true
+}
+
+// String returns the progress and, if it can be estimated, the remaining time,
+// e.g. "3/12 pods ready, about 4m remaining".
+func (t *Tracker) String() string {
+	t.lock.Lock()
+	status := t.unit(t.done, t.total)
+	t.lock.Unlock()
+
+	remaining, ok := t.Remaining()
+	if !ok {
+		return status
+	}
+	if remaining < time.Minute {
+		return status + ", less than a minute remaining"
+	}
+	// rounded up, as an estimate which is too low is what causes an installation to be interrupted
+	return fmt.Sprintf("%s, about %s remaining", status, formatDuration((remaining + time.Minute - 1).Truncate(time.Minute)))
+}
+
+// formatDuration formats the whole minutes of d, e.g. "4m" or "1h5m".
+func formatDuration(d time.Duration) string {
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	default:
+		return fmt.Sprintf("%dh%dm", h, m)
+	}
+}
@@ -0,0 +1,86 @@
+package progress
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	current := start
+	origNow := now
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = origNow })
+
+	tracker := NewTracker(Pods)
+	if d := cmp.Diff("0/0 pods ready", tracker.String()); d != "" {
+		t.Error("status mismatch (-want +got):", d)
+	}
+
+	tests := []struct {
+		elapsed     time.Duration
+		done, total int64
+		exp         string
+	}{
+		{elapsed: 0, done: 2, total: 12, exp: "2/12 pods ready"},
+		// too early to estimate
+		{elapsed: 5 * time.Second, done: 3, total: 12, exp: "3/12 pods ready"},
+		// 2 pods in 1m, 8 pods remaining
+		{elapsed: time.Minute, done: 4, total: 12, exp: "4/12 pods ready, about 4m remaining"},
+		// 9 pods in 90s, 1 pod remaining
+		{elapsed: 90 * time.Second, done: 11, total: 12, exp: "11/12 pods ready, less than a minute remaining"},
+		// 8 pods in 2h, 40 pods remaining
+		{elapsed: 2 * time.Hour, done: 10, total: 50, exp: "10/50 pods ready, about 10h remaining"},
+		{elapsed: 2 * time.Hour, done: 50, total: 50, exp: "50/50 pods ready"},
+	}
+	for _, tt := range tests {
+		current = start.Add(tt.elapsed)
+		tracker.Update(tt.done, tt.total)
+		if d := cmp.Diff(tt.exp, tracker.String()); d != "" {
+			t.Error("status mismatch (-want +got):", d)
+		}
+	}
+}
+
+func TestBytes(t *testing.T) {
+	if d := cmp.Diff("1.5/10.0 MB pulled", Bytes(3*512*1024, 10*1024*1024)); d != "" {
+		t.Error("status mismatch (-want +got):", d)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		4 * time.Minute:              "4m",
+		time.Hour:                    "1h",
+		time.Hour + 5*time.Minute:    "1h5m",
+		26*time.Hour + 2*time.Minute: "26h2m",
+	}
+	for d, exp := range tests {
+		if diff := cmp.Diff(exp, formatDuration(d)); diff != "" {
+			t.Error("duration mismatch (-want +got):", diff)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	var reported []string
+	reporters := Join(func(status string) {
+		reported = append(reported, status)
+	}, 2)
+
+	reporters[1]("migrating 0.57 -> 0.63: 3 migrations applied")
+	reporters[0]("3/12 pods ready")
+	reporters[1]("")
+	reporters[0]("")
+
+	exp := []string{
+		"migrating 0.57 -> 0.63: 3 migrations applied",
+		"3/12 pods ready; migrating 0.57 -> 0.63: 3 migrations applied",
+		"3/12 pods ready",
+		"",
+	}
+	if d := cmp.Diff(exp, reported); d != "" {
+		t.Error("reported mismatch (-want +got):", d)
+	}
+}
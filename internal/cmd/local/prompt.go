@@ -0,0 +1,55 @@
+package local
+
+import (
+	"fmt"
+	"github.com/pterm/pterm"
+)
+
+// prompter prompts the user for input, primarily for testing purposes.
+type prompter interface {
+	// Text prompts for text, returning def if nothing is entered.
+	Text(prompt, def string) (string, error)
+	// Secret prompts for text without displaying it.
+	Secret(prompt string) (string, error)
+	// Select prompts to select one of the options.
+	Select(prompt string, options []string, def string) (string, error)
+	// Confirm prompts for a yes or no answer.
+	Confirm(prompt string, def bool) (bool, error)
+}
+
+var _ prompter = (*ptermPrompter)(nil)
+
+// ptermPrompter prompts with the pterm interactive printers, which require stdin to be a terminal.
+type ptermPrompter struct{}
+
+func (ptermPrompter) Text(prompt, def string) (string, error) {
+	value, err := pterm.DefaultInteractiveTextInput.WithDefaultText(prompt).WithDefaultValue(def).Show()
+	if err != nil {
+		return "", fmt.Errorf("could not prompt for %s: %w", prompt, err)
+	}
+	return value, nil
+}
+
+func (ptermPrompter) Secret(prompt string) (string, error) {
+	value, err := pterm.DefaultInteractiveTextInput.WithDefaultText(prompt).WithMask("*").Show()
+	if err != nil {
+		return "", fmt.Errorf("could not prompt for %s: %w", prompt, err)
+	}
+	return value, nil
+}
+
+func (ptermPrompter) Select(prompt string, options []string, def string) (string, error) {
+	value, err := pterm.DefaultInteractiveSelect.WithDefaultText(prompt).WithOptions(options).WithDefaultOption(def).Show()
+	if err != nil {
+		return "", fmt.Errorf("could not prompt for %s: %w", prompt, err)
+	}
+	return value, nil
+}
+
+func (ptermPrompter) Confirm(prompt string, def bool) (bool, error) {
+	value, err := pterm.DefaultInteractiveConfirm.WithDefaultValue(def).Show(prompt)
+	if err != nil {
+		return false, fmt.Errorf("could not prompt for %s: %w", prompt, err)
+	}
+	return value, nil
+}
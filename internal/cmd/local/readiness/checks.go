@@ -0,0 +1,163 @@
+package readiness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// HTTPClient is the http client interface used by the checks, primarily for testing purposes.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// PodLister is the kubernetes client interface used by the Pods check, primarily for testing purposes.
+type PodLister interface {
+	PodList(ctx context.Context, namespace string) (*corev1.PodList, error)
+}
+
+// Ingress returns a Check which is ready once the url is being served by the ingress.
+// Either a 200 response, or a 401 response from the abctl basic-auth realm, is considered ready.
+func Ingress(client HTTPClient, url string) Check {
+	return New(fmt.Sprintf("ingress %s", url), func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("could not create request: %w", err)
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("could not send request: %w", err)
+		}
+		defer res.Body.Close()
+
+		switch {
+		// if no auth, we should get a 200
+		case res.StatusCode == http.StatusOK:
+			return nil
+		// if basic auth, we should get a 401 with a specific header that contains abctl
+		case res.StatusCode == http.StatusUnauthorized && strings.Contains(res.Header.Get("WWW-Authenticate"), "abctl"):
+			return nil
+		default:
+			return fmt.Errorf("unexpected status code %d", res.StatusCode)
+		}
+	})
+}
+
+// Health returns a Check which is ready once the Airbyte health api, served from the baseURL, reports it is available.
+// The user and pass are the basic-auth credentials of the ingress.
+func Health(client HTTPClient, baseURL, user, pass string) Check {
+	url := strings.TrimSuffix(baseURL, "/") + "/api/v1/health"
+	return New("airbyte api health", func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("could not create request: %w", err)
+		}
+		req.SetBasicAuth(user, pass)
+
+		res, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("could not send request: %w", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status code %d", res.StatusCode)
+		}
+
+		var health struct {
+			Available bool `json:"available"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&health); err != nil {
+			return fmt.Errorf("could not decode response: %w", err)
+		}
+		if !health.Available {
+			return fmt.Errorf("api reported it is not available")
+		}
+
+		return nil
+	})
+}
+
+// Pods returns a Check which is ready once every pod in the namespace is either running with all of its containers
+// ready, or has completed successfully.
+func Pods(client PodLister, namespace string) Check {
+	return New(fmt.Sprintf("pods in namespace %s", namespace), func(ctx context.Context) error {
+		pods, err := client.PodList(ctx, namespace)
+		if err != nil {
+			return fmt.Errorf("could not list pods: %w", err)
+		}
+		return podsReady(pods.Items)
+	})
+}
+
+// Component returns a Check with the name of the component, which is ready once every pod of the component, whose
+// names start with the prefix, is ready as defined by the Pods check.
+func Component(client PodLister, namespace, name, prefix string) Check {
+	return New(name, func(ctx context.Context) error {
+		pods, err := client.PodList(ctx, namespace)
+		if err != nil {
+			return fmt.Errorf("could not list pods: %w", err)
+		}
+
+		var component []corev1.Pod
+		for _, pod := range pods.Items {
+			if strings.HasPrefix(pod.Name, prefix) {
+				component = append(component, pod)
+			}
+		}
+		return podsReady(component)
+	})
+}
+
+// podsReady returns an error listing the pods which are not ready, or if there are no pods at all.
+func podsReady(pods []corev1.Pod) error {
+	if len(pods) == 0 {
+		return fmt.Errorf("no pods found")
+	}
+
+	var notReady []string
+	for _, pod := range pods {
+		if !podReady(pod) {
+			notReady = append(notReady, fmt.Sprintf("%s (%s)", pod.Name, pod.Status.Phase))
+		}
+	}
+	if len(notReady) > 0 {
+		sort.Strings(notReady)
+		return fmt.Errorf("pods not ready: %s", strings.Join(notReady, ", "))
+	}
+
+	return nil
+}
+
+// PodsReady returns the number of the pods which are ready, as defined by the Pods check.
+func PodsReady(pods []corev1.Pod) int {
+	var ready int
+	for _, pod := range pods {
+		if podReady(pod) {
+			ready++
+		}
+	}
+	return ready
+}
+
+// podReady returns true if the pod has completed successfully, or is running with every container ready.
+func podReady(pod corev1.Pod) bool {
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		return true
+	case corev1.PodRunning:
+		for _, status := range pod.Status.ContainerStatuses {
+			if !status.Ready {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,172 @@
+package readiness
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type mockHTTP struct {
+	do func(req *http.Request) (*http.Response, error)
+}
+
+func (m *mockHTTP) Do(req *http.Request) (*http.Response, error) {
+	return m.do(req)
+}
+
+func respond(status int, header http.Header, body string) *mockHTTP {
+	return &mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(body))}, nil
+	}}
+}
+
+func TestIngress(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *mockHTTP
+		ready  bool
+	}{
+		{name: "ok", client: respond(http.StatusOK, nil, ""), ready: true},
+		{name: "abctl basic auth", client: respond(http.StatusUnauthorized, http.Header{"Www-Authenticate": []string{`Basic realm="abctl"`}}, ""), ready: true},
+		{name: "other basic auth", client: respond(http.StatusUnauthorized, http.Header{"Www-Authenticate": []string{`Basic realm="other"`}}, "")},
+		{name: "bad gateway", client: respond(http.StatusBadGateway, nil, "")},
+		{name: "connection refused", client: &mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Ingress(tt.client, "http://localhost:8000").Ready(context.Background())
+			if d := cmp.Diff(tt.ready, err == nil); d != "" {
+				t.Error("ready mismatch (-want +got):", d, err)
+			}
+		})
+	}
+}
+
+func TestHealth(t *testing.T) {
+	client := &mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+		if d := cmp.Diff("http://localhost:8000/api/v1/health", req.URL.String()); d != "" {
+			t.Error("url mismatch (-want +got):", d)
+		}
+		if user, pass, _ := req.BasicAuth(); user != "user" || pass != "pass" {
+			t.Error("expected basic auth credentials")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"available": true}`))}, nil
+	}}
+	if err := Health(client, "http://localhost:8000/", "user", "pass").Ready(context.Background()); err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	for name, client := range map[string]*mockHTTP{
+		"unavailable":  respond(http.StatusOK, nil, `{"available": false}`),
+		"invalid body": respond(http.StatusOK, nil, `<html>`),
+		"unauthorized": respond(http.StatusUnauthorized, nil, ""),
+	} {
+		if err := Health(client, "http://localhost:8000", "user", "pass").Ready(context.Background()); err == nil {
+			t.Errorf("expected an error for %s", name)
+		}
+	}
+}
+
+type mockPodLister struct {
+	pods []corev1.Pod
+}
+
+func (m *mockPodLister) PodList(context.Context, string) (*corev1.PodList, error) {
+	return &corev1.PodList{Items: m.pods}, nil
+}
+
+func pod(name string, phase corev1.PodPhase, ready ...bool) corev1.Pod {
+	p := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: corev1.PodStatus{Phase: phase}}
+	for _, r := range ready {
+		p.Status.ContainerStatuses = append(p.Status.ContainerStatuses, corev1.ContainerStatus{Ready: r})
+	}
+	return p
+}
+
+func TestPods(t *testing.T) {
+	tests := []struct {
+		name string
+		pods []corev1.Pod
+		err  string
+	}{
+		{
+			name: "ready",
+			pods: []corev1.Pod{pod("server", corev1.PodRunning, true, true), pod("bootloader", corev1.PodSucceeded)},
+		},
+		{
+			name: "not ready",
+			pods: []corev1.Pod{pod("webapp", corev1.PodPending), pod("server", corev1.PodRunning, true, false), pod("db", corev1.PodRunning, true)},
+			err:  "pods not ready: server (Running), webapp (Pending)",
+		},
+		{
+			name: "no pods",
+			err:  "no pods found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := Pods(&mockPodLister{pods: tt.pods}, "airbyte-abctl")
+			if d := cmp.Diff("pods in namespace airbyte-abctl", check.Name()); d != "" {
+				t.Error("name mismatch (-want +got):", d)
+			}
+
+			var errMsg string
+			if err := check.Ready(context.Background()); err != nil {
+				errMsg = err.Error()
+			}
+			if d := cmp.Diff(tt.err, errMsg); d != "" {
+				t.Error("error mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestComponent(t *testing.T) {
+	tests := []struct {
+		name string
+		pods []corev1.Pod
+		err  string
+	}{
+		{
+			name: "ready",
+			pods: []corev1.Pod{pod("airbyte-abctl-temporal-7d9f", corev1.PodRunning, true), pod("airbyte-abctl-server-5c8b", corev1.PodPending)},
+		},
+		{
+			name: "not ready",
+			pods: []corev1.Pod{pod("airbyte-abctl-temporal-7d9f", corev1.PodRunning, false), pod("airbyte-abctl-server-5c8b", corev1.PodRunning, true)},
+			err:  "pods not ready: airbyte-abctl-temporal-7d9f (Running)",
+		},
+		{
+			name: "no pods of the component",
+			pods: []corev1.Pod{pod("airbyte-abctl-server-5c8b", corev1.PodRunning, true)},
+			err:  "no pods found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := Component(&mockPodLister{pods: tt.pods}, "airbyte-abctl", "temporal", "airbyte-abctl-temporal")
+			if d := cmp.Diff("temporal", check.Name()); d != "" {
+				t.Error("name mismatch (-want +got):", d)
+			}
+
+			var errMsg string
+			if err := check.Ready(context.Background()); err != nil {
+				errMsg = err.Error()
+			}
+			if d := cmp.Diff(tt.err, errMsg); d != "" {
+				t.Error("error mismatch (-want +got):", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package readiness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Check determines whether a single component of an Airbyte installation is ready.
+type Check interface {
+	// Name describes what is being checked, used when reporting the result.
+	Name() string
+	// Ready returns nil if the component is ready, otherwise an error explaining why it is not.
+	Ready(ctx context.Context) error
+}
+
+// Result is the outcome of running a single Check.
+type Result struct {
+	Name string
+	Err  error
+}
+
+// Run runs every check once, returning a Result for each in the same order as the checks.
+func Run(ctx context.Context, checks ...Check) []Result {
+	results := make([]Result, len(checks))
+	for i, check := range checks {
+		results[i] = Result{Name: check.Name(), Err: check.Ready(ctx)}
+	}
+	return results
+}
+
+// now returns the current time, defined here for testing purposes.
+var now = time.Now
+
+// Status is a Check which is not ready yet.
+type Status struct {
+	Name string
+	Err  error
+	// Since is how long the check has not been ready, since its first attempt.
+	Since time.Duration
+}
+
+// Reporter is called with the Status of every check which is not ready after each attempt of WaitReport.
+type Reporter func(notReady []Status)
+
+// Wait runs the checks, waiting interval between attempts, until every check is ready or the ctx is done.
+// A check which has been ready once is not run again.
+// If the ctx is done first, the returned error contains the most recent failure of every check which was not ready.
+func Wait(ctx context.Context, interval time.Duration, checks ...Check) error {
+	return WaitReport(ctx, interval, nil, checks...)
+}
+
+// WaitReport is Wait, additionally reporting which checks are not ready, and for how long, after each attempt.
+// The report may be nil.
+func WaitReport(ctx context.Context, interval time.Duration, report Reporter, checks ...Check) error {
+	start := now()
+	pending := checks
+	for {
+		var (
+			notReady []Check
+			statuses []Status
+			errs     []error
+		)
+		for _, check := range pending {
+			if err := check.Ready(ctx); err != nil {
+				since := now().Sub(start).Truncate(time.Second)
+				notReady = append(notReady, check)
+				statuses = append(statuses, Status{Name: check.Name(), Err: err, Since: since})
+				errs = append(errs, fmt.Errorf("%s: %w (not ready for %s)", check.Name(), err, since))
+			}
+		}
+		if len(notReady) == 0 {
+			return nil
+		}
+		pending = notReady
+		if report != nil {
+			report(statuses)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ctx.Err(), errors.Join(errs...))
+		case <-time.After(interval):
+		}
+	}
+}
+
+// check is a Check implemented by a function.
+type check struct {
+	name  string
+	ready func(ctx context.Context) error
+}
+
+func (c check) Name() string {
+	return c.name
+}
+
+func (c check) Ready(ctx context.Context) error {
+	return c.ready(ctx)
+}
+
+// New returns a Check with the name, which is ready when ready returns nil.
+func New(name string, ready func(ctx context.Context) error) Check {
+	return check{name: name, ready: ready}
+}
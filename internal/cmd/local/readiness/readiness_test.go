@@ -0,0 +1,109 @@
+package readiness
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	errNotReady := errors.New("not ready")
+	results := Run(context.Background(),
+		New("ready", func(context.Context) error { return nil }),
+		New("not ready", func(context.Context) error { return errNotReady }),
+	)
+
+	exp := []Result{{Name: "ready"}, {Name: "not ready", Err: errNotReady}}
+	if d := cmp.Diff(exp, results, cmp.Comparer(func(a, b error) bool { return errors.Is(a, b) })); d != "" {
+		t.Error("results mismatch (-want +got):", d)
+	}
+}
+
+func TestWait(t *testing.T) {
+	var readyCalls, pendingCalls int
+	ready := New("ready", func(context.Context) error {
+		readyCalls++
+		return nil
+	})
+	pending := New("pending", func(context.Context) error {
+		pendingCalls++
+		if pendingCalls < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+
+	if err := Wait(context.Background(), time.Millisecond, ready, pending); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// a ready check should not be run again
+	if d := cmp.Diff(1, readyCalls); d != "" {
+		t.Error("ready calls mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff(3, pendingCalls); d != "" {
+		t.Error("pending calls mismatch (-want +got):", d)
+	}
+}
+
+func TestWait_Timeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := Wait(ctx, time.Millisecond,
+		New("ready", func(context.Context) error { return nil }),
+		New("never", func(context.Context) error { return errors.New("still starting") }),
+	)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("expected deadline exceeded error, received", err)
+	}
+	if !strings.Contains(err.Error(), "never: still starting") {
+		t.Error("expected error to contain the failing check, received", err)
+	}
+	if strings.Contains(err.Error(), "ready:") {
+		t.Error("expected error to not contain the ready check, received", err)
+	}
+}
+
+func TestWaitReport(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var calls int
+	now = func() time.Time {
+		calls++
+		return start.Add(time.Duration(calls) * 30 * time.Second)
+	}
+	t.Cleanup(func() {
+		now = time.Now
+	})
+
+	errStarting := errors.New("still starting")
+	var attempts int
+	temporal := New("temporal", func(context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errStarting
+		}
+		return nil
+	})
+
+	var reported [][]Status
+	report := func(notReady []Status) {
+		reported = append(reported, notReady)
+	}
+
+	if err := WaitReport(context.Background(), time.Millisecond, report, New("webapp", func(context.Context) error { return nil }), temporal); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// the start is the first call of now, every failed attempt calls it again
+	exp := [][]Status{
+		{{Name: "temporal", Err: errStarting, Since: 30 * time.Second}},
+		{{Name: "temporal", Err: errStarting, Since: time.Minute}},
+	}
+	if d := cmp.Diff(exp, reported, cmp.Comparer(func(a, b error) bool { return errors.Is(a, b) })); d != "" {
+		t.Error("reported mismatch (-want +got):", d)
+	}
+}
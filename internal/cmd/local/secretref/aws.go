@@ -0,0 +1,60 @@
+package secretref
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// CommandRunner runs the command and returns its output.
+// Defined for testing purposes.
+type CommandRunner func(ctx context.Context, name string, args ...string) (string, error)
+
+// runCommand is the default CommandRunner, the stderr of the command is included in its error.
+func runCommand(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return string(out), err
+}
+
+// AWSSecretsManager resolves the references to the secrets of AWS Secrets Manager, read by the aws cli so its
+// credentials, profiles, and region are used as configured.
+type AWSSecretsManager struct {
+	run CommandRunner
+}
+
+// NewAWSSecretsManager returns the AWSSecretsManager provider.
+func NewAWSSecretsManager() *AWSSecretsManager {
+	return &AWSSecretsManager{run: runCommand}
+}
+
+// Resolve returns the value of the secret, or of the key of the json object it contains.
+func (a *AWSSecretsManager) Resolve(ctx context.Context, ref Ref) (string, error) {
+	out, err := a.run(ctx, "aws", "secretsmanager", "get-secret-value", "--secret-id", ref.Path, "--query", "SecretString", "--output", "text")
+	if errors.Is(err, exec.ErrNotFound) {
+		return "", errors.New("the aws cli is required to resolve the secrets of AWS Secrets Manager")
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not get secret value: %w", err)
+	}
+	value := strings.TrimSuffix(out, "\n")
+	if ref.Key == "" {
+		return value, nil
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &object); err != nil {
+		return "", fmt.Errorf("the secret %s is not a json object, it has no key %s", ref.Path, ref.Key)
+	}
+	keyValue, ok := object[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("the secret %s has no key %s", ref.Path, ref.Key)
+	}
+	return keyString(ref, keyValue)
+}
@@ -0,0 +1,53 @@
+package secretref
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"os/exec"
+	"testing"
+)
+
+func TestAWSSecretsManager_Resolve(t *testing.T) {
+	tests := []struct {
+		name   string
+		out    string
+		err    error
+		key    string
+		exp    string
+		expErr string
+	}{
+		{name: "secret", out: "l1c3ns3\n", exp: "l1c3ns3"},
+		{name: "key", out: `{"username": "airbyte", "password": "s3cr3t"}` + "\n", key: "password", exp: "s3cr3t"},
+		{name: "missing key", out: `{"username": "airbyte"}`, key: "password", expErr: "the secret airbyte/db has no key password"},
+		{name: "not json", out: "l1c3ns3", key: "password", expErr: "the secret airbyte/db is not a json object, it has no key password"},
+		{name: "no cli", err: exec.ErrNotFound, expErr: "the aws cli is required to resolve the secrets of AWS Secrets Manager"},
+		{name: "error", err: errors.New("exit status 254: ResourceNotFoundException"), expErr: "could not get secret value: exit status 254: ResourceNotFoundException"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &AWSSecretsManager{run: func(ctx context.Context, name string, args ...string) (string, error) {
+				exp := []string{"aws", "secretsmanager", "get-secret-value", "--secret-id", "airbyte/db", "--query", "SecretString", "--output", "text"}
+				if d := cmp.Diff(exp, append([]string{name}, args...)); d != "" {
+					t.Error("command mismatch (-want +got):", d)
+				}
+				return tt.out, tt.err
+			}}
+
+			value, err := a.Resolve(context.Background(), Ref{Scheme: SchemeAWSSecretsManager, Path: "airbyte/db", Key: tt.key})
+			if tt.expErr != "" {
+				if err == nil || err.Error() != tt.expErr {
+					t.Errorf("expected error %q, got %v", tt.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, value); d != "" {
+				t.Error("value mismatch (-want +got):", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+// Package secretref resolves references to the secrets of external secret stores, e.g. vault://secret/data/airbyte#password
+// or aws-sm://airbyte/license, so the secrets of an installation need not be stored in plaintext.
+package secretref
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	// SchemeVault references a key of a secret of HashiCorp Vault, as vault://path#key.
+	SchemeVault = "vault"
+	// SchemeAWSSecretsManager references a secret of AWS Secrets Manager, as aws-sm://name, or a key of a secret
+	// containing a json object, as aws-sm://name#key.
+	SchemeAWSSecretsManager = "aws-sm"
+)
+
+// Ref is a reference to a secret of a secret store.
+type Ref struct {
+	// Scheme identifies the secret store.
+	Scheme string
+	// Path is the path, or name, of the secret within the secret store.
+	Path string
+	// Key is the key of the value within the secret, empty for the whole secret.
+	Key string
+}
+
+// String returns the reference as scheme://path#key.
+func (r Ref) String() string {
+	s := fmt.Sprintf("%s://%s", r.Scheme, r.Path)
+	if r.Key != "" {
+		s += "#" + r.Key
+	}
+	return s
+}
+
+// Parse returns the reference s, and false if s is not a reference to any of the supported secret stores.
+// An error is returned if s is an invalid reference of a supported secret store.
+func Parse(s string) (Ref, bool, error) {
+	scheme, rest, ok := strings.Cut(s, "://")
+	if !ok || (scheme != SchemeVault && scheme != SchemeAWSSecretsManager) {
+		return Ref{}, false, nil
+	}
+
+	path, key, _ := strings.Cut(rest, "#")
+	ref := Ref{Scheme: scheme, Path: strings.Trim(path, "/"), Key: key}
+	if ref.Path == "" {
+		return Ref{}, true, fmt.Errorf("invalid secret reference %s, the path of the secret is required", s)
+	}
+	if scheme == SchemeVault && ref.Key == "" {
+		return Ref{}, true, fmt.Errorf("invalid secret reference %s, expected the format vault://path#key", s)
+	}
+	return ref, true, nil
+}
+
+// keyString returns the value of the key of the referenced secret as a string, other values are returned as json.
+func keyString(ref Ref, value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal the key %s of the secret %s: %w", ref.Key, ref.Path, err)
+	}
+	return string(raw), nil
+}
+
+// Provider resolves the references to the secrets of a secret store.
+type Provider interface {
+	Resolve(ctx context.Context, ref Ref) (string, error)
+}
+
+// Resolver resolves the references to the secrets of any supported secret store, each secret only once.
+type Resolver struct {
+	providers map[string]Provider
+	resolved  map[Ref]string
+}
+
+// Option for configuring the Resolver, primarily exists for testing
+type Option func(*Resolver)
+
+// WithProvider defines the provider of the secret store of the scheme.
+func WithProvider(scheme string, provider Provider) Option {
+	return func(r *Resolver) {
+		r.providers[scheme] = provider
+	}
+}
+
+// New returns a Resolver of vault, configured by the VAULT_ADDR, VAULT_TOKEN (or ~/.vault-token), and
+// VAULT_NAMESPACE environment variables, and of AWS Secrets Manager, accessed by the aws cli.
+func New(opts ...Option) *Resolver {
+	home, _ := os.UserHomeDir()
+	r := &Resolver{
+		providers: map[string]Provider{
+			SchemeVault:             NewVault(os.LookupEnv, home),
+			SchemeAWSSecretsManager: NewAWSSecretsManager(),
+		},
+		resolved: map[Ref]string{},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Resolve returns the value of the secret s references, or s unchanged if it is not a reference.
+func (r *Resolver) Resolve(ctx context.Context, s string) (string, error) {
+	ref, ok, err := Parse(s)
+	if err != nil || !ok {
+		return s, err
+	}
+	if value, ok := r.resolved[ref]; ok {
+		return value, nil
+	}
+
+	provider, ok := r.providers[ref.Scheme]
+	if !ok {
+		return "", fmt.Errorf("unsupported secret reference %s", ref)
+	}
+	value, err := provider.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("could not resolve secret %s: %w", ref, err)
+	}
+	r.resolved[ref] = value
+	return value, nil
+}
+
+// ResolveYAML returns the yaml document with every string value which is a reference replaced by the value of the
+// secret it references. The document is returned unchanged if it contains no references.
+func (r *Resolver) ResolveYAML(ctx context.Context, doc string) (string, error) {
+	var values map[string]interface{}
+	if err := yaml.Unmarshal([]byte(doc), &values); err != nil {
+		return "", fmt.Errorf("could not parse yaml: %w", err)
+	}
+
+	resolved, err := r.resolveValue(ctx, "", values)
+	if err != nil {
+		return "", err
+	}
+	if resolved == 0 {
+		return doc, nil
+	}
+
+	raw, err := yaml.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal yaml: %w", err)
+	}
+	return string(raw), nil
+}
+
+// resolveValue replaces the references within the maps and lists of v by the values of their secrets, returning the
+// number of references replaced. The path of v prefixes the errors, the keys of maps are resolved in order so the
+// first invalid reference is always the one reported.
+func (r *Resolver) resolveValue(ctx context.Context, path string, v interface{}) (int, error) {
+	// resolve replaces the element at the path by its secret if it is a reference, or resolves its own elements
+	resolve := func(path string, elem interface{}, set func(string)) (int, error) {
+		s, ok := elem.(string)
+		if !ok {
+			return r.resolveValue(ctx, path, elem)
+		}
+		if _, ok, _ := Parse(s); !ok {
+			return 0, nil
+		}
+		value, err := r.Resolve(ctx, s)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", path, err)
+		}
+		set(value)
+		return 1, nil
+	}
+
+	count := 0
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			elemPath := k
+			if path != "" {
+				elemPath = path + "." + k
+			}
+			n, err := resolve(elemPath, v[k], func(value string) { v[k] = value })
+			if err != nil {
+				return 0, err
+			}
+			count += n
+		}
+	case []interface{}:
+		for i := range v {
+			n, err := resolve(fmt.Sprintf("%s[%d]", path, i), v[i], func(value string) { v[i] = value })
+			if err != nil {
+				return 0, err
+			}
+			count += n
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,152 @@
+package secretref
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      string
+		exp    Ref
+		expOK  bool
+		expErr string
+	}{
+		{name: "vault", s: "vault://secret/data/airbyte#password", exp: Ref{Scheme: SchemeVault, Path: "secret/data/airbyte", Key: "password"}, expOK: true},
+		{name: "aws", s: "aws-sm://airbyte/license", exp: Ref{Scheme: SchemeAWSSecretsManager, Path: "airbyte/license"}, expOK: true},
+		{name: "aws key", s: "aws-sm://airbyte#db-password", exp: Ref{Scheme: SchemeAWSSecretsManager, Path: "airbyte", Key: "db-password"}, expOK: true},
+		{name: "plaintext", s: "password"},
+		{name: "url", s: "https://example.okta.com"},
+		{name: "vault without key", s: "vault://secret/data/airbyte", expOK: true, expErr: "expected the format vault://path#key"},
+		{name: "without path", s: "aws-sm://#key", expOK: true, expErr: "the path of the secret is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, ok, err := Parse(tt.s)
+			if ok != tt.expOK {
+				t.Errorf("expected ok %t, got %t", tt.expOK, ok)
+			}
+			if tt.expErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expErr) {
+					t.Errorf("expected error containing %q, got %v", tt.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, ref); d != "" {
+				t.Error("ref mismatch (-want +got):", d)
+			}
+			if ok && ref.String() != tt.s {
+				t.Errorf("expected string %s, got %s", tt.s, ref.String())
+			}
+		})
+	}
+}
+
+type mockProvider struct {
+	resolve func(ctx context.Context, ref Ref) (string, error)
+}
+
+func (m mockProvider) Resolve(ctx context.Context, ref Ref) (string, error) {
+	return m.resolve(ctx, ref)
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	calls := 0
+	r := New(WithProvider(SchemeVault, mockProvider{resolve: func(ctx context.Context, ref Ref) (string, error) {
+		calls++
+		if ref.Key == "missing" {
+			return "", errors.New("no such key")
+		}
+		return "value of " + ref.Key, nil
+	}}))
+
+	for i := 0; i < 2; i++ {
+		value, err := r.Resolve(context.Background(), "vault://secret/airbyte#password")
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if d := cmp.Diff("value of password", value); d != "" {
+			t.Error("value mismatch (-want +got):", d)
+		}
+	}
+	// the secret is only resolved once
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+
+	value, err := r.Resolve(context.Background(), "password")
+	if err != nil || value != "password" {
+		t.Errorf("expected the plaintext value unchanged, got %q, %v", value, err)
+	}
+
+	_, err = r.Resolve(context.Background(), "vault://secret/airbyte#missing")
+	if d := cmp.Diff("could not resolve secret vault://secret/airbyte#missing: no such key", err.Error()); d != "" {
+		t.Error("error mismatch (-want +got):", d)
+	}
+}
+
+func TestResolver_ResolveYAML(t *testing.T) {
+	r := New(
+		WithProvider(SchemeVault, mockProvider{resolve: func(ctx context.Context, ref Ref) (string, error) {
+			return "vault-" + ref.Key, nil
+		}}),
+		WithProvider(SchemeAWSSecretsManager, mockProvider{resolve: func(ctx context.Context, ref Ref) (string, error) {
+			return "", errors.New("access denied")
+		}}),
+	)
+
+	t.Run("references", func(t *testing.T) {
+		doc, err := r.ResolveYAML(context.Background(), `global:
+  database:
+    host: db.example.com
+    password: vault://secret/data/airbyte#db-password
+worker:
+  extraEnv:
+    - name: TOKEN
+      value: vault://secret/data/airbyte#token
+`)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		exp := `global:
+    database:
+        host: db.example.com
+        password: vault-db-password
+worker:
+    extraEnv:
+        - name: TOKEN
+          value: vault-token
+`
+		if d := cmp.Diff(exp, doc); d != "" {
+			t.Error("yaml mismatch (-want +got):", d)
+		}
+	})
+
+	t.Run("no references", func(t *testing.T) {
+		// the document is returned unchanged, including its formatting and comments
+		in := "# values\nglobal:\n  edition: community\n"
+		doc, err := r.ResolveYAML(context.Background(), in)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if d := cmp.Diff(in, doc); d != "" {
+			t.Error("yaml mismatch (-want +got):", d)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := r.ResolveYAML(context.Background(), "global:\n  license: [aws-sm://airbyte/license]\n")
+		exp := "global.license[0]: could not resolve secret aws-sm://airbyte/license: access denied"
+		if err == nil || err.Error() != exp {
+			t.Errorf("expected error %q, got %v", exp, err)
+		}
+	})
+}
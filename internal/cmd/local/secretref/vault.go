@@ -0,0 +1,115 @@
+package secretref
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultVaultAddr is the address of vault if VAULT_ADDR is not set, the same as the default of the vault cli.
+const defaultVaultAddr = "https://127.0.0.1:8200"
+
+// HTTPClient is the http client interface used by the Vault provider, primarily for testing purposes.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Vault resolves the references to the keys of the secrets of HashiCorp Vault, read by its http api.
+// The path of a reference is the api path of the secret, which for the kv version 2 secrets engine includes the data
+// prefix, e.g. vault://secret/data/airbyte#password.
+type Vault struct {
+	http      HTTPClient
+	lookupEnv func(string) (string, bool)
+	userHome  string
+}
+
+// NewVault returns the Vault provider configured by the environment variables of lookupEnv, VAULT_ADDR, VAULT_TOKEN,
+// and VAULT_NAMESPACE, the same as the vault cli. The token of ~/.vault-token is used if VAULT_TOKEN is not set.
+func NewVault(lookupEnv func(string) (string, bool), userHome string) *Vault {
+	return &Vault{
+		http:      &http.Client{Timeout: 10 * time.Second},
+		lookupEnv: lookupEnv,
+		userHome:  userHome,
+	}
+}
+
+// token returns the token authenticating the requests to vault.
+func (v *Vault) token() (string, error) {
+	if token, ok := v.lookupEnv("VAULT_TOKEN"); ok && token != "" {
+		return token, nil
+	}
+	raw, err := os.ReadFile(filepath.Join(v.userHome, ".vault-token"))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", errors.New("no vault token, set VAULT_TOKEN or run 'vault login'")
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not read vault token: %w", err)
+	}
+	return strings.TrimSpace(string(raw)), nil
+}
+
+// Resolve returns the value of the key of the secret.
+func (v *Vault) Resolve(ctx context.Context, ref Ref) (string, error) {
+	token, err := v.token()
+	if err != nil {
+		return "", err
+	}
+	addr, ok := v.lookupEnv("VAULT_ADDR")
+	if !ok || addr == "" {
+		addr = defaultVaultAddr
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(addr, "/"), ref.Path), nil)
+	if err != nil {
+		return "", fmt.Errorf("could not create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace, ok := v.lookupEnv("VAULT_NAMESPACE"); ok && namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	res, err := v.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("could not send request to vault: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("could not read response from vault: %w", err)
+	}
+
+	var secret struct {
+		Data   map[string]interface{} `json:"data"`
+		Errors []string               `json:"errors"`
+	}
+	// the errors are only reported if the body can be decoded, otherwise the status is sufficient
+	_ = json.Unmarshal(body, &secret)
+	if res.StatusCode != http.StatusOK {
+		if len(secret.Errors) > 0 {
+			return "", fmt.Errorf("vault responded with status %d: %s", res.StatusCode, strings.Join(secret.Errors, ", "))
+		}
+		return "", fmt.Errorf("vault responded with status %d", res.StatusCode)
+	}
+
+	data := secret.Data
+	// the secrets of the kv version 2 secrets engine contain the data alongside its metadata
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+
+	value, ok := data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("the secret %s has no key %s", ref.Path, ref.Key)
+	}
+	return keyString(ref, value)
+}
@@ -0,0 +1,120 @@
+package secretref
+
+import (
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type mockHTTP struct {
+	do func(req *http.Request) (*http.Response, error)
+}
+
+func (m *mockHTTP) Do(req *http.Request) (*http.Response, error) {
+	return m.do(req)
+}
+
+func TestVault_Resolve(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		status int
+		body   string
+		key    string
+		exp    string
+		expErr string
+	}{
+		{
+			name:   "kv v2",
+			env:    map[string]string{"VAULT_ADDR": "https://vault.example.com/", "VAULT_TOKEN": "s.token", "VAULT_NAMESPACE": "admin"},
+			status: http.StatusOK,
+			body:   `{"data": {"data": {"password": "s3cr3t"}, "metadata": {"version": 3}}}`,
+			key:    "password",
+			exp:    "s3cr3t",
+		},
+		{
+			name:   "kv v1",
+			env:    map[string]string{"VAULT_ADDR": "https://vault.example.com", "VAULT_TOKEN": "s.token", "VAULT_NAMESPACE": "admin"},
+			status: http.StatusOK,
+			body:   `{"data": {"port": 5432}}`,
+			key:    "port",
+			exp:    "5432",
+		},
+		{
+			name:   "missing key",
+			env:    map[string]string{"VAULT_ADDR": "https://vault.example.com", "VAULT_TOKEN": "s.token", "VAULT_NAMESPACE": "admin"},
+			status: http.StatusOK,
+			body:   `{"data": {"data": {"password": "s3cr3t"}, "metadata": {}}}`,
+			key:    "username",
+			expErr: "the secret secret/data/airbyte has no key username",
+		},
+		{
+			name:   "forbidden",
+			env:    map[string]string{"VAULT_ADDR": "https://vault.example.com", "VAULT_TOKEN": "s.token", "VAULT_NAMESPACE": "admin"},
+			status: http.StatusForbidden,
+			body:   `{"errors": ["permission denied"]}`,
+			key:    "password",
+			expErr: "vault responded with status 403: permission denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVault(func(key string) (string, bool) {
+				value, ok := tt.env[key]
+				return value, ok
+			}, t.TempDir())
+			v.http = &mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+				if d := cmp.Diff("https://vault.example.com/v1/secret/data/airbyte", req.URL.String()); d != "" {
+					t.Error("url mismatch (-want +got):", d)
+				}
+				if d := cmp.Diff("s.token", req.Header.Get("X-Vault-Token")); d != "" {
+					t.Error("token mismatch (-want +got):", d)
+				}
+				if d := cmp.Diff("admin", req.Header.Get("X-Vault-Namespace")); d != "" {
+					t.Error("namespace mismatch (-want +got):", d)
+				}
+				return &http.Response{StatusCode: tt.status, Body: io.NopCloser(strings.NewReader(tt.body))}, nil
+			}}
+
+			value, err := v.Resolve(context.Background(), Ref{Scheme: SchemeVault, Path: "secret/data/airbyte", Key: tt.key})
+			if tt.expErr != "" {
+				if err == nil || err.Error() != tt.expErr {
+					t.Errorf("expected error %q, got %v", tt.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.exp, value); d != "" {
+				t.Error("value mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestVault_Token(t *testing.T) {
+	home := t.TempDir()
+	noEnv := func(string) (string, bool) { return "", false }
+
+	if _, err := NewVault(noEnv, home).token(); err == nil || !strings.Contains(err.Error(), "no vault token") {
+		t.Error("expected a missing token error, got", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(home, ".vault-token"), []byte("s.file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	token, err := NewVault(noEnv, home).token()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff("s.file", token); d != "" {
+		t.Error("token mismatch (-want +got):", d)
+	}
+}
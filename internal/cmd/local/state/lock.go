@@ -0,0 +1,162 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/pterm/pterm"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// Lock is held by the abctl operation modifying the installation, so a concurrent operation fails instead of
+// interfering with it, e.g. by upgrading the helm release while it is still being installed.
+type Lock struct {
+	path string
+}
+
+// lockInfo is the content of the lock file, describing the operation holding it.
+type lockInfo struct {
+	PID       int       `yaml:"pid"`
+	Operation string    `yaml:"operation"`
+	StartedAt time.Time `yaml:"startedAt"`
+}
+
+// lockWriteTimeout is how long an empty or unparsable lock file is assumed to still be written by the process acquiring
+// it, after which it is stale, defined here for testing purposes.
+var lockWriteTimeout = 10 * time.Second
+
+// processRunning returns true if the process is running, defined here for testing purposes.
+var processRunning = func(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// only on windows does FindProcess fail for processes which are not running, as signals are not supported
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// AcquireLock acquires the lock file located at path for the operation, creating any missing directories.
+//
+// An error containing localerr.ErrLocked is returned if another running abctl process holds the lock.
+// A lock left behind by a process which is no longer running (e.g. it was killed) is taken over, as is a lock file
+// which is still empty or unparsable after lockWriteTimeout.
+func AcquireLock(path, operation string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("could not create directories for %s: %w", path, err)
+	}
+
+	data, err := yaml.Marshal(lockInfo{PID: os.Getpid(), Operation: operation, StartedAt: time.Now().UTC()})
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal lock: %w", err)
+	}
+
+	// a stale lock is removed once, so two processes taking it over concurrently cannot both acquire it
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = f.Write(data)
+			if errClose := f.Close(); err == nil {
+				err = errClose
+			}
+			if err != nil {
+				_ = os.Remove(path)
+				return nil, fmt.Errorf("could not write lock file %s: %w", path, err)
+			}
+			return &Lock{path: path}, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("could not create lock file %s: %w", path, err)
+		}
+
+		held, modTime, err := readLock(path)
+		if err != nil {
+			// the lock was released in the meantime
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		if held.PID == 0 {
+			// the lock is still being written by the process acquiring it, unless that process died before writing it
+			if time.Since(modTime) < lockWriteTimeout {
+				return nil, fmt.Errorf("%w: the lock file %s is being acquired", localerr.ErrLocked, path)
+			}
+		} else if processRunning(held.PID) {
+			return nil, fmt.Errorf("%w: '%s' (pid %d) has been running since %s",
+				localerr.ErrLocked, held.Operation, held.PID, held.StartedAt.Local().Format(time.RFC3339))
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("could not remove stale lock file %s: %w", path, err)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: the lock file %s was acquired concurrently", localerr.ErrLocked, path)
+}
+
+// readLock returns the operation holding the lock file located at path, and when the lock file was last modified.
+// The PID is zero if the lock file is empty or cannot be parsed.
+func readLock(path string) (lockInfo, time.Time, error) {
+	var info lockInfo
+
+	f, err := os.Open(path)
+	if err != nil {
+		return info, time.Time{}, fmt.Errorf("could not open lock file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return info, time.Time{}, fmt.Errorf("could not stat lock file %s: %w", path, err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return info, time.Time{}, fmt.Errorf("could not read lock file %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, &info); err != nil {
+		info = lockInfo{}
+	}
+
+	return info, stat.ModTime(), nil
+}
+
+// Release releases the lock.
+// No error is returned if the lock file no longer exists.
+func (l *Lock) Release() error {
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("could not remove lock file %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// LockInstallation acquires the lock file located at path for the operation, see AcquireLock, failing the spinner if
+// it cannot be acquired. It returns the function which releases the lock, only warning if it cannot be released.
+func LockInstallation(path, operation string, spinner *pterm.SpinnerPrinter) (func(), error) {
+	lock, err := AcquireLock(path, operation)
+	if err != nil {
+		if errors.Is(err, localerr.ErrLocked) {
+			spinner.Fail("Another abctl operation is modifying the installation")
+		} else {
+			spinner.Fail("Unable to lock the installation")
+		}
+		return nil, err
+	}
+
+	return func() {
+		if err := lock.Release(); err != nil {
+			pterm.Warning.Printfln("Unable to release the lock '%s', remove it before running abctl again", path)
+			logging.Debugf("could not release lock: %s", err)
+		}
+	}, nil
+}
@@ -0,0 +1,107 @@
+package state
+
+import (
+	"errors"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/pterm/pterm"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcquireLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "abctl.lock")
+
+	lock, err := AcquireLock(path, "abctl local install")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// this process is running, so the lock is held
+	_, err = AcquireLock(path, "abctl local uninstall")
+	if !errors.Is(err, localerr.ErrLocked) {
+		t.Fatal("expected the lock to be held, got", err)
+	}
+	if !strings.Contains(err.Error(), "'abctl local install'") {
+		t.Error("expected the error to describe the operation holding the lock, got", err)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Error("unexpected error for released lock", err)
+	}
+
+	lock, err = AcquireLock(path, "abctl local uninstall")
+	if err != nil {
+		t.Fatal("unexpected error for released lock", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+}
+
+func TestAcquireLock_Stale(t *testing.T) {
+	orig := processRunning
+	processRunning = func(pid int) bool { return pid != 4242 }
+	t.Cleanup(func() { processRunning = orig })
+
+	path := filepath.Join(t.TempDir(), "abctl.lock")
+	if err := os.WriteFile(path, []byte("pid: 4242\noperation: abctl local install\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := AcquireLock(path, "abctl local install")
+	if err != nil {
+		t.Fatal("expected the stale lock to be taken over, got", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// a partially written lock is being acquired by another process
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AcquireLock(path, "abctl local install"); !errors.Is(err, localerr.ErrLocked) {
+		t.Error("expected the lock to be held, got", err)
+	}
+
+	// an empty or unparsable lock is stale once the process acquiring it had the time to write it
+	for _, data := range []string{"", "pid: [", "operation: abctl local install\n"} {
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		old := time.Now().Add(-2 * lockWriteTimeout)
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+		lock, err := AcquireLock(path, "abctl local install")
+		if err != nil {
+			t.Fatalf("expected the stale lock %q to be taken over, got %v", data, err)
+		}
+		if err := lock.Release(); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+}
+
+func TestLockInstallation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "abctl.lock")
+
+	unlock, err := LockInstallation(path, "abctl local install", &pterm.DefaultSpinner)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if _, err := LockInstallation(path, "abctl gc", &pterm.DefaultSpinner); !errors.Is(err, localerr.ErrLocked) {
+		t.Error("expected the lock to be held, got", err)
+	}
+
+	unlock()
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Error("expected the lock to be released", err)
+	}
+}
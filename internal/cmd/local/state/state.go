@@ -0,0 +1,146 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/pterm/pterm"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State is the information abctl records about a local installation.
+type State struct {
+	// CreatedBy is the version of abctl which originally installed Airbyte.
+	CreatedBy string `yaml:"createdBy,omitempty"`
+	// CreatedAt is when Airbyte was originally installed.
+	CreatedAt time.Time `yaml:"createdAt,omitempty"`
+	// ModifiedBy is the version of abctl which last installed or upgraded Airbyte.
+	ModifiedBy string `yaml:"modifiedBy,omitempty"`
+	// ModifiedAt is when Airbyte was last installed or upgraded.
+	ModifiedAt time.Time `yaml:"modifiedAt,omitempty"`
+	// Paused contains the replicas of each workload which was scaled down when the installation was paused.
+	Paused map[string]int32 `yaml:"paused,omitempty"`
+	// IngressController is the ingress controller installed in front of Airbyte, empty for installations which
+	// predate the choice of ingress controller, as those always installed nginx.
+	IngressController string `yaml:"ingressController,omitempty"`
+	// IngressClass is the ingress class of the existing ingress controller serving Airbyte, if IngressController is
+	// none, empty for the default ingress class of the cluster.
+	IngressClass string `yaml:"ingressClass,omitempty"`
+	// APIPort is the port exposing the Airbyte api in addition to the webapp, zero if it is not exposed.
+	APIPort int `yaml:"apiPort,omitempty"`
+	// BindAddress is the host address the ports of the kind cluster are bound to, empty for all addresses.
+	BindAddress string `yaml:"bindAddress,omitempty"`
+	// CACert is the path of the PEM bundle of CA certificates trusted by the installation, empty if there is none.
+	CACert string `yaml:"caCert,omitempty"`
+	// HostAliases are the host=ip aliases resolved by the dns of the kind cluster.
+	HostAliases []string `yaml:"hostAliases,omitempty"`
+	// DNSServers are the [zone=]ip[:port] dns servers the dns of the kind cluster forwards queries to.
+	DNSServers []string `yaml:"dnsServers,omitempty"`
+	// HostGateway is true if host.docker.internal resolves to the docker host within the kind cluster.
+	HostGateway bool `yaml:"hostGateway,omitempty"`
+	// Disabled are the optional components of Airbyte which are not installed.
+	Disabled []string `yaml:"disabled,omitempty"`
+	// LowResource is true if Airbyte was installed with the low resource preset.
+	LowResource bool `yaml:"lowResource,omitempty"`
+	// Tuning are the values of the job concurrency and resource flags which differ from their defaults.
+	Tuning map[string]string `yaml:"tuning,omitempty"`
+	// Credentials references the secret containing the instance admin credentials generated on first install, their
+	// values are never recorded.
+	Credentials *SecretRef `yaml:"credentials,omitempty"`
+}
+
+// SecretRef references a kubernetes secret.
+type SecretRef struct {
+	Namespace string `yaml:"namespace"`
+	Name      string `yaml:"name"`
+}
+
+// Load returns the State stored in the file located at path.
+// If no file exists, an empty State is returned.
+func Load(path string) (State, error) {
+	var s State
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return s, nil
+		}
+		return s, fmt.Errorf("could not read state file %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("could not unmarshal state file %s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+// Save writes the State to the file located at path, creating any missing directories.
+func Save(path string, s State) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("could not marshal state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("could not create directories for %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("could not write state file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Remove removes the state file located at path.
+// No error is returned if the file does not exist.
+func Remove(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("could not remove state file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Touch records that the installation was modified by the version of abctl.
+func (s *State) Touch(version string) {
+	now := time.Now().UTC()
+	if s.CreatedBy == "" {
+		s.CreatedBy = version
+		s.CreatedAt = now
+	}
+	s.ModifiedBy = version
+	s.ModifiedAt = now
+}
+
+// Check verifies that the version of abctl is safe to operate against the installation.
+//
+// Running an older major or minor version of abctl against an installation last modified by a newer version
+// returns an error containing localerr.ErrVersion, as the older version may not understand the newer chart layout.
+// Running an older patch version, or a version that cannot be compared (e.g. "dev"), only results in a warning.
+func (s State) Check(version string) error {
+	if s.ModifiedBy == "" || s.ModifiedBy == version {
+		return nil
+	}
+
+	if !semver.IsValid(version) || !semver.IsValid(s.ModifiedBy) {
+		pterm.Warning.Printfln("This installation was last modified by abctl %s, which cannot be compared to the current version %s", s.ModifiedBy, version)
+		return nil
+	}
+
+	if semver.Compare(version, s.ModifiedBy) >= 0 {
+		return nil
+	}
+
+	if semver.MajorMinor(version) == semver.MajorMinor(s.ModifiedBy) {
+		pterm.Warning.Printfln("This installation was last modified by a newer version of abctl (%s), the current version is %s", s.ModifiedBy, version)
+		return nil
+	}
+
+	return fmt.Errorf("%w: this installation was last modified by abctl %s, which is newer than the current version %s", localerr.ErrVersion, s.ModifiedBy, version)
+}
@@ -0,0 +1,90 @@
+package state
+
+import (
+	"errors"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/google/go-cmp/cmp"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.yaml")
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal("unexpected error for missing file", err)
+	}
+	if d := cmp.Diff(State{}, s); d != "" {
+		t.Error("state mismatch (-want +got):", d)
+	}
+
+	s.Touch("v0.5.0")
+	if err := Save(path, s); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(s, loaded); d != "" {
+		t.Error("state mismatch (-want +got):", d)
+	}
+
+	if err := Remove(path); err != nil {
+		t.Error("unexpected error", err)
+	}
+	if err := Remove(path); err != nil {
+		t.Error("unexpected error for missing file", err)
+	}
+}
+
+func TestState_Touch(t *testing.T) {
+	var s State
+	s.Touch("v0.5.0")
+	s.Touch("v0.6.0")
+
+	if d := cmp.Diff("v0.5.0", s.CreatedBy); d != "" {
+		t.Error("created by mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff("v0.6.0", s.ModifiedBy); d != "" {
+		t.Error("modified by mismatch (-want +got):", d)
+	}
+	if s.ModifiedAt.Before(s.CreatedAt) {
+		t.Error("expected modified at to not be before created at")
+	}
+}
+
+func TestState_Check(t *testing.T) {
+	tests := []struct {
+		name       string
+		modifiedBy string
+		version    string
+		expErr     bool
+	}{
+		{name: "no state", version: "v0.5.0"},
+		{name: "same version", modifiedBy: "v0.5.0", version: "v0.5.0"},
+		{name: "upgrade", modifiedBy: "v0.5.0", version: "v0.6.0"},
+		{name: "patch downgrade", modifiedBy: "v0.5.1", version: "v0.5.0"},
+		{name: "minor downgrade", modifiedBy: "v0.6.0", version: "v0.5.0", expErr: true},
+		{name: "major downgrade", modifiedBy: "v1.0.0", version: "v0.5.0", expErr: true},
+		{name: "dev version", modifiedBy: "v1.0.0", version: "dev"},
+		{name: "dev state", modifiedBy: "dev", version: "v0.5.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := State{ModifiedBy: tt.modifiedBy}.Check(tt.version)
+			if tt.expErr {
+				if !errors.Is(err, localerr.ErrVersion) {
+					t.Error("expected ErrVersion, received", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Error("unexpected error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package watchdog
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cooldown is how long after a deployment was restarted before the watchdog will restart it again, giving the
+// restarted pods time to become ready and preventing a deployment which always crashes from being restarted
+// continuously.
+const Cooldown = 10 * time.Minute
+
+// Remediator finds and restarts crashed deployments, primarily for testing purposes.
+type Remediator interface {
+	// CrashedDeployments returns the crashed deployments, keyed by name with the reason they crashed.
+	CrashedDeployments(ctx context.Context) (map[string]string, error)
+	// RestartDeployment restarts the deployment.
+	RestartDeployment(ctx context.Context, name string) error
+}
+
+// Check finds the crashed deployments and restarts each of them, unless it was restarted within the Cooldown.
+// The prev Status is the Status of the previous check, the returned Status is the outcome of this check.
+func Check(ctx context.Context, r Remediator, prev Status, now time.Time) Status {
+	s := Status{CheckedAt: now, Restarts: prev.Restarts}
+
+	crashed, err := r.CrashedDeployments(ctx)
+	if err != nil {
+		s.Error = err.Error()
+		return s
+	}
+	s.Healthy = len(crashed) == 0
+	if !s.Healthy {
+		s.Crashed = crashed
+	}
+
+	lastRestart := map[string]time.Time{}
+	for _, restart := range prev.Restarts {
+		lastRestart[restart.Deployment] = restart.At
+	}
+
+	deployments := make([]string, 0, len(crashed))
+	for deployment := range crashed {
+		deployments = append(deployments, deployment)
+	}
+	sort.Strings(deployments)
+
+	var errs []string
+	for _, deployment := range deployments {
+		if at, ok := lastRestart[deployment]; ok && now.Sub(at) < Cooldown {
+			continue
+		}
+		if err := r.RestartDeployment(ctx, deployment); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		s.Restarts = append(s.Restarts, Restart{Deployment: deployment, Reason: crashed[deployment], At: now})
+	}
+	if len(errs) > 0 {
+		s.Error = fmt.Sprintf("could not restart deployments: %s", strings.Join(errs, "; "))
+	}
+
+	if len(s.Restarts) > maxRestarts {
+		s.Restarts = s.Restarts[len(s.Restarts)-maxRestarts:]
+	}
+
+	return s
+}
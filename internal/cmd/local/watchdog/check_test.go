@@ -0,0 +1,86 @@
+package watchdog
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+type mockRemediator struct {
+	crashed   map[string]string
+	err       error
+	restarted []string
+}
+
+func (m *mockRemediator) CrashedDeployments(context.Context) (map[string]string, error) {
+	return m.crashed, m.err
+}
+
+func (m *mockRemediator) RestartDeployment(_ context.Context, name string) error {
+	m.restarted = append(m.restarted, name)
+	return nil
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := &mockRemediator{crashed: map[string]string{
+		"server": "pod server-1 failed",
+		"worker": "pod worker-1 container main is in CrashLoopBackOff",
+	}}
+	prev := Status{Restarts: []Restart{
+		// restarted within the cooldown, must not be restarted again
+		{Deployment: "worker", Reason: "pod worker-0 failed", At: now.Add(-time.Minute)},
+		// restarted prior to the cooldown, may be restarted again
+		{Deployment: "server", Reason: "pod server-0 failed", At: now.Add(-time.Hour)},
+	}}
+
+	s := Check(context.Background(), r, prev, now)
+
+	if d := cmp.Diff([]string{"server"}, r.restarted); d != "" {
+		t.Error("restarted mismatch (-want +got):", d)
+	}
+	exp := Status{
+		CheckedAt: now,
+		Crashed:   r.crashed,
+		Restarts: append(prev.Restarts, Restart{
+			Deployment: "server", Reason: "pod server-1 failed", At: now,
+		}),
+	}
+	if d := cmp.Diff(exp, s); d != "" {
+		t.Error("status mismatch (-want +got):", d)
+	}
+}
+
+func TestCheck_Healthy(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := &mockRemediator{crashed: map[string]string{}}
+
+	s := Check(context.Background(), r, Status{}, now)
+	if d := cmp.Diff(Status{CheckedAt: now, Healthy: true}, s); d != "" {
+		t.Error("status mismatch (-want +got):", d)
+	}
+
+	r.err = errors.New("cluster unavailable")
+	s = Check(context.Background(), r, Status{}, now)
+	if d := cmp.Diff(Status{CheckedAt: now, Error: "cluster unavailable"}, s); d != "" {
+		t.Error("status mismatch (-want +got):", d)
+	}
+}
+
+func TestCheck_MaxRestarts(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var prev Status
+	for i := 0; i < maxRestarts; i++ {
+		prev.Restarts = append(prev.Restarts, Restart{Deployment: "old", At: now.Add(-24 * time.Hour)})
+	}
+
+	s := Check(context.Background(), &mockRemediator{crashed: map[string]string{"server": "failed"}}, prev, now)
+	if d := cmp.Diff(maxRestarts, len(s.Restarts)); d != "" {
+		t.Error("restarts mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff("server", s.Restarts[maxRestarts-1].Deployment); d != "" {
+		t.Error("latest restart mismatch (-want +got):", d)
+	}
+}
@@ -0,0 +1,273 @@
+package watchdog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// launchdLabel is the label of the launchd agent.
+	launchdLabel = "io.airbyte.abctl.watchdog"
+	// systemdUnit is the name (without suffix) of the systemd user service and timer.
+	systemdUnit = "abctl-watchdog"
+	// cronMarker identifies the crontab entry of the watchdog.
+	cronMarker = "# abctl-watchdog"
+)
+
+// CommandRunner runs the command and returns its combined output.
+// Defined for testing purposes.
+type CommandRunner func(ctx context.Context, name string, args ...string) (string, error)
+
+// RunCommand is the default CommandRunner.
+var RunCommand CommandRunner = func(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	return string(out), err
+}
+
+// Service is the background service which periodically runs a single watchdog check.
+type Service struct {
+	// Args are the executable and arguments which run a single watchdog check.
+	Args []string
+	// Interval is how often the check runs.
+	Interval time.Duration
+	// Home is the user's home directory, where the service definitions are written.
+	Home string
+	// LogFile is where the output of each check is written.
+	LogFile string
+}
+
+// Install installs and starts the Service for the goos, returning a description of what was installed.
+// A launchd agent is used on macOS. On Linux, a systemd user timer is used if systemd is available, otherwise a
+// crontab entry is used. Any previously installed Service is replaced.
+func Install(ctx context.Context, run CommandRunner, goos string, svc Service) (string, error) {
+	switch goos {
+	case "darwin":
+		path := filepath.Join(svc.Home, "Library", "LaunchAgents", launchdLabel+".plist")
+		if err := writeFile(path, launchdPlist(svc)); err != nil {
+			return "", err
+		}
+		// unload any previously loaded agent, which fails if it was never loaded
+		_, _ = run(ctx, "launchctl", "unload", path)
+		if out, err := run(ctx, "launchctl", "load", "-w", path); err != nil {
+			return "", fmt.Errorf("could not load launchd agent %s: %w: %s", path, err, out)
+		}
+		return fmt.Sprintf("launchd agent %s", path), nil
+	case "linux":
+		if _, err := run(ctx, "systemctl", "--user", "show-environment"); err == nil {
+			dir := filepath.Join(svc.Home, ".config", "systemd", "user")
+			if err := writeFile(filepath.Join(dir, systemdUnit+".service"), systemdService(svc)); err != nil {
+				return "", err
+			}
+			if err := writeFile(filepath.Join(dir, systemdUnit+".timer"), systemdTimer(svc)); err != nil {
+				return "", err
+			}
+			if out, err := run(ctx, "systemctl", "--user", "daemon-reload"); err != nil {
+				return "", fmt.Errorf("could not reload systemd: %w: %s", err, out)
+			}
+			if out, err := run(ctx, "systemctl", "--user", "enable", "--now", systemdUnit+".timer"); err != nil {
+				return "", fmt.Errorf("could not enable systemd timer: %w: %s", err, out)
+			}
+			return fmt.Sprintf("systemd user timer %s.timer", systemdUnit), nil
+		}
+
+		if err := updateCrontab(ctx, run, cronEntry(svc)); err != nil {
+			return "", err
+		}
+		return "crontab entry", nil
+	default:
+		return "", fmt.Errorf("the watchdog is not supported on %s", goos)
+	}
+}
+
+// Uninstall stops and removes the Service for the goos, if it is installed.
+func Uninstall(ctx context.Context, run CommandRunner, goos, home string) error {
+	switch goos {
+	case "darwin":
+		path := filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist")
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		_, _ = run(ctx, "launchctl", "unload", "-w", path)
+		return removeFile(path)
+	case "linux":
+		dir := filepath.Join(home, ".config", "systemd", "user")
+		timer := filepath.Join(dir, systemdUnit+".timer")
+		if _, err := os.Stat(timer); err == nil {
+			_, _ = run(ctx, "systemctl", "--user", "disable", "--now", systemdUnit+".timer")
+			if err := removeFile(timer); err != nil {
+				return err
+			}
+			if err := removeFile(filepath.Join(dir, systemdUnit+".service")); err != nil {
+				return err
+			}
+			_, _ = run(ctx, "systemctl", "--user", "daemon-reload")
+		}
+		// the crontab entry is removed regardless, as systemd may not have been available when it was installed
+		return updateCrontab(ctx, run, "")
+	default:
+		return fmt.Errorf("the watchdog is not supported on %s", goos)
+	}
+}
+
+// launchdPlist returns the launchd agent definition of the Service.
+func launchdPlist(svc Service) string {
+	var args strings.Builder
+	for _, arg := range svc.Args {
+		args.WriteString(fmt.Sprintf("\t\t<string>%s</string>\n", xmlEscape(arg)))
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+%s	</array>
+	<key>StartInterval</key>
+	<integer>%d</integer>
+	<key>StandardOutPath</key>
+	<string>%s</string>
+	<key>StandardErrorPath</key>
+	<string>%s</string>
+</dict>
+</plist>
+`, launchdLabel, args.String(), int(svc.Interval.Seconds()), xmlEscape(svc.LogFile), xmlEscape(svc.LogFile))
+}
+
+// systemdService returns the systemd user service definition of the Service.
+func systemdService(svc Service) string {
+	return fmt.Sprintf(`[Unit]
+Description=abctl watchdog, restarts crashed Airbyte deployments
+
+[Service]
+Type=oneshot
+ExecStart=%s
+StandardOutput=append:%s
+StandardError=append:%s
+`, shellJoin(svc.Args), svc.LogFile, svc.LogFile)
+}
+
+// systemdTimer returns the systemd user timer definition, which runs the Service every Interval.
+func systemdTimer(svc Service) string {
+	return fmt.Sprintf(`[Unit]
+Description=Run the abctl watchdog every %s
+
+[Timer]
+OnBootSec=%ds
+OnUnitActiveSec=%ds
+
+[Install]
+WantedBy=timers.target
+`, svc.Interval, int(svc.Interval.Seconds()), int(svc.Interval.Seconds()))
+}
+
+// cronEntry returns the crontab entry of the Service. As cron has a resolution of minutes, the interval is rounded
+// to the nearest minute (with a minimum of one minute).
+func cronEntry(svc Service) string {
+	minutes := int(svc.Interval.Round(time.Minute).Minutes())
+	if minutes < 1 {
+		minutes = 1
+	}
+	return fmt.Sprintf("*/%d * * * * %s >> %s 2>&1 %s", minutes, shellJoin(svc.Args), shellQuote(svc.LogFile), cronMarker)
+}
+
+// mergeCrontab returns the crontab with any existing watchdog entry replaced by the entry.
+// If the entry is empty, any existing watchdog entry is removed.
+func mergeCrontab(crontab, entry string) string {
+	var lines []string
+	for _, line := range strings.Split(crontab, "\n") {
+		if line == "" || strings.HasSuffix(line, cronMarker) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if entry != "" {
+		lines = append(lines, entry)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// updateCrontab replaces the watchdog entry of the user's crontab with the entry, or removes it if the entry is empty.
+func updateCrontab(ctx context.Context, run CommandRunner, entry string) error {
+	// crontab -l fails if the user has no crontab
+	current, err := run(ctx, "crontab", "-l")
+	if err != nil {
+		if entry == "" {
+			return nil
+		}
+		current = ""
+	}
+
+	updated := mergeCrontab(current, entry)
+	if updated == current {
+		return nil
+	}
+
+	f, err := os.CreateTemp("", "abctl-crontab")
+	if err != nil {
+		return fmt.Errorf("could not create temporary crontab: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(updated); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("could not write temporary crontab: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("could not write temporary crontab: %w", err)
+	}
+
+	if out, err := run(ctx, "crontab", f.Name()); err != nil {
+		return fmt.Errorf("could not update crontab: %w: %s", err, out)
+	}
+	return nil
+}
+
+func writeFile(path, contents string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("could not create directory %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+	return nil
+}
+
+func removeFile(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not remove %s: %w", path, err)
+	}
+	return nil
+}
+
+// shellJoin joins the args into a single command, quoting any arg which requires it.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote single quotes the s if it contains any characters which are not safe to use unquoted.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// xmlEscape escapes the s for use within an xml element.
+func xmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,167 @@
+package watchdog
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testService = Service{
+	Args:     []string{"/usr/local/bin/abctl", "local", "watchdog", "run"},
+	Interval: 5 * time.Minute,
+	LogFile:  "/home/airbyte/.airbyte/abctl/watchdog.log",
+}
+
+func TestInstall_Darwin(t *testing.T) {
+	svc := testService
+	svc.Home = t.TempDir()
+
+	var cmds []string
+	run := func(ctx context.Context, name string, args ...string) (string, error) {
+		cmds = append(cmds, name+" "+strings.Join(args, " "))
+		return "", nil
+	}
+
+	if _, err := Install(context.Background(), run, "darwin", svc); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	path := filepath.Join(svc.Home, "Library", "LaunchAgents", "io.airbyte.abctl.watchdog.plist")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("could not read plist", err)
+	}
+	for _, exp := range []string{"<string>/usr/local/bin/abctl</string>", "<integer>300</integer>"} {
+		if !strings.Contains(string(data), exp) {
+			t.Errorf("expected plist to contain %s", exp)
+		}
+	}
+	if d := cmp.Diff([]string{"launchctl unload " + path, "launchctl load -w " + path}, cmds); d != "" {
+		t.Error("commands mismatch (-want +got):", d)
+	}
+
+	cmds = nil
+	if err := Uninstall(context.Background(), run, "darwin", svc.Home); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Error("expected plist to be removed")
+	}
+}
+
+func TestInstall_Systemd(t *testing.T) {
+	svc := testService
+	svc.Home = t.TempDir()
+
+	var cmds []string
+	run := func(ctx context.Context, name string, args ...string) (string, error) {
+		cmds = append(cmds, name+" "+strings.Join(args, " "))
+		return "", nil
+	}
+
+	if _, err := Install(context.Background(), run, "linux", svc); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	dir := filepath.Join(svc.Home, ".config", "systemd", "user")
+	service, err := os.ReadFile(filepath.Join(dir, "abctl-watchdog.service"))
+	if err != nil {
+		t.Fatal("could not read service", err)
+	}
+	if !strings.Contains(string(service), "ExecStart=/usr/local/bin/abctl local watchdog run\n") {
+		t.Error("unexpected service", string(service))
+	}
+	timer, err := os.ReadFile(filepath.Join(dir, "abctl-watchdog.timer"))
+	if err != nil {
+		t.Fatal("could not read timer", err)
+	}
+	if !strings.Contains(string(timer), "OnUnitActiveSec=300s\n") {
+		t.Error("unexpected timer", string(timer))
+	}
+
+	exp := []string{
+		"systemctl --user show-environment",
+		"systemctl --user daemon-reload",
+		"systemctl --user enable --now abctl-watchdog.timer",
+	}
+	if d := cmp.Diff(exp, cmds); d != "" {
+		t.Error("commands mismatch (-want +got):", d)
+	}
+}
+
+func TestInstall_Cron(t *testing.T) {
+	svc := testService
+	svc.Home = t.TempDir()
+
+	crontab := "0 * * * * backup.sh\n"
+	run := func(ctx context.Context, name string, args ...string) (string, error) {
+		switch {
+		case name == "systemctl":
+			return "", errors.New("systemd is not available")
+		case name == "crontab" && args[0] == "-l":
+			return crontab, nil
+		case name == "crontab":
+			data, err := os.ReadFile(args[0])
+			crontab = string(data)
+			return "", err
+		}
+		return "", errors.New("unexpected command " + name)
+	}
+
+	if _, err := Install(context.Background(), run, "linux", svc); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	exp := "0 * * * * backup.sh\n" +
+		"*/5 * * * * /usr/local/bin/abctl local watchdog run >> /home/airbyte/.airbyte/abctl/watchdog.log 2>&1 # abctl-watchdog\n"
+	if d := cmp.Diff(exp, crontab); d != "" {
+		t.Error("crontab mismatch (-want +got):", d)
+	}
+
+	// installing again must replace the existing entry
+	if _, err := Install(context.Background(), run, "linux", svc); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(exp, crontab); d != "" {
+		t.Error("crontab mismatch (-want +got):", d)
+	}
+
+	if err := Uninstall(context.Background(), run, "linux", svc.Home); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff("0 * * * * backup.sh\n", crontab); d != "" {
+		t.Error("crontab mismatch (-want +got):", d)
+	}
+}
+
+func TestInstall_Unsupported(t *testing.T) {
+	run := func(ctx context.Context, name string, args ...string) (string, error) {
+		return "", nil
+	}
+	if _, err := Install(context.Background(), run, "windows", testService); err == nil {
+		t.Error("expected an error on windows")
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		input string
+		exp   string
+	}{
+		{input: "/usr/local/bin/abctl", exp: "/usr/local/bin/abctl"},
+		{input: "--context=kind-airbyte", exp: "--context=kind-airbyte"},
+		{input: "/Users/air byte/abctl", exp: "'/Users/air byte/abctl'"},
+		{input: "it's", exp: `'it'\''s'`},
+		{input: "", exp: "''"},
+	}
+
+	for _, tt := range tests {
+		if d := cmp.Diff(tt.exp, shellQuote(tt.input)); d != "" {
+			t.Errorf("%s mismatch (-want +got): %s", tt.input, d)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package watchdog
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxRestarts is the number of most recent restarts recorded in the Status.
+const maxRestarts = 20
+
+// Status is the outcome of the most recent watchdog check, which is recorded in the status file.
+type Status struct {
+	// CheckedAt is when the most recent check ran.
+	CheckedAt time.Time `yaml:"checkedAt"`
+	// Healthy is true if no crashed deployments were found by the most recent check.
+	Healthy bool `yaml:"healthy"`
+	// Error is why the most recent check could not complete, if it failed.
+	Error string `yaml:"error,omitempty"`
+	// Crashed are the crashed deployments found by the most recent check, with the reason they crashed.
+	Crashed map[string]string `yaml:"crashed,omitempty"`
+	// Restarts are the most recent restarts made by the watchdog, oldest first.
+	Restarts []Restart `yaml:"restarts,omitempty"`
+}
+
+// Restart is a deployment restarted by the watchdog.
+type Restart struct {
+	Deployment string    `yaml:"deployment"`
+	Reason     string    `yaml:"reason"`
+	At         time.Time `yaml:"at"`
+}
+
+// Load returns the Status stored in the file located at path.
+// If no file exists, an empty Status and fs.ErrNotExist are returned.
+func Load(path string) (Status, error) {
+	var s Status
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return s, err
+		}
+		return s, fmt.Errorf("could not read watchdog status file %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("could not unmarshal watchdog status file %s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+// Save writes the Status to the file located at path, creating any missing directories.
+func Save(path string, s Status) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("could not marshal watchdog status: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("could not create directories for %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("could not write watchdog status file %s: %w", path, err)
+	}
+
+	return nil
+}
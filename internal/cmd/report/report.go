@@ -0,0 +1,188 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/cli/browser"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// issuesURL is where a new GitHub issue of abctl is opened.
+const issuesURL = "https://github.com/airbytehq/abctl/issues/new"
+
+// maxURLLength is the longest issue url GitHub accepts, the log and stack of a report are shortened to fit it.
+const maxURLLength = 8000
+
+// launcher opens the url in the browser, defined here for testing purposes.
+var launcher = browser.OpenURL
+
+// NewCmdReport returns a cobra command for viewing and sending the failure reports of abctl.
+func NewCmdReport() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "View and send the reports of failed abctl commands",
+		Long: `View and send the reports of failed abctl commands.
+
+Whenever a command fails, or abctl crashes, a report is written to ` + paths.Crashes + ` with the command, its
+arguments and the error (any sensitive values redacted), the stack of a crash, the last lines of the log file, and
+the versions of abctl and Go. Only the most recent reports are kept. Nothing is sent unless 'abctl report send' is
+run, which opens a prefilled GitHub issue for review before it is submitted.`,
+	}
+
+	cmd.AddCommand(newCmdList(), newCmdShow(), newCmdSend())
+
+	return cmd
+}
+
+func newCmdList() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the reports, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reports, err := Load(paths.Crashes)
+			if err != nil {
+				pterm.Error.Println("Unable to load the reports")
+				return err
+			}
+			if len(reports) == 0 {
+				pterm.Info.Println("No reports found")
+				return nil
+			}
+
+			data := pterm.TableData{{"ID", "TIME", "VERSION", "SUMMARY"}}
+			for _, r := range reports {
+				data = append(data, []string{r.ID, r.Time.Local().Format(time.RFC3339), r.Version, r.Title()})
+			}
+			return pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+		},
+	}
+}
+
+func newCmdShow() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show [ID]",
+		Short: "Print a report, the newest unless an id is provided",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := load(args)
+			if err != nil {
+				return err
+			}
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(r)
+		},
+	}
+}
+
+func newCmdSend() *cobra.Command {
+	var flagYes bool
+
+	cmd := &cobra.Command{
+		Use:   "send [ID]",
+		Short: "Open a GitHub issue prefilled with a report, the newest unless an id is provided",
+		Long: `Open a GitHub issue prefilled with a report, the newest unless an id is provided.
+
+The issue is opened in the browser, where it can be reviewed and edited before it is submitted. If no browser can be
+opened, the url of the issue is printed instead.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := load(args)
+			if err != nil {
+				return err
+			}
+
+			body := issueBody(r)
+			if !flagYes {
+				if !term.IsTerminal(int(os.Stdin.Fd())) {
+					return errors.New("sending a report must be confirmed, run with --yes to send it without confirmation")
+				}
+				pterm.Println(body)
+				confirmed, err := pterm.DefaultInteractiveConfirm.Show("Open a GitHub issue with this report?")
+				if err != nil {
+					return fmt.Errorf("could not prompt for confirmation: %w", err)
+				}
+				if !confirmed {
+					pterm.Info.Println("The report was not sent")
+					return nil
+				}
+			}
+
+			issue := IssueURL(r)
+			if err := launcher(issue); err != nil {
+				pterm.Warning.Printfln("Unable to open the browser, open the following url to submit the report:\n%s", issue)
+				return nil
+			}
+			pterm.Success.Println("Opened the GitHub issue in the browser, review it before submitting it")
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flagYes, "yes", false, "send the report without printing it for confirmation first")
+
+	return cmd
+}
+
+// load returns the report with the id of the args, or the newest one if there is none.
+func load(args []string) (Report, error) {
+	reports, err := Load(paths.Crashes)
+	if err != nil {
+		pterm.Error.Println("Unable to load the reports")
+		return Report{}, err
+	}
+	var id string
+	if len(args) > 0 {
+		id = args[0]
+	}
+	return Find(reports, id)
+}
+
+// IssueURL returns the url opening a GitHub issue prefilled with the report.
+// The log, and if necessary the stack, are shortened for the url to not exceed maxURLLength.
+func IssueURL(r Report) string {
+	issue := func(r Report) string {
+		return issuesURL + "?" + url.Values{"title": {r.Title()}, "body": {issueBody(r)}}.Encode()
+	}
+
+	for _, field := range []*string{&r.Log, &r.Stack} {
+		for len(issue(r)) > maxURLLength && *field != "" {
+			// the last lines are the most relevant ones, the first half is dropped until it fits
+			lines := strings.Split(*field, "\n")
+			*field = strings.Join(lines[len(lines)/2+len(lines)%2:], "\n")
+		}
+	}
+	return issue(r)
+}
+
+// issueBody returns the markdown body of a GitHub issue of the report.
+func issueBody(r Report) string {
+	var b strings.Builder
+	b.WriteString("### Command\n```\n" + strings.TrimSpace(r.Command+" "+strings.Join(r.Args, " ")) + "\n```\n")
+	fmt.Fprintf(&b, "### Versions\nabctl %s", r.Version)
+	if r.Revision != "" {
+		fmt.Fprintf(&b, " (%s)", r.Revision)
+	}
+	fmt.Fprintf(&b, ", %s, %s/%s\n", r.GoVersion, r.OS, r.Arch)
+	if r.Error != "" {
+		b.WriteString("### Error\n```\n" + r.Error + "\n```\n")
+		if r.ErrorCode != "" {
+			b.WriteString("Error code " + r.ErrorCode + "\n")
+		}
+	}
+	if r.Panic != "" {
+		b.WriteString("### Panic\n```\n" + r.Panic + "\n" + r.Stack + "\n```\n")
+	}
+	if r.Log != "" {
+		b.WriteString("### Log\n```\n" + r.Log + "\n```\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,239 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/build"
+	"github.com/airbytehq/abctl/internal/cmd/audit"
+	"github.com/airbytehq/abctl/internal/redact"
+	"github.com/spf13/cobra"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// maxReports is the number of reports kept, older reports are removed when a new one is written.
+	maxReports = 20
+	// logLines is the number of the last lines of the log file included in a report.
+	logLines = 200
+	// tailBytes is the most of the log file read for its last lines, as it may grow to several MiB.
+	tailBytes = 256 * 1024
+	// ext is the extension of the report files.
+	ext = ".json"
+)
+
+// Report is the context of a failed command or panic of abctl.
+type Report struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	// Args are the positional arguments and the changed flags, with any sensitive value replaced by redact.Redacted.
+	Args      []string `json:"args,omitempty"`
+	Version   string   `json:"version"`
+	Revision  string   `json:"revision,omitempty"`
+	GoVersion string   `json:"go_version"`
+	OS        string   `json:"os"`
+	Arch      string   `json:"arch"`
+	Error     string   `json:"error,omitempty"`
+	// ErrorCode is the code of the localerr.LocalError of the error, if any.
+	ErrorCode string `json:"error_code,omitempty"`
+	// Panic is the value abctl panicked with, empty if it did not panic.
+	Panic string `json:"panic,omitempty"`
+	Stack string `json:"stack,omitempty"`
+	// Log are the last lines of the log file, with any known secrets redacted.
+	Log string `json:"log,omitempty"`
+}
+
+// New returns the Report of the cmd, which failed with the err, or panicked with the value recovered if it is
+// not nil. The logFile, if not empty, is the log file whose last lines are included in the report.
+func New(cmd *cobra.Command, err error, errorCode string, recovered interface{}, stack []byte, logFile string) Report {
+	now := time.Now().UTC()
+	r := Report{
+		ID:        now.Format("20060102T150405.000Z"),
+		Time:      now,
+		Version:   build.Version,
+		Revision:  build.Revision,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		ErrorCode: errorCode,
+	}
+	if cmd != nil {
+		r.Command = cmd.CommandPath()
+		r.Args = audit.Args(cmd)
+	}
+	if err != nil {
+		r.Error = redact.String(err.Error())
+	}
+	if recovered != nil {
+		r.Panic = redact.String(fmt.Sprint(recovered))
+		r.Stack = string(stack)
+	}
+	if logFile != "" {
+		if log, errLog := tail(logFile, logLines); errLog == nil {
+			r.Log = redact.String(log)
+		}
+	}
+	return r
+}
+
+// Reported returns true if a failure of the cmd is reported, which is every command but those of report itself.
+func Reported(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Name() == "report" && c.Parent() != nil && !c.Parent().HasParent() {
+			return false
+		}
+	}
+	return true
+}
+
+// Title returns a one line summary of the report.
+func (r Report) Title() string {
+	if r.Panic != "" {
+		return fmt.Sprintf("%s panicked: %s", r.Command, firstLine(r.Panic))
+	}
+	return fmt.Sprintf("%s failed: %s", r.Command, firstLine(r.Error))
+}
+
+// Write stores the report in the directory dir, returning the path of its file.
+// Only the most recent reports are kept, any older ones are removed.
+func Write(dir string, r Report) (string, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("could not encode report: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("could not create directory %s: %w", dir, err)
+	}
+
+	path := filepath.Join(dir, r.ID+ext)
+	// reports may contain details of the machine, they are only readable by the user
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("could not write report %s: %w", path, err)
+	}
+
+	return path, prune(dir, maxReports)
+}
+
+// Load returns the reports stored in the directory dir, newest first.
+// If the directory does not exist, no reports and no error are returned.
+func Load(dir string) ([]Report, error) {
+	names, err := reportFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []Report
+	for i := len(names) - 1; i >= 0; i-- {
+		data, err := os.ReadFile(filepath.Join(dir, names[i]))
+		if err != nil {
+			return nil, fmt.Errorf("could not read report %s: %w", names[i], err)
+		}
+		var r Report
+		// skip any report which cannot be decoded, e.g. one which was only partially written
+		if err := json.Unmarshal(data, &r); err != nil {
+			continue
+		}
+		reports = append(reports, r)
+	}
+
+	return reports, nil
+}
+
+// Find returns the report with the id, or the newest report if the id is empty.
+func Find(reports []Report, id string) (Report, error) {
+	if len(reports) == 0 {
+		return Report{}, errors.New("no reports found")
+	}
+	if id == "" {
+		return reports[0], nil
+	}
+	for _, r := range reports {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return Report{}, fmt.Errorf("report %s not found", id)
+}
+
+// reportFiles returns the names of the report files of the directory dir, oldest first.
+func reportFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not read directory %s: %w", dir, err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ext) {
+			names = append(names, e.Name())
+		}
+	}
+	// the ids are timestamps, so the names sort chronologically
+	sort.Strings(names)
+	return names, nil
+}
+
+// prune removes all but the newest keep reports of the directory dir.
+func prune(dir string, keep int) error {
+	names, err := reportFiles(dir)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(names)-keep; i++ {
+		if err := os.Remove(filepath.Join(dir, names[i])); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("could not remove report %s: %w", names[i], err)
+		}
+	}
+	return nil
+}
+
+// tail returns the last n lines of the file located at path, reading at most its last tailBytes.
+func tail(path string, n int) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("could not open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("could not stat %s: %w", path, err)
+	}
+	offset := info.Size() - tailBytes
+	if offset < 0 {
+		offset = 0
+	}
+	data := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(data, offset); err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	// the first line is likely partial if the file was not read from its start
+	if offset > 0 && len(lines) > 1 {
+		lines = lines[1:]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return string(bytes.Join(lines, []byte("\n"))), nil
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,207 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Doer is the http client interface used for downloading, primarily for testing purposes.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// partSuffix is appended to the destination path while a download is in progress.
+// A partially downloaded file is resumed, instead of being downloaded again from scratch.
+const partSuffix = ".part"
+
+// Option for configuring a download.
+type Option func(*options)
+
+type options struct {
+	maxRate  int64
+	attempts int
+	backoff  time.Duration
+}
+
+// WithMaxRate limits the download to rate bytes per second.
+// A rate of zero (or less) is unlimited.
+func WithMaxRate(rate int64) Option {
+	return func(o *options) {
+		o.maxRate = rate
+	}
+}
+
+// WithAttempts defines how many times the download will be attempted, resuming from where the
+// previous attempt stopped, and how long to wait between each attempt.
+func WithAttempts(attempts int, backoff time.Duration) Option {
+	return func(o *options) {
+		o.attempts = attempts
+		o.backoff = backoff
+	}
+}
+
+// File downloads the url to the dst file.
+//
+// The download is written to a temporary dst.part file which is renamed to dst once complete.
+// If a dst.part file already exists, from a previous failed or interrupted download, the download is resumed.
+// A failed attempt is retried, again resuming from where it stopped, up to the configured number of attempts.
+func File(ctx context.Context, client Doer, url, dst string, opts ...Option) error {
+	o := options{attempts: 5, backoff: 2 * time.Second}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("could not create directory for %s: %w", dst, err)
+	}
+
+	var err error
+	for attempt := 1; attempt <= o.attempts; attempt++ {
+		if err = fetch(ctx, client, url, dst+partSuffix, o.maxRate); err == nil {
+			break
+		}
+		if errors.Is(err, errPermanent) || attempt == o.attempts {
+			return fmt.Errorf("could not download %s: %w", url, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("could not download %s: %w", url, ctx.Err())
+		case <-time.After(o.backoff * time.Duration(attempt)):
+		}
+	}
+
+	if err := os.Rename(dst+partSuffix, dst); err != nil {
+		return fmt.Errorf("could not rename %s: %w", dst+partSuffix, err)
+	}
+
+	return nil
+}
+
+// errPermanent is returned for any error which will not succeed if retried.
+var errPermanent = errors.New("permanent failure")
+
+// fetch downloads the url to the part file, resuming from the end of the part file if it already exists.
+func fetch(ctx context.Context, client Doer, url, part string, maxRate int64) error {
+	var offset int64
+	if stat, err := os.Stat(part); err == nil {
+		offset = stat.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("%w: could not create request: %w", errPermanent, err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case res.StatusCode == http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case res.StatusCode == http.StatusOK:
+		// either a new download or the server does not support ranges, in which case start over
+		flags |= os.O_TRUNC
+	case res.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// the part file already contains the entire file
+		return nil
+	case res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("unexpected status code %d", res.StatusCode)
+	default:
+		return fmt.Errorf("%w: unexpected status code %d", errPermanent, res.StatusCode)
+	}
+
+	f, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("%w: could not open %s: %w", errPermanent, part, err)
+	}
+	defer f.Close()
+
+	var body io.Reader = res.Body
+	if maxRate > 0 {
+		body = &rateReader{ctx: ctx, r: res.Body, rate: maxRate, start: time.Now()}
+	}
+
+	if _, err := io.Copy(f, body); err != nil {
+		return fmt.Errorf("could not write %s: %w", part, err)
+	}
+
+	return nil
+}
+
+// rateReader limits the rate at which the underlying reader can be read.
+type rateReader struct {
+	ctx   context.Context
+	r     io.Reader
+	rate  int64
+	start time.Time
+	read  int64
+}
+
+func (r *rateReader) Read(p []byte) (int, error) {
+	// never read more than a tenth of a second's worth of data at a time to keep the rate smooth
+	if chunk := r.rate / 10; chunk > 0 && int64(len(p)) > chunk {
+		p = p[:chunk]
+	}
+
+	n, err := r.r.Read(p)
+	r.read += int64(n)
+
+	// sleep until the time at which the amount of data read would be allowed
+	expected := time.Duration(float64(r.read) / float64(r.rate) * float64(time.Second))
+	if wait := expected - time.Since(r.start); wait > 0 {
+		select {
+		case <-r.ctx.Done():
+			return n, r.ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+
+	return n, err
+}
+
+// ParseRate parses a human-readable rate (e.g. 500K, 2M, 1.5MB) into bytes per second.
+// Suffixes are case-insensitive and use multiples of 1024. A rate without a suffix is in bytes.
+func ParseRate(s string) (int64, error) {
+	orig := s
+	s = strings.ToUpper(strings.TrimSpace(s))
+	s = strings.TrimSuffix(s, "/S")
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "IB"), "B")
+
+	multiplier := int64(1)
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'K':
+			multiplier = 1024
+		case 'M':
+			multiplier = 1024 * 1024
+		case 'G':
+			multiplier = 1024 * 1024 * 1024
+		}
+		if multiplier > 1 {
+			s = s[:len(s)-1]
+		}
+	}
+
+	val, err := strconv.ParseFloat(s, 64)
+	if err != nil || val <= 0 {
+		return 0, fmt.Errorf("invalid rate '%s', expected a positive value such as 500K or 2M", orig)
+	}
+
+	return int64(val * float64(multiplier)), nil
+}
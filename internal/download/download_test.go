@@ -0,0 +1,253 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockDoer struct {
+	do func(req *http.Request) (*http.Response, error)
+}
+
+func (m *mockDoer) Do(req *http.Request) (*http.Response, error) {
+	return m.do(req)
+}
+
+// rangeServer returns a mockDoer serving content, supporting range requests.
+// The ranges of every request are appended to ranges.
+func rangeServer(content string, ranges *[]string) *mockDoer {
+	return &mockDoer{do: func(req *http.Request) (*http.Response, error) {
+		rng := req.Header.Get("Range")
+		*ranges = append(*ranges, rng)
+		if rng == "" {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(content))}, nil
+		}
+
+		offset, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %s", rng)
+		}
+		if offset >= len(content) {
+			return &http.Response{StatusCode: http.StatusRequestedRangeNotSatisfiable, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+		return &http.Response{StatusCode: http.StatusPartialContent, Body: io.NopCloser(strings.NewReader(content[offset:]))}, nil
+	}}
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("could not read file", err)
+	}
+	return string(data)
+}
+
+func TestFile(t *testing.T) {
+	var ranges []string
+	dst := filepath.Join(t.TempDir(), "nested", "chart.tgz")
+
+	if err := File(context.Background(), rangeServer("chart contents", &ranges), "https://example.com/chart.tgz", dst); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if d := cmp.Diff("chart contents", readFile(t, dst)); d != "" {
+		t.Error("contents mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff([]string{""}, ranges); d != "" {
+		t.Error("ranges mismatch (-want +got):", d)
+	}
+	if _, err := os.Stat(dst + partSuffix); !os.IsNotExist(err) {
+		t.Error("expected part file to be removed")
+	}
+}
+
+func TestFile_Resume(t *testing.T) {
+	tests := []struct {
+		name   string
+		part   string
+		ranges []string
+	}{
+		{name: "partial", part: "chart ", ranges: []string{"bytes=6-"}},
+		{name: "complete", part: "chart contents", ranges: []string{"bytes=14-"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ranges []string
+			dst := filepath.Join(t.TempDir(), "chart.tgz")
+			if err := os.WriteFile(dst+partSuffix, []byte(tt.part), 0644); err != nil {
+				t.Fatal("could not write part file", err)
+			}
+
+			if err := File(context.Background(), rangeServer("chart contents", &ranges), "https://example.com/chart.tgz", dst); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			if d := cmp.Diff("chart contents", readFile(t, dst)); d != "" {
+				t.Error("contents mismatch (-want +got):", d)
+			}
+			if d := cmp.Diff(tt.ranges, ranges); d != "" {
+				t.Error("ranges mismatch (-want +got):", d)
+			}
+		})
+	}
+}
+
+func TestFile_ResumeUnsupported(t *testing.T) {
+	dst := filepath.Join(t.TempDir(), "chart.tgz")
+	if err := os.WriteFile(dst+partSuffix, []byte("stale"), 0644); err != nil {
+		t.Fatal("could not write part file", err)
+	}
+
+	// a server which ignores the range header must result in the entire file being downloaded again
+	client := &mockDoer{do: func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("chart contents"))}, nil
+	}}
+
+	if err := File(context.Background(), client, "https://example.com/chart.tgz", dst); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff("chart contents", readFile(t, dst)); d != "" {
+		t.Error("contents mismatch (-want +got):", d)
+	}
+}
+
+// failingReader returns data followed by an error, simulating a dropped connection.
+type failingReader struct {
+	data io.Reader
+}
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	n, err := f.data.Read(p)
+	if err == io.EOF {
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func TestFile_Retry(t *testing.T) {
+	var (
+		ranges  []string
+		content = "chart contents"
+		calls   int
+	)
+	server := rangeServer(content, &ranges)
+	client := &mockDoer{do: func(req *http.Request) (*http.Response, error) {
+		calls++
+		switch calls {
+		case 1:
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(""))}, nil
+		case 2:
+			// drop the connection after the first few bytes
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(&failingReader{data: strings.NewReader(content[:5])})}, nil
+		default:
+			return server.Do(req)
+		}
+	}}
+
+	dst := filepath.Join(t.TempDir(), "chart.tgz")
+	if err := File(context.Background(), client, "https://example.com/chart.tgz", dst, WithAttempts(3, time.Millisecond)); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if d := cmp.Diff(content, readFile(t, dst)); d != "" {
+		t.Error("contents mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff([]string{"bytes=5-"}, ranges); d != "" {
+		t.Error("ranges mismatch (-want +got):", d)
+	}
+}
+
+func TestFile_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		calls  int
+	}{
+		{name: "permanent", status: http.StatusNotFound, calls: 1},
+		{name: "retryable", status: http.StatusBadGateway, calls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			client := &mockDoer{do: func(req *http.Request) (*http.Response, error) {
+				calls++
+				return &http.Response{StatusCode: tt.status, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}}
+
+			dst := filepath.Join(t.TempDir(), "chart.tgz")
+			if err := File(context.Background(), client, "https://example.com/chart.tgz", dst, WithAttempts(3, time.Millisecond)); err == nil {
+				t.Fatal("expected error")
+			}
+			if d := cmp.Diff(tt.calls, calls); d != "" {
+				t.Error("calls mismatch (-want +got):", d)
+			}
+			if _, err := os.Stat(dst); !os.IsNotExist(err) {
+				t.Error("expected dst to not exist")
+			}
+		})
+	}
+}
+
+func TestFile_MaxRate(t *testing.T) {
+	var ranges []string
+	dst := filepath.Join(t.TempDir(), "chart.tgz")
+	content := strings.Repeat("a", 2048)
+
+	start := time.Now()
+	if err := File(context.Background(), rangeServer(content, &ranges), "https://example.com/chart.tgz", dst, WithMaxRate(8192)); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// 2048 bytes at 8192 bytes per second should take roughly 250ms
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("expected download to be throttled, took %s", elapsed)
+	}
+	if d := cmp.Diff(content, readFile(t, dst)); d != "" {
+		t.Error("contents mismatch (-want +got):", d)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{input: "100", want: 100},
+		{input: "500K", want: 500 * 1024},
+		{input: "500k", want: 500 * 1024},
+		{input: "2M", want: 2 * 1024 * 1024},
+		{input: "2MB", want: 2 * 1024 * 1024},
+		{input: "2MiB", want: 2 * 1024 * 1024},
+		{input: "1.5M/s", want: 1536 * 1024},
+		{input: "1G", want: 1024 * 1024 * 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRate(tt.input)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Error("rate mismatch (-want +got):", d)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "fast", "-1M", "0", "M"} {
+		if _, err := ParseRate(input); err == nil {
+			t.Errorf("expected an error for '%s'", input)
+		}
+	}
+}
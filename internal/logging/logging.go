@@ -0,0 +1,358 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/redact"
+	"github.com/pterm/pterm"
+	"golang.org/x/term"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level is the verbosity of the log records printed to the terminal.
+type Level int
+
+const (
+	// LevelInfo prints only the output of the command, which is the default.
+	LevelInfo Level = iota
+	// LevelDebug additionally prints debug records, enabled by -v.
+	LevelDebug
+	// LevelTrace additionally prints trace records (e.g. the actions of helm), enabled by -vv.
+	LevelTrace
+)
+
+// Supported formats of the log records.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+const (
+	// maxFileSize is the size a log file is rotated at, when it is opened.
+	maxFileSize = 10 * 1024 * 1024
+	// maxBackups is the number of rotated log files which are kept, named abctl.log.1 (newest) to abctl.log.5.
+	maxBackups = 5
+)
+
+// Options configure the logging of a command.
+type Options struct {
+	// Verbosity is the number of times -v was provided.
+	Verbosity int
+	// Format is the format of the log records, FormatText or FormatJSON.
+	Format string
+	// File is the path of the log file capturing all output of the command, or empty to disable it.
+	File string
+	// FileOptional, if true, continues without a log file if File cannot be opened, only logging a debug record,
+	// rather than failing. It is set for the default log file, which was not chosen by the user.
+	FileOptional bool
+	// Quiet, if true, only prints errors and the output of the command (e.g. a listing) to the terminal, omitting the
+	// spinners and the info, success, and warning messages. The log file still captures them.
+	Quiet bool
+	// NoColor, if true, prints plain text without any colors or spinner animations, which is also the case if the
+	// terminal output is not a terminal (e.g. the log of a CI job).
+	NoColor bool
+}
+
+var (
+	// mu guards the configuration and serializes the printing of log records.
+	mu        sync.Mutex
+	level     = LevelInfo
+	logFormat = FormatText
+
+	// stdout is where the terminal output is written, defined here for testing purposes.
+	stdout io.Writer = os.Stdout
+	// now returns the time of a log record, defined here for testing purposes.
+	now = time.Now
+	// isTerminal returns true if w is a terminal, defined here for testing purposes.
+	isTerminal = func(w io.Writer) bool {
+		f, ok := w.(*os.File)
+		return ok && term.IsTerminal(int(f.Fd()))
+	}
+	// unstyled is true if the styling of pterm was disabled by Setup.
+	unstyled bool
+
+	// fileMu guards the log file, which is written by the log records and the captured output.
+	fileMu sync.Mutex
+	file   io.WriteCloser
+	output *outputWriter
+)
+
+// Setup configures the logging according to the opts.
+//
+// Debug and trace records are printed to the terminal according to the verbosity, while the log file captures every
+// record and all output of the command regardless of it. Close must be called once the command completes.
+func Setup(opts Options) error {
+	if opts.Format != FormatText && opts.Format != FormatJSON {
+		return fmt.Errorf("invalid log format %s, must be %s or %s", opts.Format, FormatText, FormatJSON)
+	}
+
+	var f *os.File
+	if opts.File != "" {
+		var err error
+		if f, err = openFile(opts.File); err != nil {
+			if !opts.FileOptional {
+				return err
+			}
+			// deferred before the unlock below, so it is logged once the logging is configured
+			defer Debugf("Continuing without a log file: %s", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	level = Level(opts.Verbosity)
+	if level > LevelTrace {
+		level = LevelTrace
+	}
+	logFormat = opts.Format
+	if level >= LevelDebug {
+		pterm.EnableDebugMessages()
+	}
+	if opts.NoColor || !isTerminal(stdout) {
+		pterm.DisableStyling()
+		unstyled = true
+	}
+
+	// the output omitted by quiet is still captured by the log file, if enabled
+	quiet := io.Discard
+	if f != nil {
+		fileMu.Lock()
+		file = f
+		output = &outputWriter{}
+		fileMu.Unlock()
+
+		pterm.SetDefaultOutput(io.MultiWriter(stdout, output))
+		quiet = output
+	}
+
+	if opts.Quiet {
+		setQuietWriter(quiet)
+	}
+	return nil
+}
+
+// setQuietWriter sets the writer of the spinners and the info, success, and warning printers of pterm, which is nil
+// for the default output.
+func setQuietWriter(w io.Writer) {
+	pterm.DefaultSpinner.Writer = w
+	pterm.Info.Writer = w
+	pterm.Success.Writer = w
+	pterm.Warning.Writer = w
+	pterm.Description.Writer = w
+}
+
+// Close stops capturing the output of the command and closes the log file.
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	pterm.SetDefaultOutput(stdout)
+	setQuietWriter(nil)
+	if unstyled {
+		pterm.EnableStyling()
+		unstyled = false
+	}
+
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	if file == nil {
+		return nil
+	}
+	// any incomplete line is captured as well
+	output.writeLine()
+	err := file.Close()
+	file, output = nil, nil
+	if err != nil {
+		return fmt.Errorf("could not close log file: %w", err)
+	}
+	return nil
+}
+
+// Debugf logs a debug record, which is printed to the terminal with -v.
+func Debugf(format string, a ...interface{}) {
+	log(LevelDebug, fmt.Sprintf(format, a...))
+}
+
+// Tracef logs a trace record, which is printed to the terminal with -vv.
+func Tracef(format string, a ...interface{}) {
+	log(LevelTrace, fmt.Sprintf(format, a...))
+}
+
+// tracePrinter prints trace records in the text format.
+var tracePrinter = pterm.Debug.WithPrefix(pterm.Prefix{Text: "TRACE", Style: pterm.Debug.Prefix.Style})
+
+// log prints the record to the terminal, if verbose enough, and writes it to the log file.
+func log(lvl Level, msg string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if lvl <= level {
+		// the record is written to the log file below, rather than being captured from the output
+		if output != nil {
+			output.skip.Store(true)
+			defer output.skip.Store(false)
+		}
+		if logFormat == FormatJSON {
+			// printed through pterm, so the line of an active spinner is cleared first
+			pterm.Print(formatRecord(FormatJSON, now(), lvl.String(), msg))
+		} else if lvl == LevelTrace {
+			tracePrinter.Println(msg)
+		} else {
+			pterm.Debug.Println(msg)
+		}
+	}
+
+	writeFile(lvl.String(), msg)
+}
+
+// String returns the name of the level within a log record.
+func (l Level) String() string {
+	switch l {
+	case LevelTrace:
+		return "trace"
+	case LevelDebug:
+		return "debug"
+	default:
+		return "info"
+	}
+}
+
+// writeFile writes the record to the log file, if enabled, redacting any known secrets.
+func writeFile(lvl, msg string) {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	if file == nil {
+		return
+	}
+	_, _ = io.WriteString(file, formatRecord(logFormat, now(), lvl, redact.String(msg)))
+}
+
+// record is a log record in the json format.
+type record struct {
+	Time  string `json:"time"`
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+}
+
+// formatRecord returns the record in the format, terminated by a newline.
+func formatRecord(format string, t time.Time, lvl, msg string) string {
+	ts := t.UTC().Format(time.RFC3339Nano)
+	if format == FormatJSON {
+		data, err := json.Marshal(record{Time: ts, Level: lvl, Msg: msg})
+		if err == nil {
+			return string(data) + "\n"
+		}
+	}
+	return fmt.Sprintf("%s %-5s %s\n", ts, strings.ToUpper(lvl), msg)
+}
+
+// prefixLevels are the levels of the lines printed by the pterm printers, by their prefix.
+var prefixLevels = map[string]string{
+	"INFO":    "info",
+	"WARNING": "warn",
+	"ERROR":   "error",
+	"DEBUG":   "debug",
+	"TRACE":   "trace",
+}
+
+// outputWriter captures the output of the command into the log file, one record per line.
+type outputWriter struct {
+	// buf is the incomplete line, guarded by fileMu.
+	buf bytes.Buffer
+	// skip is true while a log record is printed, as it is written to the log file directly.
+	skip atomic.Bool
+}
+
+func (o *outputWriter) Write(p []byte) (int, error) {
+	if o.skip.Load() {
+		return len(p), nil
+	}
+
+	fileMu.Lock()
+	defer fileMu.Unlock()
+
+	for _, b := range p {
+		switch b {
+		case '\n':
+			o.writeLine()
+		case '\r':
+			// the line is being redrawn (e.g. by a spinner), only its final content is captured
+			o.buf.Reset()
+		default:
+			o.buf.WriteByte(b)
+		}
+	}
+	return len(p), nil
+}
+
+// writeLine writes the buffered line as a record, guarded by fileMu.
+func (o *outputWriter) writeLine() {
+	line := strings.TrimSpace(pterm.RemoveColorFromString(o.buf.String()))
+	o.buf.Reset()
+	if line == "" || file == nil {
+		return
+	}
+
+	// the prefix of a pterm printer is replaced by the level of the record
+	lvl := "info"
+	for prefix, l := range prefixLevels {
+		// without styling the prefix is followed by a colon
+		if strings.HasPrefix(line, prefix+" ") || strings.HasPrefix(line, prefix+":") {
+			lvl, line = l, strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(line, prefix), ":"))
+			break
+		}
+	}
+	_, _ = io.WriteString(file, formatRecord(logFormat, now(), lvl, redact.String(line)))
+}
+
+// openFile opens the log file located at path for appending, creating any missing directories.
+// A log file which has reached maxFileSize is rotated first.
+func openFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("could not create directories for %s: %w", path, err)
+	}
+
+	if fi, err := os.Stat(path); err == nil && fi.Size() >= maxFileSize {
+		if err := rotate(path); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open log file %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// rotate renames the log file located at path to path.1, shifting any previously rotated log files and removing
+// the oldest.
+func rotate(path string) error {
+	if err := os.Remove(backup(path, maxBackups)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("could not remove log file %s: %w", backup(path, maxBackups), err)
+	}
+	for i := maxBackups - 1; i > 0; i-- {
+		if err := os.Rename(backup(path, i), backup(path, i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("could not rotate log file %s: %w", backup(path, i), err)
+		}
+	}
+	if err := os.Rename(path, backup(path, 1)); err != nil {
+		return fmt.Errorf("could not rotate log file %s: %w", path, err)
+	}
+	return nil
+}
+
+// backup returns the path of the i-th rotated log file.
+func backup(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
@@ -0,0 +1,207 @@
+package logging
+
+import (
+	"bytes"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pterm/pterm"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setup(t *testing.T, opts Options) *bytes.Buffer {
+	t.Helper()
+
+	var out bytes.Buffer
+	origStdout, origNow, origDebug := stdout, now, pterm.PrintDebugMessages
+	stdout = &out
+	now = func() time.Time { return time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() {
+		stdout, now, pterm.PrintDebugMessages = origStdout, origNow, origDebug
+		_ = Close()
+		level, logFormat = LevelInfo, FormatText
+	})
+
+	if err := Setup(opts); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	return &out
+}
+
+func TestSetup_InvalidFormat(t *testing.T) {
+	if err := Setup(Options{Format: "xml"}); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestSetup_FileOptional(t *testing.T) {
+	// the log file cannot be created below a file
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "logs"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	logFile := filepath.Join(dir, "logs", "abctl.log")
+
+	if err := Setup(Options{Format: FormatText, File: logFile}); err == nil {
+		t.Error("expected an error")
+	}
+
+	// without a log file, the output is not captured and printed by pterm directly
+	var out bytes.Buffer
+	pterm.SetDefaultOutput(&out)
+	setup(t, Options{Verbosity: 1, Format: FormatText, File: logFile, FileOptional: true})
+	if file != nil {
+		t.Error("expected no log file")
+	}
+	if !strings.Contains(out.String(), "Continuing without a log file") {
+		t.Error("expected a debug record, got", out.String())
+	}
+}
+
+func TestLog_Text(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "logs", "abctl.log")
+	out := setup(t, Options{Format: FormatText, File: logFile})
+
+	pterm.Info.Println("installing")
+	pterm.Fprinto(nil, "spinner frame")
+	pterm.Fprinto(nil, "final frame\n")
+	Debugf("connected to %s", "docker")
+	Tracef("helm action")
+	pterm.Warning.Println("password=hunter2")
+	if err := Close(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// debug and trace records are not printed without -v
+	if strings.Contains(out.String(), "connected to docker") || strings.Contains(out.String(), "helm action") {
+		t.Error("unexpected debug output", out.String())
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	exp := `2024-06-01T12:00:00Z INFO  installing
+2024-06-01T12:00:00Z INFO  final frame
+2024-06-01T12:00:00Z DEBUG connected to docker
+2024-06-01T12:00:00Z TRACE helm action
+2024-06-01T12:00:00Z WARN  password=REDACTED
+`
+	if d := cmp.Diff(exp, string(data)); d != "" {
+		t.Error("log file mismatch (-want +got):", d)
+	}
+}
+
+func TestLog_JSONVerbose(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "abctl.log")
+	out := setup(t, Options{Verbosity: 1, Format: FormatJSON, File: logFile})
+
+	Debugf("connected to %s", "docker")
+	Tracef("helm action")
+	if err := Close(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := `{"time":"2024-06-01T12:00:00Z","level":"debug","msg":"connected to docker"}` + "\n"
+	if d := cmp.Diff(exp, out.String()); d != "" {
+		t.Error("output mismatch (-want +got):", d)
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	exp += `{"time":"2024-06-01T12:00:00Z","level":"trace","msg":"helm action"}` + "\n"
+	if d := cmp.Diff(exp, string(data)); d != "" {
+		t.Error("log file mismatch (-want +got):", d)
+	}
+}
+
+func TestLog_QuietNoColor(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "abctl.log")
+	out := setup(t, Options{Format: FormatText, File: logFile, Quiet: true, NoColor: true})
+
+	spinner, _ := pterm.DefaultSpinner.Start("installing")
+	spinner.Success("installed")
+	pterm.Info.Println("ready")
+	pterm.Error.Println("failed")
+	pterm.Println("output")
+	if err := Close(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// only the error and the output of the command are printed, without styling
+	if d := cmp.Diff("ERROR: failed\noutput\n", out.String()); d != "" {
+		t.Error("output mismatch (-want +got):", d)
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	for _, exp := range []string{"INFO  ready", "ERROR failed", "INFO  output"} {
+		if !strings.Contains(string(data), exp) {
+			t.Errorf("expected log file to contain %q, got %s", exp, data)
+		}
+	}
+
+	if pterm.RawOutput || pterm.Info.Writer != nil {
+		t.Error("expected the styling and writers to be restored by Close")
+	}
+}
+
+func TestSetup_Terminal(t *testing.T) {
+	origIsTerminal := isTerminal
+	t.Cleanup(func() { isTerminal = origIsTerminal })
+
+	isTerminal = func(io.Writer) bool { return true }
+	setup(t, Options{Format: FormatText})
+	if pterm.RawOutput {
+		t.Error("expected styling for a terminal")
+	}
+	_ = Close()
+
+	isTerminal = func(io.Writer) bool { return false }
+	setup(t, Options{Format: FormatText})
+	if !pterm.RawOutput {
+		t.Error("expected no styling without a terminal")
+	}
+}
+
+func TestOpenFile_Rotate(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "abctl.log")
+	for i := 1; i <= maxBackups; i++ {
+		if err := os.WriteFile(backup(logFile, i), []byte{byte('0' + i)}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(logFile, make([]byte, maxFileSize), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := openFile(logFile)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	_ = f.Close()
+
+	if fi, err := os.Stat(logFile); err != nil || fi.Size() != 0 {
+		t.Error("expected an empty log file", err)
+	}
+	if fi, err := os.Stat(backup(logFile, 1)); err != nil || fi.Size() != maxFileSize {
+		t.Error("expected the rotated log file", err)
+	}
+	// the oldest log file is removed, the others are shifted
+	for i := 2; i <= maxBackups; i++ {
+		data, err := os.ReadFile(backup(logFile, i))
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if d := cmp.Diff(string([]byte{byte('0' + i - 1)}), string(data)); d != "" {
+			t.Error("rotated log file mismatch (-want +got):", d)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package shutdown
+
+import (
+	"context"
+	"fmt"
+	"github.com/pterm/pterm"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExitCodeForced is the exit code used when a second interrupt signal forces abctl to exit immediately.
+const ExitCodeForced = 130
+
+// CleanupTimeout is the maximum amount of time all the cleanup handlers combined are given to complete.
+var CleanupTimeout = 30 * time.Second
+
+// Cleanup is a best-effort function that is called if the operation it was registered with
+// was still in-flight when the command was interrupted.
+// The provided context is not derived from the (already cancelled) command context.
+type Cleanup func(ctx context.Context) error
+
+// operation represents an in-flight operation.
+type operation struct {
+	// name of the operation, e.g. "Installing 'airbyte/airbyte' Helm Chart"
+	name string
+	// abandoned describes the state that will be left behind if this operation is interrupted
+	abandoned string
+	// cleanup is an optional handler to call if this operation is interrupted
+	cleanup Cleanup
+}
+
+// Handler tracks the in-flight operations of a command so that if the command is interrupted the user can be
+// informed of what is being abandoned, and so that any registered cleanup handlers can be called.
+//
+// The first interrupt signal cancels the command and calls the cleanup handlers.
+// A second interrupt signal forces an immediate exit.
+type Handler struct {
+	lock        sync.Mutex
+	ops         map[uint64]operation
+	nextID      uint64
+	interrupted bool
+	// done is closed once all the cleanup handlers have completed
+	done chan struct{}
+	// exit is os.Exit, defined here for testing purposes
+	exit func(int)
+}
+
+// New returns a new Handler.
+func New() *Handler {
+	return &Handler{
+		ops:  map[uint64]operation{},
+		done: make(chan struct{}),
+		exit: os.Exit,
+	}
+}
+
+// Track registers an in-flight operation, returning a function which must be called once the operation has completed.
+// The abandoned value should describe the state left behind if this operation is interrupted.
+// The cleanup handler is optional and may be nil.
+func (h *Handler) Track(name, abandoned string, cleanup Cleanup) func() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	id := h.nextID
+	h.nextID++
+	h.ops[id] = operation{name: name, abandoned: abandoned, cleanup: cleanup}
+
+	return func() {
+		h.lock.Lock()
+		defer h.lock.Unlock()
+		delete(h.ops, id)
+	}
+}
+
+// Listen blocks waiting for signals on the sig channel.
+// The first signal displays a summary of the in-flight operations, calls cancel, and then calls all the cleanup
+// handlers of the in-flight operations. A second signal will call os.Exit with ExitCodeForced.
+func (h *Handler) Listen(sig <-chan os.Signal, cancel context.CancelFunc) {
+	<-sig
+
+	h.lock.Lock()
+	h.interrupted = true
+	ops := h.inFlight()
+	h.lock.Unlock()
+
+	go func() {
+		<-sig
+		pterm.Error.Println("Forcing exit, any in-flight operations have been abandoned")
+		h.exit(ExitCodeForced)
+	}()
+
+	pterm.Println()
+	pterm.Warning.Println(summary(ops))
+
+	cancel()
+
+	h.cleanup(ops)
+	close(h.done)
+}
+
+// Wait blocks until all the cleanup handlers have completed.
+// If no interrupt signal was received, Wait returns immediately.
+func (h *Handler) Wait() {
+	h.lock.Lock()
+	interrupted := h.interrupted
+	h.lock.Unlock()
+
+	if interrupted {
+		<-h.done
+	}
+}
+
+// inFlight returns the currently in-flight operations, in the order they were registered.
+// The lock must be held by the caller.
+func (h *Handler) inFlight() []operation {
+	ids := make([]uint64, 0, len(h.ops))
+	for id := range h.ops {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	ops := make([]operation, len(ids))
+	for i, id := range ids {
+		ops[i] = h.ops[id]
+	}
+
+	return ops
+}
+
+// cleanup calls the cleanup handlers of the ops, in the reverse order they were registered.
+func (h *Handler) cleanup(ops []operation) {
+	ctx, cancel := context.WithTimeout(context.Background(), CleanupTimeout)
+	defer cancel()
+
+	for i := len(ops) - 1; i >= 0; i-- {
+		if ops[i].cleanup == nil {
+			continue
+		}
+
+		pterm.Info.Printfln("Cleaning up after: %s", ops[i].name)
+		if err := ops[i].cleanup(ctx); err != nil {
+			pterm.Warning.Printfln("Unable to clean up after: %s\n  %s", ops[i].name, err)
+		}
+	}
+}
+
+// summary returns the message displayed to the user when an interrupt signal is received.
+func summary(ops []operation) string {
+	var sb strings.Builder
+	sb.WriteString("Interrupt received, cancelling (press Ctrl+C again to force exit)")
+	if len(ops) == 0 {
+		return sb.String()
+	}
+
+	sb.WriteString("\nThe following operations were still in progress and will be abandoned:")
+	for _, op := range ops {
+		sb.WriteString(fmt.Sprintf("\n  - %s", op.name))
+		if op.abandoned != "" {
+			sb.WriteString(fmt.Sprintf("\n    %s", op.abandoned))
+		}
+	}
+
+	return sb.String()
+}
+
+// defaultHandler is the Handler used by the package level functions.
+var defaultHandler = New()
+
+// Track registers an in-flight operation with the default Handler.
+// See Handler.Track for additional details.
+func Track(name, abandoned string, cleanup Cleanup) func() {
+	return defaultHandler.Track(name, abandoned, cleanup)
+}
+
+// Listen listens for signals with the default Handler.
+// See Handler.Listen for additional details.
+func Listen(sig <-chan os.Signal, cancel context.CancelFunc) {
+	defaultHandler.Listen(sig, cancel)
+}
+
+// Wait waits for the default Handler to complete its cleanup.
+// See Handler.Wait for additional details.
+func Wait() {
+	defaultHandler.Wait()
+}
@@ -0,0 +1,131 @@
+package shutdown
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pterm/pterm"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_Listen(t *testing.T) {
+	b := bytes.NewBufferString("")
+	pterm.SetDefaultOutput(b)
+	t.Cleanup(func() {
+		pterm.SetDefaultOutput(os.Stdout)
+	})
+
+	h := New()
+
+	var cleanups []string
+	// completed operations should neither be displayed nor cleaned up
+	h.Track("completed", "completed state", func(ctx context.Context) error {
+		cleanups = append(cleanups, "completed")
+		return nil
+	})()
+	h.Track("first", "first state", func(ctx context.Context) error {
+		cleanups = append(cleanups, "first")
+		return errors.New("first cleanup failed")
+	})
+	h.Track("second", "", nil)
+	h.Track("third", "third state", func(ctx context.Context) error {
+		cleanups = append(cleanups, "third")
+		return nil
+	})
+
+	sig := make(chan os.Signal, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	listening := make(chan struct{})
+	go func() {
+		h.Listen(sig, cancel)
+		close(listening)
+	}()
+
+	sig <- os.Interrupt
+
+	select {
+	case <-listening:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for listen to complete")
+	}
+
+	// wait should not block once the cleanup has completed
+	h.Wait()
+
+	if ctx.Err() == nil {
+		t.Error("expected context to be cancelled")
+	}
+
+	// cleanups are called in reverse order
+	if d := cmp.Diff([]string{"third", "first"}, cleanups); d != "" {
+		t.Error("cleanup mismatch (-want +got):", d)
+	}
+
+	output := b.String()
+	for _, exp := range []string{"first", "first state", "second", "third", "third state", "first cleanup failed"} {
+		if !strings.Contains(output, exp) {
+			t.Errorf("expected output to contain %q: %s", exp, output)
+		}
+	}
+	if strings.Contains(output, "completed state") {
+		t.Errorf("output should not contain completed operations: %s", output)
+	}
+}
+
+func TestHandler_ListenForced(t *testing.T) {
+	b := bytes.NewBufferString("")
+	pterm.SetDefaultOutput(b)
+	t.Cleanup(func() {
+		pterm.SetDefaultOutput(os.Stdout)
+	})
+
+	h := New()
+	exitCode := make(chan int, 1)
+	h.exit = func(code int) {
+		exitCode <- code
+	}
+
+	// a cleanup that blocks until the test completes, ensuring the second signal arrives during cleanup
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	h.Track("blocking", "", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	sig := make(chan os.Signal, 2)
+	go h.Listen(sig, func() {})
+
+	sig <- os.Interrupt
+	sig <- os.Interrupt
+
+	select {
+	case code := <-exitCode:
+		if d := cmp.Diff(ExitCodeForced, code); d != "" {
+			t.Error("exit code mismatch (-want +got):", d)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for forced exit")
+	}
+}
+
+func TestHandler_WaitNotInterrupted(t *testing.T) {
+	h := New()
+	h.Track("op", "", nil)
+
+	done := make(chan struct{})
+	go func() {
+		h.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("wait should return immediately if no interrupt was received")
+	}
+}
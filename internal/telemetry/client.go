@@ -4,11 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/airbytehq/abctl/internal/build"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
 	"github.com/google/uuid"
+	"github.com/pbnjay/memory"
 	"github.com/pterm/pterm"
+	"maps"
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"sync"
 )
 
@@ -42,6 +48,34 @@ type Client interface {
 	User() uuid.UUID
 }
 
+// properties returns the properties which are attached to every event, regardless of which Client is sending it.
+// The attrs are merged into the returned properties and the error, if non-nil, is added under the "error" key, along
+// with its code under the "error_code" key if it has one.
+func properties(sessionID uuid.UUID, es EventState, attrs map[string]string, ee error) map[string]string {
+	props := map[string]string{
+		"deployment_method": "abctl",
+		"session_id":        sessionID.String(),
+		"state":             string(es),
+		"os":                runtime.GOOS,
+		"build":             build.Version,
+		"script_version":    build.Version,
+		"cpu_count":         strconv.Itoa(runtime.NumCPU()),
+		"mem_total_bytes":   strconv.FormatUint(memory.TotalMemory(), 10),
+		"mem_free_bytes":    strconv.FormatUint(memory.FreeMemory(), 10),
+	}
+	// add all the attributes to the properties map before returning it
+	maps.Copy(props, attrs)
+
+	if ee != nil {
+		props["error"] = ee.Error()
+		if le := localerr.Find(ee); le != nil {
+			props["error_code"] = le.Code()
+		}
+	}
+
+	return props
+}
+
 type getConfig struct {
 	dnt      bool
 	userHome string
@@ -129,8 +163,30 @@ func Get(opts ...GetOption) Client {
 		pterm.Warning.Printfln("could not create telemetry config file: %s", err.Error())
 		instance = NoopClient{}
 	} else {
-		instance = NewSegmentClient(cfg)
+		instance = newClient(cfg)
 	}
 
 	return instance
 }
+
+// newClient returns a SegmentClient if the cfg has no additional sinks defined.
+// Otherwise, a MultiClient is returned which sends every event to both segment and all the configured sinks.
+func newClient(cfg Config) Client {
+	sessionID := uuid.New()
+	segment := NewSegmentClient(cfg, WithSessionID(sessionID))
+	if len(cfg.Sinks) == 0 {
+		return segment
+	}
+
+	clients := []Client{segment}
+	for _, sink := range cfg.Sinks {
+		cli, err := newSinkClient(cfg, sink, sessionID)
+		if err != nil {
+			pterm.Warning.Printfln("Ignoring telemetry sink '%s': %s", sink.Type, err)
+			continue
+		}
+		clients = append(clients, cli)
+	}
+
+	return NewMultiClient(clients...)
+}
@@ -3,6 +3,9 @@ package telemetry
 import (
 	"errors"
 	"fmt"
+	"github.com/airbytehq/abctl/internal/cmd/local/localerr"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
 	"os"
 	"path/filepath"
 	"strings"
@@ -73,6 +76,42 @@ func TestGet_WithExistingULID(t *testing.T) {
 	}
 }
 
+func TestGet_WithSinks(t *testing.T) {
+	instance = nil
+	home := t.TempDir()
+
+	cfg := Config{
+		AnalyticsID: NewUUID(),
+		Sinks: []SinkConfig{
+			{Type: SinkFile, Path: filepath.Join(home, "events.jsonl")},
+			{Type: "unsupported"},
+		},
+	}
+	if err := writeConfigToFile(filepath.Join(home, ConfigFile), cfg); err != nil {
+		t.Fatal("failed writing config", err)
+	}
+
+	cli := Get(WithUserHome(home))
+	multi, ok := cli.(*MultiClient)
+	if !ok {
+		t.Fatal(fmt.Sprintf("expected MultiClient; received: %T", cli))
+	}
+
+	// the unsupported sink should have been ignored
+	if len(multi.clients) != 2 {
+		t.Fatal(fmt.Sprintf("expected 2 clients; received: %d", len(multi.clients)))
+	}
+	if _, ok := multi.clients[0].(*SegmentClient); !ok {
+		t.Error(fmt.Sprintf("expected SegmentClient; received: %T", multi.clients[0]))
+	}
+	if _, ok := multi.clients[1].(*SinkClient); !ok {
+		t.Error(fmt.Sprintf("expected SinkClient; received: %T", multi.clients[1]))
+	}
+	if cli.User() != cfg.AnalyticsID.toUUID() {
+		t.Error("expected user to match analytics id")
+	}
+}
+
 func TestGet_SameInstance(t *testing.T) {
 	instance = nil
 	home := t.TempDir()
@@ -107,3 +146,19 @@ func TestGet_Dnt(t *testing.T) {
 		t.Error("expected file not exists", err)
 	}
 }
+
+func TestProperties_ErrorCode(t *testing.T) {
+	err := fmt.Errorf("could not install: %w", localerr.ErrPort)
+	props := properties(uuid.New(), Failed, nil, err)
+	if d := cmp.Diff(err.Error(), props["error"]); d != "" {
+		t.Error("error mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff("ABCTL-0004", props["error_code"]); d != "" {
+		t.Error("error_code mismatch (-want +got):", d)
+	}
+
+	props = properties(uuid.New(), Failed, nil, errors.New("unknown"))
+	if _, ok := props["error_code"]; ok {
+		t.Error("expected no error_code for an uncoded error")
+	}
+}
@@ -19,6 +19,7 @@ Anonymous usage reporting is currently enabled. For more information, please see
 const (
 	fieldAnalyticsID = "analytics_id"
 	fieldUserID      = "anonymous_user_id"
+	fieldSinks       = "sinks"
 )
 
 var ConfigFile = filepath.Join(".airbyte", "analytics.yml")
@@ -112,6 +113,7 @@ func (u ULID) IsZero() bool {
 type Config struct {
 	UserID      ULID                   `yaml:"anonymous_user_id,omitempty"`
 	AnalyticsID UUID                   `yaml:"analytics_id,omitempty"`
+	Sinks       []SinkConfig           `yaml:"sinks,omitempty"`
 	Other       map[string]interface{} `yaml:",inline"`
 }
 
@@ -152,8 +154,19 @@ func loadConfigFromFile(path string) (Config, error) {
 		}
 	}
 
+	if _, ok := c.Other[fieldSinks]; ok {
+		var sinks struct {
+			Sinks []SinkConfig `yaml:"sinks"`
+		}
+		if err := yaml.Unmarshal(analytics, &sinks); err != nil {
+			return Config{}, fmt.Errorf("could not unmarshal sinks: %w", err)
+		}
+		c.Sinks = sinks.Sinks
+	}
+
 	delete(c.Other, fieldUserID)
 	delete(c.Other, fieldAnalyticsID)
+	delete(c.Other, fieldSinks)
 
 	return c, nil
 }
@@ -151,6 +151,46 @@ total: 300`,
 		}
 	})
 
+	t.Run("happy path with sinks", func(t *testing.T) {
+		f, err := os.CreateTemp(t.TempDir(), "analytics-")
+		if err != nil {
+			t.Fatal("could not create temp file", err)
+		}
+		defer f.Close()
+
+		cfgData := fmt.Sprintf(`# comments
+%s: %s
+sinks:
+  - type: otlp
+    endpoint: http://localhost:4318
+    headers:
+      authorization: Bearer token
+  - type: file
+    path: /tmp/events.jsonl`,
+			fieldAnalyticsID, uuidID.String())
+
+		if _, err := f.WriteString(cfgData); err != nil {
+			t.Fatal("could not write to temp file", err)
+		}
+
+		cfg, err := loadConfigFromFile(f.Name())
+		if d := cmp.Diff(nil, err); d != "" {
+			t.Error("failed to load file", d)
+		}
+
+		expSinks := []SinkConfig{
+			{Type: SinkOTLP, Endpoint: "http://localhost:4318", Headers: map[string]string{"authorization": "Bearer token"}},
+			{Type: SinkFile, Path: "/tmp/events.jsonl"},
+		}
+		if d := cmp.Diff(expSinks, cfg.Sinks); d != "" {
+			t.Error("sinks are incorrect", d)
+		}
+
+		if _, ok := cfg.Other[fieldSinks]; ok {
+			t.Error("sinks should not be included in other")
+		}
+	})
+
 	t.Run("no file returns err", func(t *testing.T) {
 		_, err := loadConfigFromFile(filepath.Join(t.TempDir(), "dne.yml"))
 		if err == nil {
@@ -328,6 +368,34 @@ field: value is here
 			t.Error("contents do not match", d)
 		}
 	})
+
+	t.Run("uuid and sinks", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ConfigFile)
+
+		cfg := Config{
+			AnalyticsID: UUID(uuidID),
+			Sinks:       []SinkConfig{{Type: SinkFile, Path: "/tmp/events.jsonl"}},
+		}
+
+		if err := writeConfigToFile(path, cfg); err != nil {
+			t.Error("failed to create file", err)
+		}
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			t.Error("failed to read file", err)
+		}
+
+		exp := fmt.Sprintf(`%s%s: %s
+sinks:
+    - type: file
+      path: /tmp/events.jsonl
+`, header, fieldAnalyticsID, uuidID.String())
+
+		if d := cmp.Diff(exp, string(contents)); d != "" {
+			t.Error("contents do not match", d)
+		}
+	})
 }
 
 func TestUUID(t *testing.T) {
@@ -0,0 +1,90 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/google/uuid"
+)
+
+var _ Client = (*MultiClient)(nil)
+
+// MultiClient client, all methods are forwarded to every underlying Client.
+//
+// A Client that fails its Start call will not receive the corresponding Success or Failure call,
+// matching the behavior of the Wrapper function for a single Client.
+type MultiClient struct {
+	clients []Client
+	// started tracks which clients successfully sent their start event
+	started map[int]bool
+}
+
+// NewMultiClient returns a MultiClient which forwards every event to all the provided clients.
+// The User of the first client is considered the user of the returned MultiClient.
+func NewMultiClient(clients ...Client) *MultiClient {
+	return &MultiClient{
+		clients: clients,
+		started: map[int]bool{},
+	}
+}
+
+// Start calls Start on all the clients, only returning an error if every client failed.
+func (m *MultiClient) Start(ctx context.Context, et EventType) error {
+	var errs []error
+	for i, cli := range m.clients {
+		if err := cli.Start(ctx, et); err != nil {
+			logging.Debugf("Unable to send telemetry start data to %T: %s", cli, err)
+			errs = append(errs, err)
+			continue
+		}
+		m.started[i] = true
+	}
+
+	if len(errs) == len(m.clients) {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func (m *MultiClient) Success(ctx context.Context, et EventType) error {
+	var errs []error
+	for i, cli := range m.clients {
+		if !m.started[i] {
+			continue
+		}
+		if err := cli.Success(ctx, et); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *MultiClient) Failure(ctx context.Context, et EventType, ee error) error {
+	var errs []error
+	for i, cli := range m.clients {
+		if !m.started[i] {
+			continue
+		}
+		if err := cli.Failure(ctx, et, ee); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *MultiClient) Attr(key, val string) {
+	for _, cli := range m.clients {
+		cli.Attr(key, val)
+	}
+}
+
+func (m *MultiClient) User() uuid.UUID {
+	if len(m.clients) == 0 {
+		return uuid.Nil
+	}
+
+	return m.clients[0].User()
+}
@@ -0,0 +1,118 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"testing"
+)
+
+func TestMultiClient(t *testing.T) {
+	userID := uuid.New()
+	var calls []string
+
+	newMock := func(name string) MockClient {
+		return MockClient{
+			start: func(ctx context.Context, eventType EventType) error {
+				calls = append(calls, name+":start")
+				return nil
+			},
+			success: func(ctx context.Context, eventType EventType) error {
+				calls = append(calls, name+":success")
+				return nil
+			},
+			failure: func(ctx context.Context, eventType EventType, err error) error {
+				calls = append(calls, name+":failure")
+				return nil
+			},
+			attr: func(key, val string) {
+				calls = append(calls, name+":attr:"+key+"="+val)
+			},
+			user: func() uuid.UUID {
+				return userID
+			},
+		}
+	}
+
+	cli := NewMultiClient(newMock("a"), newMock("b"))
+	ctx := context.Background()
+
+	cli.Attr("k", "v")
+	if err := cli.Start(ctx, Install); err != nil {
+		t.Error("unexpected start error", err)
+	}
+	if err := cli.Success(ctx, Install); err != nil {
+		t.Error("unexpected success error", err)
+	}
+	if err := cli.Failure(ctx, Install, errors.New("failure")); err != nil {
+		t.Error("unexpected failure error", err)
+	}
+
+	exp := []string{
+		"a:attr:k=v", "b:attr:k=v",
+		"a:start", "b:start",
+		"a:success", "b:success",
+		"a:failure", "b:failure",
+	}
+	if d := cmp.Diff(exp, calls); d != "" {
+		t.Error("calls mismatch (-want +got):", d)
+	}
+
+	if d := cmp.Diff(userID, cli.User()); d != "" {
+		t.Error("user mismatch (-want +got):", d)
+	}
+}
+
+func TestMultiClient_StartErr(t *testing.T) {
+	errTest := errors.New("test")
+	successCalled := 0
+
+	failing := MockClient{
+		start: func(ctx context.Context, eventType EventType) error {
+			return errTest
+		},
+		success: func(ctx context.Context, eventType EventType) error {
+			t.Error("success should not be called on a client that failed to start")
+			return nil
+		},
+	}
+	working := MockClient{
+		start: func(ctx context.Context, eventType EventType) error {
+			return nil
+		},
+		success: func(ctx context.Context, eventType EventType) error {
+			successCalled++
+			return nil
+		},
+	}
+
+	ctx := context.Background()
+
+	t.Run("one failure", func(t *testing.T) {
+		cli := NewMultiClient(failing, working)
+		if err := cli.Start(ctx, Install); err != nil {
+			t.Error("start should only fail if all clients failed", err)
+		}
+		if err := cli.Success(ctx, Install); err != nil {
+			t.Error("unexpected success error", err)
+		}
+		if d := cmp.Diff(1, successCalled); d != "" {
+			t.Error("success call count mismatch (-want +got):", d)
+		}
+	})
+
+	t.Run("all failures", func(t *testing.T) {
+		cli := NewMultiClient(failing, failing)
+		if err := cli.Start(ctx, Install); !errors.Is(err, errTest) {
+			t.Error("expected start error", err)
+		}
+	})
+}
+
+func TestMultiClient_NoClients(t *testing.T) {
+	cli := NewMultiClient()
+	if d := cmp.Diff(uuid.Nil, cli.User()); d != "" {
+		t.Error("user mismatch (-want +got):", d)
+	}
+}
@@ -4,14 +4,10 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"github.com/airbytehq/abctl/internal/build"
 	"github.com/google/uuid"
-	"github.com/pbnjay/memory"
 	"k8s.io/apimachinery/pkg/util/json"
-	"maps"
 	"net/http"
-	"runtime"
-	"strconv"
+	"sync"
 	"time"
 )
 
@@ -46,7 +42,9 @@ type SegmentClient struct {
 	doer      Doer
 	sessionID uuid.UUID
 	cfg       Config
-	attrs     map[string]string
+	// lock guards the attrs, which may be set concurrently, e.g. by charts installed concurrently
+	lock  sync.Mutex
+	attrs map[string]string
 }
 
 func NewSegmentClient(cfg Config, opts ...Option) *SegmentClient {
@@ -77,6 +75,8 @@ func (s *SegmentClient) Failure(ctx context.Context, et EventType, err error) er
 }
 
 func (s *SegmentClient) Attr(key, val string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
 	s.attrs[key] = val
 }
 
@@ -90,28 +90,10 @@ const (
 )
 
 func (s *SegmentClient) send(ctx context.Context, es EventState, et EventType, ee error) error {
-	properties := map[string]string{
-		"deployment_method": "abctl",
-		"session_id":        s.sessionID.String(),
-		"state":             string(es),
-		"os":                runtime.GOOS,
-		"build":             build.Version,
-		"script_version":    build.Version,
-		"cpu_count":         strconv.Itoa(runtime.NumCPU()),
-		"mem_total_bytes":   strconv.FormatUint(memory.TotalMemory(), 10),
-		"mem_free_bytes":    strconv.FormatUint(memory.FreeMemory(), 10),
-	}
-	// add all the attributes to the properties map before sending it
-	maps.Copy(properties, s.attrs)
-
-	if ee != nil {
-		properties["error"] = ee.Error()
-	}
-
 	body := body{
 		ID:         s.cfg.AnalyticsID.String(),
 		Event:      string(et),
-		Properties: properties,
+		Properties: s.properties(es, ee),
 		Timestamp:  time.Now().UTC().Format(time.RFC3339),
 		WriteKey:   trackingKey,
 	}
@@ -143,3 +125,10 @@ type body struct {
 	Timestamp  string            `json:"timestamp"`
 	WriteKey   string            `json:"writeKey"`
 }
+
+// properties returns the properties of the event, including the attrs set so far.
+func (s *SegmentClient) properties(es EventState, ee error) map[string]string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return properties(s.sessionID, es, s.attrs, ee)
+}
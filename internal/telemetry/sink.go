@@ -0,0 +1,324 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/build"
+	"github.com/google/uuid"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SinkType is the type of destination an additional telemetry sink sends events to.
+type SinkType string
+
+const (
+	// SinkOTLP sends events as OTLP log records to an OpenTelemetry collector, via OTLP/HTTP.
+	SinkOTLP SinkType = "otlp"
+	// SinkFile appends events, one json document per line, to a local file.
+	SinkFile SinkType = "file"
+)
+
+// SinkConfig represents an additional telemetry sink defined within the analytics config file.
+// Every sink receives the same events that are sent to segment.
+//
+// Example:
+//
+//	sinks:
+//	  - type: otlp
+//	    endpoint: http://localhost:4318
+//	    headers:
+//	      authorization: Bearer token
+//	  - type: file
+//	    path: /var/log/abctl/events.jsonl
+type SinkConfig struct {
+	// Type of the sink, either "otlp" or "file".
+	Type SinkType `yaml:"type"`
+	// Endpoint is the base url of the OTLP/HTTP receiver, only used by the otlp sink.
+	// The "/v1/logs" path will be appended to this value if it isn't already present.
+	Endpoint string `yaml:"endpoint,omitempty"`
+	// Headers are additional http headers to include with every request, only used by the otlp sink.
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Path of the file to append events to, only used by the file sink.
+	Path string `yaml:"path,omitempty"`
+}
+
+// event is the sink agnostic representation of a single telemetry event.
+type event struct {
+	ID         string            `json:"anonymousId"`
+	Event      string            `json:"event"`
+	State      string            `json:"state"`
+	Properties map[string]string `json:"properties"`
+	Timestamp  string            `json:"timestamp"`
+}
+
+// exporter sends an event to a sink.
+type exporter interface {
+	export(ctx context.Context, e event) error
+}
+
+var _ Client = (*SinkClient)(nil)
+
+// SinkClient client, all methods export events to a user defined sink.
+type SinkClient struct {
+	exporter  exporter
+	sessionID uuid.UUID
+	cfg       Config
+	// lock guards the attrs, which may be set concurrently, e.g. by charts installed concurrently
+	lock  sync.Mutex
+	attrs map[string]string
+}
+
+// newSinkClient returns a SinkClient for the provided sink.
+// An error is returned if the sink is not supported or is missing required configuration.
+func newSinkClient(cfg Config, sink SinkConfig, sessionID uuid.UUID) (*SinkClient, error) {
+	var exp exporter
+
+	switch sink.Type {
+	case SinkOTLP:
+		if sink.Endpoint == "" {
+			return nil, errors.New("endpoint is required")
+		}
+		endpoint := strings.TrimSuffix(sink.Endpoint, "/")
+		if !strings.HasSuffix(endpoint, otlpLogsPath) {
+			endpoint += otlpLogsPath
+		}
+		exp = &otlpExporter{
+			doer:     &http.Client{Timeout: 10 * time.Second},
+			endpoint: endpoint,
+			headers:  sink.Headers,
+		}
+	case SinkFile:
+		if sink.Path == "" {
+			return nil, errors.New("path is required")
+		}
+		exp = &fileExporter{path: sink.Path}
+	default:
+		return nil, fmt.Errorf("unsupported sink type '%s'", sink.Type)
+	}
+
+	return &SinkClient{
+		exporter:  exp,
+		sessionID: sessionID,
+		cfg:       cfg,
+		attrs:     map[string]string{},
+	}, nil
+}
+
+func (s *SinkClient) Start(ctx context.Context, et EventType) error {
+	return s.send(ctx, Start, et, nil)
+}
+
+func (s *SinkClient) Success(ctx context.Context, et EventType) error {
+	return s.send(ctx, Success, et, nil)
+}
+
+func (s *SinkClient) Failure(ctx context.Context, et EventType, err error) error {
+	return s.send(ctx, Failed, et, err)
+}
+
+func (s *SinkClient) Attr(key, val string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.attrs[key] = val
+}
+
+func (s *SinkClient) User() uuid.UUID {
+	return s.cfg.AnalyticsID.toUUID()
+}
+
+func (s *SinkClient) send(ctx context.Context, es EventState, et EventType, ee error) error {
+	return s.exporter.export(ctx, event{
+		ID:         s.cfg.AnalyticsID.String(),
+		Event:      string(et),
+		State:      string(es),
+		Properties: s.properties(es, ee),
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+var _ exporter = (*fileExporter)(nil)
+
+// fileExporter appends every event to the file located at path.
+type fileExporter struct {
+	path string
+	lock sync.Mutex
+}
+
+func (f *fileExporter) export(_ context.Context, e event) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("could not marshal event: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return fmt.Errorf("could not create directories for %s: %w", f.path, err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("could not open file %s: %w", f.path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("could not write to file %s: %w", f.path, err)
+	}
+
+	return nil
+}
+
+// otlpLogsPath is the OTLP/HTTP path that log records are sent to.
+const otlpLogsPath = "/v1/logs"
+
+// severity numbers as defined by the OpenTelemetry log data model
+const (
+	otlpSeverityInfo  = 9
+	otlpSeverityError = 17
+)
+
+var _ exporter = (*otlpExporter)(nil)
+
+// otlpExporter sends every event as a log record to an OTLP/HTTP receiver, using the json encoding.
+// See https://opentelemetry.io/docs/specs/otlp/#otlphttp
+type otlpExporter struct {
+	doer     Doer
+	endpoint string
+	headers  map[string]string
+}
+
+func (o *otlpExporter) export(ctx context.Context, e event) error {
+	ts, err := time.Parse(time.RFC3339, e.Timestamp)
+	if err != nil {
+		return fmt.Errorf("could not parse timestamp %s: %w", e.Timestamp, err)
+	}
+
+	severityNum, severityText := otlpSeverityInfo, "INFO"
+	if e.State == string(Failed) {
+		severityNum, severityText = otlpSeverityError, "ERROR"
+	}
+
+	attrs := []otlpKeyValue{
+		newOTLPKeyValue("event", e.Event),
+		newOTLPKeyValue("state", e.State),
+		newOTLPKeyValue("anonymous_id", e.ID),
+	}
+	// sort the property keys to ensure consistent output
+	keys := make([]string, 0, len(e.Properties))
+	for k := range e.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		attrs = append(attrs, newOTLPKeyValue(k, e.Properties[k]))
+	}
+
+	payload := otlpLogs{ResourceLogs: []otlpResourceLogs{{
+		Resource: otlpResource{Attributes: []otlpKeyValue{
+			newOTLPKeyValue("service.name", "abctl"),
+			newOTLPKeyValue("service.version", build.Version),
+		}},
+		ScopeLogs: []otlpScopeLogs{{
+			Scope: otlpScope{Name: "abctl", Version: build.Version},
+			LogRecords: []otlpLogRecord{{
+				TimeUnixNano:   strconv.FormatInt(ts.UnixNano(), 10),
+				SeverityNumber: severityNum,
+				SeverityText:   severityText,
+				Body:           otlpAnyValue{StringValue: fmt.Sprintf("%s %s", e.Event, e.State)},
+				Attributes:     attrs,
+			}},
+		}},
+	}}}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not create request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.endpoint, bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range o.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := o.doer.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not post: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// otlp json types, only the fields used by the otlpExporter are defined
+// see https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/logs/v1/logs.proto
+
+type otlpLogs struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano   string         `json:"timeUnixNano"`
+	SeverityNumber int            `json:"severityNumber"`
+	SeverityText   string         `json:"severityText"`
+	Body           otlpAnyValue   `json:"body"`
+	Attributes     []otlpKeyValue `json:"attributes"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+func newOTLPKeyValue(key, val string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: val}}
+}
+
+// properties returns the properties of the event, including the attrs set so far.
+func (s *SinkClient) properties(es EventState, ee error) map[string]string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return properties(s.sessionID, es, s.attrs, ee)
+}
@@ -0,0 +1,204 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewSinkClient(t *testing.T) {
+	tests := []struct {
+		name   string
+		sink   SinkConfig
+		expErr string
+	}{
+		{
+			name: "otlp",
+			sink: SinkConfig{Type: SinkOTLP, Endpoint: "http://localhost:4318"},
+		},
+		{
+			name:   "otlp missing endpoint",
+			sink:   SinkConfig{Type: SinkOTLP},
+			expErr: "endpoint is required",
+		},
+		{
+			name: "file",
+			sink: SinkConfig{Type: SinkFile, Path: "events.jsonl"},
+		},
+		{
+			name:   "file missing path",
+			sink:   SinkConfig{Type: SinkFile},
+			expErr: "path is required",
+		},
+		{
+			name:   "unsupported",
+			sink:   SinkConfig{Type: "kafka"},
+			expErr: "unsupported sink type 'kafka'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newSinkClient(Config{}, tt.sink, sessionID)
+			if tt.expErr == "" {
+				if err != nil {
+					t.Error("unexpected error", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.expErr {
+				t.Errorf("expected error %q, received %v", tt.expErr, err)
+			}
+		})
+	}
+}
+
+func TestNewSinkClient_OTLPEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"http://localhost:4318", "http://localhost:4318/", "http://localhost:4318/v1/logs"} {
+		cli, err := newSinkClient(Config{}, SinkConfig{Type: SinkOTLP, Endpoint: endpoint}, sessionID)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if d := cmp.Diff("http://localhost:4318/v1/logs", cli.exporter.(*otlpExporter).endpoint); d != "" {
+			t.Error("endpoint mismatch (-want +got):", d)
+		}
+	}
+}
+
+func TestSinkClient_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "events.jsonl")
+	cli, err := newSinkClient(Config{AnalyticsID: UUID(userID)}, SinkConfig{Type: SinkFile, Path: path}, sessionID)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	ctx := context.Background()
+	cli.Attr("key", "val")
+	if err := cli.Start(ctx, Install); err != nil {
+		t.Error("start call failed", err)
+	}
+	if err := cli.Failure(ctx, Install, errors.New("failure reason")); err != nil {
+		t.Error("failure call failed", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("could not read file", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if d := cmp.Diff(2, len(lines)); d != "" {
+		t.Fatal("line count mismatch (-want +got):", d)
+	}
+
+	var events []event
+	for _, line := range lines {
+		var e event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatal("could not unmarshal event", err)
+		}
+		events = append(events, e)
+	}
+
+	for i, expState := range []EventState{Start, Failed} {
+		if d := cmp.Diff(userID.String(), events[i].ID); d != "" {
+			t.Error("id mismatch (-want +got):", d)
+		}
+		if d := cmp.Diff(string(Install), events[i].Event); d != "" {
+			t.Error("event mismatch (-want +got):", d)
+		}
+		if d := cmp.Diff(string(expState), events[i].State); d != "" {
+			t.Error("state mismatch (-want +got):", d)
+		}
+		if d := cmp.Diff(sessionID.String(), events[i].Properties["session_id"]); d != "" {
+			t.Error("session_id mismatch (-want +got):", d)
+		}
+		if d := cmp.Diff("val", events[i].Properties["key"]); d != "" {
+			t.Error("attr mismatch (-want +got):", d)
+		}
+	}
+
+	if d := cmp.Diff("failure reason", events[1].Properties["error"]); d != "" {
+		t.Error("error mismatch (-want +got):", d)
+	}
+}
+
+func TestSinkClient_OTLP(t *testing.T) {
+	var req *http.Request
+	mDoer := &mockDoer{
+		do: func(r *http.Request) (*http.Response, error) {
+			req = r
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(&strings.Reader{})}, nil
+		},
+	}
+
+	cli := &SinkClient{
+		exporter: &otlpExporter{
+			doer:     mDoer,
+			endpoint: "http://localhost:4318/v1/logs",
+			headers:  map[string]string{"Authorization": "Bearer token"},
+		},
+		sessionID: sessionID,
+		cfg:       Config{AnalyticsID: UUID(userID)},
+		attrs:     map[string]string{},
+	}
+
+	if err := cli.Failure(context.Background(), Install, errors.New("failure reason")); err != nil {
+		t.Fatal("failure call failed", err)
+	}
+
+	if d := cmp.Diff("http://localhost:4318/v1/logs", req.URL.String()); d != "" {
+		t.Error("request URL mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff("Bearer token", req.Header.Get("Authorization")); d != "" {
+		t.Error("request header mismatch (-want +got):", d)
+	}
+
+	var payload otlpLogs
+	if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+		t.Fatal("could not decode request body", err)
+	}
+
+	record := payload.ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	if d := cmp.Diff("ERROR", record.SeverityText); d != "" {
+		t.Error("severity mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff("install failed", record.Body.StringValue); d != "" {
+		t.Error("body mismatch (-want +got):", d)
+	}
+
+	attrs := map[string]string{}
+	for _, kv := range record.Attributes {
+		attrs[kv.Key] = kv.Value.StringValue
+	}
+	if d := cmp.Diff(userID.String(), attrs["anonymous_id"]); d != "" {
+		t.Error("anonymous_id mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff("failure reason", attrs["error"]); d != "" {
+		t.Error("error mismatch (-want +got):", d)
+	}
+}
+
+func TestSinkClient_OTLPErr(t *testing.T) {
+	mDoer := &mockDoer{
+		do: func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusBadRequest, Body: io.NopCloser(&strings.Reader{})}, nil
+		},
+	}
+
+	cli := &SinkClient{
+		exporter: &otlpExporter{doer: mDoer, endpoint: "http://localhost:4318/v1/logs"},
+		attrs:    map[string]string{},
+	}
+
+	if err := cli.Start(context.Background(), Install); err == nil {
+		t.Error("expected an error")
+	}
+}
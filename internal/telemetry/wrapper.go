@@ -2,24 +2,33 @@ package telemetry
 
 import (
 	"context"
-	"github.com/pterm/pterm"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/airbytehq/abctl/internal/trace"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// Wrapper wraps the function calls with the telemetry handlers
+// Wrapper wraps the function calls with the telemetry handlers.
+// The duration of the call is recorded as the "duration_ms" attribute, along with the duration of every phase traced
+// within it, such as a span named "cluster create" of the ctx recorded as the "phase_cluster_create_ms" attribute.
 func Wrapper(ctx context.Context, et EventType, f func() error) error {
 	cli := Get()
+	start := time.Now()
 
 	attemptSuccessFailure := true
 
 	if err := cli.Start(ctx, et); err != nil {
-		pterm.Debug.Printfln("Unable to send telemetry start data: %s", err)
+		logging.Debugf("Unable to send telemetry start data: %s", err)
 		attemptSuccessFailure = false
 	}
 
-	if err := f(); err != nil {
+	err := f()
+	recordDurations(cli, trace.SpanFromContext(ctx), time.Since(start))
+	if err != nil {
 		if attemptSuccessFailure {
 			if errTel := cli.Failure(ctx, et, err); errTel != nil {
-				pterm.Debug.Printfln("Unable to send telemetry failure data: %s", errTel)
+				logging.Debugf("Unable to send telemetry failure data: %s", errTel)
 			}
 		}
 
@@ -28,9 +37,22 @@ func Wrapper(ctx context.Context, et EventType, f func() error) error {
 
 	if attemptSuccessFailure {
 		if err := cli.Success(ctx, et); err != nil {
-			pterm.Debug.Printfln("Unable to send telemetry success data: %s", err)
+			logging.Debugf("Unable to send telemetry success data: %s", err)
 		}
 	}
 
 	return nil
 }
+
+// recordDurations adds the duration, and the durations of the phases traced by the span, as attributes of the cli.
+func recordDurations(cli Client, span *trace.Span, d time.Duration) {
+	cli.Attr("duration_ms", strconv.FormatInt(d.Milliseconds(), 10))
+	for name, d := range span.Durations() {
+		cli.Attr(phaseAttr(name), strconv.FormatInt(d.Milliseconds(), 10))
+	}
+}
+
+// phaseAttr returns the attribute of the duration of the phase, e.g. phase_helm_airbyte_ms for "helm airbyte".
+func phaseAttr(name string) string {
+	return "phase_" + strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(name)) + "_ms"
+}
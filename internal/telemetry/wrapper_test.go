@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/airbytehq/abctl/internal/trace"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/uuid"
@@ -83,6 +85,38 @@ func TestWrapper(t *testing.T) {
 	}
 }
 
+func TestWrapper_Durations(t *testing.T) {
+	t.Cleanup(func() {
+		instance = origInstance
+	})
+
+	attrs := map[string]string{}
+	instance = MockClient{
+		start:   func(ctx context.Context, eventType EventType) error { return nil },
+		success: func(ctx context.Context, eventType EventType) error { return nil },
+		attr:    func(key, val string) { attrs[key] = val },
+	}
+
+	ctx, root := trace.NewSpan(context.Background(), "abctl")
+	err := Wrapper(ctx, Install, func() error {
+		_, span := trace.NewSpan(ctx, "cluster create")
+		span.End()
+		_, span = trace.NewSpan(ctx, "helm airbyte")
+		span.End()
+		return nil
+	})
+	root.End()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	for _, key := range []string{"duration_ms", "phase_cluster_create_ms", "phase_helm_airbyte_ms"} {
+		if _, ok := attrs[key]; !ok {
+			t.Errorf("expected attribute %s, got %v", key, attrs)
+		}
+	}
+}
+
 // TestWrapper_DebugLogs verifies that the messages output by the Wrapper function are only visible
 // if debug messages are enabled.
 func TestWrapper_DebugLogs(t *testing.T) {
@@ -90,11 +124,14 @@ func TestWrapper_DebugLogs(t *testing.T) {
 	pterm.SetDefaultOutput(b)
 	t.Cleanup(func() {
 		instance = origInstance
+		_ = logging.Setup(logging.Options{Format: logging.FormatText})
 		pterm.DisableDebugMessages()
 		pterm.SetDefaultOutput(os.Stdout)
 	})
 
-	pterm.EnableDebugMessages()
+	if err := logging.Setup(logging.Options{Verbosity: 1, Format: logging.FormatText}); err != nil {
+		t.Fatal("unexpected error", err)
+	}
 
 	tests := []struct {
 		name       string
@@ -220,7 +257,9 @@ func (m MockClient) Failure(ctx context.Context, eventType EventType, err error)
 }
 
 func (m MockClient) Attr(key, val string) {
-	m.attr(key, val)
+	if m.attr != nil {
+		m.attr(key, val)
+	}
 }
 
 func (m MockClient) User() uuid.UUID {
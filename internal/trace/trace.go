@@ -0,0 +1,211 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/airbytehq/abctl/internal/redact"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Span represents a single timed operation.
+// Spans are nested, a Span created from a context which already contains a Span will be a child of that Span.
+//
+// All methods are safe to call on a nil Span.
+type Span struct {
+	lock     sync.Mutex
+	name     string
+	start    time.Time
+	end      time.Time
+	attrs    map[string]string
+	logs     []Log
+	err      error
+	children []*Span
+}
+
+// Log is a named block of text attached to a Span, such as the logs of a failing pod.
+type Log struct {
+	Name string `json:"name"`
+	Log  string `json:"log"`
+}
+
+type ctxKey struct{}
+
+// NewSpan starts a new Span with the provided name.
+// If the ctx already contains a Span, the new Span will be created as a child of that Span.
+// The returned context contains the new Span.
+func NewSpan(ctx context.Context, name string) (context.Context, *Span) {
+	span := &Span{
+		name:  name,
+		start: time.Now(),
+		attrs: map[string]string{},
+	}
+
+	if parent := SpanFromContext(ctx); parent != nil {
+		parent.lock.Lock()
+		parent.children = append(parent.children, span)
+		parent.lock.Unlock()
+	}
+
+	return context.WithValue(ctx, ctxKey{}, span), span
+}
+
+// SpanFromContext returns the Span contained within the ctx, or nil if no Span exists.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(ctxKey{}).(*Span)
+	return span
+}
+
+// AttachLog attaches the log to the Span contained within the ctx.
+// Does nothing if the ctx does not contain a Span.
+func AttachLog(ctx context.Context, name, log string) {
+	SpanFromContext(ctx).AttachLog(name, log)
+}
+
+// End marks the Span as complete.
+// Calling End more than once has no effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.end.IsZero() {
+		s.end = time.Now()
+	}
+}
+
+// SetAttr adds the key and val as an attribute to the Span.
+func (s *Span) SetAttr(key, val string) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.attrs[key] = val
+}
+
+// RecordError marks the Span as having failed with the err.
+// A nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.err = err
+}
+
+// AttachLog attaches the log to the Span.
+// Any known secrets are redacted from the log before it is attached.
+func (s *Span) AttachLog(name, log string) {
+	if s == nil {
+		return
+	}
+	log = redact.String(log)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.logs = append(s.logs, Log{Name: name, Log: log})
+}
+
+// Durations returns the durations of all ended descendants of the Span by their name, summed if several share a name.
+// Returns nil for a nil Span.
+func (s *Span) Durations() map[string]time.Duration {
+	if s == nil {
+		return nil
+	}
+	durations := map[string]time.Duration{}
+	s.durations(durations)
+	return durations
+}
+
+func (s *Span) durations(durations map[string]time.Duration) {
+	s.lock.Lock()
+	children := append([]*Span(nil), s.children...)
+	s.lock.Unlock()
+
+	for _, child := range children {
+		child.lock.Lock()
+		if !child.end.IsZero() {
+			durations[child.name] += child.end.Sub(child.start)
+		}
+		child.lock.Unlock()
+		child.durations(durations)
+	}
+}
+
+// spanJSON is the json representation of a Span.
+type spanJSON struct {
+	Name       string            `json:"name"`
+	Start      time.Time         `json:"start"`
+	End        *time.Time        `json:"end,omitempty"`
+	Duration   string            `json:"duration,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Logs       []Log             `json:"logs,omitempty"`
+	Children   []spanJSON        `json:"children,omitempty"`
+}
+
+// toJSON converts the Span, and all of its children, into their json representations.
+// Spans that have not ended will have no end time or duration.
+func (s *Span) toJSON() spanJSON {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	out := spanJSON{
+		Name:  s.name,
+		Start: s.start,
+		Logs:  s.logs,
+	}
+	if !s.end.IsZero() {
+		end := s.end
+		out.End = &end
+		out.Duration = s.end.Sub(s.start).String()
+	}
+	if len(s.attrs) > 0 {
+		out.Attributes = s.attrs
+	}
+	if s.err != nil {
+		out.Error = s.err.Error()
+	}
+	for _, child := range s.children {
+		out.Children = append(out.Children, child.toJSON())
+	}
+
+	return out
+}
+
+// WriteFile writes the Span, including the full tree of its children, as json to the file located at path.
+// The file is only readable by the user, as the errors of the trace may contain details of the installation.
+func WriteFile(path string, span *Span) error {
+	if span == nil {
+		return fmt.Errorf("no span to write")
+	}
+
+	data, err := json.MarshalIndent(span.toJSON(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal trace: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("could not create directories for %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("could not write trace to %s: %w", path, err)
+	}
+	// the mode of an existing file is not changed by WriteFile
+	if err := os.Chmod(path, 0600); err != nil {
+		return fmt.Errorf("could not restrict the permissions of %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestNewSpan(t *testing.T) {
+	ctx, root := NewSpan(context.Background(), "root")
+	if SpanFromContext(ctx) != root {
+		t.Error("expected context to contain the root span")
+	}
+
+	childCtx, child := NewSpan(ctx, "child")
+	child.SetAttr("key", "val")
+	child.RecordError(errors.New("child failed"))
+	AttachLog(childCtx, "pod", "log line")
+	child.End()
+
+	_, grandchild := NewSpan(childCtx, "grandchild")
+	grandchild.End()
+
+	// an unfinished span
+	NewSpan(ctx, "unfinished")
+
+	root.End()
+
+	got := root.toJSON()
+
+	if d := cmp.Diff("root", got.Name); d != "" {
+		t.Error("name mismatch (-want +got):", d)
+	}
+	if got.End == nil || got.Duration == "" {
+		t.Error("expected root span to have ended")
+	}
+	if d := cmp.Diff(2, len(got.Children)); d != "" {
+		t.Fatal("children count mismatch (-want +got):", d)
+	}
+
+	c := got.Children[0]
+	if d := cmp.Diff(map[string]string{"key": "val"}, c.Attributes); d != "" {
+		t.Error("attributes mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff("child failed", c.Error); d != "" {
+		t.Error("error mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff([]Log{{Name: "pod", Log: "log line"}}, c.Logs); d != "" {
+		t.Error("logs mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff("grandchild", c.Children[0].Name); d != "" {
+		t.Error("grandchild mismatch (-want +got):", d)
+	}
+
+	unfinished := got.Children[1]
+	if unfinished.End != nil || unfinished.Duration != "" {
+		t.Error("expected unfinished span to have no end")
+	}
+}
+
+func TestSpan_Nil(t *testing.T) {
+	var span *Span
+	// none of these should panic
+	span.End()
+	span.SetAttr("key", "val")
+	span.RecordError(errors.New("test"))
+	span.AttachLog("name", "log")
+	AttachLog(context.Background(), "name", "log")
+}
+
+func TestSpan_Durations(t *testing.T) {
+	start := time.Date(2024, 10, 16, 12, 0, 0, 0, time.UTC)
+	span := func(name string, d time.Duration, children ...*Span) *Span {
+		s := &Span{name: name, start: start, children: children}
+		if d != 0 {
+			s.end = start.Add(d)
+		}
+		return s
+	}
+
+	root := span("abctl", 0,
+		span("cluster create", time.Minute),
+		span("helm airbyte", 3*time.Minute, span("wait", 2*time.Minute)),
+		span("wait", time.Minute),
+		span("unfinished", 0),
+	)
+
+	exp := map[string]time.Duration{
+		"cluster create": time.Minute,
+		"helm airbyte":   3 * time.Minute,
+		"wait":           3 * time.Minute,
+	}
+	if d := cmp.Diff(exp, root.Durations()); d != "" {
+		t.Error("durations mismatch (-want +got):", d)
+	}
+
+	var nilSpan *Span
+	if d := cmp.Diff(map[string]time.Duration(nil), nilSpan.Durations()); d != "" {
+		t.Error("nil span durations mismatch (-want +got):", d)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "trace.json")
+
+	_, root := NewSpan(context.Background(), "root")
+	root.End()
+
+	if err := WriteFile(path, root); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("could not read file", err)
+	}
+
+	var got spanJSON
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal("could not unmarshal trace", err)
+	}
+	if d := cmp.Diff("root", got.Name); d != "" {
+		t.Error("name mismatch (-want +got):", d)
+	}
+
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(path, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteFile(path, root); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("expected the trace to only be readable by the user, got %s", info.Mode().Perm())
+		}
+	}
+
+	if err := WriteFile(path, nil); err == nil {
+		t.Error("expected an error for a nil span")
+	}
+}
+
+func TestSpan_AttachLog_Redacted(t *testing.T) {
+	_, span := NewSpan(context.Background(), "root")
+	span.AttachLog("pod", "DATABASE_PASSWORD=hunter2")
+
+	exp := []Log{{Name: "pod", Log: "DATABASE_PASSWORD=REDACTED"}}
+	if d := cmp.Diff(exp, span.toJSON().Logs); d != "" {
+		t.Error("logs mismatch (-want +got):", d)
+	}
+}
@@ -0,0 +1,124 @@
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/mod/semver"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// CacheTTL is how long the latest release of a channel is cached for.
+	CacheTTL = 24 * time.Hour
+	// FailedTTL is how long a failed check is cached for, so an offline or restricted network only delays an
+	// invocation once in a while.
+	FailedTTL = time.Hour
+)
+
+// now returns the current time, defined here for testing purposes.
+var now = time.Now
+
+// cacheEntry is the result of the last check, stored in the cache file.
+type cacheEntry struct {
+	Channel string    `json:"channel"`
+	Checked time.Time `json:"checked"`
+	Latest  Release   `json:"latest"`
+	// Err is the error of a failed check, empty if it succeeded.
+	Err string `json:"err,omitempty"`
+}
+
+// fresh returns true if the entry is the result of a check of the channel which hasn't expired.
+func (e cacheEntry) fresh(channel string) bool {
+	ttl := CacheTTL
+	if e.Err != "" {
+		ttl = FailedTTL
+	}
+	return e.Channel == channel && now().Sub(e.Checked) < ttl
+}
+
+// CheckCached is CheckChannel, with the latest release of the channel cached in the file located at path.
+// The network is only queried once the cached release is older than CacheTTL, or FailedTTL if the previous check
+// failed, in which case its error is returned again.
+func CheckCached(ctx context.Context, doer doer, version string, channel string, path string) (Release, error) {
+	if version == "dev" {
+		return Release{}, ErrDevVersion
+	}
+	if err := ValidateChannel(channel); err != nil {
+		return Release{}, err
+	}
+
+	entry, ok := loadCache(path)
+	if !ok || !entry.fresh(channel) {
+		entry = cacheEntry{Channel: channel, Checked: now()}
+		latest, err := latest(ctx, doer, channel)
+		if err != nil {
+			entry.Err = err.Error()
+		} else {
+			entry.Latest = latest
+		}
+		// a cache which cannot be written only results in the next invocation checking again
+		_ = saveCache(path, entry)
+	}
+
+	if entry.Err != "" {
+		return Release{}, fmt.Errorf("update check failed at %s: %s", entry.Checked.Format(time.RFC3339), entry.Err)
+	}
+	if semver.Compare(version, entry.Latest.Version) < 0 {
+		return entry.Latest, nil
+	}
+	return Release{}, nil
+}
+
+// loadCache returns the cacheEntry stored in the file located at path, false if there is none or it is unreadable.
+func loadCache(path string) (cacheEntry, bool) {
+	var entry cacheEntry
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry, false
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return entry, false
+	}
+	return entry, true
+}
+
+// saveCache stores the cacheEntry in the file located at path.
+func saveCache(path string, entry cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("could not marshal update check: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("could not create directories for %s: %w", path, err)
+	}
+
+	// write to a temporary file first, so a concurrent invocation never reads a partially written cache
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("could not write update check %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("could not replace update check %s: %w", path, err)
+	}
+	return nil
+}
+
+// ciEnvVars are the environment variables set by CI systems, e.g. GitHub Actions, GitLab, Jenkins, or Azure Pipelines.
+var ciEnvVars = []string{"CI", "BUILD_NUMBER", "TF_BUILD"}
+
+// Skip returns true if no update check should be performed as nobody would read the notice, because the output is
+// not a terminal or abctl runs in CI.
+func Skip(lookupEnv func(string) (string, bool), interactive bool) bool {
+	if !interactive {
+		return true
+	}
+	for _, name := range ciEnvVars {
+		if v, ok := lookupEnv(name); ok && v != "" && v != "false" {
+			return true
+		}
+	}
+	return false
+}
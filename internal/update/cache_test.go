@@ -0,0 +1,123 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckCached(t *testing.T) {
+	origNow := now
+	t.Cleanup(func() { now = origNow })
+	current := time.Date(2024, 10, 16, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+
+	var requests int
+	var remoteErr error
+	h := mockDoer{
+		do: func(req *http.Request) (*http.Response, error) {
+			requests++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(remoteVersion("v0.2.0"))),
+			}, remoteErr
+		},
+	}
+	path := filepath.Join(t.TempDir(), "update-check.json")
+
+	check := func(version, channel string, expRequests int, exp Release) {
+		t.Helper()
+		release, err := CheckCached(context.Background(), h, version, channel, path)
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if d := cmp.Diff(exp, release); d != "" {
+			t.Errorf("unexpected diff (-want, +got) = %s", d)
+		}
+		if d := cmp.Diff(expRequests, requests); d != "" {
+			t.Errorf("unexpected requests (-want, +got) = %s", d)
+		}
+	}
+
+	check("v0.1.0", ChannelStable, 1, Release{Version: "v0.2.0"})
+	// cached, also for another local version
+	current = current.Add(CacheTTL - time.Minute)
+	check("v0.1.0", ChannelStable, 1, Release{Version: "v0.2.0"})
+	check("v0.2.0", ChannelStable, 1, Release{})
+	// expired
+	current = current.Add(time.Minute)
+	check("v0.1.0", ChannelStable, 2, Release{Version: "v0.2.0"})
+
+	// a failed check is cached for FailedTTL
+	current = current.Add(CacheTTL)
+	remoteErr = errors.New("offline")
+	for i := 0; i < 2; i++ {
+		if _, err := CheckCached(context.Background(), h, "v0.1.0", ChannelStable, path); err == nil || !strings.Contains(err.Error(), "offline") {
+			t.Errorf("expected offline error, got %v", err)
+		}
+	}
+	if d := cmp.Diff(3, requests); d != "" {
+		t.Errorf("unexpected requests (-want, +got) = %s", d)
+	}
+	remoteErr = nil
+	current = current.Add(FailedTTL)
+	check("v0.1.0", ChannelStable, 4, Release{Version: "v0.2.0"})
+}
+
+func TestCheckCached_Channel(t *testing.T) {
+	var urls []string
+	h := mockDoer{
+		do: func(req *http.Request) (*http.Response, error) {
+			urls = append(urls, req.URL.String())
+			body := remoteVersion("v0.2.0")
+			if req.URL.String() == releasesURL {
+				body = `[{"tag_name": "v0.3.0-rc.1", "prerelease": true}]`
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+		},
+	}
+	path := filepath.Join(t.TempDir(), "update-check.json")
+
+	for _, channel := range []string{ChannelStable, ChannelRC} {
+		if _, err := CheckCached(context.Background(), h, "v0.1.0", channel, path); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+	// the cache of another channel is not used
+	if d := cmp.Diff([]string{url, releasesURL}, urls); d != "" {
+		t.Errorf("unexpected urls (-want, +got) = %s", d)
+	}
+}
+
+func TestSkip(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		interactive bool
+		exp         bool
+	}{
+		{name: "interactive", interactive: true},
+		{name: "not a terminal", exp: true},
+		{name: "ci", env: map[string]string{"CI": "true"}, interactive: true, exp: true},
+		{name: "ci false", env: map[string]string{"CI": "false"}, interactive: true},
+		{name: "jenkins", env: map[string]string{"BUILD_NUMBER": "42"}, interactive: true, exp: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookupEnv := func(name string) (string, bool) {
+				v, ok := tt.env[name]
+				return v, ok
+			}
+			if d := cmp.Diff(tt.exp, Skip(lookupEnv, tt.interactive)); d != "" {
+				t.Errorf("unexpected diff (-want, +got) = %s", d)
+			}
+		})
+	}
+}
@@ -7,10 +7,45 @@ import (
 	"fmt"
 	"golang.org/x/mod/semver"
 	"net/http"
+	"strings"
 )
 
 var ErrDevVersion = errors.New("dev version support not supported")
 
+const (
+	// ChannelStable only considers releases, the default.
+	ChannelStable = "stable"
+	// ChannelRC considers releases and their release candidates, e.g. v0.20.0-rc.1.
+	ChannelRC = "rc"
+	// ChannelNightly considers every release and pre-release, including the nightly builds.
+	ChannelNightly = "nightly"
+)
+
+// Channels returns the release channels, from the most to the least stable.
+func Channels() []string {
+	return []string{ChannelStable, ChannelRC, ChannelNightly}
+}
+
+// ValidateChannel returns an error if the channel is not one of Channels.
+func ValidateChannel(channel string) error {
+	for _, c := range Channels() {
+		if c == channel {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid channel %s, must be one of %s", channel, strings.Join(Channels(), ", "))
+}
+
+// Release is a release of abctl.
+type Release struct {
+	// Version is the semver tag of the release, e.g. v0.20.0.
+	Version string `json:"version"`
+	// URL is the page of the release.
+	URL string `json:"url,omitempty"`
+	// Notes is an excerpt of the changelog of the release.
+	Notes string `json:"notes,omitempty"`
+}
+
 type doer interface {
 	Do(req *http.Request) (*http.Response, error)
 }
@@ -20,53 +55,138 @@ type doer interface {
 // Returns the latest version, or an empty string if we're already running the latest version.
 // Will return ErrDevVersion if the build.Version is currently set to "dev".
 func Check(ctx context.Context, doer doer, version string) (string, error) {
+	release, err := CheckChannel(ctx, doer, version, ChannelStable)
+	return release.Version, err
+}
+
+// CheckChannel looks to see if there is a newer version of abctl within the release channel.
+// Versions are compared by semver, so a pre-release is older than its release, e.g. v0.20.0-rc.1 is older than
+// v0.20.0. Returns the latest release, or a zero Release if we're already running the latest version.
+// Will return ErrDevVersion if the build.Version is currently set to "dev".
+func CheckChannel(ctx context.Context, doer doer, version string, channel string) (Release, error) {
 	if version == "dev" {
-		return "", ErrDevVersion
+		return Release{}, ErrDevVersion
+	}
+	if err := ValidateChannel(channel); err != nil {
+		return Release{}, err
 	}
 
-	latest, err := latest(ctx, doer)
+	latest, err := latest(ctx, doer, channel)
 	if err != nil {
-		return "", err
+		return Release{}, err
 	}
 
-	if semver.Compare(version, latest) < 0 {
+	if semver.Compare(version, latest.Version) < 0 {
 		return latest, nil
 	}
 
 	// if we're here then our version is the latest
-	return "", nil
+	return Release{}, nil
+}
+
+const (
+	url = "https://api.github.com/repos/airbytehq/abctl/releases/latest"
+	// releasesURL lists the most recent releases, including the pre-releases excluded by url
+	releasesURL = "https://api.github.com/repos/airbytehq/abctl/releases?per_page=50"
+)
+
+// githubRelease is a release of the github api.
+type githubRelease struct {
+	TagName    string `json:"tag_name"`
+	HTMLURL    string `json:"html_url"`
+	Body       string `json:"body"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// latest returns the latest release of the channel.
+func latest(ctx context.Context, doer doer, channel string) (Release, error) {
+	var releases []githubRelease
+	if channel == ChannelStable {
+		var release githubRelease
+		if err := get(ctx, doer, url, &release); err != nil {
+			return Release{}, err
+		}
+		if !semver.IsValid(release.TagName) {
+			return Release{}, fmt.Errorf("invalid semver tag: %s", release.TagName)
+		}
+		releases = append(releases, release)
+	} else if err := get(ctx, doer, releasesURL, &releases); err != nil {
+		return Release{}, err
+	}
+
+	var newest *githubRelease
+	for i, r := range releases {
+		if r.Draft || !semver.IsValid(r.TagName) || !inChannel(r, channel) {
+			continue
+		}
+		if newest == nil || semver.Compare(r.TagName, newest.TagName) > 0 {
+			newest = &releases[i]
+		}
+	}
+	if newest == nil {
+		return Release{}, fmt.Errorf("no release found in channel %s", channel)
+	}
+
+	return Release{Version: newest.TagName, URL: newest.HTMLURL, Notes: excerpt(newest.Body, 5)}, nil
 }
 
-const url = "https://api.github.com/repos/airbytehq/abctl/releases/latest"
+// inChannel returns true if the release belongs to the channel. Every channel includes the releases, the rc channel
+// the release candidates, and the nightly channel every pre-release.
+func inChannel(r githubRelease, channel string) bool {
+	pre := semver.Prerelease(r.TagName)
+	if pre == "" && !r.Prerelease {
+		return true
+	}
+	switch channel {
+	case ChannelRC:
+		return strings.HasPrefix(pre, "-rc")
+	case ChannelNightly:
+		return true
+	default:
+		return false
+	}
+}
 
-func latest(ctx context.Context, doer doer) (string, error) {
+// get decodes the json response of the url into v.
+func get(ctx context.Context, doer doer, url string, v interface{}) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	res, err := doer.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to do request: %w", err)
+		return fmt.Errorf("failed to do request: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to do request, status code: %d", res.StatusCode)
-	}
-
-	var latest struct {
-		TagName string `json:"tag_name"`
+		return fmt.Errorf("failed to do request, status code: %d", res.StatusCode)
 	}
 
 	decoder := json.NewDecoder(res.Body)
-	if err := decoder.Decode(&latest); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	if !semver.IsValid(latest.TagName) {
-		return "", fmt.Errorf("invalid semver tag: %s", latest.TagName)
-	}
+	return nil
+}
 
-	return latest.TagName, nil
+// excerpt returns the first non-empty lines of the changelog, at most maxLines, ending in an ellipsis if the
+// changelog has further lines.
+func excerpt(changelog string, maxLines int) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(changelog, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(lines) == maxLines {
+			lines = append(lines, "...")
+			break
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
 }
@@ -154,6 +154,94 @@ func TestCheck_HTTPErr(t *testing.T) {
 	}
 }
 
+func TestCheckChannel(t *testing.T) {
+	releases := `[
+		{"tag_name": "v0.3.0-nightly.20241016", "prerelease": true, "html_url": "https://nightly", "body": "nightly"},
+		{"tag_name": "v0.3.0-rc.2", "prerelease": true, "html_url": "https://rc2", "body": "rc 2"},
+		{"tag_name": "v0.3.0-rc.10", "prerelease": true, "html_url": "https://rc10", "body": "rc 10"},
+		{"tag_name": "v0.3.0-rc.11", "draft": true},
+		{"tag_name": "v0.2.0", "html_url": "https://stable", "body": "stable"},
+		{"tag_name": "not-semver"}
+	]`
+
+	tests := []struct {
+		name    string
+		channel string
+		local   string
+		want    Release
+	}{
+		{
+			name:    "rc newer than release",
+			channel: ChannelRC,
+			local:   "v0.2.0",
+			want:    Release{Version: "v0.3.0-rc.10", URL: "https://rc10", Notes: "rc 10"},
+		},
+		{
+			name:    "nightly compares every pre-release",
+			channel: ChannelNightly,
+			local:   "v0.3.0-nightly.20241015",
+			want:    Release{Version: "v0.3.0-rc.10", URL: "https://rc10", Notes: "rc 10"},
+		},
+		{
+			name:    "rc older than its release",
+			channel: ChannelRC,
+			local:   "v0.3.0",
+		},
+		{
+			name:    "rc is latest",
+			channel: ChannelRC,
+			local:   "v0.3.0-rc.10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := mockDoer{
+				do: func(req *http.Request) (*http.Response, error) {
+					if d := cmp.Diff(releasesURL, req.URL.String()); d != "" {
+						t.Errorf("unexpected url (-want, +got) = %s", d)
+					}
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(strings.NewReader(releases)),
+					}, nil
+				},
+			}
+
+			release, err := CheckChannel(context.Background(), h, tt.local, tt.channel)
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			if d := cmp.Diff(tt.want, release); d != "" {
+				t.Errorf("unexpected diff (-want, +got) = %s", d)
+			}
+		})
+	}
+}
+
+func TestCheckChannel_Invalid(t *testing.T) {
+	h := mockDoer{
+		do: func(req *http.Request) (*http.Response, error) {
+			t.Error("unexpected request")
+			return nil, errors.New("unexpected request")
+		},
+	}
+
+	if _, err := CheckChannel(context.Background(), h, "v0.1.0", "beta"); err == nil {
+		t.Error("unexpected success")
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	changelog := "## What's Changed\r\n\r\n* one\r\n* two\r\n* three\r\n* four\r\n\r\n**Full Changelog**: https://github.com"
+	if d := cmp.Diff("## What's Changed\n* one\n* two\n* three\n* four\n...", excerpt(changelog, 5)); d != "" {
+		t.Errorf("unexpected diff (-want, +got) = %s", d)
+	}
+	if d := cmp.Diff("* one", excerpt("* one\n", 5)); d != "" {
+		t.Errorf("unexpected diff (-want, +got) = %s", d)
+	}
+}
+
 func remoteVersion(version string) string {
 	return fmt.Sprintf(`{ "tag_name": "%s" }`, version)
 }
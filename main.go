@@ -5,8 +5,12 @@ import (
 	"errors"
 	"github.com/airbytehq/abctl/internal/build"
 	"github.com/airbytehq/abctl/internal/cmd"
+	"github.com/airbytehq/abctl/internal/cmd/local/paths"
+	"github.com/airbytehq/abctl/internal/logging"
+	"github.com/airbytehq/abctl/internal/shutdown"
 	"github.com/airbytehq/abctl/internal/update"
 	"github.com/pterm/pterm"
+	"golang.org/x/term"
 	"net/http"
 	"os"
 	"os/signal"
@@ -22,40 +26,62 @@ func main() {
 	updateCtx, updateCancel := context.WithTimeout(ctx, 2*time.Second)
 	defer updateCancel()
 
-	updateChan := make(chan updateInfo)
-	go func() {
-		info := updateInfo{}
-		info.version, info.err = update.Check(updateCtx, http.DefaultClient, build.Version)
-		updateChan <- info
-	}()
+	// the release channel is only known once the flags and config are resolved, the check is started by the root command
+	updateChan := make(chan updateInfo, 1)
+	var updateChecked bool
+	checkUpdate := func(channel string) {
+		// nobody reads the notice of a non-interactive invocation, which isn't delayed by the check either
+		if update.Skip(os.LookupEnv, term.IsTerminal(int(os.Stdout.Fd()))) {
+			logging.Debugf("Release checking is disabled for non-interactive invocations")
+			return
+		}
+		updateChecked = true
+		go func() {
+			info := updateInfo{}
+			// the latest release is cached, so only an invocation once in a while queries github
+			info.release, info.err = update.CheckCached(updateCtx, http.DefaultClient, build.Version, channel, paths.UpdateCheck)
+			updateChan <- info
+		}()
+	}
 
 	// listen for shutdown signals
-	go func() {
-		signalCh := make(chan os.Signal, 1)
-		signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
-		<-signalCh
-
-		cancel()
-	}()
+	signalCh := make(chan os.Signal, 2)
+	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
+	go shutdown.Listen(signalCh, cancel)
 
 	// ensure the pterm info width matches the other printers
 	pterm.Info.Prefix.Text = " INFO  "
 
-	root := cmd.NewCmd()
+	root := cmd.NewCmd(checkUpdate)
 	cmd.Execute(ctx, root)
 
+	if !updateChecked {
+		return
+	}
 	newRelease := <-updateChan
+	// the release notice is informational, which is omitted by --quiet
+	quiet, _ := root.PersistentFlags().GetBool("quiet")
 	if newRelease.err != nil {
 		if errors.Is(newRelease.err, update.ErrDevVersion) {
-			pterm.DefaultLogger.Debug("Release checking is disabled for dev builds")
+			logging.Debugf("Release checking is disabled for dev builds")
+		} else {
+			logging.Debugf("Unable to check for a new release: %s", newRelease.err)
 		}
-	} else if newRelease.version != "" {
+	} else if newRelease.release.Version != "" && !quiet {
 		pterm.Println()
-		pterm.Info.Printfln("A new release of abctl is available: %s -> %s\nUpdating to the latest version is highly recommended", build.Version, newRelease.version)
+		pterm.Info.Printfln("A new release of abctl is available: %s -> %s\nUpdating to the latest version is highly recommended", build.Version, newRelease.release.Version)
+		if newRelease.release.Notes != "" {
+			pterm.Println()
+			pterm.Println(newRelease.release.Notes)
+		}
+		if newRelease.release.URL != "" {
+			pterm.Println()
+			pterm.Printfln("Release notes: %s", newRelease.release.URL)
+		}
 	}
 }
 
 type updateInfo struct {
-	version string
+	release update.Release
 	err     error
 }